@@ -6,16 +6,37 @@ import (
 	"fmt"
 	"github.com/dmanias/startupers/app/conf"
 	"github.com/dmanias/startupers/app/services/api/handlers"
+	"github.com/dmanias/startupers/business/core/audit"
+	"github.com/dmanias/startupers/business/core/audit/stores/auditdb"
+	"github.com/dmanias/startupers/business/core/deletion"
+	"github.com/dmanias/startupers/business/core/deletion/stores/deletiondb"
+	"github.com/dmanias/startupers/business/core/explore"
+	"github.com/dmanias/startupers/business/core/export"
+	"github.com/dmanias/startupers/business/core/export/stores/exportdb"
+	"github.com/dmanias/startupers/business/core/lockout"
+	"github.com/dmanias/startupers/business/core/lockout/stores/lockoutdb"
+	"github.com/dmanias/startupers/business/core/pat"
+	"github.com/dmanias/startupers/business/core/pat/stores/patdb"
+	"github.com/dmanias/startupers/business/core/session"
+	"github.com/dmanias/startupers/business/core/session/stores/sessiondb"
+	"github.com/dmanias/startupers/business/core/trash"
+	"github.com/dmanias/startupers/business/data/sqldb"
 	database "github.com/dmanias/startupers/business/sys/database/pgx"
+	"github.com/dmanias/startupers/business/sys/uploads"
 	"github.com/dmanias/startupers/business/web/auth"
 	"github.com/dmanias/startupers/foundation/keystore"
 	"github.com/dmanias/startupers/foundation/logger"
+	"github.com/dmanias/startupers/foundation/mailer"
+	"github.com/dmanias/startupers/foundation/oidc"
+	"github.com/dmanias/startupers/foundation/worker"
 	"github.com/rs/cors"
 	"go.uber.org/zap"
 	"net/http"
+	"net/mail"
 	"os"
 	"os/signal"
 	"runtime"
+	"strings"
 	"syscall"
 	"time"
 )
@@ -82,6 +103,7 @@ func run(log *zap.SugaredLogger) error {
 			IdleTimeout        time.Duration `conf:"default:150s"`
 			ShutdownTimeout    time.Duration `conf:"default:60s"`
 			CORSAllowedOrigins []string      `conf:"default:*"`
+			AppURL             string        `conf:"default:http://localhost:3000"`
 			//CORSAllowedOrigins []string `conf:"default:http://localhost:3000"`
 		}
 		AI struct {
@@ -101,6 +123,64 @@ func run(log *zap.SugaredLogger) error {
 			KeysFolder string `conf:"default:zarf/keys/"`
 			ActiveKID  string `conf:"env:ACTIVE_KID"`
 			Issuer     string `conf:"default:BackEnd"`
+			// SessionPurgeInterval is how often expired refresh tokens and
+			// denylist entries are removed.
+			SessionPurgeInterval time.Duration `conf:"default:1h"`
+			// UserCacheTTL is how long a user found to be enabled is trusted
+			// before the database is asked again.
+			UserCacheTTL time.Duration `conf:"default:30s"`
+			RefreshRoles bool          `conf:"default:true"`
+		}
+		Login struct {
+			// Each failed sign in doubles the wait before the next one, from
+			// BaseDelay up to MaxDelay. Reaching a threshold locks the email
+			// or IP address out for Lockout. Failures are forgotten after
+			// Window without any.
+			AccountThreshold int           `conf:"default:5"`
+			IPThreshold      int           `conf:"default:20"`
+			BaseDelay        time.Duration `conf:"default:1s"`
+			MaxDelay         time.Duration `conf:"default:30s"`
+			Lockout          time.Duration `conf:"default:15m"`
+			Window           time.Duration `conf:"default:1h"`
+			PurgeInterval    time.Duration `conf:"default:1h"`
+		}
+		Explore struct {
+			TrendingInterval time.Duration `conf:"default:10m"`
+			PostsWeight      float64       `conf:"default:1"`
+			VotesWeight      float64       `conf:"default:1"`
+			FollowsWeight    float64       `conf:"default:1"`
+			Gravity          float64       `conf:"default:1.8"`
+			Window           time.Duration `conf:"default:168h"`
+		}
+		Trash struct {
+			Retention     time.Duration `conf:"default:720h"`
+			PurgeInterval time.Duration `conf:"default:1h"`
+		}
+		Account struct {
+			// DeletionGracePeriod is how long after asking for it an account
+			// is deleted. Users can change their mind until then.
+			DeletionGracePeriod time.Duration `conf:"default:720h"`
+			DeletionInterval    time.Duration `conf:"default:1h"`
+			// ExportTTL is how long a data export can be downloaded.
+			ExportTTL      time.Duration `conf:"default:168h"`
+			ExportInterval time.Duration `conf:"default:1m"`
+		}
+		Mail struct {
+			Mailer          string        `conf:"default:file"`
+			Dir             string        `conf:"default:/tmp/startupers/mail"`
+			From            string        `conf:"default:Startupers <no-reply@startupers.io>"`
+			SMTPHost        string        `conf:"default:localhost"`
+			SMTPPort        int           `conf:"default:587"`
+			SMTPUsername    string        `conf:"env:SMTP_USERNAME"`
+			SMTPPassword    string        `conf:"env:SMTP_PASSWORD,mask"`
+			DeliverInterval time.Duration `conf:"default:30s"`
+			PurgeInterval   time.Duration `conf:"default:1h"`
+		}
+		OIDC struct {
+			// Providers names the OpenID Connect providers users can sign in
+			// with. Each is configured with the OIDC_<NAME>_ISSUER,
+			// OIDC_<NAME>_CLIENT_ID and OIDC_<NAME>_CLIENT_SECRET variables.
+			Providers []string
 		}
 		Build struct {
 			Build string `conf:"default:0.3"`
@@ -170,6 +250,115 @@ func run(log *zap.SugaredLogger) error {
 		}
 	}()
 
+	// -------------------------------------------------------------------------
+	// Mail Support
+
+	log.Infow("startup", "status", "initializing mail support", "mailer", cfg.Mail.Mailer)
+
+	var mlr mailer.Mailer
+	switch cfg.Mail.Mailer {
+	case "smtp":
+		mlr = mailer.NewSMTP(mailer.SMTPConfig{
+			Host:     cfg.Mail.SMTPHost,
+			Port:     cfg.Mail.SMTPPort,
+			Username: cfg.Mail.SMTPUsername,
+			Password: cfg.Mail.SMTPPassword,
+		})
+	case "file":
+		fileMailer, err := mailer.NewFile(cfg.Mail.Dir)
+		if err != nil {
+			return fmt.Errorf("constructing mailer: %w", err)
+		}
+		mlr = fileMailer
+	default:
+		return fmt.Errorf("unknown mailer %q", cfg.Mail.Mailer)
+	}
+
+	mailFrom, err := mail.ParseAddress(cfg.Mail.From)
+	if err != nil {
+		return fmt.Errorf("parsing mail sender: %w", err)
+	}
+
+	// -------------------------------------------------------------------------
+	// Business Support
+
+	log.Infow("startup", "status", "initializing business support")
+
+	// The API and the background jobs share the cores, so the jobs run with
+	// the same guards, purgers and recorders as the requests.
+	cores := handlers.NewCores(log, db, mlr, *mailFrom)
+
+	// -------------------------------------------------------------------------
+	// Background Jobs
+
+	log.Infow("startup", "status", "initializing background jobs")
+
+	wrk := worker.New(log)
+	defer func() {
+		log.Infow("shutdown", "status", "stopping background jobs")
+
+		ctx, cancel := context.WithTimeout(context.Background(), cfg.Web.ShutdownTimeout)
+		defer cancel()
+
+		if err := wrk.Shutdown(ctx); err != nil {
+			log.Errorw("shutdown", "status", "stopping background jobs", "ERROR", err)
+		}
+	}()
+
+	beginner := sqldb.NewBeginner(db)
+
+	weights := explore.Weights{
+		Posts:   cfg.Explore.PostsWeight,
+		Votes:   cfg.Explore.VotesWeight,
+		Follows: cfg.Explore.FollowsWeight,
+		Gravity: cfg.Explore.Gravity,
+		Window:  cfg.Explore.Window,
+	}
+	wrk.Every("trending-scores", cfg.Explore.TrendingInterval, func(ctx context.Context) error {
+		return cores.Explore.RecomputeScores(ctx, weights)
+	})
+
+	trashCore := trash.NewCore(log, beginner, cfg.Trash.Retention, cores.Idea, cores.Post, cores.Challenge)
+
+	wrk.Every("trash-purge", cfg.Trash.PurgeInterval, func(ctx context.Context) error {
+		files, err := trashCore.Purge(ctx)
+
+		// Files are only removed once the content using them is gone for good.
+		for _, file := range files {
+			if err := uploads.Remove(file); err != nil {
+				log.Errorw("trash-purge", "status", "removing file", "file", file, "ERROR", err)
+			}
+		}
+
+		return err
+	})
+
+	wrk.Every("email-delivery", cfg.Mail.DeliverInterval, cores.Email.Deliver)
+	wrk.Every("email-purge", cfg.Mail.PurgeInterval, cores.Email.Purge)
+
+	// -------------------------------------------------------------------------
+	// Initialize account deletion and data export support
+
+	log.Infow("startup", "status", "initializing account deletion and data export support")
+
+	deletionCore := deletion.NewCore(log, beginner, deletiondb.NewStore(log, db), cores.Idea, cores.Email, audit.NewCore(log, auditdb.NewStore(log, db)), cfg.Web.AppURL, cfg.Account.DeletionGracePeriod)
+	wrk.Every("account-deletion", cfg.Account.DeletionInterval, func(ctx context.Context) error {
+		files, err := deletionCore.EraseDue(ctx)
+
+		// Files are only removed once the content using them is gone for good.
+		for _, file := range files {
+			if err := uploads.Remove(file); err != nil {
+				log.Errorw("account-deletion", "status", "removing file", "file", file, "ERROR", err)
+			}
+		}
+
+		return err
+	})
+
+	exportCore := export.NewCore(log, exportdb.NewStore(log, db), cores.User, cores.Email, cfg.Web.AppURL, cfg.Account.ExportTTL)
+	wrk.Every("data-export", cfg.Account.ExportInterval, exportCore.Build)
+	wrk.Every("data-export-purge", cfg.Account.DeletionInterval, exportCore.Purge)
+
 	// -------------------------------------------------------------------------
 	// Initialize authentication support
 
@@ -181,10 +370,34 @@ func run(log *zap.SugaredLogger) error {
 		return fmt.Errorf("reading keys: %w", err)
 	}
 
+	loginPolicy := lockout.Policy{
+		AccountThreshold: cfg.Login.AccountThreshold,
+		IPThreshold:      cfg.Login.IPThreshold,
+		BaseDelay:        cfg.Login.BaseDelay,
+		MaxDelay:         cfg.Login.MaxDelay,
+		Lockout:          cfg.Login.Lockout,
+		Window:           cfg.Login.Window,
+	}
+	lockoutCore := lockout.NewCore(log, beginner, lockoutdb.NewStore(log, db), loginPolicy)
+	wrk.Every("login-attempts-purge", cfg.Login.PurgeInterval, lockoutCore.Purge)
+
+	// Access tokens of sessions that were ended are denied until they expire.
+	sessionCore := session.NewCore(log, sessiondb.NewStore(log, db))
+	wrk.Every("session-purge", cfg.Auth.SessionPurgeInterval, sessionCore.Purge)
+
+	// Disabled users lose access, and role changes take effect, within the
+	// cache TTL rather than when their token expires.
 	authCfg := auth.Config{
-		Log:       log,
-		KeyLookup: ks,
-		Issuer:    cfg.Auth.Issuer,
+		Log:          log,
+		KeyLookup:    ks,
+		Issuer:       cfg.Auth.Issuer,
+		Denylist:     sessionCore,
+		UserLookup:   cores.User,
+		UserCacheTTL: cfg.Auth.UserCacheTTL,
+		RefreshRoles: cfg.Auth.RefreshRoles,
+
+		// Scripts and command line clients use personal access tokens.
+		PersonalTokens: pat.NewCore(log, patdb.NewStore(log, db)),
 	}
 
 	authConf, err := auth.New(authCfg)
@@ -192,6 +405,29 @@ func run(log *zap.SugaredLogger) error {
 		return fmt.Errorf("constructing auth: %w", err)
 	}
 
+	// -------------------------------------------------------------------------
+	// Initialize OpenID Connect support
+
+	log.Infow("startup", "status", "initializing oidc support", "providers", cfg.OIDC.Providers)
+
+	oidcProviders := make([]*oidc.Provider, 0, len(cfg.OIDC.Providers))
+	for _, name := range cfg.OIDC.Providers {
+		env := "OIDC_" + strings.ToUpper(name) + "_"
+
+		pcfg := oidc.Config{
+			Name:         name,
+			Issuer:       os.Getenv(env + "ISSUER"),
+			ClientID:     os.Getenv(env + "CLIENT_ID"),
+			ClientSecret: os.Getenv(env + "CLIENT_SECRET"),
+			RedirectURL:  strings.TrimSuffix(cfg.Web.AppURL, "/") + "/oidc/" + name + "/callback",
+		}
+		if pcfg.Issuer == "" || pcfg.ClientID == "" {
+			return fmt.Errorf("oidc provider %q: %sISSUER and %sCLIENT_ID are required", name, env, env)
+		}
+
+		oidcProviders = append(oidcProviders, oidc.NewProvider(pcfg, nil))
+	}
+
 	// -------------------------------------------------------------------------
 	// Serve static files from the "uploads" directory
 	fs := http.FileServer(http.Dir("./uploads"))
@@ -207,6 +443,7 @@ func run(log *zap.SugaredLogger) error {
 		Log:       log,
 		KeyLookup: ks,
 		Issuer:    cfg.Auth.Issuer,
+		Denylist:  sessionCore,
 	}
 
 	apiMux := handlers.APIMux(handlers.APIMuxConfig{
@@ -219,6 +456,15 @@ func run(log *zap.SugaredLogger) error {
 		Build:      cfg.Build.Build,
 		ActiveKID:  cfg.Auth.ActiveKID,
 		APIHost:    cfg.Web.APIHost,
+
+		TrashRetention: cfg.Trash.Retention,
+		Cores:          cores,
+		AppURL:         cfg.Web.AppURL,
+		OIDCProviders:  oidcProviders,
+
+		DeletionGracePeriod: cfg.Account.DeletionGracePeriod,
+		ExportTTL:           cfg.Account.ExportTTL,
+		LoginPolicy:         loginPolicy,
 	})
 
 	corsOptions := cors.Options{
@@ -2,35 +2,94 @@ package handlers
 
 import (
 	"context"
+	"github.com/dmanias/startupers/app/services/api/handlers/v1/admingrp"
 	"github.com/dmanias/startupers/app/services/api/handlers/v1/aigrp"
+	"github.com/dmanias/startupers/app/services/api/handlers/v1/categorygrp"
 	"github.com/dmanias/startupers/app/services/api/handlers/v1/challengegrp"
 	"github.com/dmanias/startupers/app/services/api/handlers/v1/checkgrp"
+	"github.com/dmanias/startupers/app/services/api/handlers/v1/commentgrp"
+	"github.com/dmanias/startupers/app/services/api/handlers/v1/exploregrp"
+	"github.com/dmanias/startupers/app/services/api/handlers/v1/feedgrp"
 	"github.com/dmanias/startupers/app/services/api/handlers/v1/ideagrp"
+	"github.com/dmanias/startupers/app/services/api/handlers/v1/invitationgrp"
 	"github.com/dmanias/startupers/app/services/api/handlers/v1/moderationgrp"
+	"github.com/dmanias/startupers/app/services/api/handlers/v1/notificationgrp"
+	"github.com/dmanias/startupers/app/services/api/handlers/v1/patgrp"
 	"github.com/dmanias/startupers/app/services/api/handlers/v1/postgrp"
+	"github.com/dmanias/startupers/app/services/api/handlers/v1/privacygrp"
+	"github.com/dmanias/startupers/app/services/api/handlers/v1/searchgrp"
+	"github.com/dmanias/startupers/app/services/api/handlers/v1/taggrp"
 	"github.com/dmanias/startupers/app/services/api/handlers/v1/testgrp"
+	"github.com/dmanias/startupers/app/services/api/handlers/v1/trashgrp"
 	"github.com/dmanias/startupers/app/services/api/handlers/v1/usergrp"
+	"github.com/dmanias/startupers/business/core/account"
+	"github.com/dmanias/startupers/business/core/account/stores/accountdb"
+	"github.com/dmanias/startupers/business/core/activity"
+	"github.com/dmanias/startupers/business/core/activity/stores/activitydb"
 	"github.com/dmanias/startupers/business/core/ai"
 	"github.com/dmanias/startupers/business/core/ai/stores/aidb"
+	"github.com/dmanias/startupers/business/core/audit"
+	"github.com/dmanias/startupers/business/core/audit/stores/auditdb"
+	"github.com/dmanias/startupers/business/core/category"
+	"github.com/dmanias/startupers/business/core/category/stores/categorydb"
 	"github.com/dmanias/startupers/business/core/challenge"
 	challengedb "github.com/dmanias/startupers/business/core/challenge/stores/challengedb"
+	"github.com/dmanias/startupers/business/core/comment"
+	"github.com/dmanias/startupers/business/core/comment/stores/commentdb"
+	"github.com/dmanias/startupers/business/core/deletion"
+	"github.com/dmanias/startupers/business/core/deletion/stores/deletiondb"
+	"github.com/dmanias/startupers/business/core/email"
+	"github.com/dmanias/startupers/business/core/email/stores/emaildb"
+	"github.com/dmanias/startupers/business/core/explore"
+	"github.com/dmanias/startupers/business/core/explore/stores/exploredb"
+	"github.com/dmanias/startupers/business/core/export"
+	"github.com/dmanias/startupers/business/core/export/stores/exportdb"
+	"github.com/dmanias/startupers/business/core/follow"
+	"github.com/dmanias/startupers/business/core/follow/stores/followdb"
+	"github.com/dmanias/startupers/business/core/fork"
 	"github.com/dmanias/startupers/business/core/idea"
 	"github.com/dmanias/startupers/business/core/idea/stores/ideadb"
+	"github.com/dmanias/startupers/business/core/identity"
+	"github.com/dmanias/startupers/business/core/identity/stores/identitydb"
+	"github.com/dmanias/startupers/business/core/invitation"
+	"github.com/dmanias/startupers/business/core/invitation/stores/invitationdb"
+	"github.com/dmanias/startupers/business/core/lockout"
+	"github.com/dmanias/startupers/business/core/lockout/stores/lockoutdb"
 	"github.com/dmanias/startupers/business/core/moderator"
 	"github.com/dmanias/startupers/business/core/moderator/stores/moderatordb"
+	"github.com/dmanias/startupers/business/core/notification"
+	"github.com/dmanias/startupers/business/core/notification/stores/notificationdb"
+	"github.com/dmanias/startupers/business/core/pat"
+	"github.com/dmanias/startupers/business/core/pat/stores/patdb"
 	"github.com/dmanias/startupers/business/core/post"
 	postdb "github.com/dmanias/startupers/business/core/post/stores/postdb"
+	"github.com/dmanias/startupers/business/core/search"
+	"github.com/dmanias/startupers/business/core/search/stores/searchdb"
+	"github.com/dmanias/startupers/business/core/session"
+	"github.com/dmanias/startupers/business/core/session/stores/sessiondb"
+	"github.com/dmanias/startupers/business/core/tag"
+	"github.com/dmanias/startupers/business/core/tag/stores/tagdb"
+	"github.com/dmanias/startupers/business/core/trash"
 	"github.com/dmanias/startupers/business/core/user"
 	"github.com/dmanias/startupers/business/core/user/stores/userdb"
+	"github.com/dmanias/startupers/business/data/sqldb"
 	"github.com/dmanias/startupers/business/web/auth"
 	"github.com/dmanias/startupers/business/web/v1/mid"
+	"github.com/dmanias/startupers/foundation/mailer"
+	"github.com/dmanias/startupers/foundation/oidc"
 	"github.com/dmanias/startupers/foundation/web"
 	"github.com/jmoiron/sqlx"
 	"go.uber.org/zap"
 	"net/http"
+	"net/mail"
 	"os"
+	"time"
 )
 
+// minPrototypeChallenges is the number of challenges an idea has to complete
+// before it can move to the prototype stage.
+const minPrototypeChallenges = 3
+
 // APIMuxConfig contains all the mandatory systems required by handlers.
 type APIMuxConfig struct {
 	Shutdown      chan os.Signal
@@ -44,7 +103,87 @@ type APIMuxConfig struct {
 	ActiveKID     string
 	AIType        string
 	APIHost       string
-	//GoogleOauthConfig *oauth2.Config
+	// TrashRetention is how long deleted content can be restored.
+	TrashRetention time.Duration
+	// Cores are the business cores shared with the background jobs.
+	Cores Cores
+	// AppURL is where the web application the links in emails point to is.
+	AppURL string
+	// OIDCProviders are the OpenID Connect providers users can sign in with.
+	OIDCProviders []*oidc.Provider
+	// DeletionGracePeriod is how long after asking for it an account is
+	// deleted, and ExportTTL how long a data export can be downloaded.
+	DeletionGracePeriod time.Duration
+	ExportTTL           time.Duration
+	// LoginPolicy is how repeated failed sign ins are slowed down and
+	// locked out.
+	LoginPolicy lockout.Policy
+}
+
+// Cores holds the business cores the API shares with the background jobs, so
+// both work with the same guards, purgers and recorders.
+type Cores struct {
+	User         *user.Core
+	Email        *email.Core
+	Activity     *activity.Core
+	Notification *notification.Core
+	Tag          *tag.Core
+	Idea         *idea.Core
+	Post         *post.Core
+	Challenge    *challenge.Core
+	Explore      *explore.Core
+}
+
+// NewCores constructs the shared business cores and wires them together.
+// Emails are sent through the mailer from the specified address.
+func NewCores(log *zap.SugaredLogger, db *sqlx.DB, mlr mailer.Mailer, mailFrom mail.Address) Cores {
+	beginner := sqldb.NewBeginner(db)
+
+	postCore := post.NewCore(postdb.NewStore(log, db))
+	ideaCore := idea.NewCore(log, beginner, ideadb.NewStore(log, db))
+	usrCore := user.NewCore(userdb.NewStore(log, db))
+	challengeCore := challenge.NewCore(challengedb.NewStore(log, db))
+
+	// An idea needs completed challenges before it can be prototyped.
+	ideaCore.AddStageGuard(idea.StagePrototype, idea.MinCompletedChallenges(minPrototypeChallenges, challengeCore))
+
+	// Only users who verified their email address can create ideas.
+	ideaCore.AddAuthorGuard(idea.VerifiedEmail(usrCore))
+
+	// Purging an idea purges its posts, including AI answers, and challenges.
+	ideaCore.AddContentPurger("posts", postCore)
+	ideaCore.AddContentPurger("challenges", challengeCore)
+
+	// New ideas, posts, stage changes and completed challenges show up in the
+	// activity feed of the followers.
+	activityCore := activity.NewCore(log, activitydb.NewStore(log, db))
+	ideaCore.AddActivityRecorder(activityCore)
+	postCore.AddActivityRecorder(activityCore)
+	challengeCore.AddActivityRecorder(activityCore)
+
+	// Tags entered on ideas are normalised against the tag vocabulary.
+	tagCore := tag.NewCore(log, beginner, tagdb.NewStore(log, db), ideaCore)
+	ideaCore.SetTagNormalizer(tagCore)
+
+	// Emails are queued in the outbox and sent by a background job.
+	emailCore := email.NewCore(log, emaildb.NewStore(log, db), mlr, mailFrom)
+
+	// Collaborators are notified of new posts and authors of AI answers.
+	notificationCore := notification.NewCore(log, notificationdb.NewStore(log, db), ideaCore, usrCore)
+	notificationCore.AddChannel(notification.NewEmailChannel(emailCore, ideaCore))
+	postCore.AddActivityRecorder(notificationCore)
+
+	return Cores{
+		User:         usrCore,
+		Email:        emailCore,
+		Activity:     activityCore,
+		Notification: notificationCore,
+		Tag:          tagCore,
+		Idea:         ideaCore,
+		Post:         postCore,
+		Challenge:    challengeCore,
+		Explore:      explore.NewCore(log, beginner, exploredb.NewStore(log, db), ideaCore),
+	}
 }
 
 // APIMux constructs a http.Handler with all application routes defined.
@@ -62,6 +201,9 @@ func APIMux(cfg APIMuxConfig) *web.App {
 	if cfg.AuthConfig == nil {
 		panic("cfg.AuthConfig is nil")
 	}
+	if cfg.Cores.Idea == nil {
+		panic("cfg.Cores is not set")
+	}
 
 	// Initialize the moderator.Core and moderationgrp.Handlers instances
 	moderatorCore := moderator.NewCore(moderatordb.NewStore(cfg.Log, cfg.DB))
@@ -78,34 +220,61 @@ func APIMux(cfg APIMuxConfig) *web.App {
 	aiCore := ai.NewCore(aidb.NewStore(cfg.Log, cfg.DB))
 	mgh := moderationgrp.New(moderatorCore)
 	cfg.Log.Info("cfg.Auth", cfg.Auth)
-	// Initialize the post.Core and challengegrp.Handlers instances
-	postCore := post.NewCore(postdb.NewStore(cfg.Log, cfg.DB))
-	ideaCore := idea.NewCore(ideadb.NewStore(cfg.Log, cfg.DB))
+	// The business cores are shared with the background jobs.
+	postCore := cfg.Cores.Post
+	ideaCore := cfg.Cores.Idea
+	usrCore := cfg.Cores.User
+	challengeCore := cfg.Cores.Challenge
+	activityCore := cfg.Cores.Activity
+	tagCore := cfg.Cores.Tag
+	emailCore := cfg.Cores.Email
+	notificationCore := cfg.Cores.Notification
+
+	// Invitees are emailed the link to accept their invitation, and notified
+	// of it.
+	invitationCore := invitation.NewCore(cfg.Log, sqldb.NewBeginner(cfg.DB), invitationdb.NewStore(cfg.Log, cfg.DB), ideaCore, usrCore, emailCore, cfg.AppURL)
+	invitationCore.AddActivityRecorder(notificationCore)
+	forkCore := fork.NewCore(cfg.Log, sqldb.NewBeginner(cfg.DB), ideaCore, postCore, challengeCore)
+	categoryCore := category.NewCore(cfg.Log, categorydb.NewStore(cfg.Log, cfg.DB))
 	aiHandlers := aigrp.New(aiCore, aigrpCfg, mgh, postCore, ideaCore)
-	ideaHandlers := ideagrp.New(ideaCore, cfg.Log, aiHandlers, mgh, cfg.APIHost)
+	ideaHandlers := ideagrp.New(ideaCore, invitationCore, forkCore, categoryCore, cfg.Log, aiHandlers, mgh, cfg.APIHost)
 	// Update the aigrp.New function call to include ideaCore and postCore
-	postHandlers := postgrp.New(postCore, cfg.Log, aiHandlers, mgh)
+	postHandlers := postgrp.New(postCore, ideaCore, cfg.Log, aiHandlers, mgh)
 
 	// Add the routes for idea-related operations
-	app.Handle(http.MethodPost, "/ideas", ideaHandlers.Create, mid.Authenticate(cfg.Auth), mid.Authorize(cfg.Auth, auth.RuleUserOnly))
-	app.Handle(http.MethodGet, "/ideas/:idea_id", ideaHandlers.QueryByID, mid.Authenticate(cfg.Auth), mid.Authorize(cfg.Auth, auth.RuleUserOnly))
-	//app.Handle(http.MethodPut, "/ideas/:idea_id", ideaHandlers.Update, mid.Authenticate(cfg.Auth), mid.Authorize(cfg.Auth, auth.RuleUserOnly))
-	app.Handle(http.MethodDelete, "/ideas/:idea_id", ideaHandlers.Delete, mid.Authenticate(cfg.Auth), mid.Authorize(cfg.Auth, auth.RuleUserOnly))
-	app.Handle(http.MethodGet, "/:user_id/ideas", ideaHandlers.Query, mid.Authenticate(cfg.Auth), mid.Authorize(cfg.Auth, auth.RuleUserOnly))
-	app.Handle(http.MethodGet, "/tags", ideaHandlers.QueryTags, mid.Authenticate(cfg.Auth), mid.Authorize(cfg.Auth, auth.RuleUserOnly))
+	app.Handle(http.MethodPost, "/ideas", ideaHandlers.Create, mid.Authenticate(cfg.Auth), mid.Authorize(cfg.Auth, auth.RuleUserOnly, pat.ScopeIdeasWrite))
+	app.Handle(http.MethodGet, "/ideas/:idea_id", ideaHandlers.QueryByID, mid.Authenticate(cfg.Auth), mid.Authorize(cfg.Auth, auth.RuleUserOnly, pat.ScopeIdeasRead))
+	app.Handle(http.MethodPut, "/ideas/:idea_id", ideaHandlers.Update, mid.Authenticate(cfg.Auth), mid.Authorize(cfg.Auth, auth.RuleUserOnly, pat.ScopeIdeasWrite))
+	app.Handle(http.MethodDelete, "/ideas/:idea_id", ideaHandlers.Delete, mid.Authenticate(cfg.Auth), mid.Authorize(cfg.Auth, auth.RuleUserOnly, pat.ScopeIdeasWrite))
+	app.Handle(http.MethodPost, "/ideas/:idea_id/stage", ideaHandlers.TransitionStage, mid.Authenticate(cfg.Auth), mid.Authorize(cfg.Auth, auth.RuleUserOnly, pat.ScopeIdeasWrite))
+	app.Handle(http.MethodGet, "/ideas/:idea_id/stage/history", ideaHandlers.QueryStageHistory, mid.Authenticate(cfg.Auth), mid.Authorize(cfg.Auth, auth.RuleUserOnly, pat.ScopeIdeasRead))
+	app.Handle(http.MethodPost, "/ideas/:idea_id/fork", ideaHandlers.Fork, mid.Authenticate(cfg.Auth), mid.Authorize(cfg.Auth, auth.RuleUserOnly, pat.ScopeIdeasWrite))
+	app.Handle(http.MethodGet, "/ideas/:idea_id/forks", ideaHandlers.QueryForks, mid.Authenticate(cfg.Auth), mid.Authorize(cfg.Auth, auth.RuleUserOnly, pat.ScopeIdeasRead))
+	app.Handle(http.MethodGet, "/ideas/:idea_id/lineage", ideaHandlers.QueryLineage, mid.Authenticate(cfg.Auth), mid.Authorize(cfg.Auth, auth.RuleUserOnly, pat.ScopeIdeasRead))
+	app.Handle(http.MethodGet, "/ideas/:idea_id/revisions", ideaHandlers.QueryRevisions, mid.Authenticate(cfg.Auth), mid.Authorize(cfg.Auth, auth.RuleUserOnly, pat.ScopeIdeasRead))
+	app.Handle(http.MethodGet, "/ideas/:idea_id/revisions/diff", ideaHandlers.DiffRevisions, mid.Authenticate(cfg.Auth), mid.Authorize(cfg.Auth, auth.RuleUserOnly, pat.ScopeIdeasRead))
+	app.Handle(http.MethodGet, "/ideas/:idea_id/revisions/:number", ideaHandlers.QueryRevision, mid.Authenticate(cfg.Auth), mid.Authorize(cfg.Auth, auth.RuleUserOnly, pat.ScopeIdeasRead))
+	app.Handle(http.MethodPost, "/ideas/:idea_id/revisions/:number/restore", ideaHandlers.RestoreRevision, mid.Authenticate(cfg.Auth), mid.Authorize(cfg.Auth, auth.RuleUserOnly, pat.ScopeIdeasWrite))
+	app.Handle(http.MethodPost, "/ideas/:idea_id/votes", ideaHandlers.Vote, mid.Authenticate(cfg.Auth), mid.Authorize(cfg.Auth, auth.RuleUserOnly, pat.ScopeIdeasWrite))
+	app.Handle(http.MethodDelete, "/ideas/:idea_id/votes", ideaHandlers.Unvote, mid.Authenticate(cfg.Auth), mid.Authorize(cfg.Auth, auth.RuleUserOnly, pat.ScopeIdeasWrite))
+	app.Handle(http.MethodGet, "/ideas/:idea_id/votes", ideaHandlers.QueryVotes, mid.Authenticate(cfg.Auth), mid.Authorize(cfg.Auth, auth.RuleUserOnly, pat.ScopeIdeasRead))
+	app.Handle(http.MethodGet, "/:user_id/ideas", ideaHandlers.Query, mid.Authenticate(cfg.Auth), mid.Authorize(cfg.Auth, auth.RuleUserOnly, pat.ScopeIdeasRead))
 	// Add the routes for post-related operations
-	app.Handle(http.MethodPost, "/ideas/posts", postHandlers.Create, mid.Authenticate(cfg.Auth), mid.Authorize(cfg.Auth, auth.RuleUserOnly))
-	app.Handle(http.MethodGet, "/posts/:post_id", postHandlers.QueryByID, mid.Authenticate(cfg.Auth), mid.Authorize(cfg.Auth, auth.RuleUserOnly))
+	app.Handle(http.MethodPost, "/ideas/posts", postHandlers.Create, mid.Authenticate(cfg.Auth), mid.Authorize(cfg.Auth, auth.RuleUserOnly, pat.ScopeIdeasWrite))
+	app.Handle(http.MethodGet, "/posts/:post_id", postHandlers.QueryByID, mid.Authenticate(cfg.Auth), mid.Authorize(cfg.Auth, auth.RuleUserOnly, pat.ScopeIdeasRead))
 	//app.Handle(http.MethodPut, "/posts/:post_id", postHandlers.Update, mid.Authenticate(cfg.Auth), mid.Authorize(cfg.Auth, auth.RuleUserOnly))
-	app.Handle(http.MethodDelete, "/posts/:post_id", postHandlers.Delete, mid.Authenticate(cfg.Auth), mid.Authorize(cfg.Auth, auth.RuleUserOnly))
-	app.Handle(http.MethodGet, "/ideas/:idea_id/posts", postHandlers.Query, mid.Authenticate(cfg.Auth), mid.Authorize(cfg.Auth, auth.RuleUserOnly))
+	app.Handle(http.MethodDelete, "/posts/:post_id", postHandlers.Delete, mid.Authenticate(cfg.Auth), mid.Authorize(cfg.Auth, auth.RuleUserOnly, pat.ScopeIdeasWrite))
+	app.Handle(http.MethodGet, "/ideas/:idea_id/posts", postHandlers.Query, mid.Authenticate(cfg.Auth), mid.Authorize(cfg.Auth, auth.RuleUserOnly, pat.ScopeIdeasRead))
+	app.Handle(http.MethodPost, "/posts/:post_id/reactions", postHandlers.React, mid.Authenticate(cfg.Auth), mid.Authorize(cfg.Auth, auth.RuleUserOnly, pat.ScopeIdeasWrite))
+	app.Handle(http.MethodDelete, "/posts/:post_id/reactions/:emoji", postHandlers.Unreact, mid.Authenticate(cfg.Auth), mid.Authorize(cfg.Auth, auth.RuleUserOnly, pat.ScopeIdeasWrite))
+	app.Handle(http.MethodGet, "/posts/:post_id/reactions", postHandlers.QueryReactions, mid.Authenticate(cfg.Auth), mid.Authorize(cfg.Auth, auth.RuleUserOnly, pat.ScopeIdeasRead))
 
 	// Add the routes for moderator-related operations
 	app.Handle(http.MethodPost, "/moderators", mgh.Create, mid.Authenticate(cfg.Auth), mid.Authorize(cfg.Auth, auth.RuleAdminOnly))
 	app.Handle(http.MethodGet, "/moderators/:name", mgh.QueryByNameHandler, mid.Authenticate(cfg.Auth), mid.Authorize(cfg.Auth, auth.RuleUserOnly))
 	app.Handle(http.MethodGet, "/moderators", mgh.Query, mid.Authenticate(cfg.Auth), mid.Authorize(cfg.Auth, auth.RuleAdminOnly))
 
-	app.Handle(http.MethodGet, "/ask/:idea_id/:question_type/:description", aiHandlers.Ask, mid.Authenticate(cfg.Auth), mid.Authorize(cfg.Auth, auth.RuleUserOnly))
+	app.Handle(http.MethodGet, "/ask/:idea_id/:question_type/:description", aiHandlers.Ask, mid.Authenticate(cfg.Auth), mid.Authorize(cfg.Auth, auth.RuleUserOnly, pat.ScopeAIAsk))
 
 	///app.Handle(http.MethodGet, "/ask/:scenario/idea_id", aiHandlers.Ask, mid.Authenticate(cfg.Auth), mid.Authorize(cfg.Auth, auth.RuleUserOnly))
 	// Create a handlers instance for checkgrp
@@ -123,7 +292,6 @@ func APIMux(cfg APIMuxConfig) *web.App {
 	app.Handle(http.MethodGet, "/test/auth", testgrp.Test, mid.Authenticate(cfg.Auth), mid.Authorize(cfg.Auth, auth.RuleAdminOnly))
 
 	// -------------------------------------------------------------------------
-	usrCore := user.NewCore(userdb.NewStore(cfg.Log, cfg.DB))
 	authConfig := auth.Config{
 		Log:       cfg.Log, // or another *zap.SugaredLogger instance
 		KeyLookup: cfg.AuthConfig.KeyLookup,
@@ -134,10 +302,76 @@ func APIMux(cfg APIMuxConfig) *web.App {
 		cfg.Log.Errorf("Failed to create auth instance: %v", err)
 		return nil
 	}
-	ugh := usergrp.New(usrCore, authInstance, cfg.ActiveKID, cfg.Log)
+	// Email addresses are verified, and forgotten passwords reset, with
+	// single-use tokens sent by email.
+	accountCore := account.NewCore(cfg.Log, accountdb.NewStore(cfg.Log, cfg.DB), usrCore, emailCore, cfg.AppURL)
+
+	// Sessions are kept going with rotating refresh tokens. Resetting a
+	// password ends every session of the user.
+	sessionCore := session.NewCore(cfg.Log, sessiondb.NewStore(cfg.Log, cfg.DB))
+	accountCore.AddSessionRevoker(sessionCore)
+
+	// Users can also sign in with OpenID Connect providers, linked to their
+	// account by verified email address.
+	identityCore := identity.NewCore(cfg.Log, identitydb.NewStore(cfg.Log, cfg.DB), usrCore, cfg.OIDCProviders...)
+	identityCore.AddSessionRevoker(sessionCore)
+
+	// Failed sign ins are counted in the database, per email and IP address,
+	// so every replica slows down and locks out the same attempts.
+	lockoutCore := lockout.NewCore(cfg.Log, sqldb.NewBeginner(cfg.DB), lockoutdb.NewStore(cfg.Log, cfg.DB), cfg.LoginPolicy)
+
+	ugh := usergrp.New(usrCore, accountCore, sessionCore, identityCore, ideaCore, lockoutCore, authInstance, cfg.ActiveKID, cfg.Log)
 	app.Handle(http.MethodPost, "/users/login", ugh.Login)
+	app.Handle(http.MethodPost, "/users/token/refresh", ugh.Refresh)
+	app.Handle(http.MethodPost, "/users/logout", ugh.Logout)
+	app.Handle(http.MethodGet, "/users/oidc", ugh.OIDCProviders)
+	app.Handle(http.MethodGet, "/users/oidc/:provider/login", ugh.OIDCLogin)
+	app.Handle(http.MethodPost, "/users/oidc/:provider/callback", ugh.OIDCCallback)
 	app.Handle(http.MethodPost, "/users/register", ugh.Create)
-	//app.Handle(http.MethodGet, "/users", ugh.Query, mid.Authenticate(cfg.Auth), mid.Authorize(cfg.Auth, auth.RuleAdminOnly))
+	app.Handle(http.MethodPost, "/users/verify", ugh.Verify)
+	app.Handle(http.MethodPost, "/users/verify/resend", ugh.ResendVerification, mid.Authenticate(cfg.Auth), mid.Authorize(cfg.Auth, auth.RuleUserOnly))
+	app.Handle(http.MethodPost, "/users/password/forgot", ugh.ForgotPassword)
+	app.Handle(http.MethodPost, "/users/password/reset", ugh.ResetPassword)
+
+	// The account of the signed in user, and the public profiles of others.
+	app.Handle(http.MethodGet, "/users/me", ugh.QueryMe, mid.Authenticate(cfg.Auth), mid.Authorize(cfg.Auth, auth.RuleUserOnly))
+	app.Handle(http.MethodPut, "/users/me", ugh.UpdateMe, mid.Authenticate(cfg.Auth), mid.Authorize(cfg.Auth, auth.RuleUserOnly))
+	app.Handle(http.MethodPut, "/users/me/password", ugh.ChangePassword, mid.Authenticate(cfg.Auth), mid.Authorize(cfg.Auth, auth.RuleUserOnly))
+	app.Handle(http.MethodGet, "/users/:user_id", ugh.QueryProfile, mid.Authenticate(cfg.Auth), mid.Authorize(cfg.Auth, auth.RuleUserOnly, pat.ScopeIdeasRead))
+
+	// Users can download all of their data, and delete their account after a
+	// grace period. Neither can be done with a personal access token.
+	auditCore := audit.NewCore(cfg.Log, auditdb.NewStore(cfg.Log, cfg.DB))
+	deletionCore := deletion.NewCore(cfg.Log, sqldb.NewBeginner(cfg.DB), deletiondb.NewStore(cfg.Log, cfg.DB), ideaCore, emailCore, auditCore, cfg.AppURL, cfg.DeletionGracePeriod)
+	exportCore := export.NewCore(cfg.Log, exportdb.NewStore(cfg.Log, cfg.DB), usrCore, emailCore, cfg.AppURL, cfg.ExportTTL)
+	privacyHandlers := privacygrp.New(usrCore, exportCore, deletionCore, cfg.Log)
+	app.Handle(http.MethodPost, "/users/me/exports", privacyHandlers.RequestExport, mid.Authenticate(cfg.Auth), mid.Authorize(cfg.Auth, auth.RuleUserOnly))
+	app.Handle(http.MethodGet, "/users/me/exports", privacyHandlers.QueryExports, mid.Authenticate(cfg.Auth), mid.Authorize(cfg.Auth, auth.RuleUserOnly))
+	app.Handle(http.MethodGet, "/users/me/exports/:export_id/download", privacyHandlers.DownloadExport, mid.Authenticate(cfg.Auth), mid.Authorize(cfg.Auth, auth.RuleUserOnly))
+	app.Handle(http.MethodGet, "/users/me/deletion", privacyHandlers.QueryDeletion, mid.Authenticate(cfg.Auth), mid.Authorize(cfg.Auth, auth.RuleUserOnly))
+	app.Handle(http.MethodPost, "/users/me/deletion", privacyHandlers.RequestDeletion, mid.Authenticate(cfg.Auth), mid.Authorize(cfg.Auth, auth.RuleUserOnly))
+	app.Handle(http.MethodDelete, "/users/me/deletion", privacyHandlers.CancelDeletion, mid.Authenticate(cfg.Auth), mid.Authorize(cfg.Auth, auth.RuleUserOnly))
+
+	// Personal access tokens are managed with a signed in session, not with
+	// another personal access token.
+	patHandlers := patgrp.New(pat.NewCore(cfg.Log, patdb.NewStore(cfg.Log, cfg.DB)), cfg.Log)
+	app.Handle(http.MethodGet, "/users/tokens", patHandlers.Query, mid.Authenticate(cfg.Auth), mid.Authorize(cfg.Auth, auth.RuleUserOnly))
+	app.Handle(http.MethodPost, "/users/tokens", patHandlers.Create, mid.Authenticate(cfg.Auth), mid.Authorize(cfg.Auth, auth.RuleUserOnly))
+	app.Handle(http.MethodDelete, "/users/tokens/:token_id", patHandlers.Revoke, mid.Authenticate(cfg.Auth), mid.Authorize(cfg.Auth, auth.RuleUserOnly))
+
+	// Administrators manage user accounts. What they do is kept in the audit
+	// log, written in the same transaction as the change. Personal access
+	// tokens can't be used here.
+	adminHandlers := admingrp.New(sqldb.NewBeginner(cfg.DB), usrCore, ideaCore, accountCore, sessionCore, auditCore, deletionCore, lockoutCore, cfg.Auth, cfg.Log)
+	app.Handle(http.MethodGet, "/admin/users", adminHandlers.QueryUsers, mid.Authenticate(cfg.Auth), mid.Authorize(cfg.Auth, auth.RuleAdminOnly))
+	app.Handle(http.MethodGet, "/admin/users/:user_id", adminHandlers.QueryUserByID, mid.Authenticate(cfg.Auth), mid.Authorize(cfg.Auth, auth.RuleAdminOnly))
+	app.Handle(http.MethodPut, "/admin/users/:user_id/enabled", adminHandlers.SetEnabled, mid.Authenticate(cfg.Auth), mid.Authorize(cfg.Auth, auth.RuleAdminOnly))
+	app.Handle(http.MethodPut, "/admin/users/:user_id/roles", adminHandlers.SetRoles, mid.Authenticate(cfg.Auth), mid.Authorize(cfg.Auth, auth.RuleAdminOnly))
+	app.Handle(http.MethodPost, "/admin/users/:user_id/password/reset", adminHandlers.ForcePasswordReset, mid.Authenticate(cfg.Auth), mid.Authorize(cfg.Auth, auth.RuleAdminOnly))
+	app.Handle(http.MethodDelete, "/admin/users/:user_id", adminHandlers.DeleteUser, mid.Authenticate(cfg.Auth), mid.Authorize(cfg.Auth, auth.RuleAdminOnly))
+	app.Handle(http.MethodGet, "/admin/lockouts", adminHandlers.QueryLockouts, mid.Authenticate(cfg.Auth), mid.Authorize(cfg.Auth, auth.RuleAdminOnly))
+	app.Handle(http.MethodDelete, "/admin/lockouts/:lockout_id", adminHandlers.Unlock, mid.Authenticate(cfg.Auth), mid.Authorize(cfg.Auth, auth.RuleAdminOnly))
+	app.Handle(http.MethodGet, "/admin/audit", adminHandlers.QueryAudit, mid.Authenticate(cfg.Auth), mid.Authorize(cfg.Auth, auth.RuleAdminOnly))
 
 	// Serve static files from the "uploads" directory
 	fs := http.FileServer(http.Dir("./uploads"))
@@ -148,15 +382,108 @@ func APIMux(cfg APIMuxConfig) *web.App {
 
 	//-------Challenge-------
 	// Initialize the challenge.Core and challengegrp.Handlers instances
-	challengeCore := challenge.NewCore(challengedb.NewStore(cfg.Log, cfg.DB))
-	challengeHandlers := challengegrp.New(challengeCore, cfg.Log)
+	challengeHandlers := challengegrp.New(challengeCore, ideaCore, cfg.Log)
 
 	// Add the routes for challenge-related operations
-	app.Handle(http.MethodPost, "/ideas/challenges", challengeHandlers.Create, mid.Authenticate(cfg.Auth), mid.Authorize(cfg.Auth, auth.RuleUserOnly))
+	app.Handle(http.MethodPost, "/ideas/challenges", challengeHandlers.Create, mid.Authenticate(cfg.Auth), mid.Authorize(cfg.Auth, auth.RuleUserOnly, pat.ScopeIdeasWrite))
 	//app.Handle(http.MethodGet, "/challenges/:challenge_id", challengeHandlers.QueryByID, mid.Authenticate(cfg.Auth), mid.Authorize(cfg.Auth, auth.RuleUserOnly))
-	app.Handle(http.MethodPut, "/challenges/:challenge_id", challengeHandlers.Update, mid.Authenticate(cfg.Auth), mid.Authorize(cfg.Auth, auth.RuleUserOnly))
-	app.Handle(http.MethodDelete, "/challenges/:challenge_id", challengeHandlers.Delete, mid.Authenticate(cfg.Auth), mid.Authorize(cfg.Auth, auth.RuleUserOnly))
-	app.Handle(http.MethodGet, "/ideas/:idea_id/challenges", challengeHandlers.Query, mid.Authenticate(cfg.Auth), mid.Authorize(cfg.Auth, auth.RuleUserOnly))
+	app.Handle(http.MethodPut, "/challenges/:challenge_id", challengeHandlers.Update, mid.Authenticate(cfg.Auth), mid.Authorize(cfg.Auth, auth.RuleUserOnly, pat.ScopeIdeasWrite))
+	app.Handle(http.MethodDelete, "/challenges/:challenge_id", challengeHandlers.Delete, mid.Authenticate(cfg.Auth), mid.Authorize(cfg.Auth, auth.RuleUserOnly, pat.ScopeIdeasWrite))
+	app.Handle(http.MethodGet, "/ideas/:idea_id/challenges", challengeHandlers.Query, mid.Authenticate(cfg.Auth), mid.Authorize(cfg.Auth, auth.RuleUserOnly, pat.ScopeIdeasRead))
+
+	// Initialize the comment.Core and commentgrp.Handlers instances
+	commentCore := comment.NewCore(cfg.Log, commentdb.NewStore(cfg.Log, cfg.DB))
+	commentHandlers := commentgrp.New(commentCore, postCore, challengeCore, ideaCore, cfg.Log)
+
+	// Add the routes for threaded comments on posts and challenges
+	app.Handle(http.MethodPost, "/posts/:post_id/comments", commentHandlers.CreateOnPost, mid.Authenticate(cfg.Auth), mid.Authorize(cfg.Auth, auth.RuleUserOnly, pat.ScopeIdeasWrite))
+	app.Handle(http.MethodGet, "/posts/:post_id/comments", commentHandlers.QueryOnPost, mid.Authenticate(cfg.Auth), mid.Authorize(cfg.Auth, auth.RuleUserOnly, pat.ScopeIdeasRead))
+	app.Handle(http.MethodPost, "/challenges/:challenge_id/comments", commentHandlers.CreateOnChallenge, mid.Authenticate(cfg.Auth), mid.Authorize(cfg.Auth, auth.RuleUserOnly, pat.ScopeIdeasWrite))
+	app.Handle(http.MethodGet, "/challenges/:challenge_id/comments", commentHandlers.QueryOnChallenge, mid.Authenticate(cfg.Auth), mid.Authorize(cfg.Auth, auth.RuleUserOnly, pat.ScopeIdeasRead))
+	app.Handle(http.MethodPut, "/comments/:comment_id", commentHandlers.Update, mid.Authenticate(cfg.Auth), mid.Authorize(cfg.Auth, auth.RuleUserOnly, pat.ScopeIdeasWrite))
+	app.Handle(http.MethodDelete, "/comments/:comment_id", commentHandlers.Delete, mid.Authenticate(cfg.Auth), mid.Authorize(cfg.Auth, auth.RuleUserOnly, pat.ScopeIdeasWrite))
+	app.Handle(http.MethodPost, "/comments/:comment_id/hide", commentHandlers.Hide, mid.Authenticate(cfg.Auth), mid.Authorize(cfg.Auth, auth.RuleAdminOnly))
+	app.Handle(http.MethodDelete, "/comments/:comment_id/hide", commentHandlers.Unhide, mid.Authenticate(cfg.Auth), mid.Authorize(cfg.Auth, auth.RuleAdminOnly))
+
+	//-------Invitation-------
+	// Initialize the invitationgrp.Handlers instance
+	invitationHandlers := invitationgrp.New(invitationCore, ideaCore, usrCore, cfg.Log)
+
+	// Add the routes for invitation-related operations
+	app.Handle(http.MethodPost, "/ideas/:idea_id/invitations", invitationHandlers.Create, mid.Authenticate(cfg.Auth), mid.Authorize(cfg.Auth, auth.RuleUserOnly))
+	app.Handle(http.MethodGet, "/ideas/:idea_id/invitations", invitationHandlers.QueryByIdea, mid.Authenticate(cfg.Auth), mid.Authorize(cfg.Auth, auth.RuleUserOnly))
+	app.Handle(http.MethodGet, "/invitations", invitationHandlers.QueryIncoming, mid.Authenticate(cfg.Auth), mid.Authorize(cfg.Auth, auth.RuleUserOnly))
+	app.Handle(http.MethodPost, "/invitations/accept", invitationHandlers.AcceptToken, mid.Authenticate(cfg.Auth), mid.Authorize(cfg.Auth, auth.RuleUserOnly))
+	app.Handle(http.MethodPost, "/invitations/:invitation_id/accept", invitationHandlers.Accept, mid.Authenticate(cfg.Auth), mid.Authorize(cfg.Auth, auth.RuleUserOnly))
+	app.Handle(http.MethodPost, "/invitations/:invitation_id/decline", invitationHandlers.Decline, mid.Authenticate(cfg.Auth), mid.Authorize(cfg.Auth, auth.RuleUserOnly))
+	app.Handle(http.MethodDelete, "/invitations/:invitation_id", invitationHandlers.Revoke, mid.Authenticate(cfg.Auth), mid.Authorize(cfg.Auth, auth.RuleUserOnly))
+
+	//-------Search-------
+	// Initialize the search.Core and searchgrp.Handlers instances
+	searchCore := search.NewCore(searchdb.NewStore(cfg.Log, cfg.DB))
+	searchHandlers := searchgrp.New(searchCore, cfg.Log)
+
+	app.Handle(http.MethodGet, "/search", searchHandlers.Query, mid.Authenticate(cfg.Auth), mid.Authorize(cfg.Auth, auth.RuleUserOnly, pat.ScopeIdeasRead))
+
+	//-------Explore-------
+	// Initialize the exploregrp.Handlers instance
+	exploreHandlers := exploregrp.New(cfg.Cores.Explore, cfg.Log)
+
+	// Public ideas can be discovered without signing in.
+	app.Handle(http.MethodGet, "/explore", exploreHandlers.Feed)
+
+	// Initialize the follow.Core and feedgrp.Handlers instances
+	feedHandlers := feedgrp.New(activityCore, follow.NewCore(followdb.NewStore(cfg.Log, cfg.DB)), ideaCore, usrCore, cfg.Log)
+
+	// Add the routes for following ideas and users and the activity feed
+	app.Handle(http.MethodGet, "/feed", feedHandlers.Feed, mid.Authenticate(cfg.Auth), mid.Authorize(cfg.Auth, auth.RuleUserOnly))
+	app.Handle(http.MethodGet, "/follows", feedHandlers.QueryFollowing, mid.Authenticate(cfg.Auth), mid.Authorize(cfg.Auth, auth.RuleUserOnly))
+	app.Handle(http.MethodPost, "/ideas/:idea_id/follow", feedHandlers.FollowIdea, mid.Authenticate(cfg.Auth), mid.Authorize(cfg.Auth, auth.RuleUserOnly))
+	app.Handle(http.MethodDelete, "/ideas/:idea_id/follow", feedHandlers.UnfollowIdea, mid.Authenticate(cfg.Auth), mid.Authorize(cfg.Auth, auth.RuleUserOnly))
+	app.Handle(http.MethodPost, "/users/:user_id/follow", feedHandlers.FollowUser, mid.Authenticate(cfg.Auth), mid.Authorize(cfg.Auth, auth.RuleUserOnly))
+	app.Handle(http.MethodDelete, "/users/:user_id/follow", feedHandlers.UnfollowUser, mid.Authenticate(cfg.Auth), mid.Authorize(cfg.Auth, auth.RuleUserOnly))
+
+	//-------Notifications-------
+	// Initialize the notificationgrp.Handlers instance
+	notificationHandlers := notificationgrp.New(notificationCore, usrCore, cfg.Log)
+
+	app.Handle(http.MethodGet, "/notifications", notificationHandlers.Query, mid.Authenticate(cfg.Auth), mid.Authorize(cfg.Auth, auth.RuleUserOnly))
+	app.Handle(http.MethodGet, "/notifications/unread", notificationHandlers.CountUnread, mid.Authenticate(cfg.Auth), mid.Authorize(cfg.Auth, auth.RuleUserOnly))
+	app.Handle(http.MethodPost, "/notifications/read", notificationHandlers.MarkAllRead, mid.Authenticate(cfg.Auth), mid.Authorize(cfg.Auth, auth.RuleUserOnly))
+	app.Handle(http.MethodPost, "/notifications/:notification_id/read", notificationHandlers.MarkRead, mid.Authenticate(cfg.Auth), mid.Authorize(cfg.Auth, auth.RuleUserOnly))
+	app.Handle(http.MethodGet, "/notifications/preferences", notificationHandlers.QueryPreferences, mid.Authenticate(cfg.Auth), mid.Authorize(cfg.Auth, auth.RuleUserOnly))
+	app.Handle(http.MethodPut, "/notifications/preferences", notificationHandlers.UpdatePreferences, mid.Authenticate(cfg.Auth), mid.Authorize(cfg.Auth, auth.RuleUserOnly))
+
+	//-------Tags-------
+	// Initialize the taggrp.Handlers instance
+	tagHandlers := taggrp.New(tagCore, cfg.Log)
+
+	app.Handle(http.MethodGet, "/tags", tagHandlers.Query, mid.Authenticate(cfg.Auth), mid.Authorize(cfg.Auth, auth.RuleUserOnly, pat.ScopeIdeasRead))
+	app.Handle(http.MethodPut, "/tags/:slug", tagHandlers.Rename, mid.Authenticate(cfg.Auth), mid.Authorize(cfg.Auth, auth.RuleAdminOnly))
+	app.Handle(http.MethodPost, "/tags/:slug/merge", tagHandlers.Merge, mid.Authenticate(cfg.Auth), mid.Authorize(cfg.Auth, auth.RuleAdminOnly))
+
+	//-------Categories-------
+	// Initialize the categorygrp.Handlers instance
+	categoryHandlers := categorygrp.New(categoryCore, cfg.Log)
+
+	// The taxonomy feeds the public browse menu.
+	app.Handle(http.MethodGet, "/categories", categoryHandlers.Query)
+	app.Handle(http.MethodPost, "/categories", categoryHandlers.Create, mid.Authenticate(cfg.Auth), mid.Authorize(cfg.Auth, auth.RuleAdminOnly))
+	app.Handle(http.MethodPut, "/categories/:slug", categoryHandlers.Update, mid.Authenticate(cfg.Auth), mid.Authorize(cfg.Auth, auth.RuleAdminOnly))
+	app.Handle(http.MethodDelete, "/categories/:slug", categoryHandlers.Delete, mid.Authenticate(cfg.Auth), mid.Authorize(cfg.Auth, auth.RuleAdminOnly))
+
+	//-------Trash-------
+	// Initialize the trash.Core and trashgrp.Handlers instances
+	trashCore := trash.NewCore(cfg.Log, sqldb.NewBeginner(cfg.DB), cfg.TrashRetention, ideaCore, postCore, challengeCore)
+	trashHandlers := trashgrp.New(trashCore, ideaCore, postCore, challengeCore, cfg.Log)
+
+	app.Handle(http.MethodGet, "/trash/ideas", trashHandlers.QueryIdeas, mid.Authenticate(cfg.Auth), mid.Authorize(cfg.Auth, auth.RuleUserOnly))
+	app.Handle(http.MethodGet, "/trash/posts", trashHandlers.QueryPosts, mid.Authenticate(cfg.Auth), mid.Authorize(cfg.Auth, auth.RuleUserOnly))
+	app.Handle(http.MethodGet, "/trash/challenges", trashHandlers.QueryChallenges, mid.Authenticate(cfg.Auth), mid.Authorize(cfg.Auth, auth.RuleUserOnly))
+	app.Handle(http.MethodDelete, "/trash/ideas/:idea_id", trashHandlers.PurgeIdea, mid.Authenticate(cfg.Auth), mid.Authorize(cfg.Auth, auth.RuleUserOnly))
+	app.Handle(http.MethodPost, "/trash/ideas/:idea_id/restore", trashHandlers.RestoreIdea, mid.Authenticate(cfg.Auth), mid.Authorize(cfg.Auth, auth.RuleUserOnly))
+	app.Handle(http.MethodPost, "/trash/posts/:post_id/restore", trashHandlers.RestorePost, mid.Authenticate(cfg.Auth), mid.Authorize(cfg.Auth, auth.RuleUserOnly))
+	app.Handle(http.MethodPost, "/trash/challenges/:challenge_id/restore", trashHandlers.RestoreChallenge, mid.Authenticate(cfg.Auth), mid.Authorize(cfg.Auth, auth.RuleUserOnly))
 
 	//----Auth-----
 	// Initialize the authgrp.Handlers instance
@@ -0,0 +1,492 @@
+// Package admingrp maintains the group of handlers administrators use to
+// manage user accounts. Every change is recorded in the audit log, in the
+// same transaction as the change itself.
+package admingrp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/dmanias/startupers/business/core/account"
+	"github.com/dmanias/startupers/business/core/audit"
+	"github.com/dmanias/startupers/business/core/deletion"
+	"github.com/dmanias/startupers/business/core/idea"
+	"github.com/dmanias/startupers/business/core/lockout"
+	"github.com/dmanias/startupers/business/core/session"
+	"github.com/dmanias/startupers/business/core/user"
+	"github.com/dmanias/startupers/business/data/transaction"
+	"github.com/dmanias/startupers/business/sys/uploads"
+	"github.com/dmanias/startupers/business/web/auth"
+	v1 "github.com/dmanias/startupers/business/web/v1"
+	"github.com/dmanias/startupers/business/web/v1/paging"
+	"github.com/dmanias/startupers/foundation/web"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// errOwnAccount is returned when administrators try to lock themselves out.
+var errOwnAccount = errors.New("administrators can't disable, delete or demote their own account")
+
+// Handlers manages the set of admin endpoints.
+type Handlers struct {
+	beginner transaction.Beginner
+	user     *user.Core
+	idea     *idea.Core
+	account  *account.Core
+	session  *session.Core
+	audit    *audit.Core
+	deletion *deletion.Core
+	lockout  *lockout.Core
+	auth     *auth.Auth
+	log      *zap.SugaredLogger
+}
+
+// New constructs a handlers for route access. The auth value is the one the
+// routes authenticate with, so changes to a user apply to their next request.
+func New(beginner transaction.Beginner, user *user.Core, idea *idea.Core, account *account.Core, session *session.Core, audit *audit.Core, deletion *deletion.Core, lockout *lockout.Core, auth *auth.Auth, log *zap.SugaredLogger) *Handlers {
+	return &Handlers{
+		beginner: beginner,
+		user:     user,
+		idea:     idea,
+		account:  account,
+		session:  session,
+		audit:    audit,
+		deletion: deletion,
+		lockout:  lockout,
+		auth:     auth,
+		log:      log,
+	}
+}
+
+// QueryUsers returns a list of users with paging.
+func (h *Handlers) QueryUsers(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	page, err := paging.ParseRequest(r)
+	if err != nil {
+		return err
+	}
+
+	filter, err := parseFilter(r)
+	if err != nil {
+		return err
+	}
+
+	orderBy, err := parseOrder(r)
+	if err != nil {
+		return err
+	}
+
+	users, err := h.user.Query(ctx, filter, orderBy, page.Number, page.RowsPerPage)
+	if err != nil {
+		return fmt.Errorf("query: %w", err)
+	}
+
+	items := make([]AppUser, len(users))
+	for i, usr := range users {
+		items[i] = toAppUser(usr)
+	}
+
+	total, err := h.user.Count(ctx, filter)
+	if err != nil {
+		return fmt.Errorf("count: %w", err)
+	}
+
+	return web.Respond(ctx, w, paging.NewResponse(items, total, page.Number, page.RowsPerPage), http.StatusOK)
+}
+
+// QueryUserByID returns a user with the counts of their ideas.
+func (h *Handlers) QueryUserByID(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	usr, err := h.queryUser(ctx, r)
+	if err != nil {
+		return err
+	}
+
+	var counts AppIdeaCounts
+
+	var filter idea.QueryFilter
+	filter.WithUserID(usr.ID)
+	if counts.Total, err = h.idea.Count(ctx, filter); err != nil {
+		return fmt.Errorf("count ideas: userID[%s]: %w", usr.ID, err)
+	}
+
+	filter.WithPrivacy(idea.PrivacyPublic)
+	if counts.Public, err = h.idea.Count(ctx, filter); err != nil {
+		return fmt.Errorf("count public ideas: userID[%s]: %w", usr.ID, err)
+	}
+
+	var trashed idea.QueryFilter
+	trashed.WithUserID(usr.ID)
+	trashed.WithDeleted(true)
+	if counts.Trashed, err = h.idea.Count(ctx, trashed); err != nil {
+		return fmt.Errorf("count trashed ideas: userID[%s]: %w", usr.ID, err)
+	}
+
+	return web.Respond(ctx, w, AppUserDetails{AppUser: toAppUser(usr), Ideas: counts}, http.StatusOK)
+}
+
+// SetEnabled enables or disables a user. Disabling ends every session the
+// user has open.
+func (h *Handlers) SetEnabled(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	var app AppSetEnabled
+	if err := web.Decode(r, &app); err != nil {
+		return err
+	}
+
+	adminID, usr, err := h.queryTarget(ctx, r)
+	if err != nil {
+		return err
+	}
+
+	enabled := *app.Enabled
+	if usr.Enabled == enabled {
+		return web.Respond(ctx, w, toAppUser(usr), http.StatusOK)
+	}
+
+	if !enabled && usr.ID == adminID {
+		return v1.NewRequestError(errOwnAccount, http.StatusConflict)
+	}
+
+	action := audit.ActionUserEnabled
+	if !enabled {
+		action = audit.ActionUserDisabled
+	}
+
+	f := func(tx transaction.Transaction) error {
+		userCore, err := h.user.ExecuteUnderTransaction(tx)
+		if err != nil {
+			return err
+		}
+
+		updated, err := userCore.Update(ctx, usr, user.UpdateUser{Enabled: &enabled})
+		if err != nil {
+			return fmt.Errorf("update: userID[%s]: %w", usr.ID, err)
+		}
+		usr = updated
+
+		if !enabled {
+			sessionCore, err := h.session.ExecuteUnderTransaction(tx)
+			if err != nil {
+				return err
+			}
+
+			if err := sessionCore.RevokeUser(ctx, usr.ID); err != nil {
+				return fmt.Errorf("revokeuser: userID[%s]: %w", usr.ID, err)
+			}
+		}
+
+		return h.record(ctx, tx, action, adminID, usr.ID, nil)
+	}
+
+	if err := transaction.ExecuteUnderTransaction(ctx, h.log, h.beginner, f); err != nil {
+		return fmt.Errorf("setenabled: %w", err)
+	}
+	h.auth.ForgetUser(usr.ID)
+
+	return web.Respond(ctx, w, toAppUser(usr), http.StatusOK)
+}
+
+// SetRoles replaces the roles of a user.
+func (h *Handlers) SetRoles(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	var app AppSetRoles
+	if err := web.Decode(r, &app); err != nil {
+		return err
+	}
+
+	roles, err := toCoreRoles(app)
+	if err != nil {
+		return v1.NewRequestError(err, http.StatusBadRequest)
+	}
+
+	adminID, usr, err := h.queryTarget(ctx, r)
+	if err != nil {
+		return err
+	}
+
+	if usr.ID == adminID && !hasRole(roles, user.RoleAdmin) {
+		return v1.NewRequestError(errOwnAccount, http.StatusConflict)
+	}
+
+	from := roleNames(usr.Roles)
+
+	f := func(tx transaction.Transaction) error {
+		userCore, err := h.user.ExecuteUnderTransaction(tx)
+		if err != nil {
+			return err
+		}
+
+		updated, err := userCore.Update(ctx, usr, user.UpdateUser{Roles: roles})
+		if err != nil {
+			return fmt.Errorf("update: userID[%s]: %w", usr.ID, err)
+		}
+		usr = updated
+
+		details := map[string]string{
+			"from": from,
+			"to":   roleNames(usr.Roles),
+		}
+		return h.record(ctx, tx, audit.ActionUserRolesChanged, adminID, usr.ID, details)
+	}
+
+	if err := transaction.ExecuteUnderTransaction(ctx, h.log, h.beginner, f); err != nil {
+		return fmt.Errorf("setroles: %w", err)
+	}
+	h.auth.ForgetUser(usr.ID)
+
+	return web.Respond(ctx, w, toAppUser(usr), http.StatusOK)
+}
+
+// ForcePasswordReset replaces the password of a user with one nobody knows,
+// ends their sessions and emails them a link to choose a new one.
+func (h *Handlers) ForcePasswordReset(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	adminID, usr, err := h.queryTarget(ctx, r)
+	if err != nil {
+		return err
+	}
+
+	f := func(tx transaction.Transaction) error {
+		accountCore, err := h.account.ExecuteUnderTransaction(tx)
+		if err != nil {
+			return err
+		}
+
+		if err := accountCore.ForcePasswordReset(ctx, usr); err != nil {
+			return fmt.Errorf("forcepasswordreset: %w", err)
+		}
+
+		return h.record(ctx, tx, audit.ActionUserPasswordReset, adminID, usr.ID, nil)
+	}
+
+	if err := transaction.ExecuteUnderTransaction(ctx, h.log, h.beginner, f); err != nil {
+		return fmt.Errorf("forcepasswordreset: %w", err)
+	}
+
+	return web.Respond(ctx, w, nil, http.StatusNoContent)
+}
+
+// DeleteUser erases a user now, without the grace period users get when
+// they delete their own account. Ideas the user owns are purged and their
+// contributions to the ideas of others are credited to the deleted user.
+func (h *Handlers) DeleteUser(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	adminID, usr, err := h.queryTarget(ctx, r)
+	if err != nil {
+		return err
+	}
+
+	if usr.ID == adminID {
+		return v1.NewRequestError(errOwnAccount, http.StatusConflict)
+	}
+
+	// The user is gone, so the log keeps who they were. The entry is written
+	// in the transaction that removes the account.
+	ne := audit.NewEntry{
+		Action:   audit.ActionUserDeleted,
+		ActorID:  adminID,
+		TargetID: usr.ID,
+		Details: map[string]string{
+			"name":  usr.Name,
+			"email": usr.Email.Address,
+		},
+	}
+
+	files, err := h.deletion.Erase(ctx, usr.ID, ne)
+
+	// Files are only removed once the content using them is gone for good.
+	for _, file := range files {
+		if err := uploads.Remove(file); err != nil {
+			h.log.Errorw("deleteuser", "status", "removing file", "file", file, "ERROR", err)
+		}
+	}
+
+	if err != nil {
+		if errors.Is(err, deletion.ErrDeletedUser) {
+			return v1.NewRequestError(deletion.ErrDeletedUser, http.StatusConflict)
+		}
+		return fmt.Errorf("erase: userID[%s]: %w", usr.ID, err)
+	}
+	h.auth.ForgetUser(usr.ID)
+
+	return web.Respond(ctx, w, nil, http.StatusNoContent)
+}
+
+// QueryAudit returns the audit log with paging, newest first.
+func (h *Handlers) QueryAudit(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	page, err := paging.ParseRequest(r)
+	if err != nil {
+		return err
+	}
+
+	filter, err := parseAuditFilter(r)
+	if err != nil {
+		return err
+	}
+
+	entries, err := h.audit.Query(ctx, filter, page.Number, page.RowsPerPage)
+	if err != nil {
+		return fmt.Errorf("query: %w", err)
+	}
+
+	items := make([]AppAuditEntry, len(entries))
+	for i, entry := range entries {
+		items[i] = toAppAuditEntry(entry)
+	}
+
+	total, err := h.audit.Count(ctx, filter)
+	if err != nil {
+		return fmt.Errorf("count: %w", err)
+	}
+
+	return web.Respond(ctx, w, paging.NewResponse(items, total, page.Number, page.RowsPerPage), http.StatusOK)
+}
+
+// QueryLockouts returns the email and IP addresses locked out now after
+// repeated failed sign in attempts, with paging.
+func (h *Handlers) QueryLockouts(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	page, err := paging.ParseRequest(r)
+	if err != nil {
+		return err
+	}
+
+	filter, err := parseLockoutFilter(r)
+	if err != nil {
+		return err
+	}
+
+	atts, err := h.lockout.QueryLocked(ctx, filter, page.Number, page.RowsPerPage)
+	if err != nil {
+		return fmt.Errorf("querylocked: %w", err)
+	}
+
+	items := make([]AppLockout, len(atts))
+	for i, att := range atts {
+		items[i] = toAppLockout(att)
+	}
+
+	total, err := h.lockout.CountLocked(ctx, filter)
+	if err != nil {
+		return fmt.Errorf("countlocked: %w", err)
+	}
+
+	return web.Respond(ctx, w, paging.NewResponse(items, total, page.Number, page.RowsPerPage), http.StatusOK)
+}
+
+// Unlock lifts a lockout before it runs out, and forgets the failed attempts
+// behind it.
+func (h *Handlers) Unlock(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	adminID, err := auth.GetUserID(ctx)
+	if err != nil {
+		return auth.NewAuthError("unlock: %s", err)
+	}
+
+	lockoutID, err := uuid.Parse(web.Param(r, "lockout_id"))
+	if err != nil {
+		return v1.NewRequestError(err, http.StatusBadRequest)
+	}
+
+	att, err := h.lockout.QueryByID(ctx, lockoutID)
+	if err != nil {
+		if errors.Is(err, lockout.ErrNotFound) {
+			return v1.NewRequestError(lockout.ErrNotFound, http.StatusNotFound)
+		}
+		return fmt.Errorf("querybyid: lockoutID[%s]: %w", lockoutID, err)
+	}
+
+	f := func(tx transaction.Transaction) error {
+		lockoutCore, err := h.lockout.ExecuteUnderTransaction(tx)
+		if err != nil {
+			return err
+		}
+
+		if err := lockoutCore.Unlock(ctx, att); err != nil {
+			return fmt.Errorf("unlock: lockoutID[%s]: %w", lockoutID, err)
+		}
+
+		details := map[string]string{
+			"kind":    att.Kind.Name(),
+			"subject": att.Subject,
+		}
+		return h.record(ctx, tx, audit.ActionLockoutCleared, adminID, att.ID, details)
+	}
+
+	if err := transaction.ExecuteUnderTransaction(ctx, h.log, h.beginner, f); err != nil {
+		return fmt.Errorf("unlock: %w", err)
+	}
+
+	return web.Respond(ctx, w, nil, http.StatusNoContent)
+}
+
+// =============================================================================
+
+// queryUser returns the user named in the route.
+func (h *Handlers) queryUser(ctx context.Context, r *http.Request) (user.User, error) {
+	userID, err := uuid.Parse(web.Param(r, "user_id"))
+	if err != nil {
+		return user.User{}, v1.NewRequestError(err, http.StatusBadRequest)
+	}
+
+	usr, err := h.user.QueryByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, user.ErrNotFound) {
+			return user.User{}, v1.NewRequestError(user.ErrNotFound, http.StatusNotFound)
+		}
+		return user.User{}, fmt.Errorf("querybyid: userID[%s]: %w", userID, err)
+	}
+
+	return usr, nil
+}
+
+// queryTarget returns the ID of the acting administrator and the user named
+// in the route.
+func (h *Handlers) queryTarget(ctx context.Context, r *http.Request) (uuid.UUID, user.User, error) {
+	adminID, err := auth.GetUserID(ctx)
+	if err != nil {
+		return uuid.UUID{}, user.User{}, auth.NewAuthError("admin: %s", err)
+	}
+
+	usr, err := h.queryUser(ctx, r)
+	if err != nil {
+		return uuid.UUID{}, user.User{}, err
+	}
+
+	return adminID, usr, nil
+}
+
+// record adds what the administrator did to the audit log, in the
+// transaction that makes the change.
+func (h *Handlers) record(ctx context.Context, tx transaction.Transaction, action audit.Action, adminID uuid.UUID, userID uuid.UUID, details map[string]string) error {
+	ne := audit.NewEntry{
+		Action:   action,
+		ActorID:  adminID,
+		TargetID: userID,
+		Details:  details,
+	}
+
+	auditCore, err := h.audit.ExecuteUnderTransaction(tx)
+	if err != nil {
+		return err
+	}
+
+	if _, err := auditCore.Record(ctx, ne); err != nil {
+		return fmt.Errorf("record: %w", err)
+	}
+
+	return nil
+}
+
+func hasRole(roles []user.Role, role user.Role) bool {
+	for _, r := range roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+func roleNames(roles []user.Role) string {
+	names := make([]string, len(roles))
+	for i, role := range roles {
+		names[i] = role.Name()
+	}
+	return strings.Join(names, ",")
+}
@@ -0,0 +1,134 @@
+package admingrp
+
+import (
+	"github.com/dmanias/startupers/business/core/lockout"
+	"net/http"
+	"net/mail"
+	"strconv"
+	"time"
+
+	"github.com/dmanias/startupers/business/core/audit"
+	"github.com/dmanias/startupers/business/core/user"
+	"github.com/dmanias/startupers/business/sys/validate"
+	"github.com/google/uuid"
+)
+
+func parseFilter(r *http.Request) (user.QueryFilter, error) {
+	values := r.URL.Query()
+
+	var filter user.QueryFilter
+
+	if userID := values.Get("id"); userID != "" {
+		id, err := uuid.Parse(userID)
+		if err != nil {
+			return user.QueryFilter{}, validate.NewFieldsError("id", err)
+		}
+		filter.WithUserID(id)
+	}
+
+	if email := values.Get("email"); email != "" {
+		addr, err := mail.ParseAddress(email)
+		if err != nil {
+			return user.QueryFilter{}, validate.NewFieldsError("email", err)
+		}
+		filter.WithEmail(*addr)
+	}
+
+	if role := values.Get("role"); role != "" {
+		r, err := user.ParseRole(role)
+		if err != nil {
+			return user.QueryFilter{}, validate.NewFieldsError("role", err)
+		}
+		filter.WithRole(r)
+	}
+
+	if enabled := values.Get("enabled"); enabled != "" {
+		e, err := strconv.ParseBool(enabled)
+		if err != nil {
+			return user.QueryFilter{}, validate.NewFieldsError("enabled", err)
+		}
+		filter.WithEnabled(e)
+	}
+
+	if createdDate := values.Get("start_created_date"); createdDate != "" {
+		t, err := time.Parse(time.RFC3339, createdDate)
+		if err != nil {
+			return user.QueryFilter{}, validate.NewFieldsError("start_created_date", err)
+		}
+		filter.WithStartDateCreated(t)
+	}
+
+	if createdDate := values.Get("end_created_date"); createdDate != "" {
+		t, err := time.Parse(time.RFC3339, createdDate)
+		if err != nil {
+			return user.QueryFilter{}, validate.NewFieldsError("end_created_date", err)
+		}
+		filter.WithEndCreatedDate(t)
+	}
+
+	if name := values.Get("name"); name != "" {
+		filter.WithName(name)
+	}
+
+	if err := filter.Validate(); err != nil {
+		return user.QueryFilter{}, err
+	}
+
+	return filter, nil
+}
+
+func parseAuditFilter(r *http.Request) (audit.QueryFilter, error) {
+	values := r.URL.Query()
+
+	var filter audit.QueryFilter
+
+	if actorID := values.Get("actor_id"); actorID != "" {
+		id, err := uuid.Parse(actorID)
+		if err != nil {
+			return audit.QueryFilter{}, validate.NewFieldsError("actor_id", err)
+		}
+		filter.WithActorID(id)
+	}
+
+	if targetID := values.Get("target_id"); targetID != "" {
+		id, err := uuid.Parse(targetID)
+		if err != nil {
+			return audit.QueryFilter{}, validate.NewFieldsError("target_id", err)
+		}
+		filter.WithTargetID(id)
+	}
+
+	if action := values.Get("action"); action != "" {
+		a, err := audit.ParseAction(action)
+		if err != nil {
+			return audit.QueryFilter{}, validate.NewFieldsError("action", err)
+		}
+		filter.WithAction(a)
+	}
+
+	if err := filter.Validate(); err != nil {
+		return audit.QueryFilter{}, err
+	}
+
+	return filter, nil
+}
+
+func parseLockoutFilter(r *http.Request) (lockout.QueryFilter, error) {
+	values := r.URL.Query()
+
+	var filter lockout.QueryFilter
+
+	if kind := values.Get("kind"); kind != "" {
+		k, err := lockout.ParseKind(kind)
+		if err != nil {
+			return lockout.QueryFilter{}, validate.NewFieldsError("kind", err)
+		}
+		filter.WithKind(k)
+	}
+
+	if err := filter.Validate(); err != nil {
+		return lockout.QueryFilter{}, err
+	}
+
+	return filter, nil
+}
@@ -0,0 +1,147 @@
+package admingrp
+
+import (
+	"fmt"
+	"github.com/dmanias/startupers/business/core/lockout"
+	"time"
+
+	"github.com/dmanias/startupers/business/core/audit"
+	"github.com/dmanias/startupers/business/core/user"
+	"github.com/dmanias/startupers/business/sys/validate"
+)
+
+// AppUser represents information about an individual user as administrators
+// see it.
+type AppUser struct {
+	ID          string   `json:"id"`
+	Name        string   `json:"name"`
+	Email       string   `json:"email"`
+	Roles       []string `json:"roles"`
+	Enabled     bool     `json:"enabled"`
+	Verified    bool     `json:"verified"`
+	DateCreated string   `json:"dateCreated"`
+	DateUpdated string   `json:"dateUpdated"`
+}
+
+func toAppUser(usr user.User) AppUser {
+	roles := make([]string, len(usr.Roles))
+	for i, role := range usr.Roles {
+		roles[i] = role.Name()
+	}
+
+	return AppUser{
+		ID:          usr.ID.String(),
+		Name:        usr.Name,
+		Email:       usr.Email.Address,
+		Roles:       roles,
+		Enabled:     usr.Enabled,
+		Verified:    usr.Verified(),
+		DateCreated: usr.DateCreated.Format(time.RFC3339),
+		DateUpdated: usr.DateUpdated.Format(time.RFC3339),
+	}
+}
+
+// AppIdeaCounts represents how many ideas a user owns. Ideas in the trash are
+// not part of the total.
+type AppIdeaCounts struct {
+	Total   int `json:"total"`
+	Public  int `json:"public"`
+	Trashed int `json:"trashed"`
+}
+
+// AppUserDetails represents a user with the counts of their ideas.
+type AppUserDetails struct {
+	AppUser
+	Ideas AppIdeaCounts `json:"ideas"`
+}
+
+// =============================================================================
+
+// AppSetEnabled contains whether a user is allowed to sign in.
+type AppSetEnabled struct {
+	Enabled *bool `json:"enabled" validate:"required"`
+}
+
+// Validate checks the data in the model is considered clean.
+func (app AppSetEnabled) Validate() error {
+	if err := validate.Check(app); err != nil {
+		return err
+	}
+	return nil
+}
+
+// AppSetRoles contains the roles a user is given, replacing the ones they
+// have.
+type AppSetRoles struct {
+	Roles []string `json:"roles" validate:"required,min=1"`
+}
+
+// Validate checks the data in the model is considered clean.
+func (app AppSetRoles) Validate() error {
+	if err := validate.Check(app); err != nil {
+		return err
+	}
+	return nil
+}
+
+func toCoreRoles(app AppSetRoles) ([]user.Role, error) {
+	roles := make([]user.Role, 0, len(app.Roles))
+	seen := make(map[user.Role]bool)
+	for _, roleStr := range app.Roles {
+		role, err := user.ParseRole(roleStr)
+		if err != nil {
+			return nil, validate.NewFieldsError("roles", fmt.Errorf("parsing role: %w", err))
+		}
+		if !seen[role] {
+			seen[role] = true
+			roles = append(roles, role)
+		}
+	}
+
+	return roles, nil
+}
+
+// =============================================================================
+
+// AppAuditEntry represents an action an administrator took on a user.
+type AppAuditEntry struct {
+	ID          string            `json:"id"`
+	Action      string            `json:"action"`
+	ActorID     string            `json:"actorID"`
+	TargetID    string            `json:"targetID"`
+	Details     map[string]string `json:"details"`
+	DateCreated string            `json:"dateCreated"`
+}
+
+func toAppAuditEntry(entry audit.Entry) AppAuditEntry {
+	return AppAuditEntry{
+		ID:          entry.ID.String(),
+		Action:      entry.Action.Name(),
+		ActorID:     entry.ActorID.String(),
+		TargetID:    entry.TargetID.String(),
+		Details:     entry.Details,
+		DateCreated: entry.DateCreated.Format(time.RFC3339),
+	}
+}
+
+// AppLockout represents an email or IP address locked out after repeated
+// failed sign in attempts.
+type AppLockout struct {
+	ID              string `json:"id"`
+	Kind            string `json:"kind"`
+	Subject         string `json:"subject"`
+	Failures        int    `json:"failures"`
+	DateLastFailure string `json:"dateLastFailure"`
+	DateLockedUntil string `json:"dateLockedUntil"`
+}
+
+func toAppLockout(att lockout.Attempts) AppLockout {
+	return AppLockout{
+		ID:              att.ID.String(),
+		Kind:            att.Kind.Name(),
+		Subject:         att.Subject,
+		Failures:        att.Failures,
+		DateLastFailure: att.DateLastFailure.Format(time.RFC3339),
+		DateLockedUntil: att.DateLockedUntil.Format(time.RFC3339),
+	}
+}
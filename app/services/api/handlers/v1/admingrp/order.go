@@ -1,4 +1,4 @@
-package usergrp
+package admingrp
 
 import (
 	"errors"
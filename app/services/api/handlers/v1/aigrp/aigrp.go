@@ -20,6 +20,7 @@ import (
 
 	v1 "github.com/dmanias/startupers/business/web/v1"
 	"github.com/dmanias/startupers/business/web/v1/paging"
+	"github.com/dmanias/startupers/business/web/v1/visible"
 	"github.com/dmanias/startupers/foundation/web"
 )
 
@@ -92,6 +93,12 @@ func (h *Handlers) Ask(ctx context.Context, w http.ResponseWriter, r *http.Reque
 			return v1.NewRequestError(err, http.StatusBadRequest)
 		}
 
+		// Query the idea by ID, hiding ideas the user isn't allowed to see
+		idr, err := visible.Idea(ctx, h.ideaCore, ideaUUID)
+		if err != nil {
+			return err
+		}
+
 		// Create a filter to query posts that belong to the specific idea
 		filter := post.QueryFilter{
 			IdeaID: &ideaUUID,
@@ -108,15 +115,9 @@ func (h *Handlers) Ask(ctx context.Context, w http.ResponseWriter, r *http.Reque
 			return fmt.Errorf("query posts: %w", err)
 		}
 
-		// Query the idea by ID
-		idea, err := h.ideaCore.QueryByID(ctx, ideaUUID)
-		if err != nil {
-			return fmt.Errorf("query idea by ID: %w", err)
-		}
-
 		// Construct the question with the idea details and posts
 		question = fmt.Sprintf("%s\nStep's description: %s\nIdea title: %s\nIdea description: %s\nIdea tags: %v\nPosts:\n%s",
-			instruction, description, idea.Title, idea.Description, idea.Tags, formatPosts(posts))
+			instruction, description, idr.Title, idr.Description, idr.Tags, formatPosts(posts))
 
 		// Call the GPT function to get the AI response
 		aiResponse, err = gpt(h.cfg.APIKey, question)
@@ -0,0 +1,125 @@
+// Package categorygrp maintains the group of handlers for the category
+// taxonomy.
+package categorygrp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/dmanias/startupers/business/core/category"
+	v1 "github.com/dmanias/startupers/business/web/v1"
+	"github.com/dmanias/startupers/foundation/web"
+	"go.uber.org/zap"
+)
+
+// Handlers manages the set of category endpoints.
+type Handlers struct {
+	category *category.Core
+	log      *zap.SugaredLogger
+}
+
+// New constructs a handlers for route access.
+func New(category *category.Core, log *zap.SugaredLogger) *Handlers {
+	return &Handlers{
+		category: category,
+		log:      log,
+	}
+}
+
+// Query returns the whole taxonomy as a tree with the number of public ideas
+// per category. Labels are picked in the locale asked for, falling back to
+// the default locale.
+func (h *Handlers) Query(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	locale := r.URL.Query().Get("locale")
+	if locale == "" {
+		locale = category.DefaultLocale
+	}
+
+	nodes, err := h.category.QueryTree(ctx)
+	if err != nil {
+		return fmt.Errorf("query: %w", err)
+	}
+
+	return web.Respond(ctx, w, toAppCategoryTree(nodes, locale), http.StatusOK)
+}
+
+// Create adds a category to the taxonomy.
+func (h *Handlers) Create(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	var app AppNewCategory
+	if err := web.Decode(r, &app); err != nil {
+		return err
+	}
+
+	cat, err := h.category.Create(ctx, toCoreNewCategory(app))
+	if err != nil {
+		return toRequestError(err)
+	}
+
+	return web.Respond(ctx, w, toAppCategory(cat, category.DefaultLocale), http.StatusCreated)
+}
+
+// Update changes the parent, display order or labels of a category.
+func (h *Handlers) Update(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	var app AppUpdateCategory
+	if err := web.Decode(r, &app); err != nil {
+		return err
+	}
+
+	cat, err := h.queryCategory(ctx, web.Param(r, "slug"))
+	if err != nil {
+		return err
+	}
+
+	cat, err = h.category.Update(ctx, cat, toCoreUpdateCategory(app))
+	if err != nil {
+		return toRequestError(err)
+	}
+
+	return web.Respond(ctx, w, toAppCategory(cat, category.DefaultLocale), http.StatusOK)
+}
+
+// Delete removes a category without subcategories or ideas from the taxonomy.
+func (h *Handlers) Delete(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	cat, err := h.queryCategory(ctx, web.Param(r, "slug"))
+	if err != nil {
+		return err
+	}
+
+	if err := h.category.Delete(ctx, cat); err != nil {
+		return toRequestError(err)
+	}
+
+	return web.Respond(ctx, w, nil, http.StatusNoContent)
+}
+
+// queryCategory retrieves the category named in the path.
+func (h *Handlers) queryCategory(ctx context.Context, slug string) (category.Category, error) {
+	cat, err := h.category.QueryBySlug(ctx, slug)
+	if err != nil {
+		if errors.Is(err, category.ErrNotFound) {
+			return category.Category{}, v1.NewRequestError(category.ErrNotFound, http.StatusNotFound)
+		}
+		return category.Category{}, fmt.Errorf("query: slug[%s]: %w", slug, err)
+	}
+
+	return cat, nil
+}
+
+// toRequestError maps the category errors to the matching HTTP status.
+func toRequestError(err error) error {
+	switch {
+	case errors.Is(err, category.ErrExists):
+		return v1.NewRequestError(category.ErrExists, http.StatusConflict)
+	case errors.Is(err, category.ErrInUse):
+		return v1.NewRequestError(category.ErrInUse, http.StatusConflict)
+	case errors.Is(err, category.ErrInvalidSlug):
+		return v1.NewRequestError(category.ErrInvalidSlug, http.StatusBadRequest)
+	case errors.Is(err, category.ErrParentNotFound):
+		return v1.NewRequestError(category.ErrParentNotFound, http.StatusBadRequest)
+	case errors.Is(err, category.ErrCycle):
+		return v1.NewRequestError(category.ErrCycle, http.StatusBadRequest)
+	}
+	return err
+}
@@ -0,0 +1,105 @@
+package categorygrp
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/dmanias/startupers/business/core/category"
+	"github.com/dmanias/startupers/business/sys/validate"
+)
+
+// AppCategory represents a category in the browse menu, with its
+// subcategories.
+type AppCategory struct {
+	Slug           string            `json:"slug"`
+	ParentSlug     string            `json:"parentSlug,omitempty"`
+	Label          string            `json:"label"`
+	Labels         map[string]string `json:"labels"`
+	DisplayOrder   int               `json:"displayOrder"`
+	IdeaCount      int               `json:"ideaCount"`
+	TotalIdeaCount int               `json:"totalIdeaCount"`
+	Children       []AppCategory     `json:"children"`
+	DateCreated    string            `json:"dateCreated"`
+	DateUpdated    string            `json:"dateUpdated"`
+}
+
+func toAppCategory(cat category.Category, locale string) AppCategory {
+	labels := cat.Labels
+	if labels == nil {
+		labels = map[string]string{}
+	}
+
+	return AppCategory{
+		Slug:         cat.Slug,
+		ParentSlug:   cat.ParentSlug,
+		Label:        cat.Label(locale),
+		Labels:       labels,
+		DisplayOrder: cat.DisplayOrder,
+		Children:     []AppCategory{},
+		DateCreated:  cat.DateCreated.Format(time.RFC3339),
+		DateUpdated:  cat.DateUpdated.Format(time.RFC3339),
+	}
+}
+
+func toAppCategoryTree(nodes []category.Node, locale string) []AppCategory {
+	items := make([]AppCategory, len(nodes))
+	for i, node := range nodes {
+		app := toAppCategory(node.Category, locale)
+		app.IdeaCount = node.IdeaCount
+		app.TotalIdeaCount = node.TotalIdeaCount
+		app.Children = toAppCategoryTree(node.Children, locale)
+		items[i] = app
+	}
+
+	return items
+}
+
+// =============================================================================
+
+// AppNewCategory contains information needed to create a new category.
+type AppNewCategory struct {
+	Slug         string            `json:"slug" validate:"required,max=100"`
+	ParentSlug   string            `json:"parentSlug"`
+	DisplayOrder int               `json:"displayOrder"`
+	Labels       map[string]string `json:"labels" validate:"required,min=1,dive,keys,min=2,max=35,endkeys,required,max=100"`
+}
+
+func toCoreNewCategory(app AppNewCategory) category.NewCategory {
+	return category.NewCategory{
+		Slug:         app.Slug,
+		ParentSlug:   app.ParentSlug,
+		DisplayOrder: app.DisplayOrder,
+		Labels:       app.Labels,
+	}
+}
+
+// Validate checks the data in the model is considered clean.
+func (app AppNewCategory) Validate() error {
+	if err := validate.Check(app); err != nil {
+		return fmt.Errorf("validate: %w", err)
+	}
+	return nil
+}
+
+// AppUpdateCategory contains information needed to update a category.
+type AppUpdateCategory struct {
+	ParentSlug   *string           `json:"parentSlug"`
+	DisplayOrder *int              `json:"displayOrder"`
+	Labels       map[string]string `json:"labels" validate:"omitempty,min=1,dive,keys,min=2,max=35,endkeys,required,max=100"`
+}
+
+func toCoreUpdateCategory(app AppUpdateCategory) category.UpdateCategory {
+	return category.UpdateCategory{
+		ParentSlug:   app.ParentSlug,
+		DisplayOrder: app.DisplayOrder,
+		Labels:       app.Labels,
+	}
+}
+
+// Validate checks the data in the model is considered clean.
+func (app AppUpdateCategory) Validate() error {
+	if err := validate.Check(app); err != nil {
+		return fmt.Errorf("validate: %w", err)
+	}
+	return nil
+}
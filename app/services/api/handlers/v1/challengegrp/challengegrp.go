@@ -2,12 +2,17 @@ package challengegrp
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/http"
 
 	"github.com/dmanias/startupers/business/core/challenge"
+	"github.com/dmanias/startupers/business/core/idea"
+	"github.com/dmanias/startupers/business/sys/uploads"
+	"github.com/dmanias/startupers/business/web/auth"
 	v1 "github.com/dmanias/startupers/business/web/v1"
 	"github.com/dmanias/startupers/business/web/v1/paging"
+	"github.com/dmanias/startupers/business/web/v1/visible"
 	"github.com/dmanias/startupers/foundation/web"
 	"github.com/google/uuid"
 	"go.uber.org/zap"
@@ -16,13 +21,19 @@ import (
 // Handlers manages the set of challenge endpoints.
 type Handlers struct {
 	challenge *challenge.Core
+	idea      *idea.Core
 	log       *zap.SugaredLogger
 }
 
+// ErrCannotDelete is returned when the user may see a challenge but not move
+// it to the trash.
+var ErrCannotDelete = errors.New("only the owner of the idea or a collaborator can delete the challenge")
+
 // New constructs a handlers for route access.
-func New(challenge *challenge.Core, log *zap.SugaredLogger) *Handlers {
+func New(challenge *challenge.Core, idea *idea.Core, log *zap.SugaredLogger) *Handlers {
 	return &Handlers{
 		challenge: challenge,
+		idea:      idea,
 		log:       log,
 	}
 }
@@ -38,6 +49,14 @@ func (h *Handlers) Create(ctx context.Context, w http.ResponseWriter, r *http.Re
 		return v1.NewRequestError(err, http.StatusBadRequest)
 	}
 
+	if uploads.Is(nc.PhotoURL) {
+		return v1.NewRequestError(uploads.ErrServerPath, http.StatusBadRequest)
+	}
+
+	if _, err := visible.Idea(ctx, h.idea, nc.IdeaID); err != nil {
+		return err
+	}
+
 	newChallenge, err := h.challenge.Create(ctx, nc)
 	if err != nil {
 		return fmt.Errorf("create: challenge[%+v]: %w", newChallenge, err)
@@ -57,12 +76,21 @@ func (h *Handlers) Update(ctx context.Context, w http.ResponseWriter, r *http.Re
 		return v1.NewRequestError(err, http.StatusBadRequest)
 	}
 
-	challenge, err := h.challenge.QueryByID(ctx, *uc.ID)
+	challenge, err := h.queryVisibleChallenge(ctx, *uc.ID)
 	if err != nil {
-		return fmt.Errorf("query: challengeID[%s]: %w", uc.ID, err)
+		return err
 	}
 
-	updatedChallenge, err := h.challenge.Update(ctx, challenge, uc)
+	if uc.PhotoURL != nil && *uc.PhotoURL != challenge.PhotoURL && uploads.Is(*uc.PhotoURL) {
+		return v1.NewRequestError(uploads.ErrServerPath, http.StatusBadRequest)
+	}
+
+	userID, err := auth.GetUserID(ctx)
+	if err != nil {
+		return auth.NewAuthError("update: %s", err)
+	}
+
+	updatedChallenge, err := h.challenge.Update(ctx, challenge, uc, userID)
 	if err != nil {
 		return fmt.Errorf("update: challenge[%+v]: %w", challenge, err)
 	}
@@ -76,12 +104,26 @@ func (h *Handlers) Delete(ctx context.Context, w http.ResponseWriter, r *http.Re
 		return v1.NewRequestError(err, http.StatusBadRequest)
 	}
 
-	challenge, err := h.challenge.QueryByID(ctx, challengeID)
+	challenge, err := h.queryVisibleChallenge(ctx, challengeID)
 	if err != nil {
-		return fmt.Errorf("query: challengeID[%s]: %w", challengeID, err)
+		return err
 	}
 
-	if err := h.challenge.Delete(ctx, challenge); err != nil {
+	userID, err := auth.GetUserID(ctx)
+	if err != nil {
+		return auth.NewAuthError("delete: %s", err)
+	}
+
+	idr, err := visible.Idea(ctx, h.idea, challenge.IdeaID)
+	if err != nil {
+		return err
+	}
+
+	if !idr.IsMember(userID) {
+		return v1.NewRequestError(ErrCannotDelete, http.StatusForbidden)
+	}
+
+	if _, err := h.challenge.Delete(ctx, challenge, userID); err != nil {
 		return fmt.Errorf("delete: challenge[%+v]: %w", challenge, err)
 	}
 
@@ -96,9 +138,9 @@ func (h *Handlers) QueryByID(ctx context.Context, w http.ResponseWriter, r *http
 		return v1.NewRequestError(fmt.Errorf("invalid challenge ID: %w", err), http.StatusBadRequest)
 	}
 
-	challenge, err := h.challenge.QueryByID(ctx, id)
+	challenge, err := h.queryVisibleChallenge(ctx, id)
 	if err != nil {
-		return fmt.Errorf("query challenge by ID: %w", err)
+		return err
 	}
 
 	appChallenge := toAppChallenge(challenge)
@@ -118,6 +160,17 @@ func (h *Handlers) Query(ctx context.Context, w http.ResponseWriter, r *http.Req
 		return err
 	}
 
+	// The idea whose challenges are listed comes from the route.
+	ideaID, err := uuid.Parse(web.Param(r, "idea_id"))
+	if err != nil {
+		return v1.NewRequestError(err, http.StatusBadRequest)
+	}
+
+	if _, err := visible.Idea(ctx, h.idea, ideaID); err != nil {
+		return err
+	}
+	filter.WithIdeaID(ideaID)
+
 	orderBy, err := parseOrder(r)
 	if err != nil {
 		return err
@@ -140,3 +193,25 @@ func (h *Handlers) Query(ctx context.Context, w http.ResponseWriter, r *http.Req
 
 	return web.Respond(ctx, w, paging.NewResponse(items, total, page.Number, page.RowsPerPage), http.StatusOK)
 }
+
+// queryVisibleChallenge retrieves the challenge if the authenticated user is
+// allowed to see the idea it belongs to. Challenges on hidden ideas are
+// reported as not found.
+func (h *Handlers) queryVisibleChallenge(ctx context.Context, challengeID uuid.UUID) (challenge.Challenge, error) {
+	chl, err := h.challenge.QueryByID(ctx, challengeID)
+	if err != nil {
+		if errors.Is(err, challenge.ErrNotFound) {
+			return challenge.Challenge{}, v1.NewRequestError(challenge.ErrNotFound, http.StatusNotFound)
+		}
+		return challenge.Challenge{}, fmt.Errorf("query: challengeID[%s]: %w", challengeID, err)
+	}
+
+	if _, err := visible.Idea(ctx, h.idea, chl.IdeaID); err != nil {
+		if v1.IsRequestError(err) {
+			return challenge.Challenge{}, v1.NewRequestError(challenge.ErrNotFound, http.StatusNotFound)
+		}
+		return challenge.Challenge{}, err
+	}
+
+	return chl, nil
+}
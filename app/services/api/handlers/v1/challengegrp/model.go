@@ -15,17 +15,24 @@ type AppChallenge struct {
 	ModeratorID string `json:"moderatorID"`
 	Answer      string `json:"answer"`
 	PhotoURL    string `json:"photoURL"`
+	ForkedFrom  string `json:"forkedFrom,omitempty"`
 	DateCreated string `json:"dateCreated"`
 	DateUpdated string `json:"dateUpdated"`
 }
 
 func toAppChallenge(challenge challenge.Challenge) AppChallenge {
+	var forkedFrom string
+	if challenge.ForkedFrom != uuid.Nil {
+		forkedFrom = challenge.ForkedFrom.String()
+	}
+
 	return AppChallenge{
 		ID:          challenge.ID.String(),
 		IdeaID:      challenge.IdeaID.String(),
 		ModeratorID: challenge.ModeratorID.String(),
 		Answer:      challenge.Answer,
 		PhotoURL:    challenge.PhotoURL,
+		ForkedFrom:  forkedFrom,
 		DateCreated: challenge.DateCreated.Format(time.RFC3339),
 		DateUpdated: challenge.DateUpdated.Format(time.RFC3339),
 	}
@@ -0,0 +1,375 @@
+// Package commentgrp maintains the group of handlers for threaded comments on
+// posts and challenges.
+package commentgrp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/dmanias/startupers/business/core/challenge"
+	"github.com/dmanias/startupers/business/core/comment"
+	"github.com/dmanias/startupers/business/core/idea"
+	"github.com/dmanias/startupers/business/core/post"
+	"github.com/dmanias/startupers/business/core/user"
+	"github.com/dmanias/startupers/business/sys/validate"
+	"github.com/dmanias/startupers/business/web/auth"
+	v1 "github.com/dmanias/startupers/business/web/v1"
+	"github.com/dmanias/startupers/business/web/v1/paging"
+	"github.com/dmanias/startupers/business/web/v1/visible"
+	"github.com/dmanias/startupers/foundation/web"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// Set of views a list of comments can be returned in.
+const (
+	viewTree = "tree"
+	viewFlat = "flat"
+)
+
+// ErrNotAuthor is returned when someone other than the author tries to change
+// a comment.
+var ErrNotAuthor = errors.New("only the author of the comment can do this")
+
+// Handlers manages the set of comment endpoints.
+type Handlers struct {
+	comment   *comment.Core
+	post      *post.Core
+	challenge *challenge.Core
+	idea      *idea.Core
+	log       *zap.SugaredLogger
+}
+
+// New constructs a handlers for route access.
+func New(comment *comment.Core, post *post.Core, challenge *challenge.Core, idea *idea.Core, log *zap.SugaredLogger) *Handlers {
+	return &Handlers{
+		comment:   comment,
+		post:      post,
+		challenge: challenge,
+		idea:      idea,
+		log:       log,
+	}
+}
+
+// owner identifies the post or challenge a list of comments hangs off.
+type owner struct {
+	typ    comment.OwnerType
+	id     uuid.UUID
+	ideaID uuid.UUID
+}
+
+// CreateOnPost leaves a comment on a post, or replies to one of its comments.
+func (h *Handlers) CreateOnPost(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	own, err := h.queryPostOwner(ctx, r)
+	if err != nil {
+		return err
+	}
+
+	return h.create(ctx, w, r, own)
+}
+
+// CreateOnChallenge leaves a comment on a challenge, or replies to one of its
+// comments.
+func (h *Handlers) CreateOnChallenge(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	own, err := h.queryChallengeOwner(ctx, r)
+	if err != nil {
+		return err
+	}
+
+	return h.create(ctx, w, r, own)
+}
+
+// QueryOnPost returns the comments on a post with paging.
+func (h *Handlers) QueryOnPost(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	own, err := h.queryPostOwner(ctx, r)
+	if err != nil {
+		return err
+	}
+
+	return h.query(ctx, w, r, own)
+}
+
+// QueryOnChallenge returns the comments on a challenge with paging.
+func (h *Handlers) QueryOnChallenge(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	own, err := h.queryChallengeOwner(ctx, r)
+	if err != nil {
+		return err
+	}
+
+	return h.query(ctx, w, r, own)
+}
+
+// Update changes the content of a comment. Only the author can do this.
+func (h *Handlers) Update(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	var app AppUpdateComment
+	if err := web.Decode(r, &app); err != nil {
+		return err
+	}
+
+	cmt, err := h.queryAuthoredComment(ctx, r)
+	if err != nil {
+		return err
+	}
+
+	cmt, err = h.comment.Update(ctx, cmt, toCoreUpdateComment(app))
+	if err != nil {
+		if errors.Is(err, comment.ErrDeleted) {
+			return v1.NewRequestError(comment.ErrDeleted, http.StatusConflict)
+		}
+		return fmt.Errorf("update: commentID[%s]: %w", cmt.ID, err)
+	}
+
+	return web.Respond(ctx, w, toAppComment(cmt, h.viewer(ctx)), http.StatusOK)
+}
+
+// Delete removes the content of a comment. Only the author can do this; the
+// comment stays in its thread.
+func (h *Handlers) Delete(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	cmt, err := h.queryAuthoredComment(ctx, r)
+	if err != nil {
+		return err
+	}
+
+	if _, err := h.comment.Delete(ctx, cmt); err != nil {
+		return fmt.Errorf("delete: commentID[%s]: %w", cmt.ID, err)
+	}
+
+	return web.Respond(ctx, w, nil, http.StatusNoContent)
+}
+
+// Hide hides a comment from everyone but its author and the moderators.
+func (h *Handlers) Hide(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	cmt, err := h.queryComment(ctx, r)
+	if err != nil {
+		return err
+	}
+
+	userID, err := auth.GetUserID(ctx)
+	if err != nil {
+		return auth.NewAuthError("hide: %s", err)
+	}
+
+	cmt, err = h.comment.Hide(ctx, cmt, userID)
+	if err != nil {
+		return fmt.Errorf("hide: commentID[%s]: %w", cmt.ID, err)
+	}
+
+	return web.Respond(ctx, w, toAppComment(cmt, h.viewer(ctx)), http.StatusOK)
+}
+
+// Unhide makes a hidden comment visible again.
+func (h *Handlers) Unhide(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	cmt, err := h.queryComment(ctx, r)
+	if err != nil {
+		return err
+	}
+
+	cmt, err = h.comment.Unhide(ctx, cmt)
+	if err != nil {
+		return fmt.Errorf("unhide: commentID[%s]: %w", cmt.ID, err)
+	}
+
+	return web.Respond(ctx, w, toAppComment(cmt, h.viewer(ctx)), http.StatusOK)
+}
+
+// =============================================================================
+
+// create leaves a comment from the authenticated user on the owner.
+func (h *Handlers) create(ctx context.Context, w http.ResponseWriter, r *http.Request, own owner) error {
+	var app AppNewComment
+	if err := web.Decode(r, &app); err != nil {
+		return err
+	}
+
+	nc, err := toCoreNewComment(app)
+	if err != nil {
+		return v1.NewRequestError(err, http.StatusBadRequest)
+	}
+
+	userID, err := auth.GetUserID(ctx)
+	if err != nil {
+		return auth.NewAuthError("create: %s", err)
+	}
+
+	nc.IdeaID = own.ideaID
+	nc.OwnerType = own.typ
+	nc.OwnerID = own.id
+	nc.AuthorID = userID
+
+	cmt, err := h.comment.Create(ctx, nc)
+	if err != nil {
+		switch {
+		case errors.Is(err, comment.ErrParentNotFound):
+			return validate.NewFieldsError("parentID", comment.ErrParentNotFound)
+		case errors.Is(err, comment.ErrTooDeep):
+			return v1.NewRequestError(comment.ErrTooDeep, http.StatusBadRequest)
+		}
+		return fmt.Errorf("create: comment[%+v]: %w", nc, err)
+	}
+
+	return web.Respond(ctx, w, toAppComment(cmt, h.viewer(ctx)), http.StatusCreated)
+}
+
+// query returns the comments on the owner, either as a tree of threads with
+// the paging applied to the threads, or as a flat list oldest first.
+func (h *Handlers) query(ctx context.Context, w http.ResponseWriter, r *http.Request, own owner) error {
+	page, err := paging.ParseRequest(r)
+	if err != nil {
+		return err
+	}
+
+	view := r.URL.Query().Get("view")
+	if view == "" {
+		view = viewTree
+	}
+
+	v := h.viewer(ctx)
+
+	switch view {
+	case viewTree:
+		threads, err := h.comment.QueryThreads(ctx, own.typ, own.id, page.Number, page.RowsPerPage)
+		if err != nil {
+			return fmt.Errorf("query: %w", err)
+		}
+
+		total, err := h.comment.CountThreads(ctx, own.typ, own.id)
+		if err != nil {
+			return fmt.Errorf("count: %w", err)
+		}
+
+		return web.Respond(ctx, w, paging.NewResponse(toAppThreads(threads, v), total, page.Number, page.RowsPerPage), http.StatusOK)
+
+	case viewFlat:
+		var filter comment.QueryFilter
+		filter.WithOwner(own.typ, own.id)
+		filter.WithDeleted(false)
+
+		cmts, err := h.comment.Query(ctx, filter, comment.DefaultOrderBy, page.Number, page.RowsPerPage)
+		if err != nil {
+			return fmt.Errorf("query: %w", err)
+		}
+
+		total, err := h.comment.Count(ctx, filter)
+		if err != nil {
+			return fmt.Errorf("count: %w", err)
+		}
+
+		return web.Respond(ctx, w, paging.NewResponse(toAppComments(cmts, v), total, page.Number, page.RowsPerPage), http.StatusOK)
+	}
+
+	return validate.NewFieldsError("view", fmt.Errorf("must be %q or %q", viewTree, viewFlat))
+}
+
+// queryPostOwner retrieves the post named in the path if the authenticated
+// user can see the idea it belongs to.
+func (h *Handlers) queryPostOwner(ctx context.Context, r *http.Request) (owner, error) {
+	postID, err := uuid.Parse(web.Param(r, "post_id"))
+	if err != nil {
+		return owner{}, v1.NewRequestError(err, http.StatusBadRequest)
+	}
+
+	pst, err := h.post.QueryByID(ctx, postID)
+	if err != nil {
+		if errors.Is(err, post.ErrNotFound) {
+			return owner{}, v1.NewRequestError(post.ErrNotFound, http.StatusNotFound)
+		}
+		return owner{}, fmt.Errorf("query: postID[%s]: %w", postID, err)
+	}
+
+	if _, err := visible.Idea(ctx, h.idea, pst.IdeaID); err != nil {
+		if v1.IsRequestError(err) {
+			return owner{}, v1.NewRequestError(post.ErrNotFound, http.StatusNotFound)
+		}
+		return owner{}, err
+	}
+
+	return owner{typ: comment.OwnerTypePost, id: pst.ID, ideaID: pst.IdeaID}, nil
+}
+
+// queryChallengeOwner retrieves the challenge named in the path if the
+// authenticated user can see the idea it belongs to.
+func (h *Handlers) queryChallengeOwner(ctx context.Context, r *http.Request) (owner, error) {
+	challengeID, err := uuid.Parse(web.Param(r, "challenge_id"))
+	if err != nil {
+		return owner{}, v1.NewRequestError(err, http.StatusBadRequest)
+	}
+
+	chl, err := h.challenge.QueryByID(ctx, challengeID)
+	if err != nil {
+		if errors.Is(err, challenge.ErrNotFound) {
+			return owner{}, v1.NewRequestError(challenge.ErrNotFound, http.StatusNotFound)
+		}
+		return owner{}, fmt.Errorf("query: challengeID[%s]: %w", challengeID, err)
+	}
+
+	if _, err := visible.Idea(ctx, h.idea, chl.IdeaID); err != nil {
+		if v1.IsRequestError(err) {
+			return owner{}, v1.NewRequestError(challenge.ErrNotFound, http.StatusNotFound)
+		}
+		return owner{}, err
+	}
+
+	return owner{typ: comment.OwnerTypeChallenge, id: chl.ID, ideaID: chl.IdeaID}, nil
+}
+
+// queryComment retrieves the comment named in the path.
+func (h *Handlers) queryComment(ctx context.Context, r *http.Request) (comment.Comment, error) {
+	commentID, err := uuid.Parse(web.Param(r, "comment_id"))
+	if err != nil {
+		return comment.Comment{}, v1.NewRequestError(err, http.StatusBadRequest)
+	}
+
+	cmt, err := h.comment.QueryByID(ctx, commentID)
+	if err != nil {
+		if errors.Is(err, comment.ErrNotFound) {
+			return comment.Comment{}, v1.NewRequestError(comment.ErrNotFound, http.StatusNotFound)
+		}
+		return comment.Comment{}, fmt.Errorf("query: commentID[%s]: %w", commentID, err)
+	}
+
+	return cmt, nil
+}
+
+// queryAuthoredComment retrieves the comment named in the path if the
+// authenticated user can see it and wrote it.
+func (h *Handlers) queryAuthoredComment(ctx context.Context, r *http.Request) (comment.Comment, error) {
+	cmt, err := h.queryComment(ctx, r)
+	if err != nil {
+		return comment.Comment{}, err
+	}
+
+	if _, err := visible.Idea(ctx, h.idea, cmt.IdeaID); err != nil {
+		if v1.IsRequestError(err) {
+			return comment.Comment{}, v1.NewRequestError(comment.ErrNotFound, http.StatusNotFound)
+		}
+		return comment.Comment{}, err
+	}
+
+	userID, err := auth.GetUserID(ctx)
+	if err != nil {
+		return comment.Comment{}, auth.NewAuthError("query: commentID[%s]: %s", cmt.ID, err)
+	}
+
+	if cmt.AuthorID != userID {
+		return comment.Comment{}, v1.NewRequestError(ErrNotAuthor, http.StatusForbidden)
+	}
+
+	return cmt, nil
+}
+
+// viewer returns who the comments are rendered for.
+func (h *Handlers) viewer(ctx context.Context) viewer {
+	// The user has been checked by the authentication middleware.
+	userID, _ := auth.GetUserID(ctx)
+
+	v := viewer{userID: userID}
+	for _, role := range auth.GetClaims(ctx).Roles {
+		if role.Equal(user.RoleAdmin) {
+			v.moderator = true
+		}
+	}
+
+	return v
+}
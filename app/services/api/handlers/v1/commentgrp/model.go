@@ -0,0 +1,133 @@
+package commentgrp
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/dmanias/startupers/business/core/comment"
+	"github.com/dmanias/startupers/business/sys/validate"
+	"github.com/google/uuid"
+)
+
+// AppComment represents a comment on a post or a challenge. The content of
+// deleted comments, and of hidden ones unless the viewer wrote them or
+// moderates, is left out.
+type AppComment struct {
+	ID          string       `json:"id"`
+	IdeaID      string       `json:"ideaID"`
+	OwnerType   string       `json:"ownerType"`
+	OwnerID     string       `json:"ownerID"`
+	ParentID    string       `json:"parentID,omitempty"`
+	Depth       int          `json:"depth"`
+	AuthorID    string       `json:"authorID"`
+	Content     string       `json:"content"`
+	Hidden      bool         `json:"hidden"`
+	Deleted     bool         `json:"deleted"`
+	DateCreated string       `json:"dateCreated"`
+	DateUpdated string       `json:"dateUpdated"`
+	Replies     []AppComment `json:"replies,omitempty"`
+}
+
+// viewer is who a comment is rendered for.
+type viewer struct {
+	userID    uuid.UUID
+	moderator bool
+}
+
+func toAppComment(cmt comment.Comment, v viewer) AppComment {
+	var parentID string
+	if cmt.IsReply() {
+		parentID = cmt.ParentID.String()
+	}
+
+	content := cmt.Content
+	if cmt.Hidden() && !v.moderator && cmt.AuthorID != v.userID {
+		content = ""
+	}
+
+	return AppComment{
+		ID:          cmt.ID.String(),
+		IdeaID:      cmt.IdeaID.String(),
+		OwnerType:   cmt.OwnerType.Name(),
+		OwnerID:     cmt.OwnerID.String(),
+		ParentID:    parentID,
+		Depth:       cmt.Depth,
+		AuthorID:    cmt.AuthorID.String(),
+		Content:     content,
+		Hidden:      cmt.Hidden(),
+		Deleted:     cmt.Deleted(),
+		DateCreated: cmt.DateCreated.Format(time.RFC3339),
+		DateUpdated: cmt.DateUpdated.Format(time.RFC3339),
+	}
+}
+
+func toAppComments(cmts []comment.Comment, v viewer) []AppComment {
+	items := make([]AppComment, len(cmts))
+	for i, cmt := range cmts {
+		items[i] = toAppComment(cmt, v)
+	}
+	return items
+}
+
+func toAppThreads(threads []comment.Thread, v viewer) []AppComment {
+	items := make([]AppComment, len(threads))
+	for i, thread := range threads {
+		items[i] = toAppComment(thread.Comment, v)
+		items[i].Replies = toAppThreads(thread.Replies, v)
+	}
+	return items
+}
+
+// =============================================================================
+
+// AppNewComment contains information needed to leave a comment. The parent
+// is set when replying to another comment.
+type AppNewComment struct {
+	ParentID string `json:"parentID" validate:"omitempty,uuid"`
+	Content  string `json:"content" validate:"required,max=5000"`
+}
+
+func toCoreNewComment(app AppNewComment) (comment.NewComment, error) {
+	var parentID uuid.UUID
+	if app.ParentID != "" {
+		var err error
+		parentID, err = uuid.Parse(app.ParentID)
+		if err != nil {
+			return comment.NewComment{}, fmt.Errorf("parsing parentID: %w", err)
+		}
+	}
+
+	nc := comment.NewComment{
+		ParentID: parentID,
+		Content:  app.Content,
+	}
+
+	return nc, nil
+}
+
+// Validate checks the data in the model is considered clean.
+func (app AppNewComment) Validate() error {
+	if err := validate.Check(app); err != nil {
+		return err
+	}
+	return nil
+}
+
+// AppUpdateComment contains information needed to edit a comment.
+type AppUpdateComment struct {
+	Content *string `json:"content" validate:"required,max=5000"`
+}
+
+func toCoreUpdateComment(app AppUpdateComment) comment.UpdateComment {
+	return comment.UpdateComment{
+		Content: app.Content,
+	}
+}
+
+// Validate checks the data in the model is considered clean.
+func (app AppUpdateComment) Validate() error {
+	if err := validate.Check(app); err != nil {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,60 @@
+// Package exploregrp maintains the group of handlers for discovering public
+// ideas.
+package exploregrp
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/dmanias/startupers/business/core/explore"
+	"github.com/dmanias/startupers/business/sys/validate"
+	"github.com/dmanias/startupers/business/web/v1/paging"
+	"github.com/dmanias/startupers/foundation/web"
+	"go.uber.org/zap"
+)
+
+// Handlers manages the set of explore endpoints.
+type Handlers struct {
+	explore *explore.Core
+	log     *zap.SugaredLogger
+}
+
+// New constructs a handlers for route access.
+func New(explore *explore.Core, log *zap.SugaredLogger) *Handlers {
+	return &Handlers{
+		explore: explore,
+		log:     log,
+	}
+}
+
+// Feed returns public ideas ordered by trending score. Pages are requested
+// with the cursor returned by the previous page.
+func (h *Handlers) Feed(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	page, err := paging.ParseCursorRequest(r)
+	if err != nil {
+		return err
+	}
+
+	cursor, err := explore.ParseCursor(page.Cursor)
+	if err != nil {
+		return validate.NewFieldsError("cursor", err)
+	}
+
+	filter, err := parseFilter(r)
+	if err != nil {
+		return err
+	}
+
+	feed, next, err := h.explore.QueryFeed(ctx, filter, cursor, page.RowsPerPage)
+	if err != nil {
+		return fmt.Errorf("queryfeed: %w", err)
+	}
+
+	items := make([]AppFeedIdea, len(feed))
+	for i, item := range feed {
+		items[i] = toAppFeedIdea(item)
+	}
+
+	return web.Respond(ctx, w, paging.NewCursorResponse(items, next.Encode()), http.StatusOK)
+}
@@ -0,0 +1,26 @@
+package exploregrp
+
+import (
+	"net/http"
+
+	"github.com/dmanias/startupers/business/core/explore"
+)
+
+func parseFilter(r *http.Request) (explore.QueryFilter, error) {
+	values := r.URL.Query()
+	var filter explore.QueryFilter
+
+	if category := values.Get("category"); category != "" {
+		filter.WithCategory(category)
+	}
+
+	if tag := values.Get("tag"); tag != "" {
+		filter.WithTag(tag)
+	}
+
+	if err := filter.Validate(); err != nil {
+		return explore.QueryFilter{}, err
+	}
+
+	return filter, nil
+}
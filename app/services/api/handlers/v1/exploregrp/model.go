@@ -0,0 +1,36 @@
+package exploregrp
+
+import (
+	"time"
+
+	"github.com/dmanias/startupers/business/core/explore"
+)
+
+// AppFeedIdea represents a public idea in the explore feed.
+type AppFeedIdea struct {
+	ID          string   `json:"id"`
+	UserID      string   `json:"userID"`
+	Title       string   `json:"title"`
+	Description string   `json:"description"`
+	Category    string   `json:"category"`
+	Tags        []string `json:"tags"`
+	AvatarURL   string   `json:"avatarURL"`
+	Stage       string   `json:"stage"`
+	Score       float64  `json:"score"`
+	DateCreated string   `json:"dateCreated"`
+}
+
+func toAppFeedIdea(item explore.Item) AppFeedIdea {
+	return AppFeedIdea{
+		ID:          item.Idea.ID.String(),
+		UserID:      item.Idea.UserID.String(),
+		Title:       item.Idea.Title,
+		Description: item.Idea.Description,
+		Category:    item.Idea.Category,
+		Tags:        item.Idea.Tags,
+		AvatarURL:   item.Idea.AvatarURL,
+		Stage:       item.Idea.Stage.Name(),
+		Score:       item.Score,
+		DateCreated: item.Idea.DateCreated.Format(time.RFC3339),
+	}
+}
@@ -0,0 +1,248 @@
+// Package feedgrp maintains the group of handlers for following ideas and
+// users and for the activity feed.
+package feedgrp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/dmanias/startupers/business/core/activity"
+	"github.com/dmanias/startupers/business/core/follow"
+	"github.com/dmanias/startupers/business/core/idea"
+	"github.com/dmanias/startupers/business/core/user"
+	"github.com/dmanias/startupers/business/sys/validate"
+	"github.com/dmanias/startupers/business/web/auth"
+	v1 "github.com/dmanias/startupers/business/web/v1"
+	"github.com/dmanias/startupers/business/web/v1/paging"
+	"github.com/dmanias/startupers/business/web/v1/visible"
+	"github.com/dmanias/startupers/foundation/web"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// Handlers manages the set of feed endpoints.
+type Handlers struct {
+	activity *activity.Core
+	follow   *follow.Core
+	idea     *idea.Core
+	user     *user.Core
+	log      *zap.SugaredLogger
+}
+
+// New constructs a handlers for route access.
+func New(activity *activity.Core, follow *follow.Core, idea *idea.Core, user *user.Core, log *zap.SugaredLogger) *Handlers {
+	return &Handlers{
+		activity: activity,
+		follow:   follow,
+		idea:     idea,
+		user:     user,
+		log:      log,
+	}
+}
+
+// Feed returns the activity on the ideas and by the users the authenticated
+// user follows, newest first. Pages are requested with the cursor returned by
+// the previous page.
+func (h *Handlers) Feed(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	page, err := paging.ParseCursorRequest(r)
+	if err != nil {
+		return err
+	}
+
+	cursor, err := activity.ParseCursor(page.Cursor)
+	if err != nil {
+		return validate.NewFieldsError("cursor", err)
+	}
+
+	userID, err := auth.GetUserID(ctx)
+	if err != nil {
+		return auth.NewAuthError("feed: %s", err)
+	}
+
+	acts, next, err := h.activity.QueryFeed(ctx, userID, cursor, page.RowsPerPage)
+	if err != nil {
+		return fmt.Errorf("queryfeed: %w", err)
+	}
+
+	ideas, err := h.queryIdeas(ctx, acts)
+	if err != nil {
+		return err
+	}
+
+	items := make([]AppActivity, len(acts))
+	for i, act := range acts {
+		items[i] = toAppActivity(act, ideas)
+	}
+
+	return web.Respond(ctx, w, paging.NewCursorResponse(items, next.Encode()), http.StatusOK)
+}
+
+// QueryFollowing returns the ideas and users the authenticated user follows,
+// most recently followed first, with paging.
+func (h *Handlers) QueryFollowing(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	page, err := paging.ParseRequest(r)
+	if err != nil {
+		return err
+	}
+
+	userID, err := auth.GetUserID(ctx)
+	if err != nil {
+		return auth.NewAuthError("query: %s", err)
+	}
+
+	var filter follow.QueryFilter
+	filter.WithUserID(userID)
+
+	if typ := r.URL.Query().Get("type"); typ != "" {
+		targetType, err := follow.ParseTargetType(typ)
+		if err != nil {
+			return validate.NewFieldsError("type", err)
+		}
+		filter.WithTargetType(targetType)
+	}
+
+	fols, err := h.follow.Query(ctx, filter, follow.DefaultOrderBy, page.Number, page.RowsPerPage)
+	if err != nil {
+		return fmt.Errorf("query: %w", err)
+	}
+
+	total, err := h.follow.Count(ctx, filter)
+	if err != nil {
+		return fmt.Errorf("count: %w", err)
+	}
+
+	return web.Respond(ctx, w, paging.NewResponse(toAppFollows(fols), total, page.Number, page.RowsPerPage), http.StatusOK)
+}
+
+// FollowIdea makes the authenticated user follow an idea they can see.
+func (h *Handlers) FollowIdea(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	ideaID, err := h.queryVisibleIdea(ctx, r)
+	if err != nil {
+		return err
+	}
+
+	return h.create(ctx, w, follow.TargetTypeIdea, ideaID)
+}
+
+// UnfollowIdea makes the authenticated user stop following an idea.
+func (h *Handlers) UnfollowIdea(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	ideaID, err := uuid.Parse(web.Param(r, "idea_id"))
+	if err != nil {
+		return v1.NewRequestError(err, http.StatusBadRequest)
+	}
+
+	return h.delete(ctx, w, follow.TargetTypeIdea, ideaID)
+}
+
+// FollowUser makes the authenticated user follow another user.
+func (h *Handlers) FollowUser(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	userID, err := uuid.Parse(web.Param(r, "user_id"))
+	if err != nil {
+		return v1.NewRequestError(err, http.StatusBadRequest)
+	}
+
+	if _, err := h.user.QueryByID(ctx, userID); err != nil {
+		if errors.Is(err, user.ErrNotFound) {
+			return v1.NewRequestError(user.ErrNotFound, http.StatusNotFound)
+		}
+		return fmt.Errorf("query: userID[%s]: %w", userID, err)
+	}
+
+	return h.create(ctx, w, follow.TargetTypeUser, userID)
+}
+
+// UnfollowUser makes the authenticated user stop following another user.
+func (h *Handlers) UnfollowUser(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	userID, err := uuid.Parse(web.Param(r, "user_id"))
+	if err != nil {
+		return v1.NewRequestError(err, http.StatusBadRequest)
+	}
+
+	return h.delete(ctx, w, follow.TargetTypeUser, userID)
+}
+
+// =============================================================================
+
+// create makes the authenticated user follow the target.
+func (h *Handlers) create(ctx context.Context, w http.ResponseWriter, targetType follow.TargetType, targetID uuid.UUID) error {
+	userID, err := auth.GetUserID(ctx)
+	if err != nil {
+		return auth.NewAuthError("follow: %s", err)
+	}
+
+	fol, err := h.follow.Follow(ctx, userID, targetType, targetID)
+	if err != nil {
+		switch {
+		case errors.Is(err, follow.ErrAlreadyFollowing):
+			return v1.NewRequestError(follow.ErrAlreadyFollowing, http.StatusConflict)
+		case errors.Is(err, follow.ErrFollowSelf):
+			return v1.NewRequestError(follow.ErrFollowSelf, http.StatusBadRequest)
+		}
+		return fmt.Errorf("follow: %s[%s]: %w", targetType.Name(), targetID, err)
+	}
+
+	return web.Respond(ctx, w, toAppFollow(fol), http.StatusCreated)
+}
+
+// delete makes the authenticated user stop following the target.
+func (h *Handlers) delete(ctx context.Context, w http.ResponseWriter, targetType follow.TargetType, targetID uuid.UUID) error {
+	userID, err := auth.GetUserID(ctx)
+	if err != nil {
+		return auth.NewAuthError("unfollow: %s", err)
+	}
+
+	if err := h.follow.Unfollow(ctx, userID, targetType, targetID); err != nil {
+		if errors.Is(err, follow.ErrNotFound) {
+			return v1.NewRequestError(follow.ErrNotFound, http.StatusNotFound)
+		}
+		return fmt.Errorf("unfollow: %s[%s]: %w", targetType.Name(), targetID, err)
+	}
+
+	return web.Respond(ctx, w, nil, http.StatusNoContent)
+}
+
+// queryVisibleIdea returns the ID of the idea named in the path if the
+// authenticated user is allowed to see it. Ideas the user can't see are
+// reported as not found.
+func (h *Handlers) queryVisibleIdea(ctx context.Context, r *http.Request) (uuid.UUID, error) {
+	ideaID, err := uuid.Parse(web.Param(r, "idea_id"))
+	if err != nil {
+		return uuid.Nil, v1.NewRequestError(err, http.StatusBadRequest)
+	}
+
+	if _, err := visible.Idea(ctx, h.idea, ideaID); err != nil {
+		return uuid.Nil, err
+	}
+
+	return ideaID, nil
+}
+
+// queryIdeas retrieves the ideas the activities are about, by ID.
+func (h *Handlers) queryIdeas(ctx context.Context, acts []activity.Activity) (map[uuid.UUID]idea.Idea, error) {
+	if len(acts) == 0 {
+		return nil, nil
+	}
+
+	seen := make(map[uuid.UUID]bool, len(acts))
+	var ids []uuid.UUID
+	for _, act := range acts {
+		if !seen[act.IdeaID] {
+			seen[act.IdeaID] = true
+			ids = append(ids, act.IdeaID)
+		}
+	}
+
+	ideas, err := h.idea.QueryByIDs(ctx, ids)
+	if err != nil {
+		return nil, fmt.Errorf("queryfeed: %w", err)
+	}
+
+	byID := make(map[uuid.UUID]idea.Idea, len(ideas))
+	for _, idr := range ideas {
+		byID[idr.ID] = idr
+	}
+
+	return byID, nil
+}
@@ -0,0 +1,63 @@
+package feedgrp
+
+import (
+	"time"
+
+	"github.com/dmanias/startupers/business/core/activity"
+	"github.com/dmanias/startupers/business/core/follow"
+	"github.com/dmanias/startupers/business/core/idea"
+	"github.com/google/uuid"
+)
+
+// AppActivity represents an entry in the activity feed.
+type AppActivity struct {
+	ID          string            `json:"id"`
+	Type        string            `json:"type"`
+	ActorID     string            `json:"actorID"`
+	IdeaID      string            `json:"ideaID"`
+	IdeaTitle   string            `json:"ideaTitle"`
+	SubjectID   string            `json:"subjectID,omitempty"`
+	Details     map[string]string `json:"details,omitempty"`
+	DateCreated string            `json:"dateCreated"`
+}
+
+func toAppActivity(act activity.Activity, ideas map[uuid.UUID]idea.Idea) AppActivity {
+	var subjectID string
+	if act.SubjectID != uuid.Nil {
+		subjectID = act.SubjectID.String()
+	}
+
+	return AppActivity{
+		ID:          act.ID.String(),
+		Type:        act.Type.Name(),
+		ActorID:     act.ActorID.String(),
+		IdeaID:      act.IdeaID.String(),
+		IdeaTitle:   ideas[act.IdeaID].Title,
+		SubjectID:   subjectID,
+		Details:     act.Details,
+		DateCreated: act.DateCreated.Format(time.RFC3339),
+	}
+}
+
+// AppFollow represents an idea or user being followed.
+type AppFollow struct {
+	TargetType  string `json:"targetType"`
+	TargetID    string `json:"targetID"`
+	DateCreated string `json:"dateCreated"`
+}
+
+func toAppFollow(fol follow.Follow) AppFollow {
+	return AppFollow{
+		TargetType:  fol.TargetType.Name(),
+		TargetID:    fol.TargetID.String(),
+		DateCreated: fol.DateCreated.Format(time.RFC3339),
+	}
+}
+
+func toAppFollows(fols []follow.Follow) []AppFollow {
+	items := make([]AppFollow, len(fols))
+	for i, fol := range fols {
+		items[i] = toAppFollow(fol)
+	}
+	return items
+}
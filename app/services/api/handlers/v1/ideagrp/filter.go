@@ -41,6 +41,14 @@ func parseFilter(r *http.Request) (idea.QueryFilter, error) {
 		filter.WithTag(tag)
 	}
 
+	if stage := values.Get("stage"); stage != "" {
+		st, err := idea.ParseStage(stage)
+		if err != nil {
+			return idea.QueryFilter{}, validate.NewFieldsError("stage", err)
+		}
+		filter.WithStage(st)
+	}
+
 	if createdDate := values.Get("start_created_date"); createdDate != "" {
 		t, err := time.Parse(time.RFC3339, createdDate)
 		if err != nil {
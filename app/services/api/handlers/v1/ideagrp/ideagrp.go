@@ -7,9 +7,16 @@ import (
 	"fmt"
 	"github.com/dmanias/startupers/app/services/api/handlers/v1/aigrp"
 	"github.com/dmanias/startupers/app/services/api/handlers/v1/moderationgrp"
+	"github.com/dmanias/startupers/business/core/category"
+	"github.com/dmanias/startupers/business/core/fork"
 	"github.com/dmanias/startupers/business/core/idea"
+	"github.com/dmanias/startupers/business/core/invitation"
+	"github.com/dmanias/startupers/business/sys/uploads"
+	"github.com/dmanias/startupers/business/sys/validate"
+	"github.com/dmanias/startupers/business/web/auth"
 	v1 "github.com/dmanias/startupers/business/web/v1"
 	"github.com/dmanias/startupers/business/web/v1/paging"
+	"github.com/dmanias/startupers/business/web/v1/visible"
 	"github.com/dmanias/startupers/foundation/web"
 	"github.com/google/uuid"
 	"go.uber.org/zap"
@@ -19,6 +26,7 @@ import (
 	"net/http"
 	"os"
 	"path/filepath"
+	"strconv"
 	"time"
 )
 
@@ -29,9 +37,20 @@ type DalleResponse struct {
 	} `json:"data"`
 }
 
+// Set of error variables for handling idea permissions.
+var (
+	ErrNotOwner   = errors.New("only the owner of the idea can do this")
+	ErrCannotEdit = errors.New("only the owner or an editor can change the idea")
+	ErrOwnerField = errors.New("only the owner can change the privacy or avatar of the idea")
+	ErrCannotView = errors.New("only the owner or a collaborator can see the history of the idea")
+)
+
 // Handlers manages the set of idea endpoints.
 type Handlers struct {
 	idea               *idea.Core
+	invitation         *invitation.Core
+	fork               *fork.Core
+	category           *category.Core
 	log                *zap.SugaredLogger
 	aiHandlers         *aigrp.Handlers
 	moderationHandlers *moderationgrp.Handlers
@@ -39,9 +58,12 @@ type Handlers struct {
 }
 
 // New constructs a handlers for route access.
-func New(idea *idea.Core, log *zap.SugaredLogger, aiHandlers *aigrp.Handlers, moderationHandlers *moderationgrp.Handlers, APIHost string) *Handlers {
+func New(idea *idea.Core, invitation *invitation.Core, fork *fork.Core, category *category.Core, log *zap.SugaredLogger, aiHandlers *aigrp.Handlers, moderationHandlers *moderationgrp.Handlers, APIHost string) *Handlers {
 	return &Handlers{
 		idea:               idea,
+		invitation:         invitation,
+		fork:               fork,
+		category:           category,
 		log:                log,
 		aiHandlers:         aiHandlers,
 		moderationHandlers: moderationHandlers,
@@ -143,6 +165,14 @@ func (h *Handlers) Create(ctx context.Context, w http.ResponseWriter, r *http.Re
 		return err
 	}
 
+	// Check the author and the category before asking for an avatar.
+	if err := h.checkAuthor(ctx); err != nil {
+		return err
+	}
+	if err := h.checkCategory(ctx, app.Category); err != nil {
+		return err
+	}
+
 	// Assume the question is the idea's description
 	ideaDescr := "Idea title:" + app.Title + ", " + "Idea description:" + app.Description + ", " + "Idea tags:" + fmt.Sprint(app.Tags)
 
@@ -207,6 +237,9 @@ func (h *Handlers) Create(ctx context.Context, w http.ResponseWriter, r *http.Re
 	fmt.Printf("store idea2")
 	newIdea, err := h.idea.Create(ctx, nc)
 	if err != nil {
+		if errors.Is(err, idea.ErrAuthorGuard) {
+			return v1.NewRequestError(err, http.StatusForbidden)
+		}
 		return fmt.Errorf("create: idea[%+v]: %w", newIdea, err)
 	}
 
@@ -220,7 +253,7 @@ func generateUniqueFilename() string {
 
 func saveImageLocally(filename string, data []byte) (string, error) {
 	// Specify the directory where you want to save the images
-	saveDir := "uploads"
+	saveDir := uploads.Dir
 
 	// Create the directory if it doesn't exist
 	err := os.MkdirAll(saveDir, os.ModePerm)
@@ -272,12 +305,44 @@ func (h *Handlers) Update(ctx context.Context, w http.ResponseWriter, r *http.Re
 		return v1.NewRequestError(err, http.StatusBadRequest)
 	}
 
-	idea, err := h.idea.QueryByID(ctx, *uc.ID)
+	// The idea in the path takes precedence over the one in the body.
+	if param := web.Param(r, "idea_id"); param != "" {
+		ideaID, err := uuid.Parse(param)
+		if err != nil {
+			return v1.NewRequestError(err, http.StatusBadRequest)
+		}
+		uc.ID = &ideaID
+	}
+
+	idea, err := visible.Idea(ctx, h.idea, *uc.ID)
+	if err != nil {
+		return err
+	}
+
+	if err := h.checkCanEdit(ctx, idea); err != nil {
+		return err
+	}
+
+	if err := h.checkOwnerFields(ctx, idea, uc.Privacy, uc.AvatarURL); err != nil {
+		return err
+	}
+
+	if uc.AvatarURL != nil && *uc.AvatarURL != idea.AvatarURL && uploads.Is(*uc.AvatarURL) {
+		return v1.NewRequestError(uploads.ErrServerPath, http.StatusBadRequest)
+	}
+
+	if uc.Category != nil && *uc.Category != idea.Category {
+		if err := h.checkCategory(ctx, *uc.Category); err != nil {
+			return err
+		}
+	}
+
+	userID, err := auth.GetUserID(ctx)
 	if err != nil {
-		return fmt.Errorf("query: ideaID[%s]: %w", uc.ID, err)
+		return auth.NewAuthError("update: %s", err)
 	}
 
-	updatedIdea, err := h.idea.Update(ctx, idea, uc)
+	updatedIdea, err := h.idea.Update(ctx, idea, uc, userID)
 	if err != nil {
 		return fmt.Errorf("update: idea[%+v]: %w", idea, err)
 	}
@@ -285,36 +350,438 @@ func (h *Handlers) Update(ctx context.Context, w http.ResponseWriter, r *http.Re
 	return web.Respond(ctx, w, toAppIdea(updatedIdea), http.StatusOK)
 }
 
-// Delete removes an idea from the system.
+// Delete moves an idea to the trash, from where its owner can restore it
+// until the retention period is over.
 func (h *Handlers) Delete(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
 	ideaID, err := uuid.Parse(web.Param(r, "idea_id"))
 	if err != nil {
 		return v1.NewRequestError(err, http.StatusBadRequest)
 	}
 
-	idea, err := h.idea.QueryByID(ctx, ideaID)
+	idea, err := visible.Idea(ctx, h.idea, ideaID)
 	if err != nil {
-		return fmt.Errorf("query: ideaID[%s]: %w", ideaID, err)
+		return err
 	}
 
-	err = h.idea.Delete(ctx, idea)
+	userID, err := auth.GetUserID(ctx)
 	if err != nil {
-		// Log the actual error message
-		h.log.Errorf("Error deleting idea: %v", err)
+		return auth.NewAuthError("delete: %s", err)
+	}
 
-		return v1.NewRequestError(fmt.Errorf("Cannot delete idea with existing posts"), http.StatusConflict)
+	if idea.UserID != userID {
+		return v1.NewRequestError(ErrNotOwner, http.StatusForbidden)
 	}
 
-	// Delete the corresponding image from the uploads directory
-	err = os.Remove(idea.AvatarURL)
-	if err != nil {
-		h.log.Errorf("Error deleting image: %v", err)
-		// You can decide whether you want to return an error here or just log it
+	if _, err := h.idea.Delete(ctx, idea, userID); err != nil {
+		return fmt.Errorf("delete: ideaID[%s]: %w", ideaID, err)
 	}
 
 	return web.Respond(ctx, w, nil, http.StatusNoContent)
 }
 
+// TransitionStage moves an idea to another stage of its lifecycle.
+func (h *Handlers) TransitionStage(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	ideaID, err := uuid.Parse(web.Param(r, "idea_id"))
+	if err != nil {
+		return v1.NewRequestError(err, http.StatusBadRequest)
+	}
+
+	var app AppStageTransition
+	if err := web.Decode(r, &app); err != nil {
+		return err
+	}
+
+	stage, err := idea.ParseStage(app.Stage)
+	if err != nil {
+		return v1.NewRequestError(err, http.StatusBadRequest)
+	}
+
+	idr, err := visible.Idea(ctx, h.idea, ideaID)
+	if err != nil {
+		return err
+	}
+
+	if err := h.checkCanEdit(ctx, idr); err != nil {
+		return err
+	}
+
+	userID, err := auth.GetUserID(ctx)
+	if err != nil {
+		return auth.NewAuthError("transition: %s", err)
+	}
+
+	idr, err = h.idea.TransitionStage(ctx, idr, stage, userID, app.Note)
+	if err != nil {
+		switch {
+		case errors.Is(err, idea.ErrInvalidTransition):
+			return v1.NewRequestError(err, http.StatusConflict)
+		case errors.Is(err, idea.ErrStageGuard):
+			return v1.NewRequestError(err, http.StatusUnprocessableEntity)
+		}
+		return fmt.Errorf("transition: ideaID[%s]: %w", ideaID, err)
+	}
+
+	return web.Respond(ctx, w, toAppIdea(idr), http.StatusOK)
+}
+
+// QueryStageHistory returns the stage transitions of an idea, oldest first.
+func (h *Handlers) QueryStageHistory(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	ideaID, err := uuid.Parse(web.Param(r, "idea_id"))
+	if err != nil {
+		return v1.NewRequestError(err, http.StatusBadRequest)
+	}
+
+	if _, err := visible.Idea(ctx, h.idea, ideaID); err != nil {
+		return err
+	}
+
+	history, err := h.idea.QueryStageHistory(ctx, ideaID)
+	if err != nil {
+		return fmt.Errorf("querystagehistory: ideaID[%s]: %w", ideaID, err)
+	}
+
+	return web.Respond(ctx, w, toAppStageHistory(history), http.StatusOK)
+}
+
+// Vote adds the authenticated user's vote to an idea.
+func (h *Handlers) Vote(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	ideaID, err := uuid.Parse(web.Param(r, "idea_id"))
+	if err != nil {
+		return v1.NewRequestError(err, http.StatusBadRequest)
+	}
+
+	idr, err := visible.Idea(ctx, h.idea, ideaID)
+	if err != nil {
+		return err
+	}
+
+	userID, err := auth.GetUserID(ctx)
+	if err != nil {
+		return auth.NewAuthError("vote: %s", err)
+	}
+
+	idr, err = h.idea.Vote(ctx, idr, userID)
+	if err != nil {
+		if errors.Is(err, idea.ErrAlreadyVoted) {
+			return v1.NewRequestError(idea.ErrAlreadyVoted, http.StatusConflict)
+		}
+		return fmt.Errorf("vote: ideaID[%s]: %w", ideaID, err)
+	}
+
+	return web.Respond(ctx, w, toAppIdea(idr), http.StatusCreated)
+}
+
+// Unvote takes back the authenticated user's vote on an idea.
+func (h *Handlers) Unvote(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	ideaID, err := uuid.Parse(web.Param(r, "idea_id"))
+	if err != nil {
+		return v1.NewRequestError(err, http.StatusBadRequest)
+	}
+
+	idr, err := visible.Idea(ctx, h.idea, ideaID)
+	if err != nil {
+		return err
+	}
+
+	userID, err := auth.GetUserID(ctx)
+	if err != nil {
+		return auth.NewAuthError("unvote: %s", err)
+	}
+
+	idr, err = h.idea.Unvote(ctx, idr, userID)
+	if err != nil {
+		if errors.Is(err, idea.ErrVoteNotFound) {
+			return v1.NewRequestError(idea.ErrVoteNotFound, http.StatusNotFound)
+		}
+		return fmt.Errorf("unvote: ideaID[%s]: %w", ideaID, err)
+	}
+
+	return web.Respond(ctx, w, toAppIdea(idr), http.StatusOK)
+}
+
+// QueryVotes returns the votes on an idea, newest first, with paging.
+func (h *Handlers) QueryVotes(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	page, err := paging.ParseRequest(r)
+	if err != nil {
+		return err
+	}
+
+	ideaID, err := uuid.Parse(web.Param(r, "idea_id"))
+	if err != nil {
+		return v1.NewRequestError(err, http.StatusBadRequest)
+	}
+
+	if _, err := visible.Idea(ctx, h.idea, ideaID); err != nil {
+		return err
+	}
+
+	votes, err := h.idea.QueryVotes(ctx, ideaID, page.Number, page.RowsPerPage)
+	if err != nil {
+		return fmt.Errorf("query: %w", err)
+	}
+
+	total, err := h.idea.CountVotes(ctx, ideaID)
+	if err != nil {
+		return fmt.Errorf("count: %w", err)
+	}
+
+	return web.Respond(ctx, w, paging.NewResponse(toAppVotes(votes), total, page.Number, page.RowsPerPage), http.StatusOK)
+}
+
+// Fork copies an idea into the workspace of the authenticated user.
+func (h *Handlers) Fork(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	ideaID, err := uuid.Parse(web.Param(r, "idea_id"))
+	if err != nil {
+		return v1.NewRequestError(err, http.StatusBadRequest)
+	}
+
+	var app AppNewFork
+	if err := web.Decode(r, &app); err != nil {
+		return err
+	}
+
+	src, err := visible.Idea(ctx, h.idea, ideaID)
+	if err != nil {
+		return err
+	}
+
+	userID, err := auth.GetUserID(ctx)
+	if err != nil {
+		return auth.NewAuthError("fork: %s", err)
+	}
+
+	forked, err := h.fork.Fork(ctx, src, toCoreNewFork(app, userID))
+	if err != nil {
+		if errors.Is(err, fork.ErrNotForkable) || errors.Is(err, idea.ErrAuthorGuard) {
+			return v1.NewRequestError(err, http.StatusForbidden)
+		}
+		return fmt.Errorf("fork: ideaID[%s]: %w", ideaID, err)
+	}
+
+	return web.Respond(ctx, w, toAppIdea(forked), http.StatusCreated)
+}
+
+// QueryForks returns the direct forks of an idea the user can see, with
+// paging.
+func (h *Handlers) QueryForks(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	ideaID, err := uuid.Parse(web.Param(r, "idea_id"))
+	if err != nil {
+		return v1.NewRequestError(err, http.StatusBadRequest)
+	}
+
+	page, err := paging.ParseRequest(r)
+	if err != nil {
+		return err
+	}
+
+	orderBy, err := parseOrder(r)
+	if err != nil {
+		return err
+	}
+
+	if _, err := visible.Idea(ctx, h.idea, ideaID); err != nil {
+		return err
+	}
+
+	userID, err := auth.GetUserID(ctx)
+	if err != nil {
+		return auth.NewAuthError("queryforks: %s", err)
+	}
+
+	var filter idea.QueryFilter
+	filter.WithForkedFrom(ideaID)
+	filter.WithViewerID(userID)
+
+	forks, err := h.idea.Query(ctx, filter, orderBy, page.Number, page.RowsPerPage)
+	if err != nil {
+		return fmt.Errorf("queryforks: %w", err)
+	}
+
+	items := make([]AppIdea, len(forks))
+	for i, f := range forks {
+		items[i] = toAppIdea(f)
+	}
+
+	total, err := h.idea.Count(ctx, filter)
+	if err != nil {
+		return fmt.Errorf("count: %w", err)
+	}
+
+	return web.Respond(ctx, w, paging.NewResponse(items, total, page.Number, page.RowsPerPage), http.StatusOK)
+}
+
+// QueryLineage returns the fork tree an idea belongs to, from the original
+// idea down.
+func (h *Handlers) QueryLineage(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	ideaID, err := uuid.Parse(web.Param(r, "idea_id"))
+	if err != nil {
+		return v1.NewRequestError(err, http.StatusBadRequest)
+	}
+
+	if _, err := visible.Idea(ctx, h.idea, ideaID); err != nil {
+		return err
+	}
+
+	userID, err := auth.GetUserID(ctx)
+	if err != nil {
+		return auth.NewAuthError("querylineage: %s", err)
+	}
+
+	lineage, err := h.idea.QueryLineage(ctx, ideaID)
+	if err != nil {
+		return fmt.Errorf("querylineage: ideaID[%s]: %w", ideaID, err)
+	}
+
+	return web.Respond(ctx, w, toAppLineage(lineage, userID), http.StatusOK)
+}
+
+// QueryRevisions returns the revisions of an idea, newest first, with paging.
+func (h *Handlers) QueryRevisions(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	ideaID, err := uuid.Parse(web.Param(r, "idea_id"))
+	if err != nil {
+		return v1.NewRequestError(err, http.StatusBadRequest)
+	}
+
+	page, err := paging.ParseRequest(r)
+	if err != nil {
+		return err
+	}
+
+	if _, err := h.queryHistoryIdea(ctx, ideaID); err != nil {
+		return err
+	}
+
+	revs, err := h.idea.QueryRevisions(ctx, ideaID, page.Number, page.RowsPerPage)
+	if err != nil {
+		return fmt.Errorf("queryrevisions: %w", err)
+	}
+
+	items := make([]AppRevision, len(revs))
+	for i, rev := range revs {
+		items[i] = toAppRevision(rev)
+	}
+
+	total, err := h.idea.CountRevisions(ctx, ideaID)
+	if err != nil {
+		return fmt.Errorf("countrevisions: %w", err)
+	}
+
+	return web.Respond(ctx, w, paging.NewResponse(items, total, page.Number, page.RowsPerPage), http.StatusOK)
+}
+
+// QueryRevision returns the specified revision of an idea.
+func (h *Handlers) QueryRevision(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	ideaID, err := uuid.Parse(web.Param(r, "idea_id"))
+	if err != nil {
+		return v1.NewRequestError(err, http.StatusBadRequest)
+	}
+
+	number, err := strconv.Atoi(web.Param(r, "number"))
+	if err != nil {
+		return v1.NewRequestError(err, http.StatusBadRequest)
+	}
+
+	if _, err := h.queryHistoryIdea(ctx, ideaID); err != nil {
+		return err
+	}
+
+	rev, err := h.queryRevision(ctx, ideaID, number)
+	if err != nil {
+		return err
+	}
+
+	return web.Respond(ctx, w, toAppRevision(rev), http.StatusOK)
+}
+
+// DiffRevisions compares two revisions of an idea field by field.
+func (h *Handlers) DiffRevisions(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	ideaID, err := uuid.Parse(web.Param(r, "idea_id"))
+	if err != nil {
+		return v1.NewRequestError(err, http.StatusBadRequest)
+	}
+
+	values := r.URL.Query()
+
+	fromNumber, err := strconv.Atoi(values.Get("from"))
+	if err != nil {
+		return validate.NewFieldsError("from", err)
+	}
+
+	toNumber, err := strconv.Atoi(values.Get("to"))
+	if err != nil {
+		return validate.NewFieldsError("to", err)
+	}
+
+	if _, err := h.queryHistoryIdea(ctx, ideaID); err != nil {
+		return err
+	}
+
+	from, err := h.queryRevision(ctx, ideaID, fromNumber)
+	if err != nil {
+		return err
+	}
+
+	to, err := h.queryRevision(ctx, ideaID, toNumber)
+	if err != nil {
+		return err
+	}
+
+	return web.Respond(ctx, w, toAppRevisionDiff(from, to), http.StatusOK)
+}
+
+// RestoreRevision brings an idea back to the content of a past revision.
+func (h *Handlers) RestoreRevision(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	ideaID, err := uuid.Parse(web.Param(r, "idea_id"))
+	if err != nil {
+		return v1.NewRequestError(err, http.StatusBadRequest)
+	}
+
+	number, err := strconv.Atoi(web.Param(r, "number"))
+	if err != nil {
+		return v1.NewRequestError(err, http.StatusBadRequest)
+	}
+
+	idr, err := visible.Idea(ctx, h.idea, ideaID)
+	if err != nil {
+		return err
+	}
+
+	if err := h.checkCanEdit(ctx, idr); err != nil {
+		return err
+	}
+
+	rev, err := h.queryRevision(ctx, ideaID, number)
+	if err != nil {
+		return err
+	}
+
+	if err := h.checkOwnerFields(ctx, idr, &rev.Snapshot.Privacy, &rev.Snapshot.AvatarURL); err != nil {
+		return err
+	}
+
+	// The category may have left the taxonomy since the revision was made.
+	if rev.Snapshot.Category != idr.Category {
+		if err := h.checkCategory(ctx, rev.Snapshot.Category); err != nil {
+			return err
+		}
+	}
+
+	userID, err := auth.GetUserID(ctx)
+	if err != nil {
+		return auth.NewAuthError("restore: %s", err)
+	}
+
+	idr, err = h.idea.RestoreRevision(ctx, idr, number, userID)
+	if err != nil {
+		if errors.Is(err, idea.ErrRevisionNotFound) {
+			return v1.NewRequestError(idea.ErrRevisionNotFound, http.StatusNotFound)
+		}
+		return fmt.Errorf("restore: ideaID[%s] number[%d]: %w", ideaID, number, err)
+	}
+
+	return web.Respond(ctx, w, toAppIdea(idr), http.StatusOK)
+}
+
 // QueryByID retrieves an idea by its ID.
 func (h *Handlers) QueryByID(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
 	// Extract the idea_id parameter from the URL.
@@ -326,10 +793,10 @@ func (h *Handlers) QueryByID(ctx context.Context, w http.ResponseWriter, r *http
 		return v1.NewRequestError(fmt.Errorf("invalid idea ID: %w", err), http.StatusBadRequest)
 	}
 
-	// Retrieve the idea from the database.
-	idea, err := h.idea.QueryByID(ctx, id)
+	// Retrieve the idea from the database if the user is allowed to see it.
+	idea, err := visible.Idea(ctx, h.idea, id)
 	if err != nil {
-		return fmt.Errorf("query idea by ID: %w", err)
+		return err
 	}
 
 	// Convert the idea to the AppIdea type.
@@ -351,6 +818,21 @@ func (h *Handlers) Query(ctx context.Context, w http.ResponseWriter, r *http.Req
 		return err
 	}
 
+	// The user whose ideas are listed comes from the route when present.
+	if ownerID := web.Param(r, "user_id"); ownerID != "" {
+		id, err := uuid.Parse(ownerID)
+		if err != nil {
+			return v1.NewRequestError(fmt.Errorf("invalid user ID: %w", err), http.StatusBadRequest)
+		}
+		filter.WithUserID(id)
+	}
+
+	userID, err := auth.GetUserID(ctx)
+	if err != nil {
+		return auth.NewAuthError("query: %s", err)
+	}
+	filter.WithViewerID(userID)
+
 	orderBy, err := parseOrder(r)
 	if err != nil {
 		return err
@@ -376,11 +858,125 @@ func (h *Handlers) Query(ctx context.Context, w http.ResponseWriter, r *http.Req
 	return web.Respond(ctx, w, paging.NewResponse(items, total, page.Number, page.RowsPerPage), http.StatusOK)
 }
 
-func (h *Handlers) QueryTags(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
-	tags, err := h.idea.QueryTags(ctx)
+// queryHistoryIdea retrieves the idea if the authenticated user may see its
+// revision history, which is kept to the owner and the collaborators.
+func (h *Handlers) queryHistoryIdea(ctx context.Context, ideaID uuid.UUID) (idea.Idea, error) {
+	idr, err := visible.Idea(ctx, h.idea, ideaID)
 	if err != nil {
-		return fmt.Errorf("querying tags: %w", err)
+		return idea.Idea{}, err
+	}
+
+	userID, err := auth.GetUserID(ctx)
+	if err != nil {
+		return idea.Idea{}, auth.NewAuthError("history: ideaID[%s]: %s", ideaID, err)
+	}
+
+	if idr.UserID != userID && !idr.IsCollaborator(userID) {
+		return idea.Idea{}, v1.NewRequestError(ErrCannotView, http.StatusForbidden)
+	}
+
+	return idr, nil
+}
+
+// queryRevision retrieves a revision of the idea, reporting a missing one as
+// not found.
+func (h *Handlers) queryRevision(ctx context.Context, ideaID uuid.UUID, number int) (idea.Revision, error) {
+	rev, err := h.idea.QueryRevision(ctx, ideaID, number)
+	if err != nil {
+		if errors.Is(err, idea.ErrRevisionNotFound) {
+			return idea.Revision{}, v1.NewRequestError(idea.ErrRevisionNotFound, http.StatusNotFound)
+		}
+		return idea.Revision{}, fmt.Errorf("queryrevision: %w", err)
+	}
+
+	return rev, nil
+}
+
+// checkCanEdit verifies the authenticated user is the owner of the idea or a
+// collaborator who was invited as an editor.
+func (h *Handlers) checkCanEdit(ctx context.Context, idr idea.Idea) error {
+	userID, err := auth.GetUserID(ctx)
+	if err != nil {
+		return auth.NewAuthError("edit: ideaID[%s]: %s", idr.ID, err)
+	}
+
+	if idr.UserID == userID {
+		return nil
+	}
+
+	if !idr.IsCollaborator(userID) {
+		return v1.NewRequestError(ErrCannotEdit, http.StatusForbidden)
+	}
+
+	permission, err := h.invitation.QueryPermission(ctx, idr.ID, userID)
+	if err != nil {
+		if errors.Is(err, invitation.ErrNotFound) {
+			return v1.NewRequestError(ErrCannotEdit, http.StatusForbidden)
+		}
+		return fmt.Errorf("edit: ideaID[%s]: %w", idr.ID, err)
+	}
+
+	if permission != invitation.PermissionEditor {
+		return v1.NewRequestError(ErrCannotEdit, http.StatusForbidden)
+	}
+
+	return nil
+}
+
+// checkOwnerFields verifies that only the owner of the idea changes its
+// privacy or avatar. Editors can change the rest of the content, and sending
+// the current values back is allowed to them.
+func (h *Handlers) checkOwnerFields(ctx context.Context, idr idea.Idea, privacy *idea.Privacy, avatarURL *string) error {
+	userID, err := auth.GetUserID(ctx)
+	if err != nil {
+		return auth.NewAuthError("edit: ideaID[%s]: %s", idr.ID, err)
+	}
+
+	if idr.UserID == userID {
+		return nil
+	}
+
+	if privacy != nil && *privacy != idr.Privacy {
+		return v1.NewRequestError(ErrOwnerField, http.StatusForbidden)
+	}
+
+	if avatarURL != nil && *avatarURL != idr.AvatarURL {
+		return v1.NewRequestError(ErrOwnerField, http.StatusForbidden)
+	}
+
+	return nil
+}
+
+// checkAuthor reports whether the authenticated user can create ideas.
+func (h *Handlers) checkAuthor(ctx context.Context) error {
+	userID, err := auth.GetUserID(ctx)
+	if err != nil {
+		return auth.NewAuthError("checkauthor: %s", err)
+	}
+
+	if err := h.idea.CheckAuthor(ctx, userID); err != nil {
+		if errors.Is(err, idea.ErrAuthorGuard) {
+			return v1.NewRequestError(err, http.StatusForbidden)
+		}
+		return fmt.Errorf("checkauthor: userID[%s]: %w", userID, err)
+	}
+
+	return nil
+}
+
+// checkCategory makes sure the category is part of the taxonomy. An empty
+// category leaves the idea unfiled.
+func (h *Handlers) checkCategory(ctx context.Context, slug string) error {
+	if slug == "" {
+		return nil
+	}
+
+	if _, err := h.category.QueryBySlug(ctx, slug); err != nil {
+		if errors.Is(err, category.ErrNotFound) {
+			return validate.NewFieldsError("category", category.ErrNotFound)
+		}
+		return fmt.Errorf("checkcategory: slug[%s]: %w", slug, err)
 	}
 
-	return web.Respond(ctx, w, tags, http.StatusOK)
+	return nil
 }
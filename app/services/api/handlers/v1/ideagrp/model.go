@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/dmanias/startupers/business/core/fork"
 	"github.com/dmanias/startupers/business/core/idea"
 	"github.com/dmanias/startupers/business/sys/validate"
 	"github.com/google/uuid"
@@ -22,6 +23,8 @@ type AppIdea struct {
 	AvatarURL     string   `json:"avatarURL"`
 	Stage         string   `json:"stage"`
 	Inspiration   string   `json:"inspiration"`
+	ForkedFrom    string   `json:"forkedFrom,omitempty"`
+	VoteCount     int      `json:"voteCount"`
 	DateCreated   string   `json:"dateCreated"`
 	DateUpdated   string   `json:"dateUpdated"`
 }
@@ -32,6 +35,11 @@ func toAppIdea(idea idea.Idea) AppIdea {
 		collaborators[i] = collaborator.String()
 	}
 
+	var forkedFrom string
+	if idea.ForkedFrom != uuid.Nil {
+		forkedFrom = idea.ForkedFrom.String()
+	}
+
 	return AppIdea{
 		ID:            idea.ID.String(),
 		UserID:        idea.UserID.String(),
@@ -39,11 +47,13 @@ func toAppIdea(idea idea.Idea) AppIdea {
 		Description:   idea.Description,
 		Category:      idea.Category,
 		Tags:          idea.Tags,
-		Privacy:       idea.Privacy,
+		Privacy:       idea.Privacy.Name(),
 		Collaborators: collaborators,
 		AvatarURL:     idea.AvatarURL,
-		Stage:         idea.Stage,
+		Stage:         idea.Stage.Name(),
 		Inspiration:   idea.Inspiration,
+		ForkedFrom:    forkedFrom,
+		VoteCount:     idea.VoteCount,
 		DateCreated:   idea.DateCreated.Format(time.RFC3339),
 		DateUpdated:   idea.DateUpdated.Format(time.RFC3339),
 	}
@@ -51,16 +61,14 @@ func toAppIdea(idea idea.Idea) AppIdea {
 
 // AppNewIdea contains information needed to create a new idea.
 type AppNewIdea struct {
-	UserID        string   `json:"userID" validate:"required"`
-	Title         string   `json:"title" validate:"required"`
-	Description   string   `json:"description"`
-	Category      string   `json:"category"`
-	Tags          []string `json:"tags"`
-	Privacy       string   `json:"privacy"`
-	Collaborators []string `json:"collaborators"`
-	AvatarURL     string   `json:"avatarURL"`
-	Stage         string   `json:"stage"`
-	Inspiration   string   `json:"inspiration"`
+	UserID      string   `json:"userID" validate:"required"`
+	Title       string   `json:"title" validate:"required"`
+	Description string   `json:"description"`
+	Category    string   `json:"category"`
+	Tags        []string `json:"tags"`
+	Privacy     string   `json:"privacy" validate:"omitempty,oneof=private collaborators unlisted public"`
+	AvatarURL   string   `json:"avatarURL"`
+	Inspiration string   `json:"inspiration"`
 }
 
 func toCoreNewIdea(app AppNewIdea) (idea.NewIdea, error) {
@@ -69,26 +77,23 @@ func toCoreNewIdea(app AppNewIdea) (idea.NewIdea, error) {
 		return idea.NewIdea{}, fmt.Errorf("parsing userID: %w", err)
 	}
 
-	collaborators := make([]uuid.UUID, len(app.Collaborators))
-	for i, collaboratorStr := range app.Collaborators {
-		collaborator, err := uuid.Parse(collaboratorStr)
+	privacy := idea.PrivacyPrivate
+	if app.Privacy != "" {
+		privacy, err = idea.ParsePrivacy(app.Privacy)
 		if err != nil {
-			return idea.NewIdea{}, fmt.Errorf("parsing collaborator: %w", err)
+			return idea.NewIdea{}, fmt.Errorf("parsing privacy: %w", err)
 		}
-		collaborators[i] = collaborator
 	}
 
 	ni := idea.NewIdea{
-		UserID:        userID,
-		Title:         app.Title,
-		Description:   app.Description,
-		Category:      app.Category,
-		Tags:          app.Tags,
-		Privacy:       app.Privacy,
-		Collaborators: collaborators,
-		AvatarURL:     app.AvatarURL,
-		Stage:         app.Stage,
-		Inspiration:   app.Inspiration,
+		UserID:      userID,
+		Title:       app.Title,
+		Description: app.Description,
+		Category:    app.Category,
+		Tags:        app.Tags,
+		Privacy:     privacy,
+		AvatarURL:   app.AvatarURL,
+		Inspiration: app.Inspiration,
 	}
 
 	return ni, nil
@@ -104,16 +109,14 @@ func (app AppNewIdea) Validate() error {
 
 // AppUpdateIdea contains information needed to update an idea.
 type AppUpdateIdea struct {
-	ID            *string  `json:"id"`
-	Title         *string  `json:"title"`
-	Description   *string  `json:"description"`
-	Category      *string  `json:"category"`
-	Tags          []string `json:"tags"`
-	Privacy       *string  `json:"privacy"`
-	Collaborators []string `json:"collaborators"`
-	AvatarURL     *string  `json:"avatarURL"`
-	Stage         *string  `json:"stage"`
-	Inspiration   *string  `json:"inspiration"`
+	ID          *string  `json:"id"`
+	Title       *string  `json:"title"`
+	Description *string  `json:"description"`
+	Category    *string  `json:"category"`
+	Tags        []string `json:"tags"`
+	Privacy     *string  `json:"privacy" validate:"omitempty,oneof=private collaborators unlisted public"`
+	AvatarURL   *string  `json:"avatarURL"`
+	Inspiration *string  `json:"inspiration"`
 }
 
 func toCoreUpdateIdea(app AppUpdateIdea) (idea.UpdateIdea, error) {
@@ -125,29 +128,24 @@ func toCoreUpdateIdea(app AppUpdateIdea) (idea.UpdateIdea, error) {
 			return idea.UpdateIdea{}, fmt.Errorf("parsing ID: %w", err)
 		}
 	}
-	var collaborators []uuid.UUID
-	if app.Collaborators != nil {
-		collaborators = make([]uuid.UUID, len(app.Collaborators))
-		for i, collaboratorStr := range app.Collaborators {
-			collaborator, err := uuid.Parse(collaboratorStr)
-			if err != nil {
-				return idea.UpdateIdea{}, fmt.Errorf("parsing collaborator: %w", err)
-			}
-			collaborators[i] = collaborator
+	var privacy *idea.Privacy
+	if app.Privacy != nil {
+		p, err := idea.ParsePrivacy(*app.Privacy)
+		if err != nil {
+			return idea.UpdateIdea{}, fmt.Errorf("parsing privacy: %w", err)
 		}
+		privacy = &p
 	}
 
 	ui := idea.UpdateIdea{
-		ID:            &id,
-		Title:         app.Title,
-		Description:   app.Description,
-		Category:      app.Category,
-		Tags:          app.Tags,
-		Privacy:       app.Privacy,
-		Collaborators: collaborators,
-		AvatarURL:     app.AvatarURL,
-		Stage:         app.Stage,
-		Inspiration:   app.Inspiration,
+		ID:          &id,
+		Title:       app.Title,
+		Description: app.Description,
+		Category:    app.Category,
+		Tags:        app.Tags,
+		Privacy:     privacy,
+		AvatarURL:   app.AvatarURL,
+		Inspiration: app.Inspiration,
 	}
 
 	return ui, nil
@@ -160,3 +158,223 @@ func (app AppUpdateIdea) Validate() error {
 	}
 	return nil
 }
+
+// =============================================================================
+
+// AppStageTransition contains information needed to move an idea to another
+// stage.
+type AppStageTransition struct {
+	Stage string `json:"stage" validate:"required,oneof=spark validation prototype launch archived"`
+	Note  string `json:"note" validate:"max=500"`
+}
+
+// Validate checks the data in the model is considered clean.
+func (app AppStageTransition) Validate() error {
+	if err := validate.Check(app); err != nil {
+		return err
+	}
+	return nil
+}
+
+// AppVote represents a user's vote on an idea.
+type AppVote struct {
+	UserID      string `json:"userID"`
+	DateCreated string `json:"dateCreated"`
+}
+
+func toAppVotes(votes []idea.Vote) []AppVote {
+	items := make([]AppVote, len(votes))
+	for i, vote := range votes {
+		items[i] = AppVote{
+			UserID:      vote.UserID.String(),
+			DateCreated: vote.DateCreated.Format(time.RFC3339),
+		}
+	}
+	return items
+}
+
+// AppStageHistory represents an idea being moved from one stage to another.
+type AppStageHistory struct {
+	ID          string `json:"id"`
+	IdeaID      string `json:"ideaID"`
+	UserID      string `json:"userID"`
+	FromStage   string `json:"fromStage"`
+	ToStage     string `json:"toStage"`
+	Note        string `json:"note"`
+	DateCreated string `json:"dateCreated"`
+}
+
+func toAppStageHistory(sts []idea.StageTransition) []AppStageHistory {
+	items := make([]AppStageHistory, len(sts))
+	for i, st := range sts {
+		items[i] = AppStageHistory{
+			ID:          st.ID.String(),
+			IdeaID:      st.IdeaID.String(),
+			UserID:      st.UserID.String(),
+			FromStage:   st.FromStage.Name(),
+			ToStage:     st.ToStage.Name(),
+			Note:        st.Note,
+			DateCreated: st.DateCreated.Format(time.RFC3339),
+		}
+	}
+	return items
+}
+
+// =============================================================================
+
+// AppSnapshot represents the versioned content of an idea.
+type AppSnapshot struct {
+	Title       string   `json:"title"`
+	Description string   `json:"description"`
+	Category    string   `json:"category"`
+	Tags        []string `json:"tags"`
+	Privacy     string   `json:"privacy"`
+	AvatarURL   string   `json:"avatarURL"`
+	Inspiration string   `json:"inspiration"`
+}
+
+// AppRevision represents a revision of an idea.
+type AppRevision struct {
+	ID            string      `json:"id"`
+	IdeaID        string      `json:"ideaID"`
+	Number        int         `json:"number"`
+	UserID        string      `json:"userID"`
+	ChangedFields []string    `json:"changedFields"`
+	Snapshot      AppSnapshot `json:"snapshot"`
+	RestoredFrom  int         `json:"restoredFrom,omitempty"`
+	DateCreated   string      `json:"dateCreated"`
+}
+
+func toAppRevision(rev idea.Revision) AppRevision {
+	changedFields := rev.ChangedFields
+	if changedFields == nil {
+		changedFields = []string{}
+	}
+
+	return AppRevision{
+		ID:            rev.ID.String(),
+		IdeaID:        rev.IdeaID.String(),
+		Number:        rev.Number,
+		UserID:        rev.UserID.String(),
+		ChangedFields: changedFields,
+		Snapshot: AppSnapshot{
+			Title:       rev.Snapshot.Title,
+			Description: rev.Snapshot.Description,
+			Category:    rev.Snapshot.Category,
+			Tags:        rev.Snapshot.Tags,
+			Privacy:     rev.Snapshot.Privacy.Name(),
+			AvatarURL:   rev.Snapshot.AvatarURL,
+			Inspiration: rev.Snapshot.Inspiration,
+		},
+		RestoredFrom: rev.RestoredFrom,
+		DateCreated:  rev.DateCreated.Format(time.RFC3339),
+	}
+}
+
+// AppFieldChange represents how a field differs between two revisions.
+type AppFieldChange struct {
+	Field string `json:"field"`
+	From  any    `json:"from"`
+	To    any    `json:"to"`
+}
+
+// AppRevisionDiff represents the field by field difference between two
+// revisions of an idea.
+type AppRevisionDiff struct {
+	From    int              `json:"from"`
+	To      int              `json:"to"`
+	Changes []AppFieldChange `json:"changes"`
+}
+
+func toAppRevisionDiff(from idea.Revision, to idea.Revision) AppRevisionDiff {
+	changes := idea.Diff(from.Snapshot, to.Snapshot)
+
+	items := make([]AppFieldChange, len(changes))
+	for i, change := range changes {
+		items[i] = AppFieldChange{
+			Field: change.Field,
+			From:  change.From,
+			To:    change.To,
+		}
+	}
+
+	return AppRevisionDiff{
+		From:    from.Number,
+		To:      to.Number,
+		Changes: items,
+	}
+}
+
+// =============================================================================
+
+// AppNewFork contains information needed to fork an idea.
+type AppNewFork struct {
+	Title          *string `json:"title" validate:"omitempty,min=1,max=255"`
+	CopyPosts      bool    `json:"copyPosts"`
+	CopyChallenges bool    `json:"copyChallenges"`
+}
+
+func toCoreNewFork(app AppNewFork, userID uuid.UUID) fork.NewFork {
+	return fork.NewFork{
+		UserID:         userID,
+		Title:          app.Title,
+		CopyPosts:      app.CopyPosts,
+		CopyChallenges: app.CopyChallenges,
+	}
+}
+
+// Validate checks the data in the model is considered clean.
+func (app AppNewFork) Validate() error {
+	if err := validate.Check(app); err != nil {
+		return err
+	}
+	return nil
+}
+
+// AppLineageNode represents an idea in a fork tree. Ideas the user isn't
+// allowed to see are kept in the tree to preserve its shape but carry no
+// details.
+type AppLineageNode struct {
+	ID     string           `json:"id,omitempty"`
+	UserID string           `json:"userID,omitempty"`
+	Title  string           `json:"title,omitempty"`
+	Hidden bool             `json:"hidden,omitempty"`
+	Forks  []AppLineageNode `json:"forks"`
+}
+
+// toAppLineage builds the fork tree from the ideas returned by
+// idea.Core.QueryLineage, as seen by the viewer.
+func toAppLineage(ideas []idea.Idea, viewerID uuid.UUID) AppLineageNode {
+	children := make(map[uuid.UUID][]idea.Idea)
+	var root idea.Idea
+	for _, idr := range ideas {
+		if idr.ForkedFrom == uuid.Nil {
+			root = idr
+			continue
+		}
+		children[idr.ForkedFrom] = append(children[idr.ForkedFrom], idr)
+	}
+
+	var build func(idr idea.Idea) AppLineageNode
+	build = func(idr idea.Idea) AppLineageNode {
+		node := AppLineageNode{
+			Forks: make([]AppLineageNode, 0, len(children[idr.ID])),
+		}
+
+		if idr.ListedFor(viewerID) {
+			node.ID = idr.ID.String()
+			node.UserID = idr.UserID.String()
+			node.Title = idr.Title
+		} else {
+			node.Hidden = true
+		}
+
+		for _, child := range children[idr.ID] {
+			node.Forks = append(node.Forks, build(child))
+		}
+
+		return node
+	}
+
+	return build(root)
+}
@@ -18,6 +18,7 @@ var orderByFields = map[string]struct{}{
 	idea.OrderByStage:       {},
 	idea.OrderByDateCreated: {},
 	idea.OrderByDateUpdated: {},
+	idea.OrderByVotes:       {},
 }
 
 func parseOrder(r *http.Request) (order.By, error) {
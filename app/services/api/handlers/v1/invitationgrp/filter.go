@@ -0,0 +1,27 @@
+package invitationgrp
+
+import (
+	"net/http"
+
+	"github.com/dmanias/startupers/business/core/invitation"
+	"github.com/dmanias/startupers/business/sys/validate"
+)
+
+func parseFilter(r *http.Request) (invitation.QueryFilter, error) {
+	values := r.URL.Query()
+	var filter invitation.QueryFilter
+
+	if status := values.Get("status"); status != "" {
+		st, err := invitation.ParseStatus(status)
+		if err != nil {
+			return invitation.QueryFilter{}, validate.NewFieldsError("status", err)
+		}
+		filter.WithStatus(st)
+	}
+
+	if err := filter.Validate(); err != nil {
+		return invitation.QueryFilter{}, err
+	}
+
+	return filter, nil
+}
@@ -0,0 +1,304 @@
+// Package invitationgrp maintains the group of handlers for idea collaborator
+// invitations.
+package invitationgrp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/dmanias/startupers/business/core/idea"
+	"github.com/dmanias/startupers/business/core/invitation"
+	"github.com/dmanias/startupers/business/core/user"
+	"github.com/dmanias/startupers/business/web/auth"
+	v1 "github.com/dmanias/startupers/business/web/v1"
+	"github.com/dmanias/startupers/business/web/v1/paging"
+	"github.com/dmanias/startupers/business/web/v1/visible"
+	"github.com/dmanias/startupers/foundation/web"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// ErrNotOwner is returned when someone other than the owner of the idea
+// manages its invitations.
+var ErrNotOwner = errors.New("only the owner of the idea can manage its invitations")
+
+// Handlers manages the set of invitation endpoints.
+type Handlers struct {
+	invitation *invitation.Core
+	idea       *idea.Core
+	user       *user.Core
+	log        *zap.SugaredLogger
+}
+
+// New constructs a handlers for route access.
+func New(invitation *invitation.Core, idea *idea.Core, user *user.Core, log *zap.SugaredLogger) *Handlers {
+	return &Handlers{
+		invitation: invitation,
+		idea:       idea,
+		user:       user,
+		log:        log,
+	}
+}
+
+// Create invites a user to collaborate on the idea.
+func (h *Handlers) Create(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	idr, err := h.queryOwnedIdea(ctx, r)
+	if err != nil {
+		return err
+	}
+
+	var app AppNewInvitation
+	if err := web.Decode(r, &app); err != nil {
+		return err
+	}
+
+	ni, err := toCoreNewInvitation(app, idr.UserID)
+	if err != nil {
+		return v1.NewRequestError(err, http.StatusBadRequest)
+	}
+
+	inv, err := h.invitation.Create(ctx, idr, ni)
+	if err != nil {
+		if errors.Is(err, user.ErrNotFound) {
+			return v1.NewRequestError(user.ErrNotFound, http.StatusBadRequest)
+		}
+		return toRequestError(err)
+	}
+
+	return web.Respond(ctx, w, toAppInvitation(inv), http.StatusCreated)
+}
+
+// QueryByIdea returns the invitations sent for the idea with paging.
+func (h *Handlers) QueryByIdea(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	idr, err := h.queryOwnedIdea(ctx, r)
+	if err != nil {
+		return err
+	}
+
+	page, err := paging.ParseRequest(r)
+	if err != nil {
+		return err
+	}
+
+	filter, err := parseFilter(r)
+	if err != nil {
+		return err
+	}
+	filter.WithIdeaID(idr.ID)
+
+	return h.query(ctx, w, r, filter, page)
+}
+
+// QueryIncoming returns the pending invitations addressed to the
+// authenticated user that can still be answered.
+func (h *Handlers) QueryIncoming(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	usr, err := h.queryUser(ctx)
+	if err != nil {
+		return err
+	}
+
+	page, err := paging.ParseRequest(r)
+	if err != nil {
+		return err
+	}
+
+	var filter invitation.QueryFilter
+	filter.WithRecipient(usr.ID, usr.Email)
+	filter.WithStatus(invitation.StatusPending)
+	filter.WithExpiresAfter(time.Now())
+
+	return h.query(ctx, w, r, filter, page)
+}
+
+// Accept accepts the invitation addressed to the authenticated user.
+func (h *Handlers) Accept(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	usr, err := h.queryUser(ctx)
+	if err != nil {
+		return err
+	}
+
+	inv, err := h.queryInvitation(ctx, r)
+	if err != nil {
+		return err
+	}
+
+	inv, err = h.invitation.Accept(ctx, inv, usr)
+	if err != nil {
+		return toRequestError(err)
+	}
+
+	return web.Respond(ctx, w, toAppInvitation(inv), http.StatusOK)
+}
+
+// AcceptToken accepts the invitation identified by the token sent to the
+// invitee.
+func (h *Handlers) AcceptToken(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	usr, err := h.queryUser(ctx)
+	if err != nil {
+		return err
+	}
+
+	var app AppAcceptToken
+	if err := web.Decode(r, &app); err != nil {
+		return err
+	}
+
+	inv, err := h.invitation.AcceptToken(ctx, app.Token, usr)
+	if err != nil {
+		return toRequestError(err)
+	}
+
+	return web.Respond(ctx, w, toAppInvitation(inv), http.StatusOK)
+}
+
+// Decline turns down the invitation addressed to the authenticated user.
+func (h *Handlers) Decline(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	usr, err := h.queryUser(ctx)
+	if err != nil {
+		return err
+	}
+
+	inv, err := h.queryInvitation(ctx, r)
+	if err != nil {
+		return err
+	}
+
+	inv, err = h.invitation.Decline(ctx, inv, usr)
+	if err != nil {
+		return toRequestError(err)
+	}
+
+	return web.Respond(ctx, w, toAppInvitation(inv), http.StatusOK)
+}
+
+// Revoke withdraws an invitation. Only the owner of the idea can revoke.
+func (h *Handlers) Revoke(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	userID, err := auth.GetUserID(ctx)
+	if err != nil {
+		return auth.NewAuthError("revoke: %s", err)
+	}
+
+	inv, err := h.queryInvitation(ctx, r)
+	if err != nil {
+		return err
+	}
+
+	idr, err := visible.Idea(ctx, h.idea, inv.IdeaID)
+	if err != nil {
+		return err
+	}
+
+	if idr.UserID != userID {
+		return v1.NewRequestError(ErrNotOwner, http.StatusForbidden)
+	}
+
+	if _, err := h.invitation.Revoke(ctx, inv); err != nil {
+		return toRequestError(err)
+	}
+
+	return web.Respond(ctx, w, nil, http.StatusNoContent)
+}
+
+// =============================================================================
+
+func (h *Handlers) query(ctx context.Context, w http.ResponseWriter, r *http.Request, filter invitation.QueryFilter, page paging.Page) error {
+	orderBy, err := parseOrder(r)
+	if err != nil {
+		return err
+	}
+
+	invs, err := h.invitation.Query(ctx, filter, orderBy, page.Number, page.RowsPerPage)
+	if err != nil {
+		return fmt.Errorf("query: %w", err)
+	}
+
+	total, err := h.invitation.Count(ctx, filter)
+	if err != nil {
+		return fmt.Errorf("count: %w", err)
+	}
+
+	return web.Respond(ctx, w, paging.NewResponse(toAppInvitations(invs), total, page.Number, page.RowsPerPage), http.StatusOK)
+}
+
+// queryOwnedIdea retrieves the idea named in the route and checks the
+// authenticated user owns it. Ideas the user can't see are reported as not
+// found.
+func (h *Handlers) queryOwnedIdea(ctx context.Context, r *http.Request) (idea.Idea, error) {
+	ideaID, err := uuid.Parse(web.Param(r, "idea_id"))
+	if err != nil {
+		return idea.Idea{}, v1.NewRequestError(err, http.StatusBadRequest)
+	}
+
+	userID, err := auth.GetUserID(ctx)
+	if err != nil {
+		return idea.Idea{}, auth.NewAuthError("query: ideaID[%s]: %s", ideaID, err)
+	}
+
+	idr, err := visible.Idea(ctx, h.idea, ideaID)
+	if err != nil {
+		return idea.Idea{}, err
+	}
+
+	if idr.UserID != userID {
+		return idea.Idea{}, v1.NewRequestError(ErrNotOwner, http.StatusForbidden)
+	}
+
+	return idr, nil
+}
+
+// queryInvitation retrieves the invitation named in the route.
+func (h *Handlers) queryInvitation(ctx context.Context, r *http.Request) (invitation.Invitation, error) {
+	invitationID, err := uuid.Parse(web.Param(r, "invitation_id"))
+	if err != nil {
+		return invitation.Invitation{}, v1.NewRequestError(err, http.StatusBadRequest)
+	}
+
+	inv, err := h.invitation.QueryByID(ctx, invitationID)
+	if err != nil {
+		return invitation.Invitation{}, toRequestError(err)
+	}
+
+	return inv, nil
+}
+
+// queryUser retrieves the authenticated user.
+func (h *Handlers) queryUser(ctx context.Context) (user.User, error) {
+	userID, err := auth.GetUserID(ctx)
+	if err != nil {
+		return user.User{}, auth.NewAuthError("query: %s", err)
+	}
+
+	usr, err := h.user.QueryByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, user.ErrNotFound) {
+			return user.User{}, auth.NewAuthError("query: userID[%s]: %s", userID, err)
+		}
+		return user.User{}, fmt.Errorf("query: userID[%s]: %w", userID, err)
+	}
+
+	return usr, nil
+}
+
+// toRequestError maps the invitation errors to the response status they are
+// reported with.
+func toRequestError(err error) error {
+	switch {
+	case errors.Is(err, invitation.ErrNotFound):
+		return v1.NewRequestError(invitation.ErrNotFound, http.StatusNotFound)
+	case errors.Is(err, invitation.ErrSelfInvite):
+		return v1.NewRequestError(invitation.ErrSelfInvite, http.StatusBadRequest)
+	case errors.Is(err, invitation.ErrDuplicate):
+		return v1.NewRequestError(invitation.ErrDuplicate, http.StatusConflict)
+	case errors.Is(err, invitation.ErrAlreadyCollaborator):
+		return v1.NewRequestError(invitation.ErrAlreadyCollaborator, http.StatusConflict)
+	case errors.Is(err, invitation.ErrNotPending):
+		return v1.NewRequestError(invitation.ErrNotPending, http.StatusConflict)
+	case errors.Is(err, invitation.ErrExpired):
+		return v1.NewRequestError(invitation.ErrExpired, http.StatusGone)
+	}
+
+	return err
+}
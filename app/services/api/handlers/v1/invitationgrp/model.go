@@ -0,0 +1,132 @@
+package invitationgrp
+
+import (
+	"errors"
+	"fmt"
+	"net/mail"
+	"time"
+
+	"github.com/dmanias/startupers/business/core/invitation"
+	"github.com/dmanias/startupers/business/sys/validate"
+	"github.com/google/uuid"
+)
+
+// statusExpired is reported for pending invitations that can no longer be
+// answered. It is never stored.
+const statusExpired = "expired"
+
+type AppInvitation struct {
+	ID          string `json:"id"`
+	IdeaID      string `json:"ideaID"`
+	InviterID   string `json:"inviterID"`
+	InviteeID   string `json:"inviteeID,omitempty"`
+	Email       string `json:"email,omitempty"`
+	Permission  string `json:"permission"`
+	Status      string `json:"status"`
+	DateExpires string `json:"dateExpires"`
+	DateCreated string `json:"dateCreated"`
+	DateUpdated string `json:"dateUpdated"`
+}
+
+func toAppInvitation(inv invitation.Invitation) AppInvitation {
+	status := inv.Status.Name()
+	if inv.Expired(time.Now()) {
+		status = statusExpired
+	}
+
+	var inviteeID string
+	if inv.InviteeID != uuid.Nil {
+		inviteeID = inv.InviteeID.String()
+	}
+
+	return AppInvitation{
+		ID:          inv.ID.String(),
+		IdeaID:      inv.IdeaID.String(),
+		InviterID:   inv.InviterID.String(),
+		InviteeID:   inviteeID,
+		Email:       inv.Email.Address,
+		Permission:  inv.Permission.Name(),
+		Status:      status,
+		DateExpires: inv.DateExpires.Format(time.RFC3339),
+		DateCreated: inv.DateCreated.Format(time.RFC3339),
+		DateUpdated: inv.DateUpdated.Format(time.RFC3339),
+	}
+}
+
+func toAppInvitations(invs []invitation.Invitation) []AppInvitation {
+	items := make([]AppInvitation, len(invs))
+	for i, inv := range invs {
+		items[i] = toAppInvitation(inv)
+	}
+
+	return items
+}
+
+// =============================================================================
+
+type AppNewInvitation struct {
+	UserID     string `json:"userID" validate:"required_without=Email,omitempty,uuid"`
+	Email      string `json:"email" validate:"required_without=UserID,omitempty,email"`
+	Permission string `json:"permission" validate:"required,oneof=viewer editor"`
+}
+
+func toCoreNewInvitation(app AppNewInvitation, inviterID uuid.UUID) (invitation.NewInvitation, error) {
+	permission, err := invitation.ParsePermission(app.Permission)
+	if err != nil {
+		return invitation.NewInvitation{}, fmt.Errorf("parsing permission: %w", err)
+	}
+
+	ni := invitation.NewInvitation{
+		InviterID:  inviterID,
+		Permission: permission,
+	}
+
+	switch {
+	case app.UserID != "":
+		id, err := uuid.Parse(app.UserID)
+		if err != nil {
+			return invitation.NewInvitation{}, fmt.Errorf("parsing userID: %w", err)
+		}
+		ni.InviteeID = &id
+
+	case app.Email != "":
+		addr, err := mail.ParseAddress(app.Email)
+		if err != nil {
+			return invitation.NewInvitation{}, fmt.Errorf("parsing email: %w", err)
+		}
+		ni.Email = addr
+
+	default:
+		return invitation.NewInvitation{}, errors.New("userID or email is required")
+	}
+
+	return ni, nil
+}
+
+// Validate checks the data in the model is considered clean.
+func (app AppNewInvitation) Validate() error {
+	if err := validate.Check(app); err != nil {
+		return err
+	}
+
+	if app.UserID != "" && app.Email != "" {
+		return validate.NewFieldsError("email", errors.New("only one of userID or email can be set"))
+	}
+
+	return nil
+}
+
+// =============================================================================
+
+// AppAcceptToken carries the token sent to the invitee.
+type AppAcceptToken struct {
+	Token string `json:"token" validate:"required"`
+}
+
+// Validate checks the data in the model is considered clean.
+func (app AppAcceptToken) Validate() error {
+	if err := validate.Check(app); err != nil {
+		return err
+	}
+	return nil
+}
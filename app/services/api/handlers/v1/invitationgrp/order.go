@@ -0,0 +1,31 @@
+package invitationgrp
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/dmanias/startupers/business/core/invitation"
+	"github.com/dmanias/startupers/business/data/order"
+	"github.com/dmanias/startupers/business/sys/validate"
+)
+
+var orderByFields = map[string]struct{}{
+	invitation.OrderByID:          {},
+	invitation.OrderByIdeaID:      {},
+	invitation.OrderByStatus:      {},
+	invitation.OrderByDateExpires: {},
+	invitation.OrderByDateCreated: {},
+}
+
+func parseOrder(r *http.Request) (order.By, error) {
+	orderBy, err := order.Parse(r, invitation.DefaultOrderBy)
+	if err != nil {
+		return order.By{}, err
+	}
+
+	if _, exists := orderByFields[orderBy.Field]; !exists {
+		return order.By{}, validate.NewFieldsError(orderBy.Field, errors.New("order field does not exist"))
+	}
+
+	return orderBy, nil
+}
@@ -0,0 +1,109 @@
+package notificationgrp
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/dmanias/startupers/business/core/notification"
+	"github.com/dmanias/startupers/business/core/user"
+	"github.com/dmanias/startupers/business/sys/validate"
+	"github.com/google/uuid"
+)
+
+// AppNotification represents a notification of the authenticated user.
+type AppNotification struct {
+	ID          string            `json:"id"`
+	Type        string            `json:"type"`
+	ActorID     string            `json:"actorID"`
+	IdeaID      string            `json:"ideaID"`
+	SubjectID   string            `json:"subjectID,omitempty"`
+	Details     map[string]string `json:"details,omitempty"`
+	Read        bool              `json:"read"`
+	ReadAt      string            `json:"readAt,omitempty"`
+	DateCreated string            `json:"dateCreated"`
+}
+
+func toAppNotification(n notification.Notification) AppNotification {
+	var subjectID string
+	if n.SubjectID != uuid.Nil {
+		subjectID = n.SubjectID.String()
+	}
+
+	var readAt string
+	if n.Read() {
+		readAt = n.ReadAt.Format(time.RFC3339)
+	}
+
+	return AppNotification{
+		ID:          n.ID.String(),
+		Type:        n.Type.Name(),
+		ActorID:     n.ActorID.String(),
+		IdeaID:      n.IdeaID.String(),
+		SubjectID:   subjectID,
+		Details:     n.Details,
+		Read:        n.Read(),
+		ReadAt:      readAt,
+		DateCreated: n.DateCreated.Format(time.RFC3339),
+	}
+}
+
+func toAppNotifications(ns []notification.Notification) []AppNotification {
+	items := make([]AppNotification, len(ns))
+	for i, n := range ns {
+		items[i] = toAppNotification(n)
+	}
+	return items
+}
+
+// AppUnread represents the number of notifications the user hasn't read.
+type AppUnread struct {
+	Count int `json:"count"`
+}
+
+// AppMarked represents the number of notifications that were marked as read.
+type AppMarked struct {
+	Count int `json:"count"`
+}
+
+// AppPreferences represents which notifications the user wants, by
+// notification type and then by channel.
+type AppPreferences map[string]map[string]bool
+
+// toAppPreferences lists every known type and channel, with what the user
+// left unset shown as wanted.
+func toAppPreferences(np user.NotificationPreferences) AppPreferences {
+	prefs := make(AppPreferences)
+	for _, typ := range notification.Types() {
+		prefs[typ.Name()] = make(map[string]bool)
+		for _, channel := range notification.Channels() {
+			prefs[typ.Name()][channel] = np.Enabled(typ.Name(), channel)
+		}
+	}
+	return prefs
+}
+
+func toCorePreferences(app AppPreferences) user.NotificationPreferences {
+	prefs := make(user.NotificationPreferences, len(app))
+	for typ, channels := range app {
+		prefs[typ] = make(map[string]bool, len(channels))
+		for channel, enabled := range channels {
+			prefs[typ][channel] = enabled
+		}
+	}
+	return prefs
+}
+
+// Validate checks the preferences only name known types and channels.
+func (app AppPreferences) Validate() error {
+	for typ, channels := range app {
+		if _, err := notification.ParseType(typ); err != nil {
+			return validate.NewFieldsError(typ, err)
+		}
+		for channel := range channels {
+			if !notification.KnownChannel(channel) {
+				return validate.NewFieldsError(typ+"."+channel, fmt.Errorf("invalid channel %q", channel))
+			}
+		}
+	}
+	return nil
+}
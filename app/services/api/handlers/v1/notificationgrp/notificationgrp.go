@@ -0,0 +1,191 @@
+// Package notificationgrp maintains the group of handlers for the
+// notifications of the authenticated user.
+package notificationgrp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/dmanias/startupers/business/core/notification"
+	"github.com/dmanias/startupers/business/core/user"
+	"github.com/dmanias/startupers/business/sys/validate"
+	"github.com/dmanias/startupers/business/web/auth"
+	v1 "github.com/dmanias/startupers/business/web/v1"
+	"github.com/dmanias/startupers/business/web/v1/paging"
+	"github.com/dmanias/startupers/foundation/web"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// Handlers manages the set of notification endpoints.
+type Handlers struct {
+	notification *notification.Core
+	user         *user.Core
+	log          *zap.SugaredLogger
+}
+
+// New constructs a handlers for route access.
+func New(notification *notification.Core, user *user.Core, log *zap.SugaredLogger) *Handlers {
+	return &Handlers{
+		notification: notification,
+		user:         user,
+		log:          log,
+	}
+}
+
+// Query returns the notifications of the authenticated user, newest first,
+// with paging. Passing unread=true or unread=false lists only the unread or
+// the read ones.
+func (h *Handlers) Query(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	page, err := paging.ParseRequest(r)
+	if err != nil {
+		return err
+	}
+
+	userID, err := auth.GetUserID(ctx)
+	if err != nil {
+		return auth.NewAuthError("query: %s", err)
+	}
+
+	var filter notification.QueryFilter
+	filter.WithUserID(userID)
+
+	switch unread := r.URL.Query().Get("unread"); unread {
+	case "":
+	case "true":
+		filter.WithUnread(true)
+	case "false":
+		filter.WithUnread(false)
+	default:
+		return validate.NewFieldsError("unread", fmt.Errorf("invalid value %q", unread))
+	}
+
+	ns, err := h.notification.Query(ctx, filter, notification.DefaultOrderBy, page.Number, page.RowsPerPage)
+	if err != nil {
+		return fmt.Errorf("query: %w", err)
+	}
+
+	total, err := h.notification.Count(ctx, filter)
+	if err != nil {
+		return fmt.Errorf("count: %w", err)
+	}
+
+	return web.Respond(ctx, w, paging.NewResponse(toAppNotifications(ns), total, page.Number, page.RowsPerPage), http.StatusOK)
+}
+
+// CountUnread returns the number of notifications the authenticated user
+// hasn't read.
+func (h *Handlers) CountUnread(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	userID, err := auth.GetUserID(ctx)
+	if err != nil {
+		return auth.NewAuthError("countunread: %s", err)
+	}
+
+	count, err := h.notification.CountUnread(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("countunread: %w", err)
+	}
+
+	return web.Respond(ctx, w, AppUnread{Count: count}, http.StatusOK)
+}
+
+// MarkRead marks a notification of the authenticated user as read.
+// Notifications of other users are reported as not found.
+func (h *Handlers) MarkRead(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	notificationID, err := uuid.Parse(web.Param(r, "notification_id"))
+	if err != nil {
+		return v1.NewRequestError(err, http.StatusBadRequest)
+	}
+
+	userID, err := auth.GetUserID(ctx)
+	if err != nil {
+		return auth.NewAuthError("markread: %s", err)
+	}
+
+	n, err := h.notification.QueryByID(ctx, notificationID)
+	if err != nil {
+		if errors.Is(err, notification.ErrNotFound) {
+			return v1.NewRequestError(notification.ErrNotFound, http.StatusNotFound)
+		}
+		return fmt.Errorf("query: notificationID[%s]: %w", notificationID, err)
+	}
+
+	if n.UserID != userID {
+		return v1.NewRequestError(notification.ErrNotFound, http.StatusNotFound)
+	}
+
+	n, err = h.notification.MarkRead(ctx, n)
+	if err != nil {
+		return fmt.Errorf("markread: %w", err)
+	}
+
+	return web.Respond(ctx, w, toAppNotification(n), http.StatusOK)
+}
+
+// MarkAllRead marks every notification of the authenticated user as read.
+func (h *Handlers) MarkAllRead(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	userID, err := auth.GetUserID(ctx)
+	if err != nil {
+		return auth.NewAuthError("markallread: %s", err)
+	}
+
+	count, err := h.notification.MarkAllRead(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("markallread: %w", err)
+	}
+
+	return web.Respond(ctx, w, AppMarked{Count: count}, http.StatusOK)
+}
+
+// QueryPreferences returns which notifications the authenticated user wants,
+// for every type and channel.
+func (h *Handlers) QueryPreferences(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	usr, err := h.queryUser(ctx)
+	if err != nil {
+		return err
+	}
+
+	return web.Respond(ctx, w, toAppPreferences(usr.NotificationPreferences), http.StatusOK)
+}
+
+// UpdatePreferences turns notifications of the authenticated user on or off,
+// by type and channel. Types and channels left out of the request keep their
+// current setting.
+func (h *Handlers) UpdatePreferences(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	var app AppPreferences
+	if err := web.Decode(r, &app); err != nil {
+		return err
+	}
+
+	usr, err := h.queryUser(ctx)
+	if err != nil {
+		return err
+	}
+
+	usr, err = h.user.UpdateNotificationPreferences(ctx, usr, toCorePreferences(app))
+	if err != nil {
+		return fmt.Errorf("update: userID[%s]: %w", usr.ID, err)
+	}
+
+	return web.Respond(ctx, w, toAppPreferences(usr.NotificationPreferences), http.StatusOK)
+}
+
+// queryUser retrieves the authenticated user.
+func (h *Handlers) queryUser(ctx context.Context) (user.User, error) {
+	userID, err := auth.GetUserID(ctx)
+	if err != nil {
+		return user.User{}, auth.NewAuthError("query: %s", err)
+	}
+
+	usr, err := h.user.QueryByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, user.ErrNotFound) {
+			return user.User{}, v1.NewRequestError(user.ErrNotFound, http.StatusNotFound)
+		}
+		return user.User{}, fmt.Errorf("query: userID[%s]: %w", userID, err)
+	}
+
+	return usr, nil
+}
@@ -0,0 +1,101 @@
+package patgrp
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/dmanias/startupers/business/core/pat"
+	"github.com/dmanias/startupers/business/sys/validate"
+	"github.com/google/uuid"
+)
+
+// AppToken represents a personal access token of the authenticated user. The
+// secret is only set in the response to creating the token.
+type AppToken struct {
+	ID           string   `json:"id"`
+	Name         string   `json:"name"`
+	Scopes       []string `json:"scopes"`
+	Token        string   `json:"token,omitempty"`
+	DateExpires  string   `json:"dateExpires,omitempty"`
+	DateLastUsed string   `json:"dateLastUsed,omitempty"`
+	DateCreated  string   `json:"dateCreated"`
+}
+
+func toAppToken(tkn pat.Token) AppToken {
+	scopes := make([]string, len(tkn.Scopes))
+	for i, scope := range tkn.Scopes {
+		scopes[i] = scope.Name()
+	}
+
+	var dateExpires string
+	if !tkn.DateExpires.IsZero() {
+		dateExpires = tkn.DateExpires.Format(time.RFC3339)
+	}
+
+	var dateLastUsed string
+	if !tkn.DateLastUsed.IsZero() {
+		dateLastUsed = tkn.DateLastUsed.Format(time.RFC3339)
+	}
+
+	return AppToken{
+		ID:           tkn.ID.String(),
+		Name:         tkn.Name,
+		Scopes:       scopes,
+		DateExpires:  dateExpires,
+		DateLastUsed: dateLastUsed,
+		DateCreated:  tkn.DateCreated.Format(time.RFC3339),
+	}
+}
+
+func toAppTokens(tkns []pat.Token) []AppToken {
+	items := make([]AppToken, len(tkns))
+	for i, tkn := range tkns {
+		items[i] = toAppToken(tkn)
+	}
+	return items
+}
+
+// =============================================================================
+
+// AppNewToken contains information needed to create a personal access token.
+// Without an expiry the token works until it is revoked.
+type AppNewToken struct {
+	Name        string   `json:"name" validate:"required,max=100"`
+	Scopes      []string `json:"scopes" validate:"required,min=1"`
+	DateExpires string   `json:"dateExpires"`
+}
+
+func toCoreNewToken(userID uuid.UUID, app AppNewToken) (pat.NewToken, error) {
+	scopes := make([]pat.Scope, len(app.Scopes))
+	for i, value := range app.Scopes {
+		scope, err := pat.ParseScope(value)
+		if err != nil {
+			return pat.NewToken{}, validate.NewFieldsError("scopes", fmt.Errorf("%q: %w; known scopes are %v", value, err, pat.Scopes()))
+		}
+		scopes[i] = scope
+	}
+
+	nt := pat.NewToken{
+		UserID: userID,
+		Name:   app.Name,
+		Scopes: scopes,
+	}
+
+	if app.DateExpires != "" {
+		dateExpires, err := time.Parse(time.RFC3339, app.DateExpires)
+		if err != nil {
+			return pat.NewToken{}, validate.NewFieldsError("dateExpires", err)
+		}
+		nt.DateExpires = dateExpires
+	}
+
+	return nt, nil
+}
+
+// Validate checks the data in the model is considered clean.
+func (app AppNewToken) Validate() error {
+	if err := validate.Check(app); err != nil {
+		return err
+	}
+	return nil
+}
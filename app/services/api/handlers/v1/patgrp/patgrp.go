@@ -0,0 +1,115 @@
+// Package patgrp maintains the group of handlers for the personal access
+// tokens of the authenticated user.
+package patgrp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/dmanias/startupers/business/core/pat"
+	"github.com/dmanias/startupers/business/sys/validate"
+	"github.com/dmanias/startupers/business/web/auth"
+	v1 "github.com/dmanias/startupers/business/web/v1"
+	"github.com/dmanias/startupers/foundation/web"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// Handlers manages the set of personal access token endpoints.
+type Handlers struct {
+	pat *pat.Core
+	log *zap.SugaredLogger
+}
+
+// New constructs a handlers for route access.
+func New(pat *pat.Core, log *zap.SugaredLogger) *Handlers {
+	return &Handlers{
+		pat: pat,
+		log: log,
+	}
+}
+
+// Create adds a personal access token for the authenticated user. The
+// response is the only time the secret is shown.
+func (h *Handlers) Create(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	var app AppNewToken
+	if err := web.Decode(r, &app); err != nil {
+		return err
+	}
+
+	userID, err := auth.GetUserID(ctx)
+	if err != nil {
+		return auth.NewAuthError("create: %s", err)
+	}
+
+	nt, err := toCoreNewToken(userID, app)
+	if err != nil {
+		return err
+	}
+
+	tkn, secret, err := h.pat.Create(ctx, nt)
+	if err != nil {
+		switch {
+		case errors.Is(err, pat.ErrNoScopes):
+			return validate.NewFieldsError("scopes", pat.ErrNoScopes)
+		case errors.Is(err, pat.ErrExpiryPassed):
+			return validate.NewFieldsError("dateExpires", pat.ErrExpiryPassed)
+		}
+		return fmt.Errorf("create: %w", err)
+	}
+
+	resp := toAppToken(tkn)
+	resp.Token = secret
+
+	return web.Respond(ctx, w, resp, http.StatusCreated)
+}
+
+// Query returns the personal access tokens of the authenticated user that
+// weren't revoked, newest first.
+func (h *Handlers) Query(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	userID, err := auth.GetUserID(ctx)
+	if err != nil {
+		return auth.NewAuthError("query: %s", err)
+	}
+
+	tkns, err := h.pat.QueryByUserID(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("query: %w", err)
+	}
+
+	return web.Respond(ctx, w, toAppTokens(tkns), http.StatusOK)
+}
+
+// Revoke stops a personal access token of the authenticated user from
+// working.
+func (h *Handlers) Revoke(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	tokenID, err := uuid.Parse(web.Param(r, "token_id"))
+	if err != nil {
+		return v1.NewRequestError(err, http.StatusBadRequest)
+	}
+
+	userID, err := auth.GetUserID(ctx)
+	if err != nil {
+		return auth.NewAuthError("revoke: %s", err)
+	}
+
+	tkn, err := h.pat.QueryByID(ctx, tokenID)
+	if err != nil {
+		if errors.Is(err, pat.ErrNotFound) {
+			return v1.NewRequestError(pat.ErrNotFound, http.StatusNotFound)
+		}
+		return fmt.Errorf("query: tokenID[%s]: %w", tokenID, err)
+	}
+
+	if tkn.UserID != userID {
+		return v1.NewRequestError(pat.ErrNotFound, http.StatusNotFound)
+	}
+
+	if _, err := h.pat.Revoke(ctx, tkn); err != nil {
+		return fmt.Errorf("revoke: %w", err)
+	}
+
+	return web.Respond(ctx, w, nil, http.StatusNoContent)
+}
@@ -10,24 +10,40 @@ import (
 )
 
 type AppPost struct {
-	ID          string `json:"id"`
-	IdeaID      string `json:"ideaID"`
-	AuthorID    string `json:"authorID"`
-	Content     string `json:"content"`
-	OwnerType   string `json:"ownerType"`
-	DateCreated string `json:"dateCreated"`
-	DateUpdated string `json:"dateUpdated"`
+	ID            string         `json:"id"`
+	IdeaID        string         `json:"ideaID"`
+	AuthorID      string         `json:"authorID"`
+	Content       string         `json:"content"`
+	OwnerType     string         `json:"ownerType"`
+	ForkedFrom    string         `json:"forkedFrom,omitempty"`
+	ReactionCount int            `json:"reactionCount"`
+	Reactions     map[string]int `json:"reactions"`
+	DateCreated   string         `json:"dateCreated"`
+	DateUpdated   string         `json:"dateUpdated"`
 }
 
 func toAppPost(post post.Post) AppPost {
+	reactions := make(map[string]int, len(post.ReactionCounts))
+	for emoji, n := range post.ReactionCounts {
+		reactions[emoji.Name()] = n
+	}
+
+	var forkedFrom string
+	if post.ForkedFrom != uuid.Nil {
+		forkedFrom = post.ForkedFrom.String()
+	}
+
 	return AppPost{
-		ID:          post.ID.String(),
-		IdeaID:      post.IdeaID.String(),
-		AuthorID:    post.AuthorID.String(),
-		Content:     post.Content,
-		OwnerType:   post.OwnerType,
-		DateCreated: post.DateCreated.Format(time.RFC3339),
-		DateUpdated: post.DateUpdated.Format(time.RFC3339),
+		ID:            post.ID.String(),
+		IdeaID:        post.IdeaID.String(),
+		AuthorID:      post.AuthorID.String(),
+		Content:       post.Content,
+		OwnerType:     post.OwnerType,
+		ForkedFrom:    forkedFrom,
+		ReactionCount: post.ReactionCount,
+		Reactions:     reactions,
+		DateCreated:   post.DateCreated.Format(time.RFC3339),
+		DateUpdated:   post.DateUpdated.Format(time.RFC3339),
 	}
 }
 
@@ -104,3 +120,41 @@ func (app AppUpdatePost) Validate() error {
 	}
 	return nil
 }
+
+// =============================================================================
+
+// AppReaction represents a user's reaction to a post.
+type AppReaction struct {
+	UserID      string `json:"userID"`
+	Emoji       string `json:"emoji"`
+	DateCreated string `json:"dateCreated"`
+}
+
+func toAppReaction(reaction post.Reaction) AppReaction {
+	return AppReaction{
+		UserID:      reaction.UserID.String(),
+		Emoji:       reaction.Emoji.Name(),
+		DateCreated: reaction.DateCreated.Format(time.RFC3339),
+	}
+}
+
+func toAppReactions(reactions []post.Reaction) []AppReaction {
+	items := make([]AppReaction, len(reactions))
+	for i, reaction := range reactions {
+		items[i] = toAppReaction(reaction)
+	}
+	return items
+}
+
+// AppNewReaction contains the information needed to react to a post.
+type AppNewReaction struct {
+	Emoji string `json:"emoji" validate:"required,oneof=thumbsup heart laugh tada rocket eyes thinking"`
+}
+
+// Validate checks the data in the model is considered clean.
+func (app AppNewReaction) Validate() error {
+	if err := validate.Check(app); err != nil {
+		return err
+	}
+	return nil
+}
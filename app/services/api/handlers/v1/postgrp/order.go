@@ -15,6 +15,7 @@ var orderByFields = map[string]struct{}{
 	post.OrderByAuthorID:    {},
 	post.OrderByDateCreated: {},
 	post.OrderByDateUpdated: {},
+	post.OrderByVotes:       {},
 }
 
 func parseOrder(r *http.Request) (order.By, error) {
@@ -2,12 +2,17 @@ package postgrp
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"github.com/dmanias/startupers/app/services/api/handlers/v1/aigrp"
 	"github.com/dmanias/startupers/app/services/api/handlers/v1/moderationgrp"
+	"github.com/dmanias/startupers/business/core/idea"
 	"github.com/dmanias/startupers/business/core/post"
+	"github.com/dmanias/startupers/business/sys/validate"
+	"github.com/dmanias/startupers/business/web/auth"
 	v1 "github.com/dmanias/startupers/business/web/v1"
 	"github.com/dmanias/startupers/business/web/v1/paging"
+	"github.com/dmanias/startupers/business/web/v1/visible"
 	"github.com/dmanias/startupers/foundation/web"
 	"github.com/google/uuid"
 	"go.uber.org/zap"
@@ -18,15 +23,21 @@ import (
 // Handlers manages the set of post endpoints.
 type Handlers struct {
 	post               *post.Core
+	idea               *idea.Core
 	log                *zap.SugaredLogger
 	aiHandlers         *aigrp.Handlers
 	moderationHandlers *moderationgrp.Handlers
 }
 
+// ErrCannotDelete is returned when the user may see a post but not move it
+// to the trash.
+var ErrCannotDelete = errors.New("only the author, the owner of the idea or a collaborator can delete the post")
+
 // New constructs a handlers for route access.
-func New(post *post.Core, log *zap.SugaredLogger, aiHandlers *aigrp.Handlers, moderationHandlers *moderationgrp.Handlers) *Handlers {
+func New(post *post.Core, idea *idea.Core, log *zap.SugaredLogger, aiHandlers *aigrp.Handlers, moderationHandlers *moderationgrp.Handlers) *Handlers {
 	return &Handlers{
 		post:               post,
+		idea:               idea,
 		log:                log,
 		aiHandlers:         aiHandlers,
 		moderationHandlers: moderationHandlers,
@@ -39,14 +50,19 @@ func (h *Handlers) Create(ctx context.Context, w http.ResponseWriter, r *http.Re
 		return err
 	}
 
+	// Retrieve the idea ID from the app.IdeaID field
+	ideaID, err := uuid.Parse(app.IdeaID)
+	if err != nil {
+		return v1.NewRequestError(err, http.StatusBadRequest)
+	}
+
+	if _, err := visible.Idea(ctx, h.idea, ideaID); err != nil {
+		return err
+	}
+
 	// Check if the ownerType is 'idea'
-	if app.OwnerType == "idea" {
+	if app.OwnerType == post.OwnerTypeIdea {
 
-		// Retrieve the idea ID from the app.IdeaID field
-		ideaID, err := uuid.Parse(app.IdeaID)
-		if err != nil {
-			return v1.NewRequestError(err, http.StatusBadRequest)
-		}
 		// Create a filter to query posts that belong to the specific idea.
 		filter := post.QueryFilter{
 			IdeaID: &ideaID,
@@ -144,9 +160,9 @@ func (h *Handlers) Update(ctx context.Context, w http.ResponseWriter, r *http.Re
 		return v1.NewRequestError(err, http.StatusBadRequest)
 	}
 
-	post, err := h.post.QueryByID(ctx, *uc.ID)
+	post, err := h.queryVisiblePost(ctx, *uc.ID)
 	if err != nil {
-		return fmt.Errorf("query: postID[%s]: %w", uc.ID, err)
+		return err
 	}
 
 	updatedPost, err := h.post.Update(ctx, post, uc)
@@ -163,12 +179,26 @@ func (h *Handlers) Delete(ctx context.Context, w http.ResponseWriter, r *http.Re
 		return v1.NewRequestError(err, http.StatusBadRequest)
 	}
 
-	post, err := h.post.QueryByID(ctx, postID)
+	post, err := h.queryVisiblePost(ctx, postID)
+	if err != nil {
+		return err
+	}
+
+	userID, err := auth.GetUserID(ctx)
 	if err != nil {
-		return fmt.Errorf("query: postID[%s]: %w", postID, err)
+		return auth.NewAuthError("delete: %s", err)
 	}
 
-	if err := h.post.Delete(ctx, post); err != nil {
+	idr, err := visible.Idea(ctx, h.idea, post.IdeaID)
+	if err != nil {
+		return err
+	}
+
+	if post.AuthorID != userID && !idr.IsMember(userID) {
+		return v1.NewRequestError(ErrCannotDelete, http.StatusForbidden)
+	}
+
+	if _, err := h.post.Delete(ctx, post, userID); err != nil {
 		return fmt.Errorf("delete: post[%+v]: %w", post, err)
 	}
 
@@ -183,9 +213,9 @@ func (h *Handlers) QueryByID(ctx context.Context, w http.ResponseWriter, r *http
 		return v1.NewRequestError(fmt.Errorf("invalid post ID: %w", err), http.StatusBadRequest)
 	}
 
-	post, err := h.post.QueryByID(ctx, id)
+	post, err := h.queryVisiblePost(ctx, id)
 	if err != nil {
-		return fmt.Errorf("query post by ID: %w", err)
+		return err
 	}
 
 	appPost := toAppPost(post)
@@ -209,6 +239,10 @@ func (h *Handlers) Query(ctx context.Context, w http.ResponseWriter, r *http.Req
 		return v1.NewRequestError(err, http.StatusBadRequest)
 	}
 
+	if _, err := visible.Idea(ctx, h.idea, ideaID); err != nil {
+		return err
+	}
+
 	// Create a filter to query posts that belong to the specific idea.
 	filter := post.QueryFilter{
 		IdeaID: &ideaID,
@@ -237,3 +271,124 @@ func (h *Handlers) Query(ctx context.Context, w http.ResponseWriter, r *http.Req
 
 	return web.Respond(ctx, w, paging.NewResponse(items, total, page.Number, page.RowsPerPage), http.StatusOK)
 }
+
+// React adds the authenticated user's reaction to a post.
+func (h *Handlers) React(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	var app AppNewReaction
+	if err := web.Decode(r, &app); err != nil {
+		return err
+	}
+
+	emoji, err := post.ParseEmoji(app.Emoji)
+	if err != nil {
+		return validate.NewFieldsError("emoji", err)
+	}
+
+	postID, err := uuid.Parse(web.Param(r, "post_id"))
+	if err != nil {
+		return v1.NewRequestError(err, http.StatusBadRequest)
+	}
+
+	pst, err := h.queryVisiblePost(ctx, postID)
+	if err != nil {
+		return err
+	}
+
+	userID, err := auth.GetUserID(ctx)
+	if err != nil {
+		return auth.NewAuthError("react: %s", err)
+	}
+
+	pst, err = h.post.React(ctx, pst, userID, emoji)
+	if err != nil {
+		if errors.Is(err, post.ErrAlreadyReacted) {
+			return v1.NewRequestError(post.ErrAlreadyReacted, http.StatusConflict)
+		}
+		return fmt.Errorf("react: postID[%s]: %w", postID, err)
+	}
+
+	return web.Respond(ctx, w, toAppPost(pst), http.StatusCreated)
+}
+
+// Unreact takes back the authenticated user's reaction to a post.
+func (h *Handlers) Unreact(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	emoji, err := post.ParseEmoji(web.Param(r, "emoji"))
+	if err != nil {
+		return v1.NewRequestError(err, http.StatusBadRequest)
+	}
+
+	postID, err := uuid.Parse(web.Param(r, "post_id"))
+	if err != nil {
+		return v1.NewRequestError(err, http.StatusBadRequest)
+	}
+
+	pst, err := h.queryVisiblePost(ctx, postID)
+	if err != nil {
+		return err
+	}
+
+	userID, err := auth.GetUserID(ctx)
+	if err != nil {
+		return auth.NewAuthError("unreact: %s", err)
+	}
+
+	pst, err = h.post.Unreact(ctx, pst, userID, emoji)
+	if err != nil {
+		if errors.Is(err, post.ErrReactionNotFound) {
+			return v1.NewRequestError(post.ErrReactionNotFound, http.StatusNotFound)
+		}
+		return fmt.Errorf("unreact: postID[%s]: %w", postID, err)
+	}
+
+	return web.Respond(ctx, w, toAppPost(pst), http.StatusOK)
+}
+
+// QueryReactions returns the reactions to a post, newest first, with paging.
+func (h *Handlers) QueryReactions(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	page, err := paging.ParseRequest(r)
+	if err != nil {
+		return err
+	}
+
+	postID, err := uuid.Parse(web.Param(r, "post_id"))
+	if err != nil {
+		return v1.NewRequestError(err, http.StatusBadRequest)
+	}
+
+	if _, err := h.queryVisiblePost(ctx, postID); err != nil {
+		return err
+	}
+
+	reactions, err := h.post.QueryReactions(ctx, postID, page.Number, page.RowsPerPage)
+	if err != nil {
+		return fmt.Errorf("query: %w", err)
+	}
+
+	total, err := h.post.CountReactions(ctx, postID)
+	if err != nil {
+		return fmt.Errorf("count: %w", err)
+	}
+
+	return web.Respond(ctx, w, paging.NewResponse(toAppReactions(reactions), total, page.Number, page.RowsPerPage), http.StatusOK)
+}
+
+// queryVisiblePost retrieves the post if the authenticated user is allowed to
+// see the idea it belongs to. Posts on hidden ideas are reported as not found.
+func (h *Handlers) queryVisiblePost(ctx context.Context, postID uuid.UUID) (post.Post, error) {
+	pst, err := h.post.QueryByID(ctx, postID)
+	if err != nil {
+		if errors.Is(err, post.ErrNotFound) {
+			return post.Post{}, v1.NewRequestError(post.ErrNotFound, http.StatusNotFound)
+		}
+		return post.Post{}, fmt.Errorf("query: postID[%s]: %w", postID, err)
+	}
+
+	if _, err := visible.Idea(ctx, h.idea, pst.IdeaID); err != nil {
+		if v1.IsRequestError(err) {
+			return post.Post{}, v1.NewRequestError(post.ErrNotFound, http.StatusNotFound)
+		}
+		return post.Post{}, err
+	}
+
+	return pst, nil
+}
@@ -0,0 +1,58 @@
+package privacygrp
+
+import (
+	"time"
+
+	"github.com/dmanias/startupers/business/core/deletion"
+	"github.com/dmanias/startupers/business/core/export"
+)
+
+// AppExport represents an export of the data of the user.
+type AppExport struct {
+	ID            string `json:"id"`
+	Status        string `json:"status"`
+	Size          int    `json:"size"`
+	DateCompleted string `json:"dateCompleted,omitempty"`
+	DateExpires   string `json:"dateExpires,omitempty"`
+	DateCreated   string `json:"dateCreated"`
+}
+
+func toAppExport(exp export.Export) AppExport {
+	app := AppExport{
+		ID:          exp.ID.String(),
+		Status:      exp.Status.Name(),
+		Size:        exp.Size,
+		DateCreated: exp.DateCreated.Format(time.RFC3339),
+	}
+
+	if !exp.DateCompleted.IsZero() {
+		app.DateCompleted = exp.DateCompleted.Format(time.RFC3339)
+	}
+	if !exp.DateExpires.IsZero() {
+		app.DateExpires = exp.DateExpires.Format(time.RFC3339)
+	}
+
+	return app
+}
+
+func toAppExports(exps []export.Export) []AppExport {
+	items := make([]AppExport, len(exps))
+	for i, exp := range exps {
+		items[i] = toAppExport(exp)
+	}
+	return items
+}
+
+// AppDeletion represents the request of the user to delete their account,
+// and when it will be deleted.
+type AppDeletion struct {
+	DateDue     string `json:"dateDue"`
+	DateCreated string `json:"dateCreated"`
+}
+
+func toAppDeletion(req deletion.Request) AppDeletion {
+	return AppDeletion{
+		DateDue:     req.DateDue.Format(time.RFC3339),
+		DateCreated: req.DateCreated.Format(time.RFC3339),
+	}
+}
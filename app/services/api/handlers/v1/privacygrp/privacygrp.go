@@ -0,0 +1,157 @@
+// Package privacygrp maintains the group of handlers for users to export
+// their data and to delete their account.
+package privacygrp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/dmanias/startupers/business/core/deletion"
+	"github.com/dmanias/startupers/business/core/export"
+	"github.com/dmanias/startupers/business/core/user"
+	"github.com/dmanias/startupers/business/web/auth"
+	v1 "github.com/dmanias/startupers/business/web/v1"
+	"github.com/dmanias/startupers/foundation/web"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// Handlers manages the set of export and account deletion endpoints.
+type Handlers struct {
+	user     *user.Core
+	export   *export.Core
+	deletion *deletion.Core
+	log      *zap.SugaredLogger
+}
+
+// New constructs a handlers for route access.
+func New(user *user.Core, export *export.Core, deletion *deletion.Core, log *zap.SugaredLogger) *Handlers {
+	return &Handlers{
+		user:     user,
+		export:   export,
+		deletion: deletion,
+		log:      log,
+	}
+}
+
+// RequestExport asks for an export of the data of the signed in user. It is
+// built in the background; the user is emailed when it is ready.
+func (h *Handlers) RequestExport(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	userID, err := auth.GetUserID(ctx)
+	if err != nil {
+		return auth.NewAuthError("requestexport: %s", err)
+	}
+
+	exp, err := h.export.Request(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("request: %w", err)
+	}
+
+	return web.Respond(ctx, w, toAppExport(exp), http.StatusAccepted)
+}
+
+// QueryExports returns the exports of the signed in user, newest first.
+func (h *Handlers) QueryExports(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	userID, err := auth.GetUserID(ctx)
+	if err != nil {
+		return auth.NewAuthError("queryexports: %s", err)
+	}
+
+	exps, err := h.export.QueryByUserID(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("query: %w", err)
+	}
+
+	return web.Respond(ctx, w, toAppExports(exps), http.StatusOK)
+}
+
+// DownloadExport sends the ZIP archive of an export of the signed in user.
+func (h *Handlers) DownloadExport(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	userID, err := auth.GetUserID(ctx)
+	if err != nil {
+		return auth.NewAuthError("downloadexport: %s", err)
+	}
+
+	exportID, err := uuid.Parse(web.Param(r, "export_id"))
+	if err != nil {
+		return v1.NewRequestError(err, http.StatusBadRequest)
+	}
+
+	exp, err := h.export.QueryArchive(ctx, userID, exportID)
+	if err != nil {
+		switch {
+		case errors.Is(err, export.ErrNotFound):
+			return v1.NewRequestError(export.ErrNotFound, http.StatusNotFound)
+		case errors.Is(err, export.ErrNotReady):
+			return v1.NewRequestError(export.ErrNotReady, http.StatusConflict)
+		}
+		return fmt.Errorf("queryarchive: %w", err)
+	}
+
+	filename := "startupers-export-" + exp.DateCreated.UTC().Format("2006-01-02") + ".zip"
+
+	return web.RespondFile(ctx, w, exp.Archive, "application/zip", filename)
+}
+
+// QueryDeletion returns the pending request of the signed in user to delete
+// their account.
+func (h *Handlers) QueryDeletion(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	userID, err := auth.GetUserID(ctx)
+	if err != nil {
+		return auth.NewAuthError("querydeletion: %s", err)
+	}
+
+	req, err := h.deletion.QueryByUserID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, deletion.ErrNotFound) {
+			return v1.NewRequestError(deletion.ErrNotFound, http.StatusNotFound)
+		}
+		return fmt.Errorf("query: %w", err)
+	}
+
+	return web.Respond(ctx, w, toAppDeletion(req), http.StatusOK)
+}
+
+// RequestDeletion schedules the account of the signed in user to be deleted
+// once the grace period is over.
+func (h *Handlers) RequestDeletion(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	userID, err := auth.GetUserID(ctx)
+	if err != nil {
+		return auth.NewAuthError("requestdeletion: %s", err)
+	}
+
+	usr, err := h.user.QueryByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, user.ErrNotFound) {
+			return auth.NewAuthError("requestdeletion: %s", user.ErrNotFound)
+		}
+		return fmt.Errorf("querybyid: userID[%s]: %w", userID, err)
+	}
+
+	req, err := h.deletion.Request(ctx, usr)
+	if err != nil {
+		return fmt.Errorf("request: %w", err)
+	}
+
+	return web.Respond(ctx, w, toAppDeletion(req), http.StatusAccepted)
+}
+
+// CancelDeletion withdraws the request of the signed in user to delete their
+// account.
+func (h *Handlers) CancelDeletion(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	userID, err := auth.GetUserID(ctx)
+	if err != nil {
+		return auth.NewAuthError("canceldeletion: %s", err)
+	}
+
+	if err := h.deletion.Cancel(ctx, userID); err != nil {
+		if errors.Is(err, deletion.ErrNotFound) {
+			return v1.NewRequestError(deletion.ErrNotFound, http.StatusNotFound)
+		}
+		return fmt.Errorf("cancel: %w", err)
+	}
+
+	return web.Respond(ctx, w, nil, http.StatusNoContent)
+}
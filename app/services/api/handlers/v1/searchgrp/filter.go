@@ -0,0 +1,48 @@
+package searchgrp
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/dmanias/startupers/business/core/idea"
+	"github.com/dmanias/startupers/business/core/search"
+	"github.com/dmanias/startupers/business/sys/validate"
+)
+
+func parseFilter(r *http.Request) (search.QueryFilter, error) {
+	values := r.URL.Query()
+	var filter search.QueryFilter
+
+	query := strings.TrimSpace(values.Get("q"))
+	if query == "" {
+		return search.QueryFilter{}, validate.NewFieldsError("q", errors.New("q is a required field"))
+	}
+	filter.WithQuery(query)
+
+	if kind := values.Get("type"); kind != "" {
+		k, err := search.ParseKind(kind)
+		if err != nil {
+			return search.QueryFilter{}, validate.NewFieldsError("type", err)
+		}
+		filter.WithKind(k)
+	}
+
+	if tag := values.Get("tag"); tag != "" {
+		filter.WithTag(tag)
+	}
+
+	if category := values.Get("category"); category != "" {
+		filter.WithCategory(category)
+	}
+
+	if stage := values.Get("stage"); stage != "" {
+		st, err := idea.ParseStage(stage)
+		if err != nil {
+			return search.QueryFilter{}, validate.NewFieldsError("stage", err)
+		}
+		filter.WithStage(st)
+	}
+
+	return filter, nil
+}
@@ -0,0 +1,31 @@
+package searchgrp
+
+import (
+	"time"
+
+	"github.com/dmanias/startupers/business/core/search"
+)
+
+// AppResult represents a record matching a search. The snippet is HTML with
+// the matched words wrapped in <mark> tags.
+type AppResult struct {
+	Type        string  `json:"type"`
+	ID          string  `json:"id"`
+	IdeaID      string  `json:"ideaID"`
+	Title       string  `json:"title"`
+	Snippet     string  `json:"snippet"`
+	Rank        float64 `json:"rank"`
+	DateCreated string  `json:"dateCreated"`
+}
+
+func toAppResult(res search.Result) AppResult {
+	return AppResult{
+		Type:        res.Kind.Name(),
+		ID:          res.ID.String(),
+		IdeaID:      res.IdeaID.String(),
+		Title:       res.Title,
+		Snippet:     res.Snippet,
+		Rank:        res.Rank,
+		DateCreated: res.DateCreated.Format(time.RFC3339),
+	}
+}
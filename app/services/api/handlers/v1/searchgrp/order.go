@@ -0,0 +1,28 @@
+package searchgrp
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/dmanias/startupers/business/core/search"
+	"github.com/dmanias/startupers/business/data/order"
+	"github.com/dmanias/startupers/business/sys/validate"
+)
+
+var orderByFields = map[string]struct{}{
+	search.OrderByRank:        {},
+	search.OrderByDateCreated: {},
+}
+
+func parseOrder(r *http.Request) (order.By, error) {
+	orderBy, err := order.Parse(r, search.DefaultOrderBy)
+	if err != nil {
+		return order.By{}, err
+	}
+
+	if _, exists := orderByFields[orderBy.Field]; !exists {
+		return order.By{}, validate.NewFieldsError(orderBy.Field, errors.New("order field does not exist"))
+	}
+
+	return orderBy, nil
+}
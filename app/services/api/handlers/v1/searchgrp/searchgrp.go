@@ -0,0 +1,70 @@
+// Package searchgrp maintains the group of handlers for full-text search.
+package searchgrp
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/dmanias/startupers/business/core/search"
+	"github.com/dmanias/startupers/business/web/auth"
+	"github.com/dmanias/startupers/business/web/v1/paging"
+	"github.com/dmanias/startupers/foundation/web"
+	"go.uber.org/zap"
+)
+
+// Handlers manages the set of search endpoints.
+type Handlers struct {
+	search *search.Core
+	log    *zap.SugaredLogger
+}
+
+// New constructs a handlers for route access.
+func New(search *search.Core, log *zap.SugaredLogger) *Handlers {
+	return &Handlers{
+		search: search,
+		log:    log,
+	}
+}
+
+// Query returns the ideas, posts and challenges matching the search that the
+// user is allowed to see, best matches first.
+func (h *Handlers) Query(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	page, err := paging.ParseRequest(r)
+	if err != nil {
+		return err
+	}
+
+	filter, err := parseFilter(r)
+	if err != nil {
+		return err
+	}
+
+	userID, err := auth.GetUserID(ctx)
+	if err != nil {
+		return auth.NewAuthError("search: %s", err)
+	}
+	filter.WithViewerID(userID)
+
+	orderBy, err := parseOrder(r)
+	if err != nil {
+		return err
+	}
+
+	results, err := h.search.Query(ctx, filter, orderBy, page.Number, page.RowsPerPage)
+	if err != nil {
+		return fmt.Errorf("query: %w", err)
+	}
+
+	items := make([]AppResult, len(results))
+	for i, res := range results {
+		items[i] = toAppResult(res)
+	}
+
+	total, err := h.search.Count(ctx, filter)
+	if err != nil {
+		return fmt.Errorf("count: %w", err)
+	}
+
+	return web.Respond(ctx, w, paging.NewResponse(items, total, page.Number, page.RowsPerPage), http.StatusOK)
+}
@@ -0,0 +1,22 @@
+package taggrp
+
+import (
+	"net/http"
+
+	"github.com/dmanias/startupers/business/core/tag"
+)
+
+func parseFilter(r *http.Request) (tag.QueryFilter, error) {
+	values := r.URL.Query()
+	var filter tag.QueryFilter
+
+	if prefix := values.Get("prefix"); prefix != "" {
+		filter.WithPrefix(prefix)
+	}
+
+	if err := filter.Validate(); err != nil {
+		return tag.QueryFilter{}, err
+	}
+
+	return filter, nil
+}
@@ -0,0 +1,72 @@
+package taggrp
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/dmanias/startupers/business/core/tag"
+	"github.com/dmanias/startupers/business/sys/validate"
+)
+
+// AppTag represents a tag of the vocabulary.
+type AppTag struct {
+	Slug        string   `json:"slug"`
+	Name        string   `json:"name"`
+	Aliases     []string `json:"aliases"`
+	UsageCount  int      `json:"usageCount"`
+	DateCreated string   `json:"dateCreated"`
+	DateUpdated string   `json:"dateUpdated"`
+}
+
+func toAppTag(tg tag.Tag) AppTag {
+	aliases := tg.Aliases
+	if aliases == nil {
+		aliases = []string{}
+	}
+
+	return AppTag{
+		Slug:        tg.Slug,
+		Name:        tg.Name,
+		Aliases:     aliases,
+		UsageCount:  tg.UsageCount,
+		DateCreated: tg.DateCreated.Format(time.RFC3339),
+		DateUpdated: tg.DateUpdated.Format(time.RFC3339),
+	}
+}
+
+func toAppTags(tgs []tag.Tag) []AppTag {
+	items := make([]AppTag, len(tgs))
+	for i, tg := range tgs {
+		items[i] = toAppTag(tg)
+	}
+
+	return items
+}
+
+// =============================================================================
+
+// AppRenameTag contains the new name of a tag.
+type AppRenameTag struct {
+	Name string `json:"name" validate:"required,max=100"`
+}
+
+// Validate checks the data in the model is considered clean.
+func (app AppRenameTag) Validate() error {
+	if err := validate.Check(app); err != nil {
+		return fmt.Errorf("validate: %w", err)
+	}
+	return nil
+}
+
+// AppMergeTag names the tag another tag is merged into.
+type AppMergeTag struct {
+	Into string `json:"into" validate:"required"`
+}
+
+// Validate checks the data in the model is considered clean.
+func (app AppMergeTag) Validate() error {
+	if err := validate.Check(app); err != nil {
+		return fmt.Errorf("validate: %w", err)
+	}
+	return nil
+}
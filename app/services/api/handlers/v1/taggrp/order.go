@@ -0,0 +1,30 @@
+package taggrp
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/dmanias/startupers/business/core/tag"
+	"github.com/dmanias/startupers/business/data/order"
+	"github.com/dmanias/startupers/business/sys/validate"
+)
+
+var orderByFields = map[string]struct{}{
+	tag.OrderBySlug:        {},
+	tag.OrderByName:        {},
+	tag.OrderByUsage:       {},
+	tag.OrderByDateCreated: {},
+}
+
+func parseOrder(r *http.Request) (order.By, error) {
+	orderBy, err := order.Parse(r, tag.DefaultOrderBy)
+	if err != nil {
+		return order.By{}, err
+	}
+
+	if _, exists := orderByFields[orderBy.Field]; !exists {
+		return order.By{}, validate.NewFieldsError(orderBy.Field, errors.New("order field does not exist"))
+	}
+
+	return orderBy, nil
+}
@@ -0,0 +1,141 @@
+// Package taggrp maintains the group of handlers for the tag vocabulary.
+package taggrp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/dmanias/startupers/business/core/tag"
+	"github.com/dmanias/startupers/business/web/auth"
+	v1 "github.com/dmanias/startupers/business/web/v1"
+	"github.com/dmanias/startupers/business/web/v1/paging"
+	"github.com/dmanias/startupers/foundation/web"
+	"go.uber.org/zap"
+)
+
+// Handlers manages the set of tag endpoints.
+type Handlers struct {
+	tag *tag.Core
+	log *zap.SugaredLogger
+}
+
+// New constructs a handlers for route access.
+func New(tag *tag.Core, log *zap.SugaredLogger) *Handlers {
+	return &Handlers{
+		tag: tag,
+		log: log,
+	}
+}
+
+// Query returns the tags the authenticated user can see, most used first,
+// with paging. A prefix narrows the list down for autocompletion.
+func (h *Handlers) Query(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	page, err := paging.ParseRequest(r)
+	if err != nil {
+		return err
+	}
+
+	filter, err := parseFilter(r)
+	if err != nil {
+		return err
+	}
+
+	orderBy, err := parseOrder(r)
+	if err != nil {
+		return err
+	}
+
+	userID, err := auth.GetUserID(ctx)
+	if err != nil {
+		return auth.NewAuthError("query: %s", err)
+	}
+	filter.WithVisibleTo(userID)
+
+	tags, err := h.tag.Query(ctx, filter, orderBy, page.Number, page.RowsPerPage)
+	if err != nil {
+		return fmt.Errorf("query: %w", err)
+	}
+
+	total, err := h.tag.Count(ctx, filter)
+	if err != nil {
+		return fmt.Errorf("count: %w", err)
+	}
+
+	return web.Respond(ctx, w, paging.NewResponse(toAppTags(tags), total, page.Number, page.RowsPerPage), http.StatusOK)
+}
+
+// Rename changes the name of a tag and rewrites the ideas using it.
+func (h *Handlers) Rename(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	var app AppRenameTag
+	if err := web.Decode(r, &app); err != nil {
+		return err
+	}
+
+	tg, err := h.queryTag(ctx, web.Param(r, "slug"))
+	if err != nil {
+		return err
+	}
+
+	tg, err = h.tag.Rename(ctx, tg, app.Name)
+	if err != nil {
+		return toRequestError(err)
+	}
+
+	return web.Respond(ctx, w, toAppTag(tg), http.StatusOK)
+}
+
+// Merge folds a tag into another one and rewrites the ideas using it.
+func (h *Handlers) Merge(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	var app AppMergeTag
+	if err := web.Decode(r, &app); err != nil {
+		return err
+	}
+
+	source, err := h.queryTag(ctx, web.Param(r, "slug"))
+	if err != nil {
+		return err
+	}
+
+	target, err := h.tag.QueryBySlug(ctx, tag.Slugify(app.Into))
+	if err != nil {
+		if errors.Is(err, tag.ErrNotFound) {
+			return v1.NewRequestError(fmt.Errorf("tag to merge into: %w", tag.ErrNotFound), http.StatusBadRequest)
+		}
+		return fmt.Errorf("merge: %w", err)
+	}
+
+	tg, err := h.tag.Merge(ctx, source, target)
+	if err != nil {
+		return toRequestError(err)
+	}
+
+	return web.Respond(ctx, w, toAppTag(tg), http.StatusOK)
+}
+
+// queryTag retrieves the tag named in the path.
+func (h *Handlers) queryTag(ctx context.Context, slug string) (tag.Tag, error) {
+	tg, err := h.tag.QueryBySlug(ctx, slug)
+	if err != nil {
+		if errors.Is(err, tag.ErrNotFound) {
+			return tag.Tag{}, v1.NewRequestError(tag.ErrNotFound, http.StatusNotFound)
+		}
+		return tag.Tag{}, fmt.Errorf("query: slug[%s]: %w", slug, err)
+	}
+
+	return tg, nil
+}
+
+// toRequestError maps the tag errors to the matching HTTP status.
+func toRequestError(err error) error {
+	switch {
+	case errors.Is(err, tag.ErrExists):
+		return v1.NewRequestError(tag.ErrExists, http.StatusConflict)
+	case errors.Is(err, tag.ErrInvalidName):
+		return v1.NewRequestError(tag.ErrInvalidName, http.StatusBadRequest)
+	case errors.Is(err, tag.ErrMergeSelf):
+		return v1.NewRequestError(tag.ErrMergeSelf, http.StatusBadRequest)
+	}
+	return err
+}
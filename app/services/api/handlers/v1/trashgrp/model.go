@@ -0,0 +1,68 @@
+package trashgrp
+
+import (
+	"time"
+
+	"github.com/dmanias/startupers/business/core/challenge"
+	"github.com/dmanias/startupers/business/core/idea"
+	"github.com/dmanias/startupers/business/core/post"
+)
+
+// AppTrashedIdea represents an idea in the trash.
+type AppTrashedIdea struct {
+	ID          string `json:"id"`
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	Category    string `json:"category"`
+	DeletedAt   string `json:"deletedAt"`
+	PurgeAfter  string `json:"purgeAfter"`
+}
+
+func toAppTrashedIdea(idr idea.Idea, purgeAfter time.Time) AppTrashedIdea {
+	return AppTrashedIdea{
+		ID:          idr.ID.String(),
+		Title:       idr.Title,
+		Description: idr.Description,
+		Category:    idr.Category,
+		DeletedAt:   idr.DeletedAt.Format(time.RFC3339),
+		PurgeAfter:  purgeAfter.Format(time.RFC3339),
+	}
+}
+
+// AppTrashedPost represents a post in the trash.
+type AppTrashedPost struct {
+	ID         string `json:"id"`
+	IdeaID     string `json:"ideaID"`
+	Content    string `json:"content"`
+	DeletedAt  string `json:"deletedAt"`
+	PurgeAfter string `json:"purgeAfter"`
+}
+
+func toAppTrashedPost(pst post.Post, purgeAfter time.Time) AppTrashedPost {
+	return AppTrashedPost{
+		ID:         pst.ID.String(),
+		IdeaID:     pst.IdeaID.String(),
+		Content:    pst.Content,
+		DeletedAt:  pst.DeletedAt.Format(time.RFC3339),
+		PurgeAfter: purgeAfter.Format(time.RFC3339),
+	}
+}
+
+// AppTrashedChallenge represents a challenge in the trash.
+type AppTrashedChallenge struct {
+	ID         string `json:"id"`
+	IdeaID     string `json:"ideaID"`
+	Answer     string `json:"answer"`
+	DeletedAt  string `json:"deletedAt"`
+	PurgeAfter string `json:"purgeAfter"`
+}
+
+func toAppTrashedChallenge(chl challenge.Challenge, purgeAfter time.Time) AppTrashedChallenge {
+	return AppTrashedChallenge{
+		ID:         chl.ID.String(),
+		IdeaID:     chl.IdeaID.String(),
+		Answer:     chl.Answer,
+		DeletedAt:  chl.DeletedAt.Format(time.RFC3339),
+		PurgeAfter: purgeAfter.Format(time.RFC3339),
+	}
+}
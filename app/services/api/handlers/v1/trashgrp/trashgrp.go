@@ -0,0 +1,310 @@
+// Package trashgrp maintains the group of handlers for the ideas, posts and
+// challenges users have deleted.
+package trashgrp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/dmanias/startupers/business/core/challenge"
+	"github.com/dmanias/startupers/business/core/idea"
+	"github.com/dmanias/startupers/business/core/post"
+	"github.com/dmanias/startupers/business/core/trash"
+	"github.com/dmanias/startupers/business/sys/uploads"
+	"github.com/dmanias/startupers/business/web/auth"
+	v1 "github.com/dmanias/startupers/business/web/v1"
+	"github.com/dmanias/startupers/business/web/v1/paging"
+	"github.com/dmanias/startupers/foundation/web"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// Handlers manages the set of trash endpoints.
+type Handlers struct {
+	trash     *trash.Core
+	idea      *idea.Core
+	post      *post.Core
+	challenge *challenge.Core
+	log       *zap.SugaredLogger
+}
+
+// New constructs a handlers for route access.
+func New(trash *trash.Core, idea *idea.Core, post *post.Core, challenge *challenge.Core, log *zap.SugaredLogger) *Handlers {
+	return &Handlers{
+		trash:     trash,
+		idea:      idea,
+		post:      post,
+		challenge: challenge,
+		log:       log,
+	}
+}
+
+// QueryIdeas returns the ideas of the authenticated user in the trash, with
+// paging.
+func (h *Handlers) QueryIdeas(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	page, err := paging.ParseRequest(r)
+	if err != nil {
+		return err
+	}
+
+	userID, err := auth.GetUserID(ctx)
+	if err != nil {
+		return auth.NewAuthError("queryideas: %s", err)
+	}
+
+	ideas, err := h.trash.QueryIdeas(ctx, userID, page.Number, page.RowsPerPage)
+	if err != nil {
+		return fmt.Errorf("queryideas: %w", err)
+	}
+
+	items := make([]AppTrashedIdea, len(ideas))
+	for i, idr := range ideas {
+		items[i] = toAppTrashedIdea(idr, h.trash.PurgeAfter(idr.DeletedAt))
+	}
+
+	total, err := h.trash.CountIdeas(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("countideas: %w", err)
+	}
+
+	return web.Respond(ctx, w, paging.NewResponse(items, total, page.Number, page.RowsPerPage), http.StatusOK)
+}
+
+// QueryPosts returns the posts in the trash the authenticated user can
+// restore, with paging.
+func (h *Handlers) QueryPosts(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	page, err := paging.ParseRequest(r)
+	if err != nil {
+		return err
+	}
+
+	userID, err := auth.GetUserID(ctx)
+	if err != nil {
+		return auth.NewAuthError("queryposts: %s", err)
+	}
+
+	posts, err := h.trash.QueryPosts(ctx, userID, page.Number, page.RowsPerPage)
+	if err != nil {
+		return fmt.Errorf("queryposts: %w", err)
+	}
+
+	items := make([]AppTrashedPost, len(posts))
+	for i, pst := range posts {
+		items[i] = toAppTrashedPost(pst, h.trash.PurgeAfter(pst.DeletedAt))
+	}
+
+	total, err := h.trash.CountPosts(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("countposts: %w", err)
+	}
+
+	return web.Respond(ctx, w, paging.NewResponse(items, total, page.Number, page.RowsPerPage), http.StatusOK)
+}
+
+// QueryChallenges returns the challenges in the trash the authenticated user
+// can restore, with paging.
+func (h *Handlers) QueryChallenges(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	page, err := paging.ParseRequest(r)
+	if err != nil {
+		return err
+	}
+
+	userID, err := auth.GetUserID(ctx)
+	if err != nil {
+		return auth.NewAuthError("querychallenges: %s", err)
+	}
+
+	challenges, err := h.trash.QueryChallenges(ctx, userID, page.Number, page.RowsPerPage)
+	if err != nil {
+		return fmt.Errorf("querychallenges: %w", err)
+	}
+
+	items := make([]AppTrashedChallenge, len(challenges))
+	for i, chl := range challenges {
+		items[i] = toAppTrashedChallenge(chl, h.trash.PurgeAfter(chl.DeletedAt))
+	}
+
+	total, err := h.trash.CountChallenges(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("countchallenges: %w", err)
+	}
+
+	return web.Respond(ctx, w, paging.NewResponse(items, total, page.Number, page.RowsPerPage), http.StatusOK)
+}
+
+// RestoreIdea takes an idea of the authenticated user out of the trash.
+func (h *Handlers) RestoreIdea(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	ideaID, err := uuid.Parse(web.Param(r, "idea_id"))
+	if err != nil {
+		return v1.NewRequestError(err, http.StatusBadRequest)
+	}
+
+	userID, err := auth.GetUserID(ctx)
+	if err != nil {
+		return auth.NewAuthError("restoreidea: %s", err)
+	}
+
+	idr, err := h.idea.QueryDeletedByID(ctx, ideaID)
+	if err != nil {
+		if errors.Is(err, idea.ErrNotFound) {
+			return v1.NewRequestError(idea.ErrNotFound, http.StatusNotFound)
+		}
+		return fmt.Errorf("restoreidea: %w", err)
+	}
+
+	if idr.UserID != userID {
+		return v1.NewRequestError(idea.ErrNotFound, http.StatusNotFound)
+	}
+
+	if _, err := h.trash.RestoreIdea(ctx, idr); err != nil {
+		return toRequestError(err)
+	}
+
+	return web.Respond(ctx, w, nil, http.StatusNoContent)
+}
+
+// PurgeIdea removes an idea of the authenticated user from the trash for good,
+// together with all of its content.
+func (h *Handlers) PurgeIdea(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	ideaID, err := uuid.Parse(web.Param(r, "idea_id"))
+	if err != nil {
+		return v1.NewRequestError(err, http.StatusBadRequest)
+	}
+
+	userID, err := auth.GetUserID(ctx)
+	if err != nil {
+		return auth.NewAuthError("purgeidea: %s", err)
+	}
+
+	idr, err := h.idea.QueryDeletedByID(ctx, ideaID)
+	if err != nil {
+		if errors.Is(err, idea.ErrNotFound) {
+			return v1.NewRequestError(idea.ErrNotFound, http.StatusNotFound)
+		}
+		return fmt.Errorf("purgeidea: %w", err)
+	}
+
+	if idr.UserID != userID {
+		return v1.NewRequestError(idea.ErrNotFound, http.StatusNotFound)
+	}
+
+	files, err := h.trash.PurgeIdea(ctx, idr)
+	if err != nil {
+		if ce := idea.GetCascadeError(err); ce != nil {
+			h.log.Errorw("purgeidea", "ideaID", ideaID, "step", ce.Step, "ERROR", ce.Err)
+			return v1.NewRequestError(fmt.Errorf("deleting the idea failed at step %q, nothing was deleted", ce.Step), http.StatusInternalServerError)
+		}
+		return fmt.Errorf("purgeidea: ideaID[%s]: %w", ideaID, err)
+	}
+
+	// Files are only removed once the content using them is gone for good.
+	for _, file := range files {
+		if err := uploads.Remove(file); err != nil {
+			h.log.Errorw("purgeidea", "status", "removing file", "ideaID", ideaID, "file", file, "ERROR", err)
+		}
+	}
+
+	return web.Respond(ctx, w, nil, http.StatusNoContent)
+}
+
+// RestorePost takes a post out of the trash. The authenticated user must have
+// deleted or written the post, or own its idea, and the idea must not be in
+// the trash itself.
+func (h *Handlers) RestorePost(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	postID, err := uuid.Parse(web.Param(r, "post_id"))
+	if err != nil {
+		return v1.NewRequestError(err, http.StatusBadRequest)
+	}
+
+	userID, err := auth.GetUserID(ctx)
+	if err != nil {
+		return auth.NewAuthError("restorepost: %s", err)
+	}
+
+	pst, err := h.post.QueryDeletedByID(ctx, postID)
+	if err != nil {
+		if errors.Is(err, post.ErrNotFound) {
+			return v1.NewRequestError(post.ErrNotFound, http.StatusNotFound)
+		}
+		return fmt.Errorf("restorepost: %w", err)
+	}
+
+	idr, visible, err := h.visibleIdea(ctx, pst.IdeaID, userID)
+	if err != nil {
+		return fmt.Errorf("restorepost: %w", err)
+	}
+
+	canRestore := pst.DeletedBy == userID || pst.AuthorID == userID || idr.UserID == userID
+	if !canRestore || !visible {
+		return v1.NewRequestError(post.ErrNotFound, http.StatusNotFound)
+	}
+
+	if _, err := h.trash.RestorePost(ctx, pst); err != nil {
+		return toRequestError(err)
+	}
+
+	return web.Respond(ctx, w, nil, http.StatusNoContent)
+}
+
+// RestoreChallenge takes a challenge out of the trash. The authenticated user
+// must have deleted the challenge or own its idea, and the idea must not be
+// in the trash itself.
+func (h *Handlers) RestoreChallenge(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	challengeID, err := uuid.Parse(web.Param(r, "challenge_id"))
+	if err != nil {
+		return v1.NewRequestError(err, http.StatusBadRequest)
+	}
+
+	userID, err := auth.GetUserID(ctx)
+	if err != nil {
+		return auth.NewAuthError("restorechallenge: %s", err)
+	}
+
+	chl, err := h.challenge.QueryDeletedByID(ctx, challengeID)
+	if err != nil {
+		if errors.Is(err, challenge.ErrNotFound) {
+			return v1.NewRequestError(challenge.ErrNotFound, http.StatusNotFound)
+		}
+		return fmt.Errorf("restorechallenge: %w", err)
+	}
+
+	idr, visible, err := h.visibleIdea(ctx, chl.IdeaID, userID)
+	if err != nil {
+		return fmt.Errorf("restorechallenge: %w", err)
+	}
+
+	canRestore := chl.DeletedBy == userID || idr.UserID == userID
+	if !canRestore || !visible {
+		return v1.NewRequestError(challenge.ErrNotFound, http.StatusNotFound)
+	}
+
+	if _, err := h.trash.RestoreChallenge(ctx, chl); err != nil {
+		return toRequestError(err)
+	}
+
+	return web.Respond(ctx, w, nil, http.StatusNoContent)
+}
+
+// visibleIdea returns the idea the content in the trash belongs to, and
+// reports whether the user can still see it.
+func (h *Handlers) visibleIdea(ctx context.Context, ideaID uuid.UUID, userID uuid.UUID) (idea.Idea, bool, error) {
+	idr, err := h.idea.QueryVisibleByID(ctx, ideaID, userID)
+	if err != nil {
+		if errors.Is(err, idea.ErrNotFound) {
+			return idea.Idea{}, false, nil
+		}
+		return idea.Idea{}, false, err
+	}
+
+	return idr, true, nil
+}
+
+// toRequestError maps the trash errors to the matching HTTP status.
+func toRequestError(err error) error {
+	if errors.Is(err, trash.ErrExpired) {
+		return v1.NewRequestError(trash.ErrExpired, http.StatusGone)
+	}
+	return fmt.Errorf("restore: %w", err)
+}
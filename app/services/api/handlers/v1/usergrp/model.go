@@ -5,11 +5,15 @@ import (
 	"net/mail"
 	"time"
 
+	"github.com/dmanias/startupers/business/core/idea"
 	"github.com/dmanias/startupers/business/core/user"
 	"github.com/dmanias/startupers/business/sys/validate"
+	"github.com/dmanias/startupers/business/web/v1/paging"
+	"golang.org/x/text/language"
 )
 
-// AppUser represents information about an individual user.
+// AppUser represents information about an individual user, as the user
+// themselves sees it.
 type AppUser struct {
 	ID           string   `json:"id"`
 	Name         string   `json:"name"`
@@ -17,6 +21,12 @@ type AppUser struct {
 	Roles        []string `json:"roles"`
 	PasswordHash []byte   `json:"-"`
 	Enabled      bool     `json:"enabled"`
+	Verified     bool     `json:"verified"`
+	Bio          string   `json:"bio"`
+	Skills       []string `json:"skills"`
+	AvatarURL    string   `json:"avatarURL"`
+	Locale       string   `json:"locale"`
+	Timezone     string   `json:"timezone"`
 	DateCreated  string   `json:"dateCreated"`
 	DateUpdated  string   `json:"dateUpdated"`
 }
@@ -34,11 +44,84 @@ func toAppUser(usr user.User) AppUser {
 		Roles:        roles,
 		PasswordHash: usr.PasswordHash,
 		Enabled:      usr.Enabled,
+		Verified:     usr.Verified(),
+		Bio:          usr.Bio,
+		Skills:       skills(usr),
+		AvatarURL:    usr.AvatarURL,
+		Locale:       usr.Locale,
+		Timezone:     usr.Timezone,
 		DateCreated:  usr.DateCreated.Format(time.RFC3339),
 		DateUpdated:  usr.DateUpdated.Format(time.RFC3339),
 	}
 }
 
+// AppPublicUser represents what anyone can see about a user.
+type AppPublicUser struct {
+	ID          string   `json:"id"`
+	Name        string   `json:"name"`
+	Bio         string   `json:"bio"`
+	Skills      []string `json:"skills"`
+	AvatarURL   string   `json:"avatarURL"`
+	DateCreated string   `json:"dateCreated"`
+}
+
+func toAppPublicUser(usr user.User) AppPublicUser {
+	return AppPublicUser{
+		ID:          usr.ID.String(),
+		Name:        usr.Name,
+		Bio:         usr.Bio,
+		Skills:      skills(usr),
+		AvatarURL:   usr.AvatarURL,
+		DateCreated: usr.DateCreated.Format(time.RFC3339),
+	}
+}
+
+// AppPublicIdea represents a public idea shown on the profile of its owner.
+type AppPublicIdea struct {
+	ID          string   `json:"id"`
+	Title       string   `json:"title"`
+	Description string   `json:"description"`
+	Category    string   `json:"category"`
+	Tags        []string `json:"tags"`
+	Stage       string   `json:"stage"`
+	AvatarURL   string   `json:"avatarURL"`
+	VoteCount   int      `json:"voteCount"`
+	DateCreated string   `json:"dateCreated"`
+}
+
+func toAppPublicIdeas(ideas []idea.Idea) []AppPublicIdea {
+	items := make([]AppPublicIdea, len(ideas))
+	for i, idr := range ideas {
+		items[i] = AppPublicIdea{
+			ID:          idr.ID.String(),
+			Title:       idr.Title,
+			Description: idr.Description,
+			Category:    idr.Category,
+			Tags:        idr.Tags,
+			Stage:       idr.Stage.Name(),
+			AvatarURL:   idr.AvatarURL,
+			VoteCount:   idr.VoteCount,
+			DateCreated: idr.DateCreated.Format(time.RFC3339),
+		}
+	}
+	return items
+}
+
+// AppProfile represents the public profile of a user with their public
+// ideas, newest first, with paging.
+type AppProfile struct {
+	User  AppPublicUser                  `json:"user"`
+	Ideas paging.Response[AppPublicIdea] `json:"ideas"`
+}
+
+// skills returns the skills of the user, never nil so they encode as a list.
+func skills(usr user.User) []string {
+	if usr.Skills == nil {
+		return []string{}
+	}
+	return usr.Skills
+}
+
 // =============================================================================
 
 // AppNewUser contains information needed to create a new user.
@@ -137,3 +220,177 @@ func (app AppUpdateUser) Validate() error {
 	}
 	return nil
 }
+
+// =============================================================================
+
+// AppUpdateMe contains the changes a user makes to their own account. The
+// locale is a BCP 47 language tag, and the time zone an IANA name.
+type AppUpdateMe struct {
+	Name      *string  `json:"name" validate:"omitempty,min=1,max=100"`
+	Bio       *string  `json:"bio" validate:"omitempty,max=1000"`
+	Skills    []string `json:"skills" validate:"omitempty,max=20,dive,min=1,max=50"`
+	AvatarURL *string  `json:"avatarURL" validate:"omitempty,url"`
+	Locale    *string  `json:"locale"`
+	Timezone  *string  `json:"timezone"`
+}
+
+func toCoreUpdateMe(app AppUpdateMe) (user.UpdateUser, error) {
+	uu := user.UpdateUser{
+		Name:      app.Name,
+		Bio:       app.Bio,
+		Skills:    app.Skills,
+		AvatarURL: app.AvatarURL,
+	}
+
+	if app.Locale != nil {
+		locale := *app.Locale
+		if locale != "" {
+			tag, err := language.Parse(locale)
+			if err != nil {
+				return user.UpdateUser{}, validate.NewFieldsError("locale", err)
+			}
+			locale = tag.String()
+		}
+		uu.Locale = &locale
+	}
+
+	if app.Timezone != nil {
+		if tz := *app.Timezone; tz != "" {
+			if _, err := time.LoadLocation(tz); err != nil || tz == "Local" {
+				return user.UpdateUser{}, validate.NewFieldsError("timezone", fmt.Errorf("unknown time zone %q", tz))
+			}
+		}
+		uu.Timezone = app.Timezone
+	}
+
+	return uu, nil
+}
+
+// Validate checks the data in the model is considered clean.
+func (app AppUpdateMe) Validate() error {
+	if err := validate.Check(app); err != nil {
+		return err
+	}
+	return nil
+}
+
+// AppChangePassword contains the current password of a user and the new one.
+type AppChangePassword struct {
+	CurrentPassword string `json:"currentPassword" validate:"required"`
+	Password        string `json:"password" validate:"required"`
+	PasswordConfirm string `json:"passwordConfirm" validate:"eqfield=Password"`
+}
+
+// Validate checks the data in the model is considered clean.
+func (app AppChangePassword) Validate() error {
+	if err := validate.Check(app); err != nil {
+		return err
+	}
+	return nil
+}
+
+// =============================================================================
+
+// AppVerify contains the token emailed to verify an email address.
+type AppVerify struct {
+	Token string `json:"token" validate:"required"`
+}
+
+// Validate checks the data in the model is considered clean.
+func (app AppVerify) Validate() error {
+	if err := validate.Check(app); err != nil {
+		return err
+	}
+	return nil
+}
+
+// AppForgotPassword contains the email address of an account whose password
+// was forgotten.
+type AppForgotPassword struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+// Validate checks the data in the model is considered clean.
+func (app AppForgotPassword) Validate() error {
+	if err := validate.Check(app); err != nil {
+		return err
+	}
+	return nil
+}
+
+// AppResetPassword contains the token emailed to reset a password and the
+// new password.
+type AppResetPassword struct {
+	Token           string `json:"token" validate:"required"`
+	Password        string `json:"password" validate:"required"`
+	PasswordConfirm string `json:"passwordConfirm" validate:"eqfield=Password"`
+}
+
+// Validate checks the data in the model is considered clean.
+func (app AppResetPassword) Validate() error {
+	if err := validate.Check(app); err != nil {
+		return err
+	}
+	return nil
+}
+
+// =============================================================================
+
+// AppToken contains the tokens of a session: a short lived access token and
+// the refresh token to get the next one with.
+type AppToken struct {
+	Token        string `json:"token"`
+	RefreshToken string `json:"refreshToken"`
+}
+
+func toAppToken(token string, refreshToken string) AppToken {
+	return AppToken{
+		Token:        token,
+		RefreshToken: refreshToken,
+	}
+}
+
+// AppRefresh contains the refresh token of a session.
+type AppRefresh struct {
+	RefreshToken string `json:"refreshToken" validate:"required"`
+}
+
+// Validate checks the data in the model is considered clean.
+func (app AppRefresh) Validate() error {
+	if err := validate.Check(app); err != nil {
+		return err
+	}
+	return nil
+}
+
+// =============================================================================
+
+// AppProviders lists the OpenID Connect providers users can sign in with.
+type AppProviders struct {
+	Providers []string `json:"providers"`
+}
+
+func toAppProviders(providers []string) AppProviders {
+	return AppProviders{
+		Providers: providers,
+	}
+}
+
+// AppOIDCLogin contains the URL of a provider's sign in page.
+type AppOIDCLogin struct {
+	URL string `json:"url"`
+}
+
+// AppOIDCCallback contains what a provider sent the user back with.
+type AppOIDCCallback struct {
+	Code  string `json:"code" validate:"required"`
+	State string `json:"state" validate:"required"`
+}
+
+// Validate checks the data in the model is considered clean.
+func (app AppOIDCCallback) Validate() error {
+	if err := validate.Check(app); err != nil {
+		return err
+	}
+	return nil
+}
@@ -7,29 +7,54 @@ import (
 	"fmt"
 	"github.com/dmanias/startupers/business/web/auth"
 	"github.com/golang-jwt/jwt/v4"
+	"github.com/google/uuid"
 	"go.uber.org/zap"
+	"net"
 	"net/http"
 	"net/mail"
+	"strconv"
+	"strings"
 	"time"
 
+	"github.com/dmanias/startupers/business/core/account"
+	"github.com/dmanias/startupers/business/core/idea"
+	"github.com/dmanias/startupers/business/core/identity"
+	"github.com/dmanias/startupers/business/core/lockout"
+	"github.com/dmanias/startupers/business/core/session"
 	"github.com/dmanias/startupers/business/core/user"
+	"github.com/dmanias/startupers/business/data/order"
+	"github.com/dmanias/startupers/business/sys/validate"
 	v1 "github.com/dmanias/startupers/business/web/v1"
 	"github.com/dmanias/startupers/business/web/v1/paging"
 	"github.com/dmanias/startupers/foundation/web"
 )
 
+// accessTokenTTL is how long an access token can be used. Clients keep
+// their session going with the refresh token.
+const accessTokenTTL = time.Hour
+
 // Handlers manages the set of user endpoints.
 type Handlers struct {
 	user      *user.Core
+	account   *account.Core
+	session   *session.Core
+	identity  *identity.Core
+	idea      *idea.Core
+	lockout   *lockout.Core
 	auth      *auth.Auth
 	ActiveKID string
 	log       *zap.SugaredLogger
 }
 
 // New constructs a handlers for route access.
-func New(user *user.Core, auth *auth.Auth, activeKID string, log *zap.SugaredLogger) *Handlers {
+func New(user *user.Core, account *account.Core, session *session.Core, identity *identity.Core, idea *idea.Core, lockout *lockout.Core, auth *auth.Auth, activeKID string, log *zap.SugaredLogger) *Handlers {
 	return &Handlers{
 		user:      user,
+		account:   account,
+		session:   session,
+		identity:  identity,
+		idea:      idea,
+		lockout:   lockout,
 		auth:      auth,
 		ActiveKID: activeKID,
 		log:       log,
@@ -58,44 +83,233 @@ func (h *Handlers) Create(ctx context.Context, w http.ResponseWriter, r *http.Re
 		return fmt.Errorf("create: user[%+v]: %w", usr, err)
 	}
 
+	// The account exists either way, the user can ask for the email again.
+	if err := h.account.SendVerification(ctx, usr, requestLocale(r)); err != nil {
+		h.log.Errorw("create", "status", "sending verification", "userID", usr.ID, "ERROR", err)
+	}
+
 	return web.Respond(ctx, w, toAppUser(usr), http.StatusCreated)
 }
 
-// Query returns a list of users with paging.
-func (h *Handlers) Query(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
-	page, err := paging.ParseRequest(r)
+// Verify marks the email address the token was sent to as verified.
+func (h *Handlers) Verify(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	var app AppVerify
+	if err := web.Decode(r, &app); err != nil {
+		return err
+	}
+
+	usr, err := h.account.Verify(ctx, app.Token)
 	if err != nil {
+		if errors.Is(err, account.ErrInvalidToken) {
+			return v1.NewRequestError(account.ErrInvalidToken, http.StatusBadRequest)
+		}
+		return fmt.Errorf("verify: %w", err)
+	}
+
+	return web.Respond(ctx, w, toAppUser(usr), http.StatusOK)
+}
+
+// ResendVerification emails the authenticated user a new link to verify their
+// email address.
+func (h *Handlers) ResendVerification(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	userID, err := auth.GetUserID(ctx)
+	if err != nil {
+		return auth.NewAuthError("resendverification: %s", err)
+	}
+
+	usr, err := h.user.QueryByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, user.ErrNotFound) {
+			return v1.NewRequestError(user.ErrNotFound, http.StatusNotFound)
+		}
+		return fmt.Errorf("query: userID[%s]: %w", userID, err)
+	}
+
+	if err := h.account.SendVerification(ctx, usr, requestLocale(r)); err != nil {
+		if errors.Is(err, account.ErrAlreadyVerified) {
+			return v1.NewRequestError(account.ErrAlreadyVerified, http.StatusConflict)
+		}
+		return fmt.Errorf("sendverification: %w", err)
+	}
+
+	return web.Respond(ctx, w, nil, http.StatusAccepted)
+}
+
+// ForgotPassword emails a link to reset the password to the address, if it
+// belongs to an account. The response is the same either way, so it can't be
+// used to find out who has an account.
+func (h *Handlers) ForgotPassword(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	var app AppForgotPassword
+	if err := web.Decode(r, &app); err != nil {
 		return err
 	}
 
-	filter, err := parseFilter(r)
+	addr, err := mail.ParseAddress(app.Email)
 	if err != nil {
+		return validate.NewFieldsError("email", err)
+	}
+
+	if err := h.account.ForgotPassword(ctx, *addr, requestLocale(r)); err != nil {
+		return fmt.Errorf("forgotpassword: %w", err)
+	}
+
+	return web.Respond(ctx, w, nil, http.StatusAccepted)
+}
+
+// ResetPassword sets a new password for the account the token was sent to.
+// Every session of the user is ended.
+func (h *Handlers) ResetPassword(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	var app AppResetPassword
+	if err := web.Decode(r, &app); err != nil {
 		return err
 	}
 
-	orderBy, err := parseOrder(r)
+	if _, err := h.account.ResetPassword(ctx, app.Token, app.Password); err != nil {
+		if errors.Is(err, account.ErrInvalidToken) {
+			return v1.NewRequestError(account.ErrInvalidToken, http.StatusBadRequest)
+		}
+		return fmt.Errorf("resetpassword: %w", err)
+	}
+
+	return web.Respond(ctx, w, nil, http.StatusNoContent)
+}
+
+// QueryMe returns the account of the signed in user.
+func (h *Handlers) QueryMe(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	usr, err := h.me(ctx)
 	if err != nil {
 		return err
 	}
 
-	users, err := h.user.Query(ctx, filter, orderBy, page.Number, page.RowsPerPage)
+	return web.Respond(ctx, w, toAppUser(usr), http.StatusOK)
+}
+
+// UpdateMe updates the profile of the signed in user.
+func (h *Handlers) UpdateMe(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	var app AppUpdateMe
+	if err := web.Decode(r, &app); err != nil {
+		return err
+	}
+
+	uu, err := toCoreUpdateMe(app)
+	if err != nil {
+		return v1.NewRequestError(err, http.StatusBadRequest)
+	}
+
+	usr, err := h.me(ctx)
+	if err != nil {
+		return err
+	}
+
+	usr, err = h.user.Update(ctx, usr, uu)
+	if err != nil {
+		return fmt.Errorf("update: userID[%s]: %w", usr.ID, err)
+	}
+
+	return web.Respond(ctx, w, toAppUser(usr), http.StatusOK)
+}
+
+// ChangePassword sets a new password for the signed in user after checking
+// the current one. Every other session of the user is ended and a new one is
+// started for the caller.
+func (h *Handlers) ChangePassword(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	var app AppChangePassword
+	if err := web.Decode(r, &app); err != nil {
+		return err
+	}
+
+	usr, err := h.me(ctx)
+	if err != nil {
+		return err
+	}
+
+	usr, err = h.user.ChangePassword(ctx, usr, app.CurrentPassword, app.Password)
+	if err != nil {
+		if errors.Is(err, user.ErrAuthenticationFailure) {
+			return v1.NewRequestError(validate.NewFieldsError("currentPassword", errors.New("is incorrect")), http.StatusBadRequest)
+		}
+		return fmt.Errorf("changepassword: %w", err)
+	}
+
+	if err := h.session.RevokeUser(ctx, usr.ID); err != nil {
+		return fmt.Errorf("revokeuser: userID[%s]: %w", usr.ID, err)
+	}
+
+	tkn, err := h.startSession(ctx, r, usr)
+	if err != nil {
+		return err
+	}
+
+	return web.Respond(ctx, w, tkn, http.StatusOK)
+}
+
+// QueryProfile returns the public profile of a user with their public ideas.
+func (h *Handlers) QueryProfile(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	userID, err := uuid.Parse(web.Param(r, "user_id"))
+	if err != nil {
+		return v1.NewRequestError(err, http.StatusBadRequest)
+	}
+
+	page, err := paging.ParseRequest(r)
+	if err != nil {
+		return err
+	}
+
+	usr, err := h.user.QueryByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, user.ErrNotFound) {
+			return v1.NewRequestError(user.ErrNotFound, http.StatusNotFound)
+		}
+		return fmt.Errorf("querybyid: userID[%s]: %w", userID, err)
+	}
+
+	if !usr.Enabled {
+		return v1.NewRequestError(user.ErrNotFound, http.StatusNotFound)
+	}
+
+	var filter idea.QueryFilter
+	filter.WithUserID(usr.ID)
+	filter.WithPrivacy(idea.PrivacyPublic)
+
+	orderBy := order.NewBy(idea.OrderByDateCreated, order.DESC)
+
+	ideas, err := h.idea.Query(ctx, filter, orderBy, page.Number, page.RowsPerPage)
+	if err != nil {
+		return fmt.Errorf("query ideas: userID[%s]: %w", usr.ID, err)
+	}
+
+	total, err := h.idea.Count(ctx, filter)
 	if err != nil {
-		return fmt.Errorf("query: %w", err)
+		return fmt.Errorf("count ideas: userID[%s]: %w", usr.ID, err)
 	}
 
-	items := make([]AppUser, len(users))
-	for i, usr := range users {
-		items[i] = toAppUser(usr)
+	profile := AppProfile{
+		User:  toAppPublicUser(usr),
+		Ideas: paging.NewResponse(toAppPublicIdeas(ideas), total, page.Number, page.RowsPerPage),
 	}
 
-	total, err := h.user.Count(ctx, filter)
+	return web.Respond(ctx, w, profile, http.StatusOK)
+}
+
+// me returns the signed in user.
+func (h *Handlers) me(ctx context.Context) (user.User, error) {
+	userID, err := auth.GetUserID(ctx)
 	if err != nil {
-		return fmt.Errorf("count: %w", err)
+		return user.User{}, auth.NewAuthError("me: %s", err)
 	}
 
-	return web.Respond(ctx, w, paging.NewResponse(items, total, page.Number, page.RowsPerPage), http.StatusOK)
+	usr, err := h.user.QueryByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, user.ErrNotFound) {
+			return user.User{}, auth.NewAuthError("me: %s", user.ErrNotFound)
+		}
+		return user.User{}, fmt.Errorf("querybyid: userID[%s]: %w", userID, err)
+	}
+
+	return usr, nil
 }
 
+// Login signs a user in with their email and password and starts a session.
 func (h *Handlers) Login(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
 	var credentials struct {
 		Email    string `json:"email"`
@@ -107,35 +321,245 @@ func (h *Handlers) Login(ctx context.Context, w http.ResponseWriter, r *http.Req
 
 	email, err := mail.ParseAddress(credentials.Email)
 	if err != nil {
-		return fmt.Errorf("invalid email format: %w", err)
+		return v1.NewRequestError(fmt.Errorf("invalid email format: %w", err), http.StatusBadRequest)
 	}
 
+	// Repeated failures from the email or the IP address have to wait longer
+	// between attempts, and are locked out for a while past a threshold. The
+	// attempt is counted as a failure before the password is checked, and
+	// taken back if it turns out right.
+	ip := requestDevice(r).IPAddress
+
+	wait, err := h.lockout.Attempt(ctx, *email, ip)
+	if err != nil {
+		if errors.Is(err, lockout.ErrTooManyAttempts) {
+			w.Header().Set("Retry-After", strconv.Itoa(int((wait+time.Second-1)/time.Second)))
+			return v1.NewRequestError(lockout.ErrTooManyAttempts, http.StatusTooManyRequests)
+		}
+		return fmt.Errorf("attempt: %w", err)
+	}
+
+	// Unknown emails, wrong passwords and disabled accounts get the same
+	// answer, and all of them stay counted as a failure.
 	usr, err := h.user.Authenticate(ctx, *email, credentials.Password)
 	if err != nil {
-		return fmt.Errorf("authenticate: %w", err)
+		if !errors.Is(err, user.ErrAuthenticationFailure) {
+			return fmt.Errorf("authenticate: %w", err)
+		}
+		return auth.NewAuthError("login: %s", user.ErrAuthenticationFailure)
 	}
 
-	// Generate JWT token
-	// Create a Claims instance
-	claims := auth.Claims{
+	if !usr.Enabled {
+		return auth.NewAuthError("login: %s", user.ErrAuthenticationFailure)
+	}
+
+	if err := h.lockout.Succeed(ctx, *email, ip); err != nil {
+		return fmt.Errorf("succeed: %w", err)
+	}
+
+	tkn, err := h.startSession(ctx, r, usr)
+	if err != nil {
+		return err
+	}
+
+	return web.Respond(ctx, w, tkn, http.StatusOK)
+}
+
+// OIDCProviders returns the names of the OpenID Connect providers users can
+// sign in with.
+func (h *Handlers) OIDCProviders(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	return web.Respond(ctx, w, toAppProviders(h.identity.Providers()), http.StatusOK)
+}
+
+// OIDCLogin starts a sign in with the provider and returns the URL of the
+// provider's sign in page to send the user to.
+func (h *Handlers) OIDCLogin(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	provider := web.Param(r, "provider")
+
+	authURL, err := h.identity.Begin(ctx, provider)
+	if err != nil {
+		if errors.Is(err, identity.ErrUnknownProvider) {
+			return v1.NewRequestError(identity.ErrUnknownProvider, http.StatusNotFound)
+		}
+		return fmt.Errorf("begin: %w", err)
+	}
+
+	return web.Respond(ctx, w, AppOIDCLogin{URL: authURL}, http.StatusOK)
+}
+
+// OIDCCallback completes a sign in with the provider using the code and
+// state the provider sent the user back with, and starts a session.
+func (h *Handlers) OIDCCallback(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	var app AppOIDCCallback
+	if err := web.Decode(r, &app); err != nil {
+		return err
+	}
+
+	provider := web.Param(r, "provider")
+
+	usr, err := h.identity.Complete(ctx, provider, app.State, app.Code)
+	if err != nil {
+		switch {
+		case errors.Is(err, identity.ErrUnknownProvider):
+			return v1.NewRequestError(identity.ErrUnknownProvider, http.StatusNotFound)
+		case errors.Is(err, identity.ErrInvalidState):
+			return v1.NewRequestError(identity.ErrInvalidState, http.StatusBadRequest)
+		case errors.Is(err, identity.ErrEmailNotVerified):
+			return v1.NewRequestError(identity.ErrEmailNotVerified, http.StatusForbidden)
+		case errors.Is(err, identity.ErrSignInFailed):
+			h.log.Infow("oidccallback", "status", "sign in failed", "provider", provider, "ERROR", err)
+			return auth.NewAuthError("oidccallback: %s", identity.ErrSignInFailed)
+		case errors.Is(err, identity.ErrUserDisabled):
+			return auth.NewAuthError("oidccallback: %s", identity.ErrUserDisabled)
+		}
+		return fmt.Errorf("complete: %w", err)
+	}
+
+	tkn, err := h.startSession(ctx, r, usr)
+	if err != nil {
+		return err
+	}
+
+	return web.Respond(ctx, w, tkn, http.StatusOK)
+}
+
+// Refresh exchanges a refresh token for a new access token and a new refresh
+// token. A refresh token works once; using it again ends the session.
+func (h *Handlers) Refresh(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	var app AppRefresh
+	if err := web.Decode(r, &app); err != nil {
+		return err
+	}
+
+	// The access token ID is set before the refresh token is rotated, so
+	// revoking the session covers the access token issued with it.
+	accessTokenID := uuid.NewString()
+	accessTokenExpiry := time.Now().UTC().Add(accessTokenTTL)
+
+	nrt := session.NewRefreshToken{
+		Device:            requestDevice(r),
+		AccessTokenID:     accessTokenID,
+		AccessTokenExpiry: accessTokenExpiry,
+	}
+
+	rt, refreshToken, err := h.session.Rotate(ctx, app.RefreshToken, nrt)
+	if err != nil {
+		switch {
+		case errors.Is(err, session.ErrInvalidToken):
+			return auth.NewAuthError("refresh: %s", session.ErrInvalidToken)
+		case errors.Is(err, session.ErrTokenReused):
+			return auth.NewAuthError("refresh: %s", session.ErrTokenReused)
+		}
+		return fmt.Errorf("rotate: %w", err)
+	}
+
+	usr, err := h.user.QueryByID(ctx, rt.UserID)
+	if err != nil {
+		if errors.Is(err, user.ErrNotFound) {
+			return auth.NewAuthError("refresh: %s", user.ErrNotFound)
+		}
+		return fmt.Errorf("query: userID[%s]: %w", rt.UserID, err)
+	}
+
+	if !usr.Enabled {
+		if err := h.session.RevokeUser(ctx, usr.ID); err != nil {
+			return fmt.Errorf("revokeuser: %w", err)
+		}
+		return auth.NewAuthError("refresh: user disabled")
+	}
+
+	token, err := h.signToken(usr, accessTokenID, accessTokenExpiry)
+	if err != nil {
+		return err
+	}
+
+	return web.Respond(ctx, w, toAppToken(token, refreshToken), http.StatusOK)
+}
+
+// Logout ends the session of the refresh token. The access token issued with
+// it stops working too.
+func (h *Handlers) Logout(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	var app AppRefresh
+	if err := web.Decode(r, &app); err != nil {
+		return err
+	}
+
+	if err := h.session.Revoke(ctx, app.RefreshToken); err != nil {
+		return fmt.Errorf("revoke: %w", err)
+	}
+
+	return web.Respond(ctx, w, nil, http.StatusNoContent)
+}
+
+// startSession signs an access token for the user and issues the refresh
+// token of a new session.
+func (h *Handlers) startSession(ctx context.Context, r *http.Request, usr user.User) (AppToken, error) {
+	accessTokenID := uuid.NewString()
+	accessTokenExpiry := time.Now().UTC().Add(accessTokenTTL)
+
+	token, err := h.signToken(usr, accessTokenID, accessTokenExpiry)
+	if err != nil {
+		return AppToken{}, err
+	}
+
+	nrt := session.NewRefreshToken{
+		UserID:            usr.ID,
+		Device:            requestDevice(r),
+		AccessTokenID:     accessTokenID,
+		AccessTokenExpiry: accessTokenExpiry,
+	}
+
+	_, refreshToken, err := h.session.Issue(ctx, nrt)
+	if err != nil {
+		return AppToken{}, fmt.Errorf("issue: %w", err)
+	}
+
+	return toAppToken(token, refreshToken), nil
+}
+
+// signToken signs an access token for the user with the ID and expiry.
+func (h *Handlers) signToken(usr user.User, tokenID string, expiresAt time.Time) (string, error) {
+	token, err := h.auth.GenerateToken(h.ActiveKID, newClaims(usr, tokenID, expiresAt))
+	if err != nil {
+		return "", fmt.Errorf("generate token: %w", err)
+	}
+
+	return token, nil
+}
+
+// newClaims builds the claims of an access token for the user. The token ID
+// lets the token be revoked before it expires.
+func newClaims(usr user.User, tokenID string, expiresAt time.Time) auth.Claims {
+	return auth.Claims{
 		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        tokenID,
 			Subject:   usr.ID.String(),
 			Issuer:    "BackEnd",
-			ExpiresAt: jwt.NewNumericDate(time.Now().UTC().Add(time.Hour)),
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
 			IssuedAt:  jwt.NewNumericDate(time.Now().UTC()),
 		},
 		Roles:    usr.Roles,
 		UserName: usr.Name,
 	}
-	token, err := h.auth.GenerateToken(h.ActiveKID, claims)
+}
+
+// requestDevice describes the client making the request.
+func requestDevice(r *http.Request) session.Device {
+	ip, _, err := net.SplitHostPort(r.RemoteAddr)
 	if err != nil {
-		return fmt.Errorf("generate token: %w", err)
+		ip = r.RemoteAddr
 	}
 
-	// Return the token and user's name in the response
-	response := map[string]string{
-		"token": token,
+	return session.Device{
+		UserAgent: r.UserAgent(),
+		IPAddress: ip,
 	}
+}
 
-	return web.Respond(ctx, w, response, http.StatusOK)
+// requestLocale returns the language the client prefers most, from the
+// Accept-Language header.
+func requestLocale(r *http.Request) string {
+	locale, _, _ := strings.Cut(r.Header.Get("Accept-Language"), ",")
+	locale, _, _ = strings.Cut(locale, ";")
+	return strings.TrimSpace(locale)
 }
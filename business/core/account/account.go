@@ -0,0 +1,332 @@
+// Package account provides the business API for proving ownership of an
+// email address and for recovering an account whose password was forgotten.
+// Both work by emailing the user a secret token that can be used once before
+// it expires.
+package account
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/mail"
+	"net/url"
+	"time"
+
+	"github.com/dmanias/startupers/business/core/email"
+	"github.com/dmanias/startupers/business/core/user"
+	"github.com/dmanias/startupers/business/data/transaction"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// Set of how long the tokens can be used after they were sent.
+const (
+	VerifyEmailTTL   = 48 * time.Hour
+	PasswordResetTTL = time.Hour
+)
+
+// Set of error variables for CRUD operations.
+var (
+	ErrInvalidToken    = errors.New("token is invalid or has expired")
+	ErrAlreadyVerified = errors.New("email address is already verified")
+)
+
+// Storer interface declares the behavior this package needs to perists and
+// retrieve data.
+type Storer interface {
+	ExecuteUnderTransaction(tx transaction.Transaction) (Storer, error)
+	Create(ctx context.Context, tkn Token) error
+	Consume(ctx context.Context, tokenHash []byte, purpose Purpose, now time.Time) (Token, error)
+	DeleteUnused(ctx context.Context, userID uuid.UUID, purpose Purpose) error
+}
+
+// SessionRevoker ends the sessions a user has open, so a password reset logs
+// out whoever knew the old password.
+type SessionRevoker interface {
+	RevokeUser(ctx context.Context, userID uuid.UUID) error
+}
+
+// Core manages the set of APIs for account access.
+type Core struct {
+	log      *zap.SugaredLogger
+	storer   Storer
+	user     *user.Core
+	email    *email.Core
+	appURL   string
+	sessions []SessionRevoker
+}
+
+// NewCore constructs a core for account api access. The links in the emails
+// point to the web application at appURL.
+func NewCore(log *zap.SugaredLogger, storer Storer, userCore *user.Core, emailCore *email.Core, appURL string) *Core {
+	return &Core{
+		log:    log,
+		storer: storer,
+		user:   userCore,
+		email:  emailCore,
+		appURL: appURL,
+	}
+}
+
+// ExecuteUnderTransaction constructs a new Core value that will use the
+// specified transaction in any store related calls, including those of the
+// user and email cores. The session revokers keep their own connection.
+func (c *Core) ExecuteUnderTransaction(tx transaction.Transaction) (*Core, error) {
+	trS, err := c.storer.ExecuteUnderTransaction(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	userCore, err := c.user.ExecuteUnderTransaction(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	emailCore, err := c.email.ExecuteUnderTransaction(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	c = &Core{
+		log:      c.log,
+		storer:   trS,
+		user:     userCore,
+		email:    emailCore,
+		appURL:   c.appURL,
+		sessions: c.sessions,
+	}
+
+	return c, nil
+}
+
+// AddSessionRevoker registers what ends the sessions of a user whose password
+// was reset. It is meant to be called while the application is being wired
+// up, before the core is in use.
+func (c *Core) AddSessionRevoker(revoker SessionRevoker) {
+	c.sessions = append(c.sessions, revoker)
+}
+
+// SendVerification emails the user a link to verify their email address. Any
+// link sent before stops working.
+func (c *Core) SendVerification(ctx context.Context, usr user.User, locale string) error {
+	if usr.Verified() {
+		return ErrAlreadyVerified
+	}
+
+	token, err := c.create(ctx, usr, PurposeVerifyEmail, VerifyEmailTTL)
+	if err != nil {
+		return fmt.Errorf("sendverification: userID[%s]: %w", usr.ID, err)
+	}
+
+	if err := c.send(ctx, usr, "verify_email", locale, "/verify", token); err != nil {
+		return fmt.Errorf("sendverification: userID[%s]: %w", usr.ID, err)
+	}
+
+	return nil
+}
+
+// Verify marks the email address the token was sent to as verified.
+func (c *Core) Verify(ctx context.Context, token string) (user.User, error) {
+	usr, err := c.consume(ctx, token, PurposeVerifyEmail)
+	if err != nil {
+		return user.User{}, fmt.Errorf("verify: %w", err)
+	}
+
+	if usr.Verified() {
+		return usr, nil
+	}
+
+	usr, err = c.user.MarkVerified(ctx, usr)
+	if err != nil {
+		return user.User{}, fmt.Errorf("verify: userID[%s]: %w", usr.ID, err)
+	}
+
+	return usr, nil
+}
+
+// ForgotPassword emails the user with the address a link to choose a new
+// password. Nothing is sent, and no error returned, when there is no enabled
+// user with the address, so the response doesn't reveal who has an account.
+func (c *Core) ForgotPassword(ctx context.Context, addr mail.Address, locale string) error {
+	usr, err := c.user.QueryByEmail(ctx, addr)
+	if err != nil {
+		if errors.Is(err, user.ErrNotFound) {
+			return nil
+		}
+		return fmt.Errorf("forgotpassword: %w", err)
+	}
+
+	if !usr.Enabled {
+		return nil
+	}
+
+	token, err := c.create(ctx, usr, PurposePasswordReset, PasswordResetTTL)
+	if err != nil {
+		return fmt.Errorf("forgotpassword: userID[%s]: %w", usr.ID, err)
+	}
+
+	if err := c.send(ctx, usr, "password_reset", locale, "/reset-password", token); err != nil {
+		return fmt.Errorf("forgotpassword: userID[%s]: %w", usr.ID, err)
+	}
+
+	return nil
+}
+
+// ResetPassword sets the password of the user the token was sent to, and
+// ends every session the user had open.
+func (c *Core) ResetPassword(ctx context.Context, token string, password string) (user.User, error) {
+	usr, err := c.consume(ctx, token, PurposePasswordReset)
+	if err != nil {
+		return user.User{}, fmt.Errorf("resetpassword: %w", err)
+	}
+
+	usr, err = c.user.Update(ctx, usr, user.UpdateUser{Password: &password})
+	if err != nil {
+		return user.User{}, fmt.Errorf("resetpassword: userID[%s]: %w", usr.ID, err)
+	}
+
+	// Any other reset link that was sent is no longer needed.
+	if err := c.storer.DeleteUnused(ctx, usr.ID, PurposePasswordReset); err != nil {
+		return user.User{}, fmt.Errorf("resetpassword: userID[%s]: %w", usr.ID, err)
+	}
+
+	for _, revoker := range c.sessions {
+		if err := revoker.RevokeUser(ctx, usr.ID); err != nil {
+			return user.User{}, fmt.Errorf("resetpassword: revoking sessions: userID[%s]: %w", usr.ID, err)
+		}
+	}
+
+	return usr, nil
+}
+
+// ForcePasswordReset replaces the password of the user with one nobody knows,
+// ends every session the user has open and emails them a link to choose a
+// new password. Administrators use it when an account may be compromised.
+func (c *Core) ForcePasswordReset(ctx context.Context, usr user.User) error {
+	password, _, err := newToken()
+	if err != nil {
+		return fmt.Errorf("forcepasswordreset: userID[%s]: %w", usr.ID, err)
+	}
+
+	if _, err := c.user.Update(ctx, usr, user.UpdateUser{Password: &password}); err != nil {
+		return fmt.Errorf("forcepasswordreset: userID[%s]: %w", usr.ID, err)
+	}
+
+	for _, revoker := range c.sessions {
+		if err := revoker.RevokeUser(ctx, usr.ID); err != nil {
+			return fmt.Errorf("forcepasswordreset: revoking sessions: userID[%s]: %w", usr.ID, err)
+		}
+	}
+
+	token, err := c.create(ctx, usr, PurposePasswordReset, PasswordResetTTL)
+	if err != nil {
+		return fmt.Errorf("forcepasswordreset: userID[%s]: %w", usr.ID, err)
+	}
+
+	if err := c.send(ctx, usr, "password_reset", usr.Locale, "/reset-password", token); err != nil {
+		return fmt.Errorf("forcepasswordreset: userID[%s]: %w", usr.ID, err)
+	}
+
+	return nil
+}
+
+// =============================================================================
+
+// create stores a new token for the user, replacing the unused ones with the
+// same purpose, and returns the secret.
+func (c *Core) create(ctx context.Context, usr user.User, purpose Purpose, ttl time.Duration) (string, error) {
+	if err := c.storer.DeleteUnused(ctx, usr.ID, purpose); err != nil {
+		return "", fmt.Errorf("deleteunused: %w", err)
+	}
+
+	token, tokenHash, err := newToken()
+	if err != nil {
+		return "", err
+	}
+
+	now := time.Now()
+
+	tkn := Token{
+		ID:          uuid.New(),
+		UserID:      usr.ID,
+		Purpose:     purpose,
+		Email:       usr.Email,
+		TokenHash:   tokenHash,
+		DateExpires: now.Add(ttl),
+		DateCreated: now,
+	}
+
+	if err := c.storer.Create(ctx, tkn); err != nil {
+		return "", fmt.Errorf("create: %w", err)
+	}
+
+	return token, nil
+}
+
+// consume uses up the token and returns the user it was sent to. A token sent
+// to an address the user has since changed is no longer valid.
+func (c *Core) consume(ctx context.Context, token string, purpose Purpose) (user.User, error) {
+	tkn, err := c.storer.Consume(ctx, hashToken(token), purpose, time.Now())
+	if err != nil {
+		return user.User{}, err
+	}
+
+	usr, err := c.user.QueryByID(ctx, tkn.UserID)
+	if err != nil {
+		return user.User{}, err
+	}
+
+	if usr.Email.Address != tkn.Email.Address {
+		return user.User{}, ErrInvalidToken
+	}
+
+	return usr, nil
+}
+
+// send queues the email carrying the link with the token.
+func (c *Core) send(ctx context.Context, usr user.User, template string, locale string, path string, token string) error {
+	link := c.appURL + path + "?" + url.Values{"token": {token}}.Encode()
+
+	// The language the user picked wins over the one of the request.
+	if usr.Locale != "" {
+		locale = usr.Locale
+	}
+
+	ne := email.NewEmail{
+		To:       mail.Address{Name: usr.Name, Address: usr.Email.Address},
+		Template: template,
+		Locale:   locale,
+		Data: struct {
+			Name string
+			Link string
+		}{
+			Name: usr.Name,
+			Link: link,
+		},
+	}
+
+	if _, err := c.email.Enqueue(ctx, ne); err != nil {
+		return fmt.Errorf("enqueue: %w", err)
+	}
+
+	return nil
+}
+
+func newToken() (string, []byte, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", nil, fmt.Errorf("generating token: %w", err)
+	}
+
+	token := base64.RawURLEncoding.EncodeToString(b)
+
+	return token, hashToken(token), nil
+}
+
+func hashToken(token string) []byte {
+	sum := sha256.Sum256([]byte(token))
+	return sum[:]
+}
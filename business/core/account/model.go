@@ -0,0 +1,21 @@
+package account
+
+import (
+	"net/mail"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Token is a secret sent to the email address of a user that lets them prove
+// they own it. Only the hash of the secret is kept, and it can be used once.
+type Token struct {
+	ID          uuid.UUID
+	UserID      uuid.UUID
+	Purpose     Purpose
+	Email       mail.Address
+	TokenHash   []byte
+	DateExpires time.Time
+	DateUsed    time.Time
+	DateCreated time.Time
+}
@@ -0,0 +1,62 @@
+package account
+
+import "errors"
+
+// Set of things a token can be used for.
+var (
+	PurposeVerifyEmail   = Purpose{"verify_email"}
+	PurposePasswordReset = Purpose{"password_reset"}
+)
+
+// Set of known purposes.
+var purposes = map[string]Purpose{
+	PurposeVerifyEmail.name:   PurposeVerifyEmail,
+	PurposePasswordReset.name: PurposePasswordReset,
+}
+
+// Purpose represents what a token can be used for.
+type Purpose struct {
+	name string
+}
+
+// ParsePurpose parses the string value and returns a purpose if one exists.
+func ParsePurpose(value string) (Purpose, error) {
+	purpose, exists := purposes[value]
+	if !exists {
+		return Purpose{}, errors.New("invalid token purpose")
+	}
+
+	return purpose, nil
+}
+
+// MustParsePurpose parses the string value and returns a purpose if one
+// exists. If an error occurs the function panics.
+func MustParsePurpose(value string) Purpose {
+	purpose, err := ParsePurpose(value)
+	if err != nil {
+		panic(err)
+	}
+
+	return purpose
+}
+
+// Name returns the name of the purpose.
+func (p Purpose) Name() string {
+	return p.name
+}
+
+// UnmarshalText implement the unmarshal interface for JSON conversions.
+func (p *Purpose) UnmarshalText(data []byte) error {
+	p.name = string(data)
+	return nil
+}
+
+// MarshalText implement the marshal interface for JSON conversions.
+func (p Purpose) MarshalText() ([]byte, error) {
+	return []byte(p.name), nil
+}
+
+// Equal provides support for the go-cmp package and testing.
+func (p Purpose) Equal(p2 Purpose) bool {
+	return p.name == p2.name
+}
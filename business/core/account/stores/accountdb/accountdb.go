@@ -0,0 +1,126 @@
+// Package accountdb contains account token related CRUD functionality.
+package accountdb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/dmanias/startupers/business/core/account"
+	"github.com/dmanias/startupers/business/data/sqldb"
+	"github.com/dmanias/startupers/business/data/transaction"
+	database "github.com/dmanias/startupers/business/sys/database/pgx"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"go.uber.org/zap"
+)
+
+// Store manages the set of APIs for account token database access.
+type Store struct {
+	log *zap.SugaredLogger
+	db  sqlx.ExtContext
+}
+
+// NewStore constructs the api for data access.
+func NewStore(log *zap.SugaredLogger, db *sqlx.DB) *Store {
+	return &Store{
+		log: log,
+		db:  db,
+	}
+}
+
+// ExecuteUnderTransaction constructs a new Store value replacing the sqlx DB
+// value with a sqlx DB value that is currently inside a transaction.
+func (s *Store) ExecuteUnderTransaction(tx transaction.Transaction) (account.Storer, error) {
+	ec, err := sqldb.GetExtContext(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	s = &Store{
+		log: s.log,
+		db:  ec,
+	}
+
+	return s, nil
+}
+
+// Create inserts a new token into the database.
+func (s *Store) Create(ctx context.Context, tkn account.Token) error {
+	const q = `
+	INSERT INTO account_tokens
+		(id, user_id, purpose, email, token_hash, date_expires, date_used, date_created)
+	VALUES
+		(:id, :user_id, :purpose, :email, :token_hash, :date_expires, :date_used, :date_created)`
+
+	if err := database.NamedExecContext(ctx, s.log, s.db, q, toDBToken(tkn)); err != nil {
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	return nil
+}
+
+// Consume marks the unused, unexpired token with the hash as used and returns
+// it. Marking and checking happen in one statement, so a token can't be used
+// twice by concurrent requests.
+func (s *Store) Consume(ctx context.Context, tokenHash []byte, purpose account.Purpose, now time.Time) (account.Token, error) {
+	data := struct {
+		TokenHash []byte    `db:"token_hash"`
+		Purpose   string    `db:"purpose"`
+		Now       time.Time `db:"now"`
+	}{
+		TokenHash: tokenHash,
+		Purpose:   purpose.Name(),
+		Now:       now.UTC(),
+	}
+
+	const q = `
+	UPDATE
+		account_tokens
+	SET
+		"date_used" = :now
+	WHERE
+		token_hash = :token_hash AND
+		purpose = :purpose AND
+		date_used IS NULL AND
+		date_expires > :now
+	RETURNING
+		*`
+
+	var dbTkn dbToken
+	if err := database.NamedQueryStruct(ctx, s.log, s.db, q, data, &dbTkn); err != nil {
+		if errors.Is(err, database.ErrDBNotFound) {
+			return account.Token{}, fmt.Errorf("namedquerystruct: %w", account.ErrInvalidToken)
+		}
+		return account.Token{}, fmt.Errorf("namedquerystruct: %w", err)
+	}
+
+	return toCoreToken(dbTkn), nil
+}
+
+// DeleteUnused removes the tokens of the user for the purpose that haven't
+// been used.
+func (s *Store) DeleteUnused(ctx context.Context, userID uuid.UUID, purpose account.Purpose) error {
+	data := struct {
+		UserID  uuid.UUID `db:"user_id"`
+		Purpose string    `db:"purpose"`
+	}{
+		UserID:  userID,
+		Purpose: purpose.Name(),
+	}
+
+	const q = `
+	DELETE FROM
+		account_tokens
+	WHERE
+		user_id = :user_id AND
+		purpose = :purpose AND
+		date_used IS NULL`
+
+	if err := database.NamedExecContext(ctx, s.log, s.db, q, data); err != nil {
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,57 @@
+package accountdb
+
+import (
+	"database/sql"
+	"net/mail"
+	"time"
+
+	"github.com/dmanias/startupers/business/core/account"
+	"github.com/google/uuid"
+)
+
+// dbToken represent the structure we need for moving data
+// between the app and the database.
+type dbToken struct {
+	ID          uuid.UUID    `db:"id"`
+	UserID      uuid.UUID    `db:"user_id"`
+	Purpose     string       `db:"purpose"`
+	Email       string       `db:"email"`
+	TokenHash   []byte       `db:"token_hash"`
+	DateExpires time.Time    `db:"date_expires"`
+	DateUsed    sql.NullTime `db:"date_used"`
+	DateCreated time.Time    `db:"date_created"`
+}
+
+func toDBToken(tkn account.Token) dbToken {
+	return dbToken{
+		ID:          tkn.ID,
+		UserID:      tkn.UserID,
+		Purpose:     tkn.Purpose.Name(),
+		Email:       tkn.Email.Address,
+		TokenHash:   tkn.TokenHash,
+		DateExpires: tkn.DateExpires.UTC(),
+		DateUsed: sql.NullTime{
+			Time:  tkn.DateUsed.UTC(),
+			Valid: !tkn.DateUsed.IsZero(),
+		},
+		DateCreated: tkn.DateCreated.UTC(),
+	}
+}
+
+func toCoreToken(dbTkn dbToken) account.Token {
+	var dateUsed time.Time
+	if dbTkn.DateUsed.Valid {
+		dateUsed = dbTkn.DateUsed.Time.In(time.Local)
+	}
+
+	return account.Token{
+		ID:          dbTkn.ID,
+		UserID:      dbTkn.UserID,
+		Purpose:     account.MustParsePurpose(dbTkn.Purpose),
+		Email:       mail.Address{Address: dbTkn.Email},
+		TokenHash:   dbTkn.TokenHash,
+		DateExpires: dbTkn.DateExpires.In(time.Local),
+		DateUsed:    dateUsed,
+		DateCreated: dbTkn.DateCreated.In(time.Local),
+	}
+}
@@ -0,0 +1,83 @@
+// Package activity provides the business API for the activity feed. The idea,
+// post, challenge and invitation cores record what happens as it happens, and
+// each user reads the activity of the ideas and users they follow.
+package activity
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// Storer interface declares the behavior this package needs to perists and
+// retrieve data.
+type Storer interface {
+	Create(ctx context.Context, act Activity) error
+	QueryFeed(ctx context.Context, userID uuid.UUID, cursor Cursor, limit int) ([]Activity, error)
+}
+
+// Core manages the set of APIs for activity access.
+type Core struct {
+	log    *zap.SugaredLogger
+	storer Storer
+}
+
+// NewCore constructs a core for activity api access.
+func NewCore(log *zap.SugaredLogger, storer Storer) *Core {
+	return &Core{
+		log:    log,
+		storer: storer,
+	}
+}
+
+// Record adds an activity to the feed. Activity that doesn't belong in the
+// feed is ignored. Recording is best effort: the write that caused the
+// activity has already happened, so a failure is logged rather than returned.
+func (c *Core) Record(ctx context.Context, na NewActivity) {
+	if !feedTypes[na.Type] {
+		return
+	}
+
+	details := na.Details
+	if details == nil {
+		details = map[string]string{}
+	}
+
+	act := Activity{
+		ID:          uuid.New(),
+		Type:        na.Type,
+		ActorID:     na.ActorID,
+		IdeaID:      na.IdeaID,
+		SubjectID:   na.SubjectID,
+		Details:     details,
+		DateCreated: time.Now(),
+	}
+
+	if err := c.storer.Create(ctx, act); err != nil {
+		c.log.Errorw("activity", "status", "recording activity", "type", act.Type.Name(), "ideaID", act.IdeaID, "ERROR", err)
+	}
+}
+
+// QueryFeed returns a page of the activity on the ideas and by the users the
+// user follows, newest first, along with the cursor for the next page. The
+// cursor is zero on the last page. Activity on ideas the user can't see, or
+// that are in the trash, is left out.
+func (c *Core) QueryFeed(ctx context.Context, userID uuid.UUID, cursor Cursor, limit int) ([]Activity, Cursor, error) {
+	// Ask for one more row to know whether there is a next page.
+	acts, err := c.storer.QueryFeed(ctx, userID, cursor, limit+1)
+	if err != nil {
+		return nil, Cursor{}, fmt.Errorf("queryfeed: userID[%s]: %w", userID, err)
+	}
+
+	var next Cursor
+	if len(acts) > limit {
+		acts = acts[:limit]
+		last := acts[len(acts)-1]
+		next = Cursor{DateCreated: last.DateCreated, ActivityID: last.ID}
+	}
+
+	return acts, next, nil
+}
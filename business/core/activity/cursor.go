@@ -0,0 +1,66 @@
+package activity
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ErrInvalidCursor is returned when a cursor can't be decoded.
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// Cursor marks the position in the feed after which the next page starts.
+// The zero value starts from the newest activity.
+type Cursor struct {
+	DateCreated time.Time
+	ActivityID  uuid.UUID
+}
+
+// IsZero reports whether the cursor points to the top of the feed.
+func (c Cursor) IsZero() bool {
+	return c.ActivityID == uuid.Nil
+}
+
+// Encode returns the opaque string representation of the cursor.
+func (c Cursor) Encode() string {
+	if c.IsZero() {
+		return ""
+	}
+
+	s := c.DateCreated.UTC().Format(time.RFC3339Nano) + "|" + c.ActivityID.String()
+	return base64.RawURLEncoding.EncodeToString([]byte(s))
+}
+
+// ParseCursor decodes a cursor previously returned by Encode. An empty string
+// is the cursor for the top of the feed.
+func ParseCursor(value string) (Cursor, error) {
+	if value == "" {
+		return Cursor{}, nil
+	}
+
+	b, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return Cursor{}, ErrInvalidCursor
+	}
+
+	date, id, found := strings.Cut(string(b), "|")
+	if !found {
+		return Cursor{}, ErrInvalidCursor
+	}
+
+	d, err := time.Parse(time.RFC3339Nano, date)
+	if err != nil {
+		return Cursor{}, fmt.Errorf("%w: %s", ErrInvalidCursor, err)
+	}
+
+	activityID, err := uuid.Parse(id)
+	if err != nil {
+		return Cursor{}, fmt.Errorf("%w: %s", ErrInvalidCursor, err)
+	}
+
+	return Cursor{DateCreated: d, ActivityID: activityID}, nil
+}
@@ -0,0 +1,30 @@
+package activity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Activity records something a user did on an idea. The subject is the post
+// or challenge involved, if any. Details hold what is specific to the type,
+// such as the stages of a stage change.
+type Activity struct {
+	ID          uuid.UUID
+	Type        Type
+	ActorID     uuid.UUID
+	IdeaID      uuid.UUID
+	SubjectID   uuid.UUID
+	Details     map[string]string
+	DateCreated time.Time
+}
+
+// NewActivity is what the cores provide when something worth following
+// happens.
+type NewActivity struct {
+	Type      Type
+	ActorID   uuid.UUID
+	IdeaID    uuid.UUID
+	SubjectID uuid.UUID
+	Details   map[string]string
+}
@@ -0,0 +1,98 @@
+// Package activitydb contains activity related CRUD functionality.
+package activitydb
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/dmanias/startupers/business/core/activity"
+	"github.com/dmanias/startupers/business/core/follow"
+	"github.com/dmanias/startupers/business/core/idea/stores/ideadb"
+	database "github.com/dmanias/startupers/business/sys/database/pgx"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"go.uber.org/zap"
+)
+
+// Store manages the set of APIs for activity database access.
+type Store struct {
+	log *zap.SugaredLogger
+	db  sqlx.ExtContext
+}
+
+// NewStore constructs the api for data access.
+func NewStore(log *zap.SugaredLogger, db *sqlx.DB) *Store {
+	return &Store{
+		log: log,
+		db:  db,
+	}
+}
+
+// Create inserts a new activity into the database.
+func (s *Store) Create(ctx context.Context, act activity.Activity) error {
+	dbAct, err := toDBActivity(act)
+	if err != nil {
+		return err
+	}
+
+	const q = `
+	INSERT INTO activities
+		(id, type, actor_id, idea_id, subject_id, details, date_created)
+	VALUES
+		(:id, :type, :actor_id, :idea_id, :subject_id, CAST(:details AS JSONB), :date_created)`
+
+	if err := database.NamedExecContext(ctx, s.log, s.db, q, dbAct); err != nil {
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	return nil
+}
+
+// QueryFeed retrieves the activity on the ideas and by the users the user
+// follows, newest first. The privacy of the ideas is checked as the feed is
+// read, so activity disappears from the feed when its idea is made private or
+// moved to the trash. The user's own activity is left out.
+func (s *Store) QueryFeed(ctx context.Context, userID uuid.UUID, cursor activity.Cursor, limit int) ([]activity.Activity, error) {
+	data := map[string]interface{}{
+		"viewer_id":   userID,
+		"target_idea": follow.TargetTypeIdea.Name(),
+		"target_user": follow.TargetTypeUser.Name(),
+		"limit":       limit,
+	}
+
+	const q = `
+	SELECT
+		a.id, a.type, a.actor_id, a.idea_id, a.subject_id, CAST(a.details AS TEXT) AS details, a.date_created
+	FROM
+		activities AS a
+	JOIN
+		ideas AS i ON i.id = a.idea_id`
+
+	wc := []string{
+		"i.deleted_at IS NULL",
+		ideadb.VisibleClause("i", userID, data),
+		"a.actor_id <> :viewer_id",
+		`(a.idea_id IN (SELECT target_id FROM follows WHERE user_id = :viewer_id AND target_type = :target_idea) OR
+			a.actor_id IN (SELECT target_id FROM follows WHERE user_id = :viewer_id AND target_type = :target_user))`,
+	}
+
+	if !cursor.IsZero() {
+		data["cursor_date_created"] = cursor.DateCreated.UTC()
+		data["cursor_activity_id"] = cursor.ActivityID
+		wc = append(wc, "(a.date_created, a.id) < (:cursor_date_created, :cursor_activity_id)")
+	}
+
+	buf := bytes.NewBufferString(q)
+	buf.WriteString(" WHERE ")
+	buf.WriteString(strings.Join(wc, " AND "))
+	buf.WriteString(" ORDER BY a.date_created DESC, a.id DESC FETCH FIRST :limit ROWS ONLY")
+
+	var dbActs []dbActivity
+	if err := database.NamedQuerySlice(ctx, s.log, s.db, buf.String(), data, &dbActs); err != nil {
+		return nil, fmt.Errorf("namedqueryslice: %w", err)
+	}
+
+	return toCoreActivitySlice(dbActs), nil
+}
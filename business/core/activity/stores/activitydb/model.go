@@ -0,0 +1,65 @@
+package activitydb
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/dmanias/startupers/business/core/activity"
+	"github.com/google/uuid"
+)
+
+// dbActivity represent the structure we need for moving data
+// between the app and the database.
+type dbActivity struct {
+	ID          uuid.UUID     `db:"id"`
+	Type        string        `db:"type"`
+	ActorID     uuid.UUID     `db:"actor_id"`
+	IdeaID      uuid.UUID     `db:"idea_id"`
+	SubjectID   uuid.NullUUID `db:"subject_id"`
+	Details     string        `db:"details"`
+	DateCreated time.Time     `db:"date_created"`
+}
+
+func toDBActivity(act activity.Activity) (dbActivity, error) {
+	details, err := json.Marshal(act.Details)
+	if err != nil {
+		return dbActivity{}, fmt.Errorf("marshal details: %w", err)
+	}
+
+	return dbActivity{
+		ID:      act.ID,
+		Type:    act.Type.Name(),
+		ActorID: act.ActorID,
+		IdeaID:  act.IdeaID,
+		SubjectID: uuid.NullUUID{
+			UUID:  act.SubjectID,
+			Valid: act.SubjectID != uuid.Nil,
+		},
+		Details:     string(details),
+		DateCreated: act.DateCreated.UTC(),
+	}, nil
+}
+
+func toCoreActivity(dbAct dbActivity) activity.Activity {
+	var details map[string]string
+	_ = json.Unmarshal([]byte(dbAct.Details), &details)
+
+	return activity.Activity{
+		ID:          dbAct.ID,
+		Type:        activity.MustParseType(dbAct.Type),
+		ActorID:     dbAct.ActorID,
+		IdeaID:      dbAct.IdeaID,
+		SubjectID:   dbAct.SubjectID.UUID,
+		Details:     details,
+		DateCreated: dbAct.DateCreated.In(time.Local),
+	}
+}
+
+func toCoreActivitySlice(dbActs []dbActivity) []activity.Activity {
+	acts := make([]activity.Activity, len(dbActs))
+	for i, dbAct := range dbActs {
+		acts[i] = toCoreActivity(dbAct)
+	}
+	return acts
+}
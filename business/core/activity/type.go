@@ -0,0 +1,78 @@
+package activity
+
+import "errors"
+
+// Set of things that happen and can be recorded. All but the invitations show
+// up in the activity feed.
+var (
+	TypeIdeaCreated        = Type{"idea_created"}
+	TypePostCreated        = Type{"post_created"}
+	TypeStageChanged       = Type{"stage_changed"}
+	TypeChallengeCompleted = Type{"challenge_completed"}
+	TypeInvitationCreated  = Type{"invitation_created"}
+)
+
+// Set of known activity types.
+var types = map[string]Type{
+	TypeIdeaCreated.name:        TypeIdeaCreated,
+	TypePostCreated.name:        TypePostCreated,
+	TypeStageChanged.name:       TypeStageChanged,
+	TypeChallengeCompleted.name: TypeChallengeCompleted,
+	TypeInvitationCreated.name:  TypeInvitationCreated,
+}
+
+// Set of activity types that show up in the activity feed. Invitations are
+// private to the invitee, so they are only ever notified.
+var feedTypes = map[Type]bool{
+	TypeIdeaCreated:        true,
+	TypePostCreated:        true,
+	TypeStageChanged:       true,
+	TypeChallengeCompleted: true,
+}
+
+// Type represents the kind of an activity.
+type Type struct {
+	name string
+}
+
+// ParseType parses the string value and returns a type if one exists.
+func ParseType(value string) (Type, error) {
+	typ, exists := types[value]
+	if !exists {
+		return Type{}, errors.New("invalid activity type")
+	}
+
+	return typ, nil
+}
+
+// MustParseType parses the string value and returns a type if one exists. If
+// an error occurs the function panics.
+func MustParseType(value string) Type {
+	typ, err := ParseType(value)
+	if err != nil {
+		panic(err)
+	}
+
+	return typ
+}
+
+// Name returns the name of the type.
+func (t Type) Name() string {
+	return t.name
+}
+
+// UnmarshalText implement the unmarshal interface for JSON conversions.
+func (t *Type) UnmarshalText(data []byte) error {
+	t.name = string(data)
+	return nil
+}
+
+// MarshalText implement the marshal interface for JSON conversions.
+func (t Type) MarshalText() ([]byte, error) {
+	return []byte(t.name), nil
+}
+
+// Equal provides support for the go-cmp package and testing.
+func (t Type) Equal(t2 Type) bool {
+	return t.name == t2.name
+}
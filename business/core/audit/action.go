@@ -0,0 +1,70 @@
+package audit
+
+import "errors"
+
+// Set of actions administrators take that are recorded.
+var (
+	ActionUserEnabled       = Action{"user_enabled"}
+	ActionUserDisabled      = Action{"user_disabled"}
+	ActionUserRolesChanged  = Action{"user_roles_changed"}
+	ActionUserPasswordReset = Action{"user_password_reset"}
+	ActionUserDeleted       = Action{"user_deleted"}
+	ActionLockoutCleared    = Action{"lockout_cleared"}
+)
+
+// Set of known actions.
+var actions = map[string]Action{
+	ActionUserEnabled.name:       ActionUserEnabled,
+	ActionUserDisabled.name:      ActionUserDisabled,
+	ActionUserRolesChanged.name:  ActionUserRolesChanged,
+	ActionUserPasswordReset.name: ActionUserPasswordReset,
+	ActionUserDeleted.name:       ActionUserDeleted,
+	ActionLockoutCleared.name:    ActionLockoutCleared,
+}
+
+// Action represents what an administrator did.
+type Action struct {
+	name string
+}
+
+// ParseAction parses the string value and returns an action if one exists.
+func ParseAction(value string) (Action, error) {
+	action, exists := actions[value]
+	if !exists {
+		return Action{}, errors.New("invalid audit action")
+	}
+
+	return action, nil
+}
+
+// MustParseAction parses the string value and returns an action if one
+// exists. If an error occurs the function panics.
+func MustParseAction(value string) Action {
+	action, err := ParseAction(value)
+	if err != nil {
+		panic(err)
+	}
+
+	return action
+}
+
+// Name returns the name of the action.
+func (a Action) Name() string {
+	return a.name
+}
+
+// UnmarshalText implement the unmarshal interface for JSON conversions.
+func (a *Action) UnmarshalText(data []byte) error {
+	a.name = string(data)
+	return nil
+}
+
+// MarshalText implement the marshal interface for JSON conversions.
+func (a Action) MarshalText() ([]byte, error) {
+	return []byte(a.name), nil
+}
+
+// Equal provides support for the go-cmp package and testing.
+func (a Action) Equal(a2 Action) bool {
+	return a.name == a2.name
+}
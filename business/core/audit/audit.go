@@ -0,0 +1,93 @@
+// Package audit provides the business API for the audit log of what
+// administrators do to user accounts. Entries are only ever added, and they
+// outlive the users they name.
+package audit
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/dmanias/startupers/business/data/transaction"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// Storer interface declares the behavior this package needs to perists and
+// retrieve data.
+type Storer interface {
+	ExecuteUnderTransaction(tx transaction.Transaction) (Storer, error)
+	Create(ctx context.Context, entry Entry) error
+	Query(ctx context.Context, filter QueryFilter, pageNumber int, rowsPerPage int) ([]Entry, error)
+	Count(ctx context.Context, filter QueryFilter) (int, error)
+}
+
+// Core manages the set of APIs for audit access.
+type Core struct {
+	log    *zap.SugaredLogger
+	storer Storer
+}
+
+// NewCore constructs a core for audit api access.
+func NewCore(log *zap.SugaredLogger, storer Storer) *Core {
+	return &Core{
+		log:    log,
+		storer: storer,
+	}
+}
+
+// ExecuteUnderTransaction constructs a new Core value that will use the
+// specified transaction in any store related calls, so an entry is recorded
+// together with the change it is about, or not at all.
+func (c *Core) ExecuteUnderTransaction(tx transaction.Transaction) (*Core, error) {
+	trS, err := c.storer.ExecuteUnderTransaction(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	c = &Core{
+		log:    c.log,
+		storer: trS,
+	}
+
+	return c, nil
+}
+
+// Record adds an entry to the audit log. Unlike the activity feed the log
+// has to be complete, so a failure is returned to the caller.
+func (c *Core) Record(ctx context.Context, ne NewEntry) (Entry, error) {
+	details := ne.Details
+	if details == nil {
+		details = map[string]string{}
+	}
+
+	entry := Entry{
+		ID:          uuid.New(),
+		Action:      ne.Action,
+		ActorID:     ne.ActorID,
+		TargetID:    ne.TargetID,
+		Details:     details,
+		DateCreated: time.Now(),
+	}
+
+	if err := c.storer.Create(ctx, entry); err != nil {
+		return Entry{}, fmt.Errorf("create: action[%s]: targetID[%s]: %w", entry.Action.Name(), entry.TargetID, err)
+	}
+
+	return entry, nil
+}
+
+// Query retrieves a page of the audit log, newest first.
+func (c *Core) Query(ctx context.Context, filter QueryFilter, pageNumber int, rowsPerPage int) ([]Entry, error) {
+	entries, err := c.storer.Query(ctx, filter, pageNumber, rowsPerPage)
+	if err != nil {
+		return nil, fmt.Errorf("query: %w", err)
+	}
+
+	return entries, nil
+}
+
+// Count returns the total number of entries in the audit log.
+func (c *Core) Count(ctx context.Context, filter QueryFilter) (int, error) {
+	return c.storer.Count(ctx, filter)
+}
@@ -0,0 +1,38 @@
+package audit
+
+import (
+	"fmt"
+
+	"github.com/dmanias/startupers/business/sys/validate"
+	"github.com/google/uuid"
+)
+
+// QueryFilter holds the available fields a query can be filtered on.
+type QueryFilter struct {
+	ActorID  *uuid.UUID `validate:"omitempty"`
+	TargetID *uuid.UUID `validate:"omitempty"`
+	Action   *Action    `validate:"omitempty"`
+}
+
+// Validate checks the data in the model is considered clean.
+func (qf *QueryFilter) Validate() error {
+	if err := validate.Check(qf); err != nil {
+		return fmt.Errorf("validate: %w", err)
+	}
+	return nil
+}
+
+// WithActorID sets the ActorID field of the QueryFilter value.
+func (qf *QueryFilter) WithActorID(actorID uuid.UUID) {
+	qf.ActorID = &actorID
+}
+
+// WithTargetID sets the TargetID field of the QueryFilter value.
+func (qf *QueryFilter) WithTargetID(targetID uuid.UUID) {
+	qf.TargetID = &targetID
+}
+
+// WithAction sets the Action field of the QueryFilter value.
+func (qf *QueryFilter) WithAction(action Action) {
+	qf.Action = &action
+}
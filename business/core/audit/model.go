@@ -0,0 +1,26 @@
+package audit
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Entry records an action an administrator took on a user. Details hold what
+// is specific to the action, such as the roles that were assigned.
+type Entry struct {
+	ID          uuid.UUID
+	Action      Action
+	ActorID     uuid.UUID
+	TargetID    uuid.UUID
+	Details     map[string]string
+	DateCreated time.Time
+}
+
+// NewEntry is what the handlers provide when an administrator acts.
+type NewEntry struct {
+	Action   Action
+	ActorID  uuid.UUID
+	TargetID uuid.UUID
+	Details  map[string]string
+}
@@ -0,0 +1,114 @@
+// Package auditdb contains audit log related CRUD functionality.
+package auditdb
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+
+	"github.com/dmanias/startupers/business/core/audit"
+	"github.com/dmanias/startupers/business/data/sqldb"
+	"github.com/dmanias/startupers/business/data/transaction"
+	database "github.com/dmanias/startupers/business/sys/database/pgx"
+	"github.com/jmoiron/sqlx"
+	"go.uber.org/zap"
+)
+
+// Store manages the set of APIs for audit log database access.
+type Store struct {
+	log *zap.SugaredLogger
+	db  sqlx.ExtContext
+}
+
+// NewStore constructs the api for data access.
+func NewStore(log *zap.SugaredLogger, db *sqlx.DB) *Store {
+	return &Store{
+		log: log,
+		db:  db,
+	}
+}
+
+// ExecuteUnderTransaction constructs a new Store value replacing the sqlx DB
+// value with a sqlx DB value that is currently inside a transaction.
+func (s *Store) ExecuteUnderTransaction(tx transaction.Transaction) (audit.Storer, error) {
+	ec, err := sqldb.GetExtContext(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	s = &Store{
+		log: s.log,
+		db:  ec,
+	}
+
+	return s, nil
+}
+
+// Create inserts a new entry into the audit log.
+func (s *Store) Create(ctx context.Context, entry audit.Entry) error {
+	dbEnt, err := toDBEntry(entry)
+	if err != nil {
+		return err
+	}
+
+	const q = `
+	INSERT INTO audit_log
+		(id, action, actor_id, target_id, details, date_created)
+	VALUES
+		(:id, :action, :actor_id, :target_id, CAST(:details AS JSONB), :date_created)`
+
+	if err := database.NamedExecContext(ctx, s.log, s.db, q, dbEnt); err != nil {
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	return nil
+}
+
+// Query retrieves a page of the audit log, newest first.
+func (s *Store) Query(ctx context.Context, filter audit.QueryFilter, pageNumber int, rowsPerPage int) ([]audit.Entry, error) {
+	data := map[string]interface{}{
+		"offset":        (pageNumber - 1) * rowsPerPage,
+		"rows_per_page": rowsPerPage,
+	}
+
+	const q = `
+	SELECT
+		id, action, actor_id, target_id, CAST(details AS TEXT) AS details, date_created
+	FROM
+		audit_log`
+
+	buf := bytes.NewBufferString(q)
+	s.applyFilter(filter, data, buf)
+	buf.WriteString(" ORDER BY date_created DESC, id DESC")
+	buf.WriteString(" OFFSET :offset ROWS FETCH NEXT :rows_per_page ROWS ONLY")
+
+	var dbEnts []dbEntry
+	if err := database.NamedQuerySlice(ctx, s.log, s.db, buf.String(), data, &dbEnts); err != nil {
+		return nil, fmt.Errorf("namedqueryslice: %w", err)
+	}
+
+	return toCoreEntrySlice(dbEnts), nil
+}
+
+// Count returns the total number of entries in the audit log.
+func (s *Store) Count(ctx context.Context, filter audit.QueryFilter) (int, error) {
+	data := map[string]interface{}{}
+
+	const q = `
+	SELECT
+		count(1)
+	FROM
+		audit_log`
+
+	buf := bytes.NewBufferString(q)
+	s.applyFilter(filter, data, buf)
+
+	var count struct {
+		Count int `db:"count"`
+	}
+	if err := database.NamedQueryStruct(ctx, s.log, s.db, buf.String(), data, &count); err != nil {
+		return 0, fmt.Errorf("namedquerystruct: %w", err)
+	}
+
+	return count.Count, nil
+}
@@ -0,0 +1,32 @@
+package auditdb
+
+import (
+	"bytes"
+	"strings"
+
+	"github.com/dmanias/startupers/business/core/audit"
+)
+
+func (s *Store) applyFilter(filter audit.QueryFilter, data map[string]interface{}, buf *bytes.Buffer) {
+	var wc []string
+
+	if filter.ActorID != nil {
+		data["actor_id"] = *filter.ActorID
+		wc = append(wc, "actor_id = :actor_id")
+	}
+
+	if filter.TargetID != nil {
+		data["target_id"] = *filter.TargetID
+		wc = append(wc, "target_id = :target_id")
+	}
+
+	if filter.Action != nil {
+		data["action"] = filter.Action.Name()
+		wc = append(wc, "action = :action")
+	}
+
+	if len(wc) > 0 {
+		buf.WriteString(" WHERE ")
+		buf.WriteString(strings.Join(wc, " AND "))
+	}
+}
@@ -0,0 +1,59 @@
+package auditdb
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/dmanias/startupers/business/core/audit"
+	"github.com/google/uuid"
+)
+
+// dbEntry represent the structure we need for moving data
+// between the app and the database.
+type dbEntry struct {
+	ID          uuid.UUID `db:"id"`
+	Action      string    `db:"action"`
+	ActorID     uuid.UUID `db:"actor_id"`
+	TargetID    uuid.UUID `db:"target_id"`
+	Details     string    `db:"details"`
+	DateCreated time.Time `db:"date_created"`
+}
+
+func toDBEntry(entry audit.Entry) (dbEntry, error) {
+	details, err := json.Marshal(entry.Details)
+	if err != nil {
+		return dbEntry{}, fmt.Errorf("marshal details: %w", err)
+	}
+
+	return dbEntry{
+		ID:          entry.ID,
+		Action:      entry.Action.Name(),
+		ActorID:     entry.ActorID,
+		TargetID:    entry.TargetID,
+		Details:     string(details),
+		DateCreated: entry.DateCreated.UTC(),
+	}, nil
+}
+
+func toCoreEntry(dbEnt dbEntry) audit.Entry {
+	var details map[string]string
+	_ = json.Unmarshal([]byte(dbEnt.Details), &details)
+
+	return audit.Entry{
+		ID:          dbEnt.ID,
+		Action:      audit.MustParseAction(dbEnt.Action),
+		ActorID:     dbEnt.ActorID,
+		TargetID:    dbEnt.TargetID,
+		Details:     details,
+		DateCreated: dbEnt.DateCreated.In(time.Local),
+	}
+}
+
+func toCoreEntrySlice(dbEnts []dbEntry) []audit.Entry {
+	entries := make([]audit.Entry, len(dbEnts))
+	for i, dbEnt := range dbEnts {
+		entries[i] = toCoreEntry(dbEnt)
+	}
+	return entries
+}
@@ -0,0 +1,232 @@
+// Package category provides the business API for the admin managed taxonomy
+// ideas are filed under. Categories form a tree; filtering ideas on a
+// category includes its subcategories.
+package category
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Set of error variables for CRUD operations.
+var (
+	ErrNotFound       = errors.New("category not found")
+	ErrExists         = errors.New("a category with this slug already exists")
+	ErrInvalidSlug    = errors.New("slug must be lower case letters and digits separated by single dashes")
+	ErrParentNotFound = errors.New("parent category not found")
+	ErrCycle          = errors.New("a category can't be moved under itself or one of its subcategories")
+	ErrInUse          = errors.New("category still has subcategories or ideas")
+)
+
+var slugRE = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+
+// Storer interface declares the behavior this package needs to perists and
+// retrieve data.
+type Storer interface {
+	Create(ctx context.Context, cat Category) error
+	Update(ctx context.Context, cat Category) error
+	Delete(ctx context.Context, cat Category) error
+	QueryAll(ctx context.Context) ([]Category, error)
+	QueryBySlug(ctx context.Context, slug string) (Category, error)
+	CountIdeas(ctx context.Context) (map[string]int, error)
+	CountUsage(ctx context.Context, slug string) (int, error)
+}
+
+// Core manages the set of APIs for category access.
+type Core struct {
+	log    *zap.SugaredLogger
+	storer Storer
+}
+
+// NewCore constructs a core for category api access.
+func NewCore(log *zap.SugaredLogger, storer Storer) *Core {
+	return &Core{
+		log:    log,
+		storer: storer,
+	}
+}
+
+// Create adds a category to the taxonomy, under its parent if it has one.
+func (c *Core) Create(ctx context.Context, nc NewCategory) (Category, error) {
+	if !slugRE.MatchString(nc.Slug) {
+		return Category{}, ErrInvalidSlug
+	}
+
+	if nc.ParentSlug != "" {
+		if _, err := c.QueryBySlug(ctx, nc.ParentSlug); err != nil {
+			if errors.Is(err, ErrNotFound) {
+				return Category{}, ErrParentNotFound
+			}
+			return Category{}, fmt.Errorf("create: %w", err)
+		}
+	}
+
+	now := time.Now()
+
+	cat := Category{
+		Slug:         nc.Slug,
+		ParentSlug:   nc.ParentSlug,
+		DisplayOrder: nc.DisplayOrder,
+		Labels:       nc.Labels,
+		DateCreated:  now,
+		DateUpdated:  now,
+	}
+
+	if err := c.storer.Create(ctx, cat); err != nil {
+		return Category{}, fmt.Errorf("create: %w", err)
+	}
+
+	return cat, nil
+}
+
+// Update modifies a category. Moving a category moves its subcategories
+// along with it; it can't be moved under one of them.
+func (c *Core) Update(ctx context.Context, cat Category, uc UpdateCategory) (Category, error) {
+	if uc.ParentSlug != nil && *uc.ParentSlug != cat.ParentSlug {
+		if err := c.checkParent(ctx, cat, *uc.ParentSlug); err != nil {
+			return Category{}, err
+		}
+		cat.ParentSlug = *uc.ParentSlug
+	}
+	if uc.DisplayOrder != nil {
+		cat.DisplayOrder = *uc.DisplayOrder
+	}
+	if uc.Labels != nil {
+		cat.Labels = uc.Labels
+	}
+	cat.DateUpdated = time.Now()
+
+	if err := c.storer.Update(ctx, cat); err != nil {
+		return Category{}, fmt.Errorf("update: slug[%s]: %w", cat.Slug, err)
+	}
+
+	return cat, nil
+}
+
+// Delete removes a category from the taxonomy. Only categories without
+// subcategories and without ideas, including ideas in the trash, can be
+// removed.
+func (c *Core) Delete(ctx context.Context, cat Category) error {
+	cats, err := c.storer.QueryAll(ctx)
+	if err != nil {
+		return fmt.Errorf("delete: slug[%s]: %w", cat.Slug, err)
+	}
+
+	for _, other := range cats {
+		if other.ParentSlug == cat.Slug {
+			return ErrInUse
+		}
+	}
+
+	n, err := c.storer.CountUsage(ctx, cat.Slug)
+	if err != nil {
+		return fmt.Errorf("delete: slug[%s]: %w", cat.Slug, err)
+	}
+	if n > 0 {
+		return ErrInUse
+	}
+
+	if err := c.storer.Delete(ctx, cat); err != nil {
+		return fmt.Errorf("delete: slug[%s]: %w", cat.Slug, err)
+	}
+
+	return nil
+}
+
+// QueryBySlug gets the specified category from the database.
+func (c *Core) QueryBySlug(ctx context.Context, slug string) (Category, error) {
+	cat, err := c.storer.QueryBySlug(ctx, slug)
+	if err != nil {
+		return Category{}, fmt.Errorf("query: slug[%s]: %w", slug, err)
+	}
+
+	return cat, nil
+}
+
+// QueryTree returns the taxonomy as a tree with the number of public ideas
+// in each category. Siblings are sorted by display order.
+func (c *Core) QueryTree(ctx context.Context) ([]Node, error) {
+	cats, err := c.storer.QueryAll(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("querytree: %w", err)
+	}
+
+	counts, err := c.storer.CountIdeas(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("querytree: %w", err)
+	}
+
+	children := make(map[string][]Category)
+	for _, cat := range cats {
+		children[cat.ParentSlug] = append(children[cat.ParentSlug], cat)
+	}
+
+	var build func(parent string) []Node
+	build = func(parent string) []Node {
+		list := children[parent]
+		sort.SliceStable(list, func(i, j int) bool {
+			if list[i].DisplayOrder != list[j].DisplayOrder {
+				return list[i].DisplayOrder < list[j].DisplayOrder
+			}
+			return list[i].Slug < list[j].Slug
+		})
+
+		nodes := make([]Node, len(list))
+		for i, cat := range list {
+			node := Node{
+				Category:  cat,
+				IdeaCount: counts[cat.Slug],
+				Children:  build(cat.Slug),
+			}
+
+			node.TotalIdeaCount = node.IdeaCount
+			for _, child := range node.Children {
+				node.TotalIdeaCount += child.TotalIdeaCount
+			}
+
+			nodes[i] = node
+		}
+
+		return nodes
+	}
+
+	return build(""), nil
+}
+
+// =============================================================================
+
+// checkParent makes sure the parent exists and isn't the category itself or
+// one of its subcategories.
+func (c *Core) checkParent(ctx context.Context, cat Category, parentSlug string) error {
+	if parentSlug == "" {
+		return nil
+	}
+
+	cats, err := c.storer.QueryAll(ctx)
+	if err != nil {
+		return fmt.Errorf("checkparent: %w", err)
+	}
+
+	parents := make(map[string]string, len(cats))
+	for _, other := range cats {
+		parents[other.Slug] = other.ParentSlug
+	}
+
+	if _, exists := parents[parentSlug]; !exists {
+		return ErrParentNotFound
+	}
+
+	for slug := parentSlug; slug != ""; slug = parents[slug] {
+		if slug == cat.Slug {
+			return ErrCycle
+		}
+	}
+
+	return nil
+}
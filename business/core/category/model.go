@@ -0,0 +1,58 @@
+package category
+
+import "time"
+
+// DefaultLocale is the locale a category's label falls back to when it has
+// no label for the requested one.
+const DefaultLocale = "en"
+
+// Category represents a node of the category taxonomy. Ideas refer to
+// categories by their slug. Top level categories have no parent.
+type Category struct {
+	Slug         string
+	ParentSlug   string
+	DisplayOrder int
+	Labels       map[string]string
+	DateCreated  time.Time
+	DateUpdated  time.Time
+}
+
+// Label returns the label of the category in the locale, falling back to the
+// default locale and then to the slug.
+func (c Category) Label(locale string) string {
+	if label, exists := c.Labels[locale]; exists {
+		return label
+	}
+	if label, exists := c.Labels[DefaultLocale]; exists {
+		return label
+	}
+	return c.Slug
+}
+
+// NewCategory is what we require from admins when adding a Category.
+type NewCategory struct {
+	Slug         string
+	ParentSlug   string
+	DisplayOrder int
+	Labels       map[string]string
+}
+
+// UpdateCategory defines what information may be provided to modify an
+// existing Category. All fields are optional so clients can send just the
+// fields they want changed. An empty ParentSlug moves the category to the
+// top level. The slug can't be changed since ideas refer to it.
+type UpdateCategory struct {
+	ParentSlug   *string
+	DisplayOrder *int
+	Labels       map[string]string
+}
+
+// Node is a category in the taxonomy tree together with its subcategories.
+// IdeaCount is the number of public ideas in the category itself and
+// TotalIdeaCount includes the ideas in all of its subcategories.
+type Node struct {
+	Category
+	IdeaCount      int
+	TotalIdeaCount int
+	Children       []Node
+}
@@ -0,0 +1,207 @@
+// Package categorydb contains category related CRUD functionality.
+package categorydb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/dmanias/startupers/business/core/category"
+	"github.com/dmanias/startupers/business/core/idea"
+	database "github.com/dmanias/startupers/business/sys/database/pgx"
+	"github.com/jmoiron/sqlx"
+	"go.uber.org/zap"
+)
+
+// columns lists the category columns with the labels read back as text.
+const columns = `slug, parent_slug, display_order, CAST(labels AS TEXT) AS labels, date_created, date_updated`
+
+// Store manages the set of APIs for category database access.
+type Store struct {
+	log *zap.SugaredLogger
+	db  sqlx.ExtContext
+}
+
+// NewStore constructs the api for data access.
+func NewStore(log *zap.SugaredLogger, db *sqlx.DB) *Store {
+	return &Store{
+		log: log,
+		db:  db,
+	}
+}
+
+// Create inserts a new category into the database.
+func (s *Store) Create(ctx context.Context, cat category.Category) error {
+	dbCat, err := toDBCategory(cat)
+	if err != nil {
+		return err
+	}
+
+	const q = `
+	INSERT INTO categories
+		(slug, parent_slug, display_order, labels, date_created, date_updated)
+	VALUES
+		(:slug, :parent_slug, :display_order, CAST(:labels AS JSONB), :date_created, :date_updated)`
+
+	if err := database.NamedExecContext(ctx, s.log, s.db, q, dbCat); err != nil {
+		if errors.Is(err, database.ErrDBDuplicatedEntry) {
+			return fmt.Errorf("namedexeccontext: %w", category.ErrExists)
+		}
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	return nil
+}
+
+// Update replaces a category document in the database.
+func (s *Store) Update(ctx context.Context, cat category.Category) error {
+	dbCat, err := toDBCategory(cat)
+	if err != nil {
+		return err
+	}
+
+	const q = `
+	UPDATE
+		categories
+	SET
+		"parent_slug" = :parent_slug,
+		"display_order" = :display_order,
+		"labels" = CAST(:labels AS JSONB),
+		"date_updated" = :date_updated
+	WHERE
+		slug = :slug`
+
+	if err := database.NamedExecContext(ctx, s.log, s.db, q, dbCat); err != nil {
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	return nil
+}
+
+// Delete removes a category from the database.
+func (s *Store) Delete(ctx context.Context, cat category.Category) error {
+	data := struct {
+		Slug string `db:"slug"`
+	}{
+		Slug: cat.Slug,
+	}
+
+	const q = `
+	DELETE FROM
+		categories
+	WHERE
+		slug = :slug`
+
+	if err := database.NamedExecContext(ctx, s.log, s.db, q, data); err != nil {
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	return nil
+}
+
+// QueryAll retrieves every category of the taxonomy.
+func (s *Store) QueryAll(ctx context.Context) ([]category.Category, error) {
+	const q = `
+	SELECT
+		` + columns + `
+	FROM
+		categories
+	ORDER BY
+		display_order, slug`
+
+	var dbCats []dbCategory
+	if err := database.NamedQuerySlice(ctx, s.log, s.db, q, struct{}{}, &dbCats); err != nil {
+		return nil, fmt.Errorf("namedqueryslice: %w", err)
+	}
+
+	return toCoreCategorySlice(dbCats)
+}
+
+// QueryBySlug gets the specified category from the database.
+func (s *Store) QueryBySlug(ctx context.Context, slug string) (category.Category, error) {
+	data := struct {
+		Slug string `db:"slug"`
+	}{
+		Slug: slug,
+	}
+
+	const q = `
+	SELECT
+		` + columns + `
+	FROM
+		categories
+	WHERE
+		slug = :slug`
+
+	var dbCat dbCategory
+	if err := database.NamedQueryStruct(ctx, s.log, s.db, q, data, &dbCat); err != nil {
+		if errors.Is(err, database.ErrDBNotFound) {
+			return category.Category{}, fmt.Errorf("namedquerystruct: %w", category.ErrNotFound)
+		}
+		return category.Category{}, fmt.Errorf("namedquerystruct: %w", err)
+	}
+
+	return toCoreCategory(dbCat)
+}
+
+// CountIdeas returns the number of public ideas, outside the trash, filed
+// directly under each category.
+func (s *Store) CountIdeas(ctx context.Context) (map[string]int, error) {
+	data := map[string]interface{}{
+		"privacy_public": idea.PrivacyPublic.Name(),
+	}
+
+	const q = `
+	SELECT
+		category, count(1) AS count
+	FROM
+		ideas
+	WHERE
+		privacy = :privacy_public AND
+		deleted_at IS NULL AND
+		category <> ''
+	GROUP BY
+		category`
+
+	var dbCounts []struct {
+		Category string `db:"category"`
+		Count    int    `db:"count"`
+	}
+	if err := database.NamedQuerySlice(ctx, s.log, s.db, q, data, &dbCounts); err != nil {
+		return nil, fmt.Errorf("namedqueryslice: %w", err)
+	}
+
+	counts := make(map[string]int, len(dbCounts))
+	for _, c := range dbCounts {
+		counts[c.Category] = c.Count
+	}
+
+	return counts, nil
+}
+
+// CountUsage returns the number of ideas filed directly under the category,
+// whatever their privacy and including the ones in the trash.
+func (s *Store) CountUsage(ctx context.Context, slug string) (int, error) {
+	data := struct {
+		Slug string `db:"slug"`
+	}{
+		Slug: slug,
+	}
+
+	const q = `
+	SELECT
+		count(1)
+	FROM
+		ideas
+	WHERE
+		category = :slug`
+
+	var count struct {
+		Count int `db:"count"`
+	}
+	if err := database.NamedQueryStruct(ctx, s.log, s.db, q, data, &count); err != nil {
+		return 0, fmt.Errorf("namedquerystruct: %w", err)
+	}
+
+	return count.Count, nil
+}
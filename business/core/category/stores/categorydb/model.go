@@ -0,0 +1,77 @@
+package categorydb
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/dmanias/startupers/business/core/category"
+)
+
+// dbCategory represent the structure we need for moving data
+// between the app and the database.
+type dbCategory struct {
+	Slug         string         `db:"slug"`
+	ParentSlug   sql.NullString `db:"parent_slug"`
+	DisplayOrder int            `db:"display_order"`
+	Labels       string         `db:"labels"`
+	DateCreated  time.Time      `db:"date_created"`
+	DateUpdated  time.Time      `db:"date_updated"`
+}
+
+func toDBCategory(cat category.Category) (dbCategory, error) {
+	labels := cat.Labels
+	if labels == nil {
+		labels = map[string]string{}
+	}
+
+	data, err := json.Marshal(labels)
+	if err != nil {
+		return dbCategory{}, fmt.Errorf("marshal labels: %w", err)
+	}
+
+	dbCat := dbCategory{
+		Slug: cat.Slug,
+		ParentSlug: sql.NullString{
+			String: cat.ParentSlug,
+			Valid:  cat.ParentSlug != "",
+		},
+		DisplayOrder: cat.DisplayOrder,
+		Labels:       string(data),
+		DateCreated:  cat.DateCreated.UTC(),
+		DateUpdated:  cat.DateUpdated.UTC(),
+	}
+
+	return dbCat, nil
+}
+
+func toCoreCategory(dbCat dbCategory) (category.Category, error) {
+	var labels map[string]string
+	if err := json.Unmarshal([]byte(dbCat.Labels), &labels); err != nil {
+		return category.Category{}, fmt.Errorf("unmarshal labels: slug[%s]: %w", dbCat.Slug, err)
+	}
+
+	cat := category.Category{
+		Slug:         dbCat.Slug,
+		ParentSlug:   dbCat.ParentSlug.String,
+		DisplayOrder: dbCat.DisplayOrder,
+		Labels:       labels,
+		DateCreated:  dbCat.DateCreated.In(time.Local),
+		DateUpdated:  dbCat.DateUpdated.In(time.Local),
+	}
+
+	return cat, nil
+}
+
+func toCoreCategorySlice(dbCats []dbCategory) ([]category.Category, error) {
+	cats := make([]category.Category, len(dbCats))
+	for i, dbCat := range dbCats {
+		cat, err := toCoreCategory(dbCat)
+		if err != nil {
+			return nil, err
+		}
+		cats[i] = cat
+	}
+	return cats, nil
+}
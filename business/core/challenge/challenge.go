@@ -6,7 +6,9 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/dmanias/startupers/business/core/activity"
 	"github.com/dmanias/startupers/business/data/order"
+	"github.com/dmanias/startupers/business/data/transaction"
 	"github.com/google/uuid"
 )
 
@@ -15,16 +17,22 @@ var (
 )
 
 type Storer interface {
+	ExecuteUnderTransaction(tx transaction.Transaction) (Storer, error)
 	Create(ctx context.Context, challenge Challenge) error
 	Update(ctx context.Context, challenge Challenge) error
 	Delete(ctx context.Context, challenge Challenge) error
+	SetDeleted(ctx context.Context, challenge Challenge) error
+	DeleteByIdea(ctx context.Context, ideaID uuid.UUID) ([]string, error)
+	DeleteTrashedBefore(ctx context.Context, before time.Time) ([]string, error)
 	Query(ctx context.Context, filter QueryFilter, orderBy order.By, pageNumber int, rowsPerPage int) ([]Challenge, error)
 	Count(ctx context.Context, filter QueryFilter) (int, error)
+	CountCompletedForShare(ctx context.Context, ideaID uuid.UUID) (int, error)
 	QueryByID(ctx context.Context, challengeID uuid.UUID) (Challenge, error)
 }
 
 type Core struct {
-	storer Storer
+	storer   Storer
+	activity []ActivityRecorder
 }
 
 func NewCore(storer Storer) *Core {
@@ -33,6 +41,23 @@ func NewCore(storer Storer) *Core {
 	}
 }
 
+// ExecuteUnderTransaction constructs a new Core value that will use the
+// specified transaction in any store related calls. Writes made through it
+// are not handed to the activity recorders, as the transaction may still be
+// rolled back.
+func (c *Core) ExecuteUnderTransaction(tx transaction.Transaction) (*Core, error) {
+	trS, err := c.storer.ExecuteUnderTransaction(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	c = &Core{
+		storer: trS,
+	}
+
+	return c, nil
+}
+
 func (c *Core) Create(ctx context.Context, nc NewChallenge) (Challenge, error) {
 	now := time.Now()
 
@@ -42,6 +67,7 @@ func (c *Core) Create(ctx context.Context, nc NewChallenge) (Challenge, error) {
 		ModeratorID: nc.ModeratorID,
 		Answer:      nc.Answer,
 		PhotoURL:    nc.PhotoURL,
+		ForkedFrom:  nc.ForkedFrom,
 		DateCreated: now,
 		DateUpdated: now,
 	}
@@ -53,7 +79,11 @@ func (c *Core) Create(ctx context.Context, nc NewChallenge) (Challenge, error) {
 	return challenge, nil
 }
 
-func (c *Core) Update(ctx context.Context, challenge Challenge, uc UpdateChallenge) (Challenge, error) {
+// Update applies the changes made by the user to the challenge. Answering a
+// challenge completes it.
+func (c *Core) Update(ctx context.Context, challenge Challenge, uc UpdateChallenge, userID uuid.UUID) (Challenge, error) {
+	completed := challenge.Answer != ""
+
 	if uc.Answer != nil {
 		challenge.Answer = *uc.Answer
 	}
@@ -66,15 +96,69 @@ func (c *Core) Update(ctx context.Context, challenge Challenge, uc UpdateChallen
 		return Challenge{}, fmt.Errorf("update: %w", err)
 	}
 
+	if !completed && challenge.Answer != "" {
+		c.record(ctx, activity.NewActivity{
+			Type:      activity.TypeChallengeCompleted,
+			ActorID:   userID,
+			IdeaID:    challenge.IdeaID,
+			SubjectID: challenge.ID,
+		})
+	}
+
+	return challenge, nil
+}
+
+// Delete moves the challenge to the trash on behalf of the user.
+func (c *Core) Delete(ctx context.Context, challenge Challenge, userID uuid.UUID) (Challenge, error) {
+	challenge.DeletedAt = time.Now()
+	challenge.DeletedBy = userID
+
+	if err := c.storer.SetDeleted(ctx, challenge); err != nil {
+		return Challenge{}, fmt.Errorf("delete: challengeID[%s]: %w", challenge.ID, err)
+	}
+
+	return challenge, nil
+}
+
+// Restore takes the challenge out of the trash.
+func (c *Core) Restore(ctx context.Context, challenge Challenge) (Challenge, error) {
+	challenge.DeletedAt = time.Time{}
+	challenge.DeletedBy = uuid.Nil
+
+	if err := c.storer.SetDeleted(ctx, challenge); err != nil {
+		return Challenge{}, fmt.Errorf("restore: challengeID[%s]: %w", challenge.ID, err)
+	}
+
 	return challenge, nil
 }
 
-func (c *Core) Delete(ctx context.Context, challenge Challenge) error {
-	if err := c.storer.Delete(ctx, challenge); err != nil {
-		return fmt.Errorf("delete: %w", err)
+// PurgeByIdea removes every challenge of the idea from the database for good,
+// including the ones in the trash, as part of the specified transaction. The
+// photos they used are returned.
+func (c *Core) PurgeByIdea(ctx context.Context, tx transaction.Transaction, ideaID uuid.UUID) ([]string, error) {
+	storer, err := c.storer.ExecuteUnderTransaction(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	photos, err := storer.DeleteByIdea(ctx, ideaID)
+	if err != nil {
+		return nil, fmt.Errorf("purgebyidea: ideaID[%s]: %w", ideaID, err)
+	}
+
+	return photos, nil
+}
+
+// PurgeTrashedBefore removes the challenges moved to the trash before the
+// specified time from the database for good. The photos they used are
+// returned.
+func (c *Core) PurgeTrashedBefore(ctx context.Context, before time.Time) ([]string, error) {
+	photos, err := c.storer.DeleteTrashedBefore(ctx, before)
+	if err != nil {
+		return nil, fmt.Errorf("purgetrashedbefore: %w", err)
 	}
 
-	return nil
+	return photos, nil
 }
 
 func (c *Core) Query(ctx context.Context, filter QueryFilter, orderBy order.By, pageNumber int, rowsPerPage int) ([]Challenge, error) {
@@ -90,6 +174,42 @@ func (c *Core) Count(ctx context.Context, filter QueryFilter) (int, error) {
 	return c.storer.Count(ctx, filter)
 }
 
+// CountCompleted returns the number of challenges of the idea that have been
+// answered, as part of the specified transaction. The counted challenges are
+// locked until the transaction ends, so they can't be trashed or have their
+// answer removed while the caller acts on the count.
+func (c *Core) CountCompleted(ctx context.Context, tx transaction.Transaction, ideaID uuid.UUID) (int, error) {
+	storer, err := c.storer.ExecuteUnderTransaction(tx)
+	if err != nil {
+		return 0, err
+	}
+
+	n, err := storer.CountCompletedForShare(ctx, ideaID)
+	if err != nil {
+		return 0, fmt.Errorf("countcompleted: ideaID[%s]: %w", ideaID, err)
+	}
+
+	return n, nil
+}
+
+// QueryDeletedByID gets the specified challenge from the trash.
+func (c *Core) QueryDeletedByID(ctx context.Context, challengeID uuid.UUID) (Challenge, error) {
+	var filter QueryFilter
+	filter.WithChallengeID(challengeID)
+	filter.WithDeleted(true)
+
+	challenges, err := c.storer.Query(ctx, filter, DefaultOrderBy, 1, 1)
+	if err != nil {
+		return Challenge{}, fmt.Errorf("query: challengeID[%s]: %w", challengeID, err)
+	}
+
+	if len(challenges) == 0 {
+		return Challenge{}, fmt.Errorf("query: challengeID[%s]: %w", challengeID, ErrNotFound)
+	}
+
+	return challenges[0], nil
+}
+
 func (c *Core) QueryByID(ctx context.Context, challengeID uuid.UUID) (Challenge, error) {
 	challenge, err := c.storer.QueryByID(ctx, challengeID)
 	if err != nil {
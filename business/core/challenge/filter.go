@@ -14,6 +14,8 @@ type QueryFilter struct {
 	ModeratorID      *uuid.UUID `validate:"omitempty"`
 	StartCreatedDate *time.Time `validate:"omitempty"`
 	EndCreatedDate   *time.Time `validate:"omitempty"`
+	Deleted          *bool      `validate:"omitempty"`
+	RestorableBy     *uuid.UUID `validate:"omitempty"`
 }
 
 func (qf *QueryFilter) Validate() error {
@@ -44,3 +46,17 @@ func (qf *QueryFilter) WithEndCreatedDate(endDate time.Time) {
 	d := endDate.UTC()
 	qf.EndCreatedDate = &d
 }
+
+// WithDeleted switches the result between the challenges in the trash and the
+// ones that aren't. Challenges in the trash are left out unless asked for.
+func (qf *QueryFilter) WithDeleted(deleted bool) {
+	qf.Deleted = &deleted
+}
+
+// WithRestorableBy restricts the result to the challenges in the trash the
+// user can restore: those they moved there, or that belong to their ideas.
+func (qf *QueryFilter) WithRestorableBy(userID uuid.UUID) {
+	qf.RestorableBy = &userID
+	deleted := true
+	qf.Deleted = &deleted
+}
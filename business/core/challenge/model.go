@@ -12,15 +12,24 @@ type Challenge struct {
 	ModeratorID uuid.UUID
 	Answer      string
 	PhotoURL    string
+	ForkedFrom  uuid.UUID
+	DeletedAt   time.Time
+	DeletedBy   uuid.UUID
 	DateCreated time.Time
 	DateUpdated time.Time
 }
 
+// Deleted reports whether the challenge has been moved to the trash.
+func (c Challenge) Deleted() bool {
+	return !c.DeletedAt.IsZero()
+}
+
 type NewChallenge struct {
 	IdeaID      uuid.UUID
 	ModeratorID uuid.UUID
 	Answer      string
 	PhotoURL    string
+	ForkedFrom  uuid.UUID
 }
 
 type UpdateChallenge struct {
@@ -10,4 +10,5 @@ const (
 	OrderByModeratorID = "moderatorid"
 	OrderByDateCreated = "datecreated"
 	OrderByDateUpdated = "dateupdated"
+	OrderByDateDeleted = "datedeleted"
 )
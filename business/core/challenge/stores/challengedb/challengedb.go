@@ -6,9 +6,12 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/dmanias/startupers/business/core/challenge"
 	"github.com/dmanias/startupers/business/data/order"
+	"github.com/dmanias/startupers/business/data/sqldb"
+	"github.com/dmanias/startupers/business/data/transaction"
 	database "github.com/dmanias/startupers/business/sys/database/pgx"
 	"github.com/google/uuid"
 	"github.com/jmoiron/sqlx"
@@ -17,7 +20,7 @@ import (
 
 type Store struct {
 	log *zap.SugaredLogger
-	db  *sqlx.DB
+	db  sqlx.ExtContext
 }
 
 func NewStore(log *zap.SugaredLogger, db *sqlx.DB) *Store {
@@ -27,12 +30,28 @@ func NewStore(log *zap.SugaredLogger, db *sqlx.DB) *Store {
 	}
 }
 
+// ExecuteUnderTransaction constructs a new Store value replacing the sqlx DB
+// value with a sqlx DB value that is currently inside a transaction.
+func (s *Store) ExecuteUnderTransaction(tx transaction.Transaction) (challenge.Storer, error) {
+	ec, err := sqldb.GetExtContext(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	s = &Store{
+		log: s.log,
+		db:  ec,
+	}
+
+	return s, nil
+}
+
 func (s *Store) Create(ctx context.Context, challenge challenge.Challenge) error {
 	const q = `
     INSERT INTO challenges
-        (id, idea_id, moderator_id, answer, photo_url, date_created, date_updated)
+        (id, idea_id, moderator_id, answer, photo_url, forked_from, date_created, date_updated)
     VALUES
-        (:id, :idea_id, :moderator_id, :answer, :photo_url, :date_created, :date_updated)`
+        (:id, :idea_id, :moderator_id, :answer, :photo_url, :forked_from, :date_created, :date_updated)`
 
 	if err := database.NamedExecContext(ctx, s.log, s.db, q, toDBChallenge(challenge)); err != nil {
 		return fmt.Errorf("namedexeccontext: %w", err)
@@ -79,6 +98,91 @@ func (s *Store) Delete(ctx context.Context, challenge challenge.Challenge) error
 	return nil
 }
 
+// SetDeleted records whether the challenge is in the trash, and who moved it
+// there.
+func (s *Store) SetDeleted(ctx context.Context, challenge challenge.Challenge) error {
+	const q = `
+    UPDATE
+        challenges
+    SET
+        "deleted_at" = :deleted_at,
+        "deleted_by" = :deleted_by
+    WHERE
+        id = :id`
+
+	if err := database.NamedExecContext(ctx, s.log, s.db, q, toDBChallenge(challenge)); err != nil {
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteByIdea removes every challenge of the idea, including the ones in the
+// trash, and returns the photos they used.
+func (s *Store) DeleteByIdea(ctx context.Context, ideaID uuid.UUID) ([]string, error) {
+	data := struct {
+		IdeaID string `db:"idea_id"`
+	}{
+		IdeaID: ideaID.String(),
+	}
+
+	const q = `
+    WITH purged AS (
+        DELETE FROM
+            challenges
+        WHERE
+            idea_id = :idea_id
+        RETURNING
+            photo_url
+    )
+    SELECT DISTINCT
+        p.photo_url
+    FROM
+        purged AS p
+    WHERE
+        p.photo_url <> ''`
+
+	var photos []dbPhoto
+	if err := database.NamedQuerySlice(ctx, s.log, s.db, q, data, &photos); err != nil {
+		return nil, fmt.Errorf("namedqueryslice: %w", err)
+	}
+
+	return toPhotoURLs(photos), nil
+}
+
+// DeleteTrashedBefore removes the challenges moved to the trash before the
+// specified time, and returns the photos they used.
+func (s *Store) DeleteTrashedBefore(ctx context.Context, before time.Time) ([]string, error) {
+	data := struct {
+		Before time.Time `db:"before"`
+	}{
+		Before: before.UTC(),
+	}
+
+	const q = `
+    WITH purged AS (
+        DELETE FROM
+            challenges
+        WHERE
+            deleted_at < :before
+        RETURNING
+            photo_url
+    )
+    SELECT DISTINCT
+        p.photo_url
+    FROM
+        purged AS p
+    WHERE
+        p.photo_url <> ''`
+
+	var photos []dbPhoto
+	if err := database.NamedQuerySlice(ctx, s.log, s.db, q, data, &photos); err != nil {
+		return nil, fmt.Errorf("namedqueryslice: %w", err)
+	}
+
+	return toPhotoURLs(photos), nil
+}
+
 func (s *Store) Query(ctx context.Context, filter challenge.QueryFilter, orderBy order.By, pageNumber int, rowsPerPage int) ([]challenge.Challenge, error) {
 	data := map[string]interface{}{
 		"offset":        (pageNumber - 1) * rowsPerPage,
@@ -92,10 +196,7 @@ func (s *Store) Query(ctx context.Context, filter challenge.QueryFilter, orderBy
             challenges`
 
 	buf := bytes.NewBufferString(q)
-	if filter.IdeaID != nil {
-		buf.WriteString(" WHERE idea_id = :idea_id")
-		data["idea_id"] = filter.IdeaID.String()
-	}
+	s.applyFilter(filter, data, buf)
 
 	orderByClause, err := orderByClause(orderBy)
 	if err != nil {
@@ -135,6 +236,41 @@ func (s *Store) Count(ctx context.Context, filter challenge.QueryFilter) (int, e
 	return count.Count, nil
 }
 
+// CountCompletedForShare counts the answered challenges of the idea that
+// aren't in the trash and locks them against changes until the transaction
+// ends.
+func (s *Store) CountCompletedForShare(ctx context.Context, ideaID uuid.UUID) (int, error) {
+	data := struct {
+		IdeaID string `db:"idea_id"`
+	}{
+		IdeaID: ideaID.String(),
+	}
+
+	const q = `
+    SELECT
+        count(1)
+    FROM (
+        SELECT
+            id
+        FROM
+            challenges
+        WHERE
+            idea_id = :idea_id AND
+            coalesce(answer, '') <> '' AND
+            deleted_at IS NULL
+        FOR SHARE
+    ) AS c`
+
+	var count struct {
+		Count int `db:"count"`
+	}
+	if err := database.NamedQueryStruct(ctx, s.log, s.db, q, data, &count); err != nil {
+		return 0, fmt.Errorf("namedquerystruct: %w", err)
+	}
+
+	return count.Count, nil
+}
+
 func (s *Store) QueryByID(ctx context.Context, challengeID uuid.UUID) (challenge.Challenge, error) {
 	data := struct {
 		ID string `db:"id"`
@@ -148,7 +284,8 @@ func (s *Store) QueryByID(ctx context.Context, challengeID uuid.UUID) (challenge
     FROM
         challenges
     WHERE
-        id = :id`
+        id = :id AND
+        deleted_at IS NULL`
 
 	var dbChallenge dbChallenge
 	if err := database.NamedQueryStruct(ctx, s.log, s.db, q, data, &dbChallenge); err != nil {
@@ -34,6 +34,17 @@ func (s *Store) applyFilter(filter challenge.QueryFilter, data map[string]interf
 		wc = append(wc, "date_created <= :end_date_created")
 	}
 
+	if filter.RestorableBy != nil {
+		data["restorable_by"] = filter.RestorableBy
+		wc = append(wc, "(deleted_by = :restorable_by OR idea_id IN (SELECT id FROM ideas WHERE user_id = :restorable_by))")
+	}
+
+	if filter.Deleted != nil && *filter.Deleted {
+		wc = append(wc, "deleted_at IS NOT NULL")
+	} else {
+		wc = append(wc, "deleted_at IS NULL")
+	}
+
 	if len(wc) > 0 {
 		buf.WriteString(" WHERE ")
 		buf.WriteString(strings.Join(wc, " AND "))
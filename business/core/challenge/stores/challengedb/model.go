@@ -1,6 +1,7 @@
 package challengedb
 
 import (
+	"database/sql"
 	"github.com/dmanias/startupers/business/core/challenge"
 	"time"
 
@@ -8,13 +9,16 @@ import (
 )
 
 type dbChallenge struct {
-	ID          uuid.UUID `db:"id"`
-	IdeaID      uuid.UUID `db:"idea_id"`
-	ModeratorID uuid.UUID `db:"moderator_id"`
-	Answer      string    `db:"answer"`
-	PhotoURL    string    `db:"photo_url"`
-	DateCreated time.Time `db:"date_created"`
-	DateUpdated time.Time `db:"date_updated"`
+	ID          uuid.UUID     `db:"id"`
+	IdeaID      uuid.UUID     `db:"idea_id"`
+	ModeratorID uuid.UUID     `db:"moderator_id"`
+	Answer      string        `db:"answer"`
+	PhotoURL    string        `db:"photo_url"`
+	ForkedFrom  uuid.NullUUID `db:"forked_from"`
+	DeletedAt   sql.NullTime  `db:"deleted_at"`
+	DeletedBy   uuid.NullUUID `db:"deleted_by"`
+	DateCreated time.Time     `db:"date_created"`
+	DateUpdated time.Time     `db:"date_updated"`
 }
 
 func toDBChallenge(challenge challenge.Challenge) dbChallenge {
@@ -24,23 +28,56 @@ func toDBChallenge(challenge challenge.Challenge) dbChallenge {
 		ModeratorID: challenge.ModeratorID,
 		Answer:      challenge.Answer,
 		PhotoURL:    challenge.PhotoURL,
+		ForkedFrom: uuid.NullUUID{
+			UUID:  challenge.ForkedFrom,
+			Valid: challenge.ForkedFrom != uuid.Nil,
+		},
+		DeletedAt: sql.NullTime{
+			Time:  challenge.DeletedAt.UTC(),
+			Valid: challenge.Deleted(),
+		},
+		DeletedBy: uuid.NullUUID{
+			UUID:  challenge.DeletedBy,
+			Valid: challenge.DeletedBy != uuid.Nil,
+		},
 		DateCreated: challenge.DateCreated.UTC(),
 		DateUpdated: challenge.DateUpdated.UTC(),
 	}
 }
 
 func toCoreChallenge(dbChallenge dbChallenge) challenge.Challenge {
+	var deletedAt time.Time
+	if dbChallenge.DeletedAt.Valid {
+		deletedAt = dbChallenge.DeletedAt.Time.In(time.Local)
+	}
+
 	return challenge.Challenge{
 		ID:          dbChallenge.ID,
 		IdeaID:      dbChallenge.IdeaID,
 		ModeratorID: dbChallenge.ModeratorID,
 		Answer:      dbChallenge.Answer,
 		PhotoURL:    dbChallenge.PhotoURL,
+		ForkedFrom:  dbChallenge.ForkedFrom.UUID,
+		DeletedAt:   deletedAt,
+		DeletedBy:   dbChallenge.DeletedBy.UUID,
 		DateCreated: dbChallenge.DateCreated.In(time.Local),
 		DateUpdated: dbChallenge.DateUpdated.In(time.Local),
 	}
 }
 
+// dbPhoto is the photo of a purged challenge.
+type dbPhoto struct {
+	PhotoURL string `db:"photo_url"`
+}
+
+func toPhotoURLs(dbPhotos []dbPhoto) []string {
+	photos := make([]string, len(dbPhotos))
+	for i, dbPhoto := range dbPhotos {
+		photos[i] = dbPhoto.PhotoURL
+	}
+	return photos
+}
+
 func toCoreChallengeSlice(dbChallenges []dbChallenge) []challenge.Challenge {
 	challenges := make([]challenge.Challenge, len(dbChallenges))
 	for i, dbChallenge := range dbChallenges {
@@ -12,6 +12,7 @@ var orderByFields = map[string]string{
 	challenge.OrderByModeratorID: "moderator_id",
 	challenge.OrderByDateCreated: "date_created",
 	challenge.OrderByDateUpdated: "date_updated",
+	challenge.OrderByDateDeleted: "deleted_at",
 }
 
 func orderByClause(orderBy order.By) (string, error) {
@@ -0,0 +1,254 @@
+// Package comment provides the business API for threaded comments on posts
+// and challenges. Comments can be replied to up to MaxDepth levels deep,
+// edited and deleted by their author and hidden by a moderator.
+package comment
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/dmanias/startupers/business/data/order"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// MaxDepth is the deepest a reply can be nested. Comments starting a thread
+// have a depth of zero.
+const MaxDepth = 5
+
+// Set of error variables for CRUD operations.
+var (
+	ErrNotFound       = errors.New("comment not found")
+	ErrParentNotFound = errors.New("the comment being replied to does not exist")
+	ErrTooDeep        = errors.New("replies can't be nested any deeper")
+	ErrDeleted        = errors.New("comment has been deleted")
+)
+
+// Storer interface declares the behavior this package needs to perists and
+// retrieve data.
+type Storer interface {
+	Create(ctx context.Context, cmt Comment) error
+	Update(ctx context.Context, cmt Comment) error
+	SetDeleted(ctx context.Context, cmt Comment) error
+	SetHidden(ctx context.Context, cmt Comment) error
+	Query(ctx context.Context, filter QueryFilter, orderBy order.By, pageNumber int, rowsPerPage int) ([]Comment, error)
+	Count(ctx context.Context, filter QueryFilter) (int, error)
+	QueryByID(ctx context.Context, commentID uuid.UUID) (Comment, error)
+	QueryReplies(ctx context.Context, commentIDs []uuid.UUID) ([]Comment, error)
+}
+
+// Core manages the set of APIs for comment access.
+type Core struct {
+	log    *zap.SugaredLogger
+	storer Storer
+}
+
+// NewCore constructs a core for comment api access.
+func NewCore(log *zap.SugaredLogger, storer Storer) *Core {
+	return &Core{
+		log:    log,
+		storer: storer,
+	}
+}
+
+// Create adds a comment to the database. A reply is left on the same post or
+// challenge as the comment it answers, one level deeper.
+func (c *Core) Create(ctx context.Context, nc NewComment) (Comment, error) {
+	var depth int
+
+	if nc.ParentID != uuid.Nil {
+		parent, err := c.storer.QueryByID(ctx, nc.ParentID)
+		if err != nil {
+			if errors.Is(err, ErrNotFound) {
+				return Comment{}, ErrParentNotFound
+			}
+			return Comment{}, fmt.Errorf("create: parentID[%s]: %w", nc.ParentID, err)
+		}
+
+		if parent.Deleted() || !parent.OwnerType.Equal(nc.OwnerType) || parent.OwnerID != nc.OwnerID {
+			return Comment{}, ErrParentNotFound
+		}
+
+		if parent.Depth >= MaxDepth {
+			return Comment{}, ErrTooDeep
+		}
+
+		depth = parent.Depth + 1
+	}
+
+	now := time.Now()
+
+	cmt := Comment{
+		ID:          uuid.New(),
+		IdeaID:      nc.IdeaID,
+		OwnerType:   nc.OwnerType,
+		OwnerID:     nc.OwnerID,
+		ParentID:    nc.ParentID,
+		Depth:       depth,
+		AuthorID:    nc.AuthorID,
+		Content:     nc.Content,
+		DateCreated: now,
+		DateUpdated: now,
+	}
+
+	if err := c.storer.Create(ctx, cmt); err != nil {
+		return Comment{}, fmt.Errorf("create: %w", err)
+	}
+
+	return cmt, nil
+}
+
+// Update replaces the content of a comment.
+func (c *Core) Update(ctx context.Context, cmt Comment, uc UpdateComment) (Comment, error) {
+	if cmt.Deleted() {
+		return Comment{}, ErrDeleted
+	}
+
+	if uc.Content != nil {
+		cmt.Content = *uc.Content
+	}
+	cmt.DateUpdated = time.Now()
+
+	if err := c.storer.Update(ctx, cmt); err != nil {
+		return Comment{}, fmt.Errorf("update: commentID[%s]: %w", cmt.ID, err)
+	}
+
+	return cmt, nil
+}
+
+// Delete removes the content of a comment. The comment stays in its thread
+// so the replies to it keep their place.
+func (c *Core) Delete(ctx context.Context, cmt Comment) (Comment, error) {
+	if cmt.Deleted() {
+		return cmt, nil
+	}
+
+	cmt.Content = ""
+	cmt.DeletedAt = time.Now()
+
+	if err := c.storer.SetDeleted(ctx, cmt); err != nil {
+		return Comment{}, fmt.Errorf("delete: commentID[%s]: %w", cmt.ID, err)
+	}
+
+	return cmt, nil
+}
+
+// Hide hides the comment on behalf of the moderator.
+func (c *Core) Hide(ctx context.Context, cmt Comment, moderatorID uuid.UUID) (Comment, error) {
+	cmt.HiddenAt = time.Now()
+	cmt.HiddenBy = moderatorID
+
+	if err := c.storer.SetHidden(ctx, cmt); err != nil {
+		return Comment{}, fmt.Errorf("hide: commentID[%s]: %w", cmt.ID, err)
+	}
+
+	return cmt, nil
+}
+
+// Unhide makes a hidden comment visible again.
+func (c *Core) Unhide(ctx context.Context, cmt Comment) (Comment, error) {
+	cmt.HiddenAt = time.Time{}
+	cmt.HiddenBy = uuid.Nil
+
+	if err := c.storer.SetHidden(ctx, cmt); err != nil {
+		return Comment{}, fmt.Errorf("unhide: commentID[%s]: %w", cmt.ID, err)
+	}
+
+	return cmt, nil
+}
+
+// Query retrieves a list of existing comments from the database.
+func (c *Core) Query(ctx context.Context, filter QueryFilter, orderBy order.By, pageNumber int, rowsPerPage int) ([]Comment, error) {
+	comments, err := c.storer.Query(ctx, filter, orderBy, pageNumber, rowsPerPage)
+	if err != nil {
+		return nil, fmt.Errorf("query: %w", err)
+	}
+
+	return comments, nil
+}
+
+// Count returns the total number of comments in the store.
+func (c *Core) Count(ctx context.Context, filter QueryFilter) (int, error) {
+	return c.storer.Count(ctx, filter)
+}
+
+// QueryThreads retrieves a page of the threads on a post or challenge, each
+// with all of its replies. Paging applies to the comments starting the
+// threads.
+func (c *Core) QueryThreads(ctx context.Context, ownerType OwnerType, ownerID uuid.UUID, pageNumber int, rowsPerPage int) ([]Thread, error) {
+	var filter QueryFilter
+	filter.WithOwner(ownerType, ownerID)
+	filter.WithTopLevel(true)
+
+	roots, err := c.storer.Query(ctx, filter, DefaultOrderBy, pageNumber, rowsPerPage)
+	if err != nil {
+		return nil, fmt.Errorf("querythreads: %w", err)
+	}
+
+	if len(roots) == 0 {
+		return []Thread{}, nil
+	}
+
+	ids := make([]uuid.UUID, len(roots))
+	for i, root := range roots {
+		ids[i] = root.ID
+	}
+
+	replies, err := c.storer.QueryReplies(ctx, ids)
+	if err != nil {
+		return nil, fmt.Errorf("querythreads: %w", err)
+	}
+
+	return buildThreads(roots, replies), nil
+}
+
+// CountThreads returns the number of threads on a post or challenge.
+func (c *Core) CountThreads(ctx context.Context, ownerType OwnerType, ownerID uuid.UUID) (int, error) {
+	var filter QueryFilter
+	filter.WithOwner(ownerType, ownerID)
+	filter.WithTopLevel(true)
+
+	return c.storer.Count(ctx, filter)
+}
+
+// QueryByID gets the specified comment from the database.
+func (c *Core) QueryByID(ctx context.Context, commentID uuid.UUID) (Comment, error) {
+	cmt, err := c.storer.QueryByID(ctx, commentID)
+	if err != nil {
+		return Comment{}, fmt.Errorf("query: commentID[%s]: %w", commentID, err)
+	}
+
+	return cmt, nil
+}
+
+// =============================================================================
+
+// buildThreads nests the replies under the comments they answer. Replies are
+// expected oldest first and keep that order.
+func buildThreads(roots []Comment, replies []Comment) []Thread {
+	children := make(map[uuid.UUID][]Comment)
+	for _, reply := range replies {
+		children[reply.ParentID] = append(children[reply.ParentID], reply)
+	}
+
+	var build func(cmt Comment) Thread
+	build = func(cmt Comment) Thread {
+		thread := Thread{
+			Comment: cmt,
+			Replies: make([]Thread, 0, len(children[cmt.ID])),
+		}
+		for _, child := range children[cmt.ID] {
+			thread.Replies = append(thread.Replies, build(child))
+		}
+		return thread
+	}
+
+	threads := make([]Thread, len(roots))
+	for i, root := range roots {
+		threads[i] = build(root)
+	}
+
+	return threads
+}
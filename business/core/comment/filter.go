@@ -0,0 +1,49 @@
+package comment
+
+import (
+	"fmt"
+
+	"github.com/dmanias/startupers/business/sys/validate"
+	"github.com/google/uuid"
+)
+
+// QueryFilter holds the available fields a query can be filtered on.
+type QueryFilter struct {
+	OwnerType *OwnerType `validate:"omitempty"`
+	OwnerID   *uuid.UUID `validate:"omitempty"`
+	AuthorID  *uuid.UUID `validate:"omitempty"`
+	TopLevel  *bool      `validate:"omitempty"`
+	Deleted   *bool      `validate:"omitempty"`
+}
+
+// Validate checks the data in the model is considered clean.
+func (qf *QueryFilter) Validate() error {
+	if err := validate.Check(qf); err != nil {
+		return fmt.Errorf("validate: %w", err)
+	}
+	return nil
+}
+
+// WithOwner restricts the result to the comments left on the specified post
+// or challenge.
+func (qf *QueryFilter) WithOwner(ownerType OwnerType, ownerID uuid.UUID) {
+	qf.OwnerType = &ownerType
+	qf.OwnerID = &ownerID
+}
+
+// WithAuthorID sets the AuthorID field of the QueryFilter value.
+func (qf *QueryFilter) WithAuthorID(authorID uuid.UUID) {
+	qf.AuthorID = &authorID
+}
+
+// WithTopLevel restricts the result to the comments starting a thread, or to
+// the replies when false.
+func (qf *QueryFilter) WithTopLevel(topLevel bool) {
+	qf.TopLevel = &topLevel
+}
+
+// WithDeleted switches the result between the comments deleted by their
+// author and the ones that aren't. Both are returned unless asked for.
+func (qf *QueryFilter) WithDeleted(deleted bool) {
+	qf.Deleted = &deleted
+}
@@ -0,0 +1,67 @@
+package comment
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Comment represents a comment left on a post or a challenge. Comments
+// without a parent start a thread; replies point at the comment they answer
+// and sit one level deeper. The idea the owner belongs to is kept on the
+// comment so access can be checked without looking the owner up.
+type Comment struct {
+	ID          uuid.UUID
+	IdeaID      uuid.UUID
+	OwnerType   OwnerType
+	OwnerID     uuid.UUID
+	ParentID    uuid.UUID
+	Depth       int
+	AuthorID    uuid.UUID
+	Content     string
+	HiddenAt    time.Time
+	HiddenBy    uuid.UUID
+	DeletedAt   time.Time
+	DateCreated time.Time
+	DateUpdated time.Time
+}
+
+// IsReply reports whether the comment answers another comment.
+func (c Comment) IsReply() bool {
+	return c.ParentID != uuid.Nil
+}
+
+// Hidden reports whether a moderator has hidden the comment.
+func (c Comment) Hidden() bool {
+	return !c.HiddenAt.IsZero()
+}
+
+// Deleted reports whether the author has deleted the comment. Deleted
+// comments stay in their thread, without content, so replies keep their
+// place.
+func (c Comment) Deleted() bool {
+	return !c.DeletedAt.IsZero()
+}
+
+// NewComment is what we require from clients when adding a Comment. ParentID
+// is left empty to start a new thread.
+type NewComment struct {
+	IdeaID    uuid.UUID
+	OwnerType OwnerType
+	OwnerID   uuid.UUID
+	ParentID  uuid.UUID
+	AuthorID  uuid.UUID
+	Content   string
+}
+
+// UpdateComment defines what information may be provided to modify an
+// existing Comment.
+type UpdateComment struct {
+	Content *string
+}
+
+// Thread is a comment along with the replies to it, oldest first.
+type Thread struct {
+	Comment
+	Replies []Thread
+}
@@ -0,0 +1,14 @@
+package comment
+
+import "github.com/dmanias/startupers/business/data/order"
+
+// DefaultOrderBy represents the default way we sort. Conversations read
+// oldest first.
+var DefaultOrderBy = order.NewBy(OrderByDateCreated, order.ASC)
+
+// Set of fields that the results can be ordered by. These are the names
+// that should be used by the application layer.
+const (
+	OrderByDateCreated = "datecreated"
+	OrderByDateUpdated = "dateupdated"
+)
@@ -0,0 +1,63 @@
+package comment
+
+import "errors"
+
+// Set of things comments can be left on.
+var (
+	OwnerTypePost      = OwnerType{"post"}
+	OwnerTypeChallenge = OwnerType{"challenge"}
+)
+
+// Set of known owner types.
+var ownerTypes = map[string]OwnerType{
+	OwnerTypePost.name:      OwnerTypePost,
+	OwnerTypeChallenge.name: OwnerTypeChallenge,
+}
+
+// OwnerType represents the kind of content a comment thread hangs off.
+type OwnerType struct {
+	name string
+}
+
+// ParseOwnerType parses the string value and returns an owner type if one
+// exists.
+func ParseOwnerType(value string) (OwnerType, error) {
+	ownerType, exists := ownerTypes[value]
+	if !exists {
+		return OwnerType{}, errors.New("invalid owner type")
+	}
+
+	return ownerType, nil
+}
+
+// MustParseOwnerType parses the string value and returns an owner type if one
+// exists. If an error occurs the function panics.
+func MustParseOwnerType(value string) OwnerType {
+	ownerType, err := ParseOwnerType(value)
+	if err != nil {
+		panic(err)
+	}
+
+	return ownerType
+}
+
+// Name returns the name of the owner type.
+func (o OwnerType) Name() string {
+	return o.name
+}
+
+// UnmarshalText implement the unmarshal interface for JSON conversions.
+func (o *OwnerType) UnmarshalText(data []byte) error {
+	o.name = string(data)
+	return nil
+}
+
+// MarshalText implement the marshal interface for JSON conversions.
+func (o OwnerType) MarshalText() ([]byte, error) {
+	return []byte(o.name), nil
+}
+
+// Equal provides support for the go-cmp package and testing.
+func (o OwnerType) Equal(o2 OwnerType) bool {
+	return o.name == o2.name
+}
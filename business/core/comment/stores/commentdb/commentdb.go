@@ -0,0 +1,226 @@
+// Package commentdb contains comment related CRUD functionality.
+package commentdb
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/dmanias/startupers/business/core/comment"
+	"github.com/dmanias/startupers/business/data/order"
+	database "github.com/dmanias/startupers/business/sys/database/pgx"
+	"github.com/dmanias/startupers/business/sys/database/pgx/dbarray"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"go.uber.org/zap"
+)
+
+// Store manages the set of APIs for comment database access.
+type Store struct {
+	log *zap.SugaredLogger
+	db  sqlx.ExtContext
+}
+
+// NewStore constructs the api for data access.
+func NewStore(log *zap.SugaredLogger, db *sqlx.DB) *Store {
+	return &Store{
+		log: log,
+		db:  db,
+	}
+}
+
+// Create inserts a new comment into the database.
+func (s *Store) Create(ctx context.Context, cmt comment.Comment) error {
+	const q = `
+	INSERT INTO comments
+		(id, idea_id, owner_type, owner_id, parent_id, depth, author_id, content, date_created, date_updated)
+	VALUES
+		(:id, :idea_id, :owner_type, :owner_id, :parent_id, :depth, :author_id, :content, :date_created, :date_updated)`
+
+	if err := database.NamedExecContext(ctx, s.log, s.db, q, toDBComment(cmt)); err != nil {
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	return nil
+}
+
+// Update replaces the content of a comment in the database.
+func (s *Store) Update(ctx context.Context, cmt comment.Comment) error {
+	const q = `
+	UPDATE
+		comments
+	SET
+		"content" = :content,
+		"date_updated" = :date_updated
+	WHERE
+		id = :id`
+
+	if err := database.NamedExecContext(ctx, s.log, s.db, q, toDBComment(cmt)); err != nil {
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	return nil
+}
+
+// SetDeleted records that the author deleted the comment and drops its
+// content.
+func (s *Store) SetDeleted(ctx context.Context, cmt comment.Comment) error {
+	const q = `
+	UPDATE
+		comments
+	SET
+		"content" = :content,
+		"deleted_at" = :deleted_at
+	WHERE
+		id = :id`
+
+	if err := database.NamedExecContext(ctx, s.log, s.db, q, toDBComment(cmt)); err != nil {
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	return nil
+}
+
+// SetHidden records whether the comment is hidden, and by which moderator.
+func (s *Store) SetHidden(ctx context.Context, cmt comment.Comment) error {
+	const q = `
+	UPDATE
+		comments
+	SET
+		"hidden_at" = :hidden_at,
+		"hidden_by" = :hidden_by
+	WHERE
+		id = :id`
+
+	if err := database.NamedExecContext(ctx, s.log, s.db, q, toDBComment(cmt)); err != nil {
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	return nil
+}
+
+// Query retrieves a list of existing comments from the database.
+func (s *Store) Query(ctx context.Context, filter comment.QueryFilter, orderBy order.By, pageNumber int, rowsPerPage int) ([]comment.Comment, error) {
+	data := map[string]interface{}{
+		"offset":        (pageNumber - 1) * rowsPerPage,
+		"rows_per_page": rowsPerPage,
+	}
+
+	const q = `
+	SELECT
+		*
+	FROM
+		comments`
+
+	buf := bytes.NewBufferString(q)
+	s.applyFilter(filter, data, buf)
+
+	orderByClause, err := orderByClause(orderBy)
+	if err != nil {
+		return nil, err
+	}
+
+	buf.WriteString(orderByClause)
+	buf.WriteString(" OFFSET :offset ROWS FETCH NEXT :rows_per_page ROWS ONLY")
+
+	var dbCmts []dbComment
+	if err := database.NamedQuerySlice(ctx, s.log, s.db, buf.String(), data, &dbCmts); err != nil {
+		return nil, fmt.Errorf("namedqueryslice: %w", err)
+	}
+
+	return toCoreCommentSlice(dbCmts), nil
+}
+
+// Count returns the total number of comments in the DB.
+func (s *Store) Count(ctx context.Context, filter comment.QueryFilter) (int, error) {
+	data := map[string]interface{}{}
+
+	const q = `
+	SELECT
+		count(1)
+	FROM
+		comments`
+
+	buf := bytes.NewBufferString(q)
+	s.applyFilter(filter, data, buf)
+
+	var count struct {
+		Count int `db:"count"`
+	}
+	if err := database.NamedQueryStruct(ctx, s.log, s.db, buf.String(), data, &count); err != nil {
+		return 0, fmt.Errorf("namedquerystruct: %w", err)
+	}
+
+	return count.Count, nil
+}
+
+// QueryByID gets the specified comment from the database.
+func (s *Store) QueryByID(ctx context.Context, commentID uuid.UUID) (comment.Comment, error) {
+	data := struct {
+		ID string `db:"id"`
+	}{
+		ID: commentID.String(),
+	}
+
+	const q = `
+	SELECT
+		*
+	FROM
+		comments
+	WHERE
+		id = :id`
+
+	var dbCmt dbComment
+	if err := database.NamedQueryStruct(ctx, s.log, s.db, q, data, &dbCmt); err != nil {
+		if errors.Is(err, database.ErrDBNotFound) {
+			return comment.Comment{}, fmt.Errorf("namedquerystruct: %w", comment.ErrNotFound)
+		}
+		return comment.Comment{}, fmt.Errorf("namedquerystruct: %w", err)
+	}
+
+	return toCoreComment(dbCmt), nil
+}
+
+// QueryReplies retrieves every reply below the specified comments, however
+// deep, oldest first.
+func (s *Store) QueryReplies(ctx context.Context, commentIDs []uuid.UUID) ([]comment.Comment, error) {
+	ids := make([]string, len(commentIDs))
+	for i, id := range commentIDs {
+		ids[i] = id.String()
+	}
+
+	data := map[string]interface{}{
+		"comment_ids": dbarray.Array(ids),
+	}
+
+	const q = `
+	WITH RECURSIVE replies AS (
+		SELECT
+			*
+		FROM
+			comments
+		WHERE
+			parent_id = ANY(CAST(:comment_ids AS UUID[]))
+		UNION ALL
+		SELECT
+			c.*
+		FROM
+			comments c
+		JOIN
+			replies r ON c.parent_id = r.id
+	)
+	SELECT
+		*
+	FROM
+		replies
+	ORDER BY
+		date_created ASC, id ASC`
+
+	var dbCmts []dbComment
+	if err := database.NamedQuerySlice(ctx, s.log, s.db, q, data, &dbCmts); err != nil {
+		return nil, fmt.Errorf("namedqueryslice: %w", err)
+	}
+
+	return toCoreCommentSlice(dbCmts), nil
+}
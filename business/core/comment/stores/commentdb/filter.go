@@ -0,0 +1,48 @@
+package commentdb
+
+import (
+	"bytes"
+	"strings"
+
+	"github.com/dmanias/startupers/business/core/comment"
+)
+
+func (s *Store) applyFilter(filter comment.QueryFilter, data map[string]interface{}, buf *bytes.Buffer) {
+	var wc []string
+
+	if filter.OwnerType != nil {
+		data["owner_type"] = filter.OwnerType.Name()
+		wc = append(wc, "owner_type = :owner_type")
+	}
+
+	if filter.OwnerID != nil {
+		data["owner_id"] = filter.OwnerID
+		wc = append(wc, "owner_id = :owner_id")
+	}
+
+	if filter.AuthorID != nil {
+		data["author_id"] = filter.AuthorID
+		wc = append(wc, "author_id = :author_id")
+	}
+
+	if filter.TopLevel != nil {
+		if *filter.TopLevel {
+			wc = append(wc, "parent_id IS NULL")
+		} else {
+			wc = append(wc, "parent_id IS NOT NULL")
+		}
+	}
+
+	if filter.Deleted != nil {
+		if *filter.Deleted {
+			wc = append(wc, "deleted_at IS NOT NULL")
+		} else {
+			wc = append(wc, "deleted_at IS NULL")
+		}
+	}
+
+	if len(wc) > 0 {
+		buf.WriteString(" WHERE ")
+		buf.WriteString(strings.Join(wc, " AND "))
+	}
+}
@@ -0,0 +1,93 @@
+package commentdb
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/dmanias/startupers/business/core/comment"
+	"github.com/google/uuid"
+)
+
+// dbComment represent the structure we need for moving data
+// between the app and the database.
+type dbComment struct {
+	ID          uuid.UUID     `db:"id"`
+	IdeaID      uuid.UUID     `db:"idea_id"`
+	OwnerType   string        `db:"owner_type"`
+	OwnerID     uuid.UUID     `db:"owner_id"`
+	ParentID    uuid.NullUUID `db:"parent_id"`
+	Depth       int           `db:"depth"`
+	AuthorID    uuid.UUID     `db:"author_id"`
+	Content     string        `db:"content"`
+	HiddenAt    sql.NullTime  `db:"hidden_at"`
+	HiddenBy    uuid.NullUUID `db:"hidden_by"`
+	DeletedAt   sql.NullTime  `db:"deleted_at"`
+	DateCreated time.Time     `db:"date_created"`
+	DateUpdated time.Time     `db:"date_updated"`
+}
+
+func toDBComment(cmt comment.Comment) dbComment {
+	return dbComment{
+		ID:        cmt.ID,
+		IdeaID:    cmt.IdeaID,
+		OwnerType: cmt.OwnerType.Name(),
+		OwnerID:   cmt.OwnerID,
+		ParentID: uuid.NullUUID{
+			UUID:  cmt.ParentID,
+			Valid: cmt.IsReply(),
+		},
+		Depth:    cmt.Depth,
+		AuthorID: cmt.AuthorID,
+		Content:  cmt.Content,
+		HiddenAt: sql.NullTime{
+			Time:  cmt.HiddenAt.UTC(),
+			Valid: cmt.Hidden(),
+		},
+		HiddenBy: uuid.NullUUID{
+			UUID:  cmt.HiddenBy,
+			Valid: cmt.HiddenBy != uuid.Nil,
+		},
+		DeletedAt: sql.NullTime{
+			Time:  cmt.DeletedAt.UTC(),
+			Valid: cmt.Deleted(),
+		},
+		DateCreated: cmt.DateCreated.UTC(),
+		DateUpdated: cmt.DateUpdated.UTC(),
+	}
+}
+
+func toCoreComment(dbCmt dbComment) comment.Comment {
+	var hiddenAt time.Time
+	if dbCmt.HiddenAt.Valid {
+		hiddenAt = dbCmt.HiddenAt.Time.In(time.Local)
+	}
+
+	var deletedAt time.Time
+	if dbCmt.DeletedAt.Valid {
+		deletedAt = dbCmt.DeletedAt.Time.In(time.Local)
+	}
+
+	return comment.Comment{
+		ID:          dbCmt.ID,
+		IdeaID:      dbCmt.IdeaID,
+		OwnerType:   comment.MustParseOwnerType(dbCmt.OwnerType),
+		OwnerID:     dbCmt.OwnerID,
+		ParentID:    dbCmt.ParentID.UUID,
+		Depth:       dbCmt.Depth,
+		AuthorID:    dbCmt.AuthorID,
+		Content:     dbCmt.Content,
+		HiddenAt:    hiddenAt,
+		HiddenBy:    dbCmt.HiddenBy.UUID,
+		DeletedAt:   deletedAt,
+		DateCreated: dbCmt.DateCreated.In(time.Local),
+		DateUpdated: dbCmt.DateUpdated.In(time.Local),
+	}
+}
+
+func toCoreCommentSlice(dbCmts []dbComment) []comment.Comment {
+	cmts := make([]comment.Comment, len(dbCmts))
+	for i, dbCmt := range dbCmts {
+		cmts[i] = toCoreComment(dbCmt)
+	}
+	return cmts
+}
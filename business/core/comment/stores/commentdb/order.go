@@ -0,0 +1,22 @@
+package commentdb
+
+import (
+	"fmt"
+
+	"github.com/dmanias/startupers/business/core/comment"
+	"github.com/dmanias/startupers/business/data/order"
+)
+
+var orderByFields = map[string]string{
+	comment.OrderByDateCreated: "date_created",
+	comment.OrderByDateUpdated: "date_updated",
+}
+
+func orderByClause(orderBy order.By) (string, error) {
+	by, exists := orderByFields[orderBy.Field]
+	if !exists {
+		return "", fmt.Errorf("field %q does not exist", orderBy.Field)
+	}
+
+	return " ORDER BY " + by + " " + orderBy.Direction + ", id ASC", nil
+}
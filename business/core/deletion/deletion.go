@@ -0,0 +1,273 @@
+// Package deletion provides the business API for deleting user accounts.
+// Users ask for their account to be deleted and can change their mind during
+// a grace period, after which the account is erased: the ideas the user owns
+// are purged with all of their content, and what the user contributed to the
+// ideas of others is kept but credited to a placeholder deleted user.
+package deletion
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/mail"
+	"time"
+
+	"github.com/dmanias/startupers/business/core/audit"
+	"github.com/dmanias/startupers/business/core/email"
+	"github.com/dmanias/startupers/business/core/idea"
+	"github.com/dmanias/startupers/business/core/user"
+	"github.com/dmanias/startupers/business/data/transaction"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// DeletedUserID is the placeholder user the contributions of erased users are
+// credited to. It can't sign in.
+var DeletedUserID = uuid.MustParse("00000000-0000-4000-8000-000000000000")
+
+// Set of error variables for CRUD operations.
+var (
+	ErrNotFound    = errors.New("account deletion not requested")
+	ErrDeletedUser = errors.New("the deleted user placeholder can't be erased")
+)
+
+// Set of how many ideas and due requests are handled at a time.
+const (
+	purgePageSize = 100
+	erasePageSize = 100
+)
+
+// Storer interface declares the behavior this package needs to perists and
+// retrieve data.
+type Storer interface {
+	ExecuteUnderTransaction(tx transaction.Transaction) (Storer, error)
+	Create(ctx context.Context, req Request) error
+	Delete(ctx context.Context, userID uuid.UUID) error
+	QueryByUserID(ctx context.Context, userID uuid.UUID) (Request, error)
+	QueryDue(ctx context.Context, now time.Time, limit int) ([]Request, error)
+	Erase(ctx context.Context, userID uuid.UUID, deletedUserID uuid.UUID) error
+}
+
+// Core manages the set of APIs for account deletion.
+type Core struct {
+	log         *zap.SugaredLogger
+	beginner    transaction.Beginner
+	storer      Storer
+	idea        *idea.Core
+	email       *email.Core
+	audit       *audit.Core
+	appURL      string
+	gracePeriod time.Duration
+}
+
+// NewCore constructs a core for account deletion api access. Accounts are
+// erased once the grace period after the request is over. The links in the
+// emails point to the web application at appURL.
+func NewCore(log *zap.SugaredLogger, beginner transaction.Beginner, storer Storer, ideaCore *idea.Core, emailCore *email.Core, auditCore *audit.Core, appURL string, gracePeriod time.Duration) *Core {
+	return &Core{
+		log:         log,
+		beginner:    beginner,
+		storer:      storer,
+		idea:        ideaCore,
+		email:       emailCore,
+		audit:       auditCore,
+		appURL:      appURL,
+		gracePeriod: gracePeriod,
+	}
+}
+
+// Request schedules the account of the user to be erased once the grace
+// period is over, and emails the user when that will happen. Asking again
+// keeps the date of the first request.
+func (c *Core) Request(ctx context.Context, usr user.User) (Request, error) {
+	req, err := c.storer.QueryByUserID(ctx, usr.ID)
+	if err == nil {
+		return req, nil
+	}
+	if !errors.Is(err, ErrNotFound) {
+		return Request{}, fmt.Errorf("request: userID[%s]: %w", usr.ID, err)
+	}
+
+	now := time.Now()
+
+	req = Request{
+		UserID:      usr.ID,
+		DateDue:     now.Add(c.gracePeriod),
+		DateCreated: now,
+	}
+
+	if err := c.storer.Create(ctx, req); err != nil {
+		return Request{}, fmt.Errorf("request: userID[%s]: %w", usr.ID, err)
+	}
+
+	// The request stands either way, the user can see it in their account.
+	if err := c.notify(ctx, usr, req); err != nil {
+		c.log.Errorw("deletion", "status", "sending deletion email", "userID", usr.ID, "ERROR", err)
+	}
+
+	return req, nil
+}
+
+// Cancel withdraws the request of the user to delete their account.
+func (c *Core) Cancel(ctx context.Context, userID uuid.UUID) error {
+	if err := c.storer.Delete(ctx, userID); err != nil {
+		return fmt.Errorf("cancel: userID[%s]: %w", userID, err)
+	}
+
+	return nil
+}
+
+// QueryByUserID returns the pending request of the user to delete their
+// account.
+func (c *Core) QueryByUserID(ctx context.Context, userID uuid.UUID) (Request, error) {
+	req, err := c.storer.QueryByUserID(ctx, userID)
+	if err != nil {
+		return Request{}, fmt.Errorf("query: userID[%s]: %w", userID, err)
+	}
+
+	return req, nil
+}
+
+// EraseDue erases the accounts whose grace period is over. The paths of the
+// files the purged ideas referenced are returned so the caller can remove
+// them.
+func (c *Core) EraseDue(ctx context.Context) ([]string, error) {
+	var files []string
+
+	for {
+		// Erased accounts take their request with them, so the first page is
+		// always the next one to erase.
+		reqs, err := c.storer.QueryDue(ctx, time.Now(), erasePageSize)
+		if err != nil {
+			return files, fmt.Errorf("erasedue: query: %w", err)
+		}
+
+		for _, req := range reqs {
+			erased, err := c.Erase(ctx, req.UserID)
+			files = append(files, erased...)
+			if err != nil {
+				return files, fmt.Errorf("erasedue: %w", err)
+			}
+		}
+
+		if len(reqs) < erasePageSize {
+			break
+		}
+	}
+
+	return files, nil
+}
+
+// Erase deletes the account of the user now. The ideas the user owns are
+// purged with all of their content, each in its own transaction, and then
+// the rest of what the user did is anonymised and the account removed in a
+// single transaction. The audit entries, if any, are recorded in that same
+// transaction. An erase that fails part way can be run again. The paths of
+// the files the purged ideas referenced are returned so the caller can remove
+// them.
+func (c *Core) Erase(ctx context.Context, userID uuid.UUID, entries ...audit.NewEntry) ([]string, error) {
+	if userID == DeletedUserID {
+		return nil, ErrDeletedUser
+	}
+
+	files, err := c.purgeIdeas(ctx, userID)
+	if err != nil {
+		return files, fmt.Errorf("erase: userID[%s]: %w", userID, err)
+	}
+
+	f := func(tx transaction.Transaction) error {
+		storer, err := c.storer.ExecuteUnderTransaction(tx)
+		if err != nil {
+			return err
+		}
+
+		if err := storer.Erase(ctx, userID, DeletedUserID); err != nil {
+			return err
+		}
+
+		if len(entries) == 0 {
+			return nil
+		}
+
+		auditCore, err := c.audit.ExecuteUnderTransaction(tx)
+		if err != nil {
+			return err
+		}
+
+		for _, ne := range entries {
+			if _, err := auditCore.Record(ctx, ne); err != nil {
+				return fmt.Errorf("record: %w", err)
+			}
+		}
+
+		return nil
+	}
+
+	if err := transaction.ExecuteUnderTransaction(ctx, c.log, c.beginner, f); err != nil {
+		return files, fmt.Errorf("erase: userID[%s]: %w", userID, err)
+	}
+
+	return files, nil
+}
+
+// =============================================================================
+
+// purgeIdeas purges every idea the user owns, in the trash or not, and
+// returns the paths of the files they referenced.
+func (c *Core) purgeIdeas(ctx context.Context, userID uuid.UUID) ([]string, error) {
+	var files []string
+
+	for _, deleted := range []bool{false, true} {
+		var filter idea.QueryFilter
+		filter.WithUserID(userID)
+		filter.WithDeleted(deleted)
+
+		for {
+			// Purged ideas drop out of the filter, so the first page is
+			// always the next one to purge.
+			ideas, err := c.idea.Query(ctx, filter, idea.DefaultOrderBy, 1, purgePageSize)
+			if err != nil {
+				return files, fmt.Errorf("purgeideas: query: %w", err)
+			}
+
+			for _, idr := range ideas {
+				ideaFiles, err := c.idea.Purge(ctx, idr)
+				if err != nil {
+					return files, fmt.Errorf("purgeideas: %w", err)
+				}
+				files = append(files, ideaFiles...)
+			}
+
+			if len(ideas) < purgePageSize {
+				break
+			}
+		}
+	}
+
+	return files, nil
+}
+
+// notify emails the user when their account will be erased and where to
+// cancel it.
+func (c *Core) notify(ctx context.Context, usr user.User, req Request) error {
+	ne := email.NewEmail{
+		To:       mail.Address{Name: usr.Name, Address: usr.Email.Address},
+		Template: "account_deletion_scheduled",
+		Locale:   usr.Locale,
+		Data: struct {
+			Name string
+			Date string
+			Link string
+		}{
+			Name: usr.Name,
+			Date: req.DateDue.UTC().Format("2 January 2006"),
+			Link: c.appURL + "/account/delete",
+		},
+	}
+
+	if _, err := c.email.Enqueue(ctx, ne); err != nil {
+		return fmt.Errorf("enqueue: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,15 @@
+package deletion
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Request is a user's request to delete their account. The account is erased
+// once the request is due, unless the user cancels it before then.
+type Request struct {
+	UserID      uuid.UUID
+	DateDue     time.Time
+	DateCreated time.Time
+}
@@ -0,0 +1,194 @@
+// Package deletiondb contains account deletion related CRUD functionality.
+package deletiondb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/dmanias/startupers/business/core/deletion"
+	"github.com/dmanias/startupers/business/data/sqldb"
+	"github.com/dmanias/startupers/business/data/transaction"
+	database "github.com/dmanias/startupers/business/sys/database/pgx"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"go.uber.org/zap"
+)
+
+// Store manages the set of APIs for account deletion database access.
+type Store struct {
+	log *zap.SugaredLogger
+	db  sqlx.ExtContext
+}
+
+// NewStore constructs the api for data access.
+func NewStore(log *zap.SugaredLogger, db *sqlx.DB) *Store {
+	return &Store{
+		log: log,
+		db:  db,
+	}
+}
+
+// ExecuteUnderTransaction constructs a new Store value replacing the sqlx DB
+// value with a sqlx DB value that is currently inside a transaction.
+func (s *Store) ExecuteUnderTransaction(tx transaction.Transaction) (deletion.Storer, error) {
+	ec, err := sqldb.GetExtContext(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	s = &Store{
+		log: s.log,
+		db:  ec,
+	}
+
+	return s, nil
+}
+
+// Create inserts a new account deletion request into the database.
+func (s *Store) Create(ctx context.Context, req deletion.Request) error {
+	const q = `
+	INSERT INTO account_deletions
+		(user_id, date_due, date_created)
+	VALUES
+		(:user_id, :date_due, :date_created)
+	ON CONFLICT (user_id) DO NOTHING`
+
+	if err := database.NamedExecContext(ctx, s.log, s.db, q, toDBRequest(req)); err != nil {
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	return nil
+}
+
+// Delete removes the account deletion request of the user.
+func (s *Store) Delete(ctx context.Context, userID uuid.UUID) error {
+	data := struct {
+		UserID string `db:"user_id"`
+	}{
+		UserID: userID.String(),
+	}
+
+	const q = `
+	DELETE FROM
+		account_deletions
+	WHERE
+		user_id = :user_id
+	RETURNING
+		user_id`
+
+	var deleted struct {
+		UserID uuid.UUID `db:"user_id"`
+	}
+	if err := database.NamedQueryStruct(ctx, s.log, s.db, q, data, &deleted); err != nil {
+		if errors.Is(err, database.ErrDBNotFound) {
+			return fmt.Errorf("namedquerystruct: %w", deletion.ErrNotFound)
+		}
+		return fmt.Errorf("namedquerystruct: %w", err)
+	}
+
+	return nil
+}
+
+// QueryByUserID gets the account deletion request of the user.
+func (s *Store) QueryByUserID(ctx context.Context, userID uuid.UUID) (deletion.Request, error) {
+	data := struct {
+		UserID string `db:"user_id"`
+	}{
+		UserID: userID.String(),
+	}
+
+	const q = `
+	SELECT
+		user_id, date_due, date_created
+	FROM
+		account_deletions
+	WHERE
+		user_id = :user_id`
+
+	var dbReq dbRequest
+	if err := database.NamedQueryStruct(ctx, s.log, s.db, q, data, &dbReq); err != nil {
+		if errors.Is(err, database.ErrDBNotFound) {
+			return deletion.Request{}, fmt.Errorf("namedquerystruct: %w", deletion.ErrNotFound)
+		}
+		return deletion.Request{}, fmt.Errorf("namedquerystruct: %w", err)
+	}
+
+	return toCoreRequest(dbReq), nil
+}
+
+// QueryDue retrieves the account deletion requests whose grace period is
+// over, oldest first.
+func (s *Store) QueryDue(ctx context.Context, now time.Time, limit int) ([]deletion.Request, error) {
+	data := struct {
+		Now   time.Time `db:"now"`
+		Limit int       `db:"limit"`
+	}{
+		Now:   now.UTC(),
+		Limit: limit,
+	}
+
+	const q = `
+	SELECT
+		user_id, date_due, date_created
+	FROM
+		account_deletions
+	WHERE
+		date_due <= :now
+	ORDER BY
+		date_due
+	LIMIT :limit`
+
+	var dbReqs []dbRequest
+	if err := database.NamedQuerySlice(ctx, s.log, s.db, q, data, &dbReqs); err != nil {
+		return nil, fmt.Errorf("namedqueryslice: %w", err)
+	}
+
+	return toCoreRequestSlice(dbReqs), nil
+}
+
+// Erase anonymises what the user contributed to the ideas of others and
+// removes the account. It is meant to run in a transaction, once the ideas
+// the user owns are gone. Votes and reactions are personal and can't be
+// credited to one user many times, so they are removed. So are the emails to
+// the user's address in the outbox, which can hold links with tokens. The
+// rest of what belongs to the account is removed with it by the foreign keys.
+func (s *Store) Erase(ctx context.Context, userID uuid.UUID, deletedUserID uuid.UUID) error {
+	data := struct {
+		UserID        string `db:"user_id"`
+		DeletedUserID string `db:"deleted_user_id"`
+	}{
+		UserID:        userID.String(),
+		DeletedUserID: deletedUserID.String(),
+	}
+
+	steps := []struct {
+		name string
+		q    string
+	}{
+		{"posts", `UPDATE posts SET author_id = :deleted_user_id WHERE author_id = :user_id`},
+		{"posts deleted by", `UPDATE posts SET deleted_by = :deleted_user_id WHERE deleted_by = :user_id`},
+		{"challenges deleted by", `UPDATE challenges SET deleted_by = :deleted_user_id WHERE deleted_by = :user_id`},
+		{"ideas deleted by", `UPDATE ideas SET deleted_by = :deleted_user_id WHERE deleted_by = :user_id`},
+		{"ideas collaborators", `UPDATE ideas SET collaborators = array_remove(collaborators, CAST(:user_id AS UUID)) WHERE CAST(:user_id AS UUID) = ANY(collaborators)`},
+		{"comments", `UPDATE comments SET author_id = :deleted_user_id WHERE author_id = :user_id`},
+		{"comments hidden by", `UPDATE comments SET hidden_by = :deleted_user_id WHERE hidden_by = :user_id`},
+		{"idea revisions", `UPDATE idea_revisions SET user_id = :deleted_user_id WHERE user_id = :user_id`},
+		{"idea stage history", `UPDATE idea_stage_history SET user_id = :deleted_user_id WHERE user_id = :user_id`},
+		{"idea invitations", `DELETE FROM idea_invitations WHERE inviter_id = :user_id OR invitee_id = :user_id`},
+		{"idea votes", `DELETE FROM idea_votes WHERE user_id = :user_id`},
+		{"post reactions", `DELETE FROM post_reactions WHERE user_id = :user_id`},
+		{"ai interactions", `DELETE FROM ais WHERE userid = :user_id`},
+		{"email outbox", `DELETE FROM email_outbox AS o USING users AS u WHERE u.id = :user_id AND lower(right(o.recipient, length(u.email) + 2)) = lower('<' || u.email || '>')`},
+		{"user", `DELETE FROM users WHERE id = :user_id`},
+	}
+
+	for _, step := range steps {
+		if err := database.NamedExecContext(ctx, s.log, s.db, step.q, data); err != nil {
+			return fmt.Errorf("namedexeccontext: %s: %w", step.name, err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,40 @@
+package deletiondb
+
+import (
+	"time"
+
+	"github.com/dmanias/startupers/business/core/deletion"
+	"github.com/google/uuid"
+)
+
+// dbRequest represent the structure we need for moving data
+// between the app and the database.
+type dbRequest struct {
+	UserID      uuid.UUID `db:"user_id"`
+	DateDue     time.Time `db:"date_due"`
+	DateCreated time.Time `db:"date_created"`
+}
+
+func toDBRequest(req deletion.Request) dbRequest {
+	return dbRequest{
+		UserID:      req.UserID,
+		DateDue:     req.DateDue.UTC(),
+		DateCreated: req.DateCreated.UTC(),
+	}
+}
+
+func toCoreRequest(dbReq dbRequest) deletion.Request {
+	return deletion.Request{
+		UserID:      dbReq.UserID,
+		DateDue:     dbReq.DateDue.In(time.Local),
+		DateCreated: dbReq.DateCreated.In(time.Local),
+	}
+}
+
+func toCoreRequestSlice(dbReqs []dbRequest) []deletion.Request {
+	reqs := make([]deletion.Request, len(dbReqs))
+	for i, dbReq := range dbReqs {
+		reqs[i] = toCoreRequest(dbReq)
+	}
+	return reqs
+}
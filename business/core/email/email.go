@@ -0,0 +1,234 @@
+// Package email provides the business API for sending email. Emails are
+// rendered from the templates when they are queued and kept in a durable
+// outbox, so they survive restarts. A background job sends what is due and
+// retries failures with an exponential backoff. Emails are removed from the
+// outbox a while after they were sent or given up on.
+package email
+
+import (
+	"context"
+	"embed"
+	"fmt"
+	"io/fs"
+	"net/mail"
+	"time"
+
+	"github.com/dmanias/startupers/business/data/transaction"
+	"github.com/dmanias/startupers/foundation/mailer"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// DefaultLocale is the language emails are written in when there is no
+// template for the language of the recipient.
+const DefaultLocale = "en"
+
+// Settings of the delivery of the outbox.
+const (
+	// MaxAttempts is how many times sending an email is tried before it is
+	// given up on.
+	MaxAttempts = 8
+
+	// Retention is how long emails that were sent or given up on are kept in
+	// the outbox. Their bodies can hold links with tokens, so they are only
+	// kept for as long as it takes to look into a delivery problem.
+	Retention = 24 * time.Hour
+
+	// batchSize is how many emails are claimed by a delivery run.
+	batchSize = 50
+
+	// lease is how long the emails claimed by a delivery run are kept from
+	// other runs. Emails of a run that died are retried once it's over.
+	lease = 5 * time.Minute
+
+	// baseBackoff and maxBackoff bound the wait before the next attempt.
+	baseBackoff = time.Minute
+	maxBackoff  = 6 * time.Hour
+)
+
+//go:embed templates
+var templateFS embed.FS
+
+// templates holds the messages this package knows how to render.
+var templates = mustParseTemplates()
+
+// Storer interface declares the behavior this package needs to perists and
+// retrieve data.
+type Storer interface {
+	ExecuteUnderTransaction(tx transaction.Transaction) (Storer, error)
+	Create(ctx context.Context, e Email) error
+	Update(ctx context.Context, e Email) error
+	Claim(ctx context.Context, now time.Time, until time.Time, limit int) ([]Email, error)
+	DeleteDoneBefore(ctx context.Context, before time.Time) error
+}
+
+// Core manages the set of APIs for email access.
+type Core struct {
+	log    *zap.SugaredLogger
+	storer Storer
+	mailer mailer.Mailer
+	from   mail.Address
+}
+
+// NewCore constructs a core for email api access. Emails are sent through
+// the mailer from the specified address.
+func NewCore(log *zap.SugaredLogger, storer Storer, mlr mailer.Mailer, from mail.Address) *Core {
+	return &Core{
+		log:    log,
+		storer: storer,
+		mailer: mlr,
+		from:   from,
+	}
+}
+
+// ExecuteUnderTransaction constructs a new Core value that will use the
+// specified transaction in any store related calls, so an email is only
+// queued if the change it tells about is committed.
+func (c *Core) ExecuteUnderTransaction(tx transaction.Transaction) (*Core, error) {
+	trS, err := c.storer.ExecuteUnderTransaction(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	c = &Core{
+		log:    c.log,
+		storer: trS,
+		mailer: c.mailer,
+		from:   c.from,
+	}
+
+	return c, nil
+}
+
+// Enqueue renders the email in the locale of the recipient and adds it to the
+// outbox. It is sent by the next delivery run.
+func (c *Core) Enqueue(ctx context.Context, ne NewEmail) (Email, error) {
+	msg, err := templates.Render(ne.Template, ne.Locale, ne.Data)
+	if err != nil {
+		return Email{}, fmt.Errorf("render: %w", err)
+	}
+
+	now := time.Now()
+
+	e := Email{
+		ID:            uuid.New(),
+		To:            ne.To,
+		Template:      ne.Template,
+		Subject:       msg.Subject,
+		Text:          msg.Text,
+		HTML:          msg.HTML,
+		Status:        StatusPending,
+		NextAttemptAt: now,
+		DateCreated:   now,
+		DateUpdated:   now,
+	}
+
+	if err := c.storer.Create(ctx, e); err != nil {
+		return Email{}, fmt.Errorf("create: %w", err)
+	}
+
+	return e, nil
+}
+
+// Deliver sends the emails that are due. Each email is claimed for the run so
+// several replicas can deliver at the same time. A failed email is retried
+// later, and given up on after MaxAttempts.
+func (c *Core) Deliver(ctx context.Context) error {
+	now := time.Now()
+
+	emails, err := c.storer.Claim(ctx, now, now.Add(lease), batchSize)
+	if err != nil {
+		return fmt.Errorf("claim: %w", err)
+	}
+
+	for _, e := range emails {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		if err := c.send(ctx, e); err != nil {
+			c.log.Errorw("email", "status", "updating email", "emailID", e.ID, "ERROR", err)
+		}
+	}
+
+	return nil
+}
+
+// Purge removes the emails that were sent or given up on more than Retention
+// ago.
+func (c *Core) Purge(ctx context.Context) error {
+	if err := c.storer.DeleteDoneBefore(ctx, time.Now().Add(-Retention)); err != nil {
+		return fmt.Errorf("purge: %w", err)
+	}
+
+	return nil
+}
+
+// send tries to send the email and records the outcome.
+func (c *Core) send(ctx context.Context, e Email) error {
+	msg := mailer.Message{
+		From:    c.from,
+		To:      []mail.Address{e.To},
+		Subject: e.Subject,
+		Text:    e.Text,
+		HTML:    e.HTML,
+	}
+
+	now := time.Now()
+	e.Attempts++
+	e.DateUpdated = now
+
+	switch err := c.mailer.Send(ctx, msg); {
+	case err == nil:
+		e.Status = StatusSent
+		e.LastError = ""
+		e.DateSent = now
+
+	case e.Attempts >= MaxAttempts:
+		c.log.Errorw("email", "status", "giving up", "emailID", e.ID, "template", e.Template, "attempts", e.Attempts, "ERROR", err)
+		e.Status = StatusFailed
+		e.LastError = err.Error()
+
+	default:
+		c.log.Infow("email", "status", "retrying", "emailID", e.ID, "template", e.Template, "attempts", e.Attempts, "ERROR", err)
+		e.LastError = err.Error()
+		e.NextAttemptAt = now.Add(Backoff(e.Attempts))
+	}
+
+	if err := c.storer.Update(ctx, e); err != nil {
+		return fmt.Errorf("update: emailID[%s]: %w", e.ID, err)
+	}
+
+	return nil
+}
+
+// Backoff returns how long to wait before trying again after the number of
+// failed attempts. The wait doubles with every attempt, up to a limit.
+func Backoff(attempts int) time.Duration {
+	wait := baseBackoff
+	for i := 1; i < attempts && wait < maxBackoff; i++ {
+		wait *= 2
+	}
+
+	if wait > maxBackoff {
+		return maxBackoff
+	}
+
+	return wait
+}
+
+// mustParseTemplates parses the embedded templates. They are part of the
+// build, so failing to parse them is a programming error.
+func mustParseTemplates() *mailer.Templates {
+	fsys, err := fs.Sub(templateFS, "templates")
+	if err != nil {
+		panic(err)
+	}
+
+	tmpls, err := mailer.ParseTemplates(fsys, DefaultLocale)
+	if err != nil {
+		panic(err)
+	}
+
+	return tmpls
+}
@@ -0,0 +1,249 @@
+package email_test
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/mail"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dmanias/startupers/business/core/email"
+	"github.com/dmanias/startupers/business/core/email/stores/emaildb"
+	"github.com/dmanias/startupers/business/data/dbtest"
+	"github.com/dmanias/startupers/foundation/docker"
+	"github.com/dmanias/startupers/foundation/mailer"
+	"github.com/dmanias/startupers/foundation/mailer/mailertest"
+	"github.com/google/uuid"
+)
+
+var c *docker.Container
+
+func TestMain(m *testing.M) {
+	var err error
+	c, err = dbtest.StartDB()
+	if err != nil {
+		fmt.Println(err)
+	}
+
+	code := m.Run()
+
+	if c != nil {
+		dbtest.StopDB(c)
+	}
+
+	os.Exit(code)
+}
+
+// outboxRow is what the outbox holds about an email after a delivery run.
+type outboxRow struct {
+	Status        string       `db:"status"`
+	Attempts      int          `db:"attempts"`
+	LastError     string       `db:"last_error"`
+	NextAttemptAt time.Time    `db:"next_attempt_at"`
+	DateSent      sql.NullTime `db:"date_sent"`
+}
+
+func queryOutbox(t *testing.T, test *dbtest.Test, emailID uuid.UUID) outboxRow {
+	t.Helper()
+
+	var row outboxRow
+	const q = `SELECT status, attempts, last_error, next_attempt_at, date_sent FROM email_outbox WHERE id = $1`
+	if err := test.DB.GetContext(context.Background(), &row, q, emailID); err != nil {
+		t.Fatalf("Should be able to query the outbox: %s", err)
+	}
+
+	return row
+}
+
+func makeDue(t *testing.T, test *dbtest.Test, emailID uuid.UUID) {
+	t.Helper()
+
+	const q = `UPDATE email_outbox SET next_attempt_at = now() - interval '1 second' WHERE id = $1`
+	if _, err := test.DB.ExecContext(context.Background(), q, emailID); err != nil {
+		t.Fatalf("Should be able to make the email due: %s", err)
+	}
+}
+
+func newOutbox(t *testing.T) (*dbtest.Test, *mailertest.Server, *email.Core) {
+	t.Helper()
+
+	if c == nil {
+		t.Skip("Skipping, the database container could not be started")
+	}
+
+	test := dbtest.NewTest(t, c)
+	t.Cleanup(test.Teardown)
+
+	srv := mailertest.NewServer(t)
+	from := mail.Address{Name: "Startupers", Address: "no-reply@startupers.test"}
+	core := email.NewCore(test.Log, emaildb.NewStore(test.Log, test.DB), mailer.NewSMTP(srv.Config()), from)
+
+	return test, srv, core
+}
+
+func enqueue(t *testing.T, core *email.Core) email.Email {
+	t.Helper()
+
+	e, err := core.Enqueue(context.Background(), email.NewEmail{
+		To:       mail.Address{Name: "Jane", Address: "jane@example.com"},
+		Template: "invitation_received",
+		Locale:   "en",
+		Data: map[string]string{
+			"Name":      "Jane",
+			"IdeaTitle": "Solar kiosks",
+			"Link":      "https://startupers.test/invitations/accept?token=abc",
+		},
+	})
+	if err != nil {
+		t.Fatalf("Should be able to enqueue the email: %s", err)
+	}
+
+	return e
+}
+
+func Test_OutboxRetry(t *testing.T) {
+	test, srv, core := newOutbox(t)
+	ctx := context.Background()
+
+	e := enqueue(t, core)
+
+	// The server turns the first attempt away, so the email is kept for a
+	// retry after the backoff.
+	srv.Fail(1)
+
+	start := time.Now()
+	if err := core.Deliver(ctx); err != nil {
+		t.Fatalf("Should be able to deliver the outbox: %s", err)
+	}
+
+	if msgs := srv.Messages(); len(msgs) != 0 {
+		t.Fatalf("Should not have sent the email, got %d messages", len(msgs))
+	}
+
+	row := queryOutbox(t, test, e.ID)
+	if row.Status != email.StatusPending.Name() || row.Attempts != 1 || row.LastError == "" {
+		t.Fatalf("Should be pending after one failed attempt, got %+v", row)
+	}
+	if row.NextAttemptAt.Before(start.Add(email.Backoff(1))) {
+		t.Fatalf("Should wait %s before the next attempt, next attempt at %s", email.Backoff(1), row.NextAttemptAt)
+	}
+
+	// Before the backoff is over the email isn't tried again.
+	if err := core.Deliver(ctx); err != nil {
+		t.Fatalf("Should be able to deliver the outbox: %s", err)
+	}
+
+	if msgs := srv.Messages(); len(msgs) != 0 {
+		t.Fatalf("Should not have retried the email before it is due, got %d messages", len(msgs))
+	}
+
+	// Once it is due it is sent.
+	makeDue(t, test, e.ID)
+
+	if err := core.Deliver(ctx); err != nil {
+		t.Fatalf("Should be able to deliver the outbox: %s", err)
+	}
+
+	msgs := srv.Messages()
+	if len(msgs) != 1 {
+		t.Fatalf("Should have sent the email, got %d messages", len(msgs))
+	}
+	if len(msgs[0].To) != 1 || msgs[0].To[0] != "jane@example.com" {
+		t.Errorf("Should have sent the email to jane@example.com, got %v", msgs[0].To)
+	}
+	if !strings.Contains(string(msgs[0].Data), "Solar kiosks") {
+		t.Errorf("Should have rendered the template in the email:\n%s", msgs[0].Data)
+	}
+
+	row = queryOutbox(t, test, e.ID)
+	if row.Status != email.StatusSent.Name() || row.Attempts != 2 || row.LastError != "" || !row.DateSent.Valid {
+		t.Fatalf("Should be sent after the second attempt, got %+v", row)
+	}
+}
+
+func Test_OutboxGiveUp(t *testing.T) {
+	test, srv, core := newOutbox(t)
+	ctx := context.Background()
+
+	e := enqueue(t, core)
+
+	// Every attempt fails, and the email is given up on after the last one.
+	srv.Fail(email.MaxAttempts)
+
+	for i := 1; i <= email.MaxAttempts; i++ {
+		makeDue(t, test, e.ID)
+
+		if err := core.Deliver(ctx); err != nil {
+			t.Fatalf("Should be able to deliver the outbox: %s", err)
+		}
+
+		row := queryOutbox(t, test, e.ID)
+		if row.Attempts != i {
+			t.Fatalf("Should have made %d attempts, got %d", i, row.Attempts)
+		}
+
+		want := email.StatusPending
+		if i == email.MaxAttempts {
+			want = email.StatusFailed
+		}
+		if row.Status != want.Name() {
+			t.Fatalf("Should be %s after %d attempts, got %s", want.Name(), i, row.Status)
+		}
+	}
+
+	if msgs := srv.Messages(); len(msgs) != 0 {
+		t.Fatalf("Should not have sent the email, got %d messages", len(msgs))
+	}
+}
+
+func Test_OutboxPurge(t *testing.T) {
+	test, _, core := newOutbox(t)
+	ctx := context.Background()
+
+	sent := enqueue(t, core)
+	recent := enqueue(t, core)
+
+	if err := core.Deliver(ctx); err != nil {
+		t.Fatalf("Should be able to deliver the outbox: %s", err)
+	}
+
+	pending := enqueue(t, core)
+
+	// Only the sent email that is older than the retention period is due to
+	// be purged. Pending emails are kept however old they are.
+	for _, id := range []uuid.UUID{sent.ID, pending.ID} {
+		const q = `UPDATE email_outbox SET date_updated = $1 WHERE id = $2`
+		if _, err := test.DB.ExecContext(ctx, q, time.Now().Add(-2*email.Retention), id); err != nil {
+			t.Fatalf("Should be able to age the email: %s", err)
+		}
+	}
+
+	if err := core.Purge(ctx); err != nil {
+		t.Fatalf("Should be able to purge the outbox: %s", err)
+	}
+
+	tests := []struct {
+		name string
+		id   uuid.UUID
+		want bool
+	}{
+		{"sent before the retention period", sent.ID, false},
+		{"sent within the retention period", recent.ID, true},
+		{"pending", pending.ID, true},
+	}
+
+	for _, tt := range tests {
+		var count int
+		const q = `SELECT count(1) FROM email_outbox WHERE id = $1`
+		if err := test.DB.GetContext(ctx, &count, q, tt.id); err != nil {
+			t.Fatalf("Should be able to query the outbox: %s", err)
+		}
+
+		if got := count == 1; got != tt.want {
+			t.Errorf("%s: should be kept %t, got %t", tt.name, tt.want, got)
+		}
+	}
+}
@@ -0,0 +1,33 @@
+package email
+
+import (
+	"net/mail"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Email is a message waiting in the outbox to be sent, or that was sent.
+type Email struct {
+	ID            uuid.UUID
+	To            mail.Address
+	Template      string
+	Subject       string
+	Text          string
+	HTML          string
+	Status        Status
+	Attempts      int
+	LastError     string
+	NextAttemptAt time.Time
+	DateSent      time.Time
+	DateCreated   time.Time
+	DateUpdated   time.Time
+}
+
+// NewEmail contains what is needed to render an email from a template.
+type NewEmail struct {
+	To       mail.Address
+	Template string
+	Locale   string
+	Data     any
+}
@@ -0,0 +1,155 @@
+// Package emaildb contains email outbox related CRUD functionality.
+package emaildb
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/dmanias/startupers/business/core/email"
+	"github.com/dmanias/startupers/business/data/sqldb"
+	"github.com/dmanias/startupers/business/data/transaction"
+	database "github.com/dmanias/startupers/business/sys/database/pgx"
+	"github.com/jmoiron/sqlx"
+	"go.uber.org/zap"
+)
+
+// Store manages the set of APIs for email database access.
+type Store struct {
+	log *zap.SugaredLogger
+	db  sqlx.ExtContext
+}
+
+// NewStore constructs the api for data access.
+func NewStore(log *zap.SugaredLogger, db *sqlx.DB) *Store {
+	return &Store{
+		log: log,
+		db:  db,
+	}
+}
+
+// ExecuteUnderTransaction constructs a new Store value replacing the sqlx DB
+// value with a sqlx DB value that is currently inside a transaction.
+func (s *Store) ExecuteUnderTransaction(tx transaction.Transaction) (email.Storer, error) {
+	ec, err := sqldb.GetExtContext(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	s = &Store{
+		log: s.log,
+		db:  ec,
+	}
+
+	return s, nil
+}
+
+// Create adds a new email to the outbox.
+func (s *Store) Create(ctx context.Context, e email.Email) error {
+	const q = `
+	INSERT INTO email_outbox
+		(id, recipient, template, subject, text_body, html_body, status, attempts, last_error, next_attempt_at, date_sent, date_created, date_updated)
+	VALUES
+		(:id, :recipient, :template, :subject, :text_body, :html_body, :status, :attempts, :last_error, :next_attempt_at, :date_sent, :date_created, :date_updated)`
+
+	if err := database.NamedExecContext(ctx, s.log, s.db, q, toDBEmail(e)); err != nil {
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	return nil
+}
+
+// Update records the outcome of an attempt to send the email.
+func (s *Store) Update(ctx context.Context, e email.Email) error {
+	const q = `
+	UPDATE
+		email_outbox
+	SET
+		"status" = :status,
+		"attempts" = :attempts,
+		"last_error" = :last_error,
+		"next_attempt_at" = :next_attempt_at,
+		"date_sent" = :date_sent,
+		"date_updated" = :date_updated
+	WHERE
+		id = :id`
+
+	if err := database.NamedExecContext(ctx, s.log, s.db, q, toDBEmail(e)); err != nil {
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	return nil
+}
+
+// Claim returns the pending emails due by now, oldest first, and keeps them
+// from being claimed again until the specified time. Rows claimed by another
+// replica at the same time are skipped.
+func (s *Store) Claim(ctx context.Context, now time.Time, until time.Time, limit int) ([]email.Email, error) {
+	data := struct {
+		Status string    `db:"status"`
+		Now    time.Time `db:"now"`
+		Until  time.Time `db:"until"`
+		Limit  int       `db:"limit"`
+	}{
+		Status: email.StatusPending.Name(),
+		Now:    now.UTC(),
+		Until:  until.UTC(),
+		Limit:  limit,
+	}
+
+	const q = `
+	UPDATE
+		email_outbox
+	SET
+		"next_attempt_at" = :until
+	WHERE
+		id IN (
+			SELECT
+				id
+			FROM
+				email_outbox
+			WHERE
+				status = :status AND
+				next_attempt_at <= :now
+			ORDER BY
+				next_attempt_at
+			LIMIT :limit
+			FOR UPDATE SKIP LOCKED
+		)
+	RETURNING
+		*`
+
+	var dbEs []dbEmail
+	if err := database.NamedQuerySlice(ctx, s.log, s.db, q, data, &dbEs); err != nil {
+		return nil, fmt.Errorf("namedqueryslice: %w", err)
+	}
+
+	return toCoreEmailSlice(dbEs), nil
+}
+
+// DeleteDoneBefore removes the emails that were sent or given up on, and
+// haven't been touched since the specified time.
+func (s *Store) DeleteDoneBefore(ctx context.Context, before time.Time) error {
+	data := struct {
+		Sent   string    `db:"sent"`
+		Failed string    `db:"failed"`
+		Before time.Time `db:"before"`
+	}{
+		Sent:   email.StatusSent.Name(),
+		Failed: email.StatusFailed.Name(),
+		Before: before.UTC(),
+	}
+
+	const q = `
+	DELETE FROM
+		email_outbox
+	WHERE
+		status IN (:sent, :failed) AND
+		date_updated < :before`
+
+	if err := database.NamedExecContext(ctx, s.log, s.db, q, data); err != nil {
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,85 @@
+package emaildb
+
+import (
+	"database/sql"
+	"net/mail"
+	"time"
+
+	"github.com/dmanias/startupers/business/core/email"
+	"github.com/google/uuid"
+)
+
+// dbEmail represent the structure we need for moving data
+// between the app and the database.
+type dbEmail struct {
+	ID            uuid.UUID    `db:"id"`
+	Recipient     string       `db:"recipient"`
+	Template      string       `db:"template"`
+	Subject       string       `db:"subject"`
+	TextBody      string       `db:"text_body"`
+	HTMLBody      string       `db:"html_body"`
+	Status        string       `db:"status"`
+	Attempts      int          `db:"attempts"`
+	LastError     string       `db:"last_error"`
+	NextAttemptAt time.Time    `db:"next_attempt_at"`
+	DateSent      sql.NullTime `db:"date_sent"`
+	DateCreated   time.Time    `db:"date_created"`
+	DateUpdated   time.Time    `db:"date_updated"`
+}
+
+func toDBEmail(e email.Email) dbEmail {
+	return dbEmail{
+		ID:            e.ID,
+		Recipient:     e.To.String(),
+		Template:      e.Template,
+		Subject:       e.Subject,
+		TextBody:      e.Text,
+		HTMLBody:      e.HTML,
+		Status:        e.Status.Name(),
+		Attempts:      e.Attempts,
+		LastError:     e.LastError,
+		NextAttemptAt: e.NextAttemptAt.UTC(),
+		DateSent: sql.NullTime{
+			Time:  e.DateSent.UTC(),
+			Valid: !e.DateSent.IsZero(),
+		},
+		DateCreated: e.DateCreated.UTC(),
+		DateUpdated: e.DateUpdated.UTC(),
+	}
+}
+
+func toCoreEmail(dbE dbEmail) email.Email {
+	to, err := mail.ParseAddress(dbE.Recipient)
+	if err != nil {
+		to = &mail.Address{Address: dbE.Recipient}
+	}
+
+	var dateSent time.Time
+	if dbE.DateSent.Valid {
+		dateSent = dbE.DateSent.Time.In(time.Local)
+	}
+
+	return email.Email{
+		ID:            dbE.ID,
+		To:            *to,
+		Template:      dbE.Template,
+		Subject:       dbE.Subject,
+		Text:          dbE.TextBody,
+		HTML:          dbE.HTMLBody,
+		Status:        email.MustParseStatus(dbE.Status),
+		Attempts:      dbE.Attempts,
+		LastError:     dbE.LastError,
+		NextAttemptAt: dbE.NextAttemptAt.In(time.Local),
+		DateSent:      dateSent,
+		DateCreated:   dbE.DateCreated.In(time.Local),
+		DateUpdated:   dbE.DateUpdated.In(time.Local),
+	}
+}
+
+func toCoreEmailSlice(dbEs []dbEmail) []email.Email {
+	es := make([]email.Email, len(dbEs))
+	for i, dbE := range dbEs {
+		es[i] = toCoreEmail(dbE)
+	}
+	return es
+}
@@ -0,0 +1,66 @@
+package explore
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// ErrInvalidCursor is returned when a cursor can't be decoded.
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// Cursor marks the position in the feed after which the next page starts.
+// The zero value starts from the top of the feed.
+type Cursor struct {
+	Score  float64
+	IdeaID uuid.UUID
+}
+
+// IsZero reports whether the cursor points to the top of the feed.
+func (c Cursor) IsZero() bool {
+	return c.IdeaID == uuid.Nil
+}
+
+// Encode returns the opaque string representation of the cursor.
+func (c Cursor) Encode() string {
+	if c.IsZero() {
+		return ""
+	}
+
+	s := strconv.FormatFloat(c.Score, 'g', -1, 64) + "|" + c.IdeaID.String()
+	return base64.RawURLEncoding.EncodeToString([]byte(s))
+}
+
+// ParseCursor decodes a cursor previously returned by Encode. An empty string
+// is the cursor for the top of the feed.
+func ParseCursor(value string) (Cursor, error) {
+	if value == "" {
+		return Cursor{}, nil
+	}
+
+	b, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return Cursor{}, ErrInvalidCursor
+	}
+
+	score, id, found := strings.Cut(string(b), "|")
+	if !found {
+		return Cursor{}, ErrInvalidCursor
+	}
+
+	s, err := strconv.ParseFloat(score, 64)
+	if err != nil {
+		return Cursor{}, fmt.Errorf("%w: %s", ErrInvalidCursor, err)
+	}
+
+	ideaID, err := uuid.Parse(id)
+	if err != nil {
+		return Cursor{}, fmt.Errorf("%w: %s", ErrInvalidCursor, err)
+	}
+
+	return Cursor{Score: s, IdeaID: ideaID}, nil
+}
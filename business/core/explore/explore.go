@@ -0,0 +1,130 @@
+// Package explore provides the business API for discovering public ideas
+// through a feed ranked by trending score.
+package explore
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/dmanias/startupers/business/core/idea"
+	"github.com/dmanias/startupers/business/data/transaction"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// Storer interface declares the behavior this package needs to perists and
+// retrieve data.
+type Storer interface {
+	ExecuteUnderTransaction(tx transaction.Transaction) (Storer, error)
+	UpsertScores(ctx context.Context, weights Weights, now time.Time) (int, error)
+	DeleteStaleScores(ctx context.Context, before time.Time) error
+	QueryFeed(ctx context.Context, filter QueryFilter, cursor Cursor, limit int) ([]Score, error)
+}
+
+// Core manages the set of APIs for explore access.
+type Core struct {
+	log      *zap.SugaredLogger
+	beginner transaction.Beginner
+	storer   Storer
+	idea     *idea.Core
+}
+
+// NewCore constructs a core for explore api access.
+func NewCore(log *zap.SugaredLogger, beginner transaction.Beginner, storer Storer, ideaCore *idea.Core) *Core {
+	return &Core{
+		log:      log,
+		beginner: beginner,
+		storer:   storer,
+		idea:     ideaCore,
+	}
+}
+
+// RecomputeScores computes the trending score of every public idea and drops
+// the scores of ideas that are no longer public. It is meant to be run
+// periodically; the feed only reads the stored scores.
+func (c *Core) RecomputeScores(ctx context.Context, weights Weights) error {
+	// Scores not stamped with this exact time are deleted afterwards, so it
+	// has to survive the round trip to the database unchanged.
+	now := time.Now().Truncate(time.Microsecond)
+
+	var n int
+	f := func(tx transaction.Transaction) error {
+		storer, err := c.storer.ExecuteUnderTransaction(tx)
+		if err != nil {
+			return err
+		}
+
+		n, err = storer.UpsertScores(ctx, weights, now)
+		if err != nil {
+			return fmt.Errorf("upsertscores: %w", err)
+		}
+
+		if err := storer.DeleteStaleScores(ctx, now); err != nil {
+			return fmt.Errorf("deletestalescores: %w", err)
+		}
+
+		return nil
+	}
+
+	if err := transaction.ExecuteUnderTransaction(ctx, c.log, c.beginner, f); err != nil {
+		return fmt.Errorf("recompute: %w", err)
+	}
+
+	c.log.Infow("explore", "status", "scores recomputed", "ideas", n)
+
+	return nil
+}
+
+// QueryFeed returns a page of public ideas ordered by trending score, and the
+// cursor for the next page. The returned cursor is zero on the last page.
+func (c *Core) QueryFeed(ctx context.Context, filter QueryFilter, cursor Cursor, limit int) ([]Item, Cursor, error) {
+	if err := filter.Validate(); err != nil {
+		return nil, Cursor{}, err
+	}
+
+	// Ask for one more row to know whether there is a next page.
+	scores, err := c.storer.QueryFeed(ctx, filter, cursor, limit+1)
+	if err != nil {
+		return nil, Cursor{}, fmt.Errorf("queryfeed: %w", err)
+	}
+
+	var next Cursor
+	if len(scores) > limit {
+		scores = scores[:limit]
+		last := scores[len(scores)-1]
+		next = Cursor{Score: last.Score, IdeaID: last.IdeaID}
+	}
+
+	if len(scores) == 0 {
+		return nil, next, nil
+	}
+
+	ids := make([]uuid.UUID, len(scores))
+	for i, s := range scores {
+		ids[i] = s.IdeaID
+	}
+
+	ideas, err := c.idea.QueryByIDs(ctx, ids)
+	if err != nil {
+		return nil, Cursor{}, fmt.Errorf("queryfeed: %w", err)
+	}
+
+	byID := make(map[uuid.UUID]idea.Idea, len(ideas))
+	for _, idr := range ideas {
+		byID[idr.ID] = idr
+	}
+
+	// Keep the feed order. An idea deleted or made private since the feed
+	// was read is left out.
+	items := make([]Item, 0, len(scores))
+	for _, s := range scores {
+		idr, exists := byID[s.IdeaID]
+		if !exists || idr.Privacy != idea.PrivacyPublic {
+			continue
+		}
+		items = append(items, Item{Idea: idr, Score: s.Score})
+	}
+
+	return items, next, nil
+}
@@ -0,0 +1,32 @@
+package explore
+
+import (
+	"fmt"
+
+	"github.com/dmanias/startupers/business/sys/validate"
+)
+
+// QueryFilter holds the available fields the feed can be filtered on.
+type QueryFilter struct {
+	Category *string `validate:"omitempty"`
+	Tag      *string `validate:"omitempty"`
+}
+
+// Validate checks the data in the model is considered clean.
+func (qf *QueryFilter) Validate() error {
+	if err := validate.Check(qf); err != nil {
+		return fmt.Errorf("validate: %w", err)
+	}
+	return nil
+}
+
+// WithCategory sets the Category field of the QueryFilter value. Ideas in
+// its subcategories match as well.
+func (qf *QueryFilter) WithCategory(category string) {
+	qf.Category = &category
+}
+
+// WithTag sets the Tag field of the QueryFilter value.
+func (qf *QueryFilter) WithTag(tag string) {
+	qf.Tag = &tag
+}
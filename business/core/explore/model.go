@@ -0,0 +1,33 @@
+package explore
+
+import (
+	"time"
+
+	"github.com/dmanias/startupers/business/core/idea"
+	"github.com/google/uuid"
+)
+
+// Score is the trending score computed for a public idea.
+type Score struct {
+	IdeaID       uuid.UUID
+	Score        float64
+	DateComputed time.Time
+}
+
+// Item is an idea in the explore feed with its trending score.
+type Item struct {
+	Idea  idea.Idea
+	Score float64
+}
+
+// Weights configures how the trending score is computed. Activity within the
+// window raises the score and the score decays with the age of the idea:
+//
+//	score = (1 + Posts*recent posts + Votes*recent votes + Follows*recent follows) / (age in hours + 2)^Gravity
+type Weights struct {
+	Posts   float64
+	Votes   float64
+	Follows float64
+	Gravity float64
+	Window  time.Duration
+}
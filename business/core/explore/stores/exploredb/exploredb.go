@@ -0,0 +1,181 @@
+// Package exploredb contains trending score related database functionality.
+package exploredb
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/dmanias/startupers/business/core/explore"
+	"github.com/dmanias/startupers/business/core/follow"
+	"github.com/dmanias/startupers/business/core/idea"
+	"github.com/dmanias/startupers/business/data/sqldb"
+	"github.com/dmanias/startupers/business/data/transaction"
+	database "github.com/dmanias/startupers/business/sys/database/pgx"
+	"github.com/jmoiron/sqlx"
+	"go.uber.org/zap"
+)
+
+// Store manages the set of APIs for trending score database access.
+type Store struct {
+	log *zap.SugaredLogger
+	db  sqlx.ExtContext
+}
+
+// NewStore constructs the api for data access.
+func NewStore(log *zap.SugaredLogger, db *sqlx.DB) *Store {
+	return &Store{
+		log: log,
+		db:  db,
+	}
+}
+
+// ExecuteUnderTransaction constructs a new Store value replacing the sqlx DB
+// value with a sqlx DB value that is currently inside a transaction.
+func (s *Store) ExecuteUnderTransaction(tx transaction.Transaction) (explore.Storer, error) {
+	ec, err := sqldb.GetExtContext(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	s = &Store{
+		log: s.log,
+		db:  ec,
+	}
+
+	return s, nil
+}
+
+// UpsertScores computes and stores the trending score of every public idea,
+// returning the number of ideas scored.
+func (s *Store) UpsertScores(ctx context.Context, weights explore.Weights, now time.Time) (int, error) {
+	data := map[string]interface{}{
+		"now":            now.UTC(),
+		"since":          now.Add(-weights.Window).UTC(),
+		"posts_weight":   weights.Posts,
+		"votes_weight":   weights.Votes,
+		"follows_weight": weights.Follows,
+		"gravity":        weights.Gravity,
+		"privacy_public": idea.PrivacyPublic.Name(),
+		"target_idea":    follow.TargetTypeIdea.Name(),
+	}
+
+	const q = `
+	WITH scored AS (
+		INSERT INTO idea_scores
+			(idea_id, score, date_computed)
+		SELECT
+			i.id,
+			(1 + CAST(:posts_weight AS DOUBLE PRECISION) * coalesce(p.recent_posts, 0) +
+				CAST(:votes_weight AS DOUBLE PRECISION) * coalesce(v.recent_votes, 0) +
+				CAST(:follows_weight AS DOUBLE PRECISION) * coalesce(f.recent_follows, 0)) /
+				power(greatest(CAST(extract(EPOCH FROM (CAST(:now AS TIMESTAMPTZ) - i.date_created)) AS DOUBLE PRECISION) / 3600, 0) + 2,
+					CAST(:gravity AS DOUBLE PRECISION)),
+			:now
+		FROM
+			ideas AS i
+		LEFT JOIN (
+			SELECT
+				idea_id, count(1) AS recent_posts
+			FROM
+				posts
+			WHERE
+				date_created >= :since AND
+				deleted_at IS NULL
+			GROUP BY
+				idea_id
+		) AS p ON p.idea_id = i.id
+		LEFT JOIN (
+			SELECT
+				idea_id, count(1) AS recent_votes
+			FROM
+				idea_votes
+			WHERE
+				date_created >= :since
+			GROUP BY
+				idea_id
+		) AS v ON v.idea_id = i.id
+		LEFT JOIN (
+			SELECT
+				target_id AS idea_id, count(1) AS recent_follows
+			FROM
+				follows
+			WHERE
+				target_type = :target_idea AND
+				date_created >= :since
+			GROUP BY
+				target_id
+		) AS f ON f.idea_id = i.id
+		WHERE
+			i.privacy = :privacy_public AND
+			i.deleted_at IS NULL AND
+			i.date_created IS NOT NULL
+		ON CONFLICT (idea_id) DO UPDATE
+			SET score = EXCLUDED.score,
+				date_computed = EXCLUDED.date_computed
+		RETURNING 1
+	)
+	SELECT
+		count(1)
+	FROM
+		scored`
+
+	var count struct {
+		Count int `db:"count"`
+	}
+	if err := database.NamedQueryStruct(ctx, s.log, s.db, q, data, &count); err != nil {
+		return 0, fmt.Errorf("namedquerystruct: %w", err)
+	}
+
+	return count.Count, nil
+}
+
+// DeleteStaleScores removes the scores that weren't recomputed at the
+// specified time, which belong to ideas that are no longer public.
+func (s *Store) DeleteStaleScores(ctx context.Context, before time.Time) error {
+	data := struct {
+		Before time.Time `db:"before"`
+	}{
+		Before: before.UTC(),
+	}
+
+	const q = `
+	DELETE FROM
+		idea_scores
+	WHERE
+		date_computed < :before`
+
+	if err := database.NamedExecContext(ctx, s.log, s.db, q, data); err != nil {
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	return nil
+}
+
+// QueryFeed retrieves the scores of the public ideas after the cursor, highest
+// first.
+func (s *Store) QueryFeed(ctx context.Context, filter explore.QueryFilter, cursor explore.Cursor, limit int) ([]explore.Score, error) {
+	data := map[string]interface{}{
+		"limit": limit,
+	}
+
+	const q = `
+	SELECT
+		s.idea_id, s.score, s.date_computed
+	FROM
+		idea_scores AS s
+	JOIN
+		ideas AS i ON i.id = s.idea_id`
+
+	buf := bytes.NewBufferString(q)
+	s.applyFilter(filter, cursor, data, buf)
+	buf.WriteString(" ORDER BY s.score DESC, s.idea_id DESC FETCH FIRST :limit ROWS ONLY")
+
+	var dbScores []dbScore
+	if err := database.NamedQuerySlice(ctx, s.log, s.db, buf.String(), data, &dbScores); err != nil {
+		return nil, fmt.Errorf("namedqueryslice: %w", err)
+	}
+
+	return toCoreScoreSlice(dbScores), nil
+}
@@ -0,0 +1,36 @@
+package exploredb
+
+import (
+	"bytes"
+	"strings"
+
+	"github.com/dmanias/startupers/business/core/explore"
+	"github.com/dmanias/startupers/business/core/idea"
+)
+
+// applyFilter adds the feed conditions. The query is expected to join
+// idea_scores as s with the ideas table as i.
+func (s *Store) applyFilter(filter explore.QueryFilter, cursor explore.Cursor, data map[string]interface{}, buf *bytes.Buffer) {
+	data["privacy_public"] = idea.PrivacyPublic.Name()
+
+	wc := []string{"i.privacy = :privacy_public", "i.deleted_at IS NULL"}
+
+	if filter.Category != nil {
+		data["category"] = *filter.Category
+		wc = append(wc, "(i.category = :category OR i.category IN (SELECT category_subtree(:category)))")
+	}
+
+	if filter.Tag != nil {
+		data["tag"] = *filter.Tag
+		wc = append(wc, "i.tags @> ARRAY[:tag]")
+	}
+
+	if !cursor.IsZero() {
+		data["cursor_score"] = cursor.Score
+		data["cursor_idea_id"] = cursor.IdeaID
+		wc = append(wc, "(s.score, s.idea_id) < (:cursor_score, :cursor_idea_id)")
+	}
+
+	buf.WriteString(" WHERE ")
+	buf.WriteString(strings.Join(wc, " AND "))
+}
@@ -0,0 +1,26 @@
+package exploredb
+
+import (
+	"time"
+
+	"github.com/dmanias/startupers/business/core/explore"
+	"github.com/google/uuid"
+)
+
+type dbScore struct {
+	IdeaID       uuid.UUID `db:"idea_id"`
+	Score        float64   `db:"score"`
+	DateComputed time.Time `db:"date_computed"`
+}
+
+func toCoreScoreSlice(dbScores []dbScore) []explore.Score {
+	scores := make([]explore.Score, len(dbScores))
+	for i, dbScore := range dbScores {
+		scores[i] = explore.Score{
+			IdeaID:       dbScore.IdeaID,
+			Score:        dbScore.Score,
+			DateComputed: dbScore.DateComputed.In(time.Local),
+		}
+	}
+	return scores
+}
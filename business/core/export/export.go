@@ -0,0 +1,256 @@
+// Package export provides the business API for users to get a copy of their
+// data. An export is requested, built by a background job into a ZIP archive
+// of JSON files, one per kind of data, and can be downloaded until it
+// expires.
+package export
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/mail"
+	"time"
+
+	"github.com/dmanias/startupers/business/core/email"
+	"github.com/dmanias/startupers/business/core/user"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// Set of error variables for CRUD operations.
+var (
+	ErrNotFound = errors.New("export not found")
+	ErrNotReady = errors.New("export is not ready")
+)
+
+// staleAfter is how long an export can be running before it is considered
+// abandoned, by an instance that stopped, and is built again.
+const staleAfter = 15 * time.Minute
+
+// Storer interface declares the behavior this package needs to perists and
+// retrieve data.
+type Storer interface {
+	Create(ctx context.Context, exp Export) error
+	Update(ctx context.Context, exp Export) error
+	QueryByID(ctx context.Context, exportID uuid.UUID) (Export, error)
+	QueryByUserID(ctx context.Context, userID uuid.UUID) ([]Export, error)
+	Claim(ctx context.Context, now time.Time, staleBefore time.Time) (Export, error)
+	QuerySections(ctx context.Context, userID uuid.UUID) ([]Section, error)
+	DeleteExpired(ctx context.Context, now time.Time) error
+}
+
+// Core manages the set of APIs for export access.
+type Core struct {
+	log    *zap.SugaredLogger
+	storer Storer
+	user   *user.Core
+	email  *email.Core
+	appURL string
+	ttl    time.Duration
+}
+
+// NewCore constructs a core for export api access. Exports can be downloaded
+// for the ttl after they are built. The links in the emails point to the web
+// application at appURL.
+func NewCore(log *zap.SugaredLogger, storer Storer, userCore *user.Core, emailCore *email.Core, appURL string, ttl time.Duration) *Core {
+	return &Core{
+		log:    log,
+		storer: storer,
+		user:   userCore,
+		email:  emailCore,
+		appURL: appURL,
+		ttl:    ttl,
+	}
+}
+
+// Request asks for an export of the data of the user. An export that is
+// still waiting to be built is returned instead of asking for another.
+func (c *Core) Request(ctx context.Context, userID uuid.UUID) (Export, error) {
+	exps, err := c.storer.QueryByUserID(ctx, userID)
+	if err != nil {
+		return Export{}, fmt.Errorf("request: userID[%s]: %w", userID, err)
+	}
+
+	for _, exp := range exps {
+		if exp.Status == StatusPending || exp.Status == StatusRunning {
+			return exp, nil
+		}
+	}
+
+	exp := Export{
+		ID:          uuid.New(),
+		UserID:      userID,
+		Status:      StatusPending,
+		DateCreated: time.Now(),
+	}
+
+	if err := c.storer.Create(ctx, exp); err != nil {
+		return Export{}, fmt.Errorf("request: userID[%s]: %w", userID, err)
+	}
+
+	return exp, nil
+}
+
+// QueryByUserID returns the exports of the user that haven't expired, newest
+// first, without their archives.
+func (c *Core) QueryByUserID(ctx context.Context, userID uuid.UUID) ([]Export, error) {
+	exps, err := c.storer.QueryByUserID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("query: userID[%s]: %w", userID, err)
+	}
+
+	return exps, nil
+}
+
+// QueryArchive returns the export of the user with its archive, once it is
+// ready.
+func (c *Core) QueryArchive(ctx context.Context, userID uuid.UUID, exportID uuid.UUID) (Export, error) {
+	exp, err := c.storer.QueryByID(ctx, exportID)
+	if err != nil {
+		return Export{}, fmt.Errorf("queryarchive: exportID[%s]: %w", exportID, err)
+	}
+
+	// Someone else's export is as good as one that doesn't exist.
+	if exp.UserID != userID {
+		return Export{}, fmt.Errorf("queryarchive: exportID[%s]: %w", exportID, ErrNotFound)
+	}
+
+	if exp.Status != StatusReady {
+		return Export{}, fmt.Errorf("queryarchive: exportID[%s]: %w", exportID, ErrNotReady)
+	}
+
+	if !time.Now().Before(exp.DateExpires) {
+		return Export{}, fmt.Errorf("queryarchive: exportID[%s]: %w", exportID, ErrNotFound)
+	}
+
+	return exp, nil
+}
+
+// Build builds the exports that are waiting, one at a time. Each export is
+// claimed for the run so instances running side by side build different
+// ones. The user is emailed when their export is ready.
+func (c *Core) Build(ctx context.Context) error {
+	for {
+		now := time.Now()
+
+		exp, err := c.storer.Claim(ctx, now, now.Add(-staleAfter))
+		if err != nil {
+			if errors.Is(err, ErrNotFound) {
+				return nil
+			}
+			return fmt.Errorf("build: claim: %w", err)
+		}
+
+		if err := c.build(ctx, exp); err != nil {
+			c.log.Errorw("export", "status", "building export", "exportID", exp.ID, "userID", exp.UserID, "ERROR", err)
+
+			exp.Status = StatusFailed
+			exp.DateCompleted = time.Now()
+			if err := c.storer.Update(ctx, exp); err != nil {
+				return fmt.Errorf("build: exportID[%s]: %w", exp.ID, err)
+			}
+		}
+	}
+}
+
+// Purge removes the exports that have expired.
+func (c *Core) Purge(ctx context.Context) error {
+	if err := c.storer.DeleteExpired(ctx, time.Now()); err != nil {
+		return fmt.Errorf("purge: %w", err)
+	}
+
+	return nil
+}
+
+// =============================================================================
+
+// build collects the data of the user into the archive of the export.
+func (c *Core) build(ctx context.Context, exp Export) error {
+	sections, err := c.storer.QuerySections(ctx, exp.UserID)
+	if err != nil {
+		return fmt.Errorf("querysections: %w", err)
+	}
+
+	archive, err := zipSections(sections)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	exp.Status = StatusReady
+	exp.Archive = archive
+	exp.Size = len(archive)
+	exp.DateCompleted = now
+	exp.DateExpires = now.Add(c.ttl)
+
+	if err := c.storer.Update(ctx, exp); err != nil {
+		return fmt.Errorf("update: %w", err)
+	}
+
+	// The export is ready either way, the user can see it in their account.
+	if err := c.notify(ctx, exp); err != nil {
+		c.log.Errorw("export", "status", "sending export email", "exportID", exp.ID, "userID", exp.UserID, "ERROR", err)
+	}
+
+	return nil
+}
+
+// notify emails the user that their export can be downloaded.
+func (c *Core) notify(ctx context.Context, exp Export) error {
+	usr, err := c.user.QueryByID(ctx, exp.UserID)
+	if err != nil {
+		return err
+	}
+
+	ne := email.NewEmail{
+		To:       mail.Address{Name: usr.Name, Address: usr.Email.Address},
+		Template: "data_export_ready",
+		Locale:   usr.Locale,
+		Data: struct {
+			Name string
+			Date string
+			Link string
+		}{
+			Name: usr.Name,
+			Date: exp.DateExpires.UTC().Format("2 January 2006"),
+			Link: c.appURL + "/account/export",
+		},
+	}
+
+	if _, err := c.email.Enqueue(ctx, ne); err != nil {
+		return fmt.Errorf("enqueue: %w", err)
+	}
+
+	return nil
+}
+
+// zipSections writes each section as an indented JSON file in a ZIP archive.
+func zipSections(sections []Section) ([]byte, error) {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+
+	for _, section := range sections {
+		var data bytes.Buffer
+		if err := json.Indent(&data, section.Data, "", "  "); err != nil {
+			return nil, fmt.Errorf("indent: %s: %w", section.Name, err)
+		}
+
+		f, err := zw.Create(section.Name + ".json")
+		if err != nil {
+			return nil, fmt.Errorf("create: %s: %w", section.Name, err)
+		}
+
+		if _, err := data.WriteTo(f); err != nil {
+			return nil, fmt.Errorf("write: %s: %w", section.Name, err)
+		}
+	}
+
+	if err := zw.Close(); err != nil {
+		return nil, fmt.Errorf("close: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
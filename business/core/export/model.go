@@ -0,0 +1,28 @@
+package export
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Export is a copy of the data of a user, as a ZIP archive of JSON files.
+// The archive is only loaded when it is downloaded.
+type Export struct {
+	ID            uuid.UUID
+	UserID        uuid.UUID
+	Status        Status
+	Archive       []byte
+	Size          int
+	DateStarted   time.Time
+	DateCompleted time.Time
+	DateExpires   time.Time
+	DateCreated   time.Time
+}
+
+// Section is one kind of data of a user, encoded as JSON, that becomes a
+// file in the archive.
+type Section struct {
+	Name string
+	Data []byte
+}
@@ -0,0 +1,301 @@
+// Package exportdb contains data export related CRUD functionality.
+package exportdb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/dmanias/startupers/business/core/export"
+	database "github.com/dmanias/startupers/business/sys/database/pgx"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"go.uber.org/zap"
+)
+
+// Store manages the set of APIs for data export database access.
+type Store struct {
+	log *zap.SugaredLogger
+	db  sqlx.ExtContext
+}
+
+// NewStore constructs the api for data access.
+func NewStore(log *zap.SugaredLogger, db *sqlx.DB) *Store {
+	return &Store{
+		log: log,
+		db:  db,
+	}
+}
+
+// Create inserts a new export into the database.
+func (s *Store) Create(ctx context.Context, exp export.Export) error {
+	const q = `
+	INSERT INTO data_exports
+		(id, user_id, status, archive, size, date_started, date_completed, date_expires, date_created)
+	VALUES
+		(:id, :user_id, :status, :archive, :size, :date_started, :date_completed, :date_expires, :date_created)`
+
+	if err := database.NamedExecContext(ctx, s.log, s.db, q, toDBExport(exp)); err != nil {
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	return nil
+}
+
+// Update replaces an export in the database.
+func (s *Store) Update(ctx context.Context, exp export.Export) error {
+	const q = `
+	UPDATE
+		data_exports
+	SET
+		"status" = :status,
+		"archive" = :archive,
+		"size" = :size,
+		"date_started" = :date_started,
+		"date_completed" = :date_completed,
+		"date_expires" = :date_expires
+	WHERE
+		id = :id`
+
+	if err := database.NamedExecContext(ctx, s.log, s.db, q, toDBExport(exp)); err != nil {
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	return nil
+}
+
+// QueryByID gets the specified export, with its archive, from the database.
+func (s *Store) QueryByID(ctx context.Context, exportID uuid.UUID) (export.Export, error) {
+	data := struct {
+		ID string `db:"id"`
+	}{
+		ID: exportID.String(),
+	}
+
+	const q = `
+	SELECT
+		id, user_id, status, archive, size, date_started, date_completed, date_expires, date_created
+	FROM
+		data_exports
+	WHERE
+		id = :id`
+
+	var dbExp dbExport
+	if err := database.NamedQueryStruct(ctx, s.log, s.db, q, data, &dbExp); err != nil {
+		if errors.Is(err, database.ErrDBNotFound) {
+			return export.Export{}, fmt.Errorf("namedquerystruct: %w", export.ErrNotFound)
+		}
+		return export.Export{}, fmt.Errorf("namedquerystruct: %w", err)
+	}
+
+	return toCoreExport(dbExp), nil
+}
+
+// QueryByUserID retrieves the exports of the user that haven't expired,
+// newest first. Archives are left out.
+func (s *Store) QueryByUserID(ctx context.Context, userID uuid.UUID) ([]export.Export, error) {
+	data := struct {
+		UserID string `db:"user_id"`
+	}{
+		UserID: userID.String(),
+	}
+
+	const q = `
+	SELECT
+		id, user_id, status, NULL AS archive, size, date_started, date_completed, date_expires, date_created
+	FROM
+		data_exports
+	WHERE
+		user_id = :user_id AND
+		(date_expires IS NULL OR date_expires > now())
+	ORDER BY
+		date_created DESC`
+
+	var dbExps []dbExport
+	if err := database.NamedQuerySlice(ctx, s.log, s.db, q, data, &dbExps); err != nil {
+		return nil, fmt.Errorf("namedqueryslice: %w", err)
+	}
+
+	return toCoreExportSlice(dbExps), nil
+}
+
+// Claim marks the oldest export waiting to be built as running and returns
+// it. Exports left running since before staleBefore are claimed again.
+// Claimed rows are locked while they are claimed, so instances running side
+// by side claim different exports.
+func (s *Store) Claim(ctx context.Context, now time.Time, staleBefore time.Time) (export.Export, error) {
+	data := struct {
+		Now         time.Time `db:"now"`
+		StaleBefore time.Time `db:"stale_before"`
+	}{
+		Now:         now.UTC(),
+		StaleBefore: staleBefore.UTC(),
+	}
+
+	const q = `
+	UPDATE
+		data_exports
+	SET
+		status = 'running',
+		date_started = :now
+	WHERE
+		id = (
+			SELECT
+				id
+			FROM
+				data_exports
+			WHERE
+				status = 'pending' OR
+				(status = 'running' AND date_started < :stale_before)
+			ORDER BY
+				date_created
+			LIMIT 1
+			FOR UPDATE SKIP LOCKED
+		)
+	RETURNING
+		id, user_id, status, NULL AS archive, size, date_started, date_completed, date_expires, date_created`
+
+	var dbExp dbExport
+	if err := database.NamedQueryStruct(ctx, s.log, s.db, q, data, &dbExp); err != nil {
+		if errors.Is(err, database.ErrDBNotFound) {
+			return export.Export{}, fmt.Errorf("namedquerystruct: %w", export.ErrNotFound)
+		}
+		return export.Export{}, fmt.Errorf("namedquerystruct: %w", err)
+	}
+
+	return toCoreExport(dbExp), nil
+}
+
+// DeleteExpired removes the exports that have expired.
+func (s *Store) DeleteExpired(ctx context.Context, now time.Time) error {
+	data := struct {
+		Now time.Time `db:"now"`
+	}{
+		Now: now.UTC(),
+	}
+
+	const q = `
+	DELETE FROM
+		data_exports
+	WHERE
+		date_expires <= :now`
+
+	if err := database.NamedExecContext(ctx, s.log, s.db, q, data); err != nil {
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	return nil
+}
+
+// sections are the queries for each kind of data of a user that goes into an
+// export. Each returns its rows as a JSON array, oldest first. The password
+// hash of the user is left out.
+var sections = []struct {
+	name string
+	q    string
+}{
+	{"ideas", `
+	SELECT
+		id, title, description, category, tags, privacy, collaborators, avatar_url, stage, inspiration,
+		forked_from, vote_count, deleted_at, date_created, date_updated
+	FROM
+		ideas
+	WHERE
+		user_id = :user_id`},
+	{"posts", `
+	SELECT
+		id, idea_id, owner_type, content, reaction_counts, forked_from, deleted_at, date_created, date_updated
+	FROM
+		posts
+	WHERE
+		author_id = :user_id`},
+	{"challenges", `
+	SELECT
+		c.id, c.idea_id, c.moderator_id, c.answer, c.photo_url, c.forked_from, c.deleted_at, c.date_created, c.date_updated
+	FROM
+		challenges c
+	JOIN
+		ideas i ON i.id = c.idea_id
+	WHERE
+		i.user_id = :user_id`},
+	{"comments", `
+	SELECT
+		id, idea_id, owner_type, owner_id, parent_id, content, deleted_at, date_created, date_updated
+	FROM
+		comments
+	WHERE
+		author_id = :user_id`},
+	{"votes", `
+	SELECT
+		idea_id, date_created
+	FROM
+		idea_votes
+	WHERE
+		user_id = :user_id`},
+	{"reactions", `
+	SELECT
+		post_id, emoji, date_created
+	FROM
+		post_reactions
+	WHERE
+		user_id = :user_id`},
+	{"ai_interactions", `
+	SELECT
+		id, name, query, date_created, date_updated
+	FROM
+		ais
+	WHERE
+		userid = :user_id`},
+}
+
+// QuerySections retrieves the data of the user, the profile first and then
+// one section per kind of data.
+func (s *Store) QuerySections(ctx context.Context, userID uuid.UUID) ([]export.Section, error) {
+	data := struct {
+		UserID string `db:"user_id"`
+	}{
+		UserID: userID.String(),
+	}
+
+	const profile = `
+	SELECT
+		CAST(row_to_json(t) AS TEXT) AS data
+	FROM (
+		SELECT
+			id, name, email, roles, enabled, email_verified_at, notification_preferences,
+			bio, skills, avatar_url, locale, timezone, date_created, date_updated
+		FROM
+			users
+		WHERE
+			id = :user_id
+	) t`
+
+	var row struct {
+		Data string `db:"data"`
+	}
+	if err := database.NamedQueryStruct(ctx, s.log, s.db, profile, data, &row); err != nil {
+		if errors.Is(err, database.ErrDBNotFound) {
+			return nil, fmt.Errorf("namedquerystruct: profile: %w", export.ErrNotFound)
+		}
+		return nil, fmt.Errorf("namedquerystruct: profile: %w", err)
+	}
+
+	result := []export.Section{{Name: "profile", Data: []byte(row.Data)}}
+
+	for _, section := range sections {
+		q := `
+	SELECT
+		CAST(coalesce(json_agg(t ORDER BY t.date_created), '[]') AS TEXT) AS data
+	FROM (` + section.q + `
+	) t`
+
+		if err := database.NamedQueryStruct(ctx, s.log, s.db, q, data, &row); err != nil {
+			return nil, fmt.Errorf("namedquerystruct: %s: %w", section.name, err)
+		}
+
+		result = append(result, export.Section{Name: section.name, Data: []byte(row.Data)})
+	}
+
+	return result, nil
+}
@@ -0,0 +1,105 @@
+package exportdb
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"time"
+
+	"github.com/dmanias/startupers/business/core/export"
+	"github.com/google/uuid"
+)
+
+// dbExport represent the structure we need for moving data
+// between the app and the database.
+type dbExport struct {
+	ID            uuid.UUID    `db:"id"`
+	UserID        uuid.UUID    `db:"user_id"`
+	Status        string       `db:"status"`
+	Archive       dbArchive    `db:"archive"`
+	Size          int          `db:"size"`
+	DateStarted   sql.NullTime `db:"date_started"`
+	DateCompleted sql.NullTime `db:"date_completed"`
+	DateExpires   sql.NullTime `db:"date_expires"`
+	DateCreated   time.Time    `db:"date_created"`
+}
+
+func toDBExport(exp export.Export) dbExport {
+	return dbExport{
+		ID:            exp.ID,
+		UserID:        exp.UserID,
+		Status:        exp.Status.Name(),
+		Archive:       dbArchive(exp.Archive),
+		Size:          exp.Size,
+		DateStarted:   toNullTime(exp.DateStarted),
+		DateCompleted: toNullTime(exp.DateCompleted),
+		DateExpires:   toNullTime(exp.DateExpires),
+		DateCreated:   exp.DateCreated.UTC(),
+	}
+}
+
+func toCoreExport(dbExp dbExport) export.Export {
+	return export.Export{
+		ID:            dbExp.ID,
+		UserID:        dbExp.UserID,
+		Status:        export.MustParseStatus(dbExp.Status),
+		Archive:       []byte(dbExp.Archive),
+		Size:          dbExp.Size,
+		DateStarted:   fromNullTime(dbExp.DateStarted),
+		DateCompleted: fromNullTime(dbExp.DateCompleted),
+		DateExpires:   fromNullTime(dbExp.DateExpires),
+		DateCreated:   dbExp.DateCreated.In(time.Local),
+	}
+}
+
+func toCoreExportSlice(dbExps []dbExport) []export.Export {
+	exps := make([]export.Export, len(dbExps))
+	for i, dbExp := range dbExps {
+		exps[i] = toCoreExport(dbExp)
+	}
+	return exps
+}
+
+func toNullTime(t time.Time) sql.NullTime {
+	return sql.NullTime{
+		Time:  t.UTC(),
+		Valid: !t.IsZero(),
+	}
+}
+
+func fromNullTime(nt sql.NullTime) time.Time {
+	if !nt.Valid {
+		return time.Time{}
+	}
+	return nt.Time.In(time.Local)
+}
+
+// dbArchive is the ZIP archive of an export. It prints as its size so the
+// queries that are logged don't carry the archive.
+type dbArchive []byte
+
+// Value implements the driver.Valuer interface.
+func (a dbArchive) Value() (driver.Value, error) {
+	if a == nil {
+		return nil, nil
+	}
+	return []byte(a), nil
+}
+
+// Scan implements the sql.Scanner interface.
+func (a *dbArchive) Scan(src any) error {
+	switch v := src.(type) {
+	case nil:
+		*a = nil
+	case []byte:
+		*a = append(dbArchive(nil), v...)
+	default:
+		return fmt.Errorf("scanning archive: unsupported type %T", src)
+	}
+	return nil
+}
+
+// String implements the fmt.Stringer interface.
+func (a dbArchive) String() string {
+	return fmt.Sprintf("<%d bytes>", len(a))
+}
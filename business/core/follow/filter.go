@@ -0,0 +1,40 @@
+package follow
+
+import (
+	"fmt"
+
+	"github.com/dmanias/startupers/business/sys/validate"
+	"github.com/google/uuid"
+)
+
+// QueryFilter holds the available fields a query can be filtered on.
+type QueryFilter struct {
+	UserID     *uuid.UUID  `validate:"omitempty"`
+	TargetType *TargetType `validate:"omitempty"`
+	TargetID   *uuid.UUID  `validate:"omitempty"`
+}
+
+// Validate checks the data in the model is considered clean.
+func (qf *QueryFilter) Validate() error {
+	if err := validate.Check(qf); err != nil {
+		return fmt.Errorf("validate: %w", err)
+	}
+	return nil
+}
+
+// WithUserID restricts the result to what the user follows.
+func (qf *QueryFilter) WithUserID(userID uuid.UUID) {
+	qf.UserID = &userID
+}
+
+// WithTargetType restricts the result to the follows of ideas or of users.
+func (qf *QueryFilter) WithTargetType(targetType TargetType) {
+	qf.TargetType = &targetType
+}
+
+// WithTarget restricts the result to the followers of the specified idea or
+// user.
+func (qf *QueryFilter) WithTarget(targetType TargetType, targetID uuid.UUID) {
+	qf.TargetType = &targetType
+	qf.TargetID = &targetID
+}
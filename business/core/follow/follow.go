@@ -0,0 +1,91 @@
+// Package follow provides the business API for users following ideas and
+// other users. What a user follows makes up their activity feed.
+package follow
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/dmanias/startupers/business/data/order"
+	"github.com/google/uuid"
+)
+
+// Set of error variables for CRUD operations.
+var (
+	ErrNotFound         = errors.New("follow not found")
+	ErrAlreadyFollowing = errors.New("already following")
+	ErrFollowSelf       = errors.New("users can't follow themselves")
+)
+
+// Storer interface declares the behavior this package needs to perists and
+// retrieve data.
+type Storer interface {
+	Create(ctx context.Context, fol Follow) error
+	Delete(ctx context.Context, fol Follow) error
+	Query(ctx context.Context, filter QueryFilter, orderBy order.By, pageNumber int, rowsPerPage int) ([]Follow, error)
+	Count(ctx context.Context, filter QueryFilter) (int, error)
+}
+
+// Core manages the set of APIs for follow access.
+type Core struct {
+	storer Storer
+}
+
+// NewCore constructs a core for follow api access.
+func NewCore(storer Storer) *Core {
+	return &Core{
+		storer: storer,
+	}
+}
+
+// Follow makes the user follow the target.
+func (c *Core) Follow(ctx context.Context, userID uuid.UUID, targetType TargetType, targetID uuid.UUID) (Follow, error) {
+	if targetType.Equal(TargetTypeUser) && targetID == userID {
+		return Follow{}, ErrFollowSelf
+	}
+
+	fol := Follow{
+		UserID:      userID,
+		TargetType:  targetType,
+		TargetID:    targetID,
+		DateCreated: time.Now(),
+	}
+
+	if err := c.storer.Create(ctx, fol); err != nil {
+		return Follow{}, fmt.Errorf("follow: userID[%s]: %w", userID, err)
+	}
+
+	return fol, nil
+}
+
+// Unfollow makes the user stop following the target.
+func (c *Core) Unfollow(ctx context.Context, userID uuid.UUID, targetType TargetType, targetID uuid.UUID) error {
+	fol := Follow{
+		UserID:     userID,
+		TargetType: targetType,
+		TargetID:   targetID,
+	}
+
+	if err := c.storer.Delete(ctx, fol); err != nil {
+		return fmt.Errorf("unfollow: userID[%s]: %w", userID, err)
+	}
+
+	return nil
+}
+
+// Query retrieves a list of existing follows from the database.
+func (c *Core) Query(ctx context.Context, filter QueryFilter, orderBy order.By, pageNumber int, rowsPerPage int) ([]Follow, error) {
+	follows, err := c.storer.Query(ctx, filter, orderBy, pageNumber, rowsPerPage)
+	if err != nil {
+		return nil, fmt.Errorf("query: %w", err)
+	}
+
+	return follows, nil
+}
+
+// Count returns the total number of follows in the store.
+func (c *Core) Count(ctx context.Context, filter QueryFilter) (int, error) {
+	return c.storer.Count(ctx, filter)
+}
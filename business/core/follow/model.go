@@ -0,0 +1,15 @@
+package follow
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Follow records a user following an idea or another user.
+type Follow struct {
+	UserID      uuid.UUID
+	TargetType  TargetType
+	TargetID    uuid.UUID
+	DateCreated time.Time
+}
@@ -0,0 +1,32 @@
+package followdb
+
+import (
+	"bytes"
+	"strings"
+
+	"github.com/dmanias/startupers/business/core/follow"
+)
+
+func (s *Store) applyFilter(filter follow.QueryFilter, data map[string]interface{}, buf *bytes.Buffer) {
+	var wc []string
+
+	if filter.UserID != nil {
+		data["user_id"] = filter.UserID
+		wc = append(wc, "user_id = :user_id")
+	}
+
+	if filter.TargetType != nil {
+		data["target_type"] = filter.TargetType.Name()
+		wc = append(wc, "target_type = :target_type")
+	}
+
+	if filter.TargetID != nil {
+		data["target_id"] = filter.TargetID
+		wc = append(wc, "target_id = :target_id")
+	}
+
+	if len(wc) > 0 {
+		buf.WriteString(" WHERE ")
+		buf.WriteString(strings.Join(wc, " AND "))
+	}
+}
@@ -0,0 +1,125 @@
+// Package followdb contains follow related CRUD functionality.
+package followdb
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/dmanias/startupers/business/core/follow"
+	"github.com/dmanias/startupers/business/data/order"
+	database "github.com/dmanias/startupers/business/sys/database/pgx"
+	"github.com/jmoiron/sqlx"
+	"go.uber.org/zap"
+)
+
+// Store manages the set of APIs for follow database access.
+type Store struct {
+	log *zap.SugaredLogger
+	db  sqlx.ExtContext
+}
+
+// NewStore constructs the api for data access.
+func NewStore(log *zap.SugaredLogger, db *sqlx.DB) *Store {
+	return &Store{
+		log: log,
+		db:  db,
+	}
+}
+
+// Create inserts a new follow into the database.
+func (s *Store) Create(ctx context.Context, fol follow.Follow) error {
+	const q = `
+	INSERT INTO follows
+		(user_id, target_type, target_id, date_created)
+	VALUES
+		(:user_id, :target_type, :target_id, :date_created)`
+
+	if err := database.NamedExecContext(ctx, s.log, s.db, q, toDBFollow(fol)); err != nil {
+		if errors.Is(err, database.ErrDBDuplicatedEntry) {
+			return fmt.Errorf("namedexeccontext: %w", follow.ErrAlreadyFollowing)
+		}
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	return nil
+}
+
+// Delete removes a follow from the database.
+func (s *Store) Delete(ctx context.Context, fol follow.Follow) error {
+	const q = `
+	DELETE FROM
+		follows
+	WHERE
+		user_id = :user_id AND
+		target_type = :target_type AND
+		target_id = :target_id
+	RETURNING
+		*`
+
+	var dbFol dbFollow
+	if err := database.NamedQueryStruct(ctx, s.log, s.db, q, toDBFollow(fol), &dbFol); err != nil {
+		if errors.Is(err, database.ErrDBNotFound) {
+			return fmt.Errorf("namedquerystruct: %w", follow.ErrNotFound)
+		}
+		return fmt.Errorf("namedquerystruct: %w", err)
+	}
+
+	return nil
+}
+
+// Query retrieves a list of existing follows from the database.
+func (s *Store) Query(ctx context.Context, filter follow.QueryFilter, orderBy order.By, pageNumber int, rowsPerPage int) ([]follow.Follow, error) {
+	data := map[string]interface{}{
+		"offset":        (pageNumber - 1) * rowsPerPage,
+		"rows_per_page": rowsPerPage,
+	}
+
+	const q = `
+	SELECT
+		*
+	FROM
+		follows`
+
+	buf := bytes.NewBufferString(q)
+	s.applyFilter(filter, data, buf)
+
+	orderByClause, err := orderByClause(orderBy)
+	if err != nil {
+		return nil, err
+	}
+
+	buf.WriteString(orderByClause)
+	buf.WriteString(" OFFSET :offset ROWS FETCH NEXT :rows_per_page ROWS ONLY")
+
+	var dbFols []dbFollow
+	if err := database.NamedQuerySlice(ctx, s.log, s.db, buf.String(), data, &dbFols); err != nil {
+		return nil, fmt.Errorf("namedqueryslice: %w", err)
+	}
+
+	return toCoreFollowSlice(dbFols), nil
+}
+
+// Count returns the total number of follows in the DB.
+func (s *Store) Count(ctx context.Context, filter follow.QueryFilter) (int, error) {
+	data := map[string]interface{}{}
+
+	const q = `
+	SELECT
+		count(1)
+	FROM
+		follows`
+
+	buf := bytes.NewBufferString(q)
+	s.applyFilter(filter, data, buf)
+
+	var count struct {
+		Count int `db:"count"`
+	}
+	if err := database.NamedQueryStruct(ctx, s.log, s.db, buf.String(), data, &count); err != nil {
+		return 0, fmt.Errorf("namedquerystruct: %w", err)
+	}
+
+	return count.Count, nil
+}
@@ -0,0 +1,43 @@
+package followdb
+
+import (
+	"time"
+
+	"github.com/dmanias/startupers/business/core/follow"
+	"github.com/google/uuid"
+)
+
+// dbFollow represent the structure we need for moving data
+// between the app and the database.
+type dbFollow struct {
+	UserID      uuid.UUID `db:"user_id"`
+	TargetType  string    `db:"target_type"`
+	TargetID    uuid.UUID `db:"target_id"`
+	DateCreated time.Time `db:"date_created"`
+}
+
+func toDBFollow(fol follow.Follow) dbFollow {
+	return dbFollow{
+		UserID:      fol.UserID,
+		TargetType:  fol.TargetType.Name(),
+		TargetID:    fol.TargetID,
+		DateCreated: fol.DateCreated.UTC(),
+	}
+}
+
+func toCoreFollow(dbFol dbFollow) follow.Follow {
+	return follow.Follow{
+		UserID:      dbFol.UserID,
+		TargetType:  follow.MustParseTargetType(dbFol.TargetType),
+		TargetID:    dbFol.TargetID,
+		DateCreated: dbFol.DateCreated.In(time.Local),
+	}
+}
+
+func toCoreFollowSlice(dbFols []dbFollow) []follow.Follow {
+	fols := make([]follow.Follow, len(dbFols))
+	for i, dbFol := range dbFols {
+		fols[i] = toCoreFollow(dbFol)
+	}
+	return fols
+}
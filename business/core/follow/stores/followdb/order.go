@@ -0,0 +1,21 @@
+package followdb
+
+import (
+	"fmt"
+
+	"github.com/dmanias/startupers/business/core/follow"
+	"github.com/dmanias/startupers/business/data/order"
+)
+
+var orderByFields = map[string]string{
+	follow.OrderByDateCreated: "date_created",
+}
+
+func orderByClause(orderBy order.By) (string, error) {
+	by, exists := orderByFields[orderBy.Field]
+	if !exists {
+		return "", fmt.Errorf("field %q does not exist", orderBy.Field)
+	}
+
+	return " ORDER BY " + by + " " + orderBy.Direction + ", target_id ASC", nil
+}
@@ -0,0 +1,63 @@
+package follow
+
+import "errors"
+
+// Set of things users can follow.
+var (
+	TargetTypeIdea = TargetType{"idea"}
+	TargetTypeUser = TargetType{"user"}
+)
+
+// Set of known target types.
+var targetTypes = map[string]TargetType{
+	TargetTypeIdea.name: TargetTypeIdea,
+	TargetTypeUser.name: TargetTypeUser,
+}
+
+// TargetType represents the kind of thing being followed.
+type TargetType struct {
+	name string
+}
+
+// ParseTargetType parses the string value and returns a target type if one
+// exists.
+func ParseTargetType(value string) (TargetType, error) {
+	targetType, exists := targetTypes[value]
+	if !exists {
+		return TargetType{}, errors.New("invalid target type")
+	}
+
+	return targetType, nil
+}
+
+// MustParseTargetType parses the string value and returns a target type if
+// one exists. If an error occurs the function panics.
+func MustParseTargetType(value string) TargetType {
+	targetType, err := ParseTargetType(value)
+	if err != nil {
+		panic(err)
+	}
+
+	return targetType
+}
+
+// Name returns the name of the target type.
+func (t TargetType) Name() string {
+	return t.name
+}
+
+// UnmarshalText implement the unmarshal interface for JSON conversions.
+func (t *TargetType) UnmarshalText(data []byte) error {
+	t.name = string(data)
+	return nil
+}
+
+// MarshalText implement the marshal interface for JSON conversions.
+func (t TargetType) MarshalText() ([]byte, error) {
+	return []byte(t.name), nil
+}
+
+// Equal provides support for the go-cmp package and testing.
+func (t TargetType) Equal(t2 TargetType) bool {
+	return t.name == t2.name
+}
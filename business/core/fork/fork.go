@@ -0,0 +1,184 @@
+// Package fork provides the business API for forking an idea into the
+// workspace of another user, optionally with its posts and challenges.
+package fork
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/dmanias/startupers/business/core/challenge"
+	"github.com/dmanias/startupers/business/core/idea"
+	"github.com/dmanias/startupers/business/core/post"
+	"github.com/dmanias/startupers/business/data/transaction"
+	"go.uber.org/zap"
+)
+
+// ErrNotForkable is returned when the idea isn't public and the user isn't
+// its owner.
+var ErrNotForkable = errors.New("only public ideas can be forked")
+
+// copyPageSize is the number of posts or challenges read at a time while
+// copying them to the fork.
+const copyPageSize = 100
+
+// Core manages the set of APIs for forking ideas.
+type Core struct {
+	log       *zap.SugaredLogger
+	beginner  transaction.Beginner
+	idea      *idea.Core
+	post      *post.Core
+	challenge *challenge.Core
+}
+
+// NewCore constructs a core for fork api access.
+func NewCore(log *zap.SugaredLogger, beginner transaction.Beginner, ideaCore *idea.Core, postCore *post.Core, challengeCore *challenge.Core) *Core {
+	return &Core{
+		log:       log,
+		beginner:  beginner,
+		idea:      ideaCore,
+		post:      postCore,
+		challenge: challengeCore,
+	}
+}
+
+// Fork creates a private copy of the idea owned by the user, linked to the
+// original through its ForkedFrom field. Copied posts and challenges are
+// linked to theirs the same way. The new idea and the copies are created in a
+// single transaction.
+func (c *Core) Fork(ctx context.Context, src idea.Idea, nf NewFork) (idea.Idea, error) {
+	if src.Privacy != idea.PrivacyPublic && src.UserID != nf.UserID {
+		return idea.Idea{}, ErrNotForkable
+	}
+
+	title := src.Title
+	if nf.Title != nil {
+		title = *nf.Title
+	}
+
+	ni := idea.NewIdea{
+		UserID:      nf.UserID,
+		Title:       title,
+		Description: src.Description,
+		Category:    src.Category,
+		Tags:        src.Tags,
+		Privacy:     idea.PrivacyPrivate,
+		ForkedFrom:  src.ID,
+	}
+
+	var forked idea.Idea
+
+	f := func(tx transaction.Transaction) error {
+		ideaCore, err := c.idea.ExecuteUnderTransaction(tx)
+		if err != nil {
+			return err
+		}
+
+		forked, err = ideaCore.Create(ctx, ni)
+		if err != nil {
+			return fmt.Errorf("create: %w", err)
+		}
+
+		if nf.CopyPosts {
+			postCore, err := c.post.ExecuteUnderTransaction(tx)
+			if err != nil {
+				return err
+			}
+
+			if err := copyPosts(ctx, postCore, src, forked); err != nil {
+				return fmt.Errorf("copyposts: %w", err)
+			}
+		}
+
+		if nf.CopyChallenges {
+			challengeCore, err := c.challenge.ExecuteUnderTransaction(tx)
+			if err != nil {
+				return err
+			}
+
+			if err := copyChallenges(ctx, challengeCore, src, forked); err != nil {
+				return fmt.Errorf("copychallenges: %w", err)
+			}
+		}
+
+		return nil
+	}
+
+	if err := transaction.ExecuteUnderTransaction(ctx, c.log, c.beginner, f); err != nil {
+		return idea.Idea{}, fmt.Errorf("fork: ideaID[%s]: %w", src.ID, err)
+	}
+
+	return forked, nil
+}
+
+// =============================================================================
+
+// copyPosts copies the posts of the source idea to the fork. The copies are
+// authored by the owner of the fork, who posts them there; the post each was
+// copied from is kept in its ForkedFrom field.
+func copyPosts(ctx context.Context, postCore *post.Core, src idea.Idea, dst idea.Idea) error {
+	var filter post.QueryFilter
+	filter.WithIdeaID(src.ID)
+
+	orderBy := post.DefaultOrderBy
+
+	for page := 1; ; page++ {
+		posts, err := postCore.Query(ctx, filter, orderBy, page, copyPageSize)
+		if err != nil {
+			return err
+		}
+
+		for _, p := range posts {
+			np := post.NewPost{
+				IdeaID:     dst.ID,
+				AuthorID:   dst.UserID,
+				Content:    p.Content,
+				OwnerType:  p.OwnerType,
+				ForkedFrom: p.ID,
+			}
+
+			if _, err := postCore.Create(ctx, np); err != nil {
+				return err
+			}
+		}
+
+		if len(posts) < copyPageSize {
+			return nil
+		}
+	}
+}
+
+// copyChallenges copies the challenges of the source idea, with their answers,
+// to the fork. The challenge each was copied from is kept in its ForkedFrom
+// field.
+func copyChallenges(ctx context.Context, challengeCore *challenge.Core, src idea.Idea, dst idea.Idea) error {
+	var filter challenge.QueryFilter
+	filter.WithIdeaID(src.ID)
+
+	orderBy := challenge.DefaultOrderBy
+
+	for page := 1; ; page++ {
+		challenges, err := challengeCore.Query(ctx, filter, orderBy, page, copyPageSize)
+		if err != nil {
+			return err
+		}
+
+		for _, chl := range challenges {
+			nc := challenge.NewChallenge{
+				IdeaID:      dst.ID,
+				ModeratorID: chl.ModeratorID,
+				Answer:      chl.Answer,
+				PhotoURL:    chl.PhotoURL,
+				ForkedFrom:  chl.ID,
+			}
+
+			if _, err := challengeCore.Create(ctx, nc); err != nil {
+				return err
+			}
+		}
+
+		if len(challenges) < copyPageSize {
+			return nil
+		}
+	}
+}
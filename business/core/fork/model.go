@@ -0,0 +1,12 @@
+package fork
+
+import "github.com/google/uuid"
+
+// NewFork contains information needed to fork an idea. The title of the
+// original idea is kept unless a new one is given.
+type NewFork struct {
+	UserID         uuid.UUID
+	Title          *string
+	CopyPosts      bool
+	CopyChallenges bool
+}
@@ -0,0 +1,27 @@
+package idea
+
+import (
+	"context"
+
+	"github.com/dmanias/startupers/business/core/activity"
+)
+
+// ActivityRecorder records what happens to ideas, for the activity feed of
+// their followers and the notifications of the people involved.
+type ActivityRecorder interface {
+	Record(ctx context.Context, na activity.NewActivity)
+}
+
+// AddActivityRecorder registers a recorder of new ideas and stage changes,
+// such as the activity feed or the notifications. It is meant to be called
+// while the application is being wired up, before the core is in use.
+func (c *Core) AddActivityRecorder(recorder ActivityRecorder) {
+	c.activity = append(c.activity, recorder)
+}
+
+// record hands the activity to every registered recorder.
+func (c *Core) record(ctx context.Context, na activity.NewActivity) {
+	for _, recorder := range c.activity {
+		recorder.Record(ctx, na)
+	}
+}
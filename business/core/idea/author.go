@@ -0,0 +1,54 @@
+package idea
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+// AuthorGuard is a condition a user has to meet before they can create ideas.
+// A guard rejects the user by returning an error wrapping ErrAuthorGuard.
+type AuthorGuard func(ctx context.Context, userID uuid.UUID) error
+
+// VerificationChecker reports whether a user has verified their email
+// address.
+type VerificationChecker interface {
+	IsVerified(ctx context.Context, userID uuid.UUID) (bool, error)
+}
+
+// VerifiedEmail constructs a guard that requires the user to have verified
+// their email address.
+func VerifiedEmail(checker VerificationChecker) AuthorGuard {
+	return func(ctx context.Context, userID uuid.UUID) error {
+		verified, err := checker.IsVerified(ctx, userID)
+		if err != nil {
+			return fmt.Errorf("isverified: %w", err)
+		}
+
+		if !verified {
+			return fmt.Errorf("%w: email address not verified", ErrAuthorGuard)
+		}
+
+		return nil
+	}
+}
+
+// AddAuthorGuard registers a condition users have to meet before they can
+// create ideas, forks included. It is meant to be called while the
+// application is being wired up, before the core is in use.
+func (c *Core) AddAuthorGuard(guard AuthorGuard) {
+	c.authors = append(c.authors, guard)
+}
+
+// CheckAuthor reports whether the user can create ideas. Create checks it
+// too; handlers call it to fail early, before any costly work.
+func (c *Core) CheckAuthor(ctx context.Context, userID uuid.UUID) error {
+	for _, guard := range c.authors {
+		if err := guard(ctx, userID); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
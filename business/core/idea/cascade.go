@@ -0,0 +1,63 @@
+package idea
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/dmanias/startupers/business/data/transaction"
+	"github.com/google/uuid"
+)
+
+// CascadeStepIdea is the name of the last step of a purge, which deletes the
+// idea itself along with the records the database removes with it: stage
+// history, revisions, invitations, scores, search documents, comments, votes,
+// follows and feed activity.
+const CascadeStepIdea = "idea"
+
+// ContentPurger is implemented by the cores holding content that belongs to
+// an idea. PurgeByIdea removes all of that content using the caller's
+// transaction, so it is rolled back if any other part of the purge fails. It
+// returns the paths of the files the removed content referenced; the ones
+// nothing else references are removed once the purge is committed.
+type ContentPurger interface {
+	PurgeByIdea(ctx context.Context, tx transaction.Transaction, ideaID uuid.UUID) ([]string, error)
+}
+
+// cascadeStep is a named ContentPurger run when an idea is purged.
+type cascadeStep struct {
+	name   string
+	purger ContentPurger
+}
+
+// CascadeError is returned when purging an idea fails. It names the step that
+// failed; nothing was deleted.
+type CascadeError struct {
+	Step string
+	Err  error
+}
+
+// Error implements the error interface.
+func (ce *CascadeError) Error() string {
+	return fmt.Sprintf("purge step %q: %s", ce.Step, ce.Err)
+}
+
+// Unwrap returns the error of the failed step.
+func (ce *CascadeError) Unwrap() error {
+	return ce.Err
+}
+
+// IsCascadeError checks if an error of type CascadeError exists.
+func IsCascadeError(err error) bool {
+	var ce *CascadeError
+	return errors.As(err, &ce)
+}
+
+// GetCascadeError returns a copy of the CascadeError pointer.
+func GetCascadeError(err error) *CascadeError {
+	var ce *CascadeError
+	if !errors.As(err, &ce) {
+		return nil
+	}
+	return ce
+}
@@ -14,8 +14,14 @@ type QueryFilter struct {
 	Title            *string    `validate:"omitempty,min=3"`
 	Category         *string    `validate:"omitempty"`
 	Tag              *string    `validate:"omitempty"`
+	Stage            *Stage     `validate:"omitempty"`
 	StartCreatedDate *time.Time `validate:"omitempty"`
 	EndCreatedDate   *time.Time `validate:"omitempty"`
+	ViewerID         *uuid.UUID `validate:"omitempty"`
+	Privacy          *Privacy   `validate:"omitempty"`
+	ForkedFrom       *uuid.UUID `validate:"omitempty"`
+	Deleted          *bool      `validate:"omitempty"`
+	DeletedBefore    *time.Time `validate:"omitempty"`
 }
 
 func (qf *QueryFilter) Validate() error {
@@ -37,6 +43,8 @@ func (qf *QueryFilter) WithTitle(title string) {
 	qf.Title = &title
 }
 
+// WithCategory sets the Category field of the QueryFilter value. Ideas in
+// its subcategories match as well.
 func (qf *QueryFilter) WithCategory(category string) {
 	qf.Category = &category
 }
@@ -45,6 +53,10 @@ func (qf *QueryFilter) WithTag(tag string) {
 	qf.Tag = &tag
 }
 
+func (qf *QueryFilter) WithStage(stage Stage) {
+	qf.Stage = &stage
+}
+
 func (qf *QueryFilter) WithStartDateCreated(startDate time.Time) {
 	d := startDate.UTC()
 	qf.StartCreatedDate = &d
@@ -54,3 +66,33 @@ func (qf *QueryFilter) WithEndCreatedDate(endDate time.Time) {
 	d := endDate.UTC()
 	qf.EndCreatedDate = &d
 }
+
+// WithViewerID restricts the result to the ideas the specified user is allowed
+// to see in a listing. Unlisted ideas are only returned to their owner and
+// collaborators.
+func (qf *QueryFilter) WithViewerID(userID uuid.UUID) {
+	qf.ViewerID = &userID
+}
+
+// WithPrivacy restricts the result to the ideas with the privacy setting.
+func (qf *QueryFilter) WithPrivacy(privacy Privacy) {
+	qf.Privacy = &privacy
+}
+
+// WithForkedFrom restricts the result to the direct forks of the idea.
+func (qf *QueryFilter) WithForkedFrom(ideaID uuid.UUID) {
+	qf.ForkedFrom = &ideaID
+}
+
+// WithDeleted switches the result between the ideas in the trash and the ones
+// that aren't. Ideas in the trash are left out unless asked for.
+func (qf *QueryFilter) WithDeleted(deleted bool) {
+	qf.Deleted = &deleted
+}
+
+// WithDeletedBefore restricts the result to the ideas moved to the trash
+// before the specified time.
+func (qf *QueryFilter) WithDeletedBefore(date time.Time) {
+	d := date.UTC()
+	qf.DeletedBefore = &d
+}
@@ -6,62 +6,190 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/dmanias/startupers/business/core/activity"
 	"github.com/dmanias/startupers/business/data/order"
+	"github.com/dmanias/startupers/business/data/transaction"
 	"github.com/google/uuid"
+	"go.uber.org/zap"
 )
 
 var (
-	ErrNotFound = errors.New("idea not found")
+	ErrNotFound          = errors.New("idea not found")
+	ErrInvalidTransition = errors.New("stage transition not allowed")
+	ErrStageGuard        = errors.New("stage requirements not met")
+	ErrAuthorGuard       = errors.New("user can't create ideas")
+	ErrRevisionNotFound  = errors.New("revision not found")
+	ErrAlreadyVoted      = errors.New("user has already voted for this idea")
+	ErrVoteNotFound      = errors.New("vote not found")
 )
 
 type Storer interface {
+	ExecuteUnderTransaction(tx transaction.Transaction) (Storer, error)
 	Create(ctx context.Context, idea Idea) error
 	Update(ctx context.Context, idea Idea) error
+	UpdateStage(ctx context.Context, ideaID uuid.UUID, from Stage, to Stage, now time.Time) error
 	Delete(ctx context.Context, idea Idea) error
+	SetDeleted(ctx context.Context, idea Idea) error
+	AddCollaborator(ctx context.Context, ideaID uuid.UUID, userID uuid.UUID) error
+	RemoveCollaborator(ctx context.Context, ideaID uuid.UUID, userID uuid.UUID) error
 	Query(ctx context.Context, filter QueryFilter, orderBy order.By, pageNumber int, rowsPerPage int) ([]Idea, error)
 	Count(ctx context.Context, filter QueryFilter) (int, error)
 	QueryByID(ctx context.Context, ideaID uuid.UUID) (Idea, error)
 	QueryByIDs(ctx context.Context, ideaIDs []uuid.UUID) ([]Idea, error)
-	QueryTags(ctx context.Context) ([]string, error)
+	QueryUnreferenced(ctx context.Context, files []string) ([]string, error)
+	ReplaceTag(ctx context.Context, from string, to string) error
+	QueryLineage(ctx context.Context, ideaID uuid.UUID) ([]Idea, error)
+	CreateRevision(ctx context.Context, rev Revision) error
+	QueryRevisions(ctx context.Context, ideaID uuid.UUID, pageNumber int, rowsPerPage int) ([]Revision, error)
+	CountRevisions(ctx context.Context, ideaID uuid.UUID) (int, error)
+	QueryRevision(ctx context.Context, ideaID uuid.UUID, number int) (Revision, error)
+	QueryLatestRevision(ctx context.Context, ideaID uuid.UUID) (Revision, error)
+	CreateVote(ctx context.Context, vote Vote) error
+	DeleteVote(ctx context.Context, ideaID uuid.UUID, userID uuid.UUID) error
+	QueryVotes(ctx context.Context, ideaID uuid.UUID, pageNumber int, rowsPerPage int) ([]Vote, error)
+	CountVotes(ctx context.Context, ideaID uuid.UUID) (int, error)
+	CreateStageTransition(ctx context.Context, st StageTransition) error
+	QueryStageHistory(ctx context.Context, ideaID uuid.UUID) ([]StageTransition, error)
 }
 
 type Core struct {
-	storer Storer
+	log      *zap.SugaredLogger
+	beginner transaction.Beginner
+	storer   Storer
+	guards   map[Stage][]StageGuard
+	authors  []AuthorGuard
+	cascade  []cascadeStep
+	tags     TagNormalizer
+	activity []ActivityRecorder
 }
 
-func NewCore(storer Storer) *Core {
+func NewCore(log *zap.SugaredLogger, beginner transaction.Beginner, storer Storer) *Core {
 	return &Core{
-		storer: storer,
+		log:      log,
+		beginner: beginner,
+		storer:   storer,
+		guards:   make(map[Stage][]StageGuard),
 	}
 }
 
+// AddStageGuard registers a condition an idea has to meet before it can be
+// moved to the stage. It is meant to be called while the application is being
+// wired up, before the core is in use.
+func (c *Core) AddStageGuard(stage Stage, guard StageGuard) {
+	c.guards[stage] = append(c.guards[stage], guard)
+}
+
+// AddContentPurger registers a core holding content that belongs to ideas.
+// Purging an idea purges its content from every registered core first, in the
+// order they were added. It is meant to be called while the application is
+// being wired up, before the core is in use.
+func (c *Core) AddContentPurger(name string, purger ContentPurger) {
+	c.cascade = append(c.cascade, cascadeStep{name: name, purger: purger})
+}
+
+// SetTagNormalizer sets what turns the tags entered by users into canonical
+// tags when ideas are created or updated. Without one tags are stored as
+// entered. It is meant to be called while the application is being wired up,
+// before the core is in use.
+func (c *Core) SetTagNormalizer(tags TagNormalizer) {
+	c.tags = tags
+}
+
+// ExecuteUnderTransaction constructs a new Core value that will use the
+// specified transaction in any store related calls. Writes made through it
+// are not handed to the activity recorders, as the transaction may still be
+// rolled back.
+func (c *Core) ExecuteUnderTransaction(tx transaction.Transaction) (*Core, error) {
+	trS, err := c.storer.ExecuteUnderTransaction(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	c = &Core{
+		log:      c.log,
+		beginner: c.beginner,
+		storer:   trS,
+		guards:   c.guards,
+		authors:  c.authors,
+		cascade:  c.cascade,
+		tags:     c.tags,
+	}
+
+	return c, nil
+}
+
 func (c *Core) Create(ctx context.Context, ni NewIdea) (Idea, error) {
+	if err := c.CheckAuthor(ctx, ni.UserID); err != nil {
+		return Idea{}, fmt.Errorf("create: %w", err)
+	}
+
+	tags, err := c.normalizeTags(ctx, ni.Tags)
+	if err != nil {
+		return Idea{}, fmt.Errorf("create: %w", err)
+	}
+
 	now := time.Now()
 
+	privacy := ni.Privacy
+	if privacy == (Privacy{}) {
+		privacy = PrivacyPrivate
+	}
+
 	idea := Idea{
-		ID:            uuid.New(),
-		UserID:        ni.UserID,
-		Title:         ni.Title,
-		Description:   ni.Description,
-		Category:      ni.Category,
-		Tags:          ni.Tags,
-		Privacy:       ni.Privacy,
-		Collaborators: ni.Collaborators,
-		AvatarURL:     ni.AvatarURL,
-		Stage:         ni.Stage,
-		Inspiration:   ni.Inspiration,
-		DateCreated:   now,
-		DateUpdated:   now,
+		ID:          uuid.New(),
+		UserID:      ni.UserID,
+		Title:       ni.Title,
+		Description: ni.Description,
+		Category:    ni.Category,
+		Tags:        tags,
+		Privacy:     privacy,
+		AvatarURL:   ni.AvatarURL,
+		Stage:       StageSpark,
+		Inspiration: ni.Inspiration,
+		ForkedFrom:  ni.ForkedFrom,
+		DateCreated: now,
+		DateUpdated: now,
 	}
 
 	if err := c.storer.Create(ctx, idea); err != nil {
 		return Idea{}, fmt.Errorf("create: %w", err)
 	}
 
+	c.record(ctx, activity.NewActivity{
+		Type:    activity.TypeIdeaCreated,
+		ActorID: idea.UserID,
+		IdeaID:  idea.ID,
+	})
+
 	return idea, nil
 }
 
-func (c *Core) Update(ctx context.Context, idea Idea, ui UpdateIdea) (Idea, error) {
+// Update applies the changes made by the user to the idea. When the content
+// changes a revision recording it is written in the same transaction.
+func (c *Core) Update(ctx context.Context, idea Idea, ui UpdateIdea, userID uuid.UUID) (Idea, error) {
+	return c.update(ctx, idea, ui, userID, 0)
+}
+
+// RestoreRevision brings the content of the idea back to the specified
+// revision. The restore is itself recorded as a new revision.
+func (c *Core) RestoreRevision(ctx context.Context, idea Idea, number int, userID uuid.UUID) (Idea, error) {
+	rev, err := c.QueryRevision(ctx, idea.ID, number)
+	if err != nil {
+		return Idea{}, err
+	}
+
+	return c.update(ctx, idea, rev.Snapshot.toUpdateIdea(idea.ID), userID, rev.Number)
+}
+
+func (c *Core) update(ctx context.Context, idea Idea, ui UpdateIdea, userID uuid.UUID, restoredFrom int) (Idea, error) {
+	tags, err := c.normalizeTags(ctx, ui.Tags)
+	if err != nil {
+		return Idea{}, fmt.Errorf("update: ideaID[%s]: %w", idea.ID, err)
+	}
+	ui.Tags = tags
+
+	prev := idea
+
 	if ui.Title != nil {
 		idea.Title = *ui.Title
 	}
@@ -77,30 +205,205 @@ func (c *Core) Update(ctx context.Context, idea Idea, ui UpdateIdea) (Idea, erro
 	if ui.Privacy != nil {
 		idea.Privacy = *ui.Privacy
 	}
-	if ui.Collaborators != nil {
-		idea.Collaborators = ui.Collaborators
-	}
 	if ui.AvatarURL != nil {
 		idea.AvatarURL = *ui.AvatarURL
 	}
-	if ui.Stage != nil {
-		idea.Stage = *ui.Stage
-	}
 	if ui.Inspiration != nil {
 		idea.Inspiration = *ui.Inspiration
 	}
 	idea.DateUpdated = time.Now()
 
-	if err := c.storer.Update(ctx, idea); err != nil {
-		return Idea{}, fmt.Errorf("update: %w", err)
+	changes := Diff(SnapshotOf(prev), SnapshotOf(idea))
+
+	f := func(tx transaction.Transaction) error {
+		storer, err := c.storer.ExecuteUnderTransaction(tx)
+		if err != nil {
+			return err
+		}
+
+		if err := storer.Update(ctx, idea); err != nil {
+			return fmt.Errorf("update: %w", err)
+		}
+
+		if len(changes) == 0 {
+			return nil
+		}
+
+		if err := writeRevision(ctx, storer, prev, idea, userID, changes, restoredFrom); err != nil {
+			return fmt.Errorf("writerevision: %w", err)
+		}
+
+		return nil
+	}
+
+	if err := transaction.ExecuteUnderTransaction(ctx, c.log, c.beginner, f); err != nil {
+		return Idea{}, fmt.Errorf("update: ideaID[%s]: %w", idea.ID, err)
 	}
 
 	return idea, nil
 }
 
-func (c *Core) Delete(ctx context.Context, idea Idea) error {
-	if err := c.storer.Delete(ctx, idea); err != nil {
-		return fmt.Errorf("delete: %w", err)
+// Delete moves the idea to the trash on behalf of the user. The idea and its
+// content are hidden from every query until it is restored or purged.
+func (c *Core) Delete(ctx context.Context, idea Idea, userID uuid.UUID) (Idea, error) {
+	idea.DeletedAt = time.Now()
+	idea.DeletedBy = userID
+
+	if err := c.storer.SetDeleted(ctx, idea); err != nil {
+		return Idea{}, fmt.Errorf("delete: ideaID[%s]: %w", idea.ID, err)
+	}
+
+	return idea, nil
+}
+
+// Restore takes the idea out of the trash.
+func (c *Core) Restore(ctx context.Context, idea Idea) (Idea, error) {
+	idea.DeletedAt = time.Time{}
+	idea.DeletedBy = uuid.Nil
+
+	if err := c.storer.SetDeleted(ctx, idea); err != nil {
+		return Idea{}, fmt.Errorf("restore: ideaID[%s]: %w", idea.ID, err)
+	}
+
+	return idea, nil
+}
+
+// Purge removes the idea from the database for good, together with its
+// content in the registered cores, in a single transaction. When a step fails
+// everything is rolled back and a CascadeError naming the step is returned.
+// The paths of the files the idea and its content referenced, and that nothing
+// else references once they are gone, are returned so the caller can remove
+// them.
+func (c *Core) Purge(ctx context.Context, idea Idea) ([]string, error) {
+	var files []string
+	if idea.AvatarURL != "" {
+		files = append(files, idea.AvatarURL)
+	}
+
+	f := func(tx transaction.Transaction) error {
+		for _, step := range c.cascade {
+			stepFiles, err := step.purger.PurgeByIdea(ctx, tx, idea.ID)
+			if err != nil {
+				return &CascadeError{Step: step.name, Err: err}
+			}
+			files = append(files, stepFiles...)
+		}
+
+		storer, err := c.storer.ExecuteUnderTransaction(tx)
+		if err != nil {
+			return &CascadeError{Step: CascadeStepIdea, Err: err}
+		}
+
+		if err := storer.Delete(ctx, idea); err != nil {
+			return &CascadeError{Step: CascadeStepIdea, Err: err}
+		}
+
+		files, err = storer.QueryUnreferenced(ctx, files)
+		if err != nil {
+			return &CascadeError{Step: CascadeStepIdea, Err: err}
+		}
+
+		return nil
+	}
+
+	if err := transaction.ExecuteUnderTransaction(ctx, c.log, c.beginner, f); err != nil {
+		return nil, fmt.Errorf("purge: ideaID[%s]: %w", idea.ID, err)
+	}
+
+	return files, nil
+}
+
+// TransitionStage moves the idea to the specified stage on behalf of the user.
+// The move has to be allowed from the idea's current stage and pass the guards
+// registered for the target stage. The guards, the stage change and the stage
+// history are run in a single transaction, and the stage only changes if the
+// idea is still in the stage it was read in.
+func (c *Core) TransitionStage(ctx context.Context, idea Idea, to Stage, userID uuid.UUID, note string) (Idea, error) {
+	from := idea.Stage
+
+	if !from.CanTransitionTo(to) {
+		return Idea{}, fmt.Errorf("transition: %s to %s: %w", from.Name(), to.Name(), ErrInvalidTransition)
+	}
+
+	now := time.Now()
+
+	st := StageTransition{
+		ID:          uuid.New(),
+		IdeaID:      idea.ID,
+		UserID:      userID,
+		FromStage:   from,
+		ToStage:     to,
+		Note:        note,
+		DateCreated: now,
+	}
+
+	f := func(tx transaction.Transaction) error {
+		for _, guard := range c.guards[to] {
+			if err := guard(ctx, tx, idea); err != nil {
+				return err
+			}
+		}
+
+		storer, err := c.storer.ExecuteUnderTransaction(tx)
+		if err != nil {
+			return err
+		}
+
+		if err := storer.UpdateStage(ctx, idea.ID, from, to, now); err != nil {
+			return fmt.Errorf("updatestage: %w", err)
+		}
+
+		if err := storer.CreateStageTransition(ctx, st); err != nil {
+			return fmt.Errorf("createstagetransition: %w", err)
+		}
+
+		return nil
+	}
+
+	if err := transaction.ExecuteUnderTransaction(ctx, c.log, c.beginner, f); err != nil {
+		return Idea{}, fmt.Errorf("transition: ideaID[%s]: %s to %s: %w", idea.ID, from.Name(), to.Name(), err)
+	}
+
+	c.record(ctx, activity.NewActivity{
+		Type:    activity.TypeStageChanged,
+		ActorID: userID,
+		IdeaID:  idea.ID,
+		Details: map[string]string{
+			"from": from.Name(),
+			"to":   to.Name(),
+		},
+	})
+
+	idea.Stage = to
+	idea.DateUpdated = now
+
+	return idea, nil
+}
+
+// QueryStageHistory returns the stage transitions of the idea, oldest first.
+func (c *Core) QueryStageHistory(ctx context.Context, ideaID uuid.UUID) ([]StageTransition, error) {
+	history, err := c.storer.QueryStageHistory(ctx, ideaID)
+	if err != nil {
+		return nil, fmt.Errorf("querystagehistory: ideaID[%s]: %w", ideaID, err)
+	}
+
+	return history, nil
+}
+
+// AddCollaborator grants the user collaborator access to the idea. Callers are
+// expected to have obtained the user's consent, see the invitation package.
+func (c *Core) AddCollaborator(ctx context.Context, ideaID uuid.UUID, userID uuid.UUID) error {
+	if err := c.storer.AddCollaborator(ctx, ideaID, userID); err != nil {
+		return fmt.Errorf("addcollaborator: ideaID[%s] userID[%s]: %w", ideaID, userID, err)
+	}
+
+	return nil
+}
+
+// RemoveCollaborator revokes the user's collaborator access to the idea.
+func (c *Core) RemoveCollaborator(ctx context.Context, ideaID uuid.UUID, userID uuid.UUID) error {
+	if err := c.storer.RemoveCollaborator(ctx, ideaID, userID); err != nil {
+		return fmt.Errorf("removecollaborator: ideaID[%s] userID[%s]: %w", ideaID, userID, err)
 	}
 
 	return nil
@@ -128,6 +431,40 @@ func (c *Core) QueryByID(ctx context.Context, ideaID uuid.UUID) (Idea, error) {
 	return idea, nil
 }
 
+// QueryVisibleByID gets the specified idea if the viewer is allowed to see it.
+// An idea the viewer can't see is reported as ErrNotFound so its existence
+// isn't revealed.
+func (c *Core) QueryVisibleByID(ctx context.Context, ideaID uuid.UUID, viewerID uuid.UUID) (Idea, error) {
+	idea, err := c.QueryByID(ctx, ideaID)
+	if err != nil {
+		return Idea{}, err
+	}
+
+	if !idea.VisibleTo(viewerID) {
+		return Idea{}, fmt.Errorf("query: ideaID[%s]: %w", ideaID, ErrNotFound)
+	}
+
+	return idea, nil
+}
+
+// QueryDeletedByID gets the specified idea from the trash.
+func (c *Core) QueryDeletedByID(ctx context.Context, ideaID uuid.UUID) (Idea, error) {
+	var filter QueryFilter
+	filter.WithIdeaID(ideaID)
+	filter.WithDeleted(true)
+
+	ideas, err := c.storer.Query(ctx, filter, DefaultOrderBy, 1, 1)
+	if err != nil {
+		return Idea{}, fmt.Errorf("query: ideaID[%s]: %w", ideaID, err)
+	}
+
+	if len(ideas) == 0 {
+		return Idea{}, fmt.Errorf("query: ideaID[%s]: %w", ideaID, ErrNotFound)
+	}
+
+	return ideas[0], nil
+}
+
 func (c *Core) QueryByIDs(ctx context.Context, ideaIDs []uuid.UUID) ([]Idea, error) {
 	ideas, err := c.storer.QueryByIDs(ctx, ideaIDs)
 	if err != nil {
@@ -137,6 +474,107 @@ func (c *Core) QueryByIDs(ctx context.Context, ideaIDs []uuid.UUID) ([]Idea, err
 	return ideas, nil
 }
 
-func (c *Core) QueryTags(ctx context.Context) ([]string, error) {
-	return c.storer.QueryTags(ctx)
+// Unreferenced returns the files, out of the specified ones, that no idea,
+// challenge or user profile references any more. Only these can be removed
+// when the content that used them is purged, since a path may be shared.
+func (c *Core) Unreferenced(ctx context.Context, files []string) ([]string, error) {
+	unreferenced, err := c.storer.QueryUnreferenced(ctx, files)
+	if err != nil {
+		return nil, fmt.Errorf("unreferenced: %w", err)
+	}
+
+	return unreferenced, nil
+}
+
+// ReplaceTag rewrites every idea tagged with the old tag to use the new tag
+// instead. It doesn't record revisions, the content of the ideas is
+// unchanged.
+func (c *Core) ReplaceTag(ctx context.Context, from string, to string) error {
+	if err := c.storer.ReplaceTag(ctx, from, to); err != nil {
+		return fmt.Errorf("replacetag: from[%s]: to[%s]: %w", from, to, err)
+	}
+
+	return nil
+}
+
+// QueryLineage returns every idea in the fork tree the idea belongs to,
+// starting from the original idea the tree grew from. Each idea's ForkedFrom
+// points to its parent in the tree.
+func (c *Core) QueryLineage(ctx context.Context, ideaID uuid.UUID) ([]Idea, error) {
+	ideas, err := c.storer.QueryLineage(ctx, ideaID)
+	if err != nil {
+		return nil, fmt.Errorf("querylineage: ideaID[%s]: %w", ideaID, err)
+	}
+
+	return ideas, nil
+}
+
+// QueryRevisions returns the revisions of the idea, newest first.
+func (c *Core) QueryRevisions(ctx context.Context, ideaID uuid.UUID, pageNumber int, rowsPerPage int) ([]Revision, error) {
+	revs, err := c.storer.QueryRevisions(ctx, ideaID, pageNumber, rowsPerPage)
+	if err != nil {
+		return nil, fmt.Errorf("queryrevisions: ideaID[%s]: %w", ideaID, err)
+	}
+
+	return revs, nil
+}
+
+// CountRevisions returns the number of revisions of the idea.
+func (c *Core) CountRevisions(ctx context.Context, ideaID uuid.UUID) (int, error) {
+	return c.storer.CountRevisions(ctx, ideaID)
+}
+
+// QueryRevision gets the specified revision of the idea.
+func (c *Core) QueryRevision(ctx context.Context, ideaID uuid.UUID, number int) (Revision, error) {
+	rev, err := c.storer.QueryRevision(ctx, ideaID, number)
+	if err != nil {
+		return Revision{}, fmt.Errorf("queryrevision: ideaID[%s] number[%d]: %w", ideaID, number, err)
+	}
+
+	return rev, nil
+}
+
+// =============================================================================
+
+// writeRevision records the update of the idea from prev to idea. Ideas
+// updated for the first time get a baseline revision holding their content
+// before the update, so the first change can be diffed and undone as well.
+func writeRevision(ctx context.Context, storer Storer, prev Idea, idea Idea, userID uuid.UUID, changes []FieldChange, restoredFrom int) error {
+	latest, err := storer.QueryLatestRevision(ctx, idea.ID)
+	switch {
+	case errors.Is(err, ErrRevisionNotFound):
+		latest = Revision{
+			ID:          uuid.New(),
+			IdeaID:      prev.ID,
+			Number:      1,
+			UserID:      prev.UserID,
+			Snapshot:    SnapshotOf(prev),
+			DateCreated: prev.DateUpdated,
+		}
+
+		if err := storer.CreateRevision(ctx, latest); err != nil {
+			return fmt.Errorf("baseline: %w", err)
+		}
+
+	case err != nil:
+		return err
+	}
+
+	fields := make([]string, len(changes))
+	for i, change := range changes {
+		fields[i] = change.Field
+	}
+
+	rev := Revision{
+		ID:            uuid.New(),
+		IdeaID:        idea.ID,
+		Number:        latest.Number + 1,
+		UserID:        userID,
+		ChangedFields: fields,
+		Snapshot:      SnapshotOf(idea),
+		RestoredFrom:  restoredFrom,
+		DateCreated:   idea.DateUpdated,
+	}
+
+	return storer.CreateRevision(ctx, rev)
 }
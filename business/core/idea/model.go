@@ -13,37 +13,95 @@ type Idea struct {
 	Description   string
 	Category      string
 	Tags          []string
-	Privacy       string
+	Privacy       Privacy
 	Collaborators []uuid.UUID
 	AvatarURL     string
-	Stage         string
+	Stage         Stage
 	Inspiration   string
+	ForkedFrom    uuid.UUID
+	VoteCount     int
+	DeletedAt     time.Time
+	DeletedBy     uuid.UUID
 	DateCreated   time.Time
 	DateUpdated   time.Time
 }
 
+// Deleted reports whether the idea has been moved to the trash.
+func (i Idea) Deleted() bool {
+	return !i.DeletedAt.IsZero()
+}
+
+// IsCollaborator reports whether the user is listed as a collaborator.
+func (i Idea) IsCollaborator(userID uuid.UUID) bool {
+	for _, collaborator := range i.Collaborators {
+		if collaborator == userID {
+			return true
+		}
+	}
+	return false
+}
+
+// IsMember reports whether the user is the owner of the idea or one of its
+// collaborators.
+func (i Idea) IsMember(userID uuid.UUID) bool {
+	return i.UserID == userID || i.IsCollaborator(userID)
+}
+
+// VisibleTo reports whether the user is allowed to see the idea when
+// addressing it directly by ID. Ideas in the trash are visible to no one.
+func (i Idea) VisibleTo(userID uuid.UUID) bool {
+	if i.Deleted() {
+		return false
+	}
+
+	if i.UserID == userID {
+		return true
+	}
+
+	switch i.Privacy {
+	case PrivacyPublic, PrivacyUnlisted:
+		return true
+	case PrivacyCollaborators:
+		return i.IsCollaborator(userID)
+	}
+
+	return false
+}
+
+// ListedFor reports whether the idea may appear in listings shown to the user.
+// Unlike VisibleTo, unlisted ideas are only listed for their owner and
+// collaborators.
+func (i Idea) ListedFor(userID uuid.UUID) bool {
+	if i.Deleted() {
+		return false
+	}
+
+	if i.UserID == userID || i.Privacy == PrivacyPublic {
+		return true
+	}
+
+	return i.Privacy != PrivacyPrivate && i.IsCollaborator(userID)
+}
+
 type NewIdea struct {
-	UserID        uuid.UUID
-	Title         string
-	Description   string
-	Category      string
-	Tags          []string
-	Privacy       string
-	Collaborators []uuid.UUID
-	AvatarURL     string
-	Stage         string
-	Inspiration   string
+	UserID      uuid.UUID
+	Title       string
+	Description string
+	Category    string
+	Tags        []string
+	Privacy     Privacy
+	AvatarURL   string
+	Inspiration string
+	ForkedFrom  uuid.UUID
 }
 
 type UpdateIdea struct {
-	ID            *uuid.UUID
-	Title         *string
-	Description   *string
-	Category      *string
-	Tags          []string
-	Privacy       *string
-	Collaborators []uuid.UUID
-	AvatarURL     *string
-	Stage         *string
-	Inspiration   *string
+	ID          *uuid.UUID
+	Title       *string
+	Description *string
+	Category    *string
+	Tags        []string
+	Privacy     *Privacy
+	AvatarURL   *string
+	Inspiration *string
 }
@@ -13,4 +13,6 @@ const (
 	OrderByStage       = "stage"
 	OrderByDateCreated = "datecreated"
 	OrderByDateUpdated = "dateupdated"
+	OrderByDateDeleted = "datedeleted"
+	OrderByVotes       = "votes"
 )
@@ -0,0 +1,72 @@
+package idea
+
+import "errors"
+
+// Set of possible privacy levels for an idea.
+var (
+	PrivacyPrivate       = Privacy{"private"}
+	PrivacyCollaborators = Privacy{"collaborators"}
+	PrivacyUnlisted      = Privacy{"unlisted"}
+	PrivacyPublic        = Privacy{"public"}
+)
+
+// Set of known privacy levels.
+var privacies = map[string]Privacy{
+	PrivacyPrivate.name:       PrivacyPrivate,
+	PrivacyCollaborators.name: PrivacyCollaborators,
+	PrivacyUnlisted.name:      PrivacyUnlisted,
+	PrivacyPublic.name:        PrivacyPublic,
+}
+
+// Privacy represents who is allowed to see an idea.
+//
+//   - private: only the owner.
+//   - collaborators: the owner and the idea's collaborators.
+//   - unlisted: anyone holding the idea's ID, but it is left out of listings.
+//   - public: everyone.
+type Privacy struct {
+	name string
+}
+
+// ParsePrivacy parses the string value and returns a privacy level if one
+// exists.
+func ParsePrivacy(value string) (Privacy, error) {
+	privacy, exists := privacies[value]
+	if !exists {
+		return Privacy{}, errors.New("invalid privacy")
+	}
+
+	return privacy, nil
+}
+
+// MustParsePrivacy parses the string value and returns a privacy level if one
+// exists. If an error occurs the function panics.
+func MustParsePrivacy(value string) Privacy {
+	privacy, err := ParsePrivacy(value)
+	if err != nil {
+		panic(err)
+	}
+
+	return privacy
+}
+
+// Name returns the name of the privacy level.
+func (p Privacy) Name() string {
+	return p.name
+}
+
+// UnmarshalText implement the unmarshal interface for JSON conversions.
+func (p *Privacy) UnmarshalText(data []byte) error {
+	p.name = string(data)
+	return nil
+}
+
+// MarshalText implement the marshal interface for JSON conversions.
+func (p Privacy) MarshalText() ([]byte, error) {
+	return []byte(p.name), nil
+}
+
+// Equal provides support for the go-cmp package and testing.
+func (p Privacy) Equal(p2 Privacy) bool {
+	return p.name == p2.name
+}
@@ -0,0 +1,118 @@
+package idea
+
+import (
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Set of fields recorded in a revision.
+const (
+	FieldTitle       = "title"
+	FieldDescription = "description"
+	FieldCategory    = "category"
+	FieldTags        = "tags"
+	FieldPrivacy     = "privacy"
+	FieldAvatarURL   = "avatarURL"
+	FieldInspiration = "inspiration"
+)
+
+// Revision is an immutable record of the content of an idea after an update.
+// Revisions of an idea are numbered from 1 in the order they were made.
+type Revision struct {
+	ID            uuid.UUID
+	IdeaID        uuid.UUID
+	Number        int
+	UserID        uuid.UUID
+	ChangedFields []string
+	Snapshot      Snapshot
+	RestoredFrom  int
+	DateCreated   time.Time
+}
+
+// Snapshot holds the versioned content of an idea. The stage has its own
+// history and collaborators are managed through invitations, so neither is
+// part of it.
+type Snapshot struct {
+	Title       string
+	Description string
+	Category    string
+	Tags        []string
+	Privacy     Privacy
+	AvatarURL   string
+	Inspiration string
+}
+
+// FieldChange describes how a single field differs between two snapshots.
+type FieldChange struct {
+	Field string
+	From  any
+	To    any
+}
+
+// SnapshotOf returns the versioned content of the idea.
+func SnapshotOf(idea Idea) Snapshot {
+	return Snapshot{
+		Title:       idea.Title,
+		Description: idea.Description,
+		Category:    idea.Category,
+		Tags:        idea.Tags,
+		Privacy:     idea.Privacy,
+		AvatarURL:   idea.AvatarURL,
+		Inspiration: idea.Inspiration,
+	}
+}
+
+// Diff returns the fields that differ between the two snapshots.
+func Diff(from Snapshot, to Snapshot) []FieldChange {
+	var changes []FieldChange
+
+	add := func(field string, a any, b any) {
+		changes = append(changes, FieldChange{Field: field, From: a, To: b})
+	}
+
+	if from.Title != to.Title {
+		add(FieldTitle, from.Title, to.Title)
+	}
+	if from.Description != to.Description {
+		add(FieldDescription, from.Description, to.Description)
+	}
+	if from.Category != to.Category {
+		add(FieldCategory, from.Category, to.Category)
+	}
+	if !slices.Equal(from.Tags, to.Tags) {
+		add(FieldTags, from.Tags, to.Tags)
+	}
+	if from.Privacy != to.Privacy {
+		add(FieldPrivacy, from.Privacy.Name(), to.Privacy.Name())
+	}
+	if from.AvatarURL != to.AvatarURL {
+		add(FieldAvatarURL, from.AvatarURL, to.AvatarURL)
+	}
+	if from.Inspiration != to.Inspiration {
+		add(FieldInspiration, from.Inspiration, to.Inspiration)
+	}
+
+	return changes
+}
+
+// toUpdateIdea returns the update that brings an idea back to the snapshot.
+func (s Snapshot) toUpdateIdea(ideaID uuid.UUID) UpdateIdea {
+	privacy := s.Privacy
+	tags := s.Tags
+	if tags == nil {
+		tags = []string{}
+	}
+
+	return UpdateIdea{
+		ID:          &ideaID,
+		Title:       &s.Title,
+		Description: &s.Description,
+		Category:    &s.Category,
+		Tags:        tags,
+		Privacy:     &privacy,
+		AvatarURL:   &s.AvatarURL,
+		Inspiration: &s.Inspiration,
+	}
+}
@@ -0,0 +1,141 @@
+package idea
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/dmanias/startupers/business/data/transaction"
+	"github.com/google/uuid"
+)
+
+// Set of stages in the lifecycle of an idea.
+var (
+	StageSpark      = Stage{"spark"}
+	StageValidation = Stage{"validation"}
+	StagePrototype  = Stage{"prototype"}
+	StageLaunch     = Stage{"launch"}
+	StageArchived   = Stage{"archived"}
+)
+
+// Set of known stages.
+var stages = map[string]Stage{
+	StageSpark.name:      StageSpark,
+	StageValidation.name: StageValidation,
+	StagePrototype.name:  StagePrototype,
+	StageLaunch.name:     StageLaunch,
+	StageArchived.name:   StageArchived,
+}
+
+// transitions lists the stages an idea can be moved to from each stage. An
+// idea moves forward one stage at a time, can step back one stage, and can be
+// archived from anywhere. Archived ideas can only be revived as a spark.
+var transitions = map[Stage][]Stage{
+	StageSpark:      {StageValidation, StageArchived},
+	StageValidation: {StageSpark, StagePrototype, StageArchived},
+	StagePrototype:  {StageValidation, StageLaunch, StageArchived},
+	StageLaunch:     {StagePrototype, StageArchived},
+	StageArchived:   {StageSpark},
+}
+
+// Stage represents where an idea is in its lifecycle.
+type Stage struct {
+	name string
+}
+
+// ParseStage parses the string value and returns a stage if one exists.
+func ParseStage(value string) (Stage, error) {
+	stage, exists := stages[value]
+	if !exists {
+		return Stage{}, errors.New("invalid stage")
+	}
+
+	return stage, nil
+}
+
+// MustParseStage parses the string value and returns a stage if one exists. If
+// an error occurs the function panics.
+func MustParseStage(value string) Stage {
+	stage, err := ParseStage(value)
+	if err != nil {
+		panic(err)
+	}
+
+	return stage
+}
+
+// Name returns the name of the stage.
+func (s Stage) Name() string {
+	return s.name
+}
+
+// CanTransitionTo reports whether an idea in this stage can be moved to the
+// specified stage.
+func (s Stage) CanTransitionTo(to Stage) bool {
+	for _, next := range transitions[s] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// UnmarshalText implement the unmarshal interface for JSON conversions.
+func (s *Stage) UnmarshalText(data []byte) error {
+	s.name = string(data)
+	return nil
+}
+
+// MarshalText implement the marshal interface for JSON conversions.
+func (s Stage) MarshalText() ([]byte, error) {
+	return []byte(s.name), nil
+}
+
+// Equal provides support for the go-cmp package and testing.
+func (s Stage) Equal(s2 Stage) bool {
+	return s.name == s2.name
+}
+
+// =============================================================================
+
+// StageTransition records an idea being moved from one stage to another.
+type StageTransition struct {
+	ID          uuid.UUID
+	IdeaID      uuid.UUID
+	UserID      uuid.UUID
+	FromStage   Stage
+	ToStage     Stage
+	Note        string
+	DateCreated time.Time
+}
+
+// StageGuard is a condition an idea has to meet before it can enter a stage.
+// A guard rejects the transition by returning an error wrapping
+// ErrStageGuard. It runs in the transaction that moves the idea, so what it
+// checks holds when the move is made.
+type StageGuard func(ctx context.Context, tx transaction.Transaction, idea Idea) error
+
+// ChallengeCounter counts the challenges of an idea that have been completed,
+// as part of the caller's transaction. The counted challenges stay as they are
+// until the transaction ends.
+type ChallengeCounter interface {
+	CountCompleted(ctx context.Context, tx transaction.Transaction, ideaID uuid.UUID) (int, error)
+}
+
+// MinCompletedChallenges constructs a guard that requires the idea to have
+// completed at least min challenges.
+func MinCompletedChallenges(min int, counter ChallengeCounter) StageGuard {
+	return func(ctx context.Context, tx transaction.Transaction, idea Idea) error {
+		n, err := counter.CountCompleted(ctx, tx, idea.ID)
+		if err != nil {
+			return fmt.Errorf("countcompleted: %w", err)
+		}
+
+		if n < min {
+			return fmt.Errorf("%w: %d completed challenges required, %d completed", ErrStageGuard, min, n)
+		}
+
+		return nil
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"strings"
 
 	"github.com/dmanias/startupers/business/core/idea"
+	"github.com/google/uuid"
 )
 
 func (s *Store) applyFilter(filter idea.QueryFilter, data map[string]interface{}, buf *bytes.Buffer) {
@@ -23,12 +24,12 @@ func (s *Store) applyFilter(filter idea.QueryFilter, data map[string]interface{}
 
 	if filter.Title != nil {
 		data["title"] = fmt.Sprintf("%%%s%%", *filter.Title)
-		wc = append(wc, "title LIKE :title")
+		wc = append(wc, "title ILIKE :title")
 	}
 
 	if filter.Category != nil {
 		data["category"] = *filter.Category
-		wc = append(wc, "category = :category")
+		wc = append(wc, "(category = :category OR category IN (SELECT category_subtree(:category)))")
 	}
 
 	if filter.Tag != nil {
@@ -36,6 +37,11 @@ func (s *Store) applyFilter(filter idea.QueryFilter, data map[string]interface{}
 		wc = append(wc, "tags @> ARRAY[:tag]")
 	}
 
+	if filter.Stage != nil {
+		data["stage"] = filter.Stage.Name()
+		wc = append(wc, "stage = :stage")
+	}
+
 	if filter.StartCreatedDate != nil {
 		data["start_date_created"] = filter.StartCreatedDate
 		wc = append(wc, "date_created >= :start_date_created")
@@ -46,8 +52,49 @@ func (s *Store) applyFilter(filter idea.QueryFilter, data map[string]interface{}
 		wc = append(wc, "date_created <= :end_date_created")
 	}
 
+	if filter.ForkedFrom != nil {
+		data["forked_from"] = *filter.ForkedFrom
+		wc = append(wc, "forked_from = :forked_from")
+	}
+
+	if filter.ViewerID != nil {
+		wc = append(wc, VisibleClause("", *filter.ViewerID, data))
+	}
+
+	if filter.Privacy != nil {
+		data["privacy"] = filter.Privacy.Name()
+		wc = append(wc, "privacy = :privacy")
+	}
+
+	switch {
+	case filter.DeletedBefore != nil:
+		data["deleted_before"] = filter.DeletedBefore
+		wc = append(wc, "deleted_at < :deleted_before")
+	case filter.Deleted != nil && *filter.Deleted:
+		wc = append(wc, "deleted_at IS NOT NULL")
+	default:
+		wc = append(wc, "deleted_at IS NULL")
+	}
+
 	if len(wc) > 0 {
 		buf.WriteString(" WHERE ")
 		buf.WriteString(strings.Join(wc, " AND "))
 	}
 }
+
+// VisibleClause returns the condition matching the ideas the viewer is allowed
+// to see in a listing: public ideas, their own ideas and ideas they collaborate
+// on unless the owner has made them private. It is shared by the stores that
+// list content of ideas, which pass the alias they gave the ideas table, or an
+// empty string when the columns aren't qualified.
+func VisibleClause(alias string, viewerID uuid.UUID, data map[string]interface{}) string {
+	data["viewer_id"] = viewerID
+	data["privacy_public"] = idea.PrivacyPublic.Name()
+	data["privacy_private"] = idea.PrivacyPrivate.Name()
+
+	if alias != "" {
+		alias += "."
+	}
+
+	return fmt.Sprintf("(%[1]sprivacy = :privacy_public OR %[1]suser_id = :viewer_id OR (%[1]sprivacy <> :privacy_private AND :viewer_id = ANY(%[1]scollaborators)))", alias)
+}
@@ -7,9 +7,12 @@ import (
 	"database/sql/driver"
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/dmanias/startupers/business/core/idea"
 	"github.com/dmanias/startupers/business/data/order"
+	"github.com/dmanias/startupers/business/data/sqldb"
+	"github.com/dmanias/startupers/business/data/transaction"
 	database "github.com/dmanias/startupers/business/sys/database/pgx"
 	"github.com/dmanias/startupers/business/sys/database/pgx/dbarray"
 	"github.com/google/uuid"
@@ -19,7 +22,7 @@ import (
 
 type Store struct {
 	log *zap.SugaredLogger
-	db  *sqlx.DB
+	db  sqlx.ExtContext
 }
 
 func NewStore(log *zap.SugaredLogger, db *sqlx.DB) *Store {
@@ -29,12 +32,28 @@ func NewStore(log *zap.SugaredLogger, db *sqlx.DB) *Store {
 	}
 }
 
+// ExecuteUnderTransaction constructs a new Store value replacing the sqlx DB
+// value with a sqlx DB value that is currently inside a transaction.
+func (s *Store) ExecuteUnderTransaction(tx transaction.Transaction) (idea.Storer, error) {
+	ec, err := sqldb.GetExtContext(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	s = &Store{
+		log: s.log,
+		db:  ec,
+	}
+
+	return s, nil
+}
+
 func (s *Store) Create(ctx context.Context, idea idea.Idea) error {
 	const q = `
 	INSERT INTO ideas
-		(id, user_id, title, description, category, tags, privacy, collaborators, avatar_url, stage, inspiration, date_created, date_updated)
+		(id, user_id, title, description, category, tags, privacy, collaborators, avatar_url, stage, inspiration, forked_from, date_created, date_updated)
 	VALUES
-		(:id, :user_id, :title, :description, :category, :tags, :privacy, :collaborators, :avatar_url, :stage, :inspiration, :date_created, :date_updated)`
+		(:id, :user_id, :title, :description, :category, :tags, :privacy, :collaborators, :avatar_url, :stage, :inspiration, :forked_from, :date_created, :date_updated)`
 
 	if err := database.NamedExecContext(ctx, s.log, s.db, q, toDBIdea(idea)); err != nil {
 		return fmt.Errorf("namedexeccontext: %w", err)
@@ -43,6 +62,8 @@ func (s *Store) Create(ctx context.Context, idea idea.Idea) error {
 	return nil
 }
 
+// Update writes the content of the idea. The stage is left alone, it only
+// changes through UpdateStage.
 func (s *Store) Update(ctx context.Context, idea idea.Idea) error {
 	const q = `
 	UPDATE
@@ -53,9 +74,7 @@ func (s *Store) Update(ctx context.Context, idea idea.Idea) error {
 		"category" = :category,
 		"tags" = :tags,
 		"privacy" = :privacy,
-		"collaborators" = :collaborators,
 		"avatar_url" = :avatar_url,
-		"stage" = :stage,
 		"inspiration" = :inspiration,
 		"date_updated" = :date_updated
 	WHERE
@@ -68,6 +87,117 @@ func (s *Store) Update(ctx context.Context, idea idea.Idea) error {
 	return nil
 }
 
+// UpdateStage moves the idea from one stage to another, provided it is still
+// in the from stage. An idea moved by someone else in the meantime is
+// reported as ErrInvalidTransition.
+func (s *Store) UpdateStage(ctx context.Context, ideaID uuid.UUID, from idea.Stage, to idea.Stage, now time.Time) error {
+	data := struct {
+		ID          string    `db:"id"`
+		Stage       string    `db:"stage"`
+		From        string    `db:"from_stage"`
+		DateUpdated time.Time `db:"date_updated"`
+	}{
+		ID:          ideaID.String(),
+		Stage:       to.Name(),
+		From:        from.Name(),
+		DateUpdated: now.UTC(),
+	}
+
+	const q = `
+	UPDATE
+		ideas
+	SET
+		"stage" = :stage,
+		"date_updated" = :date_updated
+	WHERE
+		id = :id AND
+		stage = :from_stage
+	RETURNING
+		id`
+
+	var updated struct {
+		ID uuid.UUID `db:"id"`
+	}
+	if err := database.NamedQueryStruct(ctx, s.log, s.db, q, data, &updated); err != nil {
+		if errors.Is(err, database.ErrDBNotFound) {
+			return fmt.Errorf("namedquerystruct: %w", idea.ErrInvalidTransition)
+		}
+		return fmt.Errorf("namedquerystruct: %w", err)
+	}
+
+	return nil
+}
+
+// AddCollaborator adds the user to the idea's collaborators unless they are
+// already listed.
+func (s *Store) AddCollaborator(ctx context.Context, ideaID uuid.UUID, userID uuid.UUID) error {
+	data := struct {
+		ID     string `db:"id"`
+		UserID string `db:"user_id"`
+	}{
+		ID:     ideaID.String(),
+		UserID: userID.String(),
+	}
+
+	const q = `
+	UPDATE
+		ideas
+	SET
+		"collaborators" = array_append(coalesce(collaborators, '{}'), CAST(:user_id AS UUID))
+	WHERE
+		id = :id AND
+		NOT (CAST(:user_id AS UUID) = ANY(coalesce(collaborators, '{}')))`
+
+	if err := database.NamedExecContext(ctx, s.log, s.db, q, data); err != nil {
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	return nil
+}
+
+// RemoveCollaborator removes the user from the idea's collaborators.
+func (s *Store) RemoveCollaborator(ctx context.Context, ideaID uuid.UUID, userID uuid.UUID) error {
+	data := struct {
+		ID     string `db:"id"`
+		UserID string `db:"user_id"`
+	}{
+		ID:     ideaID.String(),
+		UserID: userID.String(),
+	}
+
+	const q = `
+	UPDATE
+		ideas
+	SET
+		"collaborators" = array_remove(collaborators, CAST(:user_id AS UUID))
+	WHERE
+		id = :id`
+
+	if err := database.NamedExecContext(ctx, s.log, s.db, q, data); err != nil {
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	return nil
+}
+
+// SetDeleted records whether the idea is in the trash, and who moved it there.
+func (s *Store) SetDeleted(ctx context.Context, idea idea.Idea) error {
+	const q = `
+	UPDATE
+		ideas
+	SET
+		"deleted_at" = :deleted_at,
+		"deleted_by" = :deleted_by
+	WHERE
+		id = :id`
+
+	if err := database.NamedExecContext(ctx, s.log, s.db, q, toDBIdea(idea)); err != nil {
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	return nil
+}
+
 func (s *Store) Delete(ctx context.Context, idea idea.Idea) error {
 	data := struct {
 		IdeaID string `db:"id"`
@@ -153,8 +283,9 @@ func (s *Store) QueryByID(ctx context.Context, ideaID uuid.UUID) (idea.Idea, err
 		*
 	FROM
 		ideas
-	WHERE 
-		id = :id`
+	WHERE
+		id = :id AND
+		deleted_at IS NULL`
 
 	var dbIdea dbIdea
 	if err := database.NamedQueryStruct(ctx, s.log, s.db, q, data, &dbIdea); err != nil {
@@ -188,7 +319,8 @@ func (s *Store) QueryByIDs(ctx context.Context, ideaIDs []uuid.UUID) ([]idea.Ide
 	FROM
 		ideas
 	WHERE
-		id = ANY(:id)`
+		id = ANY(:id) AND
+		deleted_at IS NULL`
 
 	var ideas []dbIdea
 	if err := database.NamedQuerySlice(ctx, s.log, s.db, q, data, &ideas); err != nil {
@@ -201,29 +333,411 @@ func (s *Store) QueryByIDs(ctx context.Context, ideaIDs []uuid.UUID) ([]idea.Ide
 	return toCoreIdeaSlice(ideas), nil
 }
 
-func (s *Store) QueryTags(ctx context.Context) ([]string, error) {
+// QueryUnreferenced returns the files no idea, challenge or user profile
+// references.
+func (s *Store) QueryUnreferenced(ctx context.Context, files []string) ([]string, error) {
+	if len(files) == 0 {
+		return nil, nil
+	}
+
+	data := struct {
+		Files interface {
+			driver.Valuer
+			sql.Scanner
+		} `db:"files"`
+	}{
+		Files: dbarray.Array(files),
+	}
+
 	const q = `
-	SELECT DISTINCT unnest(tags) as tag
-	FROM ideas`
+	SELECT DISTINCT
+		f.path
+	FROM
+		unnest(CAST(:files AS TEXT[])) AS f(path)
+	WHERE
+		f.path <> '' AND
+		NOT EXISTS (SELECT 1 FROM ideas WHERE avatar_url = f.path) AND
+		NOT EXISTS (SELECT 1 FROM challenges WHERE photo_url = f.path) AND
+		NOT EXISTS (SELECT 1 FROM users WHERE avatar_url = f.path)`
 
-	rows, err := s.db.QueryContext(ctx, q)
-	if err != nil {
-		return nil, fmt.Errorf("querying tags: %w", err)
+	var dbFiles []struct {
+		Path string `db:"path"`
+	}
+	if err := database.NamedQuerySlice(ctx, s.log, s.db, q, data, &dbFiles); err != nil {
+		return nil, fmt.Errorf("namedqueryslice: %w", err)
+	}
+
+	unreferenced := make([]string, len(dbFiles))
+	for i, dbFile := range dbFiles {
+		unreferenced[i] = dbFile.Path
+	}
+
+	return unreferenced, nil
+}
+
+// ReplaceTag rewrites every idea tagged with the old slug to use the new slug
+// instead. Ideas already tagged with both keep a single copy, in the position
+// of the first one.
+func (s *Store) ReplaceTag(ctx context.Context, from string, to string) error {
+	data := struct {
+		From string `db:"from_tag"`
+		To   string `db:"to_tag"`
+	}{
+		From: from,
+		To:   to,
+	}
+
+	const q = `
+	UPDATE
+		ideas
+	SET
+		"tags" = ARRAY(
+			SELECT t.tag
+			FROM (
+				SELECT tag, min(ord) AS ord
+				FROM unnest(array_replace(tags, :from_tag, :to_tag)) WITH ORDINALITY AS u(tag, ord)
+				GROUP BY tag
+			) t
+			ORDER BY t.ord)
+	WHERE
+		tags @> ARRAY[CAST(:from_tag AS TEXT)]`
+
+	if err := database.NamedExecContext(ctx, s.log, s.db, q, data); err != nil {
+		return fmt.Errorf("namedexeccontext: %w", err)
 	}
-	defer rows.Close()
 
-	var tags []string
-	for rows.Next() {
-		var tag string
-		if err := rows.Scan(&tag); err != nil {
-			return nil, fmt.Errorf("scanning tag: %w", err)
+	return nil
+}
+
+// CreateStageTransition records the idea being moved to another stage.
+func (s *Store) CreateStageTransition(ctx context.Context, st idea.StageTransition) error {
+	const q = `
+	INSERT INTO idea_stage_history
+		(id, idea_id, user_id, from_stage, to_stage, note, date_created)
+	VALUES
+		(:id, :idea_id, :user_id, :from_stage, :to_stage, :note, :date_created)`
+
+	if err := database.NamedExecContext(ctx, s.log, s.db, q, toDBStageTransition(st)); err != nil {
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	return nil
+}
+
+// QueryStageHistory retrieves the stage transitions of the idea, oldest first.
+func (s *Store) QueryStageHistory(ctx context.Context, ideaID uuid.UUID) ([]idea.StageTransition, error) {
+	data := struct {
+		IdeaID string `db:"idea_id"`
+	}{
+		IdeaID: ideaID.String(),
+	}
+
+	const q = `
+	SELECT
+		*
+	FROM
+		idea_stage_history
+	WHERE
+		idea_id = :idea_id
+	ORDER BY
+		date_created`
+
+	var dbSTs []dbStageTransition
+	if err := database.NamedQuerySlice(ctx, s.log, s.db, q, data, &dbSTs); err != nil {
+		return nil, fmt.Errorf("namedqueryslice: %w", err)
+	}
+
+	return toCoreStageTransitionSlice(dbSTs), nil
+}
+
+// CreateVote records the user's vote for the idea. The vote count on the idea
+// is kept up to date by the database.
+func (s *Store) CreateVote(ctx context.Context, vote idea.Vote) error {
+	const q = `
+	INSERT INTO idea_votes
+		(idea_id, user_id, date_created)
+	VALUES
+		(:idea_id, :user_id, :date_created)`
+
+	if err := database.NamedExecContext(ctx, s.log, s.db, q, toDBVote(vote)); err != nil {
+		if errors.Is(err, database.ErrDBDuplicatedEntry) {
+			return fmt.Errorf("namedexeccontext: %w", idea.ErrAlreadyVoted)
 		}
-		tags = append(tags, tag)
+		return fmt.Errorf("namedexeccontext: %w", err)
 	}
 
-	if err := rows.Err(); err != nil {
-		return nil, fmt.Errorf("iterating rows: %w", err)
+	return nil
+}
+
+// DeleteVote removes the user's vote for the idea.
+func (s *Store) DeleteVote(ctx context.Context, ideaID uuid.UUID, userID uuid.UUID) error {
+	data := struct {
+		IdeaID string `db:"idea_id"`
+		UserID string `db:"user_id"`
+	}{
+		IdeaID: ideaID.String(),
+		UserID: userID.String(),
+	}
+
+	const q = `
+	DELETE FROM
+		idea_votes
+	WHERE
+		idea_id = :idea_id AND
+		user_id = :user_id
+	RETURNING
+		idea_id`
+
+	var deleted struct {
+		IdeaID uuid.UUID `db:"idea_id"`
+	}
+	if err := database.NamedQueryStruct(ctx, s.log, s.db, q, data, &deleted); err != nil {
+		if errors.Is(err, database.ErrDBNotFound) {
+			return fmt.Errorf("namedquerystruct: %w", idea.ErrVoteNotFound)
+		}
+		return fmt.Errorf("namedquerystruct: %w", err)
+	}
+
+	return nil
+}
+
+// QueryVotes retrieves a page of the votes for an idea, newest first.
+func (s *Store) QueryVotes(ctx context.Context, ideaID uuid.UUID, pageNumber int, rowsPerPage int) ([]idea.Vote, error) {
+	data := map[string]interface{}{
+		"idea_id":       ideaID,
+		"offset":        (pageNumber - 1) * rowsPerPage,
+		"rows_per_page": rowsPerPage,
+	}
+
+	const q = `
+	SELECT
+		*
+	FROM
+		idea_votes
+	WHERE
+		idea_id = :idea_id
+	ORDER BY
+		date_created DESC, user_id
+	OFFSET :offset ROWS FETCH NEXT :rows_per_page ROWS ONLY`
+
+	var dbVotes []dbVote
+	if err := database.NamedQuerySlice(ctx, s.log, s.db, q, data, &dbVotes); err != nil {
+		return nil, fmt.Errorf("namedqueryslice: %w", err)
+	}
+
+	return toCoreVoteSlice(dbVotes), nil
+}
+
+// CountVotes returns the number of votes for an idea.
+func (s *Store) CountVotes(ctx context.Context, ideaID uuid.UUID) (int, error) {
+	data := struct {
+		IdeaID string `db:"idea_id"`
+	}{
+		IdeaID: ideaID.String(),
+	}
+
+	const q = `
+	SELECT
+		count(1)
+	FROM
+		idea_votes
+	WHERE
+		idea_id = :idea_id`
+
+	var count struct {
+		Count int `db:"count"`
+	}
+	if err := database.NamedQueryStruct(ctx, s.log, s.db, q, data, &count); err != nil {
+		return 0, fmt.Errorf("namedquerystruct: %w", err)
+	}
+
+	return count.Count, nil
+}
+
+// QueryLineage retrieves the fork tree the idea belongs to, from the original
+// idea down.
+func (s *Store) QueryLineage(ctx context.Context, ideaID uuid.UUID) ([]idea.Idea, error) {
+	data := struct {
+		ID string `db:"id"`
+	}{
+		ID: ideaID.String(),
+	}
+
+	const q = `
+	WITH RECURSIVE ancestors AS (
+		SELECT
+			id, forked_from
+		FROM
+			ideas
+		WHERE
+			id = :id
+		UNION ALL
+		SELECT
+			i.id, i.forked_from
+		FROM
+			ideas AS i
+		JOIN
+			ancestors AS a ON i.id = a.forked_from
+	), tree AS (
+		SELECT
+			i.*
+		FROM
+			ideas AS i
+		JOIN
+			ancestors AS a ON a.id = i.id AND a.forked_from IS NULL
+		UNION ALL
+		SELECT
+			i.*
+		FROM
+			ideas AS i
+		JOIN
+			tree AS t ON i.forked_from = t.id
+	)
+	SELECT
+		*
+	FROM
+		tree`
+
+	var dbIdeas []dbIdea
+	if err := database.NamedQuerySlice(ctx, s.log, s.db, q, data, &dbIdeas); err != nil {
+		return nil, fmt.Errorf("namedqueryslice: %w", err)
+	}
+
+	if len(dbIdeas) == 0 {
+		return nil, fmt.Errorf("namedqueryslice: %w", idea.ErrNotFound)
+	}
+
+	return toCoreIdeaSlice(dbIdeas), nil
+}
+
+// CreateRevision inserts a new revision of an idea into the database.
+func (s *Store) CreateRevision(ctx context.Context, rev idea.Revision) error {
+	dbRev, err := toDBRevision(rev)
+	if err != nil {
+		return err
+	}
+
+	const q = `
+	INSERT INTO idea_revisions
+		(id, idea_id, number, user_id, changed_fields, snapshot, restored_from, date_created)
+	VALUES
+		(:id, :idea_id, :number, :user_id, :changed_fields, CAST(:snapshot AS JSONB), :restored_from, :date_created)`
+
+	if err := database.NamedExecContext(ctx, s.log, s.db, q, dbRev); err != nil {
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	return nil
+}
+
+// QueryRevisions retrieves a page of the revisions of an idea, newest first.
+func (s *Store) QueryRevisions(ctx context.Context, ideaID uuid.UUID, pageNumber int, rowsPerPage int) ([]idea.Revision, error) {
+	data := map[string]interface{}{
+		"idea_id":       ideaID,
+		"offset":        (pageNumber - 1) * rowsPerPage,
+		"rows_per_page": rowsPerPage,
+	}
+
+	const q = `
+	SELECT
+		id, idea_id, number, user_id, changed_fields, CAST(snapshot AS TEXT) AS snapshot, restored_from, date_created
+	FROM
+		idea_revisions
+	WHERE
+		idea_id = :idea_id
+	ORDER BY
+		number DESC
+	OFFSET :offset ROWS FETCH NEXT :rows_per_page ROWS ONLY`
+
+	var dbRevs []dbRevision
+	if err := database.NamedQuerySlice(ctx, s.log, s.db, q, data, &dbRevs); err != nil {
+		return nil, fmt.Errorf("namedqueryslice: %w", err)
+	}
+
+	return toCoreRevisionSlice(dbRevs)
+}
+
+// CountRevisions returns the number of revisions of an idea.
+func (s *Store) CountRevisions(ctx context.Context, ideaID uuid.UUID) (int, error) {
+	data := struct {
+		IdeaID string `db:"idea_id"`
+	}{
+		IdeaID: ideaID.String(),
+	}
+
+	const q = `
+	SELECT
+		count(1)
+	FROM
+		idea_revisions
+	WHERE
+		idea_id = :idea_id`
+
+	var count struct {
+		Count int `db:"count"`
+	}
+	if err := database.NamedQueryStruct(ctx, s.log, s.db, q, data, &count); err != nil {
+		return 0, fmt.Errorf("namedquerystruct: %w", err)
+	}
+
+	return count.Count, nil
+}
+
+// QueryRevision gets the specified revision of an idea.
+func (s *Store) QueryRevision(ctx context.Context, ideaID uuid.UUID, number int) (idea.Revision, error) {
+	data := struct {
+		IdeaID string `db:"idea_id"`
+		Number int    `db:"number"`
+	}{
+		IdeaID: ideaID.String(),
+		Number: number,
+	}
+
+	const q = `
+	SELECT
+		id, idea_id, number, user_id, changed_fields, CAST(snapshot AS TEXT) AS snapshot, restored_from, date_created
+	FROM
+		idea_revisions
+	WHERE
+		idea_id = :idea_id AND
+		number = :number`
+
+	return s.queryRevision(ctx, q, data)
+}
+
+// QueryLatestRevision gets the most recent revision of an idea.
+func (s *Store) QueryLatestRevision(ctx context.Context, ideaID uuid.UUID) (idea.Revision, error) {
+	data := struct {
+		IdeaID string `db:"idea_id"`
+	}{
+		IdeaID: ideaID.String(),
+	}
+
+	// Lock the row so concurrent updates of the idea are numbered one after
+	// the other instead of failing on the unique number.
+	const q = `
+	SELECT
+		id, idea_id, number, user_id, changed_fields, CAST(snapshot AS TEXT) AS snapshot, restored_from, date_created
+	FROM
+		idea_revisions
+	WHERE
+		idea_id = :idea_id
+	ORDER BY
+		number DESC
+	FETCH FIRST 1 ROWS ONLY
+	FOR UPDATE`
+
+	return s.queryRevision(ctx, q, data)
+}
+
+func (s *Store) queryRevision(ctx context.Context, q string, data any) (idea.Revision, error) {
+	var dbRev dbRevision
+	if err := database.NamedQueryStruct(ctx, s.log, s.db, q, data, &dbRev); err != nil {
+		if errors.Is(err, database.ErrDBNotFound) {
+			return idea.Revision{}, fmt.Errorf("namedquerystruct: %w", idea.ErrRevisionNotFound)
+		}
+		return idea.Revision{}, fmt.Errorf("namedquerystruct: %w", err)
 	}
 
-	return tags, nil
+	return toCoreRevision(dbRev)
 }
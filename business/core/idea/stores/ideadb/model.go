@@ -1,6 +1,9 @@
 package ideadb
 
 import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
 	"time"
 
 	"github.com/dmanias/startupers/business/core/idea"
@@ -20,6 +23,10 @@ type dbIdea struct {
 	AvatarURL     string         `db:"avatar_url"`
 	Stage         string         `db:"stage"`
 	Inspiration   string         `db:"inspiration"`
+	ForkedFrom    uuid.NullUUID  `db:"forked_from"`
+	VoteCount     int            `db:"vote_count"`
+	DeletedAt     sql.NullTime   `db:"deleted_at"`
+	DeletedBy     uuid.NullUUID  `db:"deleted_by"`
 	DateCreated   time.Time      `db:"date_created"`
 	DateUpdated   time.Time      `db:"date_updated"`
 }
@@ -32,17 +39,35 @@ func toDBIdea(idea idea.Idea) dbIdea {
 		Description:   idea.Description,
 		Category:      idea.Category,
 		Tags:          idea.Tags,
-		Privacy:       idea.Privacy,
+		Privacy:       idea.Privacy.Name(),
 		Collaborators: idea.Collaborators,
 		AvatarURL:     idea.AvatarURL,
-		Stage:         idea.Stage,
+		Stage:         idea.Stage.Name(),
 		Inspiration:   idea.Inspiration,
-		DateCreated:   idea.DateCreated.UTC(),
-		DateUpdated:   idea.DateUpdated.UTC(),
+		ForkedFrom: uuid.NullUUID{
+			UUID:  idea.ForkedFrom,
+			Valid: idea.ForkedFrom != uuid.Nil,
+		},
+		VoteCount: idea.VoteCount,
+		DeletedAt: sql.NullTime{
+			Time:  idea.DeletedAt.UTC(),
+			Valid: idea.Deleted(),
+		},
+		DeletedBy: uuid.NullUUID{
+			UUID:  idea.DeletedBy,
+			Valid: idea.DeletedBy != uuid.Nil,
+		},
+		DateCreated: idea.DateCreated.UTC(),
+		DateUpdated: idea.DateUpdated.UTC(),
 	}
 }
 
 func toCoreIdea(dbIdea dbIdea) idea.Idea {
+	var deletedAt time.Time
+	if dbIdea.DeletedAt.Valid {
+		deletedAt = dbIdea.DeletedAt.Time.In(time.Local)
+	}
+
 	return idea.Idea{
 		ID:            dbIdea.ID,
 		UserID:        dbIdea.UserID,
@@ -50,11 +75,15 @@ func toCoreIdea(dbIdea dbIdea) idea.Idea {
 		Description:   dbIdea.Description,
 		Category:      dbIdea.Category,
 		Tags:          dbIdea.Tags,
-		Privacy:       dbIdea.Privacy,
+		Privacy:       idea.MustParsePrivacy(dbIdea.Privacy),
 		Collaborators: dbIdea.Collaborators,
 		AvatarURL:     dbIdea.AvatarURL,
-		Stage:         dbIdea.Stage,
+		Stage:         idea.MustParseStage(dbIdea.Stage),
 		Inspiration:   dbIdea.Inspiration,
+		ForkedFrom:    dbIdea.ForkedFrom.UUID,
+		VoteCount:     dbIdea.VoteCount,
+		DeletedAt:     deletedAt,
+		DeletedBy:     dbIdea.DeletedBy.UUID,
 		DateCreated:   dbIdea.DateCreated.In(time.Local),
 		DateUpdated:   dbIdea.DateUpdated.In(time.Local),
 	}
@@ -67,3 +96,172 @@ func toCoreIdeaSlice(dbIdeas []dbIdea) []idea.Idea {
 	}
 	return ideas
 }
+
+// =============================================================================
+
+type dbStageTransition struct {
+	ID          uuid.UUID `db:"id"`
+	IdeaID      uuid.UUID `db:"idea_id"`
+	UserID      uuid.UUID `db:"user_id"`
+	FromStage   string    `db:"from_stage"`
+	ToStage     string    `db:"to_stage"`
+	Note        string    `db:"note"`
+	DateCreated time.Time `db:"date_created"`
+}
+
+func toDBStageTransition(st idea.StageTransition) dbStageTransition {
+	return dbStageTransition{
+		ID:          st.ID,
+		IdeaID:      st.IdeaID,
+		UserID:      st.UserID,
+		FromStage:   st.FromStage.Name(),
+		ToStage:     st.ToStage.Name(),
+		Note:        st.Note,
+		DateCreated: st.DateCreated.UTC(),
+	}
+}
+
+func toCoreStageTransitionSlice(dbSTs []dbStageTransition) []idea.StageTransition {
+	sts := make([]idea.StageTransition, len(dbSTs))
+	for i, dbST := range dbSTs {
+		sts[i] = idea.StageTransition{
+			ID:          dbST.ID,
+			IdeaID:      dbST.IdeaID,
+			UserID:      dbST.UserID,
+			FromStage:   idea.MustParseStage(dbST.FromStage),
+			ToStage:     idea.MustParseStage(dbST.ToStage),
+			Note:        dbST.Note,
+			DateCreated: dbST.DateCreated.In(time.Local),
+		}
+	}
+	return sts
+}
+
+// =============================================================================
+
+type dbRevision struct {
+	ID            uuid.UUID      `db:"id"`
+	IdeaID        uuid.UUID      `db:"idea_id"`
+	Number        int            `db:"number"`
+	UserID        uuid.UUID      `db:"user_id"`
+	ChangedFields dbarray.String `db:"changed_fields"`
+	Snapshot      string         `db:"snapshot"`
+	RestoredFrom  sql.NullInt64  `db:"restored_from"`
+	DateCreated   time.Time      `db:"date_created"`
+}
+
+// dbSnapshot is the JSON document a revision's snapshot is stored as.
+type dbSnapshot struct {
+	Title       string   `json:"title"`
+	Description string   `json:"description"`
+	Category    string   `json:"category"`
+	Tags        []string `json:"tags"`
+	Privacy     string   `json:"privacy"`
+	AvatarURL   string   `json:"avatarURL"`
+	Inspiration string   `json:"inspiration"`
+}
+
+func toDBRevision(rev idea.Revision) (dbRevision, error) {
+	snapshot, err := json.Marshal(dbSnapshot{
+		Title:       rev.Snapshot.Title,
+		Description: rev.Snapshot.Description,
+		Category:    rev.Snapshot.Category,
+		Tags:        rev.Snapshot.Tags,
+		Privacy:     rev.Snapshot.Privacy.Name(),
+		AvatarURL:   rev.Snapshot.AvatarURL,
+		Inspiration: rev.Snapshot.Inspiration,
+	})
+	if err != nil {
+		return dbRevision{}, fmt.Errorf("marshal snapshot: %w", err)
+	}
+
+	changedFields := rev.ChangedFields
+	if changedFields == nil {
+		changedFields = []string{}
+	}
+
+	return dbRevision{
+		ID:            rev.ID,
+		IdeaID:        rev.IdeaID,
+		Number:        rev.Number,
+		UserID:        rev.UserID,
+		ChangedFields: changedFields,
+		Snapshot:      string(snapshot),
+		RestoredFrom: sql.NullInt64{
+			Int64: int64(rev.RestoredFrom),
+			Valid: rev.RestoredFrom != 0,
+		},
+		DateCreated: rev.DateCreated.UTC(),
+	}, nil
+}
+
+func toCoreRevision(dbRev dbRevision) (idea.Revision, error) {
+	var snapshot dbSnapshot
+	if err := json.Unmarshal([]byte(dbRev.Snapshot), &snapshot); err != nil {
+		return idea.Revision{}, fmt.Errorf("unmarshal snapshot: %w", err)
+	}
+
+	privacy, err := idea.ParsePrivacy(snapshot.Privacy)
+	if err != nil {
+		return idea.Revision{}, fmt.Errorf("parse privacy: %w", err)
+	}
+
+	return idea.Revision{
+		ID:            dbRev.ID,
+		IdeaID:        dbRev.IdeaID,
+		Number:        dbRev.Number,
+		UserID:        dbRev.UserID,
+		ChangedFields: dbRev.ChangedFields,
+		Snapshot: idea.Snapshot{
+			Title:       snapshot.Title,
+			Description: snapshot.Description,
+			Category:    snapshot.Category,
+			Tags:        snapshot.Tags,
+			Privacy:     privacy,
+			AvatarURL:   snapshot.AvatarURL,
+			Inspiration: snapshot.Inspiration,
+		},
+		RestoredFrom: int(dbRev.RestoredFrom.Int64),
+		DateCreated:  dbRev.DateCreated.In(time.Local),
+	}, nil
+}
+
+func toCoreRevisionSlice(dbRevs []dbRevision) ([]idea.Revision, error) {
+	revs := make([]idea.Revision, len(dbRevs))
+	for i, dbRev := range dbRevs {
+		rev, err := toCoreRevision(dbRev)
+		if err != nil {
+			return nil, err
+		}
+		revs[i] = rev
+	}
+	return revs, nil
+}
+
+// =============================================================================
+
+type dbVote struct {
+	IdeaID      uuid.UUID `db:"idea_id"`
+	UserID      uuid.UUID `db:"user_id"`
+	DateCreated time.Time `db:"date_created"`
+}
+
+func toDBVote(vote idea.Vote) dbVote {
+	return dbVote{
+		IdeaID:      vote.IdeaID,
+		UserID:      vote.UserID,
+		DateCreated: vote.DateCreated.UTC(),
+	}
+}
+
+func toCoreVoteSlice(dbVotes []dbVote) []idea.Vote {
+	votes := make([]idea.Vote, len(dbVotes))
+	for i, dbVote := range dbVotes {
+		votes[i] = idea.Vote{
+			IdeaID:      dbVote.IdeaID,
+			UserID:      dbVote.UserID,
+			DateCreated: dbVote.DateCreated.In(time.Local),
+		}
+	}
+	return votes
+}
@@ -15,6 +15,8 @@ var orderByFields = map[string]string{
 	idea.OrderByStage:       "stage",
 	idea.OrderByDateCreated: "date_created",
 	idea.OrderByDateUpdated: "date_updated",
+	idea.OrderByDateDeleted: "deleted_at",
+	idea.OrderByVotes:       "vote_count",
 }
 
 func orderByClause(orderBy order.By) (string, error) {
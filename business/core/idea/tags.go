@@ -0,0 +1,27 @@
+package idea
+
+import (
+	"context"
+	"fmt"
+)
+
+// TagNormalizer turns the tags entered by users into the canonical tags of
+// the vocabulary. A nil list is returned as nil so updates that leave the tags
+// alone keep doing so.
+type TagNormalizer interface {
+	Normalize(ctx context.Context, names []string) ([]string, error)
+}
+
+// normalizeTags runs the tags through the registered normalizer, if any.
+func (c *Core) normalizeTags(ctx context.Context, tags []string) ([]string, error) {
+	if c.tags == nil || tags == nil {
+		return tags, nil
+	}
+
+	tags, err := c.tags.Normalize(ctx, tags)
+	if err != nil {
+		return nil, fmt.Errorf("normalizetags: %w", err)
+	}
+
+	return tags, nil
+}
@@ -0,0 +1,58 @@
+package idea
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Vote records a user signalling interest in an idea. A user votes for an
+// idea at most once; the number of votes is kept on the idea as VoteCount.
+type Vote struct {
+	IdeaID      uuid.UUID
+	UserID      uuid.UUID
+	DateCreated time.Time
+}
+
+// Vote adds the user's vote to the idea and returns the idea with its updated
+// vote count.
+func (c *Core) Vote(ctx context.Context, idea Idea, userID uuid.UUID) (Idea, error) {
+	vote := Vote{
+		IdeaID:      idea.ID,
+		UserID:      userID,
+		DateCreated: time.Now(),
+	}
+
+	if err := c.storer.CreateVote(ctx, vote); err != nil {
+		return Idea{}, fmt.Errorf("vote: ideaID[%s]: %w", idea.ID, err)
+	}
+
+	return c.QueryByID(ctx, idea.ID)
+}
+
+// Unvote takes the user's vote back and returns the idea with its updated
+// vote count.
+func (c *Core) Unvote(ctx context.Context, idea Idea, userID uuid.UUID) (Idea, error) {
+	if err := c.storer.DeleteVote(ctx, idea.ID, userID); err != nil {
+		return Idea{}, fmt.Errorf("unvote: ideaID[%s]: %w", idea.ID, err)
+	}
+
+	return c.QueryByID(ctx, idea.ID)
+}
+
+// QueryVotes returns a page of the votes for an idea, newest first.
+func (c *Core) QueryVotes(ctx context.Context, ideaID uuid.UUID, pageNumber int, rowsPerPage int) ([]Vote, error) {
+	votes, err := c.storer.QueryVotes(ctx, ideaID, pageNumber, rowsPerPage)
+	if err != nil {
+		return nil, fmt.Errorf("queryvotes: ideaID[%s]: %w", ideaID, err)
+	}
+
+	return votes, nil
+}
+
+// CountVotes returns the number of votes for an idea.
+func (c *Core) CountVotes(ctx context.Context, ideaID uuid.UUID) (int, error) {
+	return c.storer.CountVotes(ctx, ideaID)
+}
@@ -0,0 +1,310 @@
+// Package identity provides the business API for signing users in with
+// OpenID Connect providers. The first sign in with a provider links it to the
+// user with the same verified email address, or creates a user when there is
+// none.
+package identity
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"net/mail"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/dmanias/startupers/business/core/user"
+	"github.com/dmanias/startupers/foundation/oidc"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// LoginTTL is how long a user has to complete a sign in with a provider.
+const LoginTTL = 10 * time.Minute
+
+// Set of error variables for CRUD operations.
+var (
+	ErrNotFound         = errors.New("identity not found")
+	ErrUnknownProvider  = errors.New("unknown identity provider")
+	ErrInvalidState     = errors.New("sign in is invalid or has expired")
+	ErrSignInFailed     = errors.New("identity provider did not confirm the sign in")
+	ErrEmailNotVerified = errors.New("identity provider has not verified the email address")
+	ErrUserDisabled     = errors.New("user is disabled")
+)
+
+// Storer interface declares the behavior this package needs to perists and
+// retrieve data.
+type Storer interface {
+	Create(ctx context.Context, idn Identity) error
+	QueryBySubject(ctx context.Context, provider string, subject string) (Identity, error)
+	CreateLogin(ctx context.Context, lgn Login) error
+	ConsumeLogin(ctx context.Context, stateHash []byte, provider string, now time.Time) (Login, error)
+	DeleteExpiredLogins(ctx context.Context, now time.Time) error
+}
+
+// SessionRevoker ends every session of a user.
+type SessionRevoker interface {
+	RevokeUser(ctx context.Context, userID uuid.UUID) error
+}
+
+// Core manages the set of APIs for identity access.
+type Core struct {
+	log       *zap.SugaredLogger
+	storer    Storer
+	user      *user.Core
+	providers map[string]*oidc.Provider
+	revokers  []SessionRevoker
+}
+
+// NewCore constructs a core for identity api access.
+func NewCore(log *zap.SugaredLogger, storer Storer, userCore *user.Core, providers ...*oidc.Provider) *Core {
+	c := Core{
+		log:       log,
+		storer:    storer,
+		user:      userCore,
+		providers: make(map[string]*oidc.Provider, len(providers)),
+	}
+
+	for _, p := range providers {
+		c.providers[p.Name()] = p
+	}
+
+	return &c
+}
+
+// AddSessionRevoker registers a revoker that ends the sessions of a user
+// whose unverified account is taken over by the owner of the email address.
+// It is meant to be called while the application is being wired up, before
+// the core is in use.
+func (c *Core) AddSessionRevoker(revoker SessionRevoker) {
+	c.revokers = append(c.revokers, revoker)
+}
+
+// Providers returns the names of the configured providers, sorted.
+func (c *Core) Providers() []string {
+	names := make([]string, 0, len(c.providers))
+	for name := range c.providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// Begin starts a sign in with the provider and returns the URL to send the
+// user to.
+func (c *Core) Begin(ctx context.Context, providerName string) (string, error) {
+	p, exists := c.providers[providerName]
+	if !exists {
+		return "", fmt.Errorf("begin: provider[%s]: %w", providerName, ErrUnknownProvider)
+	}
+
+	state, err := oidc.RandomString()
+	if err != nil {
+		return "", fmt.Errorf("begin: %w", err)
+	}
+	nonce, err := oidc.RandomString()
+	if err != nil {
+		return "", fmt.Errorf("begin: %w", err)
+	}
+	verifier, err := oidc.RandomString()
+	if err != nil {
+		return "", fmt.Errorf("begin: %w", err)
+	}
+
+	now := time.Now()
+
+	// Sign ins that were never completed are cleared out as new ones start.
+	if err := c.storer.DeleteExpiredLogins(ctx, now); err != nil {
+		c.log.Errorw("identity", "status", "deleting expired logins", "ERROR", err)
+	}
+
+	lgn := Login{
+		StateHash:   hashState(state),
+		Provider:    providerName,
+		Verifier:    verifier,
+		Nonce:       nonce,
+		DateExpires: now.Add(LoginTTL),
+		DateCreated: now,
+	}
+
+	if err := c.storer.CreateLogin(ctx, lgn); err != nil {
+		return "", fmt.Errorf("begin: createlogin: %w", err)
+	}
+
+	authURL, err := p.AuthCodeURL(ctx, state, nonce, verifier)
+	if err != nil {
+		return "", fmt.Errorf("begin: provider[%s]: %w", providerName, err)
+	}
+
+	return authURL, nil
+}
+
+// Complete finishes a sign in with the provider using the state and code the
+// provider sent the user back with, and returns the user who signed in.
+func (c *Core) Complete(ctx context.Context, providerName string, state string, code string) (user.User, error) {
+	p, exists := c.providers[providerName]
+	if !exists {
+		return user.User{}, fmt.Errorf("complete: provider[%s]: %w", providerName, ErrUnknownProvider)
+	}
+
+	lgn, err := c.storer.ConsumeLogin(ctx, hashState(state), providerName, time.Now())
+	if err != nil {
+		return user.User{}, fmt.Errorf("complete: %w", err)
+	}
+
+	rawIDToken, err := p.Exchange(ctx, code, lgn.Verifier)
+	if err != nil {
+		return user.User{}, fmt.Errorf("complete: provider[%s]: %w: %w", providerName, ErrSignInFailed, err)
+	}
+
+	idt, err := p.Verify(ctx, rawIDToken, lgn.Nonce)
+	if err != nil {
+		return user.User{}, fmt.Errorf("complete: provider[%s]: %w: %w", providerName, ErrSignInFailed, err)
+	}
+
+	usr, err := c.resolve(ctx, providerName, idt)
+	if err != nil {
+		return user.User{}, fmt.Errorf("complete: provider[%s]: %w", providerName, err)
+	}
+
+	if !usr.Enabled {
+		return user.User{}, fmt.Errorf("complete: userID[%s]: %w", usr.ID, ErrUserDisabled)
+	}
+
+	return usr, nil
+}
+
+// =============================================================================
+
+// resolve finds the user the provider's account is linked to, linking it the
+// first time.
+func (c *Core) resolve(ctx context.Context, providerName string, idt oidc.IDToken) (user.User, error) {
+	idn, err := c.storer.QueryBySubject(ctx, providerName, idt.Subject)
+	switch {
+	case err == nil:
+		usr, err := c.user.QueryByID(ctx, idn.UserID)
+		if err != nil {
+			return user.User{}, fmt.Errorf("query: userID[%s]: %w", idn.UserID, err)
+		}
+		return usr, nil
+
+	case !errors.Is(err, ErrNotFound):
+		return user.User{}, fmt.Errorf("querybysubject: %w", err)
+	}
+
+	// Linking goes by email address, so it must belong to whoever signed in.
+	if !idt.EmailVerified || idt.Email == "" {
+		return user.User{}, ErrEmailNotVerified
+	}
+
+	addr, err := mail.ParseAddress(idt.Email)
+	if err != nil {
+		return user.User{}, fmt.Errorf("parsing email: %w", err)
+	}
+
+	usr, err := c.user.QueryByEmail(ctx, *addr)
+	switch {
+	case err == nil:
+		usr, err = c.claim(ctx, usr)
+		if err != nil {
+			return user.User{}, err
+		}
+
+	case errors.Is(err, user.ErrNotFound):
+		usr, err = c.createUser(ctx, *addr, idt.Name)
+		if err != nil {
+			return user.User{}, err
+		}
+
+	default:
+		return user.User{}, fmt.Errorf("querybyemail: %w", err)
+	}
+
+	idn = Identity{
+		Provider:    providerName,
+		Subject:     idt.Subject,
+		UserID:      usr.ID,
+		Email:       *addr,
+		DateCreated: time.Now(),
+	}
+
+	if err := c.storer.Create(ctx, idn); err != nil {
+		return user.User{}, fmt.Errorf("create: %w", err)
+	}
+
+	return usr, nil
+}
+
+// claim prepares an existing account to be linked to the provider. An
+// account whose email was never verified could have been registered by
+// someone else; its password is replaced and its sessions are ended, so only
+// the owner of the address keeps access.
+func (c *Core) claim(ctx context.Context, usr user.User) (user.User, error) {
+	if usr.Verified() || !usr.Enabled {
+		return usr, nil
+	}
+
+	password, err := oidc.RandomString()
+	if err != nil {
+		return user.User{}, err
+	}
+
+	usr, err = c.user.Update(ctx, usr, user.UpdateUser{Password: &password})
+	if err != nil {
+		return user.User{}, fmt.Errorf("update: userID[%s]: %w", usr.ID, err)
+	}
+
+	usr, err = c.user.MarkVerified(ctx, usr)
+	if err != nil {
+		return user.User{}, fmt.Errorf("markverified: userID[%s]: %w", usr.ID, err)
+	}
+
+	for _, revoker := range c.revokers {
+		if err := revoker.RevokeUser(ctx, usr.ID); err != nil {
+			return user.User{}, fmt.Errorf("revokeuser: userID[%s]: %w", usr.ID, err)
+		}
+	}
+
+	return usr, nil
+}
+
+// createUser creates the account of someone signing in with a provider for
+// the first time. It has a random password; one can be set by resetting it.
+func (c *Core) createUser(ctx context.Context, addr mail.Address, name string) (user.User, error) {
+	if name = strings.TrimSpace(name); name == "" {
+		name, _, _ = strings.Cut(addr.Address, "@")
+	}
+
+	password, err := oidc.RandomString()
+	if err != nil {
+		return user.User{}, err
+	}
+
+	nu := user.NewUser{
+		Name:            name,
+		Email:           addr,
+		Roles:           []user.Role{user.RoleUser},
+		Password:        password,
+		PasswordConfirm: password,
+	}
+
+	usr, err := c.user.Create(ctx, nu)
+	if err != nil {
+		return user.User{}, fmt.Errorf("create: %w", err)
+	}
+
+	// The provider has verified the address.
+	usr, err = c.user.MarkVerified(ctx, usr)
+	if err != nil {
+		return user.User{}, fmt.Errorf("markverified: userID[%s]: %w", usr.ID, err)
+	}
+
+	return usr, nil
+}
+
+func hashState(state string) []byte {
+	sum := sha256.Sum256([]byte(state))
+	return sum[:]
+}
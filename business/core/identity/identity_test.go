@@ -0,0 +1,233 @@
+package identity_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/mail"
+	"os"
+	"testing"
+
+	"github.com/dmanias/startupers/business/core/identity"
+	"github.com/dmanias/startupers/business/core/identity/stores/identitydb"
+	"github.com/dmanias/startupers/business/core/user"
+	"github.com/dmanias/startupers/business/data/dbtest"
+	"github.com/dmanias/startupers/foundation/docker"
+	"github.com/dmanias/startupers/foundation/oidc"
+	"github.com/dmanias/startupers/foundation/oidc/oidctest"
+)
+
+var c *docker.Container
+
+func TestMain(m *testing.M) {
+	var err error
+	c, err = dbtest.StartDB()
+	if err != nil {
+		fmt.Println(err)
+	}
+
+	code := m.Run()
+
+	if c != nil {
+		dbtest.StopDB(c)
+	}
+
+	os.Exit(code)
+}
+
+func newIdentity(t *testing.T) (*dbtest.Test, *oidctest.Issuer, *identity.Core) {
+	t.Helper()
+
+	if c == nil {
+		t.Skip("Skipping, the database container could not be started")
+	}
+
+	test := dbtest.NewTest(t, c)
+	t.Cleanup(test.Teardown)
+
+	iss := oidctest.NewIssuer(t)
+	p := oidc.NewProvider(iss.Config("test"), nil)
+	core := identity.NewCore(test.Log, identitydb.NewStore(test.Log, test.DB), test.CoreAPIs.User, p)
+
+	return test, iss, core
+}
+
+// signIn signs in with the issuer, which asserts the claims.
+func signIn(t *testing.T, iss *oidctest.Issuer, core *identity.Core, claims oidctest.Claims) (user.User, error) {
+	t.Helper()
+
+	ctx := context.Background()
+
+	authURL, err := core.Begin(ctx, "test")
+	if err != nil {
+		t.Fatalf("Should be able to begin the sign in: %s", err)
+	}
+
+	state, code := iss.Authorize(t, authURL, claims)
+
+	return core.Complete(ctx, "test", state, code)
+}
+
+func createUser(t *testing.T, test *dbtest.Test, email string, password string) user.User {
+	t.Helper()
+
+	addr, err := mail.ParseAddress(email)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	usr, err := test.CoreAPIs.User.Create(context.Background(), user.NewUser{
+		Name:            "Jane",
+		Email:           *addr,
+		Roles:           []user.Role{user.RoleUser},
+		Password:        password,
+		PasswordConfirm: password,
+	})
+	if err != nil {
+		t.Fatalf("Should be able to create the user: %s", err)
+	}
+
+	return usr
+}
+
+func Test_LinkExistingAccount(t *testing.T) {
+	test, iss, core := newIdentity(t)
+	ctx := context.Background()
+
+	existing := createUser(t, test, "jane@example.com", "gophers")
+	existing, err := test.CoreAPIs.User.MarkVerified(ctx, existing)
+	if err != nil {
+		t.Fatalf("Should be able to verify the user: %s", err)
+	}
+
+	usr, err := signIn(t, iss, core, oidctest.Claims{
+		Subject:       "1234",
+		Email:         "jane@example.com",
+		EmailVerified: true,
+	})
+	if err != nil {
+		t.Fatalf("Should be able to sign in: %s", err)
+	}
+
+	if usr.ID != existing.ID {
+		t.Fatalf("Should link the provider to the user with the email, got userID %s, want %s", usr.ID, existing.ID)
+	}
+
+	// A verified account keeps its password.
+	if _, err := test.CoreAPIs.User.Authenticate(ctx, existing.Email, "gophers"); err != nil {
+		t.Fatalf("Should still sign in with the password: %s", err)
+	}
+
+	// Later sign ins find the user by the provider's subject, even when the
+	// email address at the provider has changed.
+	usr, err = signIn(t, iss, core, oidctest.Claims{
+		Subject:       "1234",
+		Email:         "jane.doe@example.com",
+		EmailVerified: true,
+	})
+	if err != nil {
+		t.Fatalf("Should be able to sign in again: %s", err)
+	}
+
+	if usr.ID != existing.ID {
+		t.Fatalf("Should sign in as the linked user, got userID %s, want %s", usr.ID, existing.ID)
+	}
+}
+
+func Test_LinkUnverifiedAccount(t *testing.T) {
+	test, iss, core := newIdentity(t)
+	ctx := context.Background()
+
+	// Someone registered the address without proving they own it.
+	existing := createUser(t, test, "jane@example.com", "squatter")
+
+	usr, err := signIn(t, iss, core, oidctest.Claims{
+		Subject:       "1234",
+		Email:         "jane@example.com",
+		EmailVerified: true,
+	})
+	if err != nil {
+		t.Fatalf("Should be able to sign in: %s", err)
+	}
+
+	if usr.ID != existing.ID {
+		t.Fatalf("Should link the provider to the user with the email, got userID %s, want %s", usr.ID, existing.ID)
+	}
+	if !usr.Verified() {
+		t.Fatalf("Should mark the email address as verified")
+	}
+
+	if _, err := test.CoreAPIs.User.Authenticate(ctx, existing.Email, "squatter"); !errors.Is(err, user.ErrAuthenticationFailure) {
+		t.Fatalf("Should no longer sign in with the password set before the link, got %v", err)
+	}
+}
+
+func Test_CreateAccount(t *testing.T) {
+	test, iss, core := newIdentity(t)
+
+	usr, err := signIn(t, iss, core, oidctest.Claims{
+		Subject:       "1234",
+		Email:         "jane@example.com",
+		EmailVerified: "true",
+		Name:          "Jane Doe",
+	})
+	if err != nil {
+		t.Fatalf("Should be able to sign in: %s", err)
+	}
+
+	if usr.Name != "Jane Doe" || usr.Email.Address != "jane@example.com" || !usr.Verified() {
+		t.Fatalf("Should create a verified user from the claims, got %+v", usr)
+	}
+
+	if _, err := test.CoreAPIs.User.QueryByID(context.Background(), usr.ID); err != nil {
+		t.Fatalf("Should be able to find the user: %s", err)
+	}
+}
+
+func Test_EmailNotVerified(t *testing.T) {
+	test, iss, core := newIdentity(t)
+	ctx := context.Background()
+
+	existing := createUser(t, test, "jane@example.com", "gophers")
+
+	for _, verified := range []any{false, "false", nil} {
+		_, err := signIn(t, iss, core, oidctest.Claims{
+			Subject:       "1234",
+			Email:         "jane@example.com",
+			EmailVerified: verified,
+		})
+		if !errors.Is(err, identity.ErrEmailNotVerified) {
+			t.Fatalf("Should not link an unverified email %v, got %v", verified, err)
+		}
+	}
+
+	usr, err := test.CoreAPIs.User.QueryByID(ctx, existing.ID)
+	if err != nil {
+		t.Fatalf("Should be able to find the user: %s", err)
+	}
+	if usr.Verified() {
+		t.Fatalf("Should leave the account unverified")
+	}
+}
+
+func Test_SignInRejected(t *testing.T) {
+	tt := []struct {
+		name   string
+		claims oidctest.Claims
+	}{
+		{name: "audience", claims: oidctest.Claims{Subject: "1234", Email: "jane@example.com", EmailVerified: true, Audience: "someone-else"}},
+		{name: "issuer", claims: oidctest.Claims{Subject: "1234", Email: "jane@example.com", EmailVerified: true, Issuer: "https://issuer.example.com"}},
+		{name: "nonce", claims: oidctest.Claims{Subject: "1234", Email: "jane@example.com", EmailVerified: true, Nonce: "another sign in"}},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			_, iss, core := newIdentity(t)
+
+			_, err := signIn(t, iss, core, tc.claims)
+			if !errors.Is(err, identity.ErrSignInFailed) {
+				t.Fatalf("Should reject the sign in, got %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,28 @@
+package identity
+
+import (
+	"net/mail"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Identity links an account at an OpenID Connect provider to a user.
+type Identity struct {
+	Provider    string
+	Subject     string
+	UserID      uuid.UUID
+	Email       mail.Address
+	DateCreated time.Time
+}
+
+// Login is a sign in with a provider that has been started but not completed.
+// It is found again by the hash of the state sent to the provider.
+type Login struct {
+	StateHash   []byte
+	Provider    string
+	Verifier    string
+	Nonce       string
+	DateExpires time.Time
+	DateCreated time.Time
+}
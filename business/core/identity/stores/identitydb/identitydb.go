@@ -0,0 +1,145 @@
+// Package identitydb contains identity related CRUD functionality.
+package identitydb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/dmanias/startupers/business/core/identity"
+	database "github.com/dmanias/startupers/business/sys/database/pgx"
+	"github.com/jmoiron/sqlx"
+	"go.uber.org/zap"
+)
+
+// Store manages the set of APIs for identity database access.
+type Store struct {
+	log *zap.SugaredLogger
+	db  sqlx.ExtContext
+}
+
+// NewStore constructs the api for data access.
+func NewStore(log *zap.SugaredLogger, db *sqlx.DB) *Store {
+	return &Store{
+		log: log,
+		db:  db,
+	}
+}
+
+// Create inserts a new identity into the database.
+func (s *Store) Create(ctx context.Context, idn identity.Identity) error {
+	const q = `
+	INSERT INTO user_identities
+		(provider, subject, user_id, email, date_created)
+	VALUES
+		(:provider, :subject, :user_id, :email, :date_created)`
+
+	if err := database.NamedExecContext(ctx, s.log, s.db, q, toDBIdentity(idn)); err != nil {
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	return nil
+}
+
+// QueryBySubject gets the identity of the provider's account from the
+// database.
+func (s *Store) QueryBySubject(ctx context.Context, provider string, subject string) (identity.Identity, error) {
+	data := struct {
+		Provider string `db:"provider"`
+		Subject  string `db:"subject"`
+	}{
+		Provider: provider,
+		Subject:  subject,
+	}
+
+	const q = `
+	SELECT
+		*
+	FROM
+		user_identities
+	WHERE
+		provider = :provider AND
+		subject = :subject`
+
+	var dbIdn dbIdentity
+	if err := database.NamedQueryStruct(ctx, s.log, s.db, q, data, &dbIdn); err != nil {
+		if errors.Is(err, database.ErrDBNotFound) {
+			return identity.Identity{}, fmt.Errorf("namedquerystruct: %w", identity.ErrNotFound)
+		}
+		return identity.Identity{}, fmt.Errorf("namedquerystruct: %w", err)
+	}
+
+	return toCoreIdentity(dbIdn), nil
+}
+
+// CreateLogin inserts a new sign in into the database.
+func (s *Store) CreateLogin(ctx context.Context, lgn identity.Login) error {
+	const q = `
+	INSERT INTO oidc_logins
+		(state_hash, provider, verifier, nonce, date_expires, date_created)
+	VALUES
+		(:state_hash, :provider, :verifier, :nonce, :date_expires, :date_created)`
+
+	if err := database.NamedExecContext(ctx, s.log, s.db, q, toDBLogin(lgn)); err != nil {
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	return nil
+}
+
+// ConsumeLogin removes the unexpired sign in with the state hash and returns
+// it. Removing and checking happen in one statement, so a sign in can't be
+// completed twice.
+func (s *Store) ConsumeLogin(ctx context.Context, stateHash []byte, provider string, now time.Time) (identity.Login, error) {
+	data := struct {
+		StateHash []byte    `db:"state_hash"`
+		Provider  string    `db:"provider"`
+		Now       time.Time `db:"now"`
+	}{
+		StateHash: stateHash,
+		Provider:  provider,
+		Now:       now.UTC(),
+	}
+
+	const q = `
+	DELETE FROM
+		oidc_logins
+	WHERE
+		state_hash = :state_hash AND
+		provider = :provider AND
+		date_expires > :now
+	RETURNING
+		*`
+
+	var dbLgn dbLogin
+	if err := database.NamedQueryStruct(ctx, s.log, s.db, q, data, &dbLgn); err != nil {
+		if errors.Is(err, database.ErrDBNotFound) {
+			return identity.Login{}, fmt.Errorf("namedquerystruct: %w", identity.ErrInvalidState)
+		}
+		return identity.Login{}, fmt.Errorf("namedquerystruct: %w", err)
+	}
+
+	return toCoreLogin(dbLgn), nil
+}
+
+// DeleteExpiredLogins removes the sign ins that can no longer be completed.
+func (s *Store) DeleteExpiredLogins(ctx context.Context, now time.Time) error {
+	data := struct {
+		Now time.Time `db:"now"`
+	}{
+		Now: now.UTC(),
+	}
+
+	const q = `
+	DELETE FROM
+		oidc_logins
+	WHERE
+		date_expires <= :now`
+
+	if err := database.NamedExecContext(ctx, s.log, s.db, q, data); err != nil {
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,74 @@
+package identitydb
+
+import (
+	"net/mail"
+	"time"
+
+	"github.com/dmanias/startupers/business/core/identity"
+	"github.com/google/uuid"
+)
+
+// dbIdentity represent the structure we need for moving data
+// between the app and the database.
+type dbIdentity struct {
+	Provider    string    `db:"provider"`
+	Subject     string    `db:"subject"`
+	UserID      uuid.UUID `db:"user_id"`
+	Email       string    `db:"email"`
+	DateCreated time.Time `db:"date_created"`
+}
+
+func toDBIdentity(idn identity.Identity) dbIdentity {
+	return dbIdentity{
+		Provider:    idn.Provider,
+		Subject:     idn.Subject,
+		UserID:      idn.UserID,
+		Email:       idn.Email.Address,
+		DateCreated: idn.DateCreated.UTC(),
+	}
+}
+
+func toCoreIdentity(dbIdn dbIdentity) identity.Identity {
+	return identity.Identity{
+		Provider:    dbIdn.Provider,
+		Subject:     dbIdn.Subject,
+		UserID:      dbIdn.UserID,
+		Email:       mail.Address{Address: dbIdn.Email},
+		DateCreated: dbIdn.DateCreated.In(time.Local),
+	}
+}
+
+// =============================================================================
+
+// dbLogin represent the structure we need for moving data
+// between the app and the database.
+type dbLogin struct {
+	StateHash   []byte    `db:"state_hash"`
+	Provider    string    `db:"provider"`
+	Verifier    string    `db:"verifier"`
+	Nonce       string    `db:"nonce"`
+	DateExpires time.Time `db:"date_expires"`
+	DateCreated time.Time `db:"date_created"`
+}
+
+func toDBLogin(lgn identity.Login) dbLogin {
+	return dbLogin{
+		StateHash:   lgn.StateHash,
+		Provider:    lgn.Provider,
+		Verifier:    lgn.Verifier,
+		Nonce:       lgn.Nonce,
+		DateExpires: lgn.DateExpires.UTC(),
+		DateCreated: lgn.DateCreated.UTC(),
+	}
+}
+
+func toCoreLogin(dbLgn dbLogin) identity.Login {
+	return identity.Login{
+		StateHash:   dbLgn.StateHash,
+		Provider:    dbLgn.Provider,
+		Verifier:    dbLgn.Verifier,
+		Nonce:       dbLgn.Nonce,
+		DateExpires: dbLgn.DateExpires.In(time.Local),
+		DateCreated: dbLgn.DateCreated.In(time.Local),
+	}
+}
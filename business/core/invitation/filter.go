@@ -0,0 +1,62 @@
+package invitation
+
+import (
+	"fmt"
+	"net/mail"
+	"time"
+
+	"github.com/dmanias/startupers/business/sys/validate"
+	"github.com/google/uuid"
+)
+
+// QueryFilter holds the available fields a query can be filtered on.
+type QueryFilter struct {
+	ID             *uuid.UUID    `validate:"omitempty"`
+	IdeaID         *uuid.UUID    `validate:"omitempty"`
+	InviteeID      *uuid.UUID    `validate:"omitempty"`
+	Status         *Status       `validate:"omitempty"`
+	RecipientID    *uuid.UUID    `validate:"omitempty"`
+	RecipientEmail *mail.Address `validate:"omitempty"`
+	ExpiresAfter   *time.Time    `validate:"omitempty"`
+}
+
+// Validate checks the data in the model is considered clean.
+func (qf *QueryFilter) Validate() error {
+	if err := validate.Check(qf); err != nil {
+		return fmt.Errorf("validate: %w", err)
+	}
+	return nil
+}
+
+// WithInvitationID sets the ID field of the QueryFilter value.
+func (qf *QueryFilter) WithInvitationID(invitationID uuid.UUID) {
+	qf.ID = &invitationID
+}
+
+// WithIdeaID sets the IdeaID field of the QueryFilter value.
+func (qf *QueryFilter) WithIdeaID(ideaID uuid.UUID) {
+	qf.IdeaID = &ideaID
+}
+
+// WithInviteeID sets the InviteeID field of the QueryFilter value.
+func (qf *QueryFilter) WithInviteeID(inviteeID uuid.UUID) {
+	qf.InviteeID = &inviteeID
+}
+
+// WithStatus sets the Status field of the QueryFilter value.
+func (qf *QueryFilter) WithStatus(status Status) {
+	qf.Status = &status
+}
+
+// WithRecipient restricts the result to the invitations addressed to the
+// user, either by ID or by an email address without an account attached.
+func (qf *QueryFilter) WithRecipient(userID uuid.UUID, email mail.Address) {
+	qf.RecipientID = &userID
+	qf.RecipientEmail = &email
+}
+
+// WithExpiresAfter sets the ExpiresAfter field of the QueryFilter value.
+func (qf *QueryFilter) WithExpiresAfter(t time.Time) {
+	d := t.UTC()
+	qf.ExpiresAfter = &d
+}
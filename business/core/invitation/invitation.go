@@ -0,0 +1,404 @@
+// Package invitation provides the business API for inviting users to
+// collaborate on an idea. A user only becomes a collaborator once they
+// accept an invitation addressed to them.
+package invitation
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/mail"
+	"net/url"
+	"time"
+
+	"github.com/dmanias/startupers/business/core/activity"
+	"github.com/dmanias/startupers/business/core/email"
+	"github.com/dmanias/startupers/business/core/idea"
+	"github.com/dmanias/startupers/business/core/user"
+	"github.com/dmanias/startupers/business/data/order"
+	"github.com/dmanias/startupers/business/data/transaction"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// TTL is how long an invitation can be answered after it was sent.
+const TTL = 7 * 24 * time.Hour
+
+// Set of error variables for CRUD operations.
+var (
+	ErrNotFound            = errors.New("invitation not found")
+	ErrDuplicate           = errors.New("a pending invitation already exists for this user")
+	ErrNotPending          = errors.New("invitation is no longer pending")
+	ErrExpired             = errors.New("invitation has expired")
+	ErrSelfInvite          = errors.New("the owner of an idea can't be invited to it")
+	ErrAlreadyCollaborator = errors.New("user is already a collaborator")
+)
+
+// Storer interface declares the behavior this package needs to perists and
+// retrieve data.
+type Storer interface {
+	ExecuteUnderTransaction(tx transaction.Transaction) (Storer, error)
+	Create(ctx context.Context, inv Invitation) error
+	Transition(ctx context.Context, inv Invitation, from Status) error
+	RevokeExpired(ctx context.Context, inv Invitation) error
+	Query(ctx context.Context, filter QueryFilter, orderBy order.By, pageNumber int, rowsPerPage int) ([]Invitation, error)
+	Count(ctx context.Context, filter QueryFilter) (int, error)
+	QueryByID(ctx context.Context, invitationID uuid.UUID) (Invitation, error)
+	QueryByTokenHash(ctx context.Context, tokenHash []byte) (Invitation, error)
+}
+
+// Core manages the set of APIs for invitation access.
+type Core struct {
+	log      *zap.SugaredLogger
+	beginner transaction.Beginner
+	storer   Storer
+	idea     *idea.Core
+	user     *user.Core
+	email    *email.Core
+	appURL   string
+	activity []ActivityRecorder
+}
+
+// NewCore constructs a core for invitation api access. Invitees are emailed a
+// link to accept the invitation in the web application at appURL.
+func NewCore(log *zap.SugaredLogger, beginner transaction.Beginner, storer Storer, ideaCore *idea.Core, userCore *user.Core, emailCore *email.Core, appURL string) *Core {
+	return &Core{
+		log:      log,
+		beginner: beginner,
+		storer:   storer,
+		idea:     ideaCore,
+		user:     userCore,
+		email:    emailCore,
+		appURL:   appURL,
+	}
+}
+
+// Create invites a user to collaborate on the idea. The invitee is emailed a
+// link with a token that lets them accept; the email is the only copy of the
+// token, just its hash is stored. The invitation and the email are written in
+// a single transaction. A pending invitation for the same invitee that has
+// expired is revoked first, so it doesn't block inviting them again.
+func (c *Core) Create(ctx context.Context, idr idea.Idea, ni NewInvitation) (Invitation, error) {
+	var inviteeID uuid.UUID
+	var addr mail.Address
+
+	// The invitee is addressed by the name and language of their account,
+	// when they have one.
+	var to mail.Address
+	var locale string
+
+	switch {
+	case ni.InviteeID != nil:
+		usr, err := c.user.QueryByID(ctx, *ni.InviteeID)
+		if err != nil {
+			if errors.Is(err, user.ErrNotFound) {
+				return Invitation{}, fmt.Errorf("create: inviteeID[%s]: %w", *ni.InviteeID, err)
+			}
+			return Invitation{}, fmt.Errorf("create: %w", err)
+		}
+		inviteeID = usr.ID
+		to = mail.Address{Name: usr.Name, Address: usr.Email.Address}
+		locale = usr.Locale
+
+	case ni.Email != nil:
+		addr = *ni.Email
+		to = addr
+
+		usr, err := c.user.QueryByEmail(ctx, addr)
+		switch {
+		case err == nil:
+			inviteeID = usr.ID
+			to = mail.Address{Name: usr.Name, Address: usr.Email.Address}
+			locale = usr.Locale
+		case !errors.Is(err, user.ErrNotFound):
+			return Invitation{}, fmt.Errorf("create: %w", err)
+		}
+
+	default:
+		return Invitation{}, errors.New("create: an invitee ID or email is required")
+	}
+
+	if inviteeID == idr.UserID {
+		return Invitation{}, ErrSelfInvite
+	}
+	if inviteeID != uuid.Nil && idr.IsCollaborator(inviteeID) {
+		return Invitation{}, ErrAlreadyCollaborator
+	}
+
+	token, tokenHash, err := newToken()
+	if err != nil {
+		return Invitation{}, fmt.Errorf("create: %w", err)
+	}
+
+	now := time.Now()
+
+	inv := Invitation{
+		ID:          uuid.New(),
+		IdeaID:      idr.ID,
+		InviterID:   ni.InviterID,
+		InviteeID:   inviteeID,
+		Email:       addr,
+		Permission:  ni.Permission,
+		Status:      StatusPending,
+		TokenHash:   tokenHash,
+		DateExpires: now.Add(TTL),
+		DateCreated: now,
+		DateUpdated: now,
+	}
+
+	ne := email.NewEmail{
+		To:       to,
+		Template: "invitation_received",
+		Locale:   locale,
+		Data: struct {
+			Name      string
+			IdeaTitle string
+			Link      string
+		}{
+			Name:      to.Name,
+			IdeaTitle: idr.Title,
+			Link:      c.appURL + "/invitations/accept?" + url.Values{"token": {token}}.Encode(),
+		},
+	}
+
+	f := func(tx transaction.Transaction) error {
+		storer, err := c.storer.ExecuteUnderTransaction(tx)
+		if err != nil {
+			return err
+		}
+
+		emailCore, err := c.email.ExecuteUnderTransaction(tx)
+		if err != nil {
+			return err
+		}
+
+		if err := storer.RevokeExpired(ctx, inv); err != nil {
+			return fmt.Errorf("revokeexpired: %w", err)
+		}
+
+		if err := storer.Create(ctx, inv); err != nil {
+			return fmt.Errorf("create: %w", err)
+		}
+
+		if _, err := emailCore.Enqueue(ctx, ne); err != nil {
+			return fmt.Errorf("enqueue: %w", err)
+		}
+
+		return nil
+	}
+
+	if err := transaction.ExecuteUnderTransaction(ctx, c.log, c.beginner, f); err != nil {
+		return Invitation{}, fmt.Errorf("create: %w", err)
+	}
+
+	// Only invitees that already have an account can be notified.
+	if inv.InviteeID != uuid.Nil {
+		c.record(ctx, activity.NewActivity{
+			Type:      activity.TypeInvitationCreated,
+			ActorID:   inv.InviterID,
+			IdeaID:    inv.IdeaID,
+			SubjectID: inv.ID,
+			Details:   map[string]string{"inviteeID": inv.InviteeID.String()},
+		})
+	}
+
+	return inv, nil
+}
+
+// Accept records the user's consent and adds them to the idea's
+// collaborators. Both changes are made in a single transaction.
+func (c *Core) Accept(ctx context.Context, inv Invitation, usr user.User) (Invitation, error) {
+	if !inv.AddressedTo(usr.ID, usr.Email) {
+		return Invitation{}, ErrNotFound
+	}
+
+	return c.accept(ctx, inv, usr.ID)
+}
+
+// AcceptToken accepts the invitation identified by the token sent to the
+// invitee. Whoever holds the token may claim an invitation that was sent to an
+// email address, as long as no other account has been attached to it.
+func (c *Core) AcceptToken(ctx context.Context, token string, usr user.User) (Invitation, error) {
+	inv, err := c.storer.QueryByTokenHash(ctx, hashToken(token))
+	if err != nil {
+		return Invitation{}, fmt.Errorf("query: %w", err)
+	}
+
+	if inv.InviteeID != uuid.Nil && inv.InviteeID != usr.ID {
+		return Invitation{}, ErrNotFound
+	}
+
+	return c.accept(ctx, inv, usr.ID)
+}
+
+func (c *Core) accept(ctx context.Context, inv Invitation, userID uuid.UUID) (Invitation, error) {
+	now := time.Now()
+
+	if inv.Status != StatusPending {
+		return Invitation{}, ErrNotPending
+	}
+	if inv.Expired(now) {
+		return Invitation{}, ErrExpired
+	}
+
+	inv.InviteeID = userID
+	inv.Status = StatusAccepted
+	inv.DateUpdated = now
+
+	f := func(tx transaction.Transaction) error {
+		storer, err := c.storer.ExecuteUnderTransaction(tx)
+		if err != nil {
+			return err
+		}
+
+		ideaCore, err := c.idea.ExecuteUnderTransaction(tx)
+		if err != nil {
+			return err
+		}
+
+		// The collaborator is only added if this request is the one that
+		// moved the invitation out of pending.
+		if err := storer.Transition(ctx, inv, StatusPending); err != nil {
+			return fmt.Errorf("transition: %w", err)
+		}
+
+		return ideaCore.AddCollaborator(ctx, inv.IdeaID, userID)
+	}
+
+	if err := transaction.ExecuteUnderTransaction(ctx, c.log, c.beginner, f); err != nil {
+		return Invitation{}, fmt.Errorf("accept: invitationID[%s]: %w", inv.ID, err)
+	}
+
+	return inv, nil
+}
+
+// Decline records that the user turned the invitation down.
+func (c *Core) Decline(ctx context.Context, inv Invitation, usr user.User) (Invitation, error) {
+	if !inv.AddressedTo(usr.ID, usr.Email) {
+		return Invitation{}, ErrNotFound
+	}
+
+	if inv.Status != StatusPending {
+		return Invitation{}, ErrNotPending
+	}
+
+	inv.Status = StatusDeclined
+	inv.DateUpdated = time.Now()
+
+	if err := c.storer.Transition(ctx, inv, StatusPending); err != nil {
+		return Invitation{}, fmt.Errorf("transition: %w", err)
+	}
+
+	return inv, nil
+}
+
+// Revoke withdraws the invitation. Revoking an accepted invitation also removes
+// the invitee from the idea's collaborators in the same transaction.
+func (c *Core) Revoke(ctx context.Context, inv Invitation) (Invitation, error) {
+	if inv.Status != StatusPending && inv.Status != StatusAccepted {
+		return Invitation{}, ErrNotPending
+	}
+
+	from := inv.Status
+	wasAccepted := from == StatusAccepted
+
+	inv.Status = StatusRevoked
+	inv.DateUpdated = time.Now()
+
+	f := func(tx transaction.Transaction) error {
+		storer, err := c.storer.ExecuteUnderTransaction(tx)
+		if err != nil {
+			return err
+		}
+
+		ideaCore, err := c.idea.ExecuteUnderTransaction(tx)
+		if err != nil {
+			return err
+		}
+
+		// An invitation accepted or revoked since it was read is left alone,
+		// so the collaborators always match the accepted invitations.
+		if err := storer.Transition(ctx, inv, from); err != nil {
+			return fmt.Errorf("transition: %w", err)
+		}
+
+		if wasAccepted {
+			return ideaCore.RemoveCollaborator(ctx, inv.IdeaID, inv.InviteeID)
+		}
+
+		return nil
+	}
+
+	if err := transaction.ExecuteUnderTransaction(ctx, c.log, c.beginner, f); err != nil {
+		return Invitation{}, fmt.Errorf("revoke: invitationID[%s]: %w", inv.ID, err)
+	}
+
+	return inv, nil
+}
+
+// Query retrieves a list of existing invitations from the database.
+func (c *Core) Query(ctx context.Context, filter QueryFilter, orderBy order.By, pageNumber int, rowsPerPage int) ([]Invitation, error) {
+	invs, err := c.storer.Query(ctx, filter, orderBy, pageNumber, rowsPerPage)
+	if err != nil {
+		return nil, fmt.Errorf("query: %w", err)
+	}
+
+	return invs, nil
+}
+
+// Count returns the total number of invitations in the store.
+func (c *Core) Count(ctx context.Context, filter QueryFilter) (int, error) {
+	return c.storer.Count(ctx, filter)
+}
+
+// QueryByID gets the specified invitation from the database.
+func (c *Core) QueryByID(ctx context.Context, invitationID uuid.UUID) (Invitation, error) {
+	inv, err := c.storer.QueryByID(ctx, invitationID)
+	if err != nil {
+		return Invitation{}, fmt.Errorf("query: invitationID[%s]: %w", invitationID, err)
+	}
+
+	return inv, nil
+}
+
+// QueryPermission returns the permission the collaborator was granted on the
+// idea by the invitation they accepted.
+func (c *Core) QueryPermission(ctx context.Context, ideaID uuid.UUID, userID uuid.UUID) (Permission, error) {
+	var filter QueryFilter
+	filter.WithIdeaID(ideaID)
+	filter.WithInviteeID(userID)
+	filter.WithStatus(StatusAccepted)
+
+	invs, err := c.storer.Query(ctx, filter, DefaultOrderBy, 1, 1)
+	if err != nil {
+		return Permission{}, fmt.Errorf("query: ideaID[%s] userID[%s]: %w", ideaID, userID, err)
+	}
+
+	if len(invs) == 0 {
+		return Permission{}, ErrNotFound
+	}
+
+	return invs[0].Permission, nil
+}
+
+// =============================================================================
+
+// newToken generates a random invitation token and the hash stored for it.
+func newToken() (string, []byte, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", nil, fmt.Errorf("generating token: %w", err)
+	}
+
+	token := base64.RawURLEncoding.EncodeToString(b)
+
+	return token, hashToken(token), nil
+}
+
+func hashToken(token string) []byte {
+	sum := sha256.Sum256([]byte(token))
+	return sum[:]
+}
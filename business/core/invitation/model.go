@@ -0,0 +1,49 @@
+package invitation
+
+import (
+	"net/mail"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Invitation represents an offer to collaborate on an idea.
+type Invitation struct {
+	ID          uuid.UUID
+	IdeaID      uuid.UUID
+	InviterID   uuid.UUID
+	InviteeID   uuid.UUID
+	Email       mail.Address
+	Permission  Permission
+	Status      Status
+	TokenHash   []byte
+	DateExpires time.Time
+	DateCreated time.Time
+	DateUpdated time.Time
+}
+
+// Expired reports whether a pending invitation can no longer be answered.
+func (inv Invitation) Expired(now time.Time) bool {
+	return inv.Status == StatusPending && !now.Before(inv.DateExpires)
+}
+
+// AddressedTo reports whether the invitation was sent to the user. An
+// invitation sent to an email address without an account yet matches the
+// user owning that address.
+func (inv Invitation) AddressedTo(userID uuid.UUID, email mail.Address) bool {
+	if inv.InviteeID != uuid.Nil {
+		return inv.InviteeID == userID
+	}
+
+	return inv.Email.Address != "" && strings.EqualFold(inv.Email.Address, email.Address)
+}
+
+// NewInvitation contains information needed to invite a user to an idea. The
+// invitee is identified either by user ID or by email address.
+type NewInvitation struct {
+	InviterID  uuid.UUID
+	InviteeID  *uuid.UUID
+	Email      *mail.Address
+	Permission Permission
+}
@@ -0,0 +1,63 @@
+package invitation
+
+import "errors"
+
+// Set of possible permission levels for a collaborator.
+var (
+	PermissionViewer = Permission{"viewer"}
+	PermissionEditor = Permission{"editor"}
+)
+
+// Set of known permission levels.
+var permissions = map[string]Permission{
+	PermissionViewer.name: PermissionViewer,
+	PermissionEditor.name: PermissionEditor,
+}
+
+// Permission represents what a collaborator is allowed to do with an idea.
+type Permission struct {
+	name string
+}
+
+// ParsePermission parses the string value and returns a permission if one
+// exists.
+func ParsePermission(value string) (Permission, error) {
+	permission, exists := permissions[value]
+	if !exists {
+		return Permission{}, errors.New("invalid permission")
+	}
+
+	return permission, nil
+}
+
+// MustParsePermission parses the string value and returns a permission if one
+// exists. If an error occurs the function panics.
+func MustParsePermission(value string) Permission {
+	permission, err := ParsePermission(value)
+	if err != nil {
+		panic(err)
+	}
+
+	return permission
+}
+
+// Name returns the name of the permission.
+func (p Permission) Name() string {
+	return p.name
+}
+
+// UnmarshalText implement the unmarshal interface for JSON conversions.
+func (p *Permission) UnmarshalText(data []byte) error {
+	p.name = string(data)
+	return nil
+}
+
+// MarshalText implement the marshal interface for JSON conversions.
+func (p Permission) MarshalText() ([]byte, error) {
+	return []byte(p.name), nil
+}
+
+// Equal provides support for the go-cmp package and testing.
+func (p Permission) Equal(p2 Permission) bool {
+	return p.name == p2.name
+}
@@ -0,0 +1,66 @@
+package invitation
+
+import "errors"
+
+// Set of possible states for an invitation.
+var (
+	StatusPending  = Status{"pending"}
+	StatusAccepted = Status{"accepted"}
+	StatusDeclined = Status{"declined"}
+	StatusRevoked  = Status{"revoked"}
+)
+
+// Set of known states.
+var statuses = map[string]Status{
+	StatusPending.name:  StatusPending,
+	StatusAccepted.name: StatusAccepted,
+	StatusDeclined.name: StatusDeclined,
+	StatusRevoked.name:  StatusRevoked,
+}
+
+// Status represents the state of an invitation.
+type Status struct {
+	name string
+}
+
+// ParseStatus parses the string value and returns a status if one exists.
+func ParseStatus(value string) (Status, error) {
+	status, exists := statuses[value]
+	if !exists {
+		return Status{}, errors.New("invalid status")
+	}
+
+	return status, nil
+}
+
+// MustParseStatus parses the string value and returns a status if one exists.
+// If an error occurs the function panics.
+func MustParseStatus(value string) Status {
+	status, err := ParseStatus(value)
+	if err != nil {
+		panic(err)
+	}
+
+	return status
+}
+
+// Name returns the name of the status.
+func (s Status) Name() string {
+	return s.name
+}
+
+// UnmarshalText implement the unmarshal interface for JSON conversions.
+func (s *Status) UnmarshalText(data []byte) error {
+	s.name = string(data)
+	return nil
+}
+
+// MarshalText implement the marshal interface for JSON conversions.
+func (s Status) MarshalText() ([]byte, error) {
+	return []byte(s.name), nil
+}
+
+// Equal provides support for the go-cmp package and testing.
+func (s Status) Equal(s2 Status) bool {
+	return s.name == s2.name
+}
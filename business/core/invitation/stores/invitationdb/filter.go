@@ -0,0 +1,48 @@
+package invitationdb
+
+import (
+	"bytes"
+	"strings"
+
+	"github.com/dmanias/startupers/business/core/invitation"
+)
+
+func (s *Store) applyFilter(filter invitation.QueryFilter, data map[string]interface{}, buf *bytes.Buffer) {
+	var wc []string
+
+	if filter.ID != nil {
+		data["id"] = *filter.ID
+		wc = append(wc, "id = :id")
+	}
+
+	if filter.IdeaID != nil {
+		data["idea_id"] = *filter.IdeaID
+		wc = append(wc, "idea_id = :idea_id")
+	}
+
+	if filter.InviteeID != nil {
+		data["invitee_id"] = *filter.InviteeID
+		wc = append(wc, "invitee_id = :invitee_id")
+	}
+
+	if filter.Status != nil {
+		data["status"] = filter.Status.Name()
+		wc = append(wc, "status = :status")
+	}
+
+	if filter.RecipientID != nil && filter.RecipientEmail != nil {
+		data["recipient_id"] = *filter.RecipientID
+		data["recipient_email"] = filter.RecipientEmail.Address
+		wc = append(wc, "(invitee_id = :recipient_id OR (invitee_id IS NULL AND lower(email) = lower(:recipient_email)))")
+	}
+
+	if filter.ExpiresAfter != nil {
+		data["expires_after"] = *filter.ExpiresAfter
+		wc = append(wc, "date_expires > :expires_after")
+	}
+
+	if len(wc) > 0 {
+		buf.WriteString(" WHERE ")
+		buf.WriteString(strings.Join(wc, " AND "))
+	}
+}
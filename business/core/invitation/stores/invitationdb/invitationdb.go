@@ -0,0 +1,236 @@
+// Package invitationdb contains invitation related CRUD functionality.
+package invitationdb
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/dmanias/startupers/business/core/invitation"
+	"github.com/dmanias/startupers/business/data/order"
+	"github.com/dmanias/startupers/business/data/sqldb"
+	"github.com/dmanias/startupers/business/data/transaction"
+	database "github.com/dmanias/startupers/business/sys/database/pgx"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"go.uber.org/zap"
+)
+
+// Store manages the set of APIs for invitation database access.
+type Store struct {
+	log *zap.SugaredLogger
+	db  sqlx.ExtContext
+}
+
+// NewStore constructs the api for data access.
+func NewStore(log *zap.SugaredLogger, db *sqlx.DB) *Store {
+	return &Store{
+		log: log,
+		db:  db,
+	}
+}
+
+// ExecuteUnderTransaction constructs a new Store value replacing the sqlx DB
+// value with a sqlx DB value that is currently inside a transaction.
+func (s *Store) ExecuteUnderTransaction(tx transaction.Transaction) (invitation.Storer, error) {
+	ec, err := sqldb.GetExtContext(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	s = &Store{
+		log: s.log,
+		db:  ec,
+	}
+
+	return s, nil
+}
+
+// Create inserts a new invitation into the database.
+func (s *Store) Create(ctx context.Context, inv invitation.Invitation) error {
+	const q = `
+	INSERT INTO idea_invitations
+		(id, idea_id, inviter_id, invitee_id, email, permission, status, token_hash, date_expires, date_created, date_updated)
+	VALUES
+		(:id, :idea_id, :inviter_id, :invitee_id, :email, :permission, :status, :token_hash, :date_expires, :date_created, :date_updated)`
+
+	if err := database.NamedExecContext(ctx, s.log, s.db, q, toDBInvitation(inv)); err != nil {
+		if errors.Is(err, database.ErrDBDuplicatedEntry) {
+			return fmt.Errorf("namedexeccontext: %w", invitation.ErrDuplicate)
+		}
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	return nil
+}
+
+// Transition moves the invitation to its new status, as long as it still has
+// the status it is moved from. An invitation another request moved first is
+// reported as no longer pending.
+func (s *Store) Transition(ctx context.Context, inv invitation.Invitation, from invitation.Status) error {
+	data := struct {
+		dbInvitation
+		From string `db:"from_status"`
+	}{
+		dbInvitation: toDBInvitation(inv),
+		From:         from.Name(),
+	}
+
+	const q = `
+	UPDATE
+		idea_invitations
+	SET
+		"invitee_id" = :invitee_id,
+		"status" = :status,
+		"date_updated" = :date_updated
+	WHERE
+		id = :id AND
+		status = :from_status
+	RETURNING
+		id`
+
+	var updated struct {
+		ID uuid.UUID `db:"id"`
+	}
+	if err := database.NamedQueryStruct(ctx, s.log, s.db, q, data, &updated); err != nil {
+		if errors.Is(err, database.ErrDBNotFound) {
+			return fmt.Errorf("namedquerystruct: %w", invitation.ErrNotPending)
+		}
+		return fmt.Errorf("namedquerystruct: %w", err)
+	}
+
+	return nil
+}
+
+// RevokeExpired revokes the pending invitations to the idea that are
+// addressed to the same invitee or email as the specified one and had expired
+// by the time it was created.
+func (s *Store) RevokeExpired(ctx context.Context, inv invitation.Invitation) error {
+	const q = `
+	UPDATE
+		idea_invitations
+	SET
+		"status" = 'revoked',
+		"date_updated" = :date_created
+	WHERE
+		idea_id = :idea_id AND
+		status = 'pending' AND
+		date_expires <= :date_created AND
+		(invitee_id = :invitee_id OR lower(email) = lower(:email))`
+
+	if err := database.NamedExecContext(ctx, s.log, s.db, q, toDBInvitation(inv)); err != nil {
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	return nil
+}
+
+// Query retrieves a list of existing invitations from the database.
+func (s *Store) Query(ctx context.Context, filter invitation.QueryFilter, orderBy order.By, pageNumber int, rowsPerPage int) ([]invitation.Invitation, error) {
+	data := map[string]interface{}{
+		"offset":        (pageNumber - 1) * rowsPerPage,
+		"rows_per_page": rowsPerPage,
+	}
+
+	const q = `
+	SELECT
+		*
+	FROM
+		idea_invitations`
+
+	buf := bytes.NewBufferString(q)
+	s.applyFilter(filter, data, buf)
+
+	orderByClause, err := orderByClause(orderBy)
+	if err != nil {
+		return nil, err
+	}
+
+	buf.WriteString(orderByClause)
+	buf.WriteString(" OFFSET :offset ROWS FETCH NEXT :rows_per_page ROWS ONLY")
+
+	var dbInvs []dbInvitation
+	if err := database.NamedQuerySlice(ctx, s.log, s.db, buf.String(), data, &dbInvs); err != nil {
+		return nil, fmt.Errorf("namedqueryslice: %w", err)
+	}
+
+	return toCoreInvitationSlice(dbInvs), nil
+}
+
+// Count returns the total number of invitations in the DB.
+func (s *Store) Count(ctx context.Context, filter invitation.QueryFilter) (int, error) {
+	data := map[string]interface{}{}
+
+	const q = `
+	SELECT
+		count(1)
+	FROM
+		idea_invitations`
+
+	buf := bytes.NewBufferString(q)
+	s.applyFilter(filter, data, buf)
+
+	var count struct {
+		Count int `db:"count"`
+	}
+	if err := database.NamedQueryStruct(ctx, s.log, s.db, buf.String(), data, &count); err != nil {
+		return 0, fmt.Errorf("namedquerystruct: %w", err)
+	}
+
+	return count.Count, nil
+}
+
+// QueryByID gets the specified invitation from the database.
+func (s *Store) QueryByID(ctx context.Context, invitationID uuid.UUID) (invitation.Invitation, error) {
+	data := struct {
+		ID string `db:"id"`
+	}{
+		ID: invitationID.String(),
+	}
+
+	const q = `
+	SELECT
+		*
+	FROM
+		idea_invitations
+	WHERE
+		id = :id`
+
+	var dbInv dbInvitation
+	if err := database.NamedQueryStruct(ctx, s.log, s.db, q, data, &dbInv); err != nil {
+		if errors.Is(err, database.ErrDBNotFound) {
+			return invitation.Invitation{}, fmt.Errorf("namedquerystruct: %w", invitation.ErrNotFound)
+		}
+		return invitation.Invitation{}, fmt.Errorf("namedquerystruct: %w", err)
+	}
+
+	return toCoreInvitation(dbInv), nil
+}
+
+// QueryByTokenHash gets the invitation issued with the specified token.
+func (s *Store) QueryByTokenHash(ctx context.Context, tokenHash []byte) (invitation.Invitation, error) {
+	data := struct {
+		TokenHash []byte `db:"token_hash"`
+	}{
+		TokenHash: tokenHash,
+	}
+
+	const q = `
+	SELECT
+		*
+	FROM
+		idea_invitations
+	WHERE
+		token_hash = :token_hash`
+
+	var dbInv dbInvitation
+	if err := database.NamedQueryStruct(ctx, s.log, s.db, q, data, &dbInv); err != nil {
+		if errors.Is(err, database.ErrDBNotFound) {
+			return invitation.Invitation{}, fmt.Errorf("namedquerystruct: %w", invitation.ErrNotFound)
+		}
+		return invitation.Invitation{}, fmt.Errorf("namedquerystruct: %w", err)
+	}
+
+	return toCoreInvitation(dbInv), nil
+}
@@ -0,0 +1,72 @@
+package invitationdb
+
+import (
+	"database/sql"
+	"net/mail"
+	"time"
+
+	"github.com/dmanias/startupers/business/core/invitation"
+	"github.com/google/uuid"
+)
+
+// dbInvitation represent the structure we need for moving data
+// between the app and the database.
+type dbInvitation struct {
+	ID          uuid.UUID      `db:"id"`
+	IdeaID      uuid.UUID      `db:"idea_id"`
+	InviterID   uuid.UUID      `db:"inviter_id"`
+	InviteeID   uuid.NullUUID  `db:"invitee_id"`
+	Email       sql.NullString `db:"email"`
+	Permission  string         `db:"permission"`
+	Status      string         `db:"status"`
+	TokenHash   []byte         `db:"token_hash"`
+	DateExpires time.Time      `db:"date_expires"`
+	DateCreated time.Time      `db:"date_created"`
+	DateUpdated time.Time      `db:"date_updated"`
+}
+
+func toDBInvitation(inv invitation.Invitation) dbInvitation {
+	return dbInvitation{
+		ID:        inv.ID,
+		IdeaID:    inv.IdeaID,
+		InviterID: inv.InviterID,
+		InviteeID: uuid.NullUUID{
+			UUID:  inv.InviteeID,
+			Valid: inv.InviteeID != uuid.Nil,
+		},
+		Email: sql.NullString{
+			String: inv.Email.Address,
+			Valid:  inv.Email.Address != "",
+		},
+		Permission:  inv.Permission.Name(),
+		Status:      inv.Status.Name(),
+		TokenHash:   inv.TokenHash,
+		DateExpires: inv.DateExpires.UTC(),
+		DateCreated: inv.DateCreated.UTC(),
+		DateUpdated: inv.DateUpdated.UTC(),
+	}
+}
+
+func toCoreInvitation(dbInv dbInvitation) invitation.Invitation {
+	return invitation.Invitation{
+		ID:          dbInv.ID,
+		IdeaID:      dbInv.IdeaID,
+		InviterID:   dbInv.InviterID,
+		InviteeID:   dbInv.InviteeID.UUID,
+		Email:       mail.Address{Address: dbInv.Email.String},
+		Permission:  invitation.MustParsePermission(dbInv.Permission),
+		Status:      invitation.MustParseStatus(dbInv.Status),
+		TokenHash:   dbInv.TokenHash,
+		DateExpires: dbInv.DateExpires.In(time.Local),
+		DateCreated: dbInv.DateCreated.In(time.Local),
+		DateUpdated: dbInv.DateUpdated.In(time.Local),
+	}
+}
+
+func toCoreInvitationSlice(dbInvs []dbInvitation) []invitation.Invitation {
+	invs := make([]invitation.Invitation, len(dbInvs))
+	for i, dbInv := range dbInvs {
+		invs[i] = toCoreInvitation(dbInv)
+	}
+	return invs
+}
@@ -0,0 +1,25 @@
+package invitationdb
+
+import (
+	"fmt"
+
+	"github.com/dmanias/startupers/business/core/invitation"
+	"github.com/dmanias/startupers/business/data/order"
+)
+
+var orderByFields = map[string]string{
+	invitation.OrderByID:          "id",
+	invitation.OrderByIdeaID:      "idea_id",
+	invitation.OrderByStatus:      "status",
+	invitation.OrderByDateExpires: "date_expires",
+	invitation.OrderByDateCreated: "date_created",
+}
+
+func orderByClause(orderBy order.By) (string, error) {
+	by, exists := orderByFields[orderBy.Field]
+	if !exists {
+		return "", fmt.Errorf("field %q does not exist", orderBy.Field)
+	}
+
+	return " ORDER BY " + by + " " + orderBy.Direction, nil
+}
@@ -0,0 +1,25 @@
+package lockout
+
+import (
+	"fmt"
+
+	"github.com/dmanias/startupers/business/sys/validate"
+)
+
+// QueryFilter holds the available fields a query can be filtered on.
+type QueryFilter struct {
+	Kind *Kind `validate:"omitempty"`
+}
+
+// Validate checks the data in the model is considered clean.
+func (qf *QueryFilter) Validate() error {
+	if err := validate.Check(qf); err != nil {
+		return fmt.Errorf("validate: %w", err)
+	}
+	return nil
+}
+
+// WithKind sets the Kind field of the QueryFilter value.
+func (qf *QueryFilter) WithKind(kind Kind) {
+	qf.Kind = &kind
+}
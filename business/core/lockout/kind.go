@@ -0,0 +1,63 @@
+package lockout
+
+import "errors"
+
+// Set of things failed sign in attempts are counted against.
+var (
+	KindAccount = Kind{"account"}
+	KindIP      = Kind{"ip"}
+)
+
+// Set of known kinds.
+var kinds = map[string]Kind{
+	KindAccount.name: KindAccount,
+	KindIP.name:      KindIP,
+}
+
+// Kind represents what failed sign in attempts are counted against: the
+// email address that was tried or the IP address they came from.
+type Kind struct {
+	name string
+}
+
+// ParseKind parses the string value and returns a kind if one exists.
+func ParseKind(value string) (Kind, error) {
+	kind, exists := kinds[value]
+	if !exists {
+		return Kind{}, errors.New("invalid lockout kind")
+	}
+
+	return kind, nil
+}
+
+// MustParseKind parses the string value and returns a kind if one exists. If
+// an error occurs the function panics.
+func MustParseKind(value string) Kind {
+	kind, err := ParseKind(value)
+	if err != nil {
+		panic(err)
+	}
+
+	return kind
+}
+
+// Name returns the name of the kind.
+func (k Kind) Name() string {
+	return k.name
+}
+
+// UnmarshalText implement the unmarshal interface for JSON conversions.
+func (k *Kind) UnmarshalText(data []byte) error {
+	k.name = string(data)
+	return nil
+}
+
+// MarshalText implement the marshal interface for JSON conversions.
+func (k Kind) MarshalText() ([]byte, error) {
+	return []byte(k.name), nil
+}
+
+// Equal provides support for the go-cmp package and testing.
+func (k Kind) Equal(k2 Kind) bool {
+	return k.name == k2.name
+}
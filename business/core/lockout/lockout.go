@@ -0,0 +1,252 @@
+// Package lockout provides the business API for slowing down and locking out
+// repeated failed sign in attempts. Attempts are counted per email address,
+// whether or not it has an account, and per IP address. The counts are kept
+// in the database so every replica of the API enforces them.
+package lockout
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/mail"
+	"strings"
+	"time"
+
+	"github.com/dmanias/startupers/business/data/transaction"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// Set of error variables for CRUD operations.
+var (
+	ErrNotFound        = errors.New("lockout not found")
+	ErrTooManyAttempts = errors.New("too many sign in attempts, try again later")
+)
+
+// Storer interface declares the behavior this package needs to perists and
+// retrieve data.
+type Storer interface {
+	ExecuteUnderTransaction(tx transaction.Transaction) (Storer, error)
+	Lock(ctx context.Context, failure Failure) (Attempts, error)
+	Fail(ctx context.Context, failure Failure) error
+	Forgive(ctx context.Context, failure Failure) error
+	DeleteBySubject(ctx context.Context, kind Kind, subject string) error
+	QueryByID(ctx context.Context, attemptsID uuid.UUID) (Attempts, error)
+	Delete(ctx context.Context, att Attempts) error
+	QueryLocked(ctx context.Context, filter QueryFilter, now time.Time, pageNumber int, rowsPerPage int) ([]Attempts, error)
+	CountLocked(ctx context.Context, filter QueryFilter, now time.Time) (int, error)
+	DeleteStale(ctx context.Context, lastFailureBefore time.Time, now time.Time) error
+}
+
+// Core manages the set of APIs for lockout access.
+type Core struct {
+	log      *zap.SugaredLogger
+	beginner transaction.Beginner
+	storer   Storer
+	policy   Policy
+}
+
+// NewCore constructs a core for lockout api access.
+func NewCore(log *zap.SugaredLogger, beginner transaction.Beginner, storer Storer, policy Policy) *Core {
+	return &Core{
+		log:      log,
+		beginner: beginner,
+		storer:   storer,
+		policy:   policy,
+	}
+}
+
+// ExecuteUnderTransaction constructs a new Core value that will use the
+// specified transaction in any store related calls.
+func (c *Core) ExecuteUnderTransaction(tx transaction.Transaction) (*Core, error) {
+	trS, err := c.storer.ExecuteUnderTransaction(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	c = &Core{
+		log:      c.log,
+		beginner: c.beginner,
+		storer:   trS,
+		policy:   c.policy,
+	}
+
+	return c, nil
+}
+
+// Attempt counts a sign in as the email address, from the IP address, as a
+// failure before the password is checked. The attempts against the email and
+// the IP address are locked while this is decided, so concurrent attempts on
+// any replica are counted one after the other. When the attempt has to wait
+// it isn't counted, and the wait is returned along with ErrTooManyAttempts.
+// The answer depends only on earlier attempts, never on whether the account
+// exists.
+func (c *Core) Attempt(ctx context.Context, email mail.Address, ip string) (time.Duration, error) {
+	now := time.Now()
+
+	var wait time.Duration
+
+	f := func(tx transaction.Transaction) error {
+		storer, err := c.storer.ExecuteUnderTransaction(tx)
+		if err != nil {
+			return err
+		}
+
+		failures := c.failures(email, ip, now)
+
+		for _, failure := range failures {
+			att, err := storer.Lock(ctx, failure)
+			if err != nil {
+				return fmt.Errorf("lock: kind[%s]: %w", failure.Kind.Name(), err)
+			}
+
+			if w := c.wait(att, now); w > wait {
+				wait = w
+			}
+		}
+
+		if wait > 0 {
+			return ErrTooManyAttempts
+		}
+
+		for _, failure := range failures {
+			if err := storer.Fail(ctx, failure); err != nil {
+				return fmt.Errorf("fail: kind[%s]: %w", failure.Kind.Name(), err)
+			}
+		}
+
+		return nil
+	}
+
+	if err := transaction.ExecuteUnderTransaction(ctx, c.log, c.beginner, f); err != nil {
+		return wait, fmt.Errorf("attempt: %w", err)
+	}
+
+	return 0, nil
+}
+
+// Succeed takes back the failure counted by Attempt once the password turns
+// out to be right. The attempts against the email address are forgotten. The
+// one from the IP address is only taken back, so signing in to one account
+// doesn't make it safe to keep guessing at others.
+func (c *Core) Succeed(ctx context.Context, email mail.Address, ip string) error {
+	for _, failure := range c.failures(email, ip, time.Now()) {
+		switch failure.Kind {
+		case KindAccount:
+			if err := c.storer.DeleteBySubject(ctx, failure.Kind, failure.Subject); err != nil {
+				return fmt.Errorf("deletebysubject: %w", err)
+			}
+
+		default:
+			if err := c.storer.Forgive(ctx, failure); err != nil {
+				return fmt.Errorf("forgive: kind[%s]: %w", failure.Kind.Name(), err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// QueryLocked retrieves a page of the email and IP addresses locked out now,
+// those locked until the latest first.
+func (c *Core) QueryLocked(ctx context.Context, filter QueryFilter, pageNumber int, rowsPerPage int) ([]Attempts, error) {
+	atts, err := c.storer.QueryLocked(ctx, filter, time.Now(), pageNumber, rowsPerPage)
+	if err != nil {
+		return nil, fmt.Errorf("querylocked: %w", err)
+	}
+
+	return atts, nil
+}
+
+// CountLocked returns the number of email and IP addresses locked out now.
+func (c *Core) CountLocked(ctx context.Context, filter QueryFilter) (int, error) {
+	return c.storer.CountLocked(ctx, filter, time.Now())
+}
+
+// QueryByID gets the specified attempts from the database.
+func (c *Core) QueryByID(ctx context.Context, attemptsID uuid.UUID) (Attempts, error) {
+	att, err := c.storer.QueryByID(ctx, attemptsID)
+	if err != nil {
+		return Attempts{}, fmt.Errorf("query: attemptsID[%s]: %w", attemptsID, err)
+	}
+
+	return att, nil
+}
+
+// Unlock lifts a lockout and forgets the failed attempts behind it.
+func (c *Core) Unlock(ctx context.Context, att Attempts) error {
+	if err := c.storer.Delete(ctx, att); err != nil {
+		return fmt.Errorf("delete: attemptsID[%s]: %w", att.ID, err)
+	}
+
+	return nil
+}
+
+// Purge removes the attempts that are no longer counted and aren't locked.
+func (c *Core) Purge(ctx context.Context) error {
+	now := time.Now()
+
+	if err := c.storer.DeleteStale(ctx, now.Add(-c.policy.Window), now); err != nil {
+		return fmt.Errorf("deletestale: %w", err)
+	}
+
+	return nil
+}
+
+// =============================================================================
+
+// failures returns what a sign in attempt made now is counted against.
+// Requests without an IP address are only counted against the email address.
+func (c *Core) failures(email mail.Address, ip string, now time.Time) []Failure {
+	failures := []Failure{c.failure(KindAccount, accountSubject(email), c.policy.AccountThreshold, now)}
+	if ip != "" {
+		failures = append(failures, c.failure(KindIP, ip, c.policy.IPThreshold, now))
+	}
+	return failures
+}
+
+// failure returns what the store needs to count a failed attempt made now
+// against the subject.
+func (c *Core) failure(kind Kind, subject string, threshold int, now time.Time) Failure {
+	return Failure{
+		ID:          uuid.New(),
+		Kind:        kind,
+		Subject:     subject,
+		Threshold:   threshold,
+		Date:        now,
+		WindowStart: now.Add(-c.policy.Window),
+		LockedUntil: now.Add(c.policy.Lockout),
+	}
+}
+
+// wait returns how long the next attempt has to wait: until the lockout is
+// over, or until the delay after the last failure has passed.
+func (c *Core) wait(att Attempts, now time.Time) time.Duration {
+	if att.DateLockedUntil.After(now) {
+		return att.DateLockedUntil.Sub(now)
+	}
+
+	if att.Failures == 0 || att.DateLastFailure.Before(now.Add(-c.policy.Window)) {
+		return 0
+	}
+
+	delay := c.policy.BaseDelay
+	for i := 1; i < att.Failures && delay < c.policy.MaxDelay; i++ {
+		delay *= 2
+	}
+	if delay > c.policy.MaxDelay {
+		delay = c.policy.MaxDelay
+	}
+
+	if next := att.DateLastFailure.Add(delay); next.After(now) {
+		return next.Sub(now)
+	}
+
+	return 0
+}
+
+// accountSubject returns the email address attempts are counted against, the
+// same however it was typed.
+func accountSubject(email mail.Address) string {
+	return strings.ToLower(strings.TrimSpace(email.Address))
+}
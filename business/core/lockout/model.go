@@ -0,0 +1,43 @@
+package lockout
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Attempts represents the failed sign in attempts counted against an email
+// address or an IP address.
+type Attempts struct {
+	ID              uuid.UUID
+	Kind            Kind
+	Subject         string
+	Failures        int
+	DateLastFailure time.Time
+	DateLockedUntil time.Time
+}
+
+// Policy holds how many failed attempts are allowed and how they are slowed
+// down. Each failure makes the next attempt wait twice as long as the one
+// before, from BaseDelay up to MaxDelay. Reaching the threshold locks the
+// account or the IP address for Lockout. Failures are forgotten once none
+// happened for Window.
+type Policy struct {
+	AccountThreshold int
+	IPThreshold      int
+	BaseDelay        time.Duration
+	MaxDelay         time.Duration
+	Lockout          time.Duration
+	Window           time.Duration
+}
+
+// Failure holds what the store needs to count a failed attempt.
+type Failure struct {
+	ID          uuid.UUID
+	Kind        Kind
+	Subject     string
+	Threshold   int
+	Date        time.Time
+	WindowStart time.Time
+	LockedUntil time.Time
+}
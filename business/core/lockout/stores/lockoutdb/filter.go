@@ -0,0 +1,22 @@
+package lockoutdb
+
+import (
+	"bytes"
+	"strings"
+	"time"
+
+	"github.com/dmanias/startupers/business/core/lockout"
+)
+
+func (s *Store) applyFilter(filter lockout.QueryFilter, now time.Time, data map[string]interface{}, buf *bytes.Buffer) {
+	data["now"] = now.UTC()
+	wc := []string{"date_locked_until > :now"}
+
+	if filter.Kind != nil {
+		data["kind"] = filter.Kind.Name()
+		wc = append(wc, "kind = :kind")
+	}
+
+	buf.WriteString(" WHERE ")
+	buf.WriteString(strings.Join(wc, " AND "))
+}
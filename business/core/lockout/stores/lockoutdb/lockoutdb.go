@@ -0,0 +1,273 @@
+// Package lockoutdb contains failed sign in attempt related CRUD
+// functionality.
+package lockoutdb
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/dmanias/startupers/business/core/lockout"
+	"github.com/dmanias/startupers/business/data/sqldb"
+	"github.com/dmanias/startupers/business/data/transaction"
+	database "github.com/dmanias/startupers/business/sys/database/pgx"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"go.uber.org/zap"
+)
+
+// Store manages the set of APIs for failed sign in attempt database access.
+type Store struct {
+	log *zap.SugaredLogger
+	db  sqlx.ExtContext
+}
+
+// NewStore constructs the api for data access.
+func NewStore(log *zap.SugaredLogger, db *sqlx.DB) *Store {
+	return &Store{
+		log: log,
+		db:  db,
+	}
+}
+
+// ExecuteUnderTransaction constructs a new Store value replacing the sqlx DB
+// value with a sqlx DB value that is currently inside a transaction.
+func (s *Store) ExecuteUnderTransaction(tx transaction.Transaction) (lockout.Storer, error) {
+	ec, err := sqldb.GetExtContext(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	s = &Store{
+		log: s.log,
+		db:  ec,
+	}
+
+	return s, nil
+}
+
+// Lock gets the attempts counted against the email or IP address and locks
+// them until the transaction ends, so the next attempt against the same
+// address waits for this one to be counted. Addresses without attempts yet
+// start with none.
+func (s *Store) Lock(ctx context.Context, failure lockout.Failure) (lockout.Attempts, error) {
+	const q = `
+	INSERT INTO login_attempts
+		(id, kind, subject, failures, date_last_failure)
+	VALUES
+		(:id, :kind, :subject, 0, :date)
+	ON CONFLICT (kind, subject) DO UPDATE SET
+		subject = EXCLUDED.subject
+	RETURNING
+		id, kind, subject, failures, date_last_failure, date_locked_until`
+
+	var dbAtt dbAttempts
+	if err := database.NamedQueryStruct(ctx, s.log, s.db, q, toDBFailure(failure), &dbAtt); err != nil {
+		return lockout.Attempts{}, fmt.Errorf("namedquerystruct: %w", err)
+	}
+
+	return toCoreAttempts(dbAtt), nil
+}
+
+// Fail counts a failed attempt in a single statement, so attempts made at
+// the same time on different replicas are all counted. The count starts
+// again when the last failure is older than the window, and reaching the
+// threshold sets the lockout.
+func (s *Store) Fail(ctx context.Context, failure lockout.Failure) error {
+	const q = `
+	INSERT INTO login_attempts
+		(id, kind, subject, failures, date_last_failure, date_locked_until)
+	VALUES
+		(:id, :kind, :subject, 1, :date,
+			CASE WHEN 1 >= :threshold THEN CAST(:locked_until AS TIMESTAMPTZ) END)
+	ON CONFLICT (kind, subject) DO UPDATE SET
+		failures = CASE
+			WHEN login_attempts.date_last_failure < :window_start THEN 1
+			ELSE login_attempts.failures + 1
+		END,
+		date_last_failure = EXCLUDED.date_last_failure,
+		date_locked_until = CASE
+			WHEN (CASE
+				WHEN login_attempts.date_last_failure < :window_start THEN 1
+				ELSE login_attempts.failures + 1
+			END) >= :threshold THEN CAST(:locked_until AS TIMESTAMPTZ)
+			ELSE login_attempts.date_locked_until
+		END`
+
+	if err := database.NamedExecContext(ctx, s.log, s.db, q, toDBFailure(failure)); err != nil {
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	return nil
+}
+
+// Forgive takes back one failed attempt, and lifts the lockout if that
+// attempt is what reached the threshold.
+func (s *Store) Forgive(ctx context.Context, failure lockout.Failure) error {
+	const q = `
+	UPDATE
+		login_attempts
+	SET
+		failures = GREATEST(failures - 1, 0),
+		date_locked_until = CASE
+			WHEN failures - 1 < :threshold THEN NULL
+			ELSE date_locked_until
+		END
+	WHERE
+		kind = :kind AND subject = :subject`
+
+	if err := database.NamedExecContext(ctx, s.log, s.db, q, toDBFailure(failure)); err != nil {
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteBySubject removes the attempts counted against the email or IP
+// address, if there are any.
+func (s *Store) DeleteBySubject(ctx context.Context, kind lockout.Kind, subject string) error {
+	data := struct {
+		Kind    string `db:"kind"`
+		Subject string `db:"subject"`
+	}{
+		Kind:    kind.Name(),
+		Subject: subject,
+	}
+
+	const q = `
+	DELETE FROM
+		login_attempts
+	WHERE
+		kind = :kind AND subject = :subject`
+
+	if err := database.NamedExecContext(ctx, s.log, s.db, q, data); err != nil {
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	return nil
+}
+
+// QueryByID gets the specified attempts from the database.
+func (s *Store) QueryByID(ctx context.Context, attemptsID uuid.UUID) (lockout.Attempts, error) {
+	data := struct {
+		ID string `db:"id"`
+	}{
+		ID: attemptsID.String(),
+	}
+
+	const q = `
+	SELECT
+		id, kind, subject, failures, date_last_failure, date_locked_until
+	FROM
+		login_attempts
+	WHERE
+		id = :id`
+
+	var dbAtt dbAttempts
+	if err := database.NamedQueryStruct(ctx, s.log, s.db, q, data, &dbAtt); err != nil {
+		if errors.Is(err, database.ErrDBNotFound) {
+			return lockout.Attempts{}, fmt.Errorf("namedquerystruct: %w", lockout.ErrNotFound)
+		}
+		return lockout.Attempts{}, fmt.Errorf("namedquerystruct: %w", err)
+	}
+
+	return toCoreAttempts(dbAtt), nil
+}
+
+// Delete removes the attempts from the database.
+func (s *Store) Delete(ctx context.Context, att lockout.Attempts) error {
+	data := struct {
+		ID string `db:"id"`
+	}{
+		ID: att.ID.String(),
+	}
+
+	const q = `
+	DELETE FROM
+		login_attempts
+	WHERE
+		id = :id`
+
+	if err := database.NamedExecContext(ctx, s.log, s.db, q, data); err != nil {
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	return nil
+}
+
+// QueryLocked retrieves a page of the attempts locked out now, those locked
+// until the latest first.
+func (s *Store) QueryLocked(ctx context.Context, filter lockout.QueryFilter, now time.Time, pageNumber int, rowsPerPage int) ([]lockout.Attempts, error) {
+	data := map[string]interface{}{
+		"offset":        (pageNumber - 1) * rowsPerPage,
+		"rows_per_page": rowsPerPage,
+	}
+
+	const q = `
+	SELECT
+		id, kind, subject, failures, date_last_failure, date_locked_until
+	FROM
+		login_attempts`
+
+	buf := bytes.NewBufferString(q)
+	s.applyFilter(filter, now, data, buf)
+	buf.WriteString(" ORDER BY date_locked_until DESC, id")
+	buf.WriteString(" OFFSET :offset ROWS FETCH NEXT :rows_per_page ROWS ONLY")
+
+	var dbAtts []dbAttempts
+	if err := database.NamedQuerySlice(ctx, s.log, s.db, buf.String(), data, &dbAtts); err != nil {
+		return nil, fmt.Errorf("namedqueryslice: %w", err)
+	}
+
+	return toCoreAttemptsSlice(dbAtts), nil
+}
+
+// CountLocked returns the number of attempts locked out now.
+func (s *Store) CountLocked(ctx context.Context, filter lockout.QueryFilter, now time.Time) (int, error) {
+	data := map[string]interface{}{}
+
+	const q = `
+	SELECT
+		count(1)
+	FROM
+		login_attempts`
+
+	buf := bytes.NewBufferString(q)
+	s.applyFilter(filter, now, data, buf)
+
+	var count struct {
+		Count int `db:"count"`
+	}
+	if err := database.NamedQueryStruct(ctx, s.log, s.db, buf.String(), data, &count); err != nil {
+		return 0, fmt.Errorf("namedquerystruct: %w", err)
+	}
+
+	return count.Count, nil
+}
+
+// DeleteStale removes the attempts whose last failure is older than the
+// window and that aren't locked out.
+func (s *Store) DeleteStale(ctx context.Context, lastFailureBefore time.Time, now time.Time) error {
+	data := struct {
+		LastFailureBefore time.Time `db:"last_failure_before"`
+		Now               time.Time `db:"now"`
+	}{
+		LastFailureBefore: lastFailureBefore.UTC(),
+		Now:               now.UTC(),
+	}
+
+	const q = `
+	DELETE FROM
+		login_attempts
+	WHERE
+		date_last_failure < :last_failure_before AND
+		(date_locked_until IS NULL OR date_locked_until <= :now)`
+
+	if err := database.NamedExecContext(ctx, s.log, s.db, q, data); err != nil {
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	return nil
+}
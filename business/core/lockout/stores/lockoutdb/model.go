@@ -0,0 +1,68 @@
+package lockoutdb
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/dmanias/startupers/business/core/lockout"
+	"github.com/google/uuid"
+)
+
+// dbAttempts represent the structure we need for moving data
+// between the app and the database.
+type dbAttempts struct {
+	ID              uuid.UUID    `db:"id"`
+	Kind            string       `db:"kind"`
+	Subject         string       `db:"subject"`
+	Failures        int          `db:"failures"`
+	DateLastFailure time.Time    `db:"date_last_failure"`
+	DateLockedUntil sql.NullTime `db:"date_locked_until"`
+}
+
+// dbFailure represent the structure we need for moving data
+// between the app and the database.
+type dbFailure struct {
+	ID          uuid.UUID `db:"id"`
+	Kind        string    `db:"kind"`
+	Subject     string    `db:"subject"`
+	Threshold   int       `db:"threshold"`
+	Date        time.Time `db:"date"`
+	WindowStart time.Time `db:"window_start"`
+	LockedUntil time.Time `db:"locked_until"`
+}
+
+func toDBFailure(failure lockout.Failure) dbFailure {
+	return dbFailure{
+		ID:          failure.ID,
+		Kind:        failure.Kind.Name(),
+		Subject:     failure.Subject,
+		Threshold:   failure.Threshold,
+		Date:        failure.Date.UTC(),
+		WindowStart: failure.WindowStart.UTC(),
+		LockedUntil: failure.LockedUntil.UTC(),
+	}
+}
+
+func toCoreAttempts(dbAtt dbAttempts) lockout.Attempts {
+	att := lockout.Attempts{
+		ID:              dbAtt.ID,
+		Kind:            lockout.MustParseKind(dbAtt.Kind),
+		Subject:         dbAtt.Subject,
+		Failures:        dbAtt.Failures,
+		DateLastFailure: dbAtt.DateLastFailure.In(time.Local),
+	}
+
+	if dbAtt.DateLockedUntil.Valid {
+		att.DateLockedUntil = dbAtt.DateLockedUntil.Time.In(time.Local)
+	}
+
+	return att
+}
+
+func toCoreAttemptsSlice(dbAtts []dbAttempts) []lockout.Attempts {
+	atts := make([]lockout.Attempts, len(dbAtts))
+	for i, dbAtt := range dbAtts {
+		atts[i] = toCoreAttempts(dbAtt)
+	}
+	return atts
+}
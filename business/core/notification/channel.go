@@ -0,0 +1,48 @@
+package notification
+
+import (
+	"context"
+	"sort"
+
+	"github.com/dmanias/startupers/business/core/user"
+)
+
+// Set of channels notifications are delivered over. In-app notifications are
+// the ones stored and listed by the API, the others are delivered by the
+// channels registered with the core.
+const (
+	ChannelInApp = "inapp"
+	ChannelEmail = "email"
+	ChannelPush  = "push"
+)
+
+// Set of known channels.
+var channels = map[string]bool{
+	ChannelInApp: true,
+	ChannelEmail: true,
+	ChannelPush:  true,
+}
+
+// KnownChannel reports whether notifications can be delivered over the named
+// channel.
+func KnownChannel(name string) bool {
+	return channels[name]
+}
+
+// Channels returns the names of every known channel, in order.
+func Channels() []string {
+	names := make([]string, 0, len(channels))
+	for name := range channels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// Channel delivers notifications to users outside the application, such as
+// by email or push.
+type Channel interface {
+	Name() string
+	Deliver(ctx context.Context, usr user.User, n Notification) error
+}
@@ -0,0 +1,63 @@
+package notification
+
+import (
+	"context"
+	"fmt"
+	"net/mail"
+
+	"github.com/dmanias/startupers/business/core/email"
+	"github.com/dmanias/startupers/business/core/idea"
+	"github.com/dmanias/startupers/business/core/user"
+)
+
+// EmailChannel delivers notifications by email, through the outbox. Each
+// notification type has an email template of the same name. Invitations are
+// left out: the invitation core emails them itself, with the link to accept.
+type EmailChannel struct {
+	email *email.Core
+	idea  *idea.Core
+}
+
+// NewEmailChannel constructs a channel that emails notifications.
+func NewEmailChannel(emailCore *email.Core, ideaCore *idea.Core) *EmailChannel {
+	return &EmailChannel{
+		email: emailCore,
+		idea:  ideaCore,
+	}
+}
+
+// Name returns the name of the channel.
+func (ch *EmailChannel) Name() string {
+	return ChannelEmail
+}
+
+// Deliver queues an email telling the user about the notification.
+func (ch *EmailChannel) Deliver(ctx context.Context, usr user.User, n Notification) error {
+	if n.Type.Equal(TypeInvitationReceived) {
+		return nil
+	}
+
+	idr, err := ch.idea.QueryByID(ctx, n.IdeaID)
+	if err != nil {
+		return fmt.Errorf("deliver: %w", err)
+	}
+
+	ne := email.NewEmail{
+		To:       mail.Address{Name: usr.Name, Address: usr.Email.Address},
+		Template: n.Type.Name(),
+		Locale:   usr.Locale,
+		Data: struct {
+			Name      string
+			IdeaTitle string
+		}{
+			Name:      usr.Name,
+			IdeaTitle: idr.Title,
+		},
+	}
+
+	if _, err := ch.email.Enqueue(ctx, ne); err != nil {
+		return fmt.Errorf("deliver: %w", err)
+	}
+
+	return nil
+}
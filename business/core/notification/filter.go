@@ -0,0 +1,33 @@
+package notification
+
+import (
+	"fmt"
+
+	"github.com/dmanias/startupers/business/sys/validate"
+	"github.com/google/uuid"
+)
+
+// QueryFilter holds the available fields a query can be filtered on.
+type QueryFilter struct {
+	UserID *uuid.UUID `validate:"omitempty"`
+	Unread *bool      `validate:"omitempty"`
+}
+
+// Validate checks the data in the model is considered clean.
+func (qf *QueryFilter) Validate() error {
+	if err := validate.Check(qf); err != nil {
+		return fmt.Errorf("validate: %w", err)
+	}
+	return nil
+}
+
+// WithUserID restricts the result to the notifications of the user.
+func (qf *QueryFilter) WithUserID(userID uuid.UUID) {
+	qf.UserID = &userID
+}
+
+// WithUnread restricts the result to the notifications that haven't been
+// read, or to the ones that have.
+func (qf *QueryFilter) WithUnread(unread bool) {
+	qf.Unread = &unread
+}
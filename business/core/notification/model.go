@@ -0,0 +1,26 @@
+package notification
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Notification tells a user about something that happened on an idea they are
+// involved in.
+type Notification struct {
+	ID          uuid.UUID
+	UserID      uuid.UUID
+	Type        Type
+	ActorID     uuid.UUID
+	IdeaID      uuid.UUID
+	SubjectID   uuid.UUID
+	Details     map[string]string
+	ReadAt      time.Time
+	DateCreated time.Time
+}
+
+// Read reports whether the user has read the notification.
+func (n Notification) Read() bool {
+	return !n.ReadAt.IsZero()
+}
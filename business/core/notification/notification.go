@@ -0,0 +1,223 @@
+// Package notification provides the business API for notifying users about
+// what happens on the ideas they are involved in. The post and invitation
+// cores record what happens as it happens, the notifications are stored for
+// the users to read in the application and handed to the delivery channels
+// the users haven't turned off.
+package notification
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/dmanias/startupers/business/core/activity"
+	"github.com/dmanias/startupers/business/core/idea"
+	"github.com/dmanias/startupers/business/core/post"
+	"github.com/dmanias/startupers/business/core/user"
+	"github.com/dmanias/startupers/business/data/order"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// Set of error variables for CRUD operations.
+var (
+	ErrNotFound = errors.New("notification not found")
+)
+
+// Storer interface declares the behavior this package needs to perists and
+// retrieve data.
+type Storer interface {
+	Create(ctx context.Context, n Notification) error
+	MarkRead(ctx context.Context, n Notification) error
+	MarkAllRead(ctx context.Context, userID uuid.UUID, readAt time.Time) (int, error)
+	Query(ctx context.Context, filter QueryFilter, orderBy order.By, pageNumber int, rowsPerPage int) ([]Notification, error)
+	Count(ctx context.Context, filter QueryFilter) (int, error)
+	QueryByID(ctx context.Context, notificationID uuid.UUID) (Notification, error)
+}
+
+// Core manages the set of APIs for notification access.
+type Core struct {
+	log      *zap.SugaredLogger
+	storer   Storer
+	idea     *idea.Core
+	user     *user.Core
+	channels []Channel
+}
+
+// NewCore constructs a core for notification api access.
+func NewCore(log *zap.SugaredLogger, storer Storer, ideaCore *idea.Core, userCore *user.Core) *Core {
+	return &Core{
+		log:    log,
+		storer: storer,
+		idea:   ideaCore,
+		user:   userCore,
+	}
+}
+
+// AddChannel registers a channel notifications are delivered over besides
+// the application. It is meant to be called while the application is being
+// wired up, before the core is in use.
+func (c *Core) AddChannel(channel Channel) {
+	c.channels = append(c.channels, channel)
+}
+
+// Record notifies the users involved in the activity. Activity nobody needs
+// to be told about is ignored. Notifying is best effort: the write that caused
+// the activity has already happened, so a failure is logged rather than
+// returned.
+func (c *Core) Record(ctx context.Context, na activity.NewActivity) {
+	switch {
+	case na.Type.Equal(activity.TypePostCreated):
+		c.recordPost(ctx, na)
+
+	case na.Type.Equal(activity.TypeInvitationCreated):
+		inviteeID, err := uuid.Parse(na.Details["inviteeID"])
+		if err != nil {
+			c.log.Errorw("notification", "status", "parsing invitee", "type", na.Type.Name(), "ideaID", na.IdeaID, "ERROR", err)
+			return
+		}
+		c.notify(ctx, TypeInvitationReceived, na, []uuid.UUID{inviteeID})
+	}
+}
+
+// recordPost tells the owner and the collaborators of the idea that one of
+// them posted, and the author of a question to the AI that the answer is
+// ready.
+func (c *Core) recordPost(ctx context.Context, na activity.NewActivity) {
+	idr, err := c.idea.QueryByID(ctx, na.IdeaID)
+	if err != nil {
+		c.log.Errorw("notification", "status", "querying idea", "type", na.Type.Name(), "ideaID", na.IdeaID, "ERROR", err)
+		return
+	}
+
+	var userIDs []uuid.UUID
+	for _, userID := range append([]uuid.UUID{idr.UserID}, idr.Collaborators...) {
+		if userID != na.ActorID {
+			userIDs = append(userIDs, userID)
+		}
+	}
+	c.notify(ctx, TypeCollaboratorPosted, na, userIDs)
+
+	if na.Details["ownerType"] == post.OwnerTypeIdea {
+		c.notify(ctx, TypeAIAnswerReady, na, []uuid.UUID{na.ActorID})
+	}
+}
+
+// notify stores a notification of the activity for each user, and delivers it
+// over every channel the user hasn't turned off for the type.
+func (c *Core) notify(ctx context.Context, typ Type, na activity.NewActivity, userIDs []uuid.UUID) {
+	if len(userIDs) == 0 {
+		return
+	}
+
+	usrs, err := c.user.QueryByIDs(ctx, userIDs)
+	if err != nil {
+		c.log.Errorw("notification", "status", "querying users", "type", typ.Name(), "ideaID", na.IdeaID, "ERROR", err)
+		return
+	}
+
+	details := na.Details
+	if details == nil {
+		details = map[string]string{}
+	}
+
+	for _, usr := range usrs {
+		if !usr.Enabled {
+			continue
+		}
+
+		n := Notification{
+			ID:          uuid.New(),
+			UserID:      usr.ID,
+			Type:        typ,
+			ActorID:     na.ActorID,
+			IdeaID:      na.IdeaID,
+			SubjectID:   na.SubjectID,
+			Details:     details,
+			DateCreated: time.Now(),
+		}
+
+		if usr.NotificationPreferences.Enabled(typ.Name(), ChannelInApp) {
+			if err := c.storer.Create(ctx, n); err != nil {
+				c.log.Errorw("notification", "status", "storing notification", "type", typ.Name(), "userID", usr.ID, "ERROR", err)
+			}
+		}
+
+		for _, channel := range c.channels {
+			if !usr.NotificationPreferences.Enabled(typ.Name(), channel.Name()) {
+				continue
+			}
+
+			if err := channel.Deliver(ctx, usr, n); err != nil {
+				c.log.Errorw("notification", "status", "delivering notification", "type", typ.Name(), "channel", channel.Name(), "userID", usr.ID, "ERROR", err)
+			}
+		}
+	}
+}
+
+// MarkRead marks the notification as read. Marking a notification that was
+// already read changes nothing.
+func (c *Core) MarkRead(ctx context.Context, n Notification) (Notification, error) {
+	if n.Read() {
+		return n, nil
+	}
+
+	n.ReadAt = time.Now()
+
+	if err := c.storer.MarkRead(ctx, n); err != nil {
+		return Notification{}, fmt.Errorf("markread: notificationID[%s]: %w", n.ID, err)
+	}
+
+	return n, nil
+}
+
+// MarkAllRead marks every unread notification of the user as read and
+// returns how many there were.
+func (c *Core) MarkAllRead(ctx context.Context, userID uuid.UUID) (int, error) {
+	count, err := c.storer.MarkAllRead(ctx, userID, time.Now())
+	if err != nil {
+		return 0, fmt.Errorf("markallread: userID[%s]: %w", userID, err)
+	}
+
+	return count, nil
+}
+
+// Query retrieves a list of existing notifications from the database.
+func (c *Core) Query(ctx context.Context, filter QueryFilter, orderBy order.By, pageNumber int, rowsPerPage int) ([]Notification, error) {
+	notifications, err := c.storer.Query(ctx, filter, orderBy, pageNumber, rowsPerPage)
+	if err != nil {
+		return nil, fmt.Errorf("query: %w", err)
+	}
+
+	return notifications, nil
+}
+
+// Count returns the total number of notifications in the store.
+func (c *Core) Count(ctx context.Context, filter QueryFilter) (int, error) {
+	return c.storer.Count(ctx, filter)
+}
+
+// CountUnread returns the number of notifications the user hasn't read.
+func (c *Core) CountUnread(ctx context.Context, userID uuid.UUID) (int, error) {
+	var filter QueryFilter
+	filter.WithUserID(userID)
+	filter.WithUnread(true)
+
+	count, err := c.storer.Count(ctx, filter)
+	if err != nil {
+		return 0, fmt.Errorf("countunread: userID[%s]: %w", userID, err)
+	}
+
+	return count, nil
+}
+
+// QueryByID gets the specified notification from the database.
+func (c *Core) QueryByID(ctx context.Context, notificationID uuid.UUID) (Notification, error) {
+	n, err := c.storer.QueryByID(ctx, notificationID)
+	if err != nil {
+		return Notification{}, fmt.Errorf("query: notificationID[%s]: %w", notificationID, err)
+	}
+
+	return n, nil
+}
@@ -0,0 +1,12 @@
+package notification
+
+import "github.com/dmanias/startupers/business/data/order"
+
+// DefaultOrderBy represents the default way we sort.
+var DefaultOrderBy = order.NewBy(OrderByDateCreated, order.DESC)
+
+// Set of fields that the results can be ordered by. These are the names
+// that should be used by the application layer.
+const (
+	OrderByDateCreated = "datecreated"
+)
@@ -0,0 +1,30 @@
+package notificationdb
+
+import (
+	"bytes"
+	"strings"
+
+	"github.com/dmanias/startupers/business/core/notification"
+)
+
+func (s *Store) applyFilter(filter notification.QueryFilter, data map[string]interface{}, buf *bytes.Buffer) {
+	var wc []string
+
+	if filter.UserID != nil {
+		data["user_id"] = filter.UserID
+		wc = append(wc, "user_id = :user_id")
+	}
+
+	if filter.Unread != nil {
+		if *filter.Unread {
+			wc = append(wc, "read_at IS NULL")
+		} else {
+			wc = append(wc, "read_at IS NOT NULL")
+		}
+	}
+
+	if len(wc) > 0 {
+		buf.WriteString(" WHERE ")
+		buf.WriteString(strings.Join(wc, " AND "))
+	}
+}
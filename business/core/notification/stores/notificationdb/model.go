@@ -0,0 +1,80 @@
+package notificationdb
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/dmanias/startupers/business/core/notification"
+	"github.com/google/uuid"
+)
+
+// dbNotification represent the structure we need for moving data
+// between the app and the database.
+type dbNotification struct {
+	ID          uuid.UUID     `db:"id"`
+	UserID      uuid.UUID     `db:"user_id"`
+	Type        string        `db:"type"`
+	ActorID     uuid.UUID     `db:"actor_id"`
+	IdeaID      uuid.UUID     `db:"idea_id"`
+	SubjectID   uuid.NullUUID `db:"subject_id"`
+	Details     string        `db:"details"`
+	ReadAt      sql.NullTime  `db:"read_at"`
+	DateCreated time.Time     `db:"date_created"`
+}
+
+func toDBNotification(n notification.Notification) (dbNotification, error) {
+	details, err := json.Marshal(n.Details)
+	if err != nil {
+		return dbNotification{}, fmt.Errorf("marshal details: %w", err)
+	}
+
+	return dbNotification{
+		ID:      n.ID,
+		UserID:  n.UserID,
+		Type:    n.Type.Name(),
+		ActorID: n.ActorID,
+		IdeaID:  n.IdeaID,
+		SubjectID: uuid.NullUUID{
+			UUID:  n.SubjectID,
+			Valid: n.SubjectID != uuid.Nil,
+		},
+		Details: string(details),
+		ReadAt: sql.NullTime{
+			Time:  n.ReadAt.UTC(),
+			Valid: n.Read(),
+		},
+		DateCreated: n.DateCreated.UTC(),
+	}, nil
+}
+
+func toCoreNotification(dbN dbNotification) notification.Notification {
+	var details map[string]string
+	_ = json.Unmarshal([]byte(dbN.Details), &details)
+
+	var readAt time.Time
+	if dbN.ReadAt.Valid {
+		readAt = dbN.ReadAt.Time.In(time.Local)
+	}
+
+	return notification.Notification{
+		ID:          dbN.ID,
+		UserID:      dbN.UserID,
+		Type:        notification.MustParseType(dbN.Type),
+		ActorID:     dbN.ActorID,
+		IdeaID:      dbN.IdeaID,
+		SubjectID:   dbN.SubjectID.UUID,
+		Details:     details,
+		ReadAt:      readAt,
+		DateCreated: dbN.DateCreated.In(time.Local),
+	}
+}
+
+func toCoreNotificationSlice(dbNs []dbNotification) []notification.Notification {
+	ns := make([]notification.Notification, len(dbNs))
+	for i, dbN := range dbNs {
+		ns[i] = toCoreNotification(dbN)
+	}
+	return ns
+}
@@ -0,0 +1,193 @@
+// Package notificationdb contains notification related CRUD functionality.
+package notificationdb
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/dmanias/startupers/business/core/notification"
+	"github.com/dmanias/startupers/business/data/order"
+	database "github.com/dmanias/startupers/business/sys/database/pgx"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"go.uber.org/zap"
+)
+
+// Store manages the set of APIs for notification database access.
+type Store struct {
+	log *zap.SugaredLogger
+	db  sqlx.ExtContext
+}
+
+// NewStore constructs the api for data access.
+func NewStore(log *zap.SugaredLogger, db *sqlx.DB) *Store {
+	return &Store{
+		log: log,
+		db:  db,
+	}
+}
+
+// Create inserts a new notification into the database.
+func (s *Store) Create(ctx context.Context, n notification.Notification) error {
+	dbN, err := toDBNotification(n)
+	if err != nil {
+		return err
+	}
+
+	const q = `
+	INSERT INTO notifications
+		(id, user_id, type, actor_id, idea_id, subject_id, details, read_at, date_created)
+	VALUES
+		(:id, :user_id, :type, :actor_id, :idea_id, :subject_id, CAST(:details AS JSONB), :read_at, :date_created)`
+
+	if err := database.NamedExecContext(ctx, s.log, s.db, q, dbN); err != nil {
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	return nil
+}
+
+// MarkRead records when the notification was read.
+func (s *Store) MarkRead(ctx context.Context, n notification.Notification) error {
+	dbN, err := toDBNotification(n)
+	if err != nil {
+		return err
+	}
+
+	const q = `
+	UPDATE
+		notifications
+	SET
+		"read_at" = :read_at
+	WHERE
+		id = :id`
+
+	if err := database.NamedExecContext(ctx, s.log, s.db, q, dbN); err != nil {
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	return nil
+}
+
+// MarkAllRead records when the unread notifications of the user were read and
+// returns how many there were.
+func (s *Store) MarkAllRead(ctx context.Context, userID uuid.UUID, readAt time.Time) (int, error) {
+	data := struct {
+		UserID uuid.UUID `db:"user_id"`
+		ReadAt time.Time `db:"read_at"`
+	}{
+		UserID: userID,
+		ReadAt: readAt.UTC(),
+	}
+
+	const q = `
+	WITH marked AS (
+		UPDATE
+			notifications
+		SET
+			"read_at" = :read_at
+		WHERE
+			user_id = :user_id AND
+			read_at IS NULL
+		RETURNING
+			id
+	)
+	SELECT
+		count(1)
+	FROM
+		marked`
+
+	var count struct {
+		Count int `db:"count"`
+	}
+	if err := database.NamedQueryStruct(ctx, s.log, s.db, q, data, &count); err != nil {
+		return 0, fmt.Errorf("namedquerystruct: %w", err)
+	}
+
+	return count.Count, nil
+}
+
+// Query retrieves a list of existing notifications from the database.
+func (s *Store) Query(ctx context.Context, filter notification.QueryFilter, orderBy order.By, pageNumber int, rowsPerPage int) ([]notification.Notification, error) {
+	data := map[string]interface{}{
+		"offset":        (pageNumber - 1) * rowsPerPage,
+		"rows_per_page": rowsPerPage,
+	}
+
+	const q = `
+	SELECT
+		*
+	FROM
+		notifications`
+
+	buf := bytes.NewBufferString(q)
+	s.applyFilter(filter, data, buf)
+
+	orderByClause, err := orderByClause(orderBy)
+	if err != nil {
+		return nil, err
+	}
+
+	buf.WriteString(orderByClause)
+	buf.WriteString(" OFFSET :offset ROWS FETCH NEXT :rows_per_page ROWS ONLY")
+
+	var dbNs []dbNotification
+	if err := database.NamedQuerySlice(ctx, s.log, s.db, buf.String(), data, &dbNs); err != nil {
+		return nil, fmt.Errorf("namedqueryslice: %w", err)
+	}
+
+	return toCoreNotificationSlice(dbNs), nil
+}
+
+// Count returns the total number of notifications in the DB.
+func (s *Store) Count(ctx context.Context, filter notification.QueryFilter) (int, error) {
+	data := map[string]interface{}{}
+
+	const q = `
+	SELECT
+		count(1)
+	FROM
+		notifications`
+
+	buf := bytes.NewBufferString(q)
+	s.applyFilter(filter, data, buf)
+
+	var count struct {
+		Count int `db:"count"`
+	}
+	if err := database.NamedQueryStruct(ctx, s.log, s.db, buf.String(), data, &count); err != nil {
+		return 0, fmt.Errorf("namedquerystruct: %w", err)
+	}
+
+	return count.Count, nil
+}
+
+// QueryByID gets the specified notification from the database.
+func (s *Store) QueryByID(ctx context.Context, notificationID uuid.UUID) (notification.Notification, error) {
+	data := struct {
+		ID string `db:"id"`
+	}{
+		ID: notificationID.String(),
+	}
+
+	const q = `
+	SELECT
+		*
+	FROM
+		notifications
+	WHERE
+		id = :id`
+
+	var dbN dbNotification
+	if err := database.NamedQueryStruct(ctx, s.log, s.db, q, data, &dbN); err != nil {
+		if errors.Is(err, database.ErrDBNotFound) {
+			return notification.Notification{}, fmt.Errorf("namedquerystruct: %w", notification.ErrNotFound)
+		}
+		return notification.Notification{}, fmt.Errorf("namedquerystruct: %w", err)
+	}
+
+	return toCoreNotification(dbN), nil
+}
@@ -0,0 +1,78 @@
+package notification
+
+import (
+	"errors"
+	"sort"
+)
+
+// Set of things users are notified about.
+var (
+	TypeCollaboratorPosted = Type{"collaborator_posted"}
+	TypeAIAnswerReady      = Type{"ai_answer_ready"}
+	TypeInvitationReceived = Type{"invitation_received"}
+)
+
+// Set of known notification types.
+var types = map[string]Type{
+	TypeCollaboratorPosted.name: TypeCollaboratorPosted,
+	TypeAIAnswerReady.name:      TypeAIAnswerReady,
+	TypeInvitationReceived.name: TypeInvitationReceived,
+}
+
+// Types returns every known notification type, ordered by name.
+func Types() []Type {
+	typs := make([]Type, 0, len(types))
+	for _, typ := range types {
+		typs = append(typs, typ)
+	}
+	sort.Slice(typs, func(i, j int) bool { return typs[i].name < typs[j].name })
+
+	return typs
+}
+
+// Type represents the kind of a notification.
+type Type struct {
+	name string
+}
+
+// ParseType parses the string value and returns a type if one exists.
+func ParseType(value string) (Type, error) {
+	typ, exists := types[value]
+	if !exists {
+		return Type{}, errors.New("invalid notification type")
+	}
+
+	return typ, nil
+}
+
+// MustParseType parses the string value and returns a type if one exists. If
+// an error occurs the function panics.
+func MustParseType(value string) Type {
+	typ, err := ParseType(value)
+	if err != nil {
+		panic(err)
+	}
+
+	return typ
+}
+
+// Name returns the name of the type.
+func (t Type) Name() string {
+	return t.name
+}
+
+// UnmarshalText implement the unmarshal interface for JSON conversions.
+func (t *Type) UnmarshalText(data []byte) error {
+	t.name = string(data)
+	return nil
+}
+
+// MarshalText implement the marshal interface for JSON conversions.
+func (t Type) MarshalText() ([]byte, error) {
+	return []byte(t.name), nil
+}
+
+// Equal provides support for the go-cmp package and testing.
+func (t Type) Equal(t2 Type) bool {
+	return t.name == t2.name
+}
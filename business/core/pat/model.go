@@ -0,0 +1,41 @@
+package pat
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Token is a personal access token a user created for a script or a CLI. It
+// acts as the user, limited to its scopes. Only the hash of the secret is
+// kept.
+type Token struct {
+	ID           uuid.UUID
+	UserID       uuid.UUID
+	Name         string
+	Scopes       []Scope
+	TokenHash    []byte
+	DateExpires  time.Time
+	DateLastUsed time.Time
+	DateRevoked  time.Time
+	DateCreated  time.Time
+}
+
+// Expired reports whether the token had an expiry that has passed.
+func (t Token) Expired(now time.Time) bool {
+	return !t.DateExpires.IsZero() && !now.Before(t.DateExpires)
+}
+
+// Revoked reports whether the token was revoked.
+func (t Token) Revoked() bool {
+	return !t.DateRevoked.IsZero()
+}
+
+// NewToken contains information needed to create a personal access token. A
+// zero DateExpires means the token works until it is revoked.
+type NewToken struct {
+	UserID      uuid.UUID
+	Name        string
+	Scopes      []Scope
+	DateExpires time.Time
+}
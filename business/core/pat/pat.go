@@ -0,0 +1,181 @@
+// Package pat provides the business API for personal access tokens, which
+// let scripts and command line clients call the API as a user without
+// signing in.
+package pat
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// Prefix starts every personal access token, so they are easy to recognise,
+// for instance by secret scanners.
+const Prefix = "stp_"
+
+// lastUsedInterval is how often the time a token was last used is recorded.
+const lastUsedInterval = time.Minute
+
+// Set of error variables for CRUD operations.
+var (
+	ErrNotFound     = errors.New("personal access token not found")
+	ErrInvalidToken = errors.New("personal access token is invalid, expired or revoked")
+	ErrNoScopes     = errors.New("personal access token needs at least one scope")
+	ErrExpiryPassed = errors.New("expiry must be in the future")
+)
+
+// Storer interface declares the behavior this package needs to perists and
+// retrieve data.
+type Storer interface {
+	Create(ctx context.Context, tkn Token) error
+	Revoke(ctx context.Context, tkn Token) error
+	UpdateLastUsed(ctx context.Context, tokenID uuid.UUID, lastUsed time.Time) error
+	QueryByUserID(ctx context.Context, userID uuid.UUID) ([]Token, error)
+	QueryByID(ctx context.Context, tokenID uuid.UUID) (Token, error)
+	QueryByTokenHash(ctx context.Context, tokenHash []byte) (Token, error)
+}
+
+// Core manages the set of APIs for personal access token access.
+type Core struct {
+	log    *zap.SugaredLogger
+	storer Storer
+}
+
+// NewCore constructs a core for personal access token api access.
+func NewCore(log *zap.SugaredLogger, storer Storer) *Core {
+	return &Core{
+		log:    log,
+		storer: storer,
+	}
+}
+
+// Create adds a new personal access token and returns its secret. The secret
+// is the only copy; just its hash is stored.
+func (c *Core) Create(ctx context.Context, nt NewToken) (Token, string, error) {
+	if len(nt.Scopes) == 0 {
+		return Token{}, "", ErrNoScopes
+	}
+
+	now := time.Now()
+
+	if !nt.DateExpires.IsZero() && !nt.DateExpires.After(now) {
+		return Token{}, "", ErrExpiryPassed
+	}
+
+	secret, tokenHash, err := newToken()
+	if err != nil {
+		return Token{}, "", fmt.Errorf("create: %w", err)
+	}
+
+	tkn := Token{
+		ID:          uuid.New(),
+		UserID:      nt.UserID,
+		Name:        nt.Name,
+		Scopes:      nt.Scopes,
+		TokenHash:   tokenHash,
+		DateExpires: nt.DateExpires,
+		DateCreated: now,
+	}
+
+	if err := c.storer.Create(ctx, tkn); err != nil {
+		return Token{}, "", fmt.Errorf("create: %w", err)
+	}
+
+	return tkn, secret, nil
+}
+
+// Revoke stops the token from working. Revoking a token that was already
+// revoked changes nothing.
+func (c *Core) Revoke(ctx context.Context, tkn Token) (Token, error) {
+	if tkn.Revoked() {
+		return tkn, nil
+	}
+
+	tkn.DateRevoked = time.Now()
+
+	if err := c.storer.Revoke(ctx, tkn); err != nil {
+		return Token{}, fmt.Errorf("revoke: tokenID[%s]: %w", tkn.ID, err)
+	}
+
+	return tkn, nil
+}
+
+// Authenticate returns the token with the secret, if it can still be used.
+func (c *Core) Authenticate(ctx context.Context, secret string) (Token, error) {
+	if !strings.HasPrefix(secret, Prefix) {
+		return Token{}, ErrInvalidToken
+	}
+
+	tkn, err := c.storer.QueryByTokenHash(ctx, hashToken(secret))
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return Token{}, ErrInvalidToken
+		}
+		return Token{}, fmt.Errorf("authenticate: %w", err)
+	}
+
+	now := time.Now()
+
+	if tkn.Revoked() || tkn.Expired(now) {
+		return Token{}, ErrInvalidToken
+	}
+
+	// Recording when the token was used is best effort, and not done on
+	// every request. Only the usage date is written, so a revoke that
+	// commits in the meantime isn't undone.
+	if now.Sub(tkn.DateLastUsed) >= lastUsedInterval {
+		tkn.DateLastUsed = now
+		if err := c.storer.UpdateLastUsed(ctx, tkn.ID, now); err != nil {
+			c.log.Errorw("pat", "status", "recording last use", "tokenID", tkn.ID, "ERROR", err)
+		}
+	}
+
+	return tkn, nil
+}
+
+// QueryByUserID returns the tokens of the user that weren't revoked, newest
+// first.
+func (c *Core) QueryByUserID(ctx context.Context, userID uuid.UUID) ([]Token, error) {
+	tkns, err := c.storer.QueryByUserID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("query: userID[%s]: %w", userID, err)
+	}
+
+	return tkns, nil
+}
+
+// QueryByID gets the specified token from the database.
+func (c *Core) QueryByID(ctx context.Context, tokenID uuid.UUID) (Token, error) {
+	tkn, err := c.storer.QueryByID(ctx, tokenID)
+	if err != nil {
+		return Token{}, fmt.Errorf("query: tokenID[%s]: %w", tokenID, err)
+	}
+
+	return tkn, nil
+}
+
+// =============================================================================
+
+func newToken() (string, []byte, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", nil, fmt.Errorf("generating token: %w", err)
+	}
+
+	secret := Prefix + base64.RawURLEncoding.EncodeToString(b)
+
+	return secret, hashToken(secret), nil
+}
+
+func hashToken(secret string) []byte {
+	sum := sha256.Sum256([]byte(secret))
+	return sum[:]
+}
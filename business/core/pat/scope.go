@@ -0,0 +1,78 @@
+package pat
+
+import (
+	"errors"
+	"sort"
+)
+
+// Set of possible scopes of a personal access token.
+var (
+	ScopeIdeasRead  = Scope{"ideas:read"}
+	ScopeIdeasWrite = Scope{"ideas:write"}
+	ScopeAIAsk      = Scope{"ai:ask"}
+)
+
+// Set of known scopes.
+var scopes = map[string]Scope{
+	ScopeIdeasRead.name:  ScopeIdeasRead,
+	ScopeIdeasWrite.name: ScopeIdeasWrite,
+	ScopeAIAsk.name:      ScopeAIAsk,
+}
+
+// Scope represents what a personal access token may be used for.
+type Scope struct {
+	name string
+}
+
+// ParseScope parses the string value and returns a scope if one exists.
+func ParseScope(value string) (Scope, error) {
+	scope, exists := scopes[value]
+	if !exists {
+		return Scope{}, errors.New("invalid scope")
+	}
+
+	return scope, nil
+}
+
+// MustParseScope parses the string value and returns a scope if one exists.
+// If an error occurs the function panics.
+func MustParseScope(value string) Scope {
+	scope, err := ParseScope(value)
+	if err != nil {
+		panic(err)
+	}
+
+	return scope
+}
+
+// Scopes returns the names of the known scopes, sorted.
+func Scopes() []string {
+	names := make([]string, 0, len(scopes))
+	for name := range scopes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// Name returns the name of the scope.
+func (s Scope) Name() string {
+	return s.name
+}
+
+// UnmarshalText implement the unmarshal interface for JSON conversions.
+func (s *Scope) UnmarshalText(data []byte) error {
+	s.name = string(data)
+	return nil
+}
+
+// MarshalText implement the marshal interface for JSON conversions.
+func (s Scope) MarshalText() ([]byte, error) {
+	return []byte(s.name), nil
+}
+
+// Equal provides support for the go-cmp package and testing.
+func (s Scope) Equal(s2 Scope) bool {
+	return s.name == s2.name
+}
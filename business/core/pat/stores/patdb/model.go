@@ -0,0 +1,84 @@
+package patdb
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/dmanias/startupers/business/core/pat"
+	"github.com/dmanias/startupers/business/sys/database/pgx/dbarray"
+	"github.com/google/uuid"
+)
+
+// dbToken represent the structure we need for moving data
+// between the app and the database.
+type dbToken struct {
+	ID           uuid.UUID      `db:"id"`
+	UserID       uuid.UUID      `db:"user_id"`
+	Name         string         `db:"name"`
+	Scopes       dbarray.String `db:"scopes"`
+	TokenHash    []byte         `db:"token_hash"`
+	DateExpires  sql.NullTime   `db:"date_expires"`
+	DateLastUsed sql.NullTime   `db:"date_last_used"`
+	DateRevoked  sql.NullTime   `db:"date_revoked"`
+	DateCreated  time.Time      `db:"date_created"`
+}
+
+func toDBToken(tkn pat.Token) dbToken {
+	scopes := make([]string, len(tkn.Scopes))
+	for i, scope := range tkn.Scopes {
+		scopes[i] = scope.Name()
+	}
+
+	return dbToken{
+		ID:           tkn.ID,
+		UserID:       tkn.UserID,
+		Name:         tkn.Name,
+		Scopes:       scopes,
+		TokenHash:    tkn.TokenHash,
+		DateExpires:  toNullTime(tkn.DateExpires),
+		DateLastUsed: toNullTime(tkn.DateLastUsed),
+		DateRevoked:  toNullTime(tkn.DateRevoked),
+		DateCreated:  tkn.DateCreated.UTC(),
+	}
+}
+
+func toCoreToken(dbTkn dbToken) pat.Token {
+	scopes := make([]pat.Scope, len(dbTkn.Scopes))
+	for i, value := range dbTkn.Scopes {
+		scopes[i] = pat.MustParseScope(value)
+	}
+
+	return pat.Token{
+		ID:           dbTkn.ID,
+		UserID:       dbTkn.UserID,
+		Name:         dbTkn.Name,
+		Scopes:       scopes,
+		TokenHash:    dbTkn.TokenHash,
+		DateExpires:  fromNullTime(dbTkn.DateExpires),
+		DateLastUsed: fromNullTime(dbTkn.DateLastUsed),
+		DateRevoked:  fromNullTime(dbTkn.DateRevoked),
+		DateCreated:  dbTkn.DateCreated.In(time.Local),
+	}
+}
+
+func toCoreTokenSlice(dbTkns []dbToken) []pat.Token {
+	tkns := make([]pat.Token, len(dbTkns))
+	for i, dbTkn := range dbTkns {
+		tkns[i] = toCoreToken(dbTkn)
+	}
+	return tkns
+}
+
+func toNullTime(t time.Time) sql.NullTime {
+	return sql.NullTime{
+		Time:  t.UTC(),
+		Valid: !t.IsZero(),
+	}
+}
+
+func fromNullTime(nt sql.NullTime) time.Time {
+	if !nt.Valid {
+		return time.Time{}
+	}
+	return nt.Time.In(time.Local)
+}
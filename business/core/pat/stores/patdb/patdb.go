@@ -0,0 +1,172 @@
+// Package patdb contains personal access token related CRUD functionality.
+package patdb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/dmanias/startupers/business/core/pat"
+	database "github.com/dmanias/startupers/business/sys/database/pgx"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"go.uber.org/zap"
+)
+
+// Store manages the set of APIs for personal access token database access.
+type Store struct {
+	log *zap.SugaredLogger
+	db  sqlx.ExtContext
+}
+
+// NewStore constructs the api for data access.
+func NewStore(log *zap.SugaredLogger, db *sqlx.DB) *Store {
+	return &Store{
+		log: log,
+		db:  db,
+	}
+}
+
+// Create inserts a new token into the database.
+func (s *Store) Create(ctx context.Context, tkn pat.Token) error {
+	const q = `
+	INSERT INTO personal_access_tokens
+		(id, user_id, name, scopes, token_hash, date_expires, date_last_used, date_revoked, date_created)
+	VALUES
+		(:id, :user_id, :name, :scopes, :token_hash, :date_expires, :date_last_used, :date_revoked, :date_created)`
+
+	if err := database.NamedExecContext(ctx, s.log, s.db, q, toDBToken(tkn)); err != nil {
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	return nil
+}
+
+// Revoke sets the revocation date of a token in the database. A token that
+// was already revoked keeps its first revocation date.
+func (s *Store) Revoke(ctx context.Context, tkn pat.Token) error {
+	const q = `
+	UPDATE
+		personal_access_tokens
+	SET
+		"date_revoked" = :date_revoked
+	WHERE
+		id = :id AND
+		date_revoked IS NULL`
+
+	if err := database.NamedExecContext(ctx, s.log, s.db, q, toDBToken(tkn)); err != nil {
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	return nil
+}
+
+// UpdateLastUsed sets when a token was last used. Tokens revoked in the
+// meantime are left alone.
+func (s *Store) UpdateLastUsed(ctx context.Context, tokenID uuid.UUID, lastUsed time.Time) error {
+	data := struct {
+		ID           uuid.UUID `db:"id"`
+		DateLastUsed time.Time `db:"date_last_used"`
+	}{
+		ID:           tokenID,
+		DateLastUsed: lastUsed.UTC(),
+	}
+
+	const q = `
+	UPDATE
+		personal_access_tokens
+	SET
+		"date_last_used" = :date_last_used
+	WHERE
+		id = :id AND
+		date_revoked IS NULL`
+
+	if err := database.NamedExecContext(ctx, s.log, s.db, q, data); err != nil {
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	return nil
+}
+
+// QueryByUserID gets the tokens of the user that weren't revoked from the
+// database, newest first.
+func (s *Store) QueryByUserID(ctx context.Context, userID uuid.UUID) ([]pat.Token, error) {
+	data := struct {
+		UserID uuid.UUID `db:"user_id"`
+	}{
+		UserID: userID,
+	}
+
+	const q = `
+	SELECT
+		*
+	FROM
+		personal_access_tokens
+	WHERE
+		user_id = :user_id AND
+		date_revoked IS NULL
+	ORDER BY
+		date_created DESC`
+
+	var dbTkns []dbToken
+	if err := database.NamedQuerySlice(ctx, s.log, s.db, q, data, &dbTkns); err != nil {
+		return nil, fmt.Errorf("namedqueryslice: %w", err)
+	}
+
+	return toCoreTokenSlice(dbTkns), nil
+}
+
+// QueryByID gets the specified token from the database.
+func (s *Store) QueryByID(ctx context.Context, tokenID uuid.UUID) (pat.Token, error) {
+	data := struct {
+		ID uuid.UUID `db:"id"`
+	}{
+		ID: tokenID,
+	}
+
+	const q = `
+	SELECT
+		*
+	FROM
+		personal_access_tokens
+	WHERE
+		id = :id`
+
+	var dbTkn dbToken
+	if err := database.NamedQueryStruct(ctx, s.log, s.db, q, data, &dbTkn); err != nil {
+		if errors.Is(err, database.ErrDBNotFound) {
+			return pat.Token{}, fmt.Errorf("namedquerystruct: %w", pat.ErrNotFound)
+		}
+		return pat.Token{}, fmt.Errorf("namedquerystruct: %w", err)
+	}
+
+	return toCoreToken(dbTkn), nil
+}
+
+// QueryByTokenHash gets the token with the hash from the database.
+func (s *Store) QueryByTokenHash(ctx context.Context, tokenHash []byte) (pat.Token, error) {
+	data := struct {
+		TokenHash []byte `db:"token_hash"`
+	}{
+		TokenHash: tokenHash,
+	}
+
+	const q = `
+	SELECT
+		*
+	FROM
+		personal_access_tokens
+	WHERE
+		token_hash = :token_hash`
+
+	var dbTkn dbToken
+	if err := database.NamedQueryStruct(ctx, s.log, s.db, q, data, &dbTkn); err != nil {
+		if errors.Is(err, database.ErrDBNotFound) {
+			return pat.Token{}, fmt.Errorf("namedquerystruct: %w", pat.ErrNotFound)
+		}
+		return pat.Token{}, fmt.Errorf("namedquerystruct: %w", err)
+	}
+
+	return toCoreToken(dbTkn), nil
+}
@@ -0,0 +1,73 @@
+package post
+
+import "errors"
+
+// Set of emoji users can react to a post with.
+var (
+	EmojiThumbsUp = Emoji{"thumbsup"}
+	EmojiHeart    = Emoji{"heart"}
+	EmojiLaugh    = Emoji{"laugh"}
+	EmojiTada     = Emoji{"tada"}
+	EmojiRocket   = Emoji{"rocket"}
+	EmojiEyes     = Emoji{"eyes"}
+	EmojiThinking = Emoji{"thinking"}
+)
+
+// Set of known emoji.
+var emojis = map[string]Emoji{
+	EmojiThumbsUp.name: EmojiThumbsUp,
+	EmojiHeart.name:    EmojiHeart,
+	EmojiLaugh.name:    EmojiLaugh,
+	EmojiTada.name:     EmojiTada,
+	EmojiRocket.name:   EmojiRocket,
+	EmojiEyes.name:     EmojiEyes,
+	EmojiThinking.name: EmojiThinking,
+}
+
+// Emoji represents a reaction users can leave on a post. Only the name is
+// stored; how it is drawn is up to the client.
+type Emoji struct {
+	name string
+}
+
+// ParseEmoji parses the string value and returns an emoji if one exists.
+func ParseEmoji(value string) (Emoji, error) {
+	emoji, exists := emojis[value]
+	if !exists {
+		return Emoji{}, errors.New("invalid emoji")
+	}
+
+	return emoji, nil
+}
+
+// MustParseEmoji parses the string value and returns an emoji if one exists.
+// If an error occurs the function panics.
+func MustParseEmoji(value string) Emoji {
+	emoji, err := ParseEmoji(value)
+	if err != nil {
+		panic(err)
+	}
+
+	return emoji
+}
+
+// Name returns the name of the emoji.
+func (e Emoji) Name() string {
+	return e.name
+}
+
+// UnmarshalText implement the unmarshal interface for JSON conversions.
+func (e *Emoji) UnmarshalText(data []byte) error {
+	e.name = string(data)
+	return nil
+}
+
+// MarshalText implement the marshal interface for JSON conversions.
+func (e Emoji) MarshalText() ([]byte, error) {
+	return []byte(e.name), nil
+}
+
+// Equal provides support for the go-cmp package and testing.
+func (e Emoji) Equal(e2 Emoji) bool {
+	return e.name == e2.name
+}
@@ -15,6 +15,8 @@ type QueryFilter struct {
 	OwnerType        *string    `validate:"omitempty"`
 	StartCreatedDate *time.Time `validate:"omitempty"`
 	EndCreatedDate   *time.Time `validate:"omitempty"`
+	Deleted          *bool      `validate:"omitempty"`
+	RestorableBy     *uuid.UUID `validate:"omitempty"`
 }
 
 func (qf *QueryFilter) Validate() error {
@@ -49,3 +51,17 @@ func (qf *QueryFilter) WithEndCreatedDate(endDate time.Time) {
 	d := endDate.UTC()
 	qf.EndCreatedDate = &d
 }
+
+// WithDeleted switches the result between the posts in the trash and the
+// ones that aren't. Posts in the trash are left out unless asked for.
+func (qf *QueryFilter) WithDeleted(deleted bool) {
+	qf.Deleted = &deleted
+}
+
+// WithRestorableBy restricts the result to the posts in the trash the user
+// can restore: those they moved there, wrote, or that belong to their ideas.
+func (qf *QueryFilter) WithRestorableBy(userID uuid.UUID) {
+	qf.RestorableBy = &userID
+	deleted := true
+	qf.Deleted = &deleted
+}
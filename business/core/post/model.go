@@ -6,21 +6,36 @@ import (
 	"github.com/google/uuid"
 )
 
+// OwnerTypeIdea is the owner type of the posts on an idea whose content is
+// the answer of the AI to the author's question.
+const OwnerTypeIdea = "idea"
+
 type Post struct {
-	ID          uuid.UUID
-	IdeaID      uuid.UUID
-	AuthorID    uuid.UUID
-	Content     string
-	OwnerType   string
-	DateCreated time.Time
-	DateUpdated time.Time
+	ID             uuid.UUID
+	IdeaID         uuid.UUID
+	AuthorID       uuid.UUID
+	Content        string
+	OwnerType      string
+	ForkedFrom     uuid.UUID
+	ReactionCount  int
+	ReactionCounts map[Emoji]int
+	DeletedAt      time.Time
+	DeletedBy      uuid.UUID
+	DateCreated    time.Time
+	DateUpdated    time.Time
+}
+
+// Deleted reports whether the post has been moved to the trash.
+func (p Post) Deleted() bool {
+	return !p.DeletedAt.IsZero()
 }
 
 type NewPost struct {
-	IdeaID    uuid.UUID
-	AuthorID  uuid.UUID
-	Content   string
-	OwnerType string
+	IdeaID     uuid.UUID
+	AuthorID   uuid.UUID
+	Content    string
+	OwnerType  string
+	ForkedFrom uuid.UUID
 }
 
 type UpdatePost struct {
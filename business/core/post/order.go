@@ -10,4 +10,6 @@ const (
 	OrderByAuthorID    = "authorid"
 	OrderByDateCreated = "datecreated"
 	OrderByDateUpdated = "dateupdated"
+	OrderByDateDeleted = "datedeleted"
+	OrderByVotes       = "votes"
 )
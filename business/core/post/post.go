@@ -6,25 +6,38 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/dmanias/startupers/business/core/activity"
 	"github.com/dmanias/startupers/business/data/order"
+	"github.com/dmanias/startupers/business/data/transaction"
 	"github.com/google/uuid"
 )
 
 var (
-	ErrNotFound = errors.New("post not found")
+	ErrNotFound         = errors.New("post not found")
+	ErrAlreadyReacted   = errors.New("user has already reacted to this post with this emoji")
+	ErrReactionNotFound = errors.New("reaction not found")
 )
 
 type Storer interface {
+	ExecuteUnderTransaction(tx transaction.Transaction) (Storer, error)
 	Create(ctx context.Context, post Post) error
 	Update(ctx context.Context, post Post) error
 	Delete(ctx context.Context, post Post) error
+	SetDeleted(ctx context.Context, post Post) error
+	DeleteByIdea(ctx context.Context, ideaID uuid.UUID) error
+	DeleteTrashedBefore(ctx context.Context, before time.Time) error
 	Query(ctx context.Context, filter QueryFilter, orderBy order.By, pageNumber int, rowsPerPage int) ([]Post, error)
 	Count(ctx context.Context, filter QueryFilter) (int, error)
 	QueryByID(ctx context.Context, postID uuid.UUID) (Post, error)
+	CreateReaction(ctx context.Context, reaction Reaction) error
+	DeleteReaction(ctx context.Context, postID uuid.UUID, userID uuid.UUID, emoji Emoji) error
+	QueryReactions(ctx context.Context, postID uuid.UUID, pageNumber int, rowsPerPage int) ([]Reaction, error)
+	CountReactions(ctx context.Context, postID uuid.UUID) (int, error)
 }
 
 type Core struct {
-	storer Storer
+	storer   Storer
+	activity []ActivityRecorder
 }
 
 func NewCore(storer Storer) *Core {
@@ -33,6 +46,23 @@ func NewCore(storer Storer) *Core {
 	}
 }
 
+// ExecuteUnderTransaction constructs a new Core value that will use the
+// specified transaction in any store related calls. Writes made through it
+// are not handed to the activity recorders, as the transaction may still be
+// rolled back.
+func (c *Core) ExecuteUnderTransaction(tx transaction.Transaction) (*Core, error) {
+	trS, err := c.storer.ExecuteUnderTransaction(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	c = &Core{
+		storer: trS,
+	}
+
+	return c, nil
+}
+
 func (c *Core) Create(ctx context.Context, np NewPost) (Post, error) {
 	now := time.Now()
 
@@ -42,6 +72,7 @@ func (c *Core) Create(ctx context.Context, np NewPost) (Post, error) {
 		AuthorID:    np.AuthorID,
 		Content:     np.Content,
 		OwnerType:   np.OwnerType,
+		ForkedFrom:  np.ForkedFrom,
 		DateCreated: now,
 		DateUpdated: now,
 	}
@@ -50,6 +81,14 @@ func (c *Core) Create(ctx context.Context, np NewPost) (Post, error) {
 		return Post{}, fmt.Errorf("create: %w", err)
 	}
 
+	c.record(ctx, activity.NewActivity{
+		Type:      activity.TypePostCreated,
+		ActorID:   post.AuthorID,
+		IdeaID:    post.IdeaID,
+		SubjectID: post.ID,
+		Details:   map[string]string{"ownerType": post.OwnerType},
+	})
+
 	return post, nil
 }
 
@@ -66,9 +105,51 @@ func (c *Core) Update(ctx context.Context, post Post, up UpdatePost) (Post, erro
 	return post, nil
 }
 
-func (c *Core) Delete(ctx context.Context, post Post) error {
-	if err := c.storer.Delete(ctx, post); err != nil {
-		return fmt.Errorf("delete: %w", err)
+// Delete moves the post to the trash on behalf of the user.
+func (c *Core) Delete(ctx context.Context, post Post, userID uuid.UUID) (Post, error) {
+	post.DeletedAt = time.Now()
+	post.DeletedBy = userID
+
+	if err := c.storer.SetDeleted(ctx, post); err != nil {
+		return Post{}, fmt.Errorf("delete: postID[%s]: %w", post.ID, err)
+	}
+
+	return post, nil
+}
+
+// Restore takes the post out of the trash.
+func (c *Core) Restore(ctx context.Context, post Post) (Post, error) {
+	post.DeletedAt = time.Time{}
+	post.DeletedBy = uuid.Nil
+
+	if err := c.storer.SetDeleted(ctx, post); err != nil {
+		return Post{}, fmt.Errorf("restore: postID[%s]: %w", post.ID, err)
+	}
+
+	return post, nil
+}
+
+// PurgeByIdea removes every post of the idea from the database for good,
+// including the ones in the trash, as part of the specified transaction.
+// Posts don't reference any files.
+func (c *Core) PurgeByIdea(ctx context.Context, tx transaction.Transaction, ideaID uuid.UUID) ([]string, error) {
+	storer, err := c.storer.ExecuteUnderTransaction(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := storer.DeleteByIdea(ctx, ideaID); err != nil {
+		return nil, fmt.Errorf("purgebyidea: ideaID[%s]: %w", ideaID, err)
+	}
+
+	return nil, nil
+}
+
+// PurgeTrashedBefore removes the posts moved to the trash before the
+// specified time from the database for good.
+func (c *Core) PurgeTrashedBefore(ctx context.Context, before time.Time) error {
+	if err := c.storer.DeleteTrashedBefore(ctx, before); err != nil {
+		return fmt.Errorf("purgetrashedbefore: %w", err)
 	}
 
 	return nil
@@ -87,6 +168,24 @@ func (c *Core) Count(ctx context.Context, filter QueryFilter) (int, error) {
 	return c.storer.Count(ctx, filter)
 }
 
+// QueryDeletedByID gets the specified post from the trash.
+func (c *Core) QueryDeletedByID(ctx context.Context, postID uuid.UUID) (Post, error) {
+	var filter QueryFilter
+	filter.WithPostID(postID)
+	filter.WithDeleted(true)
+
+	posts, err := c.storer.Query(ctx, filter, DefaultOrderBy, 1, 1)
+	if err != nil {
+		return Post{}, fmt.Errorf("query: postID[%s]: %w", postID, err)
+	}
+
+	if len(posts) == 0 {
+		return Post{}, fmt.Errorf("query: postID[%s]: %w", postID, ErrNotFound)
+	}
+
+	return posts[0], nil
+}
+
 func (c *Core) QueryByID(ctx context.Context, postID uuid.UUID) (Post, error) {
 	post, err := c.storer.QueryByID(ctx, postID)
 	if err != nil {
@@ -0,0 +1,61 @@
+package post
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Reaction records a user reacting to a post with an emoji. A user can react
+// with several emoji but with each one only once. The number of reactions is
+// kept on the post, in total and per emoji.
+type Reaction struct {
+	PostID      uuid.UUID
+	UserID      uuid.UUID
+	Emoji       Emoji
+	DateCreated time.Time
+}
+
+// React adds the user's reaction to the post and returns the post with its
+// updated reaction counts.
+func (c *Core) React(ctx context.Context, post Post, userID uuid.UUID, emoji Emoji) (Post, error) {
+	reaction := Reaction{
+		PostID:      post.ID,
+		UserID:      userID,
+		Emoji:       emoji,
+		DateCreated: time.Now(),
+	}
+
+	if err := c.storer.CreateReaction(ctx, reaction); err != nil {
+		return Post{}, fmt.Errorf("react: postID[%s]: %w", post.ID, err)
+	}
+
+	return c.QueryByID(ctx, post.ID)
+}
+
+// Unreact takes the user's reaction back and returns the post with its
+// updated reaction counts.
+func (c *Core) Unreact(ctx context.Context, post Post, userID uuid.UUID, emoji Emoji) (Post, error) {
+	if err := c.storer.DeleteReaction(ctx, post.ID, userID, emoji); err != nil {
+		return Post{}, fmt.Errorf("unreact: postID[%s]: %w", post.ID, err)
+	}
+
+	return c.QueryByID(ctx, post.ID)
+}
+
+// QueryReactions returns a page of the reactions to a post, newest first.
+func (c *Core) QueryReactions(ctx context.Context, postID uuid.UUID, pageNumber int, rowsPerPage int) ([]Reaction, error) {
+	reactions, err := c.storer.QueryReactions(ctx, postID, pageNumber, rowsPerPage)
+	if err != nil {
+		return nil, fmt.Errorf("queryreactions: postID[%s]: %w", postID, err)
+	}
+
+	return reactions, nil
+}
+
+// CountReactions returns the number of reactions to a post.
+func (c *Core) CountReactions(ctx context.Context, postID uuid.UUID) (int, error) {
+	return c.storer.CountReactions(ctx, postID)
+}
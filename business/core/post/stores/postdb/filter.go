@@ -25,7 +25,7 @@ func (s *Store) applyFilter(filter post.QueryFilter, data map[string]interface{}
 	}
 
 	if filter.OwnerType != nil {
-		data["owner_type"] = filter.AuthorID
+		data["owner_type"] = filter.OwnerType
 		wc = append(wc, "owner_type = :owner_type")
 	}
 
@@ -39,6 +39,17 @@ func (s *Store) applyFilter(filter post.QueryFilter, data map[string]interface{}
 		wc = append(wc, "date_created <= :end_date_created")
 	}
 
+	if filter.RestorableBy != nil {
+		data["restorable_by"] = filter.RestorableBy
+		wc = append(wc, "(deleted_by = :restorable_by OR author_id = :restorable_by OR idea_id IN (SELECT id FROM ideas WHERE user_id = :restorable_by))")
+	}
+
+	if filter.Deleted != nil && *filter.Deleted {
+		wc = append(wc, "deleted_at IS NOT NULL")
+	} else {
+		wc = append(wc, "deleted_at IS NULL")
+	}
+
 	if len(wc) > 0 {
 		buf.WriteString(" WHERE ")
 		buf.WriteString(strings.Join(wc, " AND "))
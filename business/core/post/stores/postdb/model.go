@@ -1,6 +1,8 @@
 package postdb
 
 import (
+	"database/sql"
+	"encoding/json"
 	"github.com/dmanias/startupers/business/core/post"
 	"time"
 
@@ -8,36 +10,75 @@ import (
 )
 
 type dbPost struct {
-	ID          uuid.UUID `db:"id"`
-	IdeaID      uuid.UUID `db:"idea_id"`
-	AuthorID    uuid.UUID `db:"author_id"`
-	Content     string    `db:"content"`
-	OwnerType   string    `db:"owner_type"`
-	DateCreated time.Time `db:"date_created"`
-	DateUpdated time.Time `db:"date_updated"`
+	ID             uuid.UUID     `db:"id"`
+	IdeaID         uuid.UUID     `db:"idea_id"`
+	AuthorID       uuid.UUID     `db:"author_id"`
+	Content        string        `db:"content"`
+	OwnerType      string        `db:"owner_type"`
+	ForkedFrom     uuid.NullUUID `db:"forked_from"`
+	ReactionCount  int           `db:"reaction_count"`
+	ReactionCounts string        `db:"reaction_counts"`
+	DeletedAt      sql.NullTime  `db:"deleted_at"`
+	DeletedBy      uuid.NullUUID `db:"deleted_by"`
+	DateCreated    time.Time     `db:"date_created"`
+	DateUpdated    time.Time     `db:"date_updated"`
 }
 
 func toDBPost(post post.Post) dbPost {
 	return dbPost{
-		ID:          post.ID,
-		IdeaID:      post.IdeaID,
-		AuthorID:    post.AuthorID,
-		Content:     post.Content,
-		OwnerType:   post.OwnerType,
+		ID:        post.ID,
+		IdeaID:    post.IdeaID,
+		AuthorID:  post.AuthorID,
+		Content:   post.Content,
+		OwnerType: post.OwnerType,
+		ForkedFrom: uuid.NullUUID{
+			UUID:  post.ForkedFrom,
+			Valid: post.ForkedFrom != uuid.Nil,
+		},
+		DeletedAt: sql.NullTime{
+			Time:  post.DeletedAt.UTC(),
+			Valid: post.Deleted(),
+		},
+		DeletedBy: uuid.NullUUID{
+			UUID:  post.DeletedBy,
+			Valid: post.DeletedBy != uuid.Nil,
+		},
 		DateCreated: post.DateCreated.UTC(),
 		DateUpdated: post.DateUpdated.UTC(),
 	}
 }
 
 func toCorePost(dbPost dbPost) post.Post {
+	var deletedAt time.Time
+	if dbPost.DeletedAt.Valid {
+		deletedAt = dbPost.DeletedAt.Time.In(time.Local)
+	}
+
+	// The counts are maintained by the database; emoji that are no longer
+	// offered are left out.
+	var byName map[string]int
+	_ = json.Unmarshal([]byte(dbPost.ReactionCounts), &byName)
+
+	counts := make(map[post.Emoji]int, len(byName))
+	for name, n := range byName {
+		if emoji, err := post.ParseEmoji(name); err == nil {
+			counts[emoji] = n
+		}
+	}
+
 	return post.Post{
-		ID:          dbPost.ID,
-		IdeaID:      dbPost.IdeaID,
-		AuthorID:    dbPost.AuthorID,
-		Content:     dbPost.Content,
-		OwnerType:   dbPost.OwnerType,
-		DateCreated: dbPost.DateCreated.In(time.Local),
-		DateUpdated: dbPost.DateUpdated.In(time.Local),
+		ID:             dbPost.ID,
+		IdeaID:         dbPost.IdeaID,
+		AuthorID:       dbPost.AuthorID,
+		Content:        dbPost.Content,
+		OwnerType:      dbPost.OwnerType,
+		ForkedFrom:     dbPost.ForkedFrom.UUID,
+		ReactionCount:  dbPost.ReactionCount,
+		ReactionCounts: counts,
+		DeletedAt:      deletedAt,
+		DeletedBy:      dbPost.DeletedBy.UUID,
+		DateCreated:    dbPost.DateCreated.In(time.Local),
+		DateUpdated:    dbPost.DateUpdated.In(time.Local),
 	}
 }
 
@@ -48,3 +89,34 @@ func toCorePostSlice(dbPosts []dbPost) []post.Post {
 	}
 	return posts
 }
+
+// =============================================================================
+
+type dbReaction struct {
+	PostID      uuid.UUID `db:"post_id"`
+	UserID      uuid.UUID `db:"user_id"`
+	Emoji       string    `db:"emoji"`
+	DateCreated time.Time `db:"date_created"`
+}
+
+func toDBReaction(reaction post.Reaction) dbReaction {
+	return dbReaction{
+		PostID:      reaction.PostID,
+		UserID:      reaction.UserID,
+		Emoji:       reaction.Emoji.Name(),
+		DateCreated: reaction.DateCreated.UTC(),
+	}
+}
+
+func toCoreReactionSlice(dbReactions []dbReaction) []post.Reaction {
+	reactions := make([]post.Reaction, len(dbReactions))
+	for i, dbReaction := range dbReactions {
+		reactions[i] = post.Reaction{
+			PostID:      dbReaction.PostID,
+			UserID:      dbReaction.UserID,
+			Emoji:       post.MustParseEmoji(dbReaction.Emoji),
+			DateCreated: dbReaction.DateCreated.In(time.Local),
+		}
+	}
+	return reactions
+}
@@ -12,6 +12,8 @@ var orderByFields = map[string]string{
 	post.OrderByAuthorID:    "author_id",
 	post.OrderByDateCreated: "date_created",
 	post.OrderByDateUpdated: "date_updated",
+	post.OrderByDateDeleted: "deleted_at",
+	post.OrderByVotes:       "reaction_count",
 }
 
 func orderByClause(orderBy order.By) (string, error) {
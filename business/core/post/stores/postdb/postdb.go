@@ -6,9 +6,12 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/dmanias/startupers/business/core/post"
 	"github.com/dmanias/startupers/business/data/order"
+	"github.com/dmanias/startupers/business/data/sqldb"
+	"github.com/dmanias/startupers/business/data/transaction"
 	database "github.com/dmanias/startupers/business/sys/database/pgx"
 	"github.com/google/uuid"
 	"github.com/jmoiron/sqlx"
@@ -17,7 +20,7 @@ import (
 
 type Store struct {
 	log *zap.SugaredLogger
-	db  *sqlx.DB
+	db  sqlx.ExtContext
 }
 
 func NewStore(log *zap.SugaredLogger, db *sqlx.DB) *Store {
@@ -27,12 +30,28 @@ func NewStore(log *zap.SugaredLogger, db *sqlx.DB) *Store {
 	}
 }
 
+// ExecuteUnderTransaction constructs a new Store value replacing the sqlx DB
+// value with a sqlx DB value that is currently inside a transaction.
+func (s *Store) ExecuteUnderTransaction(tx transaction.Transaction) (post.Storer, error) {
+	ec, err := sqldb.GetExtContext(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	s = &Store{
+		log: s.log,
+		db:  ec,
+	}
+
+	return s, nil
+}
+
 func (s *Store) Create(ctx context.Context, post post.Post) error {
 	const q = `
     INSERT INTO posts
-        (id, idea_id, author_id, content, owner_type, date_created, date_updated)
+        (id, idea_id, author_id, content, owner_type, forked_from, date_created, date_updated)
     VALUES
-        (:id, :idea_id, :author_id, :content, :owner_type, :date_created, :date_updated)`
+        (:id, :idea_id, :author_id, :content, :owner_type, :forked_from, :date_created, :date_updated)`
 
 	if err := database.NamedExecContext(ctx, s.log, s.db, q, toDBPost(post)); err != nil {
 		return fmt.Errorf("namedexeccontext: %w", err)
@@ -78,6 +97,69 @@ func (s *Store) Delete(ctx context.Context, post post.Post) error {
 	return nil
 }
 
+// SetDeleted records whether the post is in the trash, and who moved it
+// there.
+func (s *Store) SetDeleted(ctx context.Context, post post.Post) error {
+	const q = `
+    UPDATE
+        posts
+    SET
+        "deleted_at" = :deleted_at,
+        "deleted_by" = :deleted_by
+    WHERE
+        id = :id`
+
+	if err := database.NamedExecContext(ctx, s.log, s.db, q, toDBPost(post)); err != nil {
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteByIdea removes every post of the idea, including the ones in the
+// trash.
+func (s *Store) DeleteByIdea(ctx context.Context, ideaID uuid.UUID) error {
+	data := struct {
+		IdeaID string `db:"idea_id"`
+	}{
+		IdeaID: ideaID.String(),
+	}
+
+	const q = `
+    DELETE FROM
+        posts
+    WHERE
+        idea_id = :idea_id`
+
+	if err := database.NamedExecContext(ctx, s.log, s.db, q, data); err != nil {
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteTrashedBefore removes the posts moved to the trash before the
+// specified time.
+func (s *Store) DeleteTrashedBefore(ctx context.Context, before time.Time) error {
+	data := struct {
+		Before time.Time `db:"before"`
+	}{
+		Before: before.UTC(),
+	}
+
+	const q = `
+    DELETE FROM
+        posts
+    WHERE
+        deleted_at < :before`
+
+	if err := database.NamedExecContext(ctx, s.log, s.db, q, data); err != nil {
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	return nil
+}
+
 func (s *Store) Query(ctx context.Context, filter post.QueryFilter, orderBy order.By, pageNumber int, rowsPerPage int) ([]post.Post, error) {
 	data := map[string]interface{}{
 		"offset":        (pageNumber - 1) * rowsPerPage,
@@ -91,10 +173,7 @@ func (s *Store) Query(ctx context.Context, filter post.QueryFilter, orderBy orde
             posts`
 
 	buf := bytes.NewBufferString(q)
-	if filter.IdeaID != nil {
-		buf.WriteString(" WHERE idea_id = :idea_id")
-		data["idea_id"] = filter.IdeaID.String()
-	}
+	s.applyFilter(filter, data, buf)
 
 	orderByClause, err := orderByClause(orderBy)
 	if err != nil {
@@ -147,7 +226,8 @@ func (s *Store) QueryByID(ctx context.Context, postID uuid.UUID) (post.Post, err
     FROM
         posts
     WHERE
-        id = :id`
+        id = :id AND
+        deleted_at IS NULL`
 
 	var dbPost dbPost
 	if err := database.NamedQueryStruct(ctx, s.log, s.db, q, data, &dbPost); err != nil {
@@ -159,3 +239,110 @@ func (s *Store) QueryByID(ctx context.Context, postID uuid.UUID) (post.Post, err
 
 	return toCorePost(dbPost), nil
 }
+
+// CreateReaction records the user's reaction to the post. The reaction counts
+// on the post are kept up to date by the database.
+func (s *Store) CreateReaction(ctx context.Context, reaction post.Reaction) error {
+	const q = `
+    INSERT INTO post_reactions
+        (post_id, user_id, emoji, date_created)
+    VALUES
+        (:post_id, :user_id, :emoji, :date_created)`
+
+	if err := database.NamedExecContext(ctx, s.log, s.db, q, toDBReaction(reaction)); err != nil {
+		if errors.Is(err, database.ErrDBDuplicatedEntry) {
+			return fmt.Errorf("namedexeccontext: %w", post.ErrAlreadyReacted)
+		}
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteReaction removes the user's reaction with the emoji from the post.
+func (s *Store) DeleteReaction(ctx context.Context, postID uuid.UUID, userID uuid.UUID, emoji post.Emoji) error {
+	data := struct {
+		PostID string `db:"post_id"`
+		UserID string `db:"user_id"`
+		Emoji  string `db:"emoji"`
+	}{
+		PostID: postID.String(),
+		UserID: userID.String(),
+		Emoji:  emoji.Name(),
+	}
+
+	const q = `
+    DELETE FROM
+        post_reactions
+    WHERE
+        post_id = :post_id AND
+        user_id = :user_id AND
+        emoji = :emoji
+    RETURNING
+        post_id`
+
+	var deleted struct {
+		PostID uuid.UUID `db:"post_id"`
+	}
+	if err := database.NamedQueryStruct(ctx, s.log, s.db, q, data, &deleted); err != nil {
+		if errors.Is(err, database.ErrDBNotFound) {
+			return fmt.Errorf("namedquerystruct: %w", post.ErrReactionNotFound)
+		}
+		return fmt.Errorf("namedquerystruct: %w", err)
+	}
+
+	return nil
+}
+
+// QueryReactions retrieves a page of the reactions to a post, newest first.
+func (s *Store) QueryReactions(ctx context.Context, postID uuid.UUID, pageNumber int, rowsPerPage int) ([]post.Reaction, error) {
+	data := map[string]interface{}{
+		"post_id":       postID,
+		"offset":        (pageNumber - 1) * rowsPerPage,
+		"rows_per_page": rowsPerPage,
+	}
+
+	const q = `
+    SELECT
+        *
+    FROM
+        post_reactions
+    WHERE
+        post_id = :post_id
+    ORDER BY
+        date_created DESC, user_id, emoji
+    OFFSET :offset ROWS FETCH NEXT :rows_per_page ROWS ONLY`
+
+	var dbReactions []dbReaction
+	if err := database.NamedQuerySlice(ctx, s.log, s.db, q, data, &dbReactions); err != nil {
+		return nil, fmt.Errorf("namedqueryslice: %w", err)
+	}
+
+	return toCoreReactionSlice(dbReactions), nil
+}
+
+// CountReactions returns the number of reactions to a post.
+func (s *Store) CountReactions(ctx context.Context, postID uuid.UUID) (int, error) {
+	data := struct {
+		PostID string `db:"post_id"`
+	}{
+		PostID: postID.String(),
+	}
+
+	const q = `
+    SELECT
+        count(1)
+    FROM
+        post_reactions
+    WHERE
+        post_id = :post_id`
+
+	var count struct {
+		Count int `db:"count"`
+	}
+	if err := database.NamedQueryStruct(ctx, s.log, s.db, q, data, &count); err != nil {
+		return 0, fmt.Errorf("namedquerystruct: %w", err)
+	}
+
+	return count.Count, nil
+}
@@ -0,0 +1,62 @@
+package search
+
+import (
+	"fmt"
+
+	"github.com/dmanias/startupers/business/core/idea"
+	"github.com/dmanias/startupers/business/sys/validate"
+	"github.com/google/uuid"
+)
+
+// QueryFilter holds the search terms and the available fields the results can
+// be filtered on. Tag, category and stage apply to the idea a result belongs
+// to.
+type QueryFilter struct {
+	Query    string      `validate:"required,max=256"`
+	ViewerID uuid.UUID   `validate:"required"`
+	Kind     *Kind       `validate:"omitempty"`
+	Tag      *string     `validate:"omitempty"`
+	Category *string     `validate:"omitempty"`
+	Stage    *idea.Stage `validate:"omitempty"`
+}
+
+// Validate checks the data in the model is considered clean.
+func (qf *QueryFilter) Validate() error {
+	if err := validate.Check(qf); err != nil {
+		return fmt.Errorf("validate: %w", err)
+	}
+	return nil
+}
+
+// WithQuery sets the search terms. The syntax accepted is the one of web
+// search engines: quoted phrases, "or" and "-" to exclude a word.
+func (qf *QueryFilter) WithQuery(query string) {
+	qf.Query = query
+}
+
+// WithViewerID restricts the result to the ideas, and their posts and
+// challenges, the specified user is allowed to see in a listing.
+func (qf *QueryFilter) WithViewerID(userID uuid.UUID) {
+	qf.ViewerID = userID
+}
+
+// WithKind sets the Kind field of the QueryFilter value.
+func (qf *QueryFilter) WithKind(kind Kind) {
+	qf.Kind = &kind
+}
+
+// WithTag sets the Tag field of the QueryFilter value.
+func (qf *QueryFilter) WithTag(tag string) {
+	qf.Tag = &tag
+}
+
+// WithCategory sets the Category field of the QueryFilter value. Ideas in
+// its subcategories match as well.
+func (qf *QueryFilter) WithCategory(category string) {
+	qf.Category = &category
+}
+
+// WithStage sets the Stage field of the QueryFilter value.
+func (qf *QueryFilter) WithStage(stage idea.Stage) {
+	qf.Stage = &stage
+}
@@ -0,0 +1,64 @@
+package search
+
+import "errors"
+
+// Set of possible kinds of search results.
+var (
+	KindIdea      = Kind{"idea"}
+	KindPost      = Kind{"post"}
+	KindChallenge = Kind{"challenge"}
+)
+
+// Set of known kinds.
+var kinds = map[string]Kind{
+	KindIdea.name:      KindIdea,
+	KindPost.name:      KindPost,
+	KindChallenge.name: KindChallenge,
+}
+
+// Kind represents the type of record a search result points to.
+type Kind struct {
+	name string
+}
+
+// ParseKind parses the string value and returns a kind if one exists.
+func ParseKind(value string) (Kind, error) {
+	kind, exists := kinds[value]
+	if !exists {
+		return Kind{}, errors.New("invalid kind")
+	}
+
+	return kind, nil
+}
+
+// MustParseKind parses the string value and returns a kind if one exists. If
+// an error occurs the function panics.
+func MustParseKind(value string) Kind {
+	kind, err := ParseKind(value)
+	if err != nil {
+		panic(err)
+	}
+
+	return kind
+}
+
+// Name returns the name of the kind.
+func (k Kind) Name() string {
+	return k.name
+}
+
+// UnmarshalText implement the unmarshal interface for JSON conversions.
+func (k *Kind) UnmarshalText(data []byte) error {
+	k.name = string(data)
+	return nil
+}
+
+// MarshalText implement the marshal interface for JSON conversions.
+func (k Kind) MarshalText() ([]byte, error) {
+	return []byte(k.name), nil
+}
+
+// Equal provides support for the go-cmp package and testing.
+func (k Kind) Equal(k2 Kind) bool {
+	return k.name == k2.name
+}
@@ -0,0 +1,19 @@
+package search
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Result represents a record matching a search. Posts and challenges carry the
+// title of the idea they belong to.
+type Result struct {
+	Kind        Kind
+	ID          uuid.UUID
+	IdeaID      uuid.UUID
+	Title       string
+	Snippet     string
+	Rank        float64
+	DateCreated time.Time
+}
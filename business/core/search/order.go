@@ -0,0 +1,13 @@
+package search
+
+import "github.com/dmanias/startupers/business/data/order"
+
+// DefaultOrderBy represents the default way we sort.
+var DefaultOrderBy = order.NewBy(OrderByRank, order.DESC)
+
+// Set of fields that the results can be ordered by. These are the names
+// that should be used by the application layer.
+const (
+	OrderByRank        = "rank"
+	OrderByDateCreated = "datecreated"
+)
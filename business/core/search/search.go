@@ -0,0 +1,51 @@
+// Package search provides the business API for full-text search across
+// ideas, posts and challenges.
+package search
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/dmanias/startupers/business/data/order"
+)
+
+// Storer interface declares the behavior this package needs to retrieve data.
+type Storer interface {
+	Query(ctx context.Context, filter QueryFilter, orderBy order.By, pageNumber int, rowsPerPage int) ([]Result, error)
+	Count(ctx context.Context, filter QueryFilter) (int, error)
+}
+
+// Core manages the set of APIs for search access.
+type Core struct {
+	storer Storer
+}
+
+// NewCore constructs a core for search api access.
+func NewCore(storer Storer) *Core {
+	return &Core{
+		storer: storer,
+	}
+}
+
+// Query returns the records matching the search the viewer is allowed to see.
+func (c *Core) Query(ctx context.Context, filter QueryFilter, orderBy order.By, pageNumber int, rowsPerPage int) ([]Result, error) {
+	if err := filter.Validate(); err != nil {
+		return nil, err
+	}
+
+	results, err := c.storer.Query(ctx, filter, orderBy, pageNumber, rowsPerPage)
+	if err != nil {
+		return nil, fmt.Errorf("query: %w", err)
+	}
+
+	return results, nil
+}
+
+// Count returns the total number of records matching the search.
+func (c *Core) Count(ctx context.Context, filter QueryFilter) (int, error) {
+	if err := filter.Validate(); err != nil {
+		return 0, err
+	}
+
+	return c.storer.Count(ctx, filter)
+}
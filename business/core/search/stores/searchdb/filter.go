@@ -0,0 +1,43 @@
+package searchdb
+
+import (
+	"bytes"
+	"strings"
+
+	"github.com/dmanias/startupers/business/core/idea/stores/ideadb"
+	"github.com/dmanias/startupers/business/core/search"
+)
+
+// applyFilter adds the conditions for the search. The query is expected to
+// join search_documents as sd with the ideas table as i and the parsed search
+// terms as q.
+func (s *Store) applyFilter(filter search.QueryFilter, data map[string]interface{}, buf *bytes.Buffer) {
+	wc := []string{
+		"sd.document @@ q.query",
+		"i.deleted_at IS NULL",
+		ideadb.VisibleClause("i", filter.ViewerID, data),
+	}
+
+	if filter.Kind != nil {
+		data["kind"] = filter.Kind.Name()
+		wc = append(wc, "sd.kind = :kind")
+	}
+
+	if filter.Tag != nil {
+		data["tag"] = *filter.Tag
+		wc = append(wc, "i.tags @> ARRAY[:tag]")
+	}
+
+	if filter.Category != nil {
+		data["category"] = *filter.Category
+		wc = append(wc, "(i.category = :category OR i.category IN (SELECT category_subtree(:category)))")
+	}
+
+	if filter.Stage != nil {
+		data["stage"] = filter.Stage.Name()
+		wc = append(wc, "i.stage = :stage")
+	}
+
+	buf.WriteString(" WHERE ")
+	buf.WriteString(strings.Join(wc, " AND "))
+}
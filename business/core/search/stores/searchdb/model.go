@@ -0,0 +1,34 @@
+package searchdb
+
+import (
+	"time"
+
+	"github.com/dmanias/startupers/business/core/search"
+	"github.com/google/uuid"
+)
+
+type dbResult struct {
+	Kind        string    `db:"kind"`
+	ID          uuid.UUID `db:"id"`
+	IdeaID      uuid.UUID `db:"idea_id"`
+	Title       string    `db:"title"`
+	Snippet     string    `db:"snippet"`
+	Rank        float64   `db:"rank"`
+	DateCreated time.Time `db:"date_created"`
+}
+
+func toCoreResultSlice(dbResults []dbResult) []search.Result {
+	results := make([]search.Result, len(dbResults))
+	for i, dbRes := range dbResults {
+		results[i] = search.Result{
+			Kind:        search.MustParseKind(dbRes.Kind),
+			ID:          dbRes.ID,
+			IdeaID:      dbRes.IdeaID,
+			Title:       dbRes.Title,
+			Snippet:     dbRes.Snippet,
+			Rank:        dbRes.Rank,
+			DateCreated: dbRes.DateCreated.In(time.Local),
+		}
+	}
+	return results
+}
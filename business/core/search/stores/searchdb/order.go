@@ -0,0 +1,22 @@
+package searchdb
+
+import (
+	"fmt"
+
+	"github.com/dmanias/startupers/business/core/search"
+	"github.com/dmanias/startupers/business/data/order"
+)
+
+var orderByFields = map[string]string{
+	search.OrderByRank:        "rank",
+	search.OrderByDateCreated: "date_created",
+}
+
+func orderByClause(orderBy order.By) (string, error) {
+	by, exists := orderByFields[orderBy.Field]
+	if !exists {
+		return "", fmt.Errorf("field %q does not exist", orderBy.Field)
+	}
+
+	return " ORDER BY " + by + " " + orderBy.Direction + ", id", nil
+}
@@ -0,0 +1,108 @@
+// Package searchdb contains full-text search functionality.
+package searchdb
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+
+	"github.com/dmanias/startupers/business/core/search"
+	"github.com/dmanias/startupers/business/data/order"
+	database "github.com/dmanias/startupers/business/sys/database/pgx"
+	"github.com/jmoiron/sqlx"
+	"go.uber.org/zap"
+)
+
+// headlineOptions controls the snippets returned with the results. The
+// matched words are wrapped in <mark> tags; the rest of the text is HTML
+// escaped before the snippet is built.
+const headlineOptions = "StartSel=<mark>, StopSel=</mark>, MaxWords=35, MinWords=15, MaxFragments=2"
+
+// Store manages the set of APIs for search database access.
+type Store struct {
+	log *zap.SugaredLogger
+	db  sqlx.ExtContext
+}
+
+// NewStore constructs the api for data access.
+func NewStore(log *zap.SugaredLogger, db *sqlx.DB) *Store {
+	return &Store{
+		log: log,
+		db:  db,
+	}
+}
+
+// Query retrieves the ranked documents matching the search.
+func (s *Store) Query(ctx context.Context, filter search.QueryFilter, orderBy order.By, pageNumber int, rowsPerPage int) ([]search.Result, error) {
+	data := map[string]interface{}{
+		"query":            filter.Query,
+		"headline_options": headlineOptions,
+		"offset":           (pageNumber - 1) * rowsPerPage,
+		"rows_per_page":    rowsPerPage,
+	}
+
+	// The page is selected first so snippets are only built for the rows
+	// that are returned.
+	const q = `
+	SELECT
+		r.kind, r.id, r.idea_id, r.title, r.rank, r.date_created,
+		ts_headline(search_language(), replace(replace(replace(r.body, '&', '&amp;'), '<', '&lt;'), '>', '&gt;'), r.query, :headline_options) AS snippet
+	FROM (
+		SELECT
+			sd.kind, sd.id, sd.idea_id, i.title, sd.body, sd.date_created, q.query,
+			ts_rank_cd(sd.document, q.query) AS rank
+		FROM
+			search_documents AS sd
+		JOIN
+			ideas AS i ON i.id = sd.idea_id
+		CROSS JOIN
+			websearch_to_tsquery(search_language(), :query) AS q(query)`
+
+	buf := bytes.NewBufferString(q)
+	s.applyFilter(filter, data, buf)
+
+	orderByClause, err := orderByClause(orderBy)
+	if err != nil {
+		return nil, err
+	}
+
+	buf.WriteString(orderByClause)
+	buf.WriteString(" OFFSET :offset ROWS FETCH NEXT :rows_per_page ROWS ONLY) AS r")
+	buf.WriteString(orderByClause)
+
+	var dbResults []dbResult
+	if err := database.NamedQuerySlice(ctx, s.log, s.db, buf.String(), data, &dbResults); err != nil {
+		return nil, fmt.Errorf("namedqueryslice: %w", err)
+	}
+
+	return toCoreResultSlice(dbResults), nil
+}
+
+// Count returns the total number of documents matching the search.
+func (s *Store) Count(ctx context.Context, filter search.QueryFilter) (int, error) {
+	data := map[string]interface{}{
+		"query": filter.Query,
+	}
+
+	const q = `
+	SELECT
+		count(1)
+	FROM
+		search_documents AS sd
+	JOIN
+		ideas AS i ON i.id = sd.idea_id
+	CROSS JOIN
+		websearch_to_tsquery(search_language(), :query) AS q(query)`
+
+	buf := bytes.NewBufferString(q)
+	s.applyFilter(filter, data, buf)
+
+	var count struct {
+		Count int `db:"count"`
+	}
+	if err := database.NamedQueryStruct(ctx, s.log, s.db, buf.String(), data, &count); err != nil {
+		return 0, fmt.Errorf("namedquerystruct: %w", err)
+	}
+
+	return count.Count, nil
+}
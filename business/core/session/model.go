@@ -0,0 +1,49 @@
+package session
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Device describes what a refresh token was issued to.
+type Device struct {
+	UserAgent string
+	IPAddress string
+}
+
+// RefreshToken lets a client get a new access token without signing in
+// again. Every refresh replaces the token with a new one of the same family;
+// a family is one sign-in on one device. Only the hash of the secret is kept.
+type RefreshToken struct {
+	ID                uuid.UUID
+	FamilyID          uuid.UUID
+	UserID            uuid.UUID
+	TokenHash         []byte
+	Device            Device
+	AccessTokenID     string
+	AccessTokenExpiry time.Time
+	DateExpires       time.Time
+	DateUsed          time.Time
+	DateRevoked       time.Time
+	DateCreated       time.Time
+}
+
+// Used reports whether the token was already exchanged for a new one.
+func (rt RefreshToken) Used() bool {
+	return !rt.DateUsed.IsZero()
+}
+
+// Revoked reports whether the token's family was revoked.
+func (rt RefreshToken) Revoked() bool {
+	return !rt.DateRevoked.IsZero()
+}
+
+// NewRefreshToken contains what is needed to issue a refresh token alongside
+// an access token.
+type NewRefreshToken struct {
+	UserID            uuid.UUID
+	Device            Device
+	AccessTokenID     string
+	AccessTokenExpiry time.Time
+}
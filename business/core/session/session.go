@@ -0,0 +1,230 @@
+// Package session provides the business API for the sessions of signed in
+// users. A session is kept alive with rotating refresh tokens, and ended by
+// revoking its tokens. Access tokens of ended sessions are put on a denylist
+// until they expire.
+package session
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/dmanias/startupers/business/data/transaction"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// RefreshTTL is how long a refresh token can be used after it was issued.
+const RefreshTTL = 30 * 24 * time.Hour
+
+// Set of error variables for CRUD operations.
+var (
+	ErrInvalidToken = errors.New("refresh token is invalid or has expired")
+	ErrTokenReused  = errors.New("refresh token was already used")
+)
+
+// Storer interface declares the behavior this package needs to perists and
+// retrieve data.
+type Storer interface {
+	ExecuteUnderTransaction(tx transaction.Transaction) (Storer, error)
+	Create(ctx context.Context, rt RefreshToken) error
+	MarkUsed(ctx context.Context, rt RefreshToken) error
+	QueryByTokenHash(ctx context.Context, tokenHash []byte) (RefreshToken, error)
+	RevokeFamily(ctx context.Context, familyID uuid.UUID, now time.Time) error
+	RevokeUser(ctx context.Context, userID uuid.UUID, now time.Time) error
+	IsDenied(ctx context.Context, accessTokenID string) (bool, error)
+	Purge(ctx context.Context, now time.Time) error
+}
+
+// Core manages the set of APIs for session access.
+type Core struct {
+	log    *zap.SugaredLogger
+	storer Storer
+}
+
+// NewCore constructs a core for session api access.
+func NewCore(log *zap.SugaredLogger, storer Storer) *Core {
+	return &Core{
+		log:    log,
+		storer: storer,
+	}
+}
+
+// ExecuteUnderTransaction constructs a new Core value that will use the
+// specified transaction in any store related calls.
+func (c *Core) ExecuteUnderTransaction(tx transaction.Transaction) (*Core, error) {
+	trS, err := c.storer.ExecuteUnderTransaction(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	c = &Core{
+		log:    c.log,
+		storer: trS,
+	}
+
+	return c, nil
+}
+
+// Issue starts a new session and returns its first refresh token. The
+// returned secret is the only copy; just its hash is stored.
+func (c *Core) Issue(ctx context.Context, nrt NewRefreshToken) (RefreshToken, string, error) {
+	rt, token, err := c.create(ctx, uuid.New(), nrt)
+	if err != nil {
+		return RefreshToken{}, "", fmt.Errorf("issue: userID[%s]: %w", nrt.UserID, err)
+	}
+
+	return rt, token, nil
+}
+
+// Rotate exchanges a refresh token for a new one of the same session. A token
+// can only be exchanged once: presenting it again means it was stolen, and
+// the whole session is revoked.
+func (c *Core) Rotate(ctx context.Context, token string, nrt NewRefreshToken) (RefreshToken, string, error) {
+	rt, err := c.storer.QueryByTokenHash(ctx, hashToken(token))
+	if err != nil {
+		return RefreshToken{}, "", fmt.Errorf("rotate: %w", err)
+	}
+
+	now := time.Now()
+
+	switch {
+	case rt.Revoked():
+		return RefreshToken{}, "", fmt.Errorf("rotate: familyID[%s]: %w", rt.FamilyID, ErrInvalidToken)
+
+	case rt.Used():
+		return RefreshToken{}, "", c.revokeReused(ctx, rt, now)
+
+	case !now.Before(rt.DateExpires):
+		return RefreshToken{}, "", fmt.Errorf("rotate: familyID[%s]: %w", rt.FamilyID, ErrInvalidToken)
+	}
+
+	rt.DateUsed = now
+	if err := c.storer.MarkUsed(ctx, rt); err != nil {
+		// Another request exchanged the token first.
+		if errors.Is(err, ErrTokenReused) {
+			return RefreshToken{}, "", c.revokeReused(ctx, rt, now)
+		}
+		return RefreshToken{}, "", fmt.Errorf("rotate: familyID[%s]: %w", rt.FamilyID, err)
+	}
+
+	nrt.UserID = rt.UserID
+
+	next, nextToken, err := c.create(ctx, rt.FamilyID, nrt)
+	if err != nil {
+		return RefreshToken{}, "", fmt.Errorf("rotate: familyID[%s]: %w", rt.FamilyID, err)
+	}
+
+	return next, nextToken, nil
+}
+
+// Revoke ends the session the refresh token belongs to. Revoking a token
+// that doesn't exist, or was already revoked, changes nothing.
+func (c *Core) Revoke(ctx context.Context, token string) error {
+	rt, err := c.storer.QueryByTokenHash(ctx, hashToken(token))
+	if err != nil {
+		if errors.Is(err, ErrInvalidToken) {
+			return nil
+		}
+		return fmt.Errorf("revoke: %w", err)
+	}
+
+	if err := c.storer.RevokeFamily(ctx, rt.FamilyID, time.Now()); err != nil {
+		return fmt.Errorf("revoke: familyID[%s]: %w", rt.FamilyID, err)
+	}
+
+	return nil
+}
+
+// RevokeUser ends every session of the user, such as after a password reset
+// or when an account is compromised.
+func (c *Core) RevokeUser(ctx context.Context, userID uuid.UUID) error {
+	if err := c.storer.RevokeUser(ctx, userID, time.Now()); err != nil {
+		return fmt.Errorf("revokeuser: userID[%s]: %w", userID, err)
+	}
+
+	return nil
+}
+
+// IsDenied reports whether the access token with the ID belongs to a session
+// that was revoked before the token expired.
+func (c *Core) IsDenied(ctx context.Context, accessTokenID string) (bool, error) {
+	denied, err := c.storer.IsDenied(ctx, accessTokenID)
+	if err != nil {
+		return false, fmt.Errorf("isdenied: jti[%s]: %w", accessTokenID, err)
+	}
+
+	return denied, nil
+}
+
+// Purge removes the refresh tokens and the denylist entries that have
+// expired, as they can no longer be used.
+func (c *Core) Purge(ctx context.Context) error {
+	if err := c.storer.Purge(ctx, time.Now()); err != nil {
+		return fmt.Errorf("purge: %w", err)
+	}
+
+	return nil
+}
+
+// =============================================================================
+
+// create stores a new refresh token of the family and returns the secret.
+func (c *Core) create(ctx context.Context, familyID uuid.UUID, nrt NewRefreshToken) (RefreshToken, string, error) {
+	token, tokenHash, err := newToken()
+	if err != nil {
+		return RefreshToken{}, "", err
+	}
+
+	now := time.Now()
+
+	rt := RefreshToken{
+		ID:                uuid.New(),
+		FamilyID:          familyID,
+		UserID:            nrt.UserID,
+		TokenHash:         tokenHash,
+		Device:            nrt.Device,
+		AccessTokenID:     nrt.AccessTokenID,
+		AccessTokenExpiry: nrt.AccessTokenExpiry,
+		DateExpires:       now.Add(RefreshTTL),
+		DateCreated:       now,
+	}
+
+	if err := c.storer.Create(ctx, rt); err != nil {
+		return RefreshToken{}, "", fmt.Errorf("create: %w", err)
+	}
+
+	return rt, token, nil
+}
+
+// revokeReused revokes the session of a refresh token that was presented
+// again after it had been exchanged.
+func (c *Core) revokeReused(ctx context.Context, rt RefreshToken, now time.Time) error {
+	c.log.Infow("session", "status", "refresh token reused, revoking session", "userID", rt.UserID, "familyID", rt.FamilyID)
+
+	if err := c.storer.RevokeFamily(ctx, rt.FamilyID, now); err != nil {
+		return fmt.Errorf("rotate: familyID[%s]: %w", rt.FamilyID, err)
+	}
+
+	return fmt.Errorf("rotate: familyID[%s]: %w", rt.FamilyID, ErrTokenReused)
+}
+
+func newToken() (string, []byte, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", nil, fmt.Errorf("generating token: %w", err)
+	}
+
+	token := base64.RawURLEncoding.EncodeToString(b)
+
+	return token, hashToken(token), nil
+}
+
+func hashToken(token string) []byte {
+	sum := sha256.Sum256([]byte(token))
+	return sum[:]
+}
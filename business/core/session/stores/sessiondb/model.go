@@ -0,0 +1,78 @@
+package sessiondb
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/dmanias/startupers/business/core/session"
+	"github.com/google/uuid"
+)
+
+// dbRefreshToken represent the structure we need for moving data
+// between the app and the database.
+type dbRefreshToken struct {
+	ID                uuid.UUID    `db:"id"`
+	FamilyID          uuid.UUID    `db:"family_id"`
+	UserID            uuid.UUID    `db:"user_id"`
+	TokenHash         []byte       `db:"token_hash"`
+	UserAgent         string       `db:"user_agent"`
+	IPAddress         string       `db:"ip_address"`
+	AccessTokenID     string       `db:"access_token_id"`
+	AccessTokenExpiry time.Time    `db:"access_token_expiry"`
+	DateExpires       time.Time    `db:"date_expires"`
+	DateUsed          sql.NullTime `db:"date_used"`
+	DateRevoked       sql.NullTime `db:"date_revoked"`
+	DateCreated       time.Time    `db:"date_created"`
+}
+
+func toDBRefreshToken(rt session.RefreshToken) dbRefreshToken {
+	return dbRefreshToken{
+		ID:                rt.ID,
+		FamilyID:          rt.FamilyID,
+		UserID:            rt.UserID,
+		TokenHash:         rt.TokenHash,
+		UserAgent:         rt.Device.UserAgent,
+		IPAddress:         rt.Device.IPAddress,
+		AccessTokenID:     rt.AccessTokenID,
+		AccessTokenExpiry: rt.AccessTokenExpiry.UTC(),
+		DateExpires:       rt.DateExpires.UTC(),
+		DateUsed: sql.NullTime{
+			Time:  rt.DateUsed.UTC(),
+			Valid: !rt.DateUsed.IsZero(),
+		},
+		DateRevoked: sql.NullTime{
+			Time:  rt.DateRevoked.UTC(),
+			Valid: !rt.DateRevoked.IsZero(),
+		},
+		DateCreated: rt.DateCreated.UTC(),
+	}
+}
+
+func toCoreRefreshToken(dbRT dbRefreshToken) session.RefreshToken {
+	var dateUsed time.Time
+	if dbRT.DateUsed.Valid {
+		dateUsed = dbRT.DateUsed.Time.In(time.Local)
+	}
+
+	var dateRevoked time.Time
+	if dbRT.DateRevoked.Valid {
+		dateRevoked = dbRT.DateRevoked.Time.In(time.Local)
+	}
+
+	return session.RefreshToken{
+		ID:        dbRT.ID,
+		FamilyID:  dbRT.FamilyID,
+		UserID:    dbRT.UserID,
+		TokenHash: dbRT.TokenHash,
+		Device: session.Device{
+			UserAgent: dbRT.UserAgent,
+			IPAddress: dbRT.IPAddress,
+		},
+		AccessTokenID:     dbRT.AccessTokenID,
+		AccessTokenExpiry: dbRT.AccessTokenExpiry.In(time.Local),
+		DateExpires:       dbRT.DateExpires.In(time.Local),
+		DateUsed:          dateUsed,
+		DateRevoked:       dateRevoked,
+		DateCreated:       dbRT.DateCreated.In(time.Local),
+	}
+}
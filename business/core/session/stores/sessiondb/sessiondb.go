@@ -0,0 +1,265 @@
+// Package sessiondb contains refresh token and access token denylist related
+// CRUD functionality.
+package sessiondb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/dmanias/startupers/business/core/session"
+	"github.com/dmanias/startupers/business/data/sqldb"
+	"github.com/dmanias/startupers/business/data/transaction"
+	database "github.com/dmanias/startupers/business/sys/database/pgx"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"go.uber.org/zap"
+)
+
+// Store manages the set of APIs for session database access.
+type Store struct {
+	log *zap.SugaredLogger
+	db  sqlx.ExtContext
+}
+
+// NewStore constructs the api for data access.
+func NewStore(log *zap.SugaredLogger, db *sqlx.DB) *Store {
+	return &Store{
+		log: log,
+		db:  db,
+	}
+}
+
+// ExecuteUnderTransaction constructs a new Store value replacing the sqlx DB
+// value with a sqlx DB value that is currently inside a transaction.
+func (s *Store) ExecuteUnderTransaction(tx transaction.Transaction) (session.Storer, error) {
+	ec, err := sqldb.GetExtContext(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	s = &Store{
+		log: s.log,
+		db:  ec,
+	}
+
+	return s, nil
+}
+
+// Create inserts a new refresh token into the database.
+func (s *Store) Create(ctx context.Context, rt session.RefreshToken) error {
+	const q = `
+	INSERT INTO refresh_tokens
+		(id, family_id, user_id, token_hash, user_agent, ip_address, access_token_id, access_token_expiry, date_expires, date_used, date_revoked, date_created)
+	VALUES
+		(:id, :family_id, :user_id, :token_hash, :user_agent, :ip_address, :access_token_id, :access_token_expiry, :date_expires, :date_used, :date_revoked, :date_created)`
+
+	if err := database.NamedExecContext(ctx, s.log, s.db, q, toDBRefreshToken(rt)); err != nil {
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	return nil
+}
+
+// MarkUsed records that the refresh token was exchanged. Checking and marking
+// happen in one statement, so a token that another request exchanged first,
+// or that was revoked meanwhile, is reported as reused.
+func (s *Store) MarkUsed(ctx context.Context, rt session.RefreshToken) error {
+	data := struct {
+		ID       uuid.UUID `db:"id"`
+		DateUsed time.Time `db:"date_used"`
+	}{
+		ID:       rt.ID,
+		DateUsed: rt.DateUsed.UTC(),
+	}
+
+	const q = `
+	UPDATE
+		refresh_tokens
+	SET
+		"date_used" = :date_used
+	WHERE
+		id = :id AND
+		date_used IS NULL AND
+		date_revoked IS NULL
+	RETURNING
+		id`
+
+	var marked struct {
+		ID uuid.UUID `db:"id"`
+	}
+	if err := database.NamedQueryStruct(ctx, s.log, s.db, q, data, &marked); err != nil {
+		if errors.Is(err, database.ErrDBNotFound) {
+			return fmt.Errorf("namedquerystruct: %w", session.ErrTokenReused)
+		}
+		return fmt.Errorf("namedquerystruct: %w", err)
+	}
+
+	return nil
+}
+
+// QueryByTokenHash gets the refresh token with the hash from the database.
+func (s *Store) QueryByTokenHash(ctx context.Context, tokenHash []byte) (session.RefreshToken, error) {
+	data := struct {
+		TokenHash []byte `db:"token_hash"`
+	}{
+		TokenHash: tokenHash,
+	}
+
+	const q = `
+	SELECT
+		*
+	FROM
+		refresh_tokens
+	WHERE
+		token_hash = :token_hash`
+
+	var dbRT dbRefreshToken
+	if err := database.NamedQueryStruct(ctx, s.log, s.db, q, data, &dbRT); err != nil {
+		if errors.Is(err, database.ErrDBNotFound) {
+			return session.RefreshToken{}, fmt.Errorf("namedquerystruct: %w", session.ErrInvalidToken)
+		}
+		return session.RefreshToken{}, fmt.Errorf("namedquerystruct: %w", err)
+	}
+
+	return toCoreRefreshToken(dbRT), nil
+}
+
+// RevokeFamily revokes every refresh token of the family, and puts the access
+// tokens issued with them that haven't expired yet on the denylist.
+func (s *Store) RevokeFamily(ctx context.Context, familyID uuid.UUID, now time.Time) error {
+	data := struct {
+		FamilyID uuid.UUID `db:"family_id"`
+		Now      time.Time `db:"now"`
+	}{
+		FamilyID: familyID,
+		Now:      now.UTC(),
+	}
+
+	const q = `
+	WITH revoked AS (
+		UPDATE
+			refresh_tokens
+		SET
+			"date_revoked" = :now
+		WHERE
+			family_id = :family_id AND
+			date_revoked IS NULL
+		RETURNING
+			access_token_id, user_id, access_token_expiry
+	)
+	INSERT INTO revoked_access_tokens
+		(jti, user_id, date_expires, date_created)
+	SELECT
+		access_token_id, user_id, access_token_expiry, :now
+	FROM
+		revoked
+	WHERE
+		access_token_expiry > :now
+	ON CONFLICT (jti) DO NOTHING`
+
+	if err := database.NamedExecContext(ctx, s.log, s.db, q, data); err != nil {
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	return nil
+}
+
+// RevokeUser revokes every refresh token of the user, and puts the access
+// tokens issued with them that haven't expired yet on the denylist.
+func (s *Store) RevokeUser(ctx context.Context, userID uuid.UUID, now time.Time) error {
+	data := struct {
+		UserID uuid.UUID `db:"user_id"`
+		Now    time.Time `db:"now"`
+	}{
+		UserID: userID,
+		Now:    now.UTC(),
+	}
+
+	const q = `
+	WITH revoked AS (
+		UPDATE
+			refresh_tokens
+		SET
+			"date_revoked" = :now
+		WHERE
+			user_id = :user_id AND
+			date_revoked IS NULL
+		RETURNING
+			access_token_id, user_id, access_token_expiry
+	)
+	INSERT INTO revoked_access_tokens
+		(jti, user_id, date_expires, date_created)
+	SELECT
+		access_token_id, user_id, access_token_expiry, :now
+	FROM
+		revoked
+	WHERE
+		access_token_expiry > :now
+	ON CONFLICT (jti) DO NOTHING`
+
+	if err := database.NamedExecContext(ctx, s.log, s.db, q, data); err != nil {
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	return nil
+}
+
+// IsDenied reports whether the access token with the ID is on the denylist.
+func (s *Store) IsDenied(ctx context.Context, accessTokenID string) (bool, error) {
+	data := struct {
+		JTI string `db:"jti"`
+	}{
+		JTI: accessTokenID,
+	}
+
+	const q = `
+	SELECT
+		count(1)
+	FROM
+		revoked_access_tokens
+	WHERE
+		jti = :jti`
+
+	var count struct {
+		Count int `db:"count"`
+	}
+	if err := database.NamedQueryStruct(ctx, s.log, s.db, q, data, &count); err != nil {
+		return false, fmt.Errorf("namedquerystruct: %w", err)
+	}
+
+	return count.Count > 0, nil
+}
+
+// Purge removes the refresh tokens and the denylist entries that have
+// expired.
+func (s *Store) Purge(ctx context.Context, now time.Time) error {
+	data := struct {
+		Now time.Time `db:"now"`
+	}{
+		Now: now.UTC(),
+	}
+
+	const qTokens = `
+	DELETE FROM
+		refresh_tokens
+	WHERE
+		date_expires < :now`
+
+	if err := database.NamedExecContext(ctx, s.log, s.db, qTokens, data); err != nil {
+		return fmt.Errorf("namedexeccontext: refresh tokens: %w", err)
+	}
+
+	const qDenylist = `
+	DELETE FROM
+		revoked_access_tokens
+	WHERE
+		date_expires < :now`
+
+	if err := database.NamedExecContext(ctx, s.log, s.db, qDenylist, data); err != nil {
+		return fmt.Errorf("namedexeccontext: denylist: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,34 @@
+package tag
+
+import (
+	"fmt"
+
+	"github.com/dmanias/startupers/business/sys/validate"
+	"github.com/google/uuid"
+)
+
+// QueryFilter holds the available fields a query can be filtered on.
+type QueryFilter struct {
+	Prefix    *string    `validate:"omitempty,max=100"`
+	VisibleTo *uuid.UUID `validate:"omitempty"`
+}
+
+// Validate checks the data in the model is considered clean.
+func (qf *QueryFilter) Validate() error {
+	if err := validate.Check(qf); err != nil {
+		return fmt.Errorf("validate: %w", err)
+	}
+	return nil
+}
+
+// WithPrefix sets the Prefix field of the QueryFilter value. Tags whose slug
+// or one of its aliases starts with the slug of the prefix match.
+func (qf *QueryFilter) WithPrefix(prefix string) {
+	qf.Prefix = &prefix
+}
+
+// WithVisibleTo sets the VisibleTo field of the QueryFilter value. Only tags
+// used by public ideas, or by ideas the user owns or collaborates on, match.
+func (qf *QueryFilter) WithVisibleTo(userID uuid.UUID) {
+	qf.VisibleTo = &userID
+}
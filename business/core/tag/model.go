@@ -0,0 +1,15 @@
+package tag
+
+import "time"
+
+// Tag represents an entry in the tag vocabulary. Ideas refer to tags by their
+// slug. Aliases are the slugs that resolve to the tag, such as the slugs of
+// tags merged into it or the slug it had before being renamed.
+type Tag struct {
+	Slug        string
+	Name        string
+	Aliases     []string
+	UsageCount  int
+	DateCreated time.Time
+	DateUpdated time.Time
+}
@@ -0,0 +1,15 @@
+package tag
+
+import "github.com/dmanias/startupers/business/data/order"
+
+// DefaultOrderBy represents the default way we sort.
+var DefaultOrderBy = order.NewBy(OrderByUsage, order.DESC)
+
+// Set of fields that the results can be ordered by. These are the names
+// that should be used by the application layer.
+const (
+	OrderBySlug        = "slug"
+	OrderByName        = "name"
+	OrderByUsage       = "usage"
+	OrderByDateCreated = "datecreated"
+)
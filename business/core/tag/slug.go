@@ -0,0 +1,35 @@
+package tag
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Slugify returns the canonical form of a tag name. Letters are lower cased
+// and every run of other characters is replaced by a single dash, so
+// "Machine Learning" and "machine_learning" both become "machine-learning".
+// An empty string is returned for names without letters or digits.
+func Slugify(name string) string {
+	var b strings.Builder
+	dash := false
+
+	for _, r := range strings.ToLower(name) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if dash && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			b.WriteRune(r)
+			dash = false
+			continue
+		}
+		dash = true
+	}
+
+	return b.String()
+}
+
+// DisplayName returns the name as it is shown to users, with surrounding
+// space trimmed and inner space collapsed.
+func DisplayName(name string) string {
+	return strings.Join(strings.Fields(name), " ")
+}
@@ -0,0 +1,31 @@
+package tagdb
+
+import (
+	"bytes"
+	"strings"
+
+	"github.com/dmanias/startupers/business/core/idea/stores/ideadb"
+	"github.com/dmanias/startupers/business/core/tag"
+)
+
+func (s *Store) applyFilter(filter tag.QueryFilter, data map[string]interface{}, buf *bytes.Buffer) {
+	var wc []string
+
+	if filter.Prefix != nil {
+		data["prefix"] = tag.Slugify(*filter.Prefix) + "%"
+		wc = append(wc, "(slug LIKE :prefix OR EXISTS (SELECT 1 FROM unnest(aliases) AS alias WHERE alias LIKE :prefix))")
+	}
+
+	// The usage count only covers public ideas, so tags used by the other
+	// ideas the viewer can see are looked up separately.
+	if filter.VisibleTo != nil {
+		wc = append(wc, `(usage_count > 0 OR EXISTS (
+			SELECT 1 FROM ideas i
+			WHERE i.tags @> ARRAY[tags.slug] AND i.deleted_at IS NULL AND `+ideadb.VisibleClause("i", *filter.VisibleTo, data)+`))`)
+	}
+
+	if len(wc) > 0 {
+		buf.WriteString(" WHERE ")
+		buf.WriteString(strings.Join(wc, " AND "))
+	}
+}
@@ -0,0 +1,54 @@
+package tagdb
+
+import (
+	"time"
+
+	"github.com/dmanias/startupers/business/core/tag"
+	"github.com/dmanias/startupers/business/sys/database/pgx/dbarray"
+)
+
+// dbTag represent the structure we need for moving data
+// between the app and the database.
+type dbTag struct {
+	Slug        string         `db:"slug"`
+	Name        string         `db:"name"`
+	Aliases     dbarray.String `db:"aliases"`
+	UsageCount  int            `db:"usage_count"`
+	DateCreated time.Time      `db:"date_created"`
+	DateUpdated time.Time      `db:"date_updated"`
+}
+
+func toDBTag(tg tag.Tag) dbTag {
+	aliases := tg.Aliases
+	if aliases == nil {
+		aliases = []string{}
+	}
+
+	return dbTag{
+		Slug:        tg.Slug,
+		Name:        tg.Name,
+		Aliases:     aliases,
+		UsageCount:  tg.UsageCount,
+		DateCreated: tg.DateCreated.UTC(),
+		DateUpdated: tg.DateUpdated.UTC(),
+	}
+}
+
+func toCoreTag(dbTg dbTag) tag.Tag {
+	return tag.Tag{
+		Slug:        dbTg.Slug,
+		Name:        dbTg.Name,
+		Aliases:     dbTg.Aliases,
+		UsageCount:  dbTg.UsageCount,
+		DateCreated: dbTg.DateCreated.In(time.Local),
+		DateUpdated: dbTg.DateUpdated.In(time.Local),
+	}
+}
+
+func toCoreTagSlice(dbTgs []dbTag) []tag.Tag {
+	tgs := make([]tag.Tag, len(dbTgs))
+	for i, dbTg := range dbTgs {
+		tgs[i] = toCoreTag(dbTg)
+	}
+	return tgs
+}
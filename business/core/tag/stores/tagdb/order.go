@@ -0,0 +1,25 @@
+package tagdb
+
+import (
+	"fmt"
+
+	"github.com/dmanias/startupers/business/core/tag"
+	"github.com/dmanias/startupers/business/data/order"
+)
+
+var orderByFields = map[string]string{
+	tag.OrderBySlug:        "slug",
+	tag.OrderByName:        "name",
+	tag.OrderByUsage:       "usage_count",
+	tag.OrderByDateCreated: "date_created",
+}
+
+func orderByClause(orderBy order.By) (string, error) {
+	by, exists := orderByFields[orderBy.Field]
+	if !exists {
+		return "", fmt.Errorf("field %q does not exist", orderBy.Field)
+	}
+
+	// Tags with the same value are listed alphabetically.
+	return " ORDER BY " + by + " " + orderBy.Direction + ", slug ASC", nil
+}
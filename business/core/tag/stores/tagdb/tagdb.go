@@ -0,0 +1,213 @@
+// Package tagdb contains tag related CRUD functionality.
+package tagdb
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/dmanias/startupers/business/core/tag"
+	"github.com/dmanias/startupers/business/data/order"
+	"github.com/dmanias/startupers/business/data/sqldb"
+	"github.com/dmanias/startupers/business/data/transaction"
+	database "github.com/dmanias/startupers/business/sys/database/pgx"
+	"github.com/dmanias/startupers/business/sys/database/pgx/dbarray"
+	"github.com/jmoiron/sqlx"
+	"go.uber.org/zap"
+)
+
+// Store manages the set of APIs for tag database access.
+type Store struct {
+	log *zap.SugaredLogger
+	db  sqlx.ExtContext
+}
+
+// NewStore constructs the api for data access.
+func NewStore(log *zap.SugaredLogger, db *sqlx.DB) *Store {
+	return &Store{
+		log: log,
+		db:  db,
+	}
+}
+
+// ExecuteUnderTransaction constructs a new Store value replacing the sqlx DB
+// value with a sqlx DB value that is currently inside a transaction.
+func (s *Store) ExecuteUnderTransaction(tx transaction.Transaction) (tag.Storer, error) {
+	ec, err := sqldb.GetExtContext(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	s = &Store{
+		log: s.log,
+		db:  ec,
+	}
+
+	return s, nil
+}
+
+// Create inserts a new tag into the database. The usage count is maintained
+// by the database as ideas start and stop using the tag.
+func (s *Store) Create(ctx context.Context, tg tag.Tag) error {
+	const q = `
+	INSERT INTO tags
+		(slug, name, aliases, usage_count, date_created, date_updated)
+	VALUES
+		(:slug, :name, :aliases, 0, :date_created, :date_updated)`
+
+	if err := database.NamedExecContext(ctx, s.log, s.db, q, toDBTag(tg)); err != nil {
+		if errors.Is(err, database.ErrDBDuplicatedEntry) {
+			return fmt.Errorf("namedexeccontext: %w", tag.ErrExists)
+		}
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	return nil
+}
+
+// Update replaces the name and aliases of a tag in the database.
+func (s *Store) Update(ctx context.Context, tg tag.Tag) error {
+	const q = `
+	UPDATE
+		tags
+	SET
+		"name" = :name,
+		"aliases" = :aliases,
+		"date_updated" = :date_updated
+	WHERE
+		slug = :slug`
+
+	if err := database.NamedExecContext(ctx, s.log, s.db, q, toDBTag(tg)); err != nil {
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	return nil
+}
+
+// Delete removes a tag from the database.
+func (s *Store) Delete(ctx context.Context, tg tag.Tag) error {
+	data := struct {
+		Slug string `db:"slug"`
+	}{
+		Slug: tg.Slug,
+	}
+
+	const q = `
+	DELETE FROM
+		tags
+	WHERE
+		slug = :slug`
+
+	if err := database.NamedExecContext(ctx, s.log, s.db, q, data); err != nil {
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	return nil
+}
+
+// Query retrieves a list of existing tags from the database.
+func (s *Store) Query(ctx context.Context, filter tag.QueryFilter, orderBy order.By, pageNumber int, rowsPerPage int) ([]tag.Tag, error) {
+	data := map[string]interface{}{
+		"offset":        (pageNumber - 1) * rowsPerPage,
+		"rows_per_page": rowsPerPage,
+	}
+
+	const q = `
+	SELECT
+		*
+	FROM
+		tags`
+
+	buf := bytes.NewBufferString(q)
+	s.applyFilter(filter, data, buf)
+
+	orderByClause, err := orderByClause(orderBy)
+	if err != nil {
+		return nil, err
+	}
+
+	buf.WriteString(orderByClause)
+	buf.WriteString(" OFFSET :offset ROWS FETCH NEXT :rows_per_page ROWS ONLY")
+
+	var dbTgs []dbTag
+	if err := database.NamedQuerySlice(ctx, s.log, s.db, buf.String(), data, &dbTgs); err != nil {
+		return nil, fmt.Errorf("namedqueryslice: %w", err)
+	}
+
+	return toCoreTagSlice(dbTgs), nil
+}
+
+// Count returns the total number of tags in the DB.
+func (s *Store) Count(ctx context.Context, filter tag.QueryFilter) (int, error) {
+	data := map[string]interface{}{}
+
+	const q = `
+	SELECT
+		count(1)
+	FROM
+		tags`
+
+	buf := bytes.NewBufferString(q)
+	s.applyFilter(filter, data, buf)
+
+	var count struct {
+		Count int `db:"count"`
+	}
+	if err := database.NamedQueryStruct(ctx, s.log, s.db, buf.String(), data, &count); err != nil {
+		return 0, fmt.Errorf("namedquerystruct: %w", err)
+	}
+
+	return count.Count, nil
+}
+
+// QueryBySlug gets the specified tag from the database.
+func (s *Store) QueryBySlug(ctx context.Context, slug string) (tag.Tag, error) {
+	data := struct {
+		Slug string `db:"slug"`
+	}{
+		Slug: slug,
+	}
+
+	const q = `
+	SELECT
+		*
+	FROM
+		tags
+	WHERE
+		slug = :slug`
+
+	var dbTg dbTag
+	if err := database.NamedQueryStruct(ctx, s.log, s.db, q, data, &dbTg); err != nil {
+		if errors.Is(err, database.ErrDBNotFound) {
+			return tag.Tag{}, fmt.Errorf("namedquerystruct: %w", tag.ErrNotFound)
+		}
+		return tag.Tag{}, fmt.Errorf("namedquerystruct: %w", err)
+	}
+
+	return toCoreTag(dbTg), nil
+}
+
+// QueryBySlugs retrieves the tags whose slug or one of whose aliases is in the
+// specified list.
+func (s *Store) QueryBySlugs(ctx context.Context, slugs []string) ([]tag.Tag, error) {
+	data := map[string]interface{}{
+		"slugs": dbarray.Array(slugs),
+	}
+
+	const q = `
+	SELECT
+		*
+	FROM
+		tags
+	WHERE
+		slug = ANY(:slugs) OR
+		aliases && CAST(:slugs AS TEXT[])`
+
+	var dbTgs []dbTag
+	if err := database.NamedQuerySlice(ctx, s.log, s.db, q, data, &dbTgs); err != nil {
+		return nil, fmt.Errorf("namedqueryslice: %w", err)
+	}
+
+	return toCoreTagSlice(dbTgs), nil
+}
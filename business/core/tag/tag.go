@@ -0,0 +1,298 @@
+// Package tag provides the business API for the tag vocabulary ideas are
+// labelled with. Tags entered by users are normalised to a canonical slug,
+// and admins can rename tags or merge them together.
+package tag
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/dmanias/startupers/business/core/idea"
+	"github.com/dmanias/startupers/business/data/order"
+	"github.com/dmanias/startupers/business/data/transaction"
+	"go.uber.org/zap"
+)
+
+// Set of error variables for CRUD operations.
+var (
+	ErrNotFound    = errors.New("tag not found")
+	ErrExists      = errors.New("a tag with this name already exists")
+	ErrInvalidName = errors.New("tag name must contain a letter or digit")
+	ErrMergeSelf   = errors.New("a tag can't be merged into itself")
+)
+
+// Storer interface declares the behavior this package needs to perists and
+// retrieve data.
+type Storer interface {
+	ExecuteUnderTransaction(tx transaction.Transaction) (Storer, error)
+	Create(ctx context.Context, tag Tag) error
+	Update(ctx context.Context, tag Tag) error
+	Delete(ctx context.Context, tag Tag) error
+	Query(ctx context.Context, filter QueryFilter, orderBy order.By, pageNumber int, rowsPerPage int) ([]Tag, error)
+	Count(ctx context.Context, filter QueryFilter) (int, error)
+	QueryBySlug(ctx context.Context, slug string) (Tag, error)
+	QueryBySlugs(ctx context.Context, slugs []string) ([]Tag, error)
+}
+
+// Core manages the set of APIs for tag access.
+type Core struct {
+	log      *zap.SugaredLogger
+	beginner transaction.Beginner
+	storer   Storer
+	idea     *idea.Core
+}
+
+// NewCore constructs a core for tag api access.
+func NewCore(log *zap.SugaredLogger, beginner transaction.Beginner, storer Storer, ideaCore *idea.Core) *Core {
+	return &Core{
+		log:      log,
+		beginner: beginner,
+		storer:   storer,
+		idea:     ideaCore,
+	}
+}
+
+// Normalize turns the tag names entered by a user into the slugs of the
+// matching tags. Aliases resolve to the tag they belong to, names without
+// letters or digits are dropped, duplicates are removed and tags that don't
+// exist yet are added to the vocabulary. The order of the names is kept.
+func (c *Core) Normalize(ctx context.Context, names []string) ([]string, error) {
+	if names == nil {
+		return nil, nil
+	}
+
+	slugs := make([]string, 0, len(names))
+	display := make(map[string]string, len(names))
+	for _, name := range names {
+		slug := Slugify(name)
+		if slug == "" {
+			continue
+		}
+		if _, exists := display[slug]; !exists {
+			display[slug] = DisplayName(name)
+			slugs = append(slugs, slug)
+		}
+	}
+
+	if len(slugs) == 0 {
+		return []string{}, nil
+	}
+
+	tags, err := c.storer.QueryBySlugs(ctx, slugs)
+	if err != nil {
+		return nil, fmt.Errorf("normalize: %w", err)
+	}
+
+	canonical := make(map[string]string, len(slugs))
+	for _, tag := range tags {
+		canonical[tag.Slug] = tag.Slug
+		for _, alias := range tag.Aliases {
+			canonical[alias] = tag.Slug
+		}
+	}
+
+	now := time.Now()
+	seen := make(map[string]bool, len(slugs))
+	out := make([]string, 0, len(slugs))
+
+	for _, slug := range slugs {
+		canon, exists := canonical[slug]
+		if !exists {
+			tag := Tag{
+				Slug:        slug,
+				Name:        display[slug],
+				Aliases:     []string{},
+				DateCreated: now,
+				DateUpdated: now,
+			}
+
+			// Another request may have added the same tag in the meantime,
+			// which is just as good.
+			if err := c.storer.Create(ctx, tag); err != nil && !errors.Is(err, ErrExists) {
+				return nil, fmt.Errorf("normalize: slug[%s]: %w", slug, err)
+			}
+			canon = slug
+		}
+
+		if !seen[canon] {
+			seen[canon] = true
+			out = append(out, canon)
+		}
+	}
+
+	return out, nil
+}
+
+// Rename changes the name of the tag. When the slug of the new name differs
+// the tag moves to the new slug, the old slug becomes an alias and every idea
+// using the tag is rewritten, all in a single transaction.
+func (c *Core) Rename(ctx context.Context, tag Tag, name string) (Tag, error) {
+	slug := Slugify(name)
+	if slug == "" {
+		return Tag{}, ErrInvalidName
+	}
+
+	now := time.Now()
+
+	if slug == tag.Slug {
+		tag.Name = DisplayName(name)
+		tag.DateUpdated = now
+
+		if err := c.storer.Update(ctx, tag); err != nil {
+			return Tag{}, fmt.Errorf("rename: slug[%s]: %w", tag.Slug, err)
+		}
+
+		return tag, nil
+	}
+
+	// The new slug may only be taken by one of the tag's own aliases.
+	taken, err := c.storer.QueryBySlugs(ctx, []string{slug})
+	if err != nil {
+		return Tag{}, fmt.Errorf("rename: slug[%s]: %w", tag.Slug, err)
+	}
+	for _, other := range taken {
+		if other.Slug != tag.Slug {
+			return Tag{}, ErrExists
+		}
+	}
+
+	aliases := []string{tag.Slug}
+	for _, alias := range tag.Aliases {
+		if alias != slug {
+			aliases = append(aliases, alias)
+		}
+	}
+
+	renamed := Tag{
+		Slug:        slug,
+		Name:        DisplayName(name),
+		Aliases:     aliases,
+		DateCreated: tag.DateCreated,
+		DateUpdated: now,
+	}
+
+	f := func(tx transaction.Transaction) error {
+		storer, err := c.storer.ExecuteUnderTransaction(tx)
+		if err != nil {
+			return err
+		}
+
+		ideaCore, err := c.idea.ExecuteUnderTransaction(tx)
+		if err != nil {
+			return err
+		}
+
+		if err := storer.Create(ctx, renamed); err != nil {
+			return fmt.Errorf("create: %w", err)
+		}
+
+		if err := ideaCore.ReplaceTag(ctx, tag.Slug, renamed.Slug); err != nil {
+			return fmt.Errorf("replacetag: %w", err)
+		}
+
+		if err := storer.Delete(ctx, tag); err != nil {
+			return fmt.Errorf("delete: %w", err)
+		}
+
+		return nil
+	}
+
+	if err := transaction.ExecuteUnderTransaction(ctx, c.log, c.beginner, f); err != nil {
+		return Tag{}, fmt.Errorf("rename: slug[%s]: %w", tag.Slug, err)
+	}
+
+	return c.QueryBySlug(ctx, renamed.Slug)
+}
+
+// Merge folds the source tag into the target tag. Every idea using the source
+// tag is rewritten to use the target instead, and the source slug and its
+// aliases become aliases of the target, all in a single transaction.
+func (c *Core) Merge(ctx context.Context, source Tag, target Tag) (Tag, error) {
+	if source.Slug == target.Slug {
+		return Tag{}, ErrMergeSelf
+	}
+
+	target.Aliases = mergeAliases(target, source)
+	target.DateUpdated = time.Now()
+
+	f := func(tx transaction.Transaction) error {
+		storer, err := c.storer.ExecuteUnderTransaction(tx)
+		if err != nil {
+			return err
+		}
+
+		ideaCore, err := c.idea.ExecuteUnderTransaction(tx)
+		if err != nil {
+			return err
+		}
+
+		if err := ideaCore.ReplaceTag(ctx, source.Slug, target.Slug); err != nil {
+			return fmt.Errorf("replacetag: %w", err)
+		}
+
+		if err := storer.Delete(ctx, source); err != nil {
+			return fmt.Errorf("delete: %w", err)
+		}
+
+		if err := storer.Update(ctx, target); err != nil {
+			return fmt.Errorf("update: %w", err)
+		}
+
+		return nil
+	}
+
+	if err := transaction.ExecuteUnderTransaction(ctx, c.log, c.beginner, f); err != nil {
+		return Tag{}, fmt.Errorf("merge: source[%s]: target[%s]: %w", source.Slug, target.Slug, err)
+	}
+
+	return c.QueryBySlug(ctx, target.Slug)
+}
+
+// Query retrieves a list of existing tags from the database.
+func (c *Core) Query(ctx context.Context, filter QueryFilter, orderBy order.By, pageNumber int, rowsPerPage int) ([]Tag, error) {
+	tags, err := c.storer.Query(ctx, filter, orderBy, pageNumber, rowsPerPage)
+	if err != nil {
+		return nil, fmt.Errorf("query: %w", err)
+	}
+
+	return tags, nil
+}
+
+// Count returns the total number of tags in the store.
+func (c *Core) Count(ctx context.Context, filter QueryFilter) (int, error) {
+	return c.storer.Count(ctx, filter)
+}
+
+// QueryBySlug gets the specified tag from the database. Aliases are not
+// resolved.
+func (c *Core) QueryBySlug(ctx context.Context, slug string) (Tag, error) {
+	tag, err := c.storer.QueryBySlug(ctx, slug)
+	if err != nil {
+		return Tag{}, fmt.Errorf("query: slug[%s]: %w", slug, err)
+	}
+
+	return tag, nil
+}
+
+// =============================================================================
+
+// mergeAliases returns the aliases of the target after the source is merged
+// into it. The source slug and its aliases are added, except for the slug of
+// the target itself.
+func mergeAliases(target Tag, source Tag) []string {
+	seen := map[string]bool{target.Slug: true}
+	var out []string
+
+	for _, list := range [][]string{target.Aliases, {source.Slug}, source.Aliases} {
+		for _, alias := range list {
+			if !seen[alias] {
+				seen[alias] = true
+				out = append(out, alias)
+			}
+		}
+	}
+
+	return out
+}
@@ -0,0 +1,243 @@
+// Package trash provides the business API for the ideas, posts and challenges
+// users have deleted. Deleted content stays in the trash for a retention
+// period during which it can be restored, after which it is purged for good.
+package trash
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/dmanias/startupers/business/core/challenge"
+	"github.com/dmanias/startupers/business/core/idea"
+	"github.com/dmanias/startupers/business/core/post"
+	"github.com/dmanias/startupers/business/data/order"
+	"github.com/dmanias/startupers/business/data/transaction"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// ErrExpired is returned when restoring content whose retention period is
+// over.
+var ErrExpired = errors.New("retention period has expired")
+
+// purgePageSize is the number of expired ideas purged at a time.
+const purgePageSize = 100
+
+// Core manages the set of APIs for the trash.
+type Core struct {
+	log       *zap.SugaredLogger
+	beginner  transaction.Beginner
+	retention time.Duration
+	idea      *idea.Core
+	post      *post.Core
+	challenge *challenge.Core
+}
+
+// NewCore constructs a core for trash api access. Deleted content can be
+// restored for the retention period.
+func NewCore(log *zap.SugaredLogger, beginner transaction.Beginner, retention time.Duration, ideaCore *idea.Core, postCore *post.Core, challengeCore *challenge.Core) *Core {
+	return &Core{
+		log:       log,
+		beginner:  beginner,
+		retention: retention,
+		idea:      ideaCore,
+		post:      postCore,
+		challenge: challengeCore,
+	}
+}
+
+// PurgeAfter returns the time content deleted at the specified time is purged.
+func (c *Core) PurgeAfter(deletedAt time.Time) time.Time {
+	return deletedAt.Add(c.retention)
+}
+
+// QueryIdeas returns the ideas of the user in the trash, most recently
+// deleted first.
+func (c *Core) QueryIdeas(ctx context.Context, userID uuid.UUID, pageNumber int, rowsPerPage int) ([]idea.Idea, error) {
+	orderBy := order.NewBy(idea.OrderByDateDeleted, order.DESC)
+
+	ideas, err := c.idea.Query(ctx, ideaFilter(userID), orderBy, pageNumber, rowsPerPage)
+	if err != nil {
+		return nil, fmt.Errorf("queryideas: userID[%s]: %w", userID, err)
+	}
+
+	return ideas, nil
+}
+
+// CountIdeas returns the number of ideas of the user in the trash.
+func (c *Core) CountIdeas(ctx context.Context, userID uuid.UUID) (int, error) {
+	return c.idea.Count(ctx, ideaFilter(userID))
+}
+
+// QueryPosts returns the posts in the trash the user can restore: those they
+// moved there, wrote, or that belong to their ideas. The most recently
+// deleted come first.
+func (c *Core) QueryPosts(ctx context.Context, userID uuid.UUID, pageNumber int, rowsPerPage int) ([]post.Post, error) {
+	orderBy := order.NewBy(post.OrderByDateDeleted, order.DESC)
+
+	posts, err := c.post.Query(ctx, postFilter(userID), orderBy, pageNumber, rowsPerPage)
+	if err != nil {
+		return nil, fmt.Errorf("queryposts: userID[%s]: %w", userID, err)
+	}
+
+	return posts, nil
+}
+
+// CountPosts returns the number of posts in the trash the user can restore.
+func (c *Core) CountPosts(ctx context.Context, userID uuid.UUID) (int, error) {
+	return c.post.Count(ctx, postFilter(userID))
+}
+
+// QueryChallenges returns the challenges in the trash the user can restore:
+// those they moved there, or that belong to their ideas. The most recently
+// deleted come first.
+func (c *Core) QueryChallenges(ctx context.Context, userID uuid.UUID, pageNumber int, rowsPerPage int) ([]challenge.Challenge, error) {
+	orderBy := order.NewBy(challenge.OrderByDateDeleted, order.DESC)
+
+	challenges, err := c.challenge.Query(ctx, challengeFilter(userID), orderBy, pageNumber, rowsPerPage)
+	if err != nil {
+		return nil, fmt.Errorf("querychallenges: userID[%s]: %w", userID, err)
+	}
+
+	return challenges, nil
+}
+
+// CountChallenges returns the number of challenges in the trash the user can
+// restore.
+func (c *Core) CountChallenges(ctx context.Context, userID uuid.UUID) (int, error) {
+	return c.challenge.Count(ctx, challengeFilter(userID))
+}
+
+// RestoreIdea takes the idea out of the trash if its retention period isn't
+// over.
+func (c *Core) RestoreIdea(ctx context.Context, idr idea.Idea) (idea.Idea, error) {
+	if c.expired(idr.DeletedAt) {
+		return idea.Idea{}, fmt.Errorf("restoreidea: ideaID[%s]: %w", idr.ID, ErrExpired)
+	}
+
+	return c.idea.Restore(ctx, idr)
+}
+
+// RestorePost takes the post out of the trash if its retention period isn't
+// over.
+func (c *Core) RestorePost(ctx context.Context, pst post.Post) (post.Post, error) {
+	if c.expired(pst.DeletedAt) {
+		return post.Post{}, fmt.Errorf("restorepost: postID[%s]: %w", pst.ID, ErrExpired)
+	}
+
+	return c.post.Restore(ctx, pst)
+}
+
+// RestoreChallenge takes the challenge out of the trash if its retention
+// period isn't over.
+func (c *Core) RestoreChallenge(ctx context.Context, chl challenge.Challenge) (challenge.Challenge, error) {
+	if c.expired(chl.DeletedAt) {
+		return challenge.Challenge{}, fmt.Errorf("restorechallenge: challengeID[%s]: %w", chl.ID, ErrExpired)
+	}
+
+	return c.challenge.Restore(ctx, chl)
+}
+
+// PurgeIdea removes the idea from the trash for good, together with all of
+// its content, without waiting for the retention period to end. The paths of
+// the files the idea and its content referenced are returned so the caller
+// can remove them.
+func (c *Core) PurgeIdea(ctx context.Context, idr idea.Idea) ([]string, error) {
+	return c.idea.Purge(ctx, idr)
+}
+
+// Purge removes the content whose retention period is over from the database.
+// Each expired idea is purged together with all of its content in its own
+// transaction. The paths of the files the purged content referenced are
+// returned so the caller can remove them.
+func (c *Core) Purge(ctx context.Context) ([]string, error) {
+	before := time.Now().Add(-c.retention)
+
+	var filter idea.QueryFilter
+	filter.WithDeletedBefore(before)
+
+	var files []string
+
+	for {
+		// Purged ideas drop out of the filter, so the first page is always
+		// the next one to purge.
+		ideas, err := c.idea.Query(ctx, filter, idea.DefaultOrderBy, 1, purgePageSize)
+		if err != nil {
+			return files, fmt.Errorf("purge: query: %w", err)
+		}
+
+		for _, idr := range ideas {
+			ideaFiles, err := c.idea.Purge(ctx, idr)
+			if err != nil {
+				return files, fmt.Errorf("purge: %w", err)
+			}
+			files = append(files, ideaFiles...)
+		}
+
+		if len(ideas) < purgePageSize {
+			break
+		}
+	}
+
+	if err := c.post.PurgeTrashedBefore(ctx, before); err != nil {
+		return files, fmt.Errorf("purge: %w", err)
+	}
+
+	// The photos of the purged challenges are checked for other users in the
+	// transaction that removes the challenges.
+	var photos []string
+
+	f := func(tx transaction.Transaction) error {
+		challengeCore, err := c.challenge.ExecuteUnderTransaction(tx)
+		if err != nil {
+			return err
+		}
+
+		purged, err := challengeCore.PurgeTrashedBefore(ctx, before)
+		if err != nil {
+			return err
+		}
+
+		ideaCore, err := c.idea.ExecuteUnderTransaction(tx)
+		if err != nil {
+			return err
+		}
+
+		photos, err = ideaCore.Unreferenced(ctx, purged)
+		return err
+	}
+
+	if err := transaction.ExecuteUnderTransaction(ctx, c.log, c.beginner, f); err != nil {
+		return files, fmt.Errorf("purge: %w", err)
+	}
+	files = append(files, photos...)
+
+	return files, nil
+}
+
+// =============================================================================
+
+func (c *Core) expired(deletedAt time.Time) bool {
+	return time.Now().After(c.PurgeAfter(deletedAt))
+}
+
+func ideaFilter(userID uuid.UUID) idea.QueryFilter {
+	var filter idea.QueryFilter
+	filter.WithUserID(userID)
+	filter.WithDeleted(true)
+	return filter
+}
+
+func postFilter(userID uuid.UUID) post.QueryFilter {
+	var filter post.QueryFilter
+	filter.WithRestorableBy(userID)
+	return filter
+}
+
+func challengeFilter(userID uuid.UUID) challenge.QueryFilter {
+	var filter challenge.QueryFilter
+	filter.WithRestorableBy(userID)
+	return filter
+}
@@ -9,14 +9,26 @@ import (
 
 // User represents information about an individual user.
 type User struct {
-	ID           uuid.UUID
-	Name         string
-	Email        mail.Address
-	Roles        []Role
-	PasswordHash []byte
-	Enabled      bool
-	DateCreated  time.Time
-	DateUpdated  time.Time
+	ID                      uuid.UUID
+	Name                    string
+	Email                   mail.Address
+	Roles                   []Role
+	PasswordHash            []byte
+	Enabled                 bool
+	EmailVerifiedAt         time.Time
+	NotificationPreferences NotificationPreferences
+	Bio                     string
+	Skills                  []string
+	AvatarURL               string
+	Locale                  string
+	Timezone                string
+	DateCreated             time.Time
+	DateUpdated             time.Time
+}
+
+// Verified reports whether the user has proven they own their email address.
+func (u User) Verified() bool {
+	return !u.EmailVerifiedAt.IsZero()
 }
 
 // NewUser contains information needed to create a new user.
@@ -36,4 +48,9 @@ type UpdateUser struct {
 	Password        *string
 	PasswordConfirm *string
 	Enabled         *bool
+	Bio             *string
+	Skills          []string
+	AvatarURL       *string
+	Locale          *string
+	Timezone        *string
 }
@@ -0,0 +1,52 @@
+package user
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// NotificationPreferences holds which notifications a user wants, by
+// notification type and then by delivery channel. Anything that isn't listed
+// is wanted, so new types and channels are on until the user turns them off.
+type NotificationPreferences map[string]map[string]bool
+
+// Enabled reports whether the user wants notifications of the type delivered
+// over the channel.
+func (np NotificationPreferences) Enabled(typ string, channel string) bool {
+	enabled, exists := np[typ][channel]
+	if !exists {
+		return true
+	}
+
+	return enabled
+}
+
+// UpdateNotificationPreferences merges the preferences into the ones the user
+// already has.
+func (c *Core) UpdateNotificationPreferences(ctx context.Context, usr User, np NotificationPreferences) (User, error) {
+	prefs := make(NotificationPreferences, len(usr.NotificationPreferences)+len(np))
+	for typ, channels := range usr.NotificationPreferences {
+		prefs[typ] = make(map[string]bool, len(channels))
+		for channel, enabled := range channels {
+			prefs[typ][channel] = enabled
+		}
+	}
+	for typ, channels := range np {
+		if prefs[typ] == nil {
+			prefs[typ] = make(map[string]bool, len(channels))
+		}
+		for channel, enabled := range channels {
+			prefs[typ][channel] = enabled
+		}
+	}
+
+	usr.NotificationPreferences = prefs
+	usr.DateUpdated = time.Now()
+
+	if err := c.storer.Update(ctx, usr); err != nil {
+		return User{}, fmt.Errorf("update: %w", err)
+	}
+
+	return usr, nil
+}
@@ -26,6 +26,16 @@ func (s *Store) applyFilter(filter user.QueryFilter, data map[string]interface{}
 		wc = append(wc, "email = :email")
 	}
 
+	if filter.Role != nil {
+		data["role"] = filter.Role.Name()
+		wc = append(wc, ":role = ANY(roles)")
+	}
+
+	if filter.Enabled != nil {
+		data["enabled"] = *filter.Enabled
+		wc = append(wc, "enabled = :enabled")
+	}
+
 	if filter.StartCreatedDate != nil {
 		data["start_date_created"] = *filter.StartCreatedDate
 		wc = append(wc, "date_created >= :start_date_created")
@@ -1,6 +1,8 @@
 package userdb
 
 import (
+	"database/sql"
+	"encoding/json"
 	"net/mail"
 	"time"
 
@@ -12,14 +14,21 @@ import (
 // dbUser represent the structure we need for moving data
 // between the app and the database.
 type dbUser struct {
-	ID           uuid.UUID      `db:"id"`
-	Name         string         `db:"name"`
-	Email        string         `db:"email"`
-	Roles        dbarray.String `db:"roles"`
-	PasswordHash []byte         `db:"password_hash"`
-	Enabled      bool           `db:"enabled"`
-	DateCreated  time.Time      `db:"date_created"`
-	DateUpdated  time.Time      `db:"date_updated"`
+	ID                      uuid.UUID      `db:"id"`
+	Name                    string         `db:"name"`
+	Email                   string         `db:"email"`
+	Roles                   dbarray.String `db:"roles"`
+	PasswordHash            []byte         `db:"password_hash"`
+	Enabled                 bool           `db:"enabled"`
+	EmailVerifiedAt         sql.NullTime   `db:"email_verified_at"`
+	NotificationPreferences string         `db:"notification_preferences"`
+	Bio                     string         `db:"bio"`
+	Skills                  dbarray.String `db:"skills"`
+	AvatarURL               string         `db:"avatar_url"`
+	Locale                  string         `db:"locale"`
+	Timezone                string         `db:"timezone"`
+	DateCreated             time.Time      `db:"date_created"`
+	DateUpdated             time.Time      `db:"date_updated"`
 }
 
 func toDBUser(usr user.User) dbUser {
@@ -28,6 +37,17 @@ func toDBUser(usr user.User) dbUser {
 		roles[i] = role.Name()
 	}
 
+	prefs := usr.NotificationPreferences
+	if prefs == nil {
+		prefs = user.NotificationPreferences{}
+	}
+	notificationPrefs, _ := json.Marshal(prefs)
+
+	skills := usr.Skills
+	if skills == nil {
+		skills = []string{}
+	}
+
 	return dbUser{
 		ID:           usr.ID,
 		Name:         usr.Name,
@@ -35,8 +55,18 @@ func toDBUser(usr user.User) dbUser {
 		Roles:        roles,
 		PasswordHash: usr.PasswordHash,
 		Enabled:      usr.Enabled,
-		DateCreated:  usr.DateCreated.UTC(),
-		DateUpdated:  usr.DateUpdated.UTC(),
+		EmailVerifiedAt: sql.NullTime{
+			Time:  usr.EmailVerifiedAt.UTC(),
+			Valid: usr.Verified(),
+		},
+		NotificationPreferences: string(notificationPrefs),
+		Bio:                     usr.Bio,
+		Skills:                  skills,
+		AvatarURL:               usr.AvatarURL,
+		Locale:                  usr.Locale,
+		Timezone:                usr.Timezone,
+		DateCreated:             usr.DateCreated.UTC(),
+		DateUpdated:             usr.DateUpdated.UTC(),
 	}
 }
 
@@ -50,15 +80,30 @@ func toCoreUser(dbUsr dbUser) user.User {
 		roles[i] = user.MustParseRole(value)
 	}
 
+	var emailVerifiedAt time.Time
+	if dbUsr.EmailVerifiedAt.Valid {
+		emailVerifiedAt = dbUsr.EmailVerifiedAt.Time.In(time.Local)
+	}
+
+	var prefs user.NotificationPreferences
+	_ = json.Unmarshal([]byte(dbUsr.NotificationPreferences), &prefs)
+
 	usr := user.User{
-		ID:           dbUsr.ID,
-		Name:         dbUsr.Name,
-		Email:        addr,
-		Roles:        roles,
-		PasswordHash: dbUsr.PasswordHash,
-		Enabled:      dbUsr.Enabled,
-		DateCreated:  dbUsr.DateCreated.In(time.Local),
-		DateUpdated:  dbUsr.DateUpdated.In(time.Local),
+		ID:                      dbUsr.ID,
+		Name:                    dbUsr.Name,
+		Email:                   addr,
+		Roles:                   roles,
+		PasswordHash:            dbUsr.PasswordHash,
+		Enabled:                 dbUsr.Enabled,
+		EmailVerifiedAt:         emailVerifiedAt,
+		NotificationPreferences: prefs,
+		Bio:                     dbUsr.Bio,
+		Skills:                  dbUsr.Skills,
+		AvatarURL:               dbUsr.AvatarURL,
+		Locale:                  dbUsr.Locale,
+		Timezone:                dbUsr.Timezone,
+		DateCreated:             dbUsr.DateCreated.In(time.Local),
+		DateUpdated:             dbUsr.DateUpdated.In(time.Local),
 	}
 
 	return usr
@@ -12,6 +12,8 @@ import (
 
 	"github.com/dmanias/startupers/business/core/user"
 	"github.com/dmanias/startupers/business/data/order"
+	"github.com/dmanias/startupers/business/data/sqldb"
+	"github.com/dmanias/startupers/business/data/transaction"
 	database "github.com/dmanias/startupers/business/sys/database/pgx"
 	"github.com/dmanias/startupers/business/sys/database/pgx/dbarray"
 	"github.com/google/uuid"
@@ -22,7 +24,7 @@ import (
 // Store manages the set of APIs for user database access.
 type Store struct {
 	log *zap.SugaredLogger
-	db  *sqlx.DB
+	db  sqlx.ExtContext
 }
 
 // NewStore constructs the api for data access.
@@ -33,13 +35,29 @@ func NewStore(log *zap.SugaredLogger, db *sqlx.DB) *Store {
 	}
 }
 
+// ExecuteUnderTransaction constructs a new Store value replacing the sqlx DB
+// value with a sqlx DB value that is currently inside a transaction.
+func (s *Store) ExecuteUnderTransaction(tx transaction.Transaction) (user.Storer, error) {
+	ec, err := sqldb.GetExtContext(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	s = &Store{
+		log: s.log,
+		db:  ec,
+	}
+
+	return s, nil
+}
+
 // Create inserts a new user into the database.
 func (s *Store) Create(ctx context.Context, usr user.User) error {
 	const q = `
 	INSERT INTO users
-		(id, name, email, password_hash, roles, enabled, date_created, date_updated)
+		(id, name, email, password_hash, roles, enabled, email_verified_at, notification_preferences, bio, skills, avatar_url, locale, timezone, date_created, date_updated)
 	VALUES
-		(:id, :name, :email, :password_hash, :roles, :enabled, :date_created, :date_updated)`
+		(:id, :name, :email, :password_hash, :roles, :enabled, :email_verified_at, CAST(:notification_preferences AS JSONB), :bio, :skills, :avatar_url, :locale, :timezone, :date_created, :date_updated)`
 
 	if err := database.NamedExecContext(ctx, s.log, s.db, q, toDBUser(usr)); err != nil {
 		if errors.Is(err, database.ErrDBDuplicatedEntry) {
@@ -61,6 +79,14 @@ func (s *Store) Update(ctx context.Context, usr user.User) error {
 		"email" = :email,
 		"roles" = :roles,
 		"password_hash" = :password_hash,
+		"enabled" = :enabled,
+		"email_verified_at" = :email_verified_at,
+		"notification_preferences" = CAST(:notification_preferences AS JSONB),
+		"bio" = :bio,
+		"skills" = :skills,
+		"avatar_url" = :avatar_url,
+		"locale" = :locale,
+		"timezone" = :timezone,
 		"date_updated" = :date_updated
 	WHERE
 		id = :id`
@@ -90,6 +116,9 @@ func (s *Store) Delete(ctx context.Context, usr user.User) error {
 		id = :id`
 
 	if err := database.NamedExecContext(ctx, s.log, s.db, q, data); err != nil {
+		if errors.Is(err, database.ErrDBForeignKey) {
+			return user.ErrHasContent
+		}
 		return fmt.Errorf("namedexeccontext: %w", err)
 	}
 
@@ -11,6 +11,7 @@ import (
 	"time"
 
 	"github.com/dmanias/startupers/business/data/order"
+	"github.com/dmanias/startupers/business/data/transaction"
 	"github.com/google/uuid"
 	"golang.org/x/crypto/bcrypt"
 )
@@ -20,11 +21,17 @@ var (
 	ErrNotFound              = errors.New("user not found")
 	ErrUniqueEmail           = errors.New("email is not unique")
 	ErrAuthenticationFailure = errors.New("authentication failed")
+	ErrHasContent            = errors.New("user still owns content")
 )
 
+// dummyHash is a bcrypt hash, of the default cost, that sign ins with an
+// unknown email address are checked against.
+var dummyHash = []byte("$2a$10$WZL6sTPEDBjl7Rn3j/POReRV9Oq4.7iFJC6Jeo.1uJZXg7wv3Ic5i")
+
 // Storer interface declares the behavior this package needs to perists and
 // retrieve data.
 type Storer interface {
+	ExecuteUnderTransaction(tx transaction.Transaction) (Storer, error)
 	Create(ctx context.Context, usr User) error
 	Update(ctx context.Context, usr User) error
 	Delete(ctx context.Context, usr User) error
@@ -47,6 +54,21 @@ func NewCore(storer Storer) *Core {
 	}
 }
 
+// ExecuteUnderTransaction constructs a new Core value that will use the
+// specified transaction in any store related calls.
+func (c *Core) ExecuteUnderTransaction(tx transaction.Transaction) (*Core, error) {
+	trS, err := c.storer.ExecuteUnderTransaction(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	c = &Core{
+		storer: trS,
+	}
+
+	return c, nil
+}
+
 // Create inserts a new user into the database.
 func (c *Core) Create(ctx context.Context, nu NewUser) (User, error) {
 
@@ -92,6 +114,10 @@ func (c *Core) Update(ctx context.Context, usr User, uu UpdateUser) (User, error
 		usr.Name = *uu.Name
 	}
 	if uu.Email != nil {
+		// A new address has to be verified again.
+		if uu.Email.Address != usr.Email.Address {
+			usr.EmailVerifiedAt = time.Time{}
+		}
 		usr.Email = *uu.Email
 	}
 	if uu.Roles != nil {
@@ -107,6 +133,21 @@ func (c *Core) Update(ctx context.Context, usr User, uu UpdateUser) (User, error
 	if uu.Enabled != nil {
 		usr.Enabled = *uu.Enabled
 	}
+	if uu.Bio != nil {
+		usr.Bio = *uu.Bio
+	}
+	if uu.Skills != nil {
+		usr.Skills = uu.Skills
+	}
+	if uu.AvatarURL != nil {
+		usr.AvatarURL = *uu.AvatarURL
+	}
+	if uu.Locale != nil {
+		usr.Locale = *uu.Locale
+	}
+	if uu.Timezone != nil {
+		usr.Timezone = *uu.Timezone
+	}
 	usr.DateUpdated = time.Now()
 
 	if err := c.storer.Update(ctx, usr); err != nil {
@@ -116,7 +157,48 @@ func (c *Core) Update(ctx context.Context, usr User, uu UpdateUser) (User, error
 	return usr, nil
 }
 
-// Delete removes a user from the database.
+// ChangePassword replaces the password of the user, who has to know the
+// current one.
+func (c *Core) ChangePassword(ctx context.Context, usr User, current string, password string) (User, error) {
+	if err := bcrypt.CompareHashAndPassword(usr.PasswordHash, []byte(current)); err != nil {
+		return User{}, fmt.Errorf("comparehashandpassword: %w", ErrAuthenticationFailure)
+	}
+
+	updated, err := c.Update(ctx, usr, UpdateUser{Password: &password})
+	if err != nil {
+		return User{}, fmt.Errorf("changepassword: userID[%s]: %w", usr.ID, err)
+	}
+
+	return updated, nil
+}
+
+// MarkVerified records that the user has proven they own their email
+// address.
+func (c *Core) MarkVerified(ctx context.Context, usr User) (User, error) {
+	now := time.Now()
+	usr.EmailVerifiedAt = now
+	usr.DateUpdated = now
+
+	if err := c.storer.Update(ctx, usr); err != nil {
+		return User{}, fmt.Errorf("update: %w", err)
+	}
+
+	return usr, nil
+}
+
+// IsVerified reports whether the user has proven they own their email
+// address.
+func (c *Core) IsVerified(ctx context.Context, userID uuid.UUID) (bool, error) {
+	usr, err := c.QueryByID(ctx, userID)
+	if err != nil {
+		return false, err
+	}
+
+	return usr.Verified(), nil
+}
+
+// Delete removes a user from the database. A user who still owns content,
+// such as ideas, can't be removed.
 func (c *Core) Delete(ctx context.Context, usr User) error {
 	if err := c.storer.Delete(ctx, usr); err != nil {
 		return fmt.Errorf("delete: %w", err)
@@ -175,13 +257,26 @@ func (c *Core) QueryByEmail(ctx context.Context, email mail.Address) (User, erro
 // Authenticate finds a user by their email and verifies their password. On
 // success it returns a Claims User representing this user. The claims can be
 // used to generate a token for future authentication.
+//
+// An unknown email address fails the same way as a wrong password, and takes
+// as long, so the answer doesn't tell whether the account exists.
 func (c *Core) Authenticate(ctx context.Context, email mail.Address, password string) (User, error) {
 	usr, err := c.QueryByEmail(ctx, email)
 	if err != nil {
-		return User{}, fmt.Errorf("query: email[%s]: %w", email, err)
+		if !errors.Is(err, ErrNotFound) {
+			return User{}, fmt.Errorf("query: email[%s]: %w", email, err)
+		}
+		usr = User{}
+	}
+
+	// Accounts without a password, like those created by signing in with an
+	// OpenID Connect provider, are checked against the same dummy hash.
+	hash := usr.PasswordHash
+	if len(hash) == 0 {
+		hash = dummyHash
 	}
 
-	if err := bcrypt.CompareHashAndPassword(usr.PasswordHash, []byte(password)); err != nil {
+	if err := bcrypt.CompareHashAndPassword(hash, []byte(password)); err != nil || usr.ID == uuid.Nil {
 		return User{}, fmt.Errorf("comparehashandpassword: %w", ErrAuthenticationFailure)
 	}
 
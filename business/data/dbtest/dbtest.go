@@ -9,6 +9,10 @@ import (
 	"fmt"
 	"math/rand"
 	"net/mail"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
 	"testing"
 	"time"
 
@@ -112,11 +116,11 @@ func NewTest(t *testing.T, c *docker.Container) *Test {
 
 	t.Log("Migrate and seed database ...")
 
-	//if err := dbmigrate.Migrate(ctx, db); err != nil {
-	//	t.Logf("Logs for %s\n%s:", c.ID, docker.DumpContainerLogs(c.ID))
-	//	t.Fatalf("Migrating error: %s", err)
-	//}
-	//
+	if err := migrate(ctx, db); err != nil {
+		t.Logf("Logs for %s\n%s:", c.ID, docker.DumpContainerLogs(c.ID))
+		t.Fatalf("Migrating error: %s", err)
+	}
+
 	//if err := dbmigrate.Seed(ctx, db); err != nil {
 	//	t.Logf("Logs for %s\n%s:", c.ID, docker.DumpContainerLogs(c.ID))
 	//	t.Fatalf("Seeding error: %s", err)
@@ -230,6 +234,33 @@ func FloatPointer(f float64) *float64 {
 
 // =============================================================================
 
+// migrate applies the migrations of the service to the database, in order.
+func migrate(ctx context.Context, db *sqlx.DB) error {
+	_, file, _, _ := runtime.Caller(0)
+	dir := filepath.Join(filepath.Dir(file), "..", "..", "..", "internal", "migrations")
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.up.sql"))
+	if err != nil {
+		return fmt.Errorf("glob: %w", err)
+	}
+	sort.Strings(files)
+
+	for _, file := range files {
+		q, err := os.ReadFile(file)
+		if err != nil {
+			return fmt.Errorf("read: %s: %w", filepath.Base(file), err)
+		}
+
+		if _, err := db.ExecContext(ctx, string(q)); err != nil {
+			return fmt.Errorf("exec: %s: %w", filepath.Base(file), err)
+		}
+	}
+
+	return nil
+}
+
+// =============================================================================
+
 // CoreAPIs represents all the core api's needed for testing.
 type CoreAPIs struct {
 	User *user.Core
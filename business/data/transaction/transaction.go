@@ -7,7 +7,8 @@ import (
 	"errors"
 	"fmt"
 
-	"github.com/dmanias/startupers/foundation/logger"
+	"github.com/dmanias/startupers/foundation/web"
+	"go.uber.org/zap"
 )
 
 // Transaction represents a value that can commit or rollback a transaction.
@@ -38,10 +39,11 @@ func Get(ctx context.Context) (Transaction, bool) {
 
 // ExecuteUnderTransaction is a helper function that can be used in tests and
 // other apps to execute the core APIs under a transaction.
-func ExecuteUnderTransaction(ctx context.Context, log *logger.Logger, bgn Beginner, fn func(tx Transaction) error) error {
+func ExecuteUnderTransaction(ctx context.Context, log *zap.SugaredLogger, bgn Beginner, fn func(tx Transaction) error) error {
 	hasCommitted := false
+	traceID := web.GetTraceID(ctx)
 
-	log.Info(ctx, "BEGIN TRANSACTION")
+	log.Infow("BEGIN TRANSACTION", "trace_id", traceID)
 	tx, err := bgn.Begin()
 	if err != nil {
 		return err
@@ -49,14 +51,14 @@ func ExecuteUnderTransaction(ctx context.Context, log *logger.Logger, bgn Beginn
 
 	defer func() {
 		if !hasCommitted {
-			log.Info(ctx, "ROLLBACK TRANSACTION")
+			log.Infow("ROLLBACK TRANSACTION", "trace_id", traceID)
 		}
 
 		if err := tx.Rollback(); err != nil {
 			if errors.Is(err, sql.ErrTxDone) {
 				return
 			}
-			log.Info(ctx, "ROLLBACK TRANSACTION", "ERROR", err)
+			log.Errorw("ROLLBACK TRANSACTION", "trace_id", traceID, "ERROR", err)
 		}
 	}()
 
@@ -64,7 +66,7 @@ func ExecuteUnderTransaction(ctx context.Context, log *logger.Logger, bgn Beginn
 		return fmt.Errorf("EXECUTE TRANSACTION: %w", err)
 	}
 
-	log.Info(ctx, "COMMIT TRANSACTION")
+	log.Infow("COMMIT TRANSACTION", "trace_id", traceID)
 	if err := tx.Commit(); err != nil {
 		return fmt.Errorf("COMMIT TRANSACTION: %w", err)
 	}
@@ -0,0 +1,39 @@
+// Package uploads manages the files kept on local disk for the content users
+// create, such as the avatars generated for ideas.
+package uploads
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// Dir is the directory the files are kept in, relative to the working
+// directory of the service.
+const Dir = "uploads"
+
+// ErrServerPath is returned when a client tries to point content at a file in
+// Dir. Only the service stores those paths, for files it wrote itself.
+var ErrServerPath = errors.New("paths to uploaded files can't be set")
+
+// Is reports whether the path names a file in Dir.
+func Is(path string) bool {
+	return filepath.Dir(filepath.Clean(path)) == Dir
+}
+
+// Remove removes the file at the path stored with some content. Paths that
+// don't name a file in Dir, such as links to images on other sites, are left
+// alone, so a stored path can never remove anything else. A file that is
+// already gone isn't an error.
+func Remove(path string) error {
+	if !Is(path) {
+		return nil
+	}
+
+	if err := os.Remove(filepath.Clean(path)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("remove: %w", err)
+	}
+
+	return nil
+}
@@ -9,9 +9,12 @@ import (
 	"fmt"
 	"strings"
 	"sync"
+	"time"
 
+	"github.com/dmanias/startupers/business/core/pat"
 	"github.com/dmanias/startupers/business/core/user"
 	"github.com/golang-jwt/jwt/v4"
+	"github.com/google/uuid"
 	"github.com/open-policy-agent/opa/rego"
 	"go.uber.org/zap"
 )
@@ -24,6 +27,13 @@ type Claims struct {
 	jwt.RegisteredClaims
 	Roles    []user.Role `json:"roles"`
 	UserName string      `json:"userName"`
+	Scopes   []pat.Scope `json:"scopes,omitempty"`
+}
+
+// Personal reports whether the claims come from a personal access token
+// rather than a JWT. Personal access tokens always have scopes.
+func (c Claims) Personal() bool {
+	return len(c.Scopes) > 0
 }
 
 // KeyLookup declares a method set of behavior for looking up
@@ -34,34 +44,69 @@ type KeyLookup interface {
 	PublicKey(kid string) (key string, err error)
 }
 
-// Config represents information required to initialize auth.
+// Denylist declares the behavior for checking whether a token was revoked
+// before it expired, by its ID (the jti claim).
+type Denylist interface {
+	IsDenied(ctx context.Context, tokenID string) (bool, error)
+}
+
+// PersonalTokenLookup declares the behavior for looking up a personal access
+// token by its secret.
+type PersonalTokenLookup interface {
+	Authenticate(ctx context.Context, secret string) (pat.Token, error)
+}
+
+// Config represents information required to initialize auth. When a
+// UserLookup is set, the user a token was issued to must still exist and be
+// enabled; what is looked up is cached for UserCacheTTL. RefreshRoles makes
+// the roles the user has now take the place of the ones in the token.
+// Personal access tokens are accepted when both PersonalTokens and
+// UserLookup are set.
 type Config struct {
-	Log       *zap.SugaredLogger
-	KeyLookup KeyLookup
-	Issuer    string
+	Log            *zap.SugaredLogger
+	KeyLookup      KeyLookup
+	Issuer         string
+	Denylist       Denylist
+	UserLookup     UserLookup
+	UserCacheTTL   time.Duration
+	RefreshRoles   bool
+	PersonalTokens PersonalTokenLookup
 }
 
 // Auth is used to authenticate clients. It can generate a token for a
 // set of user claims and recreate the claims by parsing the token.
 type Auth struct {
-	Log       *zap.SugaredLogger
-	keyLookup KeyLookup
-	method    jwt.SigningMethod
-	parser    *jwt.Parser
-	issuer    string
-	mu        sync.RWMutex
-	cache     map[string]string
+	Log          *zap.SugaredLogger
+	keyLookup    KeyLookup
+	method       jwt.SigningMethod
+	parser       *jwt.Parser
+	issuer       string
+	denylist     Denylist
+	userLookup   UserLookup
+	userCacheTTL time.Duration
+	refreshRoles bool
+	personal     PersonalTokenLookup
+	mu           sync.RWMutex
+	cache        map[string]string
+	userMu       sync.RWMutex
+	users        map[uuid.UUID]cachedUser
 }
 
 // New creates an Auth to support authentication/authorization.
 func New(cfg Config) (*Auth, error) {
 	a := Auth{
-		Log:       cfg.Log,
-		keyLookup: cfg.KeyLookup,
-		method:    jwt.GetSigningMethod(jwt.SigningMethodRS256.Name),
-		parser:    jwt.NewParser(jwt.WithValidMethods([]string{jwt.SigningMethodRS256.Name})),
-		issuer:    cfg.Issuer,
-		cache:     make(map[string]string),
+		Log:          cfg.Log,
+		keyLookup:    cfg.KeyLookup,
+		method:       jwt.GetSigningMethod(jwt.SigningMethodRS256.Name),
+		parser:       jwt.NewParser(jwt.WithValidMethods([]string{jwt.SigningMethodRS256.Name})),
+		issuer:       cfg.Issuer,
+		denylist:     cfg.Denylist,
+		userLookup:   cfg.UserLookup,
+		userCacheTTL: cfg.UserCacheTTL,
+		refreshRoles: cfg.RefreshRoles,
+		personal:     cfg.PersonalTokens,
+		cache:        make(map[string]string),
+		users:        make(map[uuid.UUID]cachedUser),
 	}
 
 	return &a, nil
@@ -96,6 +141,11 @@ func (a *Auth) Authenticate(ctx context.Context, bearerToken string) (Claims, er
 	if len(parts) != 2 || parts[0] != "Bearer" {
 		return Claims{}, errors.New("expected authorization header format: Bearer <token>")
 	}
+
+	if strings.HasPrefix(parts[1], pat.Prefix) {
+		return a.authenticatePersonal(ctx, parts[1])
+	}
+
 	var claims Claims
 	token, _, err := a.parser.ParseUnverified(parts[1], &claims)
 	if err != nil {
@@ -129,19 +179,40 @@ func (a *Auth) Authenticate(ctx context.Context, bearerToken string) (Claims, er
 		return Claims{}, fmt.Errorf("authentication failed : %w", err)
 	}
 
+	// Check the token wasn't revoked before it expired.
+	if a.denylist != nil && claims.ID != "" {
+		denied, err := a.denylist.IsDenied(ctx, claims.ID)
+		if err != nil {
+			return Claims{}, fmt.Errorf("checking denylist: %w", err)
+		}
+		if denied {
+			return Claims{}, errors.New("token has been revoked")
+		}
+	}
+
 	// Check the database for this user to verify they are still enabled.
+	if a.userLookup != nil {
+		claims, err = a.checkUser(ctx, claims)
+		if err != nil {
+			return Claims{}, err
+		}
+	}
 
 	return claims, nil
 }
 
 // Authorize attempts to authorize the user with the provided input roles, if
 // none of the input roles are within the user's claims, we return an error
-// otherwise the user is authorized.
-func (a *Auth) Authorize(ctx context.Context, claims Claims, rule string) error {
+// otherwise the user is authorized. A personal access token is only
+// authorized when it has one of the scopes; without scopes, only JWTs are.
+func (a *Auth) Authorize(ctx context.Context, claims Claims, rule string, scopes ...pat.Scope) error {
 	input := map[string]any{
-		"Roles":   claims.Roles,
-		"Subject": claims.Subject,
-		"UserID":  claims.Subject,
+		"Roles":          claims.Roles,
+		"Subject":        claims.Subject,
+		"UserID":         claims.Subject,
+		"Personal":       claims.Personal(),
+		"Scopes":         claims.Scopes,
+		"RequiredScopes": scopes,
 	}
 
 	if err := a.opaPolicyEvaluation(ctx, opaAuthorization, rule, input); err != nil {
@@ -153,6 +224,44 @@ func (a *Auth) Authorize(ctx context.Context, claims Claims, rule string) error
 
 // =============================================================================
 
+// authenticatePersonal builds the claims of a personal access token. The
+// roles are the ones the user has now.
+func (a *Auth) authenticatePersonal(ctx context.Context, secret string) (Claims, error) {
+	if a.personal == nil || a.userLookup == nil {
+		return Claims{}, errors.New("personal access tokens are not accepted")
+	}
+
+	tkn, err := a.personal.Authenticate(ctx, secret)
+	if err != nil {
+		return Claims{}, fmt.Errorf("personal access token: %w", err)
+	}
+
+	cu, err := a.lookupUser(ctx, tkn.UserID)
+	if err != nil {
+		return Claims{}, err
+	}
+
+	if !cu.enabled {
+		return Claims{}, fmt.Errorf("user[%s] is disabled", tkn.UserID)
+	}
+
+	claims := Claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:       tkn.ID.String(),
+			Subject:  tkn.UserID.String(),
+			IssuedAt: jwt.NewNumericDate(tkn.DateCreated),
+		},
+		Roles:  cu.roles,
+		Scopes: tkn.Scopes,
+	}
+
+	if !tkn.DateExpires.IsZero() {
+		claims.ExpiresAt = jwt.NewNumericDate(tkn.DateExpires)
+	}
+
+	return claims, nil
+}
+
 // publicKeyLookup performs a lookup for the public pem for the specified kid.
 func (a *Auth) publicKeyLookup(kid string) (string, error) {
 	pem, err := func() (string, error) {
@@ -2,6 +2,9 @@ package auth
 
 import (
 	"context"
+	"fmt"
+
+	"github.com/google/uuid"
 )
 
 // ctxKey represents the type of value for the context key.
@@ -25,3 +28,16 @@ func GetClaims(ctx context.Context) Claims {
 	}
 	return v
 }
+
+// GetUserID returns the ID of the authenticated user from the claims stored
+// in the context.
+func GetUserID(ctx context.Context) (uuid.UUID, error) {
+	claims := GetClaims(ctx)
+
+	userID, err := uuid.Parse(claims.Subject)
+	if err != nil {
+		return uuid.UUID{}, fmt.Errorf("parsing subject[%s]: %w", claims.Subject, err)
+	}
+
+	return userID, nil
+}
@@ -9,29 +9,45 @@ roleUser := "USER"
 roleAdmin := "ADMIN"
 roleAll := {roleAdmin, roleUser}
 
+# Personal access tokens only reach the routes that accept one of their
+# scopes. JWTs carry no scopes and are not limited.
+scopeAllowed {
+	not input.Personal
+}
+
+scopeAllowed {
+	input.Personal
+	input.Scopes[_] == input.RequiredScopes[_]
+}
+
 ruleAny {
+	scopeAllowed
 	claim_roles := {role | role := input.Roles[_]}
 	input_roles := roleAll & claim_roles
 	count(input_roles) > 0
 }
 
 ruleAdminOnly {
+	scopeAllowed
 	claim_roles := {role | role := input.Roles[_]}
 	input_admin := {roleAdmin} & claim_roles
 	count(input_admin) > 0
 }
 
 ruleUserOnly {
+	scopeAllowed
 	claim_roles := {role | role := input.Roles[_]}
 	input_user := {roleUser} & claim_roles
 	count(input_user) > 0
 }
 
 ruleAdminOrSubject {
+	scopeAllowed
 	claim_roles := {role | role := input.Roles[_]}
 	input_admin := {roleAdmin} & claim_roles
     count(input_admin) > 0
 } else {
+	scopeAllowed
     claim_roles := {role | role := input.Roles[_]}
 	input_user := {roleUser} & claim_roles
 	count(input_user) > 0
@@ -0,0 +1,106 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/dmanias/startupers/business/core/user"
+	"github.com/google/uuid"
+)
+
+// maxCachedUsers is how many users are cached before expired entries are
+// swept out.
+const maxCachedUsers = 10_000
+
+// UserLookup declares the behavior for looking up the user a token was
+// issued to.
+type UserLookup interface {
+	QueryByID(ctx context.Context, userID uuid.UUID) (user.User, error)
+}
+
+// cachedUser is what authentication needs to know about a user, and until
+// when it can be trusted without asking the database again.
+type cachedUser struct {
+	enabled bool
+	roles   []user.Role
+	expires time.Time
+}
+
+// checkUser verifies the subject of the claims still exists and is enabled.
+// When roles are refreshed, the roles in the claims are replaced with the
+// ones the user has now.
+func (a *Auth) checkUser(ctx context.Context, claims Claims) (Claims, error) {
+	userID, err := uuid.Parse(claims.Subject)
+	if err != nil {
+		return Claims{}, fmt.Errorf("parsing subject[%s]: %w", claims.Subject, err)
+	}
+
+	cu, err := a.lookupUser(ctx, userID)
+	if err != nil {
+		return Claims{}, err
+	}
+
+	if !cu.enabled {
+		return Claims{}, fmt.Errorf("user[%s] is disabled", userID)
+	}
+
+	if a.refreshRoles {
+		claims.Roles = cu.roles
+	}
+
+	return claims, nil
+}
+
+// lookupUser returns the user from the cache, or from the lookup once the
+// cached entry has expired.
+func (a *Auth) lookupUser(ctx context.Context, userID uuid.UUID) (cachedUser, error) {
+	now := time.Now()
+
+	a.userMu.RLock()
+	cu, exists := a.users[userID]
+	a.userMu.RUnlock()
+
+	if exists && now.Before(cu.expires) {
+		return cu, nil
+	}
+
+	usr, err := a.userLookup.QueryByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, user.ErrNotFound) {
+			return cachedUser{}, fmt.Errorf("user[%s] not found", userID)
+		}
+		return cachedUser{}, fmt.Errorf("looking up user[%s]: %w", userID, err)
+	}
+
+	cu = cachedUser{
+		enabled: usr.Enabled,
+		roles:   usr.Roles,
+		expires: now.Add(a.userCacheTTL),
+	}
+
+	a.userMu.Lock()
+	defer a.userMu.Unlock()
+
+	if len(a.users) >= maxCachedUsers {
+		for id, entry := range a.users {
+			if !now.Before(entry.expires) {
+				delete(a.users, id)
+			}
+		}
+	}
+	a.users[userID] = cu
+
+	return cu, nil
+}
+
+// ForgetUser drops what is cached about the user, so a change to the account
+// applies to the next request served by this instance. Other instances pick
+// it up once their cached entry expires.
+func (a *Auth) ForgetUser(userID uuid.UUID) {
+	a.userMu.Lock()
+	defer a.userMu.Unlock()
+
+	delete(a.users, userID)
+}
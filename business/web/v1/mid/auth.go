@@ -2,12 +2,14 @@ package mid
 
 import (
 	"context"
+	"github.com/dmanias/startupers/business/core/pat"
 	"github.com/dmanias/startupers/business/web/auth"
 	"github.com/dmanias/startupers/foundation/web"
 	"net/http"
 )
 
-// Authenticate validates a JWT from the `Authorization` header.
+// Authenticate validates a JWT or a personal access token from the
+// `Authorization` header.
 func Authenticate(a *auth.Auth) web.Middleware {
 	m := func(handler web.Handler) web.Handler {
 		h := func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
@@ -29,7 +31,9 @@ func Authenticate(a *auth.Auth) web.Middleware {
 
 // Authorize validates that an authenticated user has at least one role from a
 // specified list. This method constructs the actual function that is used.
-func Authorize(a *auth.Auth, rule string) web.Middleware {
+// Personal access tokens are only let through when they have one of the
+// scopes.
+func Authorize(a *auth.Auth, rule string, scopes ...pat.Scope) web.Middleware {
 	m := func(handler web.Handler) web.Handler {
 		h := func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
 			claims := auth.GetClaims(ctx)
@@ -37,7 +41,7 @@ func Authorize(a *auth.Auth, rule string) web.Middleware {
 				return auth.NewAuthError("authorize: you are not authorized for that action, no claims")
 			}
 
-			if err := a.Authorize(ctx, claims, rule); err != nil {
+			if err := a.Authorize(ctx, claims, rule, scopes...); err != nil {
 				return auth.NewAuthError("authorize: you are not authorized for that action, claims[%v] rule[%v]: %s", claims.Roles, rule, err)
 			}
 
@@ -2,6 +2,7 @@
 package paging
 
 import (
+	"fmt"
 	"net/http"
 	"strconv"
 
@@ -62,3 +63,54 @@ func ParseRequest(r *http.Request) (Page, error) {
 		RowsPerPage: rowsPerPage,
 	}, nil
 }
+
+// =============================================================================
+
+// CursorResponse is what is returned when a query is paged with a cursor
+// instead of page numbers. Next is empty on the last page.
+type CursorResponse[T any] struct {
+	Items []T    `json:"items"`
+	Next  string `json:"next,omitempty"`
+}
+
+// NewCursorResponse constructs a cursor response value for a web response.
+func NewCursorResponse[T any](items []T, next string) CursorResponse[T] {
+	return CursorResponse[T]{
+		Items: items,
+		Next:  next,
+	}
+}
+
+// CursorPage represents the requested position and rows per page for a query
+// paged with a cursor.
+type CursorPage struct {
+	Cursor      string
+	RowsPerPage int
+}
+
+// ParseCursorRequest parses the request for the cursor and rows query string.
+// The defaults are provided as well.
+func ParseCursorRequest(r *http.Request) (CursorPage, error) {
+	values := r.URL.Query()
+
+	rowsPerPage := 10
+	if rows := values.Get("rows"); rows != "" {
+		var err error
+		rowsPerPage, err = strconv.Atoi(rows)
+		if err != nil {
+			return CursorPage{}, validate.NewFieldsError("rows", err)
+		}
+	}
+
+	if rowsPerPage < 1 || rowsPerPage > maxCursorRows {
+		return CursorPage{}, validate.NewFieldsError("rows", fmt.Errorf("rows must be between 1 and %d", maxCursorRows))
+	}
+
+	return CursorPage{
+		Cursor:      values.Get("cursor"),
+		RowsPerPage: rowsPerPage,
+	}, nil
+}
+
+// maxCursorRows is the largest page a cursor query can ask for.
+const maxCursorRows = 100
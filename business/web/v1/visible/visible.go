@@ -0,0 +1,34 @@
+// Package visible provides support for looking up ideas on behalf of the
+// authenticated user. Ideas the user isn't allowed to see are reported as
+// not found, so their existence isn't revealed.
+package visible
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/dmanias/startupers/business/core/idea"
+	"github.com/dmanias/startupers/business/web/auth"
+	v1 "github.com/dmanias/startupers/business/web/v1"
+	"github.com/google/uuid"
+)
+
+// Idea retrieves the idea if the authenticated user is allowed to see it.
+func Idea(ctx context.Context, ideaCore *idea.Core, ideaID uuid.UUID) (idea.Idea, error) {
+	userID, err := auth.GetUserID(ctx)
+	if err != nil {
+		return idea.Idea{}, auth.NewAuthError("query: ideaID[%s]: %s", ideaID, err)
+	}
+
+	idr, err := ideaCore.QueryVisibleByID(ctx, ideaID, userID)
+	if err != nil {
+		if errors.Is(err, idea.ErrNotFound) {
+			return idea.Idea{}, v1.NewRequestError(idea.ErrNotFound, http.StatusNotFound)
+		}
+		return idea.Idea{}, fmt.Errorf("query: ideaID[%s]: %w", ideaID, err)
+	}
+
+	return idr, nil
+}
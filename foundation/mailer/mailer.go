@@ -0,0 +1,133 @@
+// Package mailer provides support for sending email. Messages are sent over
+// SMTP in production and kept in memory or written to files during
+// development and testing.
+package mailer
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"net/textproto"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Mailer sends email messages.
+type Mailer interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// Message is an email with a plain text body, an HTML body or both.
+type Message struct {
+	From    mail.Address
+	To      []mail.Address
+	Subject string
+	Text    string
+	HTML    string
+}
+
+// Recipients returns the bare addresses of the recipients.
+func (m Message) Recipients() []string {
+	to := make([]string, len(m.To))
+	for i, addr := range m.To {
+		to[i] = addr.Address
+	}
+	return to
+}
+
+// Bytes renders the message in the internet message format. A message with
+// both bodies is sent as multipart/alternative so clients pick the one they
+// can display.
+func (m Message) Bytes() ([]byte, error) {
+	if len(m.To) == 0 {
+		return nil, fmt.Errorf("message has no recipients")
+	}
+
+	to := make([]string, len(m.To))
+	for i, addr := range m.To {
+		to[i] = addr.String()
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "From: %s\r\n", m.From.String())
+	fmt.Fprintf(&buf, "To: %s\r\n", strings.Join(to, ", "))
+	fmt.Fprintf(&buf, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", m.Subject))
+	fmt.Fprintf(&buf, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	fmt.Fprintf(&buf, "Message-ID: <%s@%s>\r\n", uuid.NewString(), domain(m.From))
+	buf.WriteString("MIME-Version: 1.0\r\n")
+
+	switch {
+	case m.Text != "" && m.HTML != "":
+		mw := multipart.NewWriter(&buf)
+		fmt.Fprintf(&buf, "Content-Type: multipart/alternative; boundary=%q\r\n\r\n", mw.Boundary())
+
+		if err := writePart(mw, "text/plain", m.Text); err != nil {
+			return nil, err
+		}
+		if err := writePart(mw, "text/html", m.HTML); err != nil {
+			return nil, err
+		}
+		if err := mw.Close(); err != nil {
+			return nil, err
+		}
+
+	case m.HTML != "":
+		if err := writeBody(&buf, "text/html", m.HTML); err != nil {
+			return nil, err
+		}
+
+	default:
+		if err := writeBody(&buf, "text/plain", m.Text); err != nil {
+			return nil, err
+		}
+	}
+
+	return buf.Bytes(), nil
+}
+
+// writePart adds a quoted-printable part of the content type.
+func writePart(mw *multipart.Writer, contentType string, body string) error {
+	header := textproto.MIMEHeader{}
+	header.Set("Content-Type", contentType+"; charset=utf-8")
+	header.Set("Content-Transfer-Encoding", "quoted-printable")
+
+	pw, err := mw.CreatePart(header)
+	if err != nil {
+		return err
+	}
+
+	qw := quotedprintable.NewWriter(pw)
+	if _, err := qw.Write([]byte(body)); err != nil {
+		return err
+	}
+
+	return qw.Close()
+}
+
+// writeBody writes the headers and the quoted-printable body of a single part
+// message.
+func writeBody(buf *bytes.Buffer, contentType string, body string) error {
+	fmt.Fprintf(buf, "Content-Type: %s; charset=utf-8\r\n", contentType)
+	buf.WriteString("Content-Transfer-Encoding: quoted-printable\r\n\r\n")
+
+	qw := quotedprintable.NewWriter(buf)
+	if _, err := qw.Write([]byte(body)); err != nil {
+		return err
+	}
+
+	return qw.Close()
+}
+
+// domain returns the domain of the address, for building message IDs.
+func domain(addr mail.Address) string {
+	if i := strings.LastIndex(addr.Address, "@"); i >= 0 {
+		return addr.Address[i+1:]
+	}
+	return "localhost"
+}
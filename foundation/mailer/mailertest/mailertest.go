@@ -0,0 +1,279 @@
+// Package mailertest provides an in-process SMTP server for testing code
+// that sends email over SMTP.
+package mailertest
+
+import (
+	"bytes"
+	"encoding/base64"
+	"io"
+	"net"
+	"net/textproto"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/dmanias/startupers/foundation/mailer"
+)
+
+// Message is what the server received for a message it accepted.
+type Message struct {
+	Username string
+	From     string
+	To       []string
+	Data     []byte
+}
+
+// Server is a minimal SMTP server listening on the loopback interface. It
+// keeps the messages it accepts, can require PLAIN authentication and can be
+// told to turn messages away.
+type Server struct {
+	ln net.Listener
+	wg sync.WaitGroup
+
+	mu       sync.Mutex
+	username string
+	password string
+	failures int
+	messages []Message
+}
+
+// NewServer starts a server that is stopped when the test ends.
+func NewServer(t *testing.T) *Server {
+	t.Helper()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+
+	s := Server{
+		ln: ln,
+	}
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		s.serve()
+	}()
+
+	t.Cleanup(func() {
+		ln.Close()
+		s.wg.Wait()
+	})
+
+	return &s
+}
+
+// Config returns the settings to reach the server, with the credentials it
+// requires if any.
+func (s *Server) Config() mailer.SMTPConfig {
+	addr := s.ln.Addr().(*net.TCPAddr)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return mailer.SMTPConfig{
+		Host:     addr.IP.String(),
+		Port:     addr.Port,
+		Username: s.username,
+		Password: s.password,
+	}
+}
+
+// RequireAuth makes the server only accept messages from clients that
+// authenticated with the credentials.
+func (s *Server) RequireAuth(username string, password string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.username = username
+	s.password = password
+}
+
+// Fail makes the server turn the next n messages away with a temporary
+// failure.
+func (s *Server) Fail(n int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.failures = n
+}
+
+// Messages returns the messages accepted so far, oldest first.
+func (s *Server) Messages() []Message {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	msgs := make([]Message, len(s.messages))
+	copy(msgs, s.messages)
+
+	return msgs
+}
+
+// =============================================================================
+
+func (s *Server) serve() {
+	for {
+		conn, err := s.ln.Accept()
+		if err != nil {
+			return
+		}
+
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			defer conn.Close()
+			s.handle(textproto.NewConn(conn))
+		}()
+	}
+}
+
+// handle runs one SMTP session.
+func (s *Server) handle(conn *textproto.Conn) {
+	s.mu.Lock()
+	required, password := s.username, s.password
+	s.mu.Unlock()
+
+	var (
+		username string
+		msg      Message
+	)
+
+	reply := func(code int, text string) {
+		conn.PrintfLine("%d %s", code, text)
+	}
+
+	reply(220, "localhost ESMTP mailertest")
+
+	for {
+		line, err := conn.ReadLine()
+		if err != nil {
+			return
+		}
+
+		verb, arg, _ := strings.Cut(line, " ")
+
+		switch strings.ToUpper(verb) {
+		case "EHLO", "HELO":
+			if required != "" {
+				conn.PrintfLine("250-localhost")
+				reply(250, "AUTH PLAIN")
+				continue
+			}
+			reply(250, "localhost")
+
+		case "AUTH":
+			mech, resp, _ := strings.Cut(arg, " ")
+			if !strings.EqualFold(mech, "PLAIN") {
+				reply(504, "5.5.4 Unrecognized authentication type")
+				continue
+			}
+
+			user, ok := authenticate(resp, required, password)
+			if !ok {
+				reply(535, "5.7.8 Authentication credentials invalid")
+				continue
+			}
+
+			username = user
+			reply(235, "2.7.0 Authentication successful")
+
+		case "MAIL":
+			if required != "" && username == "" {
+				reply(530, "5.7.0 Authentication required")
+				continue
+			}
+
+			if s.fail() {
+				reply(451, "4.3.0 Try again later")
+				continue
+			}
+
+			msg = Message{
+				Username: username,
+				From:     address(arg),
+			}
+			reply(250, "2.1.0 OK")
+
+		case "RCPT":
+			msg.To = append(msg.To, address(arg))
+			reply(250, "2.1.5 OK")
+
+		case "DATA":
+			reply(354, "End data with <CR><LF>.<CR><LF>")
+
+			data, err := io.ReadAll(conn.DotReader())
+			if err != nil {
+				return
+			}
+			msg.Data = data
+
+			s.mu.Lock()
+			s.messages = append(s.messages, msg)
+			s.mu.Unlock()
+
+			msg = Message{}
+			reply(250, "2.0.0 OK")
+
+		case "RSET":
+			msg = Message{}
+			reply(250, "2.0.0 OK")
+
+		case "NOOP":
+			reply(250, "2.0.0 OK")
+
+		case "QUIT":
+			reply(221, "2.0.0 Bye")
+			return
+
+		default:
+			reply(502, "5.5.2 Command not implemented")
+		}
+	}
+}
+
+// authenticate checks the initial response of a PLAIN authentication against
+// the credentials and returns the user it is for.
+func authenticate(resp string, username string, password string) (string, bool) {
+	b, err := base64.StdEncoding.DecodeString(resp)
+	if err != nil {
+		return "", false
+	}
+
+	parts := bytes.Split(b, []byte{0})
+	if len(parts) != 3 {
+		return "", false
+	}
+
+	user, pass := string(parts[1]), string(parts[2])
+	if user != username || pass != password {
+		return "", false
+	}
+
+	return user, true
+}
+
+// fail reports whether the next message has to be turned away.
+func (s *Server) fail() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.failures == 0 {
+		return false
+	}
+
+	s.failures--
+	return true
+}
+
+// address returns the address of a MAIL FROM or RCPT TO argument.
+func address(arg string) string {
+	_, addr, _ := strings.Cut(arg, ":")
+	addr = strings.TrimSpace(addr)
+
+	if i := strings.IndexByte(addr, ' '); i >= 0 {
+		addr = addr[:i]
+	}
+
+	return strings.Trim(addr, "<>")
+}
@@ -0,0 +1,83 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Memory keeps the messages it is asked to send, for tests to inspect.
+type Memory struct {
+	mu       sync.Mutex
+	messages []Message
+}
+
+// NewMemory constructs a mailer that keeps messages in memory.
+func NewMemory() *Memory {
+	return &Memory{}
+}
+
+// Send keeps the message.
+func (m *Memory) Send(ctx context.Context, msg Message) error {
+	if _, err := msg.Bytes(); err != nil {
+		return fmt.Errorf("render: %w", err)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.messages = append(m.messages, msg)
+
+	return nil
+}
+
+// Messages returns the messages sent so far, oldest first.
+func (m *Memory) Messages() []Message {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	msgs := make([]Message, len(m.messages))
+	copy(msgs, m.messages)
+
+	return msgs
+}
+
+// =============================================================================
+
+// File writes the messages it is asked to send to a folder, one .eml file per
+// message, so they can be opened with a mail client during development.
+type File struct {
+	dir string
+}
+
+// NewFile constructs a mailer that writes messages to the folder, creating it
+// if needed.
+func NewFile(dir string) (*File, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("mkdir: %s: %w", dir, err)
+	}
+
+	return &File{
+		dir: dir,
+	}, nil
+}
+
+// Send writes the message to a new file.
+func (f *File) Send(ctx context.Context, msg Message) error {
+	body, err := msg.Bytes()
+	if err != nil {
+		return fmt.Errorf("render: %w", err)
+	}
+
+	name := fmt.Sprintf("%s-%s.eml", time.Now().UTC().Format("20060102T150405.000000000"), uuid.NewString())
+	if err := os.WriteFile(filepath.Join(f.dir, name), body, 0o644); err != nil {
+		return fmt.Errorf("write: %s: %w", name, err)
+	}
+
+	return nil
+}
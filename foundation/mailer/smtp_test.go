@@ -0,0 +1,139 @@
+package mailer_test
+
+import (
+	"context"
+	"net/mail"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dmanias/startupers/foundation/mailer"
+	"github.com/dmanias/startupers/foundation/mailer/mailertest"
+)
+
+func testMessage() mailer.Message {
+	return mailer.Message{
+		From: mail.Address{Name: "Startupers", Address: "no-reply@startupers.test"},
+		To: []mail.Address{
+			{Name: "Jane", Address: "jane@example.com"},
+			{Address: "john@example.com"},
+		},
+		Subject: "Welcome aboard",
+		Text:    "Hi Jane,\nwelcome aboard.",
+		HTML:    "<p>Hi Jane,</p><p>welcome aboard.</p>",
+	}
+}
+
+func Test_SMTPSend(t *testing.T) {
+	srv := mailertest.NewServer(t)
+	srv.RequireAuth("mailer", "secret")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := mailer.NewSMTP(srv.Config()).Send(ctx, testMessage()); err != nil {
+		t.Fatalf("Should be able to send the message: %s", err)
+	}
+
+	msgs := srv.Messages()
+	if len(msgs) != 1 {
+		t.Fatalf("Should have received one message, got %d", len(msgs))
+	}
+	got := msgs[0]
+
+	if got.Username != "mailer" {
+		t.Errorf("Should have authenticated as %q, got %q", "mailer", got.Username)
+	}
+	if got.From != "no-reply@startupers.test" {
+		t.Errorf("Should have been sent from %q, got %q", "no-reply@startupers.test", got.From)
+	}
+	if strings.Join(got.To, ",") != "jane@example.com,john@example.com" {
+		t.Errorf("Should have been sent to both recipients, got %v", got.To)
+	}
+
+	data := string(got.Data)
+	for _, want := range []string{
+		"Subject: Welcome aboard",
+		"Content-Type: multipart/alternative",
+		"Content-Type: text/plain; charset=utf-8",
+		"Content-Type: text/html; charset=utf-8",
+		"<p>Hi Jane,</p>",
+	} {
+		if !strings.Contains(data, want) {
+			t.Errorf("Should have %q in the message:\n%s", want, data)
+		}
+	}
+}
+
+func Test_SMTPSendWithoutAuth(t *testing.T) {
+	srv := mailertest.NewServer(t)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := mailer.NewSMTP(srv.Config()).Send(ctx, testMessage()); err != nil {
+		t.Fatalf("Should be able to send the message: %s", err)
+	}
+
+	if msgs := srv.Messages(); len(msgs) != 1 || msgs[0].Username != "" {
+		t.Fatalf("Should have received one message without authentication, got %+v", msgs)
+	}
+}
+
+func Test_SMTPSendWrongCredentials(t *testing.T) {
+	srv := mailertest.NewServer(t)
+	srv.RequireAuth("mailer", "secret")
+
+	cfg := srv.Config()
+	cfg.Password = "wrong"
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	err := mailer.NewSMTP(cfg).Send(ctx, testMessage())
+	if err == nil || !strings.HasPrefix(err.Error(), "auth:") {
+		t.Fatalf("Should fail to authenticate, got %v", err)
+	}
+
+	if msgs := srv.Messages(); len(msgs) != 0 {
+		t.Fatalf("Should not have received a message, got %d", len(msgs))
+	}
+}
+
+func Test_SMTPSendRejected(t *testing.T) {
+	srv := mailertest.NewServer(t)
+	srv.Fail(1)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	smtp := mailer.NewSMTP(srv.Config())
+
+	err := smtp.Send(ctx, testMessage())
+	if err == nil || !strings.HasPrefix(err.Error(), "mail:") {
+		t.Fatalf("Should be turned away by the server, got %v", err)
+	}
+
+	if err := smtp.Send(ctx, testMessage()); err != nil {
+		t.Fatalf("Should be able to send the message once the server accepts it: %s", err)
+	}
+
+	if msgs := srv.Messages(); len(msgs) != 1 {
+		t.Fatalf("Should have received one message, got %d", len(msgs))
+	}
+}
+
+func Test_SMTPSendUnreachable(t *testing.T) {
+	srv := mailertest.NewServer(t)
+
+	cfg := srv.Config()
+	cfg.Port = 1
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	err := mailer.NewSMTP(cfg).Send(ctx, testMessage())
+	if err == nil || !strings.HasPrefix(err.Error(), "dial:") {
+		t.Fatalf("Should fail to reach the server, got %v", err)
+	}
+}
@@ -0,0 +1,126 @@
+package mailer
+
+import (
+	"bytes"
+	"fmt"
+	htmltemplate "html/template"
+	"io/fs"
+	"path"
+	"strings"
+	texttemplate "text/template"
+)
+
+// Templates renders localised messages from a set of template files. Each
+// message has a plain text file named <name>.<locale>.txt, which also defines
+// the subject in a "subject" block, and an optional HTML file named
+// <name>.<locale>.html.
+type Templates struct {
+	defaultLocale string
+	text          map[string]*texttemplate.Template
+	html          map[string]*htmltemplate.Template
+}
+
+// ParseTemplates parses every template file at the root of the file system.
+// Messages are rendered in the default locale when there is no template for
+// the requested one.
+func ParseTemplates(fsys fs.FS, defaultLocale string) (*Templates, error) {
+	t := Templates{
+		defaultLocale: defaultLocale,
+		text:          make(map[string]*texttemplate.Template),
+		html:          make(map[string]*htmltemplate.Template),
+	}
+
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, fmt.Errorf("readdir: %w", err)
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+
+		name := entry.Name()
+		ext := path.Ext(name)
+		key := strings.TrimSuffix(name, ext)
+
+		data, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return nil, fmt.Errorf("readfile: %s: %w", name, err)
+		}
+
+		switch ext {
+		case ".txt":
+			tmpl, err := texttemplate.New(key).Parse(string(data))
+			if err != nil {
+				return nil, fmt.Errorf("parse: %s: %w", name, err)
+			}
+			if tmpl.Lookup("subject") == nil {
+				return nil, fmt.Errorf("parse: %s: no subject block", name)
+			}
+			t.text[key] = tmpl
+
+		case ".html":
+			tmpl, err := htmltemplate.New(key).Parse(string(data))
+			if err != nil {
+				return nil, fmt.Errorf("parse: %s: %w", name, err)
+			}
+			t.html[key] = tmpl
+		}
+	}
+
+	return &t, nil
+}
+
+// Render builds the subject and bodies of the named message in the locale.
+// A regional locale such as el-GR falls back to el, and then to the default
+// locale.
+func (t *Templates) Render(name string, locale string, data any) (Message, error) {
+	key, err := t.lookup(name, locale)
+	if err != nil {
+		return Message{}, err
+	}
+
+	var subject bytes.Buffer
+	if err := t.text[key].ExecuteTemplate(&subject, "subject", data); err != nil {
+		return Message{}, fmt.Errorf("execute: %s subject: %w", key, err)
+	}
+
+	var text bytes.Buffer
+	if err := t.text[key].Execute(&text, data); err != nil {
+		return Message{}, fmt.Errorf("execute: %s.txt: %w", key, err)
+	}
+
+	msg := Message{
+		Subject: strings.TrimSpace(subject.String()),
+		Text:    strings.TrimSpace(text.String()),
+	}
+
+	if tmpl, exists := t.html[key]; exists {
+		var html bytes.Buffer
+		if err := tmpl.Execute(&html, data); err != nil {
+			return Message{}, fmt.Errorf("execute: %s.html: %w", key, err)
+		}
+		msg.HTML = html.String()
+	}
+
+	return msg, nil
+}
+
+// lookup finds the template key to use for the message in the locale.
+func (t *Templates) lookup(name string, locale string) (string, error) {
+	locales := []string{locale}
+	if i := strings.IndexAny(locale, "-_"); i > 0 {
+		locales = append(locales, locale[:i])
+	}
+	locales = append(locales, t.defaultLocale)
+
+	for _, l := range locales {
+		key := name + "." + strings.ToLower(l)
+		if _, exists := t.text[key]; exists {
+			return key, nil
+		}
+	}
+
+	return "", fmt.Errorf("template %q not found", name)
+}
@@ -0,0 +1,198 @@
+package oidc_test
+
+import (
+	"context"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dmanias/startupers/foundation/oidc"
+	"github.com/dmanias/startupers/foundation/oidc/oidctest"
+)
+
+// signIn runs a sign in with the issuer up to the code, and returns the
+// provider, the code and what was kept to complete it.
+func signIn(t *testing.T, iss *oidctest.Issuer, claims oidctest.Claims) (p *oidc.Provider, code string, nonce string, verifier string) {
+	t.Helper()
+
+	p = oidc.NewProvider(iss.Config("test"), nil)
+
+	state := mustRandom(t)
+	nonce = mustRandom(t)
+	verifier = mustRandom(t)
+
+	authURL, err := p.AuthCodeURL(context.Background(), state, nonce, verifier)
+	if err != nil {
+		t.Fatalf("Should be able to build the auth code url: %s", err)
+	}
+
+	if !strings.HasPrefix(authURL, iss.URL()+"/authorize?") {
+		t.Fatalf("Should send the user to the discovered authorization endpoint, got %s", authURL)
+	}
+
+	u, _ := url.Parse(authURL)
+	if got := u.Query().Get("code_challenge"); got != oidc.Challenge(verifier) {
+		t.Fatalf("Should send the S256 challenge of the verifier, got %q", got)
+	}
+
+	gotState, code := iss.Authorize(t, authURL, claims)
+	if gotState != state {
+		t.Fatalf("Should get the state back, got %q", gotState)
+	}
+
+	return p, code, nonce, verifier
+}
+
+func mustRandom(t *testing.T) string {
+	t.Helper()
+
+	s, err := oidc.RandomString()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func Test_SignIn(t *testing.T) {
+	iss := oidctest.NewIssuer(t)
+	ctx := context.Background()
+
+	p, code, nonce, verifier := signIn(t, iss, oidctest.Claims{
+		Subject:       "1234",
+		Email:         "jane@example.com",
+		EmailVerified: true,
+		Name:          "Jane",
+	})
+
+	raw, err := p.Exchange(ctx, code, verifier)
+	if err != nil {
+		t.Fatalf("Should be able to exchange the code: %s", err)
+	}
+
+	idt, err := p.Verify(ctx, raw, nonce)
+	if err != nil {
+		t.Fatalf("Should be able to verify the ID token: %s", err)
+	}
+
+	want := oidc.IDToken{Subject: "1234", Email: "jane@example.com", EmailVerified: true, Name: "Jane"}
+	if idt != want {
+		t.Fatalf("Should get the claims of the sign in, got %+v, want %+v", idt, want)
+	}
+
+	// A code can only be exchanged once.
+	if _, err := p.Exchange(ctx, code, verifier); err == nil {
+		t.Fatalf("Should not be able to exchange the code twice")
+	}
+}
+
+func Test_ExchangeWrongVerifier(t *testing.T) {
+	iss := oidctest.NewIssuer(t)
+
+	p, code, _, _ := signIn(t, iss, oidctest.Claims{Subject: "1234"})
+
+	_, err := p.Exchange(context.Background(), code, mustRandom(t))
+	if err == nil || !strings.Contains(err.Error(), "invalid_grant") {
+		t.Fatalf("Should not exchange the code without the verifier of its challenge, got %v", err)
+	}
+}
+
+func Test_VerifyRejected(t *testing.T) {
+	tt := []struct {
+		name   string
+		claims oidctest.Claims
+		nonce  func(nonce string) string
+		want   string
+	}{
+		{
+			name:   "nonce",
+			claims: oidctest.Claims{Subject: "1234"},
+			nonce:  func(string) string { return "another sign in" },
+			want:   "nonce does not match",
+		},
+		{
+			name:   "audience",
+			claims: oidctest.Claims{Subject: "1234", Audience: "someone-else"},
+			want:   "not issued for this client",
+		},
+		{
+			name:   "issuer",
+			claims: oidctest.Claims{Subject: "1234", Issuer: "https://issuer.example.com"},
+			want:   "issuer",
+		},
+		{
+			name:   "expired",
+			claims: oidctest.Claims{Subject: "1234", ExpiresIn: -time.Minute},
+			want:   "expired",
+		},
+		{
+			name:   "subject",
+			claims: oidctest.Claims{},
+			want:   "no subject",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			iss := oidctest.NewIssuer(t)
+			ctx := context.Background()
+
+			p, code, nonce, verifier := signIn(t, iss, tc.claims)
+
+			raw, err := p.Exchange(ctx, code, verifier)
+			if err != nil {
+				t.Fatalf("Should be able to exchange the code: %s", err)
+			}
+
+			if tc.nonce != nil {
+				nonce = tc.nonce(nonce)
+			}
+
+			_, err = p.Verify(ctx, raw, nonce)
+			if err == nil || !strings.Contains(err.Error(), tc.want) {
+				t.Fatalf("Should reject the ID token with %q, got %v", tc.want, err)
+			}
+		})
+	}
+}
+
+func Test_VerifyEmailVerified(t *testing.T) {
+	tt := []struct {
+		name     string
+		verified any
+		want     bool
+	}{
+		{name: "true", verified: true, want: true},
+		{name: "string", verified: "true", want: true},
+		{name: "false", verified: false, want: false},
+		{name: "false string", verified: "false", want: false},
+		{name: "missing", verified: nil, want: false},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			iss := oidctest.NewIssuer(t)
+			ctx := context.Background()
+
+			p, code, nonce, verifier := signIn(t, iss, oidctest.Claims{
+				Subject:       "1234",
+				Email:         "jane@example.com",
+				EmailVerified: tc.verified,
+			})
+
+			raw, err := p.Exchange(ctx, code, verifier)
+			if err != nil {
+				t.Fatalf("Should be able to exchange the code: %s", err)
+			}
+
+			idt, err := p.Verify(ctx, raw, nonce)
+			if err != nil {
+				t.Fatalf("Should be able to verify the ID token: %s", err)
+			}
+
+			if idt.EmailVerified != tc.want {
+				t.Fatalf("Should read email_verified %v as %v, got %v", tc.verified, tc.want, idt.EmailVerified)
+			}
+		})
+	}
+}
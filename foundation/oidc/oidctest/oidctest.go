@@ -0,0 +1,272 @@
+// Package oidctest provides an in-process OpenID Connect issuer for testing
+// code that signs users in with a provider. It serves the discovery document,
+// the signing keys and the token endpoint, and checks the PKCE verifier when
+// a code is exchanged.
+package oidctest
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/dmanias/startupers/foundation/oidc"
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// Client credentials the issuer accepts, and where it sends users back to.
+const (
+	ClientID     = "startupers"
+	ClientSecret = "secret"
+	RedirectURL  = "https://startupers.test/v1/auth/oidc/test/callback"
+)
+
+// kid is the ID of the issuer's signing key.
+const kid = "oidctest"
+
+// Claims is what the issuer asserts in the ID token of a sign in. Issuer,
+// Audience and Nonce default to the issuer's URL, the client ID and the nonce
+// of the sign in; set them to issue a token that should be rejected.
+type Claims struct {
+	Subject       string
+	Email         string
+	EmailVerified any
+	Name          string
+	Issuer        string
+	Audience      string
+	Nonce         string
+	ExpiresIn     time.Duration
+}
+
+// grant is a sign in the issuer handed a code out for.
+type grant struct {
+	challenge string
+	nonce     string
+	claims    Claims
+}
+
+// Issuer is an OpenID Connect provider listening on the loopback interface.
+type Issuer struct {
+	srv *httptest.Server
+	key *rsa.PrivateKey
+
+	mu     sync.Mutex
+	grants map[string]grant
+	codes  int
+}
+
+// NewIssuer starts an issuer that is stopped when the test ends.
+func NewIssuer(t *testing.T) *Issuer {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generating key: %v", err)
+	}
+
+	iss := Issuer{
+		key:    key,
+		grants: make(map[string]grant),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", iss.discovery)
+	mux.HandleFunc("/jwks", iss.jwks)
+	mux.HandleFunc("/token", iss.token)
+
+	iss.srv = httptest.NewServer(mux)
+	t.Cleanup(iss.srv.Close)
+
+	return &iss
+}
+
+// URL returns the issuer's URL.
+func (iss *Issuer) URL() string {
+	return iss.srv.URL
+}
+
+// Config returns the settings of a provider registered with the issuer.
+func (iss *Issuer) Config(name string) oidc.Config {
+	return oidc.Config{
+		Name:         name,
+		Issuer:       iss.srv.URL,
+		ClientID:     ClientID,
+		ClientSecret: ClientSecret,
+		RedirectURL:  RedirectURL,
+	}
+}
+
+// Authorize stands in for the user signing in at the issuer. It reads the
+// sign in from the URL the provider built, and returns the state and the
+// code the user is sent back with.
+func (iss *Issuer) Authorize(t *testing.T, authURL string, claims Claims) (state string, code string) {
+	t.Helper()
+
+	u, err := url.Parse(authURL)
+	if err != nil {
+		t.Fatalf("parsing auth url: %v", err)
+	}
+	q := u.Query()
+
+	switch {
+	case q.Get("response_type") != "code":
+		t.Fatalf("response_type: got %q, want code", q.Get("response_type"))
+	case q.Get("client_id") != ClientID:
+		t.Fatalf("client_id: got %q, want %q", q.Get("client_id"), ClientID)
+	case q.Get("redirect_uri") != RedirectURL:
+		t.Fatalf("redirect_uri: got %q, want %q", q.Get("redirect_uri"), RedirectURL)
+	case q.Get("code_challenge_method") != "S256" || q.Get("code_challenge") == "":
+		t.Fatalf("sign in has no S256 code challenge")
+	}
+
+	iss.mu.Lock()
+	defer iss.mu.Unlock()
+
+	iss.codes++
+	code = "code-" + strconv.Itoa(iss.codes)
+
+	iss.grants[code] = grant{
+		challenge: q.Get("code_challenge"),
+		nonce:     q.Get("nonce"),
+		claims:    claims,
+	}
+
+	return q.Get("state"), code
+}
+
+// =============================================================================
+
+func (iss *Issuer) discovery(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]string{
+		"issuer":                 iss.srv.URL,
+		"authorization_endpoint": iss.srv.URL + "/authorize",
+		"token_endpoint":         iss.srv.URL + "/token",
+		"jwks_uri":               iss.srv.URL + "/jwks",
+	})
+}
+
+func (iss *Issuer) jwks(w http.ResponseWriter, r *http.Request) {
+	pub := iss.key.PublicKey
+
+	writeJSON(w, http.StatusOK, map[string]any{
+		"keys": []map[string]string{{
+			"kty": "RSA",
+			"kid": kid,
+			"use": "sig",
+			"alg": "RS256",
+			"n":   base64.RawURLEncoding.EncodeToString(pub.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes()),
+		}},
+	})
+}
+
+// token exchanges a code for an ID token. The code can only be used once, by
+// the client it was issued to, with the verifier of its challenge.
+func (iss *Issuer) token(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		tokenError(w, "invalid_request", err.Error())
+		return
+	}
+
+	if r.PostForm.Get("client_id") != ClientID || r.PostForm.Get("client_secret") != ClientSecret {
+		tokenError(w, "invalid_client", "unknown client")
+		return
+	}
+	if r.PostForm.Get("grant_type") != "authorization_code" || r.PostForm.Get("redirect_uri") != RedirectURL {
+		tokenError(w, "invalid_request", "unexpected grant")
+		return
+	}
+
+	iss.mu.Lock()
+	g, exists := iss.grants[r.PostForm.Get("code")]
+	delete(iss.grants, r.PostForm.Get("code"))
+	iss.mu.Unlock()
+
+	if !exists {
+		tokenError(w, "invalid_grant", "unknown code")
+		return
+	}
+
+	sum := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+	if base64.RawURLEncoding.EncodeToString(sum[:]) != g.challenge {
+		tokenError(w, "invalid_grant", "code verifier does not match the challenge")
+		return
+	}
+
+	idToken, err := iss.sign(g)
+	if err != nil {
+		tokenError(w, "server_error", err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]string{
+		"access_token": "access",
+		"token_type":   "Bearer",
+		"id_token":     idToken,
+	})
+}
+
+// sign issues the ID token of the sign in.
+func (iss *Issuer) sign(g grant) (string, error) {
+	c := g.claims
+
+	if c.Issuer == "" {
+		c.Issuer = iss.srv.URL
+	}
+	if c.Audience == "" {
+		c.Audience = ClientID
+	}
+	if c.Nonce == "" {
+		c.Nonce = g.nonce
+	}
+	if c.ExpiresIn == 0 {
+		c.ExpiresIn = time.Hour
+	}
+
+	now := time.Now()
+
+	claims := jwt.MapClaims{
+		"iss":   c.Issuer,
+		"sub":   c.Subject,
+		"aud":   c.Audience,
+		"nonce": c.Nonce,
+		"iat":   now.Unix(),
+		"exp":   now.Add(c.ExpiresIn).Unix(),
+	}
+	if c.Email != "" {
+		claims["email"] = c.Email
+	}
+	if c.EmailVerified != nil {
+		claims["email_verified"] = c.EmailVerified
+	}
+	if c.Name != "" {
+		claims["name"] = c.Name
+	}
+
+	tkn := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	tkn.Header["kid"] = kid
+
+	return tkn.SignedString(iss.key)
+}
+
+func tokenError(w http.ResponseWriter, code string, description string) {
+	writeJSON(w, http.StatusBadRequest, map[string]string{
+		"error":             code,
+		"error_description": description,
+	})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
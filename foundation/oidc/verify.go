@@ -0,0 +1,85 @@
+package oidc
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// idClaims are the claims of an ID token the service reads.
+type idClaims struct {
+	jwt.RegisteredClaims
+	Nonce         string `json:"nonce"`
+	Email         string `json:"email"`
+	EmailVerified any    `json:"email_verified"`
+	Name          string `json:"name"`
+}
+
+// Verify checks the ID token was signed by the provider, was issued for this
+// service, hasn't expired, and carries the nonce of the sign in it completes.
+func (p *Provider) Verify(ctx context.Context, rawIDToken string, nonce string) (IDToken, error) {
+	d, err := p.discover(ctx)
+	if err != nil {
+		return IDToken{}, err
+	}
+
+	parser := jwt.NewParser(jwt.WithValidMethods([]string{
+		jwt.SigningMethodRS256.Name,
+		jwt.SigningMethodRS384.Name,
+		jwt.SigningMethodRS512.Name,
+		jwt.SigningMethodES256.Name,
+		jwt.SigningMethodES384.Name,
+	}))
+
+	var claims idClaims
+	keyFunc := func(token *jwt.Token) (any, error) {
+		kid, _ := token.Header["kid"].(string)
+		return p.keys.key(ctx, kid)
+	}
+
+	if _, err := parser.ParseWithClaims(rawIDToken, &claims, keyFunc); err != nil {
+		return IDToken{}, fmt.Errorf("verify: %w", err)
+	}
+
+	if claims.Issuer != d.Issuer {
+		return IDToken{}, fmt.Errorf("verify: issuer %q does not match %q", claims.Issuer, d.Issuer)
+	}
+	if !claims.VerifyAudience(p.cfg.ClientID, true) {
+		return IDToken{}, errors.New("verify: token was not issued for this client")
+	}
+	if claims.ExpiresAt == nil {
+		return IDToken{}, errors.New("verify: token has no expiry")
+	}
+	if subtle.ConstantTimeCompare([]byte(claims.Nonce), []byte(nonce)) != 1 {
+		return IDToken{}, errors.New("verify: nonce does not match")
+	}
+	if claims.Subject == "" {
+		return IDToken{}, errors.New("verify: token has no subject")
+	}
+
+	idt := IDToken{
+		Subject:       claims.Subject,
+		Email:         claims.Email,
+		EmailVerified: parseBool(claims.EmailVerified),
+		Name:          claims.Name,
+	}
+
+	return idt, nil
+}
+
+// parseBool reads a claim some providers send as a boolean and others as a
+// string.
+func parseBool(v any) bool {
+	switch b := v.(type) {
+	case bool:
+		return b
+	case string:
+		ok, _ := strconv.ParseBool(b)
+		return ok
+	}
+	return false
+}
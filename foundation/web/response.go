@@ -3,7 +3,9 @@ package web
 import (
 	"context"
 	"encoding/json"
+	"mime"
 	"net/http"
+	"strconv"
 )
 
 // Respond converts a Go value to JSON and sends it to the client.
@@ -29,3 +31,20 @@ func Respond(ctx context.Context, w http.ResponseWriter, data any, statusCode in
 
 	return nil
 }
+
+// RespondFile sends the data to the client as a file to download with the
+// specified name.
+func RespondFile(ctx context.Context, w http.ResponseWriter, data []byte, contentType string, filename string) error {
+	setStatusCode(ctx, http.StatusOK)
+
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": filename}))
+	w.Header().Set("Content-Length", strconv.Itoa(len(data)))
+	w.WriteHeader(http.StatusOK)
+
+	if _, err := w.Write(data); err != nil {
+		return err
+	}
+
+	return nil
+}
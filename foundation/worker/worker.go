@@ -0,0 +1,104 @@
+// Package worker runs functions periodically in the background.
+package worker
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// JobFunc is the work performed on every run of a job.
+type JobFunc func(ctx context.Context) error
+
+// Worker manages a set of jobs running on a fixed interval.
+type Worker struct {
+	log      *zap.SugaredLogger
+	wg       sync.WaitGroup
+	shutdown chan struct{}
+	once     sync.Once
+}
+
+// New constructs a Worker with no jobs.
+func New(log *zap.SugaredLogger) *Worker {
+	return &Worker{
+		log:      log,
+		shutdown: make(chan struct{}),
+	}
+}
+
+// Every starts running the job right away and then every interval until the
+// worker is shut down. Each run is given at most one interval to complete.
+// Errors are logged and the job keeps being scheduled.
+func (w *Worker) Every(name string, interval time.Duration, fn JobFunc) {
+	w.wg.Add(1)
+
+	go func() {
+		defer w.wg.Done()
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			w.run(name, interval, fn)
+
+			select {
+			case <-ticker.C:
+			case <-w.shutdown:
+				return
+			}
+		}
+	}()
+}
+
+// Shutdown stops scheduling jobs and waits for the running ones to finish or
+// for the context to be done.
+func (w *Worker) Shutdown(ctx context.Context) error {
+	w.once.Do(func() {
+		close(w.shutdown)
+	})
+
+	done := make(chan struct{})
+	go func() {
+		w.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return errors.New("timed out waiting for jobs to finish")
+	}
+}
+
+func (w *Worker) run(name string, timeout time.Duration, fn JobFunc) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	// Abandon the run as soon as the worker is shut down.
+	go func() {
+		select {
+		case <-w.shutdown:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	defer func() {
+		if rec := recover(); rec != nil {
+			w.log.Errorw("worker", "job", name, "status", "panic", "ERROR", rec)
+		}
+	}()
+
+	start := time.Now()
+
+	if err := fn(ctx); err != nil {
+		w.log.Errorw("worker", "job", name, "status", "failed", "since", time.Since(start).String(), "ERROR", err)
+		return
+	}
+
+	w.log.Infow("worker", "job", name, "status", "completed", "since", time.Since(start).String())
+}
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_ideas_user_id;
+DROP INDEX IF EXISTS idx_ideas_privacy;
+
+ALTER TABLE ideas
+    DROP CONSTRAINT IF EXISTS ideas_privacy_check;
@@ -0,0 +1,12 @@
+-- Restrict idea privacy to the known levels. Anything else is treated as
+-- private so no idea becomes more visible than before.
+UPDATE ideas
+SET privacy = 'private'
+WHERE privacy NOT IN ('private', 'collaborators', 'unlisted', 'public');
+
+ALTER TABLE ideas
+    ADD CONSTRAINT ideas_privacy_check CHECK (privacy IN ('private', 'collaborators', 'unlisted', 'public'));
+
+-- Listings filter on privacy and owner on every request.
+CREATE INDEX IF NOT EXISTS idx_ideas_privacy ON ideas (privacy);
+CREATE INDEX IF NOT EXISTS idx_ideas_user_id ON ideas (user_id);
@@ -0,0 +1,35 @@
+-- Invitations to collaborate on an idea. A user only becomes a collaborator
+-- once they accept. Invitations sent to an email address without an account
+-- keep invitee_id empty until they are accepted.
+CREATE TABLE IF NOT EXISTS idea_invitations
+(
+    id           UUID        NOT NULL,
+    idea_id      UUID        NOT NULL,
+    inviter_id   UUID        NOT NULL,
+    invitee_id   UUID        NULL,
+    email        TEXT        NULL,
+    permission   TEXT        NOT NULL,
+    status       TEXT        NOT NULL,
+    token_hash   BYTEA       NOT NULL,
+    date_expires TIMESTAMPTZ NOT NULL,
+    date_created TIMESTAMPTZ NOT NULL,
+    date_updated TIMESTAMPTZ NOT NULL,
+
+    PRIMARY KEY (id),
+    UNIQUE (token_hash),
+    FOREIGN KEY (idea_id) REFERENCES ideas (id) ON DELETE CASCADE,
+    FOREIGN KEY (inviter_id) REFERENCES users (id),
+    FOREIGN KEY (invitee_id) REFERENCES users (id),
+    CONSTRAINT idea_invitations_permission_check CHECK (permission IN ('viewer', 'editor')),
+    CONSTRAINT idea_invitations_status_check CHECK (status IN ('pending', 'accepted', 'declined', 'revoked')),
+    CONSTRAINT idea_invitations_invitee_check CHECK (invitee_id IS NOT NULL OR email IS NOT NULL)
+);
+
+-- Only one open invitation per invitee and idea.
+CREATE UNIQUE INDEX IF NOT EXISTS idx_idea_invitations_pending_invitee
+    ON idea_invitations (idea_id, invitee_id) WHERE status = 'pending' AND invitee_id IS NOT NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_idea_invitations_pending_email
+    ON idea_invitations (idea_id, lower(email)) WHERE status = 'pending' AND email IS NOT NULL;
+
+CREATE INDEX IF NOT EXISTS idx_idea_invitations_invitee_id ON idea_invitations (invitee_id);
+CREATE INDEX IF NOT EXISTS idx_idea_invitations_email ON idea_invitations (lower(email));
@@ -0,0 +1,8 @@
+DROP TABLE IF EXISTS idea_stage_history;
+
+DROP INDEX IF EXISTS idx_ideas_stage;
+
+ALTER TABLE ideas
+    DROP CONSTRAINT IF EXISTS ideas_stage_check,
+    ALTER COLUMN stage DROP NOT NULL,
+    ALTER COLUMN stage DROP DEFAULT;
@@ -0,0 +1,30 @@
+-- Restrict idea stages to the lifecycle. Ideas with an unknown stage start
+-- over as a spark.
+UPDATE ideas
+SET stage = 'spark'
+WHERE stage IS NULL OR stage NOT IN ('spark', 'validation', 'prototype', 'launch', 'archived');
+
+ALTER TABLE ideas
+    ALTER COLUMN stage SET DEFAULT 'spark',
+    ALTER COLUMN stage SET NOT NULL,
+    ADD CONSTRAINT ideas_stage_check CHECK (stage IN ('spark', 'validation', 'prototype', 'launch', 'archived'));
+
+CREATE INDEX IF NOT EXISTS idx_ideas_stage ON ideas (stage);
+
+-- Every move of an idea between stages, and who made it.
+CREATE TABLE IF NOT EXISTS idea_stage_history
+(
+    id           UUID        NOT NULL,
+    idea_id      UUID        NOT NULL,
+    user_id      UUID        NOT NULL,
+    from_stage   VARCHAR(50) NOT NULL,
+    to_stage     VARCHAR(50) NOT NULL,
+    note         TEXT        NOT NULL DEFAULT '',
+    date_created TIMESTAMPTZ NOT NULL,
+
+    PRIMARY KEY (id),
+    FOREIGN KEY (idea_id) REFERENCES ideas (id) ON DELETE CASCADE,
+    FOREIGN KEY (user_id) REFERENCES users (id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_idea_stage_history_idea_id ON idea_stage_history (idea_id, date_created);
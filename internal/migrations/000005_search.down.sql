@@ -0,0 +1,16 @@
+DROP TRIGGER IF EXISTS challenges_search_index ON challenges;
+DROP TRIGGER IF EXISTS posts_search_index ON posts;
+DROP TRIGGER IF EXISTS ideas_search_index ON ideas;
+
+DROP FUNCTION IF EXISTS search_reindex();
+DROP FUNCTION IF EXISTS search_index_challenge();
+DROP FUNCTION IF EXISTS search_index_post();
+DROP FUNCTION IF EXISTS search_index_idea();
+DROP FUNCTION IF EXISTS search_challenge_document(challenges);
+DROP FUNCTION IF EXISTS search_post_document(posts);
+DROP FUNCTION IF EXISTS search_idea_document(ideas);
+
+DROP TABLE IF EXISTS search_documents;
+
+DROP FUNCTION IF EXISTS search_language();
+DROP TABLE IF EXISTS search_settings;
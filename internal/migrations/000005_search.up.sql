@@ -0,0 +1,179 @@
+-- Full-text search over ideas, posts and challenges. Every searchable row has a
+-- document in search_documents that is kept up to date by triggers, so one
+-- query can rank all result types together.
+
+-- The text search configuration used to index and query documents. Changing it
+-- requires running search_reindex().
+CREATE TABLE IF NOT EXISTS search_settings
+(
+    id       BOOLEAN   NOT NULL DEFAULT TRUE,
+    language REGCONFIG NOT NULL DEFAULT 'english',
+
+    PRIMARY KEY (id),
+    CONSTRAINT search_settings_single_row CHECK (id)
+);
+
+INSERT INTO search_settings DEFAULT VALUES ON CONFLICT DO NOTHING;
+
+CREATE OR REPLACE FUNCTION search_language() RETURNS REGCONFIG
+    LANGUAGE sql
+    STABLE
+AS
+$$
+SELECT coalesce((SELECT language FROM search_settings), 'english'::REGCONFIG)
+$$;
+
+CREATE TABLE IF NOT EXISTS search_documents
+(
+    kind         VARCHAR(20) NOT NULL,
+    id           UUID        NOT NULL,
+    idea_id      UUID        NOT NULL,
+    title        TEXT        NOT NULL DEFAULT '',
+    body         TEXT        NOT NULL DEFAULT '',
+    document     TSVECTOR    NOT NULL,
+    date_created TIMESTAMPTZ NOT NULL,
+
+    PRIMARY KEY (kind, id),
+    FOREIGN KEY (idea_id) REFERENCES ideas (id) ON DELETE CASCADE,
+    CONSTRAINT search_documents_kind_check CHECK (kind IN ('idea', 'post', 'challenge'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_search_documents_document ON search_documents USING GIN (document);
+CREATE INDEX IF NOT EXISTS idx_search_documents_idea_id ON search_documents (idea_id);
+
+-- Document builders. Titles weigh the most, then tags and category, then the
+-- body text.
+CREATE OR REPLACE FUNCTION search_idea_document(i ideas) RETURNS TSVECTOR
+    LANGUAGE sql
+    STABLE
+AS
+$$
+SELECT setweight(to_tsvector(search_language(), coalesce(i.title, '')), 'A') ||
+       setweight(to_tsvector(search_language(), array_to_string(coalesce(i.tags, '{}'), ' ')), 'B') ||
+       setweight(to_tsvector(search_language(), coalesce(i.category, '')), 'B') ||
+       setweight(to_tsvector(search_language(), coalesce(i.description, '')), 'C') ||
+       setweight(to_tsvector(search_language(), coalesce(i.inspiration, '')), 'D')
+$$;
+
+CREATE OR REPLACE FUNCTION search_post_document(p posts) RETURNS TSVECTOR
+    LANGUAGE sql
+    STABLE
+AS
+$$
+SELECT setweight(to_tsvector(search_language(), coalesce(p.content, '')), 'C')
+$$;
+
+CREATE OR REPLACE FUNCTION search_challenge_document(c challenges) RETURNS TSVECTOR
+    LANGUAGE sql
+    STABLE
+AS
+$$
+SELECT setweight(to_tsvector(search_language(), coalesce(c.answer, '')), 'C')
+$$;
+
+-- Triggers keeping search_documents in sync.
+CREATE OR REPLACE FUNCTION search_index_idea() RETURNS TRIGGER
+    LANGUAGE plpgsql
+AS
+$$
+BEGIN
+    IF TG_OP = 'DELETE' THEN
+        DELETE FROM search_documents WHERE kind = 'idea' AND id = OLD.id;
+        RETURN OLD;
+    END IF;
+
+    INSERT INTO search_documents (kind, id, idea_id, title, body, document, date_created)
+    VALUES ('idea', NEW.id, NEW.id, NEW.title, concat_ws(' ', NEW.description, NEW.inspiration),
+            search_idea_document(NEW), coalesce(NEW.date_created, now()))
+    ON CONFLICT (kind, id) DO UPDATE
+        SET title    = EXCLUDED.title,
+            body     = EXCLUDED.body,
+            document = EXCLUDED.document;
+
+    RETURN NEW;
+END;
+$$;
+
+CREATE OR REPLACE FUNCTION search_index_post() RETURNS TRIGGER
+    LANGUAGE plpgsql
+AS
+$$
+BEGIN
+    IF TG_OP = 'DELETE' THEN
+        DELETE FROM search_documents WHERE kind = 'post' AND id = OLD.id;
+        RETURN OLD;
+    END IF;
+
+    INSERT INTO search_documents (kind, id, idea_id, body, document, date_created)
+    VALUES ('post', NEW.id, NEW.idea_id, NEW.content, search_post_document(NEW), coalesce(NEW.date_created, now()))
+    ON CONFLICT (kind, id) DO UPDATE
+        SET body     = EXCLUDED.body,
+            document = EXCLUDED.document;
+
+    RETURN NEW;
+END;
+$$;
+
+CREATE OR REPLACE FUNCTION search_index_challenge() RETURNS TRIGGER
+    LANGUAGE plpgsql
+AS
+$$
+BEGIN
+    IF TG_OP = 'DELETE' THEN
+        DELETE FROM search_documents WHERE kind = 'challenge' AND id = OLD.id;
+        RETURN OLD;
+    END IF;
+
+    INSERT INTO search_documents (kind, id, idea_id, body, document, date_created)
+    VALUES ('challenge', NEW.id, NEW.idea_id, coalesce(NEW.answer, ''), search_challenge_document(NEW), NEW.date_created)
+    ON CONFLICT (kind, id) DO UPDATE
+        SET body     = EXCLUDED.body,
+            document = EXCLUDED.document;
+
+    RETURN NEW;
+END;
+$$;
+
+DROP TRIGGER IF EXISTS ideas_search_index ON ideas;
+CREATE TRIGGER ideas_search_index
+    AFTER INSERT OR UPDATE OF title, description, category, tags, inspiration OR DELETE
+    ON ideas
+    FOR EACH ROW
+EXECUTE FUNCTION search_index_idea();
+
+DROP TRIGGER IF EXISTS posts_search_index ON posts;
+CREATE TRIGGER posts_search_index
+    AFTER INSERT OR UPDATE OF content OR DELETE
+    ON posts
+    FOR EACH ROW
+EXECUTE FUNCTION search_index_post();
+
+DROP TRIGGER IF EXISTS challenges_search_index ON challenges;
+CREATE TRIGGER challenges_search_index
+    AFTER INSERT OR UPDATE OF answer OR DELETE
+    ON challenges
+    FOR EACH ROW
+EXECUTE FUNCTION search_index_challenge();
+
+-- Rebuilds every document, e.g. after the search language was changed.
+CREATE OR REPLACE FUNCTION search_reindex() RETURNS VOID
+    LANGUAGE sql
+AS
+$$
+DELETE FROM search_documents;
+
+INSERT INTO search_documents (kind, id, idea_id, title, body, document, date_created)
+SELECT 'idea', i.id, i.id, i.title, concat_ws(' ', i.description, i.inspiration), search_idea_document(i),
+       coalesce(i.date_created, now())
+FROM ideas i;
+
+INSERT INTO search_documents (kind, id, idea_id, body, document, date_created)
+SELECT 'post', p.id, p.idea_id, p.content, search_post_document(p), coalesce(p.date_created, now())
+FROM posts p;
+
+INSERT INTO search_documents (kind, id, idea_id, body, document, date_created)
+SELECT 'challenge', c.id, c.idea_id, coalesce(c.answer, ''), search_challenge_document(c), c.date_created
+FROM challenges c;
+$$;
+
+SELECT search_reindex();
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_posts_idea_id_date_created;
+DROP TABLE IF EXISTS idea_scores;
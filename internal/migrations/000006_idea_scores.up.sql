@@ -0,0 +1,17 @@
+-- Trending scores of public ideas, recomputed periodically so the explore
+-- feed only has to read them.
+CREATE TABLE IF NOT EXISTS idea_scores
+(
+    idea_id       UUID             NOT NULL,
+    score         DOUBLE PRECISION NOT NULL,
+    date_computed TIMESTAMPTZ      NOT NULL,
+
+    PRIMARY KEY (idea_id),
+    FOREIGN KEY (idea_id) REFERENCES ideas (id) ON DELETE CASCADE
+);
+
+-- The feed pages through scores with a (score, idea_id) keyset.
+CREATE INDEX IF NOT EXISTS idx_idea_scores_feed ON idea_scores (score DESC, idea_id DESC);
+
+-- Recent posts are counted per idea on every recomputation.
+CREATE INDEX IF NOT EXISTS idx_posts_idea_id_date_created ON posts (idea_id, date_created);
@@ -0,0 +1,10 @@
+ALTER TABLE challenges
+    DROP COLUMN IF EXISTS forked_from;
+
+ALTER TABLE posts
+    DROP COLUMN IF EXISTS forked_from;
+
+DROP INDEX IF EXISTS idx_ideas_forked_from;
+
+ALTER TABLE ideas
+    DROP COLUMN IF EXISTS forked_from;
@@ -0,0 +1,13 @@
+-- Link a forked idea to the idea it was copied from. Forks survive the
+-- deletion of the original and become the root of their own tree.
+ALTER TABLE ideas
+    ADD COLUMN IF NOT EXISTS forked_from UUID NULL REFERENCES ideas (id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_ideas_forked_from ON ideas (forked_from);
+
+-- Posts and challenges copied into a fork keep the one they were copied from.
+ALTER TABLE posts
+    ADD COLUMN IF NOT EXISTS forked_from UUID NULL REFERENCES posts (id) ON DELETE SET NULL;
+
+ALTER TABLE challenges
+    ADD COLUMN IF NOT EXISTS forked_from UUID NULL REFERENCES challenges (id) ON DELETE SET NULL;
@@ -0,0 +1,14 @@
+-- Immutable history of the content of ideas. Every update that changes the
+-- content of an idea writes a new revision holding a snapshot of it.
+CREATE TABLE IF NOT EXISTS idea_revisions
+(
+    id             UUID PRIMARY KEY,
+    idea_id        UUID        NOT NULL REFERENCES ideas (id) ON DELETE CASCADE,
+    number         INT         NOT NULL,
+    user_id        UUID        NOT NULL REFERENCES users (id),
+    changed_fields TEXT[]      NOT NULL DEFAULT '{}',
+    snapshot       JSONB       NOT NULL,
+    restored_from  INT         NULL,
+    date_created   TIMESTAMPTZ NOT NULL,
+    UNIQUE (idea_id, number)
+);
@@ -0,0 +1,106 @@
+-- Deleted ideas, posts and challenges are kept in the trash until they are
+-- restored or purged once the retention period is over.
+ALTER TABLE ideas
+    ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ NULL,
+    ADD COLUMN IF NOT EXISTS deleted_by UUID        NULL REFERENCES users (id);
+
+ALTER TABLE posts
+    ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ NULL,
+    ADD COLUMN IF NOT EXISTS deleted_by UUID        NULL REFERENCES users (id);
+
+ALTER TABLE challenges
+    ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ NULL,
+    ADD COLUMN IF NOT EXISTS deleted_by UUID        NULL REFERENCES users (id);
+
+-- The trash listings and the purge job only look at deleted rows.
+CREATE INDEX IF NOT EXISTS idx_ideas_deleted_at ON ideas (deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_posts_deleted_at ON posts (deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_challenges_deleted_at ON challenges (deleted_at) WHERE deleted_at IS NOT NULL;
+
+-- Posts and challenges in the trash are taken out of the search index. Ideas
+-- in the trash are filtered out at query time, which also hides their content.
+CREATE OR REPLACE FUNCTION search_index_post() RETURNS TRIGGER
+    LANGUAGE plpgsql
+AS
+$$
+BEGIN
+    IF TG_OP = 'DELETE' THEN
+        DELETE FROM search_documents WHERE kind = 'post' AND id = OLD.id;
+        RETURN OLD;
+    END IF;
+
+    IF NEW.deleted_at IS NOT NULL THEN
+        DELETE FROM search_documents WHERE kind = 'post' AND id = NEW.id;
+        RETURN NEW;
+    END IF;
+
+    INSERT INTO search_documents (kind, id, idea_id, body, document, date_created)
+    VALUES ('post', NEW.id, NEW.idea_id, NEW.content, search_post_document(NEW), coalesce(NEW.date_created, now()))
+    ON CONFLICT (kind, id) DO UPDATE
+        SET body     = EXCLUDED.body,
+            document = EXCLUDED.document;
+
+    RETURN NEW;
+END;
+$$;
+
+CREATE OR REPLACE FUNCTION search_index_challenge() RETURNS TRIGGER
+    LANGUAGE plpgsql
+AS
+$$
+BEGIN
+    IF TG_OP = 'DELETE' THEN
+        DELETE FROM search_documents WHERE kind = 'challenge' AND id = OLD.id;
+        RETURN OLD;
+    END IF;
+
+    IF NEW.deleted_at IS NOT NULL THEN
+        DELETE FROM search_documents WHERE kind = 'challenge' AND id = NEW.id;
+        RETURN NEW;
+    END IF;
+
+    INSERT INTO search_documents (kind, id, idea_id, body, document, date_created)
+    VALUES ('challenge', NEW.id, NEW.idea_id, coalesce(NEW.answer, ''), search_challenge_document(NEW), NEW.date_created)
+    ON CONFLICT (kind, id) DO UPDATE
+        SET body     = EXCLUDED.body,
+            document = EXCLUDED.document;
+
+    RETURN NEW;
+END;
+$$;
+
+DROP TRIGGER IF EXISTS posts_search_index ON posts;
+CREATE TRIGGER posts_search_index
+    AFTER INSERT OR UPDATE OF content, deleted_at OR DELETE
+    ON posts
+    FOR EACH ROW
+EXECUTE FUNCTION search_index_post();
+
+DROP TRIGGER IF EXISTS challenges_search_index ON challenges;
+CREATE TRIGGER challenges_search_index
+    AFTER INSERT OR UPDATE OF answer, deleted_at OR DELETE
+    ON challenges
+    FOR EACH ROW
+EXECUTE FUNCTION search_index_challenge();
+
+CREATE OR REPLACE FUNCTION search_reindex() RETURNS VOID
+    LANGUAGE sql
+AS
+$$
+DELETE FROM search_documents;
+
+INSERT INTO search_documents (kind, id, idea_id, title, body, document, date_created)
+SELECT 'idea', i.id, i.id, i.title, concat_ws(' ', i.description, i.inspiration), search_idea_document(i),
+       coalesce(i.date_created, now())
+FROM ideas i;
+
+INSERT INTO search_documents (kind, id, idea_id, body, document, date_created)
+SELECT 'post', p.id, p.idea_id, p.content, search_post_document(p), coalesce(p.date_created, now())
+FROM posts p
+WHERE p.deleted_at IS NULL;
+
+INSERT INTO search_documents (kind, id, idea_id, body, document, date_created)
+SELECT 'challenge', c.id, c.idea_id, coalesce(c.answer, ''), search_challenge_document(c), c.date_created
+FROM challenges c
+WHERE c.deleted_at IS NULL;
+$$;
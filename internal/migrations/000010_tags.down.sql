@@ -0,0 +1,9 @@
+-- Ideas keep the normalised slugs they were rewritten to.
+DROP TRIGGER IF EXISTS ideas_tags_usage ON ideas;
+DROP FUNCTION IF EXISTS tags_usage();
+DROP FUNCTION IF EXISTS tags_recount(TEXT[]);
+DROP FUNCTION IF EXISTS tag_slug(TEXT);
+
+DROP INDEX IF EXISTS idx_ideas_tags;
+
+DROP TABLE IF EXISTS tags;
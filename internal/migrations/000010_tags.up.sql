@@ -0,0 +1,95 @@
+-- The tag vocabulary. Ideas refer to tags by their slug, the canonical form of
+-- a tag name. Aliases are slugs that resolve to the tag, left behind when tags
+-- are renamed or merged. The usage count is the number of public ideas using
+-- the tag and is kept up to date by a trigger on ideas.
+CREATE TABLE IF NOT EXISTS tags
+(
+    slug         TEXT        NOT NULL,
+    name         TEXT        NOT NULL,
+    aliases      TEXT[]      NOT NULL DEFAULT '{}',
+    usage_count  INT         NOT NULL DEFAULT 0,
+    date_created TIMESTAMPTZ NOT NULL,
+    date_updated TIMESTAMPTZ NOT NULL,
+
+    PRIMARY KEY (slug)
+);
+
+-- Autocomplete matches slugs and aliases by prefix, most used first.
+CREATE INDEX IF NOT EXISTS idx_tags_slug_prefix ON tags (slug text_pattern_ops);
+CREATE INDEX IF NOT EXISTS idx_tags_aliases ON tags USING GIN (aliases);
+CREATE INDEX IF NOT EXISTS idx_tags_usage_count ON tags (usage_count DESC);
+
+-- Usage counts, renames and merges look ideas up by tag.
+CREATE INDEX IF NOT EXISTS idx_ideas_tags ON ideas USING GIN (tags);
+
+-- Mirrors tag.Slugify: lower case, every run of other characters than
+-- letters and digits becomes a single dash.
+CREATE OR REPLACE FUNCTION tag_slug(name TEXT) RETURNS TEXT
+    LANGUAGE sql
+    IMMUTABLE
+AS
+$$
+SELECT trim(BOTH '-' FROM regexp_replace(lower(name), '[^[:alnum:]]+', '-', 'g'));
+$$;
+
+-- Build the vocabulary from the tags already on ideas, keeping the name the
+-- tag was first entered with, and rewrite the ideas to use the slugs.
+INSERT INTO tags (slug, name, date_created, date_updated)
+SELECT DISTINCT ON (tag_slug(t.tag)) tag_slug(t.tag), regexp_replace(trim(t.tag), '\s+', ' ', 'g'), now(), now()
+FROM ideas i,
+     unnest(i.tags) AS t(tag)
+WHERE tag_slug(t.tag) <> ''
+ORDER BY tag_slug(t.tag), i.date_created
+ON CONFLICT (slug) DO NOTHING;
+
+UPDATE ideas i
+SET tags = ARRAY(
+        SELECT n.slug
+        FROM (SELECT tag_slug(t.tag) AS slug, min(t.ord) AS ord
+              FROM unnest(i.tags) WITH ORDINALITY AS t(tag, ord)
+              GROUP BY 1) n
+        WHERE n.slug <> ''
+        ORDER BY n.ord)
+WHERE i.tags IS NOT NULL;
+
+CREATE OR REPLACE FUNCTION tags_recount(slugs TEXT[]) RETURNS VOID
+    LANGUAGE sql
+AS
+$$
+UPDATE tags t
+SET usage_count = (SELECT count(1)
+                   FROM ideas i
+                   WHERE i.tags @> ARRAY [t.slug]
+                     AND i.privacy = 'public'
+                     AND i.deleted_at IS NULL)
+WHERE t.slug = ANY (slugs);
+$$;
+
+CREATE OR REPLACE FUNCTION tags_usage() RETURNS TRIGGER
+    LANGUAGE plpgsql
+AS
+$$
+BEGIN
+    IF TG_OP = 'INSERT' THEN
+        PERFORM tags_recount(NEW.tags);
+        RETURN NEW;
+    END IF;
+
+    IF TG_OP = 'DELETE' THEN
+        PERFORM tags_recount(OLD.tags);
+        RETURN OLD;
+    END IF;
+
+    PERFORM tags_recount(coalesce(OLD.tags, '{}') || coalesce(NEW.tags, '{}'));
+    RETURN NEW;
+END;
+$$;
+
+DROP TRIGGER IF EXISTS ideas_tags_usage ON ideas;
+CREATE TRIGGER ideas_tags_usage
+    AFTER INSERT OR UPDATE OF tags, privacy, deleted_at OR DELETE
+    ON ideas
+    FOR EACH ROW
+EXECUTE FUNCTION tags_usage();
+
+SELECT tags_recount(ARRAY(SELECT slug FROM tags));
@@ -0,0 +1,6 @@
+-- Ideas keep the category slugs they were rewritten to.
+DROP FUNCTION IF EXISTS category_subtree(TEXT);
+
+DROP INDEX IF EXISTS idx_ideas_category;
+
+DROP TABLE IF EXISTS categories;
@@ -0,0 +1,49 @@
+-- The category taxonomy ideas are filed under. Ideas refer to categories by
+-- their slug; an empty category means the idea isn't filed anywhere. Labels
+-- hold the name of the category per locale.
+CREATE TABLE IF NOT EXISTS categories
+(
+    slug          TEXT        NOT NULL,
+    parent_slug   TEXT        NULL REFERENCES categories (slug) ON DELETE RESTRICT,
+    display_order INT         NOT NULL DEFAULT 0,
+    labels        JSONB       NOT NULL DEFAULT '{}',
+    date_created  TIMESTAMPTZ NOT NULL,
+    date_updated  TIMESTAMPTZ NOT NULL,
+
+    PRIMARY KEY (slug)
+);
+
+CREATE INDEX IF NOT EXISTS idx_categories_parent_slug ON categories (parent_slug);
+CREATE INDEX IF NOT EXISTS idx_ideas_category ON ideas (category);
+
+-- Returns the slug of the category and of all of its subcategories, so
+-- filtering on a category includes the ideas filed under its subcategories.
+CREATE OR REPLACE FUNCTION category_subtree(root TEXT) RETURNS SETOF TEXT
+    LANGUAGE sql
+    STABLE
+AS
+$$
+WITH RECURSIVE tree AS (SELECT slug
+                        FROM categories
+                        WHERE slug = root
+                        UNION ALL
+                        SELECT c.slug
+                        FROM categories c
+                                 JOIN tree t ON c.parent_slug = t.slug)
+SELECT slug
+FROM tree;
+$$;
+
+-- Turn the categories already on ideas into top level categories, labelled
+-- with the name they were first entered with, and file the ideas under them.
+INSERT INTO categories (slug, labels, date_created, date_updated)
+SELECT DISTINCT ON (tag_slug(category)) tag_slug(category), jsonb_build_object('en', trim(category)), now(), now()
+FROM ideas
+WHERE tag_slug(coalesce(category, '')) <> ''
+ORDER BY tag_slug(category), date_created
+ON CONFLICT (slug) DO NOTHING;
+
+UPDATE ideas
+SET category = tag_slug(category)
+WHERE category IS NOT NULL
+  AND category <> tag_slug(category);
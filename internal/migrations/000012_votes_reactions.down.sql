@@ -0,0 +1,14 @@
+DROP TRIGGER IF EXISTS post_reactions_count ON post_reactions;
+DROP FUNCTION IF EXISTS post_reactions_count();
+DROP TABLE IF EXISTS post_reactions;
+DROP INDEX IF EXISTS idx_posts_reaction_count;
+ALTER TABLE posts
+    DROP COLUMN IF EXISTS reaction_counts,
+    DROP COLUMN IF EXISTS reaction_count;
+
+DROP TRIGGER IF EXISTS idea_votes_count ON idea_votes;
+DROP FUNCTION IF EXISTS idea_votes_count();
+DROP TABLE IF EXISTS idea_votes;
+DROP INDEX IF EXISTS idx_ideas_vote_count;
+ALTER TABLE ideas
+    DROP COLUMN IF EXISTS vote_count;
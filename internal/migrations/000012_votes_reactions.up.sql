@@ -0,0 +1,96 @@
+-- Upvotes on ideas. A user votes an idea at most once; the number of votes is
+-- kept on the idea by a trigger so ideas can be sorted by it.
+ALTER TABLE ideas
+    ADD COLUMN IF NOT EXISTS vote_count INT NOT NULL DEFAULT 0;
+
+CREATE TABLE IF NOT EXISTS idea_votes
+(
+    idea_id      UUID        NOT NULL REFERENCES ideas (id) ON DELETE CASCADE,
+    user_id      UUID        NOT NULL REFERENCES users (id),
+    date_created TIMESTAMPTZ NOT NULL,
+
+    PRIMARY KEY (idea_id, user_id)
+);
+
+-- Voters are listed newest first and trending scores count recent votes.
+CREATE INDEX IF NOT EXISTS idx_idea_votes_idea_date ON idea_votes (idea_id, date_created DESC);
+CREATE INDEX IF NOT EXISTS idx_idea_votes_date ON idea_votes (date_created);
+CREATE INDEX IF NOT EXISTS idx_ideas_vote_count ON ideas (vote_count DESC);
+
+CREATE OR REPLACE FUNCTION idea_votes_count() RETURNS TRIGGER
+    LANGUAGE plpgsql
+AS
+$$
+BEGIN
+    IF TG_OP = 'INSERT' THEN
+        UPDATE ideas SET vote_count = vote_count + 1 WHERE id = NEW.idea_id;
+        RETURN NEW;
+    END IF;
+
+    UPDATE ideas SET vote_count = greatest(vote_count - 1, 0) WHERE id = OLD.idea_id;
+    RETURN OLD;
+END;
+$$;
+
+DROP TRIGGER IF EXISTS idea_votes_count ON idea_votes;
+CREATE TRIGGER idea_votes_count
+    AFTER INSERT OR DELETE
+    ON idea_votes
+    FOR EACH ROW
+EXECUTE FUNCTION idea_votes_count();
+
+-- Emoji reactions on posts. A user can leave several different emoji on a
+-- post but each one only once. The post keeps the total and the count per
+-- emoji, maintained by a trigger.
+ALTER TABLE posts
+    ADD COLUMN IF NOT EXISTS reaction_count  INT   NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS reaction_counts JSONB NOT NULL DEFAULT '{}';
+
+CREATE TABLE IF NOT EXISTS post_reactions
+(
+    post_id      UUID        NOT NULL REFERENCES posts (id) ON DELETE CASCADE,
+    user_id      UUID        NOT NULL REFERENCES users (id),
+    emoji        TEXT        NOT NULL CHECK (emoji IN ('thumbsup', 'heart', 'laugh', 'tada', 'rocket', 'eyes', 'thinking')),
+    date_created TIMESTAMPTZ NOT NULL,
+
+    PRIMARY KEY (post_id, user_id, emoji)
+);
+
+CREATE INDEX IF NOT EXISTS idx_post_reactions_post_date ON post_reactions (post_id, date_created DESC);
+CREATE INDEX IF NOT EXISTS idx_posts_reaction_count ON posts (reaction_count DESC);
+
+CREATE OR REPLACE FUNCTION post_reactions_count() RETURNS TRIGGER
+    LANGUAGE plpgsql
+AS
+$$
+DECLARE
+    n INT;
+BEGIN
+    IF TG_OP = 'INSERT' THEN
+        UPDATE posts
+        SET reaction_count  = reaction_count + 1,
+            reaction_counts = jsonb_set(reaction_counts, ARRAY [NEW.emoji],
+                                        to_jsonb(coalesce((reaction_counts ->> NEW.emoji)::INT, 0) + 1))
+        WHERE id = NEW.post_id;
+        RETURN NEW;
+    END IF;
+
+    SELECT coalesce((reaction_counts ->> OLD.emoji)::INT, 0) INTO n FROM posts WHERE id = OLD.post_id;
+
+    UPDATE posts
+    SET reaction_count  = greatest(reaction_count - 1, 0),
+        reaction_counts = CASE
+                              WHEN n <= 1 THEN reaction_counts - OLD.emoji
+                              ELSE jsonb_set(reaction_counts, ARRAY [OLD.emoji], to_jsonb(n - 1))
+            END
+    WHERE id = OLD.post_id;
+    RETURN OLD;
+END;
+$$;
+
+DROP TRIGGER IF EXISTS post_reactions_count ON post_reactions;
+CREATE TRIGGER post_reactions_count
+    AFTER INSERT OR DELETE
+    ON post_reactions
+    FOR EACH ROW
+EXECUTE FUNCTION post_reactions_count();
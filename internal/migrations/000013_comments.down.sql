@@ -0,0 +1,5 @@
+DROP TRIGGER IF EXISTS challenges_comments_deleted ON challenges;
+DROP TRIGGER IF EXISTS posts_comments_deleted ON posts;
+DROP FUNCTION IF EXISTS comments_owner_deleted();
+
+DROP TABLE IF EXISTS comments;
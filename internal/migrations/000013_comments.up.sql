@@ -0,0 +1,53 @@
+-- Threaded comments on posts and challenges. A comment hangs off its owner,
+-- named by type and ID, and replies point at the comment they answer. The
+-- idea the owner belongs to is kept for access checks and so comments go
+-- when the idea is purged.
+CREATE TABLE IF NOT EXISTS comments
+(
+    id           UUID        NOT NULL,
+    idea_id      UUID        NOT NULL REFERENCES ideas (id) ON DELETE CASCADE,
+    owner_type   TEXT        NOT NULL CHECK (owner_type IN ('post', 'challenge')),
+    owner_id     UUID        NOT NULL,
+    parent_id    UUID        NULL REFERENCES comments (id) ON DELETE CASCADE,
+    depth        INT         NOT NULL DEFAULT 0,
+    author_id    UUID        NOT NULL REFERENCES users (id),
+    content      TEXT        NOT NULL,
+    hidden_at    TIMESTAMPTZ NULL,
+    hidden_by    UUID        NULL REFERENCES users (id),
+    deleted_at   TIMESTAMPTZ NULL,
+    date_created TIMESTAMPTZ NOT NULL,
+    date_updated TIMESTAMPTZ NOT NULL,
+
+    PRIMARY KEY (id)
+);
+
+-- Threads are listed per owner oldest first, replies are found by parent.
+CREATE INDEX IF NOT EXISTS idx_comments_owner ON comments (owner_type, owner_id, date_created);
+CREATE INDEX IF NOT EXISTS idx_comments_parent ON comments (parent_id);
+CREATE INDEX IF NOT EXISTS idx_comments_idea ON comments (idea_id);
+
+-- Owners are not referenced by a foreign key, so their comments are removed
+-- when they are deleted for good.
+CREATE OR REPLACE FUNCTION comments_owner_deleted() RETURNS TRIGGER
+    LANGUAGE plpgsql
+AS
+$$
+BEGIN
+    DELETE FROM comments WHERE owner_type = TG_ARGV[0] AND owner_id = OLD.id;
+    RETURN OLD;
+END;
+$$;
+
+DROP TRIGGER IF EXISTS posts_comments_deleted ON posts;
+CREATE TRIGGER posts_comments_deleted
+    AFTER DELETE
+    ON posts
+    FOR EACH ROW
+EXECUTE FUNCTION comments_owner_deleted('post');
+
+DROP TRIGGER IF EXISTS challenges_comments_deleted ON challenges;
+CREATE TRIGGER challenges_comments_deleted
+    AFTER DELETE
+    ON challenges
+    FOR EACH ROW
+EXECUTE FUNCTION comments_owner_deleted('challenge');
@@ -0,0 +1,6 @@
+DROP TRIGGER IF EXISTS users_follows_deleted ON users;
+DROP TRIGGER IF EXISTS ideas_follows_deleted ON ideas;
+DROP FUNCTION IF EXISTS follows_target_deleted();
+
+DROP TABLE IF EXISTS activities;
+DROP TABLE IF EXISTS follows;
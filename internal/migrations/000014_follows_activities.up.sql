@@ -0,0 +1,57 @@
+-- Users follow ideas and other users. The target is named by type and ID.
+CREATE TABLE IF NOT EXISTS follows
+(
+    user_id      UUID        NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    target_type  TEXT        NOT NULL CHECK (target_type IN ('idea', 'user')),
+    target_id    UUID        NOT NULL,
+    date_created TIMESTAMPTZ NOT NULL,
+
+    PRIMARY KEY (user_id, target_type, target_id)
+);
+
+-- The feed looks up what a user follows, followers are listed per target.
+CREATE INDEX IF NOT EXISTS idx_follows_target ON follows (target_type, target_id);
+
+-- What happened on ideas, as recorded by the cores, for the activity feed.
+-- Privacy is checked against the idea when the feed is read.
+CREATE TABLE IF NOT EXISTS activities
+(
+    id           UUID        NOT NULL,
+    type         TEXT        NOT NULL,
+    actor_id     UUID        NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    idea_id      UUID        NOT NULL REFERENCES ideas (id) ON DELETE CASCADE,
+    subject_id   UUID        NULL,
+    details      JSONB       NOT NULL DEFAULT '{}',
+    date_created TIMESTAMPTZ NOT NULL,
+
+    PRIMARY KEY (id)
+);
+
+-- The feed is read newest first by idea and by actor.
+CREATE INDEX IF NOT EXISTS idx_activities_idea ON activities (idea_id, date_created DESC, id DESC);
+CREATE INDEX IF NOT EXISTS idx_activities_actor ON activities (actor_id, date_created DESC, id DESC);
+
+-- Following goes away with the idea or user followed.
+CREATE OR REPLACE FUNCTION follows_target_deleted() RETURNS TRIGGER
+    LANGUAGE plpgsql
+AS
+$$
+BEGIN
+    DELETE FROM follows WHERE target_type = TG_ARGV[0] AND target_id = OLD.id;
+    RETURN OLD;
+END;
+$$;
+
+DROP TRIGGER IF EXISTS ideas_follows_deleted ON ideas;
+CREATE TRIGGER ideas_follows_deleted
+    AFTER DELETE
+    ON ideas
+    FOR EACH ROW
+EXECUTE FUNCTION follows_target_deleted('idea');
+
+DROP TRIGGER IF EXISTS users_follows_deleted ON users;
+CREATE TRIGGER users_follows_deleted
+    AFTER DELETE
+    ON users
+    FOR EACH ROW
+EXECUTE FUNCTION follows_target_deleted('user');
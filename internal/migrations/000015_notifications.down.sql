@@ -0,0 +1,4 @@
+ALTER TABLE users
+    DROP COLUMN IF EXISTS notification_preferences;
+
+DROP TABLE IF EXISTS notifications;
@@ -0,0 +1,26 @@
+-- Notifications of what happened on the ideas a user is involved in, as
+-- recorded by the cores. The subject and details depend on the type.
+CREATE TABLE IF NOT EXISTS notifications
+(
+    id           UUID        NOT NULL,
+    user_id      UUID        NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    type         TEXT        NOT NULL,
+    actor_id     UUID        NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    idea_id      UUID        NOT NULL REFERENCES ideas (id) ON DELETE CASCADE,
+    subject_id   UUID        NULL,
+    details      JSONB       NOT NULL DEFAULT '{}',
+    read_at      TIMESTAMPTZ NULL,
+    date_created TIMESTAMPTZ NOT NULL,
+
+    PRIMARY KEY (id)
+);
+
+-- Notifications are listed newest first per user, and the unread ones are
+-- counted on every page load.
+CREATE INDEX IF NOT EXISTS idx_notifications_user ON notifications (user_id, date_created DESC, id);
+CREATE INDEX IF NOT EXISTS idx_notifications_unread ON notifications (user_id) WHERE read_at IS NULL;
+
+-- Which notifications a user wants, by type and then by channel. Anything
+-- not listed is wanted.
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS notification_preferences JSONB NOT NULL DEFAULT '{}';
@@ -0,0 +1,24 @@
+-- Emails waiting to be sent, rendered when they were queued. Delivery claims
+-- the due rows by pushing next_attempt_at forward, and pushes it further back
+-- after every failed attempt.
+CREATE TABLE IF NOT EXISTS email_outbox
+(
+    id              UUID        NOT NULL,
+    recipient       TEXT        NOT NULL,
+    template        TEXT        NOT NULL,
+    subject         TEXT        NOT NULL,
+    text_body       TEXT        NOT NULL,
+    html_body       TEXT        NOT NULL DEFAULT '',
+    status          TEXT        NOT NULL CHECK (status IN ('pending', 'sent', 'failed')),
+    attempts        INT         NOT NULL DEFAULT 0,
+    last_error      TEXT        NOT NULL DEFAULT '',
+    next_attempt_at TIMESTAMPTZ NOT NULL,
+    date_sent       TIMESTAMPTZ NULL,
+    date_created    TIMESTAMPTZ NOT NULL,
+    date_updated    TIMESTAMPTZ NOT NULL,
+
+    PRIMARY KEY (id)
+);
+
+-- Delivery only looks at the pending emails that are due.
+CREATE INDEX IF NOT EXISTS idx_email_outbox_due ON email_outbox (next_attempt_at) WHERE status = 'pending';
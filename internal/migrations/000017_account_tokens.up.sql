@@ -0,0 +1,24 @@
+-- Users prove they own their email address before they can create ideas.
+-- Accounts that existed before verification was introduced are trusted.
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS email_verified_at TIMESTAMPTZ NULL;
+
+UPDATE users SET email_verified_at = date_created WHERE email_verified_at IS NULL;
+
+-- Single-use tokens emailed to users to verify their address or reset their
+-- password. Only the SHA-256 hash of the token is stored.
+CREATE TABLE IF NOT EXISTS account_tokens
+(
+    id           UUID        NOT NULL,
+    user_id      UUID        NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    purpose      TEXT        NOT NULL CHECK (purpose IN ('verify_email', 'password_reset')),
+    email        TEXT        NOT NULL,
+    token_hash   BYTEA       NOT NULL UNIQUE,
+    date_expires TIMESTAMPTZ NOT NULL,
+    date_used    TIMESTAMPTZ NULL,
+    date_created TIMESTAMPTZ NOT NULL,
+
+    PRIMARY KEY (id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_account_tokens_user ON account_tokens (user_id, purpose);
@@ -0,0 +1,35 @@
+-- Rotating refresh tokens. Each sign-in starts a family; exchanging a token
+-- marks it used and adds the next one to the family. Only the SHA-256 hash of
+-- the token is stored.
+CREATE TABLE IF NOT EXISTS refresh_tokens
+(
+    id                  UUID        NOT NULL,
+    family_id           UUID        NOT NULL,
+    user_id             UUID        NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    token_hash          BYTEA       NOT NULL UNIQUE,
+    user_agent          TEXT        NOT NULL DEFAULT '',
+    ip_address          TEXT        NOT NULL DEFAULT '',
+    access_token_id     TEXT        NOT NULL,
+    access_token_expiry TIMESTAMPTZ NOT NULL,
+    date_expires        TIMESTAMPTZ NOT NULL,
+    date_used           TIMESTAMPTZ NULL,
+    date_revoked        TIMESTAMPTZ NULL,
+    date_created        TIMESTAMPTZ NOT NULL,
+
+    PRIMARY KEY (id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_refresh_tokens_family ON refresh_tokens (family_id);
+CREATE INDEX IF NOT EXISTS idx_refresh_tokens_user ON refresh_tokens (user_id);
+
+-- Access tokens revoked before they expired, by their jti claim. Rows are
+-- purged once the token would have expired anyway.
+CREATE TABLE IF NOT EXISTS revoked_access_tokens
+(
+    jti          TEXT        NOT NULL,
+    user_id      UUID        NOT NULL,
+    date_expires TIMESTAMPTZ NOT NULL,
+    date_created TIMESTAMPTZ NOT NULL,
+
+    PRIMARY KEY (jti)
+);
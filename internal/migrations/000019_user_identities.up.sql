@@ -0,0 +1,28 @@
+-- Accounts at OpenID Connect providers linked to users. A user can sign in
+-- with several providers.
+CREATE TABLE IF NOT EXISTS user_identities
+(
+    provider     TEXT        NOT NULL,
+    subject      TEXT        NOT NULL,
+    user_id      UUID        NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    email        TEXT        NOT NULL,
+    date_created TIMESTAMPTZ NOT NULL,
+
+    PRIMARY KEY (provider, subject)
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_identities_user ON user_identities (user_id);
+
+-- Sign ins with a provider that were started but not completed yet, by the
+-- SHA-256 hash of the state sent to the provider.
+CREATE TABLE IF NOT EXISTS oidc_logins
+(
+    state_hash   BYTEA       NOT NULL,
+    provider     TEXT        NOT NULL,
+    verifier     TEXT        NOT NULL,
+    nonce        TEXT        NOT NULL,
+    date_expires TIMESTAMPTZ NOT NULL,
+    date_created TIMESTAMPTZ NOT NULL,
+
+    PRIMARY KEY (state_hash)
+);
@@ -0,0 +1,18 @@
+-- Personal access tokens for scripts and command line clients. Only the
+-- SHA-256 hash of the token is stored.
+CREATE TABLE IF NOT EXISTS personal_access_tokens
+(
+    id             UUID        NOT NULL,
+    user_id        UUID        NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    name           TEXT        NOT NULL,
+    scopes         TEXT[]      NOT NULL,
+    token_hash     BYTEA       NOT NULL UNIQUE,
+    date_expires   TIMESTAMPTZ NULL,
+    date_last_used TIMESTAMPTZ NULL,
+    date_revoked   TIMESTAMPTZ NULL,
+    date_created   TIMESTAMPTZ NOT NULL,
+
+    PRIMARY KEY (id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_personal_access_tokens_user ON personal_access_tokens (user_id);
@@ -0,0 +1,9 @@
+-- What users tell others about themselves, and the language and time zone
+-- they want to be addressed in. An empty locale or time zone means the
+-- defaults apply.
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS bio        TEXT   NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS skills     TEXT[] NOT NULL DEFAULT '{}',
+    ADD COLUMN IF NOT EXISTS avatar_url TEXT   NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS locale     TEXT   NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS timezone   TEXT   NOT NULL DEFAULT '';
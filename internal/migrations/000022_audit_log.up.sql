@@ -0,0 +1,18 @@
+-- What administrators did to user accounts. The actor and the target are not
+-- foreign keys so the log outlives the users it names.
+CREATE TABLE IF NOT EXISTS audit_log
+(
+    id           UUID        NOT NULL,
+    action       TEXT        NOT NULL,
+    actor_id     UUID        NOT NULL,
+    target_id    UUID        NOT NULL,
+    details      JSONB       NOT NULL DEFAULT '{}',
+    date_created TIMESTAMPTZ NOT NULL,
+
+    PRIMARY KEY (id)
+);
+
+-- The log is read newest first, overall or by actor or target.
+CREATE INDEX IF NOT EXISTS idx_audit_log_date ON audit_log (date_created DESC, id DESC);
+CREATE INDEX IF NOT EXISTS idx_audit_log_actor ON audit_log (actor_id, date_created DESC);
+CREATE INDEX IF NOT EXISTS idx_audit_log_target ON audit_log (target_id, date_created DESC);
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS data_exports;
+DROP TABLE IF EXISTS account_deletions;
+DELETE FROM users WHERE id = '00000000-0000-4000-8000-000000000000';
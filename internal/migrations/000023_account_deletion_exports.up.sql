@@ -0,0 +1,39 @@
+-- Users ask for their account to be deleted and can cancel until the request
+-- is due, when the account is erased.
+CREATE TABLE IF NOT EXISTS account_deletions
+(
+    user_id      UUID        NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    date_due     TIMESTAMPTZ NOT NULL,
+    date_created TIMESTAMPTZ NOT NULL,
+
+    PRIMARY KEY (user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_account_deletions_due ON account_deletions (date_due);
+
+-- What erased users contributed to the ideas of others is credited to this
+-- placeholder. It is disabled and has no usable password.
+INSERT INTO users (id, name, email, roles, password_hash, enabled, date_created, date_updated)
+VALUES ('00000000-0000-4000-8000-000000000000', 'Deleted user', 'deleted@startupers.invalid', '{}', '', FALSE, now(), now())
+ON CONFLICT (id) DO NOTHING;
+
+-- Exports of the data of a user, built by a background job and kept as a ZIP
+-- archive until they expire.
+CREATE TABLE IF NOT EXISTS data_exports
+(
+    id             UUID        NOT NULL,
+    user_id        UUID        NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    status         TEXT        NOT NULL CHECK (status IN ('pending', 'running', 'ready', 'failed')),
+    archive        BYTEA       NULL,
+    size           INT         NOT NULL DEFAULT 0,
+    date_started   TIMESTAMPTZ NULL,
+    date_completed TIMESTAMPTZ NULL,
+    date_expires   TIMESTAMPTZ NULL,
+    date_created   TIMESTAMPTZ NOT NULL,
+
+    PRIMARY KEY (id)
+);
+
+-- Exports are listed per user and claimed by the job oldest first.
+CREATE INDEX IF NOT EXISTS idx_data_exports_user ON data_exports (user_id, date_created DESC);
+CREATE INDEX IF NOT EXISTS idx_data_exports_status ON data_exports (status, date_created);
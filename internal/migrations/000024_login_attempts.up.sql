@@ -0,0 +1,19 @@
+-- Failed sign in attempts, counted per email address and per IP address so
+-- every replica of the API enforces the same delays and lockouts. Email
+-- addresses without an account are counted like any other.
+CREATE TABLE IF NOT EXISTS login_attempts
+(
+    id                UUID        NOT NULL,
+    kind              TEXT        NOT NULL CHECK (kind IN ('account', 'ip')),
+    subject           TEXT        NOT NULL,
+    failures          INT         NOT NULL DEFAULT 0,
+    date_last_failure TIMESTAMPTZ NOT NULL,
+    date_locked_until TIMESTAMPTZ NULL,
+
+    PRIMARY KEY (id),
+    UNIQUE (kind, subject)
+);
+
+-- Administrators list what is locked now, and old attempts are purged.
+CREATE INDEX IF NOT EXISTS idx_login_attempts_locked ON login_attempts (date_locked_until);
+CREATE INDEX IF NOT EXISTS idx_login_attempts_last_failure ON login_attempts (date_last_failure);